@@ -10,6 +10,10 @@ const (
 	AgentClaudeCode AgentType = "claude-code"
 	AgentAmp        AgentType = "amp"
 	AgentGeneric    AgentType = "generic"
+
+	// AgentOpenCode labels OpenCode sessions, which are read over its API
+	// rather than detected in tmux panes.
+	AgentOpenCode AgentType = "opencode"
 )
 
 // AgentState wraps parser.Result with agent metadata.
@@ -43,3 +47,20 @@ type Agent interface {
 	// Returns empty string for agents without mode support.
 	DetectMode(output string) parser.Mode
 }
+
+// ChoiceVerifier is implemented by agents that can tell their own
+// interactive prompts apart from output text that merely looks like one.
+type ChoiceVerifier interface {
+	// VerifyChoices reports whether choices are the options of a selector
+	// the agent is showing, rather than numbered lines printed in output.
+	VerifyChoices(output string, choices []string) bool
+}
+
+// ChoiceSelector is implemented by agents that know the keystrokes picking
+// an option of their selector.
+type ChoiceSelector interface {
+	// ChoiceKeys returns the tmux keys that select choice in the selector
+	// shown at the bottom of output. ok is false when no selector on screen
+	// offers it.
+	ChoiceKeys(output, choice string) (keys []string, ok bool)
+}
@@ -15,8 +15,8 @@ type Agent struct {
 // New creates a new Amp agent with default paths.
 func New() *Agent {
 	return &Agent{
-		threadsDir: getThreadsDir(),
-		stateDir:   getStateDir(),
+		threadsDir: ThreadsDir(),
+		stateDir:   StateDir(),
 	}
 }
 
@@ -47,6 +47,18 @@ func (a *Agent) GetStateFromFiles(cwd string) (*agents.AgentState, error) {
 	}, nil
 }
 
+func (a *Agent) Transcript(cwd string) (*agents.Transcript, error) {
+	return GetTranscript(a.threadsDir, a.stateDir, cwd)
+}
+
+func (a *Agent) VerifyChoices(output string, choices []string) bool {
+	return VerifyChoices(output, choices)
+}
+
+func (a *Agent) ChoiceKeys(output, choice string) ([]string, bool) {
+	return ChoiceKeys(output, choice)
+}
+
 func (a *Agent) FilterStatusBar(output string) string {
 	return FilterStatusBar(output)
 }
@@ -1,6 +1,7 @@
 package amp
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/noamsto/houston/parser"
@@ -198,3 +199,31 @@ func TestParseOutput_ActivityPatterns(t *testing.T) {
 		})
 	}
 }
+
+func TestChoiceKeys(t *testing.T) {
+	input := `│ Run this command?                                                             │
+│   Yes                                                                         │
+│ ‣ Allow All for This Session                                                  │
+│   Allow All for Every Session                                                 │
+│   No                                                                          │`
+
+	tests := []struct {
+		choice string
+		want   []string
+	}{
+		{"Yes", []string{"Up", "Enter"}},
+		{"Allow All for This Session", []string{"Enter"}},
+		{"No", []string{"Down", "Down", "Enter"}},
+	}
+	for _, tt := range tests {
+		if got, ok := ChoiceKeys(input, tt.choice); !ok || !slices.Equal(got, tt.want) {
+			t.Errorf("ChoiceKeys(%q) = %v, %v; want %v", tt.choice, got, ok, tt.want)
+		}
+	}
+	if _, ok := ChoiceKeys(input, "Run this command?"); ok {
+		t.Error("ChoiceKeys treated the question as an option")
+	}
+	if _, ok := ChoiceKeys("no selector here", "Yes"); ok {
+		t.Error("ChoiceKeys found a selector in plain output")
+	}
+}
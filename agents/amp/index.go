@@ -0,0 +1,195 @@
+package amp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/internal/fswatch"
+)
+
+// threadIndex maps the thread files of a threads directory to the
+// workspaces they were started in, so finding the thread of a directory
+// doesn't mean parsing every thread. Once the directory is watched, only
+// files that change are read again; until then (or if watching fails) each
+// lookup reads the directory and re-parses files whose size or mtime moved.
+type threadIndex struct {
+	dir string
+
+	refreshMu sync.Mutex // Serializes starting the watch and rescans
+
+	mu       sync.Mutex
+	watching bool
+	threads  map[string]threadEntry // File name -> entry
+}
+
+type threadEntry struct {
+	created    int64
+	modTime    time.Time
+	size       int64
+	workspaces []string // Resolved workspace paths
+}
+
+var indexes = struct {
+	sync.Mutex
+	byDir map[string]*threadIndex
+}{byDir: make(map[string]*threadIndex)}
+
+// indexFor returns the shared index of a threads directory.
+func indexFor(dir string) *threadIndex {
+	indexes.Lock()
+	defer indexes.Unlock()
+	ix, ok := indexes.byDir[dir]
+	if !ok {
+		ix = &threadIndex{dir: dir, threads: make(map[string]threadEntry)}
+		indexes.byDir[dir] = ix
+	}
+	return ix
+}
+
+// matching returns the file names of the threads started in a workspace
+// containing cwd, newest first.
+func (ix *threadIndex) matching(cwd string) ([]string, error) {
+	if err := ix.refresh(); err != nil {
+		return nil, err
+	}
+
+	ix.mu.Lock()
+	var names []string
+	for name, entry := range ix.threads {
+		if inWorkspace(entry.workspaces, cwd) {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ci, cj := ix.threads[names[i]].created, ix.threads[names[j]].created
+		if ci != cj {
+			return ci > cj
+		}
+		return names[i] > names[j]
+	})
+	ix.mu.Unlock()
+	return names, nil
+}
+
+// refresh brings the index up to date unless the directory is watched,
+// starting the watch if it isn't yet.
+func (ix *threadIndex) refresh() error {
+	ix.refreshMu.Lock()
+	defer ix.refreshMu.Unlock()
+
+	ix.mu.Lock()
+	watching := ix.watching
+	ix.mu.Unlock()
+	if watching {
+		return nil
+	}
+
+	fw, err := fswatch.WatchDir(ix.dir)
+	if err != nil {
+		return ix.rescan()
+	}
+	// Scan after the watch is in place so no change slips between the two.
+	if err := ix.rescan(); err != nil {
+		_ = fw.Close()
+		return err
+	}
+	ix.mu.Lock()
+	ix.watching = true
+	ix.mu.Unlock()
+
+	go func() {
+		for ev := range fw.Events {
+			ix.update(ev)
+		}
+		ix.mu.Lock()
+		ix.watching = false
+		ix.mu.Unlock()
+	}()
+	return nil
+}
+
+// rescan reads the directory, parsing only new and changed thread files.
+func (ix *threadIndex) rescan() error {
+	entries, err := os.ReadDir(ix.dir)
+	if err != nil {
+		return fmt.Errorf("reading threads dir: %w", err)
+	}
+
+	ix.mu.Lock()
+	old := ix.threads
+	ix.mu.Unlock()
+
+	threads := make(map[string]threadEntry, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if entry, ok := old[e.Name()]; ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+			threads[e.Name()] = entry
+			continue
+		}
+		if entry, err := readThreadEntry(filepath.Join(ix.dir, e.Name())); err == nil {
+			threads[e.Name()] = entry
+		}
+	}
+
+	ix.mu.Lock()
+	ix.threads = threads
+	ix.mu.Unlock()
+	return nil
+}
+
+// update applies a change to one thread file.
+func (ix *threadIndex) update(ev fswatch.Event) {
+	if !strings.HasSuffix(ev.Name, ".json") {
+		return
+	}
+	entry, err := readThreadEntry(filepath.Join(ix.dir, ev.Name))
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	switch {
+	case ev.Removed || os.IsNotExist(err):
+		delete(ix.threads, ev.Name)
+	case err == nil:
+		ix.threads[ev.Name] = entry
+	}
+	// Unparseable (e.g. caught mid-write): keep the last entry
+}
+
+// readThreadEntry reads what the index keeps of a thread file, skipping
+// its messages.
+func readThreadEntry(path string) (threadEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return threadEntry{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return threadEntry{}, err
+	}
+
+	var header struct {
+		Created int64     `json:"created"`
+		Env     ThreadEnv `json:"env"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return threadEntry{}, err
+	}
+	return threadEntry{
+		created:    header.Created,
+		modTime:    info.ModTime(),
+		size:       info.Size(),
+		workspaces: workspacePaths(header.Env),
+	}, nil
+}
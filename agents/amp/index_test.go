@@ -0,0 +1,54 @@
+package amp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeThread(t *testing.T, dir, id, workspace string, created int64) {
+	t.Helper()
+	data := fmt.Sprintf(`{"id":%q,"created":%d,"env":{"initial":{"trees":[{"uri":"file://%s"}]}},"messages":[]}`, id, created, workspace)
+	if err := os.WriteFile(filepath.Join(dir, id+".json"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitMatching polls the index until cwd matches want.
+func waitMatching(t *testing.T, ix *threadIndex, cwd string, want []string) {
+	t.Helper()
+	var got []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		var err error
+		if got, err = ix.matching(cwd); err != nil {
+			t.Fatal(err)
+		}
+		if slices.Equal(got, want) {
+			return
+		}
+	}
+	t.Fatalf("matching(%s) = %v, want %v", cwd, got, want)
+}
+
+func TestThreadIndex(t *testing.T) {
+	dir := t.TempDir()
+	repo := t.TempDir()
+	other := t.TempDir()
+	writeThread(t, dir, "T-old", repo, 100)
+	writeThread(t, dir, "T-other", other, 300)
+
+	ix := &threadIndex{dir: dir, threads: make(map[string]threadEntry)}
+	waitMatching(t, ix, filepath.Join(repo, "src"), []string{"T-old.json"})
+
+	// New and removed threads are picked up
+	writeThread(t, dir, "T-new", repo, 200)
+	waitMatching(t, ix, repo, []string{"T-new.json", "T-old.json"})
+
+	if err := os.Remove(filepath.Join(dir, "T-old.json")); err != nil {
+		t.Fatal(err)
+	}
+	waitMatching(t, ix, repo, []string{"T-new.json"})
+}
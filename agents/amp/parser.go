@@ -2,6 +2,7 @@ package amp
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/noamsto/houston/parser"
@@ -165,6 +166,81 @@ func ParseOutput(output string) parser.Result {
 	return parser.Result{Type: parser.TypeIdle}
 }
 
+// VerifyChoices reports whether choices are the options of Amp's ‣
+// selector on screen. Numbered choices picked out of output text don't
+// verify.
+func VerifyChoices(output string, choices []string) bool {
+	selector, _ := parseAmpChoices(lastN(strings.Split(output, "\n"), 50))
+	return len(selector) > 0 && slices.Equal(selector, choices)
+}
+
+// ChoiceKeys returns the keys that pick choice in Amp's ‣ selector on
+// screen: arrows from the cursor, then Enter.
+func ChoiceKeys(output, choice string) ([]string, bool) {
+	options, cursor, ok := ampSelector(lastN(strings.Split(output, "\n"), 50))
+	if !ok {
+		return nil, false
+	}
+	target := slices.Index(options, strings.TrimSpace(choice))
+	if target == -1 {
+		return nil, false
+	}
+	key := "Down"
+	if target < cursor {
+		key = "Up"
+	}
+	var keys []string
+	for range max(target-cursor, cursor-target) {
+		keys = append(keys, key)
+	}
+	return append(keys, "Enter"), true
+}
+
+// ampSelector returns the options of the last ‣ selector in lines in
+// screen order, and the index of the one under the cursor.
+func ampSelector(lines []string) (options []string, cursor int, ok bool) {
+	at := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if m := ampChoiceSelectedPattern.FindStringSubmatch(lines[i]); m != nil && !strings.HasPrefix(strings.TrimSpace(m[1]), "(") {
+			at = i
+			break
+		}
+	}
+	if at == -1 {
+		return nil, 0, false
+	}
+	for i := at - 1; i >= 0; i-- {
+		option, ok := ampOption(lines[i])
+		if !ok {
+			break
+		}
+		options = append([]string{option}, options...)
+	}
+	cursor = len(options)
+	options = append(options, strings.TrimSpace(ampChoiceSelectedPattern.FindStringSubmatch(lines[at])[1]))
+	for _, line := range lines[at+1:] {
+		option, ok := ampOption(line)
+		if !ok {
+			break
+		}
+		options = append(options, option)
+	}
+	return options, cursor, true
+}
+
+// ampOption reports whether a line is an unselected option of a ‣
+// selector: short, capitalized, and not a sentence or the question.
+func ampOption(line string) (string, bool) {
+	trimmed := strings.TrimRight(strings.TrimLeft(line, "│ \t"), "│ \t")
+	if len(trimmed) < 2 || trimmed[0] < 'A' || trimmed[0] > 'Z' {
+		return "", false
+	}
+	if len(trimmed) >= 40 || strings.Contains(trimmed, ".") || strings.HasSuffix(trimmed, "?") {
+		return "", false
+	}
+	return trimmed, true
+}
+
 // parseAmpChoices extracts choices from Amp's cursor-based selection UI.
 // Returns choices and the question text.
 func parseAmpChoices(lines []string) ([]string, string) {
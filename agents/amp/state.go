@@ -6,7 +6,6 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 
@@ -61,14 +60,14 @@ type Usage struct {
 	OutputTokens int    `json:"outputTokens"`
 }
 
-// getThreadsDir returns the Amp threads directory.
-func getThreadsDir() string {
+// ThreadsDir returns the Amp threads directory.
+func ThreadsDir() string {
 	homeDir, _ := os.UserHomeDir()
 	return filepath.Join(homeDir, ".local", "share", "amp", "threads")
 }
 
-// getStateDir returns the Amp state directory.
-func getStateDir() string {
+// StateDir returns the Amp state directory.
+func StateDir() string {
 	homeDir, _ := os.UserHomeDir()
 	return filepath.Join(homeDir, ".local", "state", "amp")
 }
@@ -101,46 +100,17 @@ func findThreadForCwd(threadsDir, stateDir, cwd string) (*Thread, error) {
 		}
 	}
 
-	// Otherwise, find most recent thread matching cwd
-	entries, err := os.ReadDir(threadsDir)
+	// Otherwise, the most recent thread started in a workspace containing cwd
+	names, err := indexFor(threadsDir).matching(cwd)
 	if err != nil {
-		return nil, fmt.Errorf("reading threads dir: %w", err)
-	}
-
-	var matchingThreads []struct {
-		thread  *Thread
-		created int64
+		return nil, err
 	}
-
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		threadPath := filepath.Join(threadsDir, entry.Name())
-		thread, err := readThreadFile(threadPath)
-		if err != nil {
-			continue
-		}
-
-		if threadMatchesCwd(thread, cwd) {
-			matchingThreads = append(matchingThreads, struct {
-				thread  *Thread
-				created int64
-			}{thread, thread.Created})
+	for _, name := range names {
+		if thread, err := readThreadFile(filepath.Join(threadsDir, name)); err == nil {
+			return thread, nil
 		}
 	}
-
-	if len(matchingThreads) == 0 {
-		return nil, fmt.Errorf("no thread found for cwd: %s", cwd)
-	}
-
-	// Sort by created (newest first)
-	sort.Slice(matchingThreads, func(i, j int) bool {
-		return matchingThreads[i].created > matchingThreads[j].created
-	})
-
-	return matchingThreads[0].thread, nil
+	return nil, fmt.Errorf("no thread found for cwd: %s", cwd)
 }
 
 // readLastThreadID reads the last-thread-id from state directory.
@@ -175,7 +145,13 @@ func readThreadFile(path string) (*Thread, error) {
 
 // threadMatchesCwd checks if thread's workspace matches the given cwd.
 func threadMatchesCwd(thread *Thread, cwd string) bool {
-	for _, tree := range thread.Env.Initial.Trees {
+	return inWorkspace(workspacePaths(thread.Env), cwd)
+}
+
+// workspacePaths returns the resolved paths of a thread's workspaces.
+func workspacePaths(env ThreadEnv) []string {
+	var paths []string
+	for _, tree := range env.Initial.Trees {
 		// Parse URI and extract path
 		treePath := uriToPath(tree.URI)
 		if treePath == "" {
@@ -187,8 +163,14 @@ func threadMatchesCwd(thread *Thread, cwd string) bool {
 		if resolved, err := filepath.EvalSymlinks(treePath); err == nil {
 			treePath = resolved
 		}
+		paths = append(paths, treePath)
+	}
+	return paths
+}
 
-		// Check exact match or if cwd is under the workspace
+// inWorkspace reports whether cwd is one of the workspaces or under one.
+func inWorkspace(workspaces []string, cwd string) bool {
+	for _, treePath := range workspaces {
 		if treePath == cwd || strings.HasPrefix(cwd, treePath+"/") {
 			return true
 		}
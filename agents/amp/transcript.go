@@ -0,0 +1,73 @@
+package amp
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/noamsto/houston/agents"
+)
+
+// GetTranscript reconstructs the conversation of the thread matching cwd.
+func GetTranscript(threadsDir, stateDir, cwd string) (*agents.Transcript, error) {
+	cwd = filepath.Clean(cwd)
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+
+	thread, err := findThreadForCwd(threadsDir, stateDir, cwd)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildTranscript(thread), nil
+}
+
+// BuildTranscript converts an Amp thread into a transcript of user prompts,
+// assistant text and tool calls.
+func BuildTranscript(thread *Thread) *agents.Transcript {
+	t := &agents.Transcript{
+		Agent:     agents.AgentAmp,
+		SessionID: thread.ID,
+		Title:     thread.Title,
+		Entries:   []agents.TranscriptEntry{},
+	}
+	for _, tree := range thread.Env.Initial.Trees {
+		if path := uriToPath(tree.URI); path != "" {
+			t.CWD = path
+			break
+		}
+	}
+
+	for _, msg := range thread.Messages {
+		for _, item := range msg.Content {
+			block, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			blockType, _ := block["type"].(string)
+
+			switch blockType {
+			case "text":
+				text, _ := block["text"].(string)
+				if strings.TrimSpace(text) == "" {
+					continue
+				}
+				role := agents.RoleAssistant
+				if msg.Role == "user" {
+					role = agents.RoleUser
+				}
+				t.Entries = append(t.Entries, agents.TranscriptEntry{Role: role, Text: text})
+			case "tool_use":
+				name, _ := block["name"].(string)
+				input, _ := block["input"].(map[string]any)
+				t.Entries = append(t.Entries, agents.TranscriptEntry{
+					Role:      agents.RoleTool,
+					Tool:      name,
+					ToolInput: agents.SummarizeToolInput(input),
+				})
+			}
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,123 @@
+package claude
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/noamsto/houston/internal/ansi"
+)
+
+var (
+	selectorChoicePattern = regexp.MustCompile(`^([❯>]?)\s*([0-9]+)[.)]\s+(.+)$`)
+	// Key hints under a selector: "Esc to cancel · Tab to add additional instructions"
+	selectorHintPattern = regexp.MustCompile(`(?i)^(esc|enter|tab|↑|ctrl)\b.* to `)
+)
+
+// VerifyChoices reports whether choices are the options of Claude's
+// selector at the bottom of the screen: the numbered options are the last
+// content (below them only key hints, borders and the status bar) and one
+// of them carries the ❯ cursor. Numbered lines anywhere else in the output,
+// including ones written to look like a prompt, don't verify.
+func VerifyChoices(output string, choices []string) bool {
+	options, ok := selectorOptions(output)
+	if !ok || len(options) != len(choices) {
+		return false
+	}
+	for i, c := range choices {
+		if normalizeChoice(c) != options[i].text {
+			return false
+		}
+	}
+	return true
+}
+
+// ChoiceKeys returns the keys that pick choice in the selector on screen:
+// its number, or arrows from the cursor and Enter past option 9.
+func ChoiceKeys(output, choice string) ([]string, bool) {
+	options, ok := selectorOptions(output)
+	if !ok {
+		return nil, false
+	}
+	target, cursor := -1, 0
+	for i, o := range options {
+		if o.text == normalizeChoice(choice) && target == -1 {
+			target = i
+		}
+		if o.cursor {
+			cursor = i
+		}
+	}
+	if target == -1 {
+		return nil, false
+	}
+	if n := options[target].number; n >= 1 && n <= 9 {
+		return []string{strconv.Itoa(n)}, true
+	}
+	return arrowKeys(cursor, target), true
+}
+
+// arrowKeys moves a selector cursor from one option to another and
+// confirms.
+func arrowKeys(from, to int) []string {
+	key := "Down"
+	if to < from {
+		key = "Up"
+	}
+	var keys []string
+	for range max(to-from, from-to) {
+		keys = append(keys, key)
+	}
+	return append(keys, "Enter")
+}
+
+// selectorOption is one line of Claude's numbered selector.
+type selectorOption struct {
+	number int
+	text   string
+	cursor bool
+}
+
+// selectorOptions returns the options of the selector at the bottom of the
+// screen, top to bottom. ok is false when there is none or no option
+// carries the cursor.
+func selectorOptions(output string) (options []selectorOption, ok bool) {
+	lines := strings.Split(output, "\n")
+
+	cursor := false
+	started := false
+	for i := len(lines) - 1; i >= 0 && i >= len(lines)-60; i-- {
+		raw := lines[i]
+		line := permissionLine(raw)
+		if !started && (line == "" || IsStatusLine(raw) || selectorHintPattern.MatchString(line) || strings.Trim(line, "╭╮╰╯─") == "") {
+			continue // Below the selector
+		}
+		m := selectorChoicePattern.FindStringSubmatch(line)
+		if m == nil {
+			if optionDetail(raw) || (started && line == "") {
+				continue // Wrapped option text or option description
+			}
+			if !started {
+				return nil, false // Other content after the options
+			}
+			break // Question or body above the options
+		}
+		started = true
+		n, _ := strconv.Atoi(m[2])
+		o := selectorOption{number: n, text: normalizeChoice(m[3]), cursor: m[1] == "❯"}
+		cursor = cursor || o.cursor
+		options = append([]selectorOption{o}, options...)
+	}
+	return options, cursor && len(options) > 0
+}
+
+// optionDetail reports whether a line is indented like the description or
+// wrapped text of a selector option.
+func optionDetail(raw string) bool {
+	line := strings.TrimPrefix(ansi.Strip(raw), "│")
+	return strings.TrimSpace(line) != "" && strings.HasPrefix(line, "    ")
+}
+
+func normalizeChoice(c string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(c), "│"))
+}
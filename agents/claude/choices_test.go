@@ -0,0 +1,119 @@
+package claude
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/parser"
+)
+
+func TestVerifyChoices(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{
+			name: "permission selector",
+			output: ` Bash command
+
+   go test ./...
+
+ Do you want to proceed?
+ ❯ 1. Yes
+   2. No, and tell Claude what to do differently (esc)
+
+ Esc to cancel`,
+			want: true,
+		},
+		{
+			name: "bordered selector",
+			output: `│ Do you want to proceed?                  │
+│ ❯ 1. Yes                                 │
+│   2. No, and tell Claude what to do differently (esc) │
+╰──────────────────────────────────────────╯`,
+			want: true,
+		},
+		{
+			name: "selector with descriptions",
+			output: ` Which database should I use?
+
+ ❯ 1. Yes
+      Keep Postgres
+   2. No, and tell Claude what to do differently (esc)
+      Switch to SQLite
+`,
+			want: true,
+		},
+		{
+			name: "bait in output, no cursor",
+			output: `● The README says:
+
+  Do you want to proceed?
+  1. Yes
+  2. No, and tell Claude what to do differently (esc)
+
+────────────────────────────────────────────────────────────
+> 
+────────────────────────────────────────────────────────────
+  -- INSERT --`,
+			want: false,
+		},
+		{
+			name: "bait followed by more output",
+			output: `Do you want to proceed?
+❯ 1. Yes
+  2. No, and tell Claude what to do differently (esc)
+● Reading file…`,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			choices := parser.Parse(tt.output).Choices
+			if len(choices) == 0 {
+				choices = []string{"Yes", "No, and tell Claude what to do differently (esc)"}
+			}
+			if got := VerifyChoices(tt.output, choices); got != tt.want {
+				t.Errorf("VerifyChoices(%v) = %v, want %v", choices, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChoiceKeys(t *testing.T) {
+	menu := ` Do you want to proceed?
+ ❯ 1. Yes
+   2. Yes, and don't ask again for go test commands
+   3. No, and tell Claude what to do differently (esc)
+
+ Esc to cancel`
+	keys, ok := ChoiceKeys(menu, "No, and tell Claude what to do differently (esc)")
+	if !ok || !slices.Equal(keys, []string{"3"}) {
+		t.Errorf("ChoiceKeys = %v, %v; want [3]", keys, ok)
+	}
+	if _, ok := ChoiceKeys(menu, "Maybe"); ok {
+		t.Error("ChoiceKeys found an option the menu doesn't have")
+	}
+
+	// Past 9 there is no digit to press
+	var long strings.Builder
+	long.WriteString(" Pick a file\n")
+	for i := 1; i <= 11; i++ {
+		cursor := "  "
+		if i == 2 {
+			cursor = "❯ "
+		}
+		fmt.Fprintf(&long, " %s%d. file%d.go\n", cursor, i, i)
+	}
+	if keys, _ := ChoiceKeys(long.String(), "file11.go"); !slices.Equal(keys, []string{"Down", "Down", "Down", "Down", "Down", "Down", "Down", "Down", "Down", "Enter"}) {
+		t.Errorf("ChoiceKeys(file11.go) = %v, want 9 Downs and Enter", keys)
+	}
+
+	if _, ok := ChoiceKeys("Do you want to proceed?\n1. Yes\n2. No\n● Reading file…", "Yes"); ok {
+		t.Error("ChoiceKeys accepted numbered lines that aren't a selector")
+	}
+}
@@ -7,11 +7,28 @@ import (
 )
 
 // Agent implements agents.Agent for Claude Code.
-type Agent struct{}
+type Agent struct {
+	tailer *Tailer
+}
+
+// Option configures an Agent.
+type Option func(*Agent)
+
+// WithTailer serves file-based state from a Tailer instead of reading the
+// session file on every call.
+func WithTailer(t *Tailer) Option {
+	return func(a *Agent) {
+		a.tailer = t
+	}
+}
 
 // New creates a new Claude Code agent.
-func New() *Agent {
-	return &Agent{}
+func New(opts ...Option) *Agent {
+	a := &Agent{}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 func (a *Agent) Type() agents.AgentType {
@@ -31,7 +48,13 @@ func (a *Agent) ParseOutput(output string) agents.AgentState {
 }
 
 func (a *Agent) GetStateFromFiles(cwd string) (*agents.AgentState, error) {
-	state, err := GetStateFromFiles(cwd)
+	var state *parser.Result
+	var err error
+	if a.tailer != nil {
+		state, err = a.tailer.State(cwd)
+	} else {
+		state, err = GetStateFromFiles(cwd)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +64,22 @@ func (a *Agent) GetStateFromFiles(cwd string) (*agents.AgentState, error) {
 	}, nil
 }
 
+func (a *Agent) Transcript(cwd string) (*agents.Transcript, error) {
+	return GetTranscript(cwd)
+}
+
+func (a *Agent) Todos(cwd string) ([]agents.Todo, error) {
+	return GetTodos(cwd)
+}
+
+func (a *Agent) VerifyChoices(output string, choices []string) bool {
+	return VerifyChoices(output, choices)
+}
+
+func (a *Agent) ChoiceKeys(output, choice string) ([]string, bool) {
+	return ChoiceKeys(output, choice)
+}
+
 func (a *Agent) FilterStatusBar(output string) string {
 	return FilterStatusBar(output)
 }
@@ -0,0 +1,109 @@
+package claude
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/noamsto/houston/internal/ansi"
+)
+
+// MCP server statuses.
+const (
+	MCPConnected = "connected"
+	MCPFailed    = "failed"
+	MCPPending   = "pending"
+	MCPNeedsAuth = "needs-auth"
+	MCPDisabled  = "disabled"
+)
+
+// MCPServer is the last status seen on screen for one MCP server.
+type MCPServer struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"` // Connection error from a failed tool call
+}
+
+// MCPStatus is the MCP server health visible in a Claude pane.
+type MCPStatus struct {
+	Servers []MCPServer `json:"servers,omitempty"`
+	Failed  int         `json:"failed,omitempty"` // From the "N MCP servers failed" notice, which doesn't name them
+}
+
+var (
+	// "⏺ github - create_issue (MCP)(title: "x")"
+	mcpCallPattern = regexp.MustCompile(`^(?:⏺\s*)?([\w.@-]+) - [\w.-]+ \(MCP\)`)
+	// "⎿  Error: MCP error -32000: Connection closed"
+	mcpResultPattern = regexp.MustCompile(`^⎿\s*(.*)$`)
+	// Errors meaning the server is gone, as opposed to a failed request
+	mcpConnErrorPattern = regexp.MustCompile(`(?i)connection closed|not connected|ECONNREFUSED|ECONNRESET|socket hang up|disconnected|transport (?:closed|error)|failed to connect`)
+	// "1 MCP server failed · /mcp"
+	mcpFailedNoticePattern = regexp.MustCompile(`(\d+) MCP servers? failed`)
+	// "MCP server "github" failed to connect", "MCP server github disconnected"
+	mcpServerNoticePattern = regexp.MustCompile(`MCP server ["“']?([\w.@-]+)["”']? (?:failed to connect|has disconnected|disconnected|connection (?:closed|lost))`)
+	// /mcp dialog: "❯ 1. github  ✘ failed · Enter to view details"
+	mcpListPattern = regexp.MustCompile(`^(?:[❯>]\s*)?\d+\.\s+([\w.@-]+)\s+(?:[✔✘◯△⚠✓✗]\s*)?(connected|failed|connecting|needs authentication|disabled)\b`)
+)
+
+var mcpListStatuses = map[string]string{
+	"connected":            MCPConnected,
+	"failed":               MCPFailed,
+	"connecting":           MCPPending,
+	"needs authentication": MCPNeedsAuth,
+	"disabled":             MCPDisabled,
+}
+
+// ParseMCPStatus reads MCP server health from a Claude pane: the /mcp
+// dialog, connection notices, and MCP tool calls whose result is a
+// connection error. Later lines win, so a successful call after a failure
+// reports the server connected again.
+func ParseMCPStatus(output string) MCPStatus {
+	var st MCPStatus
+	index := make(map[string]int)
+	set := func(name, status, errText string) {
+		if i, ok := index[name]; ok {
+			st.Servers[i].Status = status
+			st.Servers[i].Error = errText
+			return
+		}
+		index[name] = len(st.Servers)
+		st.Servers = append(st.Servers, MCPServer{Name: name, Status: status, Error: errText})
+	}
+
+	call := "" // Server of the MCP tool call awaiting its result line
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(ansi.Strip(line))
+		if line == "" {
+			continue
+		}
+
+		if m := mcpCallPattern.FindStringSubmatch(line); m != nil {
+			call = m[1]
+			continue
+		}
+		if call != "" {
+			if m := mcpResultPattern.FindStringSubmatch(line); m != nil {
+				if result := m[1]; strings.HasPrefix(result, "Error") && mcpConnErrorPattern.MatchString(result) {
+					set(call, MCPFailed, result)
+				} else {
+					set(call, MCPConnected, "")
+				}
+				call = ""
+				continue
+			}
+		}
+
+		if m := mcpListPattern.FindStringSubmatch(line); m != nil {
+			set(m[1], mcpListStatuses[m[2]], "")
+			continue
+		}
+		if m := mcpServerNoticePattern.FindStringSubmatch(line); m != nil {
+			set(m[1], MCPFailed, line)
+			continue
+		}
+		if m := mcpFailedNoticePattern.FindStringSubmatch(line); m != nil {
+			st.Failed, _ = strconv.Atoi(m[1])
+		}
+	}
+	return st
+}
@@ -0,0 +1,79 @@
+package claude
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMCPStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   MCPStatus
+	}{
+		{
+			name: "tool call loses its server",
+			output: `⏺ github - list_issues (MCP)(repo: "noamsto/houston")
+  ⎿  [{"number": 12, "title": "Fix parser"}]
+
+⏺ github - create_issue (MCP)(title: "Add tests")
+  ⎿  Error: MCP error -32000: Connection closed
+
+⏺ I couldn't create the issue.`,
+			want: MCPStatus{Servers: []MCPServer{
+				{Name: "github", Status: MCPFailed, Error: "Error: MCP error -32000: Connection closed"},
+			}},
+		},
+		{
+			name: "request error keeps the server connected",
+			output: `⏺ github - create_issue (MCP)(title: "x")
+  ⎿  Error: Validation failed: title is too short`,
+			want: MCPStatus{Servers: []MCPServer{{Name: "github", Status: MCPConnected}}},
+		},
+		{
+			name: "reconnected after failure",
+			output: `⏺ context7 - resolve-library-id (MCP)(libraryName: "react")
+  ⎿  Error: Not connected
+⏺ context7 - resolve-library-id (MCP)(libraryName: "react")
+  ⎿  Available Libraries: ...`,
+			want: MCPStatus{Servers: []MCPServer{{Name: "context7", Status: MCPConnected}}},
+		},
+		{
+			name: "mcp dialog",
+			output: ` Manage MCP servers
+
+ ❯ 1. context7  ✔ connected · Enter to view details
+   2. github    ✘ failed · Enter to view details
+   3. linear    △ needs authentication · Enter to login`,
+			want: MCPStatus{Servers: []MCPServer{
+				{Name: "context7", Status: MCPConnected},
+				{Name: "github", Status: MCPFailed},
+				{Name: "linear", Status: MCPNeedsAuth},
+			}},
+		},
+		{
+			name:   "startup notice",
+			output: "> \n  ? for shortcuts                                2 MCP servers failed · /mcp",
+			want:   MCPStatus{Failed: 2},
+		},
+		{
+			name:   "named notice",
+			output: `  ⎿  MCP server "sentry" disconnected`,
+			want: MCPStatus{Servers: []MCPServer{
+				{Name: "sentry", Status: MCPFailed, Error: `⎿  MCP server "sentry" disconnected`},
+			}},
+		},
+		{
+			name:   "no MCP activity",
+			output: "⏺ Bash(git status)\n  ⎿  On branch main",
+			want:   MCPStatus{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMCPStatus(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMCPStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package claude
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/noamsto/houston/internal/ansi"
+)
+
+// PermissionPrompt is a Claude Code tool permission dialog, e.g.
+//
+//	Bash command
+//
+//	  git status
+//	  Show working tree status
+//
+//	Do you want to proceed?
+//	❯ 1. Yes
+//	  2. Yes, and don't ask again for git status commands in /repo
+//	  3. No, and tell Claude what to do differently (esc)
+type PermissionPrompt struct {
+	Tool     string   `json:"tool"`   // Bash, Edit, Write, Read, WebFetch, or the MCP tool
+	Detail   string   `json:"detail"` // Command, file path or URL the tool acts on
+	Body     []string `json:"body"`   // Every line between the title and the question (wrapped commands, descriptions)
+	Question string   `json:"question"`
+	Choices  []string `json:"choices"`
+	Approve  string   `json:"approve"` // Key that selects the plain "Yes" choice
+}
+
+// permissionTitles maps dialog titles to tool names.
+var permissionTitles = map[string]string{
+	"Bash command": "Bash",
+	"Edit file":    "Edit",
+	"Create file":  "Write",
+	"Write file":   "Write",
+	"Read file":    "Read",
+	"Fetch":        "WebFetch",
+	"Tool use":     "MCP",
+}
+
+var (
+	permissionQuestionPattern = regexp.MustCompile(`^Do you want to (?:proceed|make this edit to|create|allow|overwrite)\b.*\?$`)
+	permissionChoicePattern   = regexp.MustCompile(`^[❯>]?\s*([0-9])\.\s+(.+)$`)
+	// "server - tool_name(args) (MCP)"
+	mcpToolPattern = regexp.MustCompile(`^(\S+)\s+-\s+([^(\s]+)`)
+	// "Do you want to make this edit to foo.go?"
+	permissionFilePattern = regexp.MustCompile(`(?:edit to|create|overwrite) (.+)\?$`)
+)
+
+// permissionLine strips colors and dialog borders from a screen line.
+func permissionLine(line string) string {
+	line = strings.TrimSpace(ansi.Strip(line))
+	line = strings.TrimPrefix(line, "│")
+	line = strings.TrimSuffix(line, "│")
+	return strings.TrimSpace(line)
+}
+
+// ParsePermissionPrompt finds an open permission dialog at the bottom of a
+// pane's output. ok is false when the screen shows no dialog, or one
+// without a plain "Yes" choice.
+func ParsePermissionPrompt(output string) (PermissionPrompt, bool) {
+	lines := strings.Split(output, "\n")
+	for i := range lines {
+		lines[i] = permissionLine(lines[i])
+	}
+
+	// The question is the last one on screen, followed only by its choices.
+	q := -1
+	for i := len(lines) - 1; i >= 0 && i >= len(lines)-40; i-- {
+		if permissionQuestionPattern.MatchString(lines[i]) {
+			q = i
+			break
+		}
+	}
+	if q < 0 {
+		return PermissionPrompt{}, false
+	}
+
+	p := PermissionPrompt{Question: lines[q]}
+	for _, line := range lines[q+1:] {
+		m := permissionChoicePattern.FindStringSubmatch(line)
+		if m == nil {
+			if len(p.Choices) > 0 {
+				break
+			}
+			continue
+		}
+		p.Choices = append(p.Choices, m[2])
+		if p.Approve == "" && m[2] == "Yes" {
+			p.Approve = m[1]
+		}
+	}
+	if p.Approve == "" || len(p.Choices) < 2 {
+		return PermissionPrompt{}, false
+	}
+
+	// The title sits above the detail, a few lines up from the question.
+	for i := q - 1; i >= 0 && i >= q-30; i-- {
+		tool, ok := permissionTitles[lines[i]]
+		if !ok {
+			continue
+		}
+		p.Tool = tool
+		for _, line := range lines[i+1 : q] {
+			if line = strings.Trim(line, "╭╮╰╯─│ "); line != "" {
+				p.Body = append(p.Body, line)
+			}
+		}
+		if len(p.Body) > 0 {
+			p.Detail = p.Body[0]
+		}
+		break
+	}
+	if p.Tool == "" {
+		return PermissionPrompt{}, false
+	}
+
+	switch p.Tool {
+	case "MCP":
+		if m := mcpToolPattern.FindStringSubmatch(p.Detail); m != nil {
+			p.Tool = "mcp__" + m[1] + "__" + m[2]
+		}
+	case "Edit", "Write":
+		// The question names the file; the box above may show its full path.
+		if m := permissionFilePattern.FindStringSubmatch(p.Question); m != nil && !strings.HasSuffix(p.Detail, m[1]) {
+			p.Detail = m[1]
+		}
+	case "Read", "WebFetch":
+		// Some versions show the call, e.g. "Read(/path/to/file)"
+		if open := strings.Index(p.Detail, "("); open > 0 && strings.HasSuffix(p.Detail, ")") {
+			p.Detail = p.Detail[open+1 : len(p.Detail)-1]
+		}
+	}
+	return p, true
+}
@@ -0,0 +1,97 @@
+package claude
+
+import "testing"
+
+func TestParsePermissionPrompt(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantOK     bool
+		wantTool   string
+		wantDetail string
+	}{
+		{
+			name: "bash",
+			output: `● Checking the tree.
+
+ Bash command
+
+   git status --short
+   Show working tree status
+
+ Do you want to proceed?
+ ❯ 1. Yes
+   2. Yes, and don't ask again for git status commands in /home/me/work/app
+   3. No, and tell Claude what to do differently (esc)
+`,
+			wantOK: true, wantTool: "Bash", wantDetail: "git status --short",
+		},
+		{
+			name: "bordered bash",
+			output: `╭──────────────────────────────────────────╮
+│ Bash command                             │
+│                                          │
+│   go test ./...                          │
+│   Run tests                              │
+│                                          │
+│ Do you want to proceed?                  │
+│ ❯ 1. Yes                                 │
+│   2. No, and tell Claude what to do (esc)│
+╰──────────────────────────────────────────╯`,
+			wantOK: true, wantTool: "Bash", wantDetail: "go test ./...",
+		},
+		{
+			name: "edit",
+			output: ` Edit file
+ ╭────────────────────╮
+ │ server/api.go      │
+ ╰────────────────────╯
+ Do you want to make this edit to api.go?
+ ❯ 1. Yes
+   2. Yes, allow all edits during this session (shift+tab)
+   3. No, and tell Claude what to do differently (esc)`,
+			wantOK: true, wantTool: "Edit", wantDetail: "server/api.go",
+		},
+		{
+			name: "mcp",
+			output: ` Tool use
+
+   github - create_issue(title: "x") (MCP)
+
+ Do you want to proceed?
+ ❯ 1. Yes
+   2. No, and tell Claude what to do differently (esc)`,
+			wantOK: true, wantTool: "mcp__github__create_issue", wantDetail: `github - create_issue(title: "x") (MCP)`,
+		},
+		{
+			name: "plain question",
+			output: `Which approach should I take?
+1. Refactor
+2. Rewrite`,
+			wantOK: false,
+		},
+		{
+			name:   "no prompt",
+			output: "● Done.\n> ",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := ParsePermissionPrompt(tt.output)
+			if ok != tt.wantOK {
+				t.Fatalf("ParsePermissionPrompt() ok = %v, want %v (%+v)", ok, tt.wantOK, p)
+			}
+			if !ok {
+				return
+			}
+			if p.Tool != tt.wantTool || p.Detail != tt.wantDetail {
+				t.Errorf("ParsePermissionPrompt() = %q %q, want %q %q", p.Tool, p.Detail, tt.wantTool, tt.wantDetail)
+			}
+			if p.Approve != "1" {
+				t.Errorf("Approve = %q, want %q", p.Approve, "1")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package claude
+
+import "strings"
+
+// modelPrice is a model's list price in USD per million tokens. Cache
+// writes are priced at the five-minute rate.
+type modelPrice struct {
+	input, output, cacheWrite, cacheRead float64
+}
+
+// modelPrices maps model id prefixes to their prices; the first matching
+// prefix wins, so more specific ones come first.
+var modelPrices = []struct {
+	prefix string
+	price  modelPrice
+}{
+	{"claude-opus-4-5", modelPrice{5, 25, 6.25, 0.5}},
+	{"claude-opus-4", modelPrice{15, 75, 18.75, 1.5}},
+	{"claude-3-opus", modelPrice{15, 75, 18.75, 1.5}},
+	{"claude-sonnet-4", modelPrice{3, 15, 3.75, 0.3}},
+	{"claude-3-7-sonnet", modelPrice{3, 15, 3.75, 0.3}},
+	{"claude-3-5-sonnet", modelPrice{3, 15, 3.75, 0.3}},
+	{"claude-haiku-4-5", modelPrice{1, 5, 1.25, 0.1}},
+	{"claude-3-5-haiku", modelPrice{0.8, 4, 1, 0.08}},
+	{"claude-3-haiku", modelPrice{0.25, 1.25, 0.3, 0.03}},
+}
+
+// UsageCost prices usage of model at its list price, and reports false for
+// a model it has no price for.
+func UsageCost(model string, u Usage) (float64, bool) {
+	for _, p := range modelPrices {
+		if strings.HasPrefix(model, p.prefix) {
+			cost := float64(u.InputTokens)*p.price.input +
+				float64(u.OutputTokens)*p.price.output +
+				float64(u.CacheCreationInputTokens)*p.price.cacheWrite +
+				float64(u.CacheReadInputTokens)*p.price.cacheRead
+			return cost / 1e6, true
+		}
+	}
+	return 0, false
+}
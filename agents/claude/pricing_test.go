@@ -0,0 +1,27 @@
+package claude
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUsageCost(t *testing.T) {
+	u := Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheCreationInputTokens: 200_000, CacheReadInputTokens: 2_000_000}
+	tests := []struct {
+		model string
+		want  float64
+		ok    bool
+	}{
+		{"claude-opus-4-5-20251101", 5 + 2.5 + 1.25 + 1, true},
+		{"claude-opus-4-1-20250805", 15 + 7.5 + 3.75 + 3, true},
+		{"claude-sonnet-4-5-20250929", 3 + 1.5 + 0.75 + 0.6, true},
+		{"claude-haiku-4-5-20251001", 1 + 0.5 + 0.25 + 0.2, true},
+		{"gpt-5", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := UsageCost(tt.model, u)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("UsageCost(%s) = %v, %v; want %v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// reverseBlockSize is how much of a session file is read per step when
+// scanning it backwards from the end.
+const reverseBlockSize = 64 * 1024
+
+// maxTailEntries bounds the number of session files whose tail is cached.
+const maxTailEntries = 256
+
+// sessionTail is the cached end of a session file: its last messages, up
+// to offset, the end of the last complete line parsed.
+type sessionTail struct {
+	size     int64
+	modTime  time.Time
+	offset   int64
+	keep     int
+	messages []Message
+}
+
+var tails = struct {
+	sync.Mutex
+	entries map[string]*sessionTail
+}{entries: make(map[string]*sessionTail)}
+
+// ReadLastMessages reads the last N messages from a session file. Session
+// files only grow, so the tail is cached: an unchanged file isn't read
+// again, and a grown one only has its appended lines parsed. The first read
+// scans backwards from the end, so the size of the file doesn't matter.
+// Only complete (newline-terminated) lines are returned.
+func ReadLastMessages(path string, n int) ([]Message, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening session file: %w", err)
+	}
+
+	tails.Lock()
+	cached, ok := tails.entries[path]
+	var tail sessionTail
+	if ok {
+		tail = *cached
+	}
+	tails.Unlock()
+
+	switch {
+	case ok && tail.keep >= n && info.Size() == tail.size && info.ModTime().Equal(tail.modTime):
+		return lastN(tail.messages, n), nil
+	case ok && tail.keep >= n && info.Size() >= tail.offset:
+		appended, offset, err := readAppended(path, tail.offset)
+		if err == nil {
+			tail.messages = lastN(append(tail.messages[:len(tail.messages):len(tail.messages)], appended...), tail.keep)
+			tail.offset = offset
+			break
+		}
+		// Rewritten rather than appended to: read it again
+		fallthrough
+	default:
+		messages, offset, err := readLastLines(path, info.Size(), n)
+		if err != nil {
+			return nil, err
+		}
+		tail = sessionTail{offset: offset, keep: n, messages: messages}
+	}
+	tail.size = info.Size()
+	tail.modTime = info.ModTime()
+
+	tails.Lock()
+	if _, ok := tails.entries[path]; !ok && len(tails.entries) >= maxTailEntries {
+		for key := range tails.entries {
+			delete(tails.entries, key)
+			break
+		}
+	}
+	tails.entries[path] = &tail
+	tails.Unlock()
+
+	return lastN(tail.messages, n), nil
+}
+
+// readLastLines returns the last n messages of a file of the given size,
+// reading it backwards in blocks, and the offset after the last complete
+// line.
+func readLastLines(path string, size int64, n int) ([]Message, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening session file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var (
+		reversed []Message
+		offset   = int64(-1) // After the last newline, once found
+		pending  []byte      // Start of the line the previous block ended in
+		pos      = size
+	)
+	for pos > 0 && len(reversed) < n {
+		start := max(pos-reverseBlockSize, 0)
+		block := make([]byte, pos-start, int(pos-start)+len(pending))
+		if _, err := f.ReadAt(block, start); err != nil && err != io.EOF {
+			return nil, 0, fmt.Errorf("reading session file: %w", err)
+		}
+		data := append(block, pending...)
+		pos = start
+
+		for len(reversed) < n {
+			i := bytes.LastIndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			line := data[i+1:]
+			data = data[:i]
+			if offset < 0 {
+				// Bytes after the last newline are a line still being written
+				offset = pos + int64(i) + 1
+				continue
+			}
+			if msg, ok := parseLine(line); ok {
+				reversed = append(reversed, msg)
+			}
+		}
+		pending = data
+	}
+	// The first line of the file has no newline before it
+	if pos == 0 && offset >= 0 && len(reversed) < n {
+		if msg, ok := parseLine(pending); ok {
+			reversed = append(reversed, msg)
+		}
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	messages := make([]Message, len(reversed))
+	for i, msg := range reversed {
+		messages[len(reversed)-1-i] = msg
+	}
+	return messages, offset, nil
+}
+
+// readAppended parses the complete lines written after offset, which must
+// follow a newline, and returns the offset after the last one.
+func readAppended(path string, offset int64) ([]Message, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("opening session file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	start := offset
+	if offset > 0 {
+		start-- // Check the newline the cached tail ended with
+	}
+	data, err := io.ReadAll(io.NewSectionReader(f, start, 1<<62))
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading session file: %w", err)
+	}
+	if offset > 0 {
+		if len(data) == 0 || data[0] != '\n' {
+			return nil, 0, fmt.Errorf("session file rewritten")
+		}
+		data = data[1:]
+	}
+
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, offset, nil
+	}
+	var messages []Message
+	for _, line := range bytes.Split(data[:end], []byte{'\n'}) {
+		if msg, ok := parseLine(line); ok {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, offset + int64(end) + 1, nil
+}
+
+// parseLine decodes one JSONL line, skipping blank lines, invalid JSON and
+// file history snapshots like ReadMessages does.
+func parseLine(line []byte) (Message, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return Message{}, false
+	}
+	var msg Message
+	if err := json.Unmarshal(line, &msg); err != nil || msg.Type == "file-history-snapshot" {
+		return Message{}, false
+	}
+	return msg, true
+}
+
+// lastN returns a copy of the last n messages.
+func lastN(messages []Message, n int) []Message {
+	if len(messages) > n {
+		messages = messages[len(messages)-n:]
+	}
+	return append([]Message(nil), messages...)
+}
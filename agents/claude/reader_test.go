@@ -0,0 +1,82 @@
+package claude
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func userLine(i int) string {
+	return fmt.Sprintf(`{"type":"user","uuid":"u%d","message":{"role":"user","content":"%s"}}`, i, strings.Repeat("x", 200))
+}
+
+func uuids(messages []Message) string {
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.UUID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestReadLastMessages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	var lines []string
+	for i := 0; i < 2000; i++ { // Several read blocks
+		lines = append(lines, userLine(i))
+	}
+	lines = append(lines, `{"type":"file-history-snapshot"}`, "not json", "")
+	writeLines(t, path, lines...)
+
+	got, err := ReadLastMessages(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := uuids(got); ids != "u1997,u1998,u1999" {
+		t.Errorf("last messages = %s", ids)
+	}
+
+	// Appended lines are picked up; a partial line is left for later
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := userLine(2001)
+	if _, err := f.WriteString(userLine(2000) + "\n" + partial[:50]); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = ReadLastMessages(path, 3)
+	if ids := uuids(got); ids != "u1998,u1999,u2000" {
+		t.Errorf("after append = %s", ids)
+	}
+	if _, err := f.WriteString(partial[50:] + "\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	got, _ = ReadLastMessages(path, 3)
+	if ids := uuids(got); ids != "u1999,u2000,u2001" {
+		t.Errorf("after completing line = %s", ids)
+	}
+
+	// A truncated file is read again
+	writeLines(t, path, userLine(7), userLine(8))
+	got, _ = ReadLastMessages(path, 3)
+	if ids := uuids(got); ids != "u7,u8" {
+		t.Errorf("after truncation = %s", ids)
+	}
+}
+
+func TestReadLastMessagesLongLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	long := fmt.Sprintf(`{"type":"assistant","uuid":"long","message":{"role":"assistant","content":"%s"}}`, strings.Repeat("y", 3*reverseBlockSize))
+	writeLines(t, path, userLine(0), long, userLine(1))
+
+	got, err := ReadLastMessages(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := uuids(got); ids != "u0,long,u1" {
+		t.Errorf("messages = %s", ids)
+	}
+}
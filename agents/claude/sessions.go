@@ -0,0 +1,131 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SessionInfo summarizes a Claude Code conversation of a project.
+type SessionInfo struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`             // Summary, or the start of the first prompt
+	Summary      string    `json:"summary,omitempty"` // Written by Claude when the conversation was summarized
+	FirstPrompt  string    `json:"first_prompt,omitempty"`
+	CWD          string    `json:"cwd,omitempty"`
+	GitBranch    string    `json:"git_branch,omitempty"`
+	LastActivity time.Time `json:"last_activity"`
+	Messages     int       `json:"messages"` // User prompts and assistant replies
+}
+
+// sessionIDPattern matches session file names, which `claude --resume`
+// takes as its argument.
+var sessionIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidSessionID reports whether id can name a session file.
+func ValidSessionID(id string) bool {
+	return sessionIDPattern.MatchString(id)
+}
+
+// ListSessions returns the conversations recorded for cwd, most recent
+// first. Subagent transcripts are not listed.
+func ListSessions(cwd string) ([]SessionInfo, error) {
+	projectDir := ProjectDir(cwd)
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []SessionInfo{}
+	for _, e := range entries {
+		name := e.Name()
+		id := strings.TrimSuffix(name, ".jsonl")
+		if e.IsDir() || !strings.HasSuffix(name, ".jsonl") || strings.HasPrefix(name, "agent-") || !ValidSessionID(id) {
+			continue
+		}
+		messages, err := ReadMessages(filepath.Join(projectDir, name))
+		if err != nil {
+			continue
+		}
+		info := summarizeSession(id, messages)
+		if info.Messages == 0 {
+			continue // Only snapshots or metadata, nothing to resume
+		}
+		if info.LastActivity.IsZero() {
+			if fi, err := e.Info(); err == nil {
+				info.LastActivity = fi.ModTime()
+			}
+		}
+		sessions = append(sessions, info)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastActivity.After(sessions[j].LastActivity)
+	})
+	return sessions, nil
+}
+
+// FindSession returns the path of session id of cwd's project, if it exists.
+func FindSession(cwd, id string) (string, bool) {
+	if !ValidSessionID(id) || strings.HasPrefix(id, "agent-") {
+		return "", false
+	}
+	path := filepath.Join(ProjectDir(cwd), id+".jsonl")
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// summarizeSession describes a session from its messages.
+func summarizeSession(id string, messages []Message) SessionInfo {
+	info := SessionInfo{ID: id}
+	for _, msg := range messages {
+		if msg.CWD != "" {
+			info.CWD = msg.CWD
+		}
+		if msg.GitBranch != "" {
+			info.GitBranch = msg.GitBranch
+		}
+		if msg.Timestamp.After(info.LastActivity) {
+			info.LastActivity = msg.Timestamp
+		}
+		if msg.Type == "summary" && msg.Summary != "" {
+			info.Summary = msg.Summary
+			continue
+		}
+		if msg.IsMeta || (msg.Type != "user" && msg.Type != "assistant") {
+			continue
+		}
+		if msg.Type == "user" {
+			if isToolResult(msg.Message.Content) {
+				continue
+			}
+			if info.FirstPrompt == "" && !msg.IsCompactSummary {
+				info.FirstPrompt = firstText(msg.Message.Content)
+			}
+		}
+		info.Messages++
+	}
+
+	info.Title = info.Summary
+	if info.Title == "" {
+		info.Title = promptTitle(info.FirstPrompt)
+	}
+	return info
+}
+
+// firstText returns the first text block of message content, skipping
+// slash-command and caveat markup.
+func firstText(content any) string {
+	for _, block := range parseContentBlocks(content) {
+		text := strings.TrimSpace(block.Text)
+		if block.Type == "text" && text != "" && !strings.HasPrefix(text, "<") {
+			return text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestListSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := ProjectDir("/work/repo")
+
+	writeLines(t, filepath.Join(dir, "old.jsonl"),
+		`{"type":"user","sessionId":"old","cwd":"/work/repo","gitBranch":"main","timestamp":"2026-01-01T10:00:00Z","message":{"role":"user","content":"<command-name>/init</command-name>"}}`,
+		`{"type":"user","sessionId":"old","timestamp":"2026-01-01T10:00:01Z","message":{"role":"user","content":"Add a login page\nwith OAuth"}}`,
+		`{"type":"assistant","sessionId":"old","timestamp":"2026-01-01T10:01:00Z","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Read","input":{}}]}}`,
+		`{"type":"user","sessionId":"old","timestamp":"2026-01-01T10:01:01Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"..."}]}}`,
+	)
+	writeLines(t, filepath.Join(dir, "new.jsonl"),
+		`{"type":"summary","summary":"Fix flaky tests"}`,
+		`{"type":"user","sessionId":"new","timestamp":"2026-02-01T10:00:00Z","message":{"role":"user","content":"Tests fail"}}`,
+	)
+	writeLines(t, filepath.Join(dir, "empty.jsonl"), `{"type":"file-history-snapshot"}`)
+	writeLines(t, filepath.Join(dir, "agent-x.jsonl"),
+		`{"type":"user","sessionId":"new","message":{"role":"user","content":"Subagent work"}}`,
+	)
+
+	sessions, err := ListSessions("/work/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2: %+v", len(sessions), sessions)
+	}
+
+	if s := sessions[0]; s.ID != "new" || s.Title != "Fix flaky tests" || s.Messages != 1 {
+		t.Errorf("newest session = %+v", s)
+	}
+	s := sessions[1]
+	if s.ID != "old" || s.Title != "Add a login page" || s.Messages != 3 || s.CWD != "/work/repo" || s.GitBranch != "main" {
+		t.Errorf("older session = %+v", s)
+	}
+	if want := time.Date(2026, 1, 1, 10, 1, 1, 0, time.UTC); !s.LastActivity.Equal(want) {
+		t.Errorf("LastActivity = %v, want %v", s.LastActivity, want)
+	}
+}
+
+func TestFindSession(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	writeLines(t, filepath.Join(ProjectDir("/work/repo"), "abc-123.jsonl"), `{}`)
+	if err := os.WriteFile(filepath.Join(ProjectDir("/work/repo"), "agent-1.jsonl"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[string]bool{
+		"abc-123":       true,
+		"missing":       false,
+		"agent-1":       false,
+		"../abc-123":    false,
+		"abc-123; rm x": false,
+	} {
+		if _, ok := FindSession("/work/repo", id); ok != want {
+			t.Errorf("FindSession(%q) = %v, want %v", id, ok, want)
+		}
+	}
+}
@@ -25,6 +25,9 @@ type Message struct {
 	Todos      []Todo    `json:"todos"`
 	Message    MessageContent `json:"message"`
 	Summary    string `json:"summary"`
+	IsMeta     bool   `json:"isMeta"`
+
+	IsCompactSummary bool `json:"isCompactSummary"` // User message injected by /compact
 }
 
 // MessageContent represents the content of a user or assistant message.
@@ -49,8 +52,10 @@ type ContentBlock struct {
 
 // Usage tracks token usage.
 type Usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 }
 
 // Todo represents a todo item.
@@ -124,8 +129,8 @@ func FindLatestSession(projectDir string) (string, error) {
 	return filepath.Join(projectDir, sessions[0].Name()), nil
 }
 
-// ReadLastMessages reads the last N messages from a session file.
-func ReadLastMessages(path string, n int) ([]Message, error) {
+// ReadMessages reads every message from a session file.
+func ReadMessages(path string) ([]Message, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("opening session file: %w", err)
@@ -158,10 +163,7 @@ func ReadLastMessages(path string, n int) ([]Message, error) {
 		return nil, fmt.Errorf("scanning session file: %w", err)
 	}
 
-	if len(messages) <= n {
-		return messages, nil
-	}
-	return messages[len(messages)-n:], nil
+	return messages, nil
 }
 
 // GetSessionState analyzes messages and returns the current session state.
@@ -259,13 +261,10 @@ func GetStateFromFiles(cwd string) (*parser.Result, error) {
 		return nil, err
 	}
 
-	messages, err := ReadLastMessages(sessionPath, 50)
+	result, err := readState(sessionPath)
 	if err != nil {
 		return nil, err
 	}
-
-	state := GetSessionState(messages)
-	result := state.ToParserResult()
 	return &result, nil
 }
 
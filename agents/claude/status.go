@@ -0,0 +1,89 @@
+package claude
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/noamsto/houston/internal/ansi"
+)
+
+// ClaudeStatus contains parsed Claude status bar information.
+type ClaudeStatus struct {
+	Model          string  `json:"model,omitempty"`           // e.g., "Sonnet 4.5"
+	ContextTokens  int     `json:"context_tokens,omitempty"`  // e.g., 50000 for "50k"
+	ContextLimit   int     `json:"context_limit,omitempty"`   // e.g., 200000 for "200k"
+	ContextPercent float64 `json:"context_percent,omitempty"` // Shown, or computed from tokens and limit
+	Messages       int     `json:"messages,omitempty"`        // e.g., 43 for "💬 43 msgs"
+	Cost           string  `json:"cost,omitempty"`            // e.g., "$1.24"
+}
+
+var (
+	// Parse model: 🤖 Sonnet 4.5 | 📊 ...
+	statusModelPattern = regexp.MustCompile(`🤖\s*([^|│📊⏱💬$\n]+)`)
+
+	// Parse context: 📊 50k/200k (25.0%)
+	// Groups: 1=used, 2=limit, 3=percent (optional)
+	statusContextPattern = regexp.MustCompile(`(\d+(?:\.\d+)?[kKmM]?)\s*/\s*(\d+(?:\.\d+)?[kKmM])\b(?:\s*\((\d+(?:\.\d+)?)%\))?`)
+
+	statusMessagesPattern = regexp.MustCompile(`(\d+)\s*msgs?\b`)
+	statusCostPattern     = regexp.MustCompile(`\$\d+(?:\.\d+)?`)
+)
+
+// ParseStatus extracts structured status information from Claude's status
+// bar, as returned by ExtractStatusLine.
+func ParseStatus(statusLine string) ClaudeStatus {
+	status := ClaudeStatus{}
+
+	// Strip ANSI codes before parsing - they interfere with regex matching
+	statusLine = ansi.Strip(statusLine)
+
+	if match := statusModelPattern.FindStringSubmatch(statusLine); len(match) > 1 {
+		status.Model = strings.TrimSpace(match[1])
+	}
+
+	// Prefer the 📊 segment; other segments may hold fractions too (paths)
+	context := statusLine
+	if i := strings.Index(statusLine, "📊"); i >= 0 {
+		context = statusLine[i:]
+	}
+	if match := statusContextPattern.FindStringSubmatch(context); len(match) > 2 {
+		status.ContextTokens = parseTokenCount(match[1])
+		status.ContextLimit = parseTokenCount(match[2])
+		if match[3] != "" {
+			status.ContextPercent, _ = strconv.ParseFloat(match[3], 64)
+		} else if status.ContextLimit > 0 {
+			status.ContextPercent = float64(status.ContextTokens) * 100 / float64(status.ContextLimit)
+		}
+	}
+
+	if match := statusMessagesPattern.FindStringSubmatch(statusLine); len(match) > 1 {
+		status.Messages, _ = strconv.Atoi(match[1])
+	}
+	status.Cost = statusCostPattern.FindString(statusLine)
+
+	return status
+}
+
+// Found reports whether anything was parsed.
+func (s ClaudeStatus) Found() bool {
+	return s != ClaudeStatus{}
+}
+
+// parseTokenCount reads a token count such as "50k", "1.2M" or "800".
+func parseTokenCount(s string) int {
+	mult := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"), strings.HasSuffix(s, "K"):
+		mult = 1e3
+		s = s[:len(s)-1]
+	case strings.HasSuffix(s, "m"), strings.HasSuffix(s, "M"):
+		mult = 1e6
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return int(n * mult)
+}
@@ -0,0 +1,62 @@
+package claude
+
+import "testing"
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected ClaudeStatus
+	}{
+		{
+			name:  "full status bar",
+			input: "❄ impure 📂 ~/path  main ≡!+  🤖 Sonnet 4.5 | 📊 50k/200k (25.0%) | ⏱️  0.05h | 💬 43 msgs",
+			expected: ClaudeStatus{
+				Model:          "Sonnet 4.5",
+				ContextTokens:  50000,
+				ContextLimit:   200000,
+				ContextPercent: 25,
+				Messages:       43,
+			},
+		},
+		{
+			name:  "percent computed",
+			input: "\x1b[1m\x1b[34m🤖\x1b[0m Opus 4.6 | 📊 151k/200k | 💰 $3.20",
+			expected: ClaudeStatus{
+				Model:          "Opus 4.6",
+				ContextTokens:  151000,
+				ContextLimit:   200000,
+				ContextPercent: 75.5,
+				Cost:           "$3.20",
+			},
+		},
+		{
+			name:  "multi-line with million-token context",
+			input: "🤖 Sonnet 4.5 [1m]\n📊 1.2k/1M (0.1%) | 💬 1 msg",
+			expected: ClaudeStatus{
+				Model:          "Sonnet 4.5 [1m]",
+				ContextTokens:  1200,
+				ContextLimit:   1000000,
+				ContextPercent: 0.1,
+				Messages:       1,
+			},
+		},
+		{
+			name:     "no status bar",
+			input:    "-- INSERT --",
+			expected: ClaudeStatus{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseStatus(tt.input)
+			if got != tt.expected {
+				t.Errorf("ParseStatus() = %+v, want %+v", got, tt.expected)
+			}
+			if got.Found() != (tt.expected != ClaudeStatus{}) {
+				t.Errorf("Found() = %v", got.Found())
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package claude
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Subagent status values.
+const (
+	SubagentWorking = "working"
+	SubagentDone    = "done"
+	SubagentStopped = "stopped" // Went quiet without finishing (interrupted)
+)
+
+const (
+	// subagentRecent is how long a subagent stays listed after its last
+	// write, so a fan-out that just finished still shows its results.
+	subagentRecent = 10 * time.Minute
+
+	// subagentStale is how long a working subagent may stay silent before
+	// it is considered stopped.
+	subagentStale = 3 * time.Minute
+
+	// taskCallWindow is how many of the parent session's last messages are
+	// searched for the Task calls that started its recent subagents.
+	taskCallWindow = 1000
+)
+
+// Subagent is a helper agent a Claude session started with the Task tool.
+type Subagent struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`                  // Subagent type, or the start of its prompt
+	Description  string    `json:"description,omitempty"` // Short task description given to Task
+	Tool         string    `json:"tool,omitempty"`        // Tool it is running, while working
+	Activity     string    `json:"activity"`
+	Status       string    `json:"status"` // "working", "done", "stopped"
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// taskCall is a Task tool call of the parent session.
+type taskCall struct {
+	description  string
+	subagentType string
+	finished     bool // The parent got its tool_result
+}
+
+// Subagents returns the recently active subagents of the latest session for
+// cwd, working ones first.
+func Subagents(cwd string) ([]Subagent, error) {
+	projectDir := ProjectDir(cwd)
+	sessionPath, err := FindLatestSession(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	sessionID := strings.TrimSuffix(filepath.Base(sessionPath), ".jsonl")
+
+	files := subagentFiles(projectDir, sessionID, time.Now().Add(-subagentRecent))
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	messages, err := ReadLastMessages(sessionPath, taskCallWindow)
+	if err != nil {
+		return nil, err
+	}
+	calls := taskCalls(messages)
+
+	var subagents []Subagent
+	for _, path := range files {
+		msgs, err := ReadMessages(path)
+		if err != nil || len(msgs) == 0 {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		subagents = append(subagents, buildSubagent(path, msgs, calls, info.ModTime()))
+	}
+
+	sort.SliceStable(subagents, func(i, j int) bool {
+		wi, wj := subagents[i].Status == SubagentWorking, subagents[j].Status == SubagentWorking
+		if wi != wj {
+			return wi
+		}
+		return subagents[i].LastActivity.After(subagents[j].LastActivity)
+	})
+	return subagents, nil
+}
+
+// subagentFiles lists the subagent transcripts of a session written since
+// cutoff. Claude Code keeps them next to the session as agent-*.jsonl, or
+// in <session>/subagents/ in newer versions; the former are shared by every
+// session of the project and are matched by their sessionId.
+func subagentFiles(projectDir, sessionID string, cutoff time.Time) []string {
+	var files []string
+	recent := func(dir string, match func(path string) bool) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasPrefix(name, "agent-") || !strings.HasSuffix(name, ".jsonl") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil || info.ModTime().Before(cutoff) {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			if match == nil || match(path) {
+				files = append(files, path)
+			}
+		}
+	}
+
+	recent(filepath.Join(projectDir, sessionID, "subagents"), nil)
+	recent(projectDir, func(path string) bool { return fileSessionID(path) == sessionID })
+	return files
+}
+
+// fileSessionID reads the session ID from the first line of a transcript.
+func fileSessionID(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var msg Message
+		if json.Unmarshal(scanner.Bytes(), &msg) == nil && msg.SessionID != "" {
+			return msg.SessionID
+		}
+	}
+	return ""
+}
+
+// taskCalls indexes the parent session's Task calls by prompt, which is
+// what a subagent transcript starts with.
+func taskCalls(messages []Message) map[string]*taskCall {
+	calls := make(map[string]*taskCall)
+	byID := make(map[string]*taskCall)
+	for _, msg := range messages {
+		switch msg.Type {
+		case "assistant":
+			for _, block := range parseContentBlocks(msg.Message.Content) {
+				if block.Type != "tool_use" || (block.Name != "Task" && block.Name != "Agent") {
+					continue
+				}
+				prompt, _ := block.Input["prompt"].(string)
+				if prompt == "" {
+					continue
+				}
+				call := &taskCall{}
+				call.description, _ = block.Input["description"].(string)
+				call.subagentType, _ = block.Input["subagent_type"].(string)
+				calls[strings.TrimSpace(prompt)] = call
+				byID[block.ID] = call
+			}
+		case "user":
+			for id, call := range byID {
+				if hasToolResultFor(msg.Message.Content, id) {
+					call.finished = true
+					delete(byID, id)
+				}
+			}
+		}
+	}
+	return calls
+}
+
+// buildSubagent describes a subagent from its transcript and the Task call
+// that started it, if found.
+func buildSubagent(path string, messages []Message, calls map[string]*taskCall, modTime time.Time) Subagent {
+	sub := Subagent{
+		ID:           strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "agent-"), ".jsonl"),
+		LastActivity: modTime,
+	}
+
+	var prompt string
+	for _, msg := range messages {
+		if msg.Type == "user" && !isToolResult(msg.Message.Content) {
+			for _, block := range parseContentBlocks(msg.Message.Content) {
+				if block.Type == "text" {
+					prompt = strings.TrimSpace(block.Text)
+					break
+				}
+			}
+			break
+		}
+	}
+
+	call := calls[prompt]
+	if call != nil {
+		sub.Name = call.subagentType
+		sub.Description = call.description
+	}
+	if sub.Name == "" {
+		sub.Name = promptTitle(prompt)
+	}
+	if sub.Name == "" {
+		sub.Name = "subagent"
+	}
+
+	state := GetSessionState(messages)
+	if !state.LastActivity.IsZero() {
+		sub.LastActivity = state.LastActivity
+	}
+	switch {
+	case (call != nil && call.finished) || (state.IsWaiting && !state.IsWorking):
+		sub.Status = SubagentDone
+		sub.Activity = "Done"
+	case time.Since(modTime) > subagentStale:
+		sub.Status = SubagentStopped
+		sub.Activity = "Stopped"
+	default:
+		sub.Status = SubagentWorking
+		sub.Tool = state.LastToolName
+		sub.Activity = state.Activity()
+	}
+	return sub
+}
+
+// promptTitle shortens a prompt's first line into a name.
+func promptTitle(prompt string) string {
+	title, _, _ := strings.Cut(prompt, "\n")
+	title = strings.TrimSpace(title)
+	if r := []rune(title); len(r) > 60 {
+		return string(r[:57]) + "..."
+	}
+	return title
+}
@@ -0,0 +1,75 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubagents(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cwd := "/work/repo"
+	dir := ProjectDir(cwd)
+
+	writeLines(t, filepath.Join(dir, "s1.jsonl"),
+		`{"type":"user","sessionId":"s1","message":{"role":"user","content":"Audit the handlers"}}`,
+		`{"type":"assistant","sessionId":"s1","message":{"role":"assistant","stop_reason":"tool_use","content":[`+
+			`{"type":"tool_use","id":"t1","name":"Task","input":{"description":"Review API","subagent_type":"code-reviewer","prompt":"Review server/api.go"}},`+
+			`{"type":"tool_use","id":"t2","name":"Task","input":{"description":"Find tests","prompt":"List the tests\nthat cover sessions"}}]}}`,
+		`{"type":"user","sessionId":"s1","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t2","content":"done"}]}}`,
+	)
+	// Still working, in the newer per-session layout
+	writeLines(t, filepath.Join(dir, "s1", "subagents", "agent-a1.jsonl"),
+		`{"type":"user","sessionId":"s1","isSidechain":true,"message":{"role":"user","content":"Review server/api.go"}}`,
+		`{"type":"assistant","sessionId":"s1","message":{"role":"assistant","stop_reason":"tool_use","content":[{"type":"tool_use","id":"x","name":"Grep","input":{}}]}}`,
+	)
+	// Finished (its Task call has a result), in the older flat layout
+	writeLines(t, filepath.Join(dir, "agent-a2.jsonl"),
+		`{"type":"user","sessionId":"s1","isSidechain":true,"message":{"role":"user","content":"List the tests\nthat cover sessions"}}`,
+		`{"type":"assistant","sessionId":"s1","message":{"role":"assistant","stop_reason":"end_turn","content":[{"type":"text","text":"Found 3."}]}}`,
+	)
+	// Another session's subagent
+	writeLines(t, filepath.Join(dir, "agent-a3.jsonl"),
+		`{"type":"user","sessionId":"s0","isSidechain":true,"message":{"role":"user","content":"Old work"}}`,
+	)
+
+	subs, err := Subagents(cwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("got %d subagents, want 2: %+v", len(subs), subs)
+	}
+
+	if s := subs[0]; s.ID != "a1" || s.Name != "code-reviewer" || s.Description != "Review API" ||
+		s.Status != SubagentWorking || s.Tool != "Grep" || s.Activity != "Searching content" {
+		t.Errorf("working subagent = %+v", s)
+	}
+	if s := subs[1]; s.ID != "a2" || s.Name != "List the tests" || s.Description != "Find tests" || s.Status != SubagentDone {
+		t.Errorf("finished subagent = %+v", s)
+	}
+}
+
+func TestSubagentsNone(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	writeLines(t, filepath.Join(ProjectDir("/work/repo"), "s1.jsonl"),
+		`{"type":"user","sessionId":"s1","message":{"role":"user","content":"hi"}}`,
+	)
+
+	subs, err := Subagents("/work/repo")
+	if err != nil || len(subs) != 0 {
+		t.Errorf("Subagents = %+v, %v; want none", subs, err)
+	}
+}
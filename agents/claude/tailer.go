@@ -0,0 +1,202 @@
+package claude
+
+import (
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/internal/fswatch"
+	"github.com/noamsto/houston/parser"
+)
+
+// stateWindow is how many of a session's last messages its state is
+// derived from.
+const stateWindow = 50
+
+// tailIdle is how long a project stays watched after its state was last
+// requested.
+const tailIdle = 5 * time.Minute
+
+// StateEvent is emitted when the state of a watched project's latest
+// session changes.
+type StateEvent struct {
+	CWD    string
+	Path   string // Session file
+	Result parser.Result
+}
+
+// Tailer keeps the state of the latest session of each project it is asked
+// about, watching the project directory and parsing only the lines appended
+// to a session file when it is written. Projects not asked about for a while
+// are no longer watched.
+type Tailer struct {
+	mu      sync.Mutex
+	watches map[string]*tailWatch // Project dir -> watch
+	closed  bool
+
+	subsMu sync.Mutex
+	subs   map[chan StateEvent]struct{}
+}
+
+type tailWatch struct {
+	cwd      string
+	fw       *fswatch.Watcher
+	path     string // Latest session file
+	result   parser.Result
+	lastUsed time.Time
+}
+
+func NewTailer() *Tailer {
+	return &Tailer{
+		watches: make(map[string]*tailWatch),
+		subs:    make(map[chan StateEvent]struct{}),
+	}
+}
+
+// State returns the state of the latest session for cwd, like
+// GetStateFromFiles. The first call for a project reads it and starts
+// watching it; later calls return the state kept current by the watch.
+func (t *Tailer) State(cwd string) (*parser.Result, error) {
+	dir := ProjectDir(cwd)
+	now := time.Now()
+
+	t.mu.Lock()
+	t.expire(now)
+	w, ok := t.watches[dir]
+	if ok {
+		w.lastUsed = now
+		result := w.result
+		t.mu.Unlock()
+		result.Choices = slices.Clone(result.Choices)
+		return &result, nil
+	}
+	t.mu.Unlock()
+
+	return t.watch(cwd, dir)
+}
+
+func (t *Tailer) watch(cwd, dir string) (*parser.Result, error) {
+	fw, err := fswatch.WatchDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read after the watch is in place so no write slips between the two.
+	path, err := FindLatestSession(dir)
+	if err != nil {
+		_ = fw.Close()
+		return nil, err
+	}
+	result, err := readState(path)
+	if err != nil {
+		_ = fw.Close()
+		return nil, err
+	}
+
+	t.mu.Lock()
+	if _, ok := t.watches[dir]; ok || t.closed {
+		// Raced with another caller, or closed meanwhile
+		t.mu.Unlock()
+		_ = fw.Close()
+		return &result, nil
+	}
+	w := &tailWatch{cwd: cwd, fw: fw, path: path, result: result, lastUsed: time.Now()}
+	t.watches[dir] = w
+	t.mu.Unlock()
+
+	go t.follow(dir, w)
+
+	out := result
+	out.Choices = slices.Clone(result.Choices)
+	return &out, nil
+}
+
+// follow updates a project's state as its session files are written, until
+// the watch is closed.
+func (t *Tailer) follow(dir string, w *tailWatch) {
+	for ev := range w.fw.Events {
+		if ev.Removed || !strings.HasSuffix(ev.Name, ".jsonl") || strings.HasPrefix(ev.Name, "agent-") {
+			continue
+		}
+		// The file just written is the latest session
+		path := filepath.Join(dir, ev.Name)
+		result, err := readState(path)
+		if err != nil {
+			continue
+		}
+
+		t.mu.Lock()
+		changed := path != w.path || !reflect.DeepEqual(result, w.result)
+		w.path = path
+		w.result = result
+		t.mu.Unlock()
+
+		if changed {
+			t.publish(StateEvent{CWD: w.cwd, Path: path, Result: result})
+		}
+	}
+}
+
+// expire stops watching projects not asked about within tailIdle. Callers
+// hold t.mu.
+func (t *Tailer) expire(now time.Time) {
+	for dir, w := range t.watches {
+		if now.Sub(w.lastUsed) > tailIdle {
+			_ = w.fw.Close()
+			delete(t.watches, dir)
+		}
+	}
+}
+
+// Close stops watching every project.
+func (t *Tailer) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for dir, w := range t.watches {
+		_ = w.fw.Close()
+		delete(t.watches, dir)
+	}
+}
+
+// Subscribe returns a channel of state changes and a function to
+// unsubscribe. Slow subscribers miss events rather than block the tailer.
+func (t *Tailer) Subscribe() (<-chan StateEvent, func()) {
+	ch := make(chan StateEvent, 16)
+	t.subsMu.Lock()
+	t.subs[ch] = struct{}{}
+	t.subsMu.Unlock()
+
+	return ch, func() {
+		t.subsMu.Lock()
+		if _, ok := t.subs[ch]; ok {
+			delete(t.subs, ch)
+			close(ch)
+		}
+		t.subsMu.Unlock()
+	}
+}
+
+func (t *Tailer) publish(ev StateEvent) {
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+	for ch := range t.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// readState derives the state of a session from its last messages.
+func readState(path string) (parser.Result, error) {
+	messages, err := ReadLastMessages(path, stateWindow)
+	if err != nil {
+		return parser.Result{}, err
+	}
+	state := GetSessionState(messages)
+	return state.ToParserResult(), nil
+}
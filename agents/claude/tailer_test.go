@@ -0,0 +1,52 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/parser"
+)
+
+func TestTailer(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cwd := "/work/repo"
+	path := filepath.Join(ProjectDir(cwd), "s1.jsonl")
+	writeLines(t, path, `{"type":"user","message":{"role":"user","content":"fix the build"}}`)
+
+	tailer := NewTailer()
+	defer tailer.Close()
+	events, unsubscribe := tailer.Subscribe()
+	defer unsubscribe()
+
+	result, err := tailer.State(cwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Type != parser.TypeIdle {
+		t.Fatalf("initial type = %v, want idle", result.Type)
+	}
+
+	// The turn ending is picked up from the write, without another State call
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Fixed."}],"stop_reason":"end_turn"}}` + "\n")
+	_ = f.Close()
+
+	select {
+	case ev := <-events:
+		if ev.CWD != cwd || ev.Path != path || ev.Result.Type != parser.TypeDone {
+			t.Errorf("event = %+v, want done for %s", ev, path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no state event after the session file was written")
+	}
+
+	result, _ = tailer.State(cwd)
+	if result.Type != parser.TypeDone {
+		t.Errorf("cached type = %v, want done", result.Type)
+	}
+}
@@ -0,0 +1,65 @@
+package claude
+
+import "github.com/noamsto/houston/agents"
+
+// todoWindow is how many of a session's last messages are searched for its
+// task list. Claude rewrites the list as it works, so one not updated in
+// that long is stale.
+const todoWindow = 500
+
+// GetTodos returns the task list of the latest session for cwd.
+func GetTodos(cwd string) ([]agents.Todo, error) {
+	sessionPath, err := FindLatestSession(ProjectDir(cwd))
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := ReadLastMessages(sessionPath, todoWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	return LatestTodos(messages), nil
+}
+
+// LatestTodos finds the current task list in a session: the input of the
+// last TodoWrite call, or the todos older Claude Code versions recorded on
+// each message, whichever comes last.
+func LatestTodos(messages []Message) []agents.Todo {
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if len(msg.Todos) > 0 {
+			todos := make([]agents.Todo, 0, len(msg.Todos))
+			for _, t := range msg.Todos {
+				todos = append(todos, agents.Todo{Content: t.Content, Status: t.Status, ActiveForm: t.ActiveForm})
+			}
+			return todos
+		}
+		if msg.Type != "assistant" {
+			continue
+		}
+
+		blocks := parseContentBlocks(msg.Message.Content)
+		for j := len(blocks) - 1; j >= 0; j-- {
+			block := blocks[j]
+			if block.Type != "tool_use" || block.Name != "TodoWrite" {
+				continue
+			}
+			items, _ := block.Input["todos"].([]any)
+			todos := make([]agents.Todo, 0, len(items))
+			for _, item := range items {
+				m, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				var t agents.Todo
+				t.Content, _ = m["content"].(string)
+				t.Status, _ = m["status"].(string)
+				t.ActiveForm, _ = m["activeForm"].(string)
+				todos = append(todos, t)
+			}
+			return todos
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package claude
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/noamsto/houston/agents"
+)
+
+func TestLatestTodos(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"TodoWrite","input":{"todos":[{"content":"Write tests","status":"in_progress","activeForm":"Writing tests"}]}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Updating."},{"type":"tool_use","id":"t2","name":"TodoWrite","input":{"todos":[` +
+			`{"content":"Write tests","status":"completed","activeForm":"Writing tests"},` +
+			`{"content":"Fix lint","status":"pending","activeForm":"Fixing lint"}]}}]}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"make lint"}}]}}`,
+	}
+	var messages []Message
+	for _, line := range lines {
+		var msg Message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	got := LatestTodos(messages)
+	want := []agents.Todo{
+		{Content: "Write tests", Status: "completed", ActiveForm: "Writing tests"},
+		{Content: "Fix lint", Status: "pending", ActiveForm: "Fixing lint"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d todos, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("todo %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// Older versions record the list on each message
+	legacy := []Message{{Type: "user", Todos: []Todo{{Content: "Ship", Status: "pending", ActiveForm: "Shipping"}}}}
+	if got := LatestTodos(legacy); len(got) != 1 || got[0].ActiveForm != "Shipping" {
+		t.Errorf("LatestTodos(legacy) = %+v", got)
+	}
+	if got := LatestTodos(messages[3:]); got != nil {
+		t.Errorf("LatestTodos without TodoWrite = %+v, want nil", got)
+	}
+}
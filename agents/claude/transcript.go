@@ -0,0 +1,115 @@
+package claude
+
+import (
+	"strings"
+
+	"github.com/noamsto/houston/agents"
+)
+
+// GetTranscript reconstructs the conversation of the latest session for cwd.
+func GetTranscript(cwd string) (*agents.Transcript, error) {
+	sessionPath, err := FindLatestSession(ProjectDir(cwd))
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := ReadMessages(sessionPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildTranscript(messages), nil
+}
+
+// BuildTranscript converts JSONL messages into a transcript of user prompts,
+// assistant text and tool calls. Tool results, thinking blocks and meta
+// messages are omitted; a /compact summary replaces the turns before it.
+func BuildTranscript(messages []Message) *agents.Transcript {
+	t := &agents.Transcript{
+		Agent:   agents.AgentClaudeCode,
+		Entries: []agents.TranscriptEntry{},
+	}
+
+	for _, msg := range messages {
+		if msg.SessionID != "" {
+			t.SessionID = msg.SessionID
+		}
+		if msg.CWD != "" {
+			t.CWD = msg.CWD
+		}
+		if msg.Type == "summary" && msg.Summary != "" {
+			t.Title = msg.Summary
+			continue
+		}
+		if msg.IsMeta {
+			continue
+		}
+
+		switch msg.Type {
+		case "user":
+			if isToolResult(msg.Message.Content) {
+				continue
+			}
+			if msg.IsCompactSummary {
+				// Everything before a compaction is represented by its summary
+				for _, block := range parseContentBlocks(msg.Message.Content) {
+					if block.Type == "text" {
+						t.Summary = block.Text
+					}
+				}
+				t.Entries = t.Entries[:0]
+				continue
+			}
+			var parts []string
+			for _, block := range parseContentBlocks(msg.Message.Content) {
+				if block.Type == "text" && block.Text != "" {
+					parts = append(parts, block.Text)
+				}
+			}
+			text := strings.TrimSpace(strings.Join(parts, "\n\n"))
+			if text == "" || isCommandNoise(text) {
+				continue
+			}
+			t.Entries = append(t.Entries, agents.TranscriptEntry{
+				Role:      agents.RoleUser,
+				Text:      text,
+				Timestamp: msg.Timestamp,
+			})
+
+		case "assistant":
+			for _, block := range parseContentBlocks(msg.Message.Content) {
+				switch block.Type {
+				case "text":
+					if strings.TrimSpace(block.Text) == "" {
+						continue
+					}
+					t.Entries = append(t.Entries, agents.TranscriptEntry{
+						Role:      agents.RoleAssistant,
+						Text:      block.Text,
+						Timestamp: msg.Timestamp,
+					})
+				case "tool_use":
+					t.Entries = append(t.Entries, agents.TranscriptEntry{
+						Role:      agents.RoleTool,
+						Tool:      block.Name,
+						ToolInput: agents.SummarizeToolInput(block.Input),
+						Timestamp: msg.Timestamp,
+					})
+				}
+			}
+		}
+	}
+
+	return t
+}
+
+// isCommandNoise reports whether a user message is slash-command plumbing
+// (command echoes, local command output) rather than a typed prompt.
+func isCommandNoise(text string) bool {
+	for _, prefix := range []string{"<command-name>", "<command-message>", "<local-command-stdout>", "<local-command-stderr>", "Caveat:"} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
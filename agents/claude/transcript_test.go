@@ -0,0 +1,61 @@
+package claude
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/agents"
+)
+
+func TestBuildTranscript(t *testing.T) {
+	lines := []string{
+		`{"type":"summary","summary":"Fix login bug"}`,
+		`{"type":"user","sessionId":"abc","cwd":"/repo","message":{"role":"user","content":"Fix the login bug"}}`,
+		`{"type":"user","isMeta":true,"message":{"role":"user","content":"Caveat: meta"}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"Let me look."},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}]}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`,
+		`{"type":"user","message":{"role":"user","content":"<command-name>/compact</command-name>"}}`,
+		`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Fixed."}]}}`,
+	}
+
+	var messages []Message
+	for _, line := range lines {
+		var msg Message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("unmarshal %q: %v", line, err)
+		}
+		messages = append(messages, msg)
+	}
+
+	tr := BuildTranscript(messages)
+
+	if tr.Title != "Fix login bug" {
+		t.Errorf("Title = %q, want %q", tr.Title, "Fix login bug")
+	}
+	if tr.SessionID != "abc" || tr.CWD != "/repo" {
+		t.Errorf("SessionID/CWD = %q/%q, want abc//repo", tr.SessionID, tr.CWD)
+	}
+
+	want := []agents.TranscriptEntry{
+		{Role: agents.RoleUser, Text: "Fix the login bug"},
+		{Role: agents.RoleAssistant, Text: "Let me look."},
+		{Role: agents.RoleTool, Tool: "Bash", ToolInput: "go test ./..."},
+		{Role: agents.RoleAssistant, Text: "Fixed."},
+	}
+	if len(tr.Entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(tr.Entries), len(want), tr.Entries)
+	}
+	for i, e := range tr.Entries {
+		if e.Role != want[i].Role || e.Text != want[i].Text || e.Tool != want[i].Tool || e.ToolInput != want[i].ToolInput {
+			t.Errorf("entry %d = %+v, want %+v", i, e, want[i])
+		}
+	}
+
+	md := tr.Markdown()
+	for _, s := range []string{"# Fix login bug", "> Fix the login bug", "**Bash** `go test ./...`", "Fixed."} {
+		if !strings.Contains(md, s) {
+			t.Errorf("Markdown() missing %q:\n%s", s, md)
+		}
+	}
+}
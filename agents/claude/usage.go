@@ -0,0 +1,166 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UsageRecord is the token usage of one Claude session for one day, model
+// and branch.
+type UsageRecord struct {
+	Date      string `json:"date"` // YYYY-MM-DD, local time
+	SessionID string `json:"session_id"`
+	CWD       string `json:"cwd"`
+	Branch    string `json:"branch"`
+	Model     string `json:"model"`
+	Messages  int    `json:"messages"` // Assistant responses
+	Usage
+	Cost *float64 `json:"cost_usd,omitempty"` // At list price; nil for a model without one (see UsageCost)
+}
+
+// ProjectsDir returns the directory holding every project's session logs.
+func ProjectsDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".claude", "projects")
+}
+
+// UsageBetween sums assistant token usage from every session log under
+// projectsDir for messages in [from, to). Files last written before from are
+// skipped without being read.
+func UsageBetween(projectsDir string, from, to time.Time) ([]UsageRecord, error) {
+	files, err := filepath.Glob(filepath.Join(projectsDir, "*", "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var records []UsageRecord
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Before(from) {
+			continue
+		}
+		messages, err := ReadMessages(path)
+		if err != nil {
+			continue
+		}
+		records = append(records, SessionUsage(messages, from, to)...)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Date != records[j].Date {
+			return records[i].Date < records[j].Date
+		}
+		return records[i].SessionID < records[j].SessionID
+	})
+	return records, nil
+}
+
+// SessionUsage aggregates one session's assistant usage in [from, to) by
+// day, model and branch. A zero to means no upper bound. Claude logs one
+// entry per content block with the same message id and usage, so each
+// message is counted once.
+func SessionUsage(messages []Message, from, to time.Time) []UsageRecord {
+	type key struct{ date, session, branch, model string }
+	byKey := make(map[key]*UsageRecord)
+	var order []key
+	seen := make(map[string]bool)
+
+	for _, msg := range messages {
+		if msg.Type != "assistant" || msg.Message.Model == "" || strings.HasPrefix(msg.Message.Model, "<") {
+			continue // Skip synthetic messages ("<synthetic>")
+		}
+		if msg.Timestamp.Before(from) || (!to.IsZero() && !msg.Timestamp.Before(to)) {
+			continue
+		}
+		if id := msg.Message.ID; id != "" {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+
+		k := key{msg.Timestamp.Local().Format(time.DateOnly), msg.SessionID, msg.GitBranch, msg.Message.Model}
+		rec, ok := byKey[k]
+		if !ok {
+			rec = &UsageRecord{Date: k.date, SessionID: k.session, CWD: msg.CWD, Branch: k.branch, Model: k.model}
+			byKey[k] = rec
+			order = append(order, k)
+		}
+		u := msg.Message.Usage
+		rec.Messages++
+		rec.InputTokens += u.InputTokens
+		rec.OutputTokens += u.OutputTokens
+		rec.CacheCreationInputTokens += u.CacheCreationInputTokens
+		rec.CacheReadInputTokens += u.CacheReadInputTokens
+	}
+
+	records := make([]UsageRecord, 0, len(order))
+	for _, k := range order {
+		rec := byKey[k]
+		if cost, ok := UsageCost(rec.Model, rec.Usage); ok {
+			rec.Cost = &cost
+		}
+		records = append(records, *rec)
+	}
+	return records
+}
+
+// Activity is what Claude did in a project over a time range.
+type Activity struct {
+	Conversations int            `json:"conversations"` // Session logs with responses in the range
+	Messages      int            `json:"messages"`      // Assistant responses
+	ToolCalls     map[string]int `json:"tool_calls"`    // By tool name
+	Usage
+}
+
+// ProjectActivity sums the activity in [from, to) of every session log in
+// projectDir (see ProjectDir). Files last written before from are skipped
+// without being read.
+func ProjectActivity(projectDir string, from, to time.Time) (Activity, error) {
+	activity := Activity{ToolCalls: make(map[string]int)}
+	files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+	if err != nil {
+		return activity, err
+	}
+
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Before(from) {
+			continue
+		}
+		messages, err := ReadMessages(path)
+		if err != nil {
+			continue
+		}
+		records := SessionUsage(messages, from, to)
+		if len(records) == 0 {
+			continue
+		}
+		activity.Conversations++
+		for _, r := range records {
+			activity.Messages += r.Messages
+			activity.InputTokens += r.InputTokens
+			activity.OutputTokens += r.OutputTokens
+			activity.CacheCreationInputTokens += r.CacheCreationInputTokens
+			activity.CacheReadInputTokens += r.CacheReadInputTokens
+		}
+
+		// Like usage, a tool call may be logged more than once
+		seen := make(map[string]bool)
+		for _, msg := range messages {
+			if msg.Type != "assistant" || msg.Timestamp.Before(from) || !msg.Timestamp.Before(to) {
+				continue
+			}
+			for _, block := range parseContentBlocks(msg.Message.Content) {
+				if block.Type == "tool_use" && !seen[block.ID] {
+					seen[block.ID] = true
+					activity.ToolCalls[block.Name]++
+				}
+			}
+		}
+	}
+	return activity, nil
+}
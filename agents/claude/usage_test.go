@@ -0,0 +1,88 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsageBetween(t *testing.T) {
+	lines := []string{
+		`{"type":"user","sessionId":"s1","timestamp":"2026-03-01T10:00:00Z","message":{"role":"user","content":"hi"}}`,
+		// Two content blocks of one message share id and usage
+		`{"type":"assistant","sessionId":"s1","cwd":"/repo","gitBranch":"main","timestamp":"2026-03-01T10:00:05Z","message":{"id":"m1","model":"claude-opus","usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":100}}}`,
+		`{"type":"assistant","sessionId":"s1","cwd":"/repo","gitBranch":"main","timestamp":"2026-03-01T10:00:06Z","message":{"id":"m1","model":"claude-opus","usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":100}}}`,
+		`{"type":"assistant","sessionId":"s1","cwd":"/repo","gitBranch":"main","timestamp":"2026-03-01T10:01:00Z","message":{"id":"m2","model":"claude-opus","usage":{"input_tokens":3,"output_tokens":7}}}`,
+		`{"type":"assistant","sessionId":"s1","cwd":"/repo","gitBranch":"main","timestamp":"2026-03-01T10:02:00Z","message":{"id":"m3","model":"<synthetic>","usage":{"input_tokens":99}}}`,
+		// Outside the range
+		`{"type":"assistant","sessionId":"s1","cwd":"/repo","gitBranch":"main","timestamp":"2026-02-01T10:00:00Z","message":{"id":"m0","model":"claude-opus","usage":{"input_tokens":1000}}}`,
+	}
+
+	dir := t.TempDir()
+	project := filepath.Join(dir, "-repo")
+	if err := os.MkdirAll(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "s1.jsonl"), []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+	records, err := UsageBetween(dir, from, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("UsageBetween() = %+v, want 1 record", records)
+	}
+	r := records[0]
+	if r.SessionID != "s1" || r.Branch != "main" || r.Model != "claude-opus" || r.CWD != "/repo" {
+		t.Errorf("record identity = %+v", r)
+	}
+	if r.Messages != 2 || r.InputTokens != 13 || r.OutputTokens != 12 || r.CacheReadInputTokens != 100 {
+		t.Errorf("record usage = %+v, want 2 messages, 13 in, 12 out, 100 cache read", r)
+	}
+	if r.Cost != nil {
+		t.Errorf("record cost = %v, want none for a model without a price", *r.Cost)
+	}
+
+	// Files untouched since before the range are skipped
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(project, "s1.jsonl"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := UsageBetween(dir, from, time.Time{}); len(records) != 0 {
+		t.Errorf("UsageBetween(stale file) = %+v, want none", records)
+	}
+}
+
+func TestProjectActivity(t *testing.T) {
+	lines := []string{
+		`{"type":"assistant","sessionId":"s1","timestamp":"2026-03-01T10:00:05Z","message":{"id":"m1","model":"claude-opus","content":[{"type":"tool_use","id":"t1","name":"Bash"}],"usage":{"input_tokens":10,"output_tokens":5}}}`,
+		`{"type":"assistant","sessionId":"s1","timestamp":"2026-03-01T10:00:06Z","message":{"id":"m1","model":"claude-opus","content":[{"type":"tool_use","id":"t1","name":"Bash"}],"usage":{"input_tokens":10,"output_tokens":5}}}`,
+		`{"type":"assistant","sessionId":"s1","timestamp":"2026-03-01T10:01:00Z","message":{"id":"m2","model":"claude-opus","content":[{"type":"tool_use","id":"t2","name":"Edit"},{"type":"tool_use","id":"t3","name":"Bash"}],"usage":{"input_tokens":3,"output_tokens":7}}}`,
+		// Outside the range
+		`{"type":"assistant","sessionId":"s1","timestamp":"2026-03-02T10:00:00Z","message":{"id":"m3","model":"claude-opus","content":[{"type":"tool_use","id":"t4","name":"Read"}],"usage":{"input_tokens":1000}}}`,
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "s1.jsonl"), []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "s2.jsonl"), []byte(lines[3]+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	a, err := ProjectActivity(dir, from, from.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Conversations != 1 || a.Messages != 2 || a.InputTokens != 13 || a.OutputTokens != 12 {
+		t.Errorf("activity = %+v, want 1 conversation, 2 messages, 13 in, 12 out", a)
+	}
+	if len(a.ToolCalls) != 2 || a.ToolCalls["Bash"] != 2 || a.ToolCalls["Edit"] != 1 {
+		t.Errorf("tool calls = %v, want Bash 2, Edit 1", a.ToolCalls)
+	}
+}
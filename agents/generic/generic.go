@@ -6,7 +6,8 @@ import (
 	"github.com/noamsto/houston/parser"
 )
 
-// Agent is a no-op fallback for panes without a detected AI agent.
+// Agent is the fallback for panes without a detected AI agent. It only
+// recognizes build and test runs (see ParseCommand).
 type Agent struct{}
 
 // New creates a new generic agent.
@@ -22,10 +23,10 @@ func (a *Agent) DetectFromOutput(_ string) bool {
 	return false // Never auto-detect; only used as fallback
 }
 
-func (a *Agent) ParseOutput(_ string) agents.AgentState {
+func (a *Agent) ParseOutput(output string) agents.AgentState {
 	return agents.AgentState{
 		Agent:  agents.AgentGeneric,
-		Result: parser.Result{Type: parser.TypeIdle},
+		Result: ParseCommand("", output),
 	}
 }
 
@@ -0,0 +1,145 @@
+package generic
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/noamsto/houston/parser"
+)
+
+// resultWindow is how many of the last non-empty lines may hold a tool's
+// result: the summary line, then the shell prompt it returned to.
+const resultWindow = 5
+
+type lineKind int
+
+const (
+	lineProgress lineKind = iota // Still running
+	linePassed                   // Finished successfully
+	lineFailed                   // Finished with a failure
+)
+
+// toolLine recognizes a line a build or test tool prints.
+type toolLine struct {
+	tool string
+	kind lineKind
+	re   *regexp.Regexp
+}
+
+// toolLines are checked in order; failures come first so a summary such as
+// "1 failed, 3 passed" counts as failed.
+var toolLines = []toolLine{
+	{"go test", lineFailed, regexp.MustCompile(`^(FAIL\b|--- FAIL:|panic: )`)},
+	{"cargo", lineFailed, regexp.MustCompile(`^(error(\[E\d+\])?:|test result: FAILED)`)},
+	{"npm", lineFailed, regexp.MustCompile(`^npm (ERR!|error)`)},
+	{"make", lineFailed, regexp.MustCompile(`^g?make(\[\d+\])?: \*\*\*`)},
+	{"docker build", lineFailed, regexp.MustCompile(`^ERROR(: failed to solve| \[)`)},
+	{"pytest", lineFailed, regexp.MustCompile(`^(=+ .*\b\d+ (failed|errors?)\b.*=+$|FAILED |ERROR )`)},
+
+	{"go test", linePassed, regexp.MustCompile(`^(ok\s+\S+|PASS$)`)},
+	{"cargo", linePassed, regexp.MustCompile(`^(\s*Finished |test result: ok)`)},
+	{"npm", linePassed, regexp.MustCompile(`^(added \d+ packages?|up to date)`)},
+	{"make", linePassed, regexp.MustCompile(`^g?make(\[\d+\])?: (Nothing to be done|'.*' is up to date)`)},
+	{"pytest", linePassed, regexp.MustCompile(`^=+ .*\b\d+ passed\b.*=+$`)},
+	{"docker build", linePassed, regexp.MustCompile(`^(Successfully (built|tagged) |(#\d+|\s*=> =>) naming to .* done$)`)},
+
+	{"go test", lineProgress, regexp.MustCompile(`^(=== (RUN|PAUSE|CONT)\s|\?\s+\S+\s+\[no test files\])`)},
+	{"cargo", lineProgress, regexp.MustCompile(`^\s*(Compiling|Downloaded|Building|Running) `)},
+	{"pytest", lineProgress, regexp.MustCompile(`^(collecting \.\.\.|\S+\.py [.sFEx]+)`)},
+	{"docker build", lineProgress, regexp.MustCompile(`^(Step \d+/\d+ :|\[\+\] Building|#\d+ \[)`)},
+}
+
+// toolCommands maps the foreground command of a pane to the tool it runs.
+var toolCommands = map[string]string{
+	"cargo":   "cargo",
+	"go":      "go",
+	"npm":     "npm",
+	"npx":     "npx",
+	"pnpm":    "pnpm",
+	"yarn":    "yarn",
+	"make":    "make",
+	"gmake":   "make",
+	"pytest":  "pytest",
+	"py.test": "pytest",
+	"tox":     "tox",
+	"docker":  "docker",
+	"podman":  "podman",
+}
+
+// ParseCommand reads the state of a build or test run from a pane's
+// foreground command and output: while a known tool is the foreground
+// process it is working; once back at the prompt, the tool's last summary
+// line tells whether it passed or failed, and a compiler or test failure
+// left at the end of the output (see parser.ParseFailure) is an error even
+// without one. Anything else is idle.
+func ParseCommand(command, output string) parser.Result {
+	if tool := commandTool(command); tool != "" {
+		return parser.Result{Type: parser.TypeWorking, Activity: "Running " + tool}
+	}
+
+	failure, failed := parser.ParseFailure(output)
+	lines := lastLines(output, resultWindow)
+	for i := len(lines) - 1; i >= 0; i-- {
+		line, ok := matchToolLine(lines[i])
+		if !ok {
+			continue
+		}
+		switch line.kind {
+		case lineFailed:
+			result := parser.Result{Type: parser.TypeError, Activity: line.tool + " failed", ErrorSnippet: strings.TrimSpace(lines[i])}
+			if failed {
+				result.ErrorSnippet = failure.ErrorSnippet
+			}
+			return result
+		case linePassed:
+			return parser.Result{Type: parser.TypeDone, Activity: line.tool + " finished"}
+		case lineProgress:
+			// Progress as the last line: still running. Followed by
+			// something else: interrupted.
+			if i == len(lines)-1 {
+				return parser.Result{Type: parser.TypeWorking, Activity: "Running " + line.tool}
+			}
+			return parser.Result{Type: parser.TypeIdle}
+		}
+	}
+	if failed {
+		return failure
+	}
+	return parser.Result{Type: parser.TypeIdle}
+}
+
+// commandTool returns the tool a foreground command runs, if known. Node
+// renames npm's process to e.g. "npm test", so only the first word counts.
+func commandTool(command string) string {
+	fields := strings.Fields(strings.ToLower(command))
+	if len(fields) == 0 {
+		return ""
+	}
+	return toolCommands[filepath.Base(fields[0])]
+}
+
+func matchToolLine(line string) (toolLine, bool) {
+	for _, t := range toolLines {
+		if t.re.MatchString(line) {
+			return t, true
+		}
+	}
+	return toolLine{}, false
+}
+
+// lastLines returns up to n of the last non-empty lines of output.
+func lastLines(output string, n int) []string {
+	var lines []string
+	all := strings.Split(output, "\n")
+	for i := len(all) - 1; i >= 0 && len(lines) < n; i-- {
+		if line := strings.TrimRight(all[i], " \t\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	// Back in output order
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
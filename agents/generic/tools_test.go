@@ -0,0 +1,39 @@
+package generic
+
+import (
+	"testing"
+
+	"github.com/noamsto/houston/parser"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		command  string
+		output   string
+		want     parser.ResultType
+		activity string
+	}{
+		{"cargo running", "cargo", "   Compiling serde v1.0.0\n", parser.TypeWorking, "Running cargo"},
+		{"npm retitled", "npm test", "> jest\n", parser.TypeWorking, "Running npm"},
+		{"shell idle", "zsh", "~/src $ ls\nREADME.md\n~/src $\n", parser.TypeIdle, ""},
+		{"go test passed", "zsh", "ok  \tgithub.com/x/y\t0.2s\n~/src $\n", parser.TypeDone, "go test finished"},
+		{"go test failed", "zsh", "ok  \tgithub.com/x/a\t0.1s\n--- FAIL: TestB (0.00s)\nFAIL\tgithub.com/x/b\t0.1s\nFAIL\n~/src $\n", parser.TypeError, "go test failed"},
+		{"cargo error", "bash", "error[E0425]: cannot find value `x` in this scope\n  --> src/main.rs:2:5\n\nerror: could not compile `demo`\n$\n", parser.TypeError, "cargo failed"},
+		{"make failed", "bash", "cc -o app main.c\nmake: *** [Makefile:2: app] Error 1\n$\n", parser.TypeError, "make failed"},
+		{"pytest summary", "zsh", "FAILED tests/test_a.py::test_x - assert 1 == 2\n==== 1 failed, 3 passed in 0.12s ====\n%\n", parser.TypeError, "pytest failed"},
+		{"pytest passed", "zsh", "==== 4 passed in 0.10s ====\n%\n", parser.TypeDone, "pytest finished"},
+		{"docker build failed", "bash", "ERROR: failed to solve: process \"/bin/sh -c make\" did not complete successfully: exit code: 2\n$\n", parser.TypeError, "docker build failed"},
+		{"go build error", "zsh", "# github.com/x/app\n./main.go:9:2: undefined: helper\n~/src $\n", parser.TypeError, ""},
+		{"interrupted", "bash", "   Compiling app v0.1.0\n^C\n$\n", parser.TypeIdle, ""},
+		{"stale result scrolled away", "bash", "FAIL\n$ ls\na\nb\nc\nd\n$\n", parser.TypeIdle, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseCommand(tt.command, tt.output)
+			if got.Type != tt.want || got.Activity != tt.activity {
+				t.Errorf("ParseCommand() = %v %q, want %v %q", got.Type, got.Activity, tt.want, tt.activity)
+			}
+		})
+	}
+}
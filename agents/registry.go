@@ -21,14 +21,19 @@ type Registry struct {
 	agents  []Agent
 	cache   map[string]cachedDetection
 	cacheMu sync.RWMutex
+
+	// Manually pinned agent types (paneID -> type), checked before detection
+	overrides   map[string]AgentType
+	overridesMu sync.RWMutex
 }
 
 // NewRegistry creates a registry with the given agents.
 // Agents are checked in order during detection.
 func NewRegistry(agents ...Agent) *Registry {
 	return &Registry{
-		agents: agents,
-		cache:  make(map[string]cachedDetection),
+		agents:    agents,
+		cache:     make(map[string]cachedDetection),
+		overrides: make(map[string]AgentType),
 	}
 }
 
@@ -36,6 +41,10 @@ func NewRegistry(agents ...Agent) *Registry {
 // paneID is used for caching, command is from tmux pane_current_command,
 // output is raw terminal output (ANSI will be stripped internally).
 func (r *Registry) Detect(paneID, command, output string) Agent {
+	if agentType, ok := r.Override(paneID); ok {
+		return r.getAgent(agentType)
+	}
+
 	// Check cache first, but invalidate if command changed
 	r.cacheMu.RLock()
 	cached, ok := r.cache[paneID]
@@ -79,6 +88,69 @@ func (r *Registry) InvalidateCache(paneID string) {
 	r.cacheMu.Unlock()
 }
 
+// SetOverride pins the agent type for a pane, bypassing detection.
+func (r *Registry) SetOverride(paneID string, agentType AgentType) {
+	r.overridesMu.Lock()
+	r.overrides[paneID] = agentType
+	r.overridesMu.Unlock()
+	r.InvalidateCache(paneID)
+}
+
+// ClearOverride removes a pinned agent type, returning the pane to detection.
+func (r *Registry) ClearOverride(paneID string) {
+	r.overridesMu.Lock()
+	delete(r.overrides, paneID)
+	r.overridesMu.Unlock()
+	r.InvalidateCache(paneID)
+}
+
+// PruneOverrides removes the pinned agent types of the panes gone reports,
+// and returns how many it removed.
+func (r *Registry) PruneOverrides(gone func(paneID string) bool) int {
+	r.overridesMu.Lock()
+	var removed []string
+	for paneID := range r.overrides {
+		if gone(paneID) {
+			delete(r.overrides, paneID)
+			removed = append(removed, paneID)
+		}
+	}
+	r.overridesMu.Unlock()
+	for _, paneID := range removed {
+		r.InvalidateCache(paneID)
+	}
+	return len(removed)
+}
+
+// Override returns the pinned agent type for a pane, if any.
+func (r *Registry) Override(paneID string) (AgentType, bool) {
+	r.overridesMu.RLock()
+	defer r.overridesMu.RUnlock()
+	agentType, ok := r.overrides[paneID]
+	return agentType, ok
+}
+
+// Overrides returns a copy of all pinned agent types.
+func (r *Registry) Overrides() map[string]AgentType {
+	r.overridesMu.RLock()
+	defer r.overridesMu.RUnlock()
+	result := make(map[string]AgentType, len(r.overrides))
+	for k, v := range r.overrides {
+		result[k] = v
+	}
+	return result
+}
+
+// HasAgent reports whether the registry has an implementation for a type.
+func (r *Registry) HasAgent(agentType AgentType) bool {
+	for _, a := range r.agents {
+		if a.Type() == agentType {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAgent returns the agent implementation for a type.
 func (r *Registry) GetAgent(agentType AgentType) Agent {
 	return r.getAgent(agentType)
@@ -107,6 +179,17 @@ func (r *Registry) cacheResult(paneID, command string, agentType AgentType) {
 	r.cacheMu.Unlock()
 }
 
+// DetectFromCommand returns the agent type for a process name, or
+// AgentGeneric if it isn't a known agent.
+func DetectFromCommand(command string) AgentType {
+	return detectFromCommand(command)
+}
+
+// IsShellCommand reports whether a process name is a known shell.
+func IsShellCommand(command string) bool {
+	return isShellCommand(command)
+}
+
 // detectFromCommand checks tmux pane_current_command for agent patterns.
 func detectFromCommand(command string) AgentType {
 	cmd := strings.ToLower(command)
@@ -2,6 +2,8 @@ package agents
 
 import (
 	"testing"
+
+	"github.com/noamsto/houston/parser"
 )
 
 func TestDetectFromCommand(t *testing.T) {
@@ -30,3 +32,47 @@ func TestDetectFromCommand(t *testing.T) {
 		})
 	}
 }
+
+// stubAgent is a minimal Agent for registry tests.
+type stubAgent struct{ agentType AgentType }
+
+func (a stubAgent) Type() AgentType                               { return a.agentType }
+func (a stubAgent) DetectFromOutput(string) bool                  { return false }
+func (a stubAgent) ParseOutput(string) AgentState                 { return AgentState{Agent: a.agentType} }
+func (a stubAgent) GetStateFromFiles(string) (*AgentState, error) { return nil, nil }
+func (a stubAgent) FilterStatusBar(output string) string          { return output }
+func (a stubAgent) ExtractStatusLine(string) string               { return "" }
+func (a stubAgent) DetectMode(string) parser.Mode                 { return parser.ModeUnknown }
+
+func TestRegistryOverride(t *testing.T) {
+	r := NewRegistry(stubAgent{AgentClaudeCode}, stubAgent{AgentAmp}, stubAgent{AgentGeneric})
+
+	// A wrapper script hides the agent from command detection
+	if got := r.Detect("work:1.0", "run-agent.sh", "").Type(); got != AgentGeneric {
+		t.Fatalf("Detect() before override = %v, want %v", got, AgentGeneric)
+	}
+
+	r.SetOverride("work:1.0", AgentAmp)
+	if got := r.Detect("work:1.0", "run-agent.sh", "").Type(); got != AgentAmp {
+		t.Errorf("Detect() with override = %v, want %v", got, AgentAmp)
+	}
+	if got := r.Detect("work:2.0", "run-agent.sh", "").Type(); got != AgentGeneric {
+		t.Errorf("Detect() of other pane = %v, want %v", got, AgentGeneric)
+	}
+
+	r.SetOverride("work:3.0", AgentAmp)
+	if n := r.PruneOverrides(func(paneID string) bool { return paneID == "work:3.0" }); n != 1 {
+		t.Errorf("PruneOverrides() = %d, want 1", n)
+	}
+	if _, ok := r.Override("work:3.0"); ok {
+		t.Error("Override() of a pruned pane still set")
+	}
+
+	r.ClearOverride("work:1.0")
+	if got := r.Detect("work:1.0", "claude", "").Type(); got != AgentClaudeCode {
+		t.Errorf("Detect() after clearing override = %v, want %v", got, AgentClaudeCode)
+	}
+	if len(r.Overrides()) != 0 {
+		t.Errorf("Overrides() = %v, want empty", r.Overrides())
+	}
+}
@@ -0,0 +1,38 @@
+package agents
+
+// Todo status values. OpenCode also cancels items.
+const (
+	TodoPending    = "pending"
+	TodoInProgress = "in_progress"
+	TodoCompleted  = "completed"
+	TodoCancelled  = "cancelled"
+)
+
+// Todo is an item of the task list an agent keeps for its session.
+type Todo struct {
+	Content    string `json:"content"`
+	Status     string `json:"status"`                // "pending", "in_progress", "completed", "cancelled"
+	ActiveForm string `json:"active_form,omitempty"` // Shown while in progress, e.g. "Running tests"
+}
+
+// TodoProvider is implemented by agents that keep a task list in their
+// file-based storage.
+type TodoProvider interface {
+	// Todos returns the current task list of the session running in cwd.
+	Todos(cwd string) ([]Todo, error)
+}
+
+// TodoProgress counts completed items and the items that count towards
+// the list's progress (all but cancelled ones).
+func TodoProgress(todos []Todo) (completed, total int) {
+	for _, t := range todos {
+		switch t.Status {
+		case TodoCancelled:
+			continue
+		case TodoCompleted:
+			completed++
+		}
+		total++
+	}
+	return completed, total
+}
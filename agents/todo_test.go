@@ -0,0 +1,17 @@
+package agents
+
+import "testing"
+
+func TestTodoProgress(t *testing.T) {
+	todos := []Todo{
+		{Content: "a", Status: TodoCompleted},
+		{Content: "b", Status: TodoCompleted},
+		{Content: "c", Status: TodoInProgress},
+		{Content: "d", Status: TodoPending},
+		{Content: "e", Status: TodoCancelled},
+	}
+	completed, total := TodoProgress(todos)
+	if completed != 2 || total != 4 {
+		t.Errorf("TodoProgress = %d/%d, want 2/4", completed, total)
+	}
+}
@@ -0,0 +1,212 @@
+package agents
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/internal/textutil"
+)
+
+// EntryRole identifies who produced a transcript entry.
+type EntryRole string
+
+const (
+	RoleUser      EntryRole = "user"
+	RoleAssistant EntryRole = "assistant"
+	RoleTool      EntryRole = "tool"
+)
+
+// TranscriptEntry is a single turn fragment in a reconstructed conversation.
+type TranscriptEntry struct {
+	Role      EntryRole `json:"role"`
+	Text      string    `json:"text,omitempty"`
+	Tool      string    `json:"tool,omitempty"`       // Tool name for RoleTool entries
+	ToolInput string    `json:"tool_input,omitempty"` // Short, human-readable summary of the tool input
+	Timestamp time.Time `json:"timestamp,omitempty"`
+}
+
+// Transcript is an agent conversation reconstructed from its log files.
+type Transcript struct {
+	Agent     AgentType         `json:"agent"`
+	SessionID string            `json:"session_id"`
+	Title     string            `json:"title,omitempty"`
+	CWD       string            `json:"cwd,omitempty"`
+	Summary   string            `json:"summary,omitempty"` // Compaction summary of earlier turns, if any
+	Entries   []TranscriptEntry `json:"entries"`
+}
+
+// TranscriptProvider is implemented by agents that can reconstruct
+// their conversation from file-based storage.
+type TranscriptProvider interface {
+	// Transcript returns the conversation for the session running in cwd.
+	Transcript(cwd string) (*Transcript, error)
+}
+
+// SummarizeToolInput picks the most descriptive field from a tool input map
+// (command, file path, pattern, ...) for display in a transcript.
+func SummarizeToolInput(input map[string]any) string {
+	for _, key := range []string{"command", "file_path", "path", "pattern", "url", "query", "description", "prompt"} {
+		if v, ok := input[key].(string); ok && v != "" {
+			v = strings.TrimSpace(v)
+			if idx := strings.IndexByte(v, '\n'); idx >= 0 {
+				v = v[:idx] + " …"
+			}
+			if len(v) > 200 {
+				v = textutil.Truncate(v, 197) + "..."
+			}
+			return v
+		}
+	}
+	return ""
+}
+
+// Markdown renders the transcript as GitHub-flavored Markdown.
+func (t *Transcript) Markdown() string {
+	var b strings.Builder
+
+	title := t.Title
+	if title == "" {
+		title = "Session " + t.SessionID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- **Agent:** %s\n", t.Agent)
+	if t.CWD != "" {
+		fmt.Fprintf(&b, "- **Directory:** `%s`\n", t.CWD)
+	}
+	b.WriteString("\n")
+
+	var lastRole EntryRole
+	for _, e := range t.Entries {
+		switch e.Role {
+		case RoleUser:
+			b.WriteString("## User\n\n")
+			b.WriteString(quoteMarkdown(e.Text))
+			b.WriteString("\n\n")
+		case RoleAssistant:
+			if lastRole != RoleAssistant && lastRole != RoleTool {
+				b.WriteString("## Assistant\n\n")
+			}
+			b.WriteString(strings.TrimSpace(e.Text))
+			b.WriteString("\n\n")
+		case RoleTool:
+			if lastRole != RoleAssistant && lastRole != RoleTool {
+				b.WriteString("## Assistant\n\n")
+			}
+			if e.ToolInput != "" {
+				fmt.Fprintf(&b, "- 🔧 **%s** `%s`\n\n", e.Tool, strings.ReplaceAll(e.ToolInput, "`", "'"))
+			} else {
+				fmt.Fprintf(&b, "- 🔧 **%s**\n\n", e.Tool)
+			}
+		}
+		lastRole = e.Role
+	}
+
+	return b.String()
+}
+
+// HTML renders the transcript as a self-contained HTML document.
+func (t *Transcript) HTML() string {
+	var b strings.Builder
+
+	title := t.Title
+	if title == "" {
+		title = "Session " + t.SessionID
+	}
+
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	b.WriteString(`<style>
+body{font-family:system-ui,sans-serif;max-width:860px;margin:2em auto;padding:0 1em;line-height:1.5;color:#1f2328}
+.entry{margin:1em 0;padding:.75em 1em;border-radius:6px;white-space:pre-wrap}
+.user{background:#ddf4ff}
+.assistant{background:#f6f8fa}
+.tool{font-family:ui-monospace,monospace;font-size:.9em;color:#57606a;padding:.25em 1em}
+.meta{color:#57606a}
+</style></head><body>
+`)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", html.EscapeString(title))
+	fmt.Fprintf(&b, "<p class=\"meta\">Agent: %s", html.EscapeString(string(t.Agent)))
+	if t.CWD != "" {
+		fmt.Fprintf(&b, " · Directory: <code>%s</code>", html.EscapeString(t.CWD))
+	}
+	b.WriteString("</p>\n")
+
+	for _, e := range t.Entries {
+		switch e.Role {
+		case RoleUser, RoleAssistant:
+			fmt.Fprintf(&b, "<div class=\"entry %s\">%s</div>\n", e.Role, html.EscapeString(strings.TrimSpace(e.Text)))
+		case RoleTool:
+			fmt.Fprintf(&b, "<div class=\"entry tool\">🔧 <strong>%s</strong> %s</div>\n",
+				html.EscapeString(e.Tool), html.EscapeString(e.ToolInput))
+		}
+	}
+
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
+
+// HandoffPrompt formats the last maxEntries entries (all if <= 0) as an
+// opening prompt for another agent taking over the same work.
+func (t *Transcript) HandoffPrompt(maxEntries int, instructions string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "You are taking over a coding task from another AI agent (%s)", t.Agent)
+	if t.CWD != "" {
+		fmt.Fprintf(&b, " working in %s", t.CWD)
+	}
+	b.WriteString(".\n\n")
+
+	if t.Summary != "" {
+		b.WriteString("## Summary of earlier work\n\n")
+		b.WriteString(strings.TrimSpace(t.Summary))
+		b.WriteString("\n\n")
+	}
+
+	entries := t.Entries
+	if maxEntries > 0 && len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	if len(entries) > 0 {
+		b.WriteString("## Recent conversation\n\n")
+		for _, e := range entries {
+			switch e.Role {
+			case RoleUser:
+				fmt.Fprintf(&b, "**User:** %s\n\n", truncateText(e.Text, 2000))
+			case RoleAssistant:
+				fmt.Fprintf(&b, "**Agent:** %s\n\n", truncateText(e.Text, 2000))
+			case RoleTool:
+				fmt.Fprintf(&b, "- tool %s %s\n", e.Tool, e.ToolInput)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Next step\n\n")
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		b.WriteString(instructions)
+	} else {
+		b.WriteString("Review the current state of the repository and continue where the previous agent left off.")
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+func truncateText(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return textutil.Truncate(s, n) + " …"
+}
+
+// quoteMarkdown prefixes every line with "> " so user prompts render as quotes.
+func quoteMarkdown(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
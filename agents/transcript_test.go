@@ -0,0 +1,99 @@
+package agents
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSummarizeToolInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]any
+		want  string
+	}{
+		{"command", map[string]any{"command": "ls -la", "description": "list"}, "ls -la"},
+		{"file path", map[string]any{"file_path": "/tmp/x.go"}, "/tmp/x.go"},
+		{"multiline", map[string]any{"command": "echo a\necho b"}, "echo a …"},
+		{"long", map[string]any{"command": strings.Repeat("a", 250)}, strings.Repeat("a", 197) + "..."},
+		{"long multi-byte", map[string]any{"prompt": strings.Repeat("é", 150)}, strings.Repeat("é", 98) + "..."},
+		{"empty", map[string]any{}, ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SummarizeToolInput(tt.input)
+			if got != tt.want {
+				t.Errorf("SummarizeToolInput() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("SummarizeToolInput() = %q, not valid UTF-8", got)
+			}
+		})
+	}
+}
+
+func TestTranscriptHTMLEscapes(t *testing.T) {
+	tr := &Transcript{
+		Agent:     AgentAmp,
+		SessionID: "T-1",
+		Entries: []TranscriptEntry{
+			{Role: RoleUser, Text: "<script>alert(1)</script>"},
+		},
+	}
+
+	out := tr.HTML()
+	if strings.Contains(out, "<script>") {
+		t.Errorf("HTML() did not escape user text:\n%s", out)
+	}
+	if !strings.Contains(out, "Session T-1") {
+		t.Errorf("HTML() missing fallback title:\n%s", out)
+	}
+}
+
+func TestHandoffPrompt(t *testing.T) {
+	tr := &Transcript{
+		Agent:   AgentClaudeCode,
+		CWD:     "/repo",
+		Summary: "Refactored the parser.",
+		Entries: []TranscriptEntry{
+			{Role: RoleUser, Text: "old prompt"},
+			{Role: RoleUser, Text: "add tests"},
+			{Role: RoleTool, Tool: "Bash", ToolInput: "go test ./..."},
+		},
+	}
+
+	out := tr.HandoffPrompt(2, "")
+	for _, s := range []string{"(claude-code) working in /repo", "Refactored the parser.", "add tests", "tool Bash go test ./...", "continue where the previous agent left off"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("HandoffPrompt() missing %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "old prompt") {
+		t.Errorf("HandoffPrompt() should keep only the last 2 entries:\n%s", out)
+	}
+
+	if out := tr.HandoffPrompt(0, "Open a PR."); !strings.Contains(out, "Open a PR.") || !strings.Contains(out, "old prompt") {
+		t.Errorf("HandoffPrompt(0, instructions) = %s", out)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"  short  ", 10, "short"},
+		{"abcdef", 4, "abcd …"},
+		{"日本語", 4, "日 …"},
+		{"日本語", 6, "日本 …"},
+	}
+
+	for _, tt := range tests {
+		if got := truncateText(tt.s, tt.n); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/noamsto/houston/internal/listen"
+	"github.com/noamsto/houston/server"
+	"github.com/noamsto/houston/tmux"
+)
+
+// The list, attention and send commands talk to a running houston server,
+// so they see the same states as the dashboard (hooks, grace periods,
+// reminders) instead of re-deriving them.
+
+var apiClient = &http.Client{Timeout: 10 * time.Second}
+
+// apiEndpoint returns the client and base URL that reach the server at
+// addr. A unix:PATH address is dialed over the socket.
+func apiEndpoint(addr string) (*http.Client, string) {
+	path, ok := listen.UnixPath(addr)
+	if !ok {
+		return apiClient, "http://" + addr
+	}
+	dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+	return &http.Client{Timeout: apiClient.Timeout, Transport: &http.Transport{DialContext: dial}}, "http://houston"
+}
+
+// serverAddr returns addr, or when empty the -addr the server would use
+// from HOUSTON_ADDR or the config file.
+func serverAddr(addr string) (string, error) {
+	if addr != "" {
+		return addr, nil
+	}
+	fs := flag.NewFlagSet("houston", flag.ContinueOnError)
+	opts := newOptions(fs)
+	if path, _, err := opts.load(fs); err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return opts.addr, nil
+}
+
+// fetchSessions gets the categorized session list from the server.
+func fetchSessions(addr string) (*server.SessionsData, error) {
+	client, base := apiEndpoint(addr)
+	resp, err := client.Get(base + "/api/sessions")
+	if err != nil {
+		return nil, fmt.Errorf("is houston running? %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var data server.SessionsData
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decode sessions: %w", err)
+	}
+	return &data, nil
+}
+
+// paneTarget formats a window's pane as session:window.pane, prefixed with
+// "host|" for remote panes (the form send accepts).
+func paneTarget(p tmux.Pane) string {
+	target := fmt.Sprintf("%s:%d.%d", p.Session, p.Window, p.Index)
+	if p.Host != "" {
+		return p.Host + "|" + target
+	}
+	return target
+}
+
+// windowState describes what a window is doing, for list and attention.
+func windowState(win server.WindowWithStatus) string {
+	state := win.ParseResult.Type.String()
+	if len(win.MCPDown) > 0 && win.NeedsAttention {
+		state = "mcp-down"
+	}
+	return state
+}
+
+// runList implements `houston list`: print every window with its state.
+func runList(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	addr := fs.String("addr", "", "houston server address (default from $HOUSTON_ADDR or the config file)")
+	asJSON := fs.Bool("json", false, "Print the /api/sessions response as JSON")
+	_ = fs.Parse(args)
+
+	a, err := serverAddr(*addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	data, err := fetchSessions(a)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "list sessions: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(data)
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CATEGORY\tTARGET\tWINDOW\tSTATE\tAGENT\tBRANCH\tWAITING")
+	for _, group := range []struct {
+		name     string
+		sessions []server.SessionWithWindows
+	}{
+		{"attention", data.NeedsAttention},
+		{"active", data.Active},
+		{"idle", data.Idle},
+	} {
+		for _, sess := range group.sessions {
+			for _, win := range sess.Windows {
+				waiting := "-"
+				if win.NeedsAttention && win.WaitingMinutes > 0 {
+					waiting = fmt.Sprintf("%dm", win.WaitingMinutes)
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					group.name, paneTarget(win.Pane), win.Window.Name, windowState(win),
+					orDash(string(win.AgentType)), orDash(win.Branch), waiting)
+			}
+		}
+	}
+	_ = tw.Flush()
+	return 0
+}
+
+// runAttention implements `houston attention`: exit 1 when any window
+// needs attention, listing them, and 0 when none does.
+func runAttention(args []string) int {
+	fs := flag.NewFlagSet("attention", flag.ExitOnError)
+	addr := fs.String("addr", "", "houston server address (default from $HOUSTON_ADDR or the config file)")
+	quiet := fs.Bool("q", false, "Print nothing; only set the exit status")
+	count := fs.Bool("count", false, "Print the number of windows needing attention (for status bars)")
+	_ = fs.Parse(args)
+
+	a, err := serverAddr(*addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	data, err := fetchSessions(a)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "list sessions: %v\n", err)
+		return 2
+	}
+
+	var waiting []server.WindowWithStatus
+	for _, sess := range data.NeedsAttention {
+		for _, win := range sess.Windows {
+			if win.NeedsAttention {
+				waiting = append(waiting, win)
+			}
+		}
+	}
+
+	switch {
+	case *quiet:
+	case *count:
+		fmt.Println(len(waiting))
+	default:
+		for _, win := range waiting {
+			line := paneTarget(win.Pane) + " " + windowState(win)
+			if q := win.ParseResult.Question; q != "" {
+				line += ": " + q
+			} else if len(win.MCPDown) > 0 {
+				line += ": " + strings.Join(win.MCPDown, ", ")
+			}
+			fmt.Println(line)
+		}
+	}
+	if len(waiting) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runSend implements `houston send <target> <text>`: type text into a
+// pane through the server, like the dashboard's input bar. Without text
+// arguments the text is read from stdin.
+func runSend(args []string) int {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	addr := fs.String("addr", "", "houston server address (default from $HOUSTON_ADDR or the config file)")
+	special := fs.Bool("special", false, "Send a special key name (Enter, Escape, C-c, Up, ...) instead of text")
+	noEnter := fs.Bool("no-enter", false, "Don't press Enter after the text")
+	mode := fs.String("mode", "", "type or paste the text (default: paste multi-line or long text, type the rest)")
+	noVerify := fs.Bool("no-verify", false, "Don't check the text showed up on the pane (e.g. at a password prompt)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: houston send [flags] <[host|]session:window.pane> [text...]")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return 2
+	}
+	target, text := fs.Arg(0), strings.Join(fs.Args()[1:], " ")
+	if fs.NArg() == 1 {
+		in, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "read stdin: %v\n", err)
+			return 1
+		}
+		text = strings.TrimRight(string(in), "\n")
+	}
+
+	a, err := serverAddr(*addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	host := ""
+	if i := strings.Index(target, "|"); i >= 0 {
+		host, target = target[:i], target[i+1:]
+	}
+	client, base := apiEndpoint(a)
+	u := base + "/api/pane/" + url.PathEscape(target) + "/send"
+	if host != "" {
+		u += "?host=" + url.QueryEscape(host)
+	}
+	form := url.Values{"input": {text}}
+	if *special {
+		form.Set("special", "true")
+	}
+	if *noEnter {
+		form.Set("noenter", "true")
+	}
+	if *mode != "" {
+		form.Set("mode", *mode)
+	}
+	if *noVerify {
+		form.Set("verify", "false")
+	}
+
+	resp, err := client.PostForm(u, form)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "send: is houston running? %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		fmt.Fprintf(os.Stderr, "send: %s: %s\n", resp.Status, strings.TrimSpace(string(body)))
+		return 1
+	}
+	var result struct {
+		Checked   bool `json:"checked"`
+		Confirmed bool `json:"confirmed"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&result) == nil && result.Checked && !result.Confirmed {
+		fmt.Fprintln(os.Stderr, "send: sent, but the text didn't show up on the pane")
+	}
+	return 0
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runConfig implements `houston config validate`: load the config file and
+// HOUSTON_* variables the way the server would, and report any problem.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, "usage: houston config validate [-config path] [flags]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	opts := newOptions(fs)
+	_ = fs.Parse(args[1:])
+
+	path, found, err := opts.load(fs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	if !found {
+		fmt.Printf("no config file at %s; flags and environment are valid\n", path)
+		return 0
+	}
+	fmt.Printf("%s: ok\n", path)
+	return 0
+}
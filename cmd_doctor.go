@@ -0,0 +1,405 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents/amp"
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/opencode"
+	"github.com/noamsto/houston/server"
+	"github.com/noamsto/houston/terminal"
+)
+
+// minTmux is the oldest tmux the pane terminal works with: its
+// control-mode client attaches with -f ignore-size (tmux 3.2).
+var minTmux = [2]int{3, 2}
+
+// doctorLevel grades a doctor check.
+type doctorLevel int
+
+const (
+	doctorOK doctorLevel = iota
+	doctorWarn
+	doctorFail
+)
+
+func (l doctorLevel) String() string {
+	switch l {
+	case doctorWarn:
+		return "warn"
+	case doctorFail:
+		return "FAIL"
+	}
+	return "ok"
+}
+
+// doctorCheck is the outcome of one check of houston's environment.
+type doctorCheck struct {
+	name   string
+	level  doctorLevel
+	detail string
+	fix    string // What to do about a warning or failure
+}
+
+// runDoctor implements `houston doctor`: check the environment houston
+// runs in, with the settings the server would use, and print what to fix.
+// It exits 1 when a check fails; warnings are features that won't work.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	opts := newOptions(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: houston doctor [-config path] [flags]\n\nChecks tmux, hooks, directories, agents, OpenCode and terminal control\nwith the settings the server would use.\n\nflags:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	// Discovery and detection log as they go; only the report is wanted
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	home, _ := os.UserHomeDir()
+	configCheck := doctorConfig(opts, fs)
+	if opts.statusDir == "" {
+		opts.statusDir = filepath.Join(home, ".local", "state", "houston")
+	}
+	if opts.dataDir == "" {
+		opts.dataDir = filepath.Join(home, ".local", "share", "houston")
+	}
+
+	checks := []doctorCheck{
+		configCheck,
+		doctorTmux(),
+		doctorServer(opts.addr),
+		doctorHooks(filepath.Join(home, ".claude", "settings.json")),
+		doctorDir("status dir", opts.statusDir),
+		doctorDir("data dir", opts.dataDir),
+		doctorClaude(),
+		doctorAmp(),
+		doctorOpenCode(opts),
+		doctorTerminal(opts.terminal),
+	}
+
+	failed := false
+	for _, c := range checks {
+		fmt.Printf("%-4s  %-10s  %s\n", c.level, c.name, c.detail)
+		if c.fix != "" && c.level != doctorOK {
+			fmt.Printf("%-4s  %-10s  fix: %s\n", "", "", c.fix)
+		}
+		failed = failed || c.level == doctorFail
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func doctorConfig(opts *options, fs *flag.FlagSet) doctorCheck {
+	c := doctorCheck{name: "config"}
+	path, found, err := opts.load(fs)
+	switch {
+	case err != nil:
+		c.level, c.detail = doctorFail, fmt.Sprintf("%s: %v", path, err)
+		c.fix = "correct the setting; houston config validate checks it again"
+	case found:
+		c.detail = path
+	default:
+		c.detail = "no config file (" + path + "); defaults, flags and HOUSTON_* variables"
+	}
+	return c
+}
+
+func doctorTmux() doctorCheck {
+	c := doctorCheck{name: "tmux"}
+	path, err := exec.LookPath("tmux")
+	if err != nil {
+		c.level, c.detail = doctorFail, "tmux is not on PATH"
+		c.fix = "install tmux (apt install tmux, brew install tmux, nix profile install nixpkgs#tmux)"
+		return c
+	}
+	out, err := exec.Command(path, "-V").Output()
+	if err != nil {
+		c.level, c.detail = doctorFail, fmt.Sprintf("%s -V: %v", path, err)
+		return c
+	}
+	version := strings.TrimSpace(string(out))
+	c.detail = version + " at " + path
+	if v, ok := tmuxVersion(version); ok && (v[0] < minTmux[0] || v[0] == minTmux[0] && v[1] < minTmux[1]) {
+		c.level = doctorWarn
+		c.detail += fmt.Sprintf("; the pane terminal needs %d.%d or later", minTmux[0], minTmux[1])
+		c.fix = "upgrade tmux; the dashboard works, the live pane terminal doesn't"
+		return c
+	}
+
+	out, err = exec.Command(path, "list-sessions", "-F", "#{session_name}").CombinedOutput()
+	switch {
+	case err != nil && strings.Contains(string(out), "no server running"):
+		c.level = doctorWarn
+		c.detail += "; no tmux server running"
+		c.fix = "start tmux (tmux new -s work); houston shows its sessions"
+	case err != nil:
+		c.level = doctorFail
+		c.detail += "; list-sessions: " + strings.TrimSpace(string(out))
+		c.fix = "check that houston runs as the user who owns the tmux server (and the same TMUX_TMPDIR)"
+	default:
+		c.detail += fmt.Sprintf("; %d sessions", len(strings.Fields(string(out))))
+	}
+	return c
+}
+
+// tmuxVersion reads major and minor from tmux -V ("tmux 3.3a",
+// "tmux next-3.5"). Builds from master have none.
+func tmuxVersion(s string) ([2]int, bool) {
+	m := regexp.MustCompile(`(\d+)\.(\d+)`).FindStringSubmatch(s)
+	if m == nil {
+		return [2]int{}, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return [2]int{major, minor}, true
+}
+
+func doctorServer(addr string) doctorCheck {
+	c := doctorCheck{name: "server"}
+	client, base := apiEndpoint(addr)
+	client = &http.Client{Timeout: 2 * time.Second, Transport: client.Transport}
+	resp, err := client.Get(base + "/api/meta")
+	if err != nil {
+		c.level, c.detail = doctorWarn, "no houston serving on "+addr
+		c.fix = "start it with houston serve (or -addr to check another address)"
+		return c
+	}
+	defer resp.Body.Close()
+	var meta server.Meta
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&meta) != nil {
+		c.level, c.detail = doctorWarn, addr+" answers, but not as houston ("+resp.Status+")"
+		c.fix = "another program holds the address; pick a free one with -addr"
+		return c
+	}
+	version := meta.Version
+	if version == "" {
+		version = "dev"
+	}
+	c.detail = fmt.Sprintf("houston %s serving on %s", version, addr)
+	return c
+}
+
+// doctorHooks looks for a houston hook in Claude Code's user settings.
+// Hooks are optional (terminal parsing sees most states) but report
+// prompts and finished turns at once.
+func doctorHooks(settingsPath string) doctorCheck {
+	c := doctorCheck{name: "hooks"}
+	fix := "houston install-hooks"
+	data, err := os.ReadFile(settingsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		c.level, c.detail, c.fix = doctorWarn, "no Claude Code settings at "+settingsPath, fix
+		return c
+	}
+	if err != nil {
+		c.level, c.detail = doctorFail, err.Error()
+		return c
+	}
+	var settings struct {
+		Hooks map[string][]struct {
+			Hooks []struct {
+				Command string `json:"command"`
+			} `json:"hooks"`
+		} `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		c.level, c.detail = doctorFail, fmt.Sprintf("%s: %v", settingsPath, err)
+		c.fix = "fix the JSON; Claude Code ignores settings it can't read"
+		return c
+	}
+
+	var events []string
+	for _, event := range []string{"Notification", "Stop"} {
+		for _, matcher := range settings.Hooks[event] {
+			for _, h := range matcher.Hooks {
+				if isHoustonHook(h.Command) && !slices.Contains(events, event) {
+					events = append(events, event)
+				}
+			}
+		}
+	}
+	switch len(events) {
+	case 0:
+		c.level, c.detail, c.fix = doctorWarn, "no houston hook in "+settingsPath, fix
+	case 1:
+		c.level, c.detail, c.fix = doctorWarn, "houston hook on "+events[0]+" only", fix
+	default:
+		c.detail = "houston hook on " + strings.Join(events, " and ")
+	}
+	return c
+}
+
+// isHoustonHook reports whether a hook command reports to houston.
+func isHoustonHook(command string) bool {
+	return strings.Contains(command, "claude-hook") || strings.Contains(command, "houston") ||
+		strings.Contains(command, "/api/hooks/claude")
+}
+
+// doctorDir checks that houston can write to dir, or create it.
+func doctorDir(name, dir string) doctorCheck {
+	c := doctorCheck{name: name, detail: dir}
+	info, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Created on start; its nearest existing parent must be writable
+		parent := filepath.Dir(dir)
+		for {
+			if _, err := os.Stat(parent); err == nil || parent == filepath.Dir(parent) {
+				break
+			}
+			parent = filepath.Dir(parent)
+		}
+		if err := writable(parent); err != nil {
+			c.level, c.detail = doctorFail, dir+" doesn't exist and can't be created: "+err.Error()
+			c.fix = "create it for this user: mkdir -p " + dir
+			return c
+		}
+		c.detail += " (created on start)"
+		return c
+	}
+	if err != nil {
+		c.level, c.detail = doctorFail, err.Error()
+		return c
+	}
+	if !info.IsDir() {
+		c.level, c.detail = doctorFail, dir+" is not a directory"
+		c.fix = "move the file away, or point houston elsewhere"
+		return c
+	}
+	if err := writable(dir); err != nil {
+		c.level, c.detail = doctorFail, dir+" is not writable: "+err.Error()
+		c.fix = "chown -R $USER " + dir
+		return c
+	}
+	if info.Mode().Perm()&0o002 != 0 {
+		c.level, c.detail = doctorWarn, fmt.Sprintf("%s is writable by anyone (%v)", dir, info.Mode().Perm())
+		c.fix = "chmod o-w " + dir + "; other users could fake agent states"
+	}
+	return c
+}
+
+// writable reports whether a file can be created in dir.
+func writable(dir string) error {
+	f, err := os.CreateTemp(dir, ".houston-doctor-*")
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+func doctorClaude() doctorCheck {
+	c := doctorCheck{name: "claude"}
+	dir := claude.ProjectsDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		c.level, c.detail = doctorWarn, "no transcripts at "+dir
+		c.fix = "run Claude Code once on this machine; until then its panes are read from the terminal only"
+		return c
+	}
+	c.detail = fmt.Sprintf("%d projects in %s", len(entries), dir)
+	if _, err := exec.LookPath("claude"); err != nil {
+		c.detail += " (claude is not on PATH)"
+	}
+	return c
+}
+
+func doctorAmp() doctorCheck {
+	c := doctorCheck{name: "amp"}
+	dir := amp.ThreadsDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if _, err := exec.LookPath("amp"); err != nil {
+			c.detail = "not installed"
+			return c
+		}
+		c.level, c.detail = doctorWarn, "no threads at "+dir
+		c.fix = "run Amp once; until then its panes are read from the terminal only"
+		return c
+	}
+	c.detail = fmt.Sprintf("%d threads in %s", len(entries), dir)
+	return c
+}
+
+func doctorOpenCode(opts *options) doctorCheck {
+	c := doctorCheck{name: "opencode"}
+	if opts.noOpenCode {
+		c.detail = "disabled (-no-opencode)"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if opts.openCodeURL != "" {
+		if !opencode.IsAvailable(ctx, opts.openCodeURL) {
+			c.level, c.detail = doctorFail, "no OpenCode server answers at "+opts.openCodeURL
+			c.fix = "start it (opencode serve --port 4096) or correct -opencode-url"
+			return c
+		}
+		c.detail = "server at " + opts.openCodeURL
+		return c
+	}
+
+	var discoveryOpts []opencode.DiscoveryOption
+	if opts.openCodeMDNS {
+		discoveryOpts = append(discoveryOpts, opencode.WithMDNS())
+	}
+	servers := opencode.NewDiscovery(discoveryOpts...).Scan(ctx)
+	if len(servers) == 0 {
+		c.level, c.detail = doctorWarn, "no OpenCode server found"
+		c.fix = "load contrib/opencode-plugin in OpenCode, run opencode serve --port 4096, or pass -opencode-url (-no-opencode if unused)"
+		return c
+	}
+	urls := make([]string, len(servers))
+	for i, srv := range servers {
+		urls[i] = srv.URL
+	}
+	c.detail = fmt.Sprintf("%d servers: %s", len(servers), strings.Join(urls, ", "))
+	return c
+}
+
+func doctorTerminal(name string) doctorCheck {
+	c := doctorCheck{name: "terminal"}
+	if name != "" {
+		ctrl, err := terminal.NewNamedController(name)
+		if err != nil {
+			c.level, c.detail = doctorFail, err.Error()
+			c.fix = "fix the terminal's setup, or leave -terminal out to detect one"
+			return c
+		}
+		c.detail = describeTerminal(ctrl)
+		return c
+	}
+	ctrl := terminal.NewController()
+	if ctrl.Name() == "" {
+		c.level, c.detail = doctorWarn, "no terminal found whose font houston can control"
+		c.fix = "run houston from kitty (allow_remote_control, listen_on), WezTerm, Alacritty 0.13+, Ghostty, iTerm2 or Windows Terminal, or set HOUSTON_FONT_CMD"
+		return c
+	}
+	c.detail = describeTerminal(ctrl)
+	return c
+}
+
+func describeTerminal(ctrl terminal.TerminalController) string {
+	if ctrl.Name() == "" {
+		return "none (-terminal none)"
+	}
+	return ctrl.Name() + ": " + strings.Join(ctrl.Supports(), ", ")
+}
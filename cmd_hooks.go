@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// hookEvents are the Claude Code events scripts/claude-hook.sh handles.
+var hookEvents = []string{"Notification", "Stop", "SubagentStop", "PreToolUse"}
+
+// runInstallHooks implements `houston install-hooks`: install the Claude
+// Code hook script and register it in Claude's settings.json, so prompts
+// and finished turns reach houston at once. Running it again updates the
+// script and houston's hook commands in place and adds nothing twice.
+func runInstallHooks(args []string) int {
+	fs := flag.NewFlagSet("install-hooks", flag.ExitOnError)
+	opts := newOptions(fs)
+	dryRun := fs.Bool("dry-run", false, "Print what would change without writing anything")
+	hookURL := fs.String("url", "", "Post events to the houston at this URL (e.g. http://dashboard:9090) instead of writing status files")
+	events := fs.String("events", "Notification,Stop", "Comma-separated hook events to register ("+strings.Join(hookEvents, ", ")+")")
+	home, _ := os.UserHomeDir()
+	settingsPath := fs.String("settings", filepath.Join(home, ".claude", "settings.json"), "Claude Code settings file")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: houston install-hooks [-dry-run] [-url URL] [flags]\n\nInstalls the Claude Code hook script and adds it to Claude's settings.\nSafe to run again: existing houston hooks are updated, not duplicated.\n\nflags:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if path, _, err := opts.load(fs); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+		return 1
+	}
+	defaultStatusDir := filepath.Join(home, ".local", "state", "houston")
+	if opts.statusDir == "" {
+		opts.statusDir = defaultStatusDir
+	}
+	if opts.dataDir == "" {
+		opts.dataDir = filepath.Join(home, ".local", "share", "houston")
+	}
+
+	var names []string
+	for _, name := range strings.Split(*events, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(hookEvents, name) {
+			fmt.Fprintf(os.Stderr, "houston: unknown hook event %q; want %s\n", name, strings.Join(hookEvents, ", "))
+			return 2
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if *hookURL != "" {
+		if u, err := url.Parse(*hookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fmt.Fprintf(os.Stderr, "houston: -url %q: want an http:// or https:// URL\n", *hookURL)
+			return 2
+		}
+	}
+
+	scriptPath := filepath.Join(opts.dataDir, "claude-hook.sh")
+	command := hookCommand(scriptPath, *hookURL, opts.statusDir, defaultStatusDir)
+
+	settings, err := os.ReadFile(*settingsPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "houston: %v\n", err)
+		return 1
+	}
+	updated, changes, err := mergeHooks(settings, command, names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "houston: %s: %v\n", *settingsPath, err)
+		return 1
+	}
+
+	verb := ""
+	if *dryRun {
+		verb = "would "
+	}
+	current, _ := os.ReadFile(scriptPath)
+	scriptChanged := !bytes.Equal(current, claudeHookScript)
+	switch {
+	case current == nil:
+		fmt.Printf("%sinstall %s\n", verb, scriptPath)
+	case scriptChanged:
+		fmt.Printf("%supdate %s\n", verb, scriptPath)
+	default:
+		fmt.Printf("%s is up to date\n", scriptPath)
+	}
+	for _, change := range changes {
+		fmt.Printf("%s%s\n", verb, change)
+	}
+	settingsChanged := !bytes.Equal(settings, updated)
+	if !settingsChanged {
+		fmt.Printf("%s is up to date\n", *settingsPath)
+	}
+	if _, err := exec.LookPath("jq"); err != nil {
+		fmt.Println("note: the hook script needs jq, which is not on PATH")
+	}
+	if *dryRun {
+		if settingsChanged {
+			fmt.Printf("\n%s would read:\n%s", *settingsPath, updated)
+		}
+		return 0
+	}
+
+	if scriptChanged {
+		if err := os.MkdirAll(opts.dataDir, 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "houston: %v\n", err)
+			return 1
+		}
+		if err := writeFileAtomic(scriptPath, claudeHookScript, 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "houston: %v\n", err)
+			return 1
+		}
+	}
+	if settingsChanged {
+		path, perm := *settingsPath, os.FileMode(0o644)
+		if info, err := os.Stat(path); err == nil {
+			// Write through a link to settings kept with other dotfiles
+			if path, err = filepath.EvalSymlinks(path); err != nil {
+				fmt.Fprintf(os.Stderr, "houston: %v\n", err)
+				return 1
+			}
+			perm = info.Mode().Perm()
+		} else if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "houston: %v\n", err)
+			return 1
+		}
+		if settings != nil {
+			// Keep the settings as they were, in case the merge isn't wanted
+			if err := os.WriteFile(path+".bak", settings, 0o600); err != nil {
+				fmt.Fprintf(os.Stderr, "houston: %v\n", err)
+				return 1
+			}
+		}
+		if err := writeFileAtomic(path, updated, perm); err != nil {
+			fmt.Fprintf(os.Stderr, "houston: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// hookCommand is the settings.json command running the script at
+// scriptPath: posting to hookURL when set, otherwise writing status files
+// to statusDir, which the script is told about when it isn't its default.
+func hookCommand(scriptPath, hookURL, statusDir, defaultStatusDir string) string {
+	command := tmux.ShellQuote(scriptPath)
+	switch {
+	case hookURL != "":
+		command = "HOUSTON_URL=" + tmux.ShellQuote(hookURL) + " " + command
+	case statusDir != defaultStatusDir:
+		command = "HOUSTON_STATUS_DIR=" + tmux.ShellQuote(statusDir) + " " + command
+	}
+	return command
+}
+
+// mergeHooks returns the settings.json data with command registered as a
+// hook for each of events, and a line describing each change. An event's
+// existing houston hooks (see isHoustonHook) are pointed at command; an
+// event without one gets a new entry. Other settings and hooks, and the
+// order of keys, are kept.
+func mergeHooks(data []byte, command string, events []string) ([]byte, []string, error) {
+	var settings jsonObject
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return nil, nil, err
+		}
+	}
+	var hooks jsonObject
+	if raw, ok := settings.get("hooks"); ok {
+		if err := json.Unmarshal(raw, &hooks); err != nil {
+			return nil, nil, fmt.Errorf("hooks: %w", err)
+		}
+	}
+
+	var changes []string
+	for _, event := range events {
+		var matchers []json.RawMessage
+		if raw, ok := hooks.get(event); ok {
+			if err := json.Unmarshal(raw, &matchers); err != nil {
+				return nil, nil, fmt.Errorf("hooks.%s: %w", event, err)
+			}
+		}
+		found, updated := false, false
+		for i, raw := range matchers {
+			var matcher jsonObject
+			if err := json.Unmarshal(raw, &matcher); err != nil {
+				return nil, nil, fmt.Errorf("hooks.%s: %w", event, err)
+			}
+			var entries []jsonObject
+			if raw, ok := matcher.get("hooks"); ok {
+				if err := json.Unmarshal(raw, &entries); err != nil {
+					return nil, nil, fmt.Errorf("hooks.%s: %w", event, err)
+				}
+			}
+			changed := false
+			for j := range entries {
+				entry := &entries[j]
+				var cmd string
+				if raw, ok := entry.get("command"); !ok || json.Unmarshal(raw, &cmd) != nil || !isHoustonHook(cmd) {
+					continue
+				}
+				found = true
+				if cmd != command {
+					entry.set("command", command)
+					changed = true
+				}
+			}
+			if changed {
+				matcher.set("hooks", entries)
+				matchers[i] = mustMarshal(matcher)
+				updated = true
+			}
+		}
+		switch {
+		case !found:
+			var entry, matcher jsonObject
+			entry.set("type", "command")
+			entry.set("command", command)
+			matcher.set("hooks", []jsonObject{entry})
+			matchers = append(matchers, mustMarshal(matcher))
+			changes = append(changes, "add the "+event+" hook: "+command)
+		case updated:
+			changes = append(changes, "update the "+event+" hook: "+command)
+		default:
+			continue
+		}
+		hooks.set(event, matchers)
+	}
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+	settings.set("hooks", hooks)
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, mustMarshal(settings), "", "  "); err != nil {
+		return nil, nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), changes, nil
+}
+
+// jsonObject is a JSON object that keeps the order of its keys, so
+// rewriting a file someone edits by hand doesn't shuffle it.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+func (o jsonObject) get(key string) (json.RawMessage, bool) {
+	for _, m := range o {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return nil, false
+}
+
+// set replaces key's value, or appends key when o hasn't got it.
+func (o *jsonObject) set(key string, value any) {
+	raw := mustMarshal(value)
+	for i := range *o {
+		if (*o)[i].key == key {
+			(*o)[i].value = raw
+			return
+		}
+	}
+	*o = append(*o, jsonMember{key, raw})
+}
+
+func (o *jsonObject) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return fmt.Errorf("want a JSON object, got %s", data)
+	}
+	*o = nil
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		*o = append(*o, jsonMember{tok.(string), value})
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(mustMarshal(m.key))
+		b.WriteByte(':')
+		b.Write(m.value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// mustMarshal encodes v, which can't fail for the values used here,
+// without escaping <, > and & in hook commands.
+func mustMarshal(v any) json.RawMessage {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		panic(err)
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// writeFileAtomic replaces path with data, so a reader never sees half a
+// file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
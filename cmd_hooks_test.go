@@ -0,0 +1,154 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeHooks(t *testing.T) {
+	const command = "/data/claude-hook.sh"
+	tests := []struct {
+		name    string
+		in      string
+		events  []string
+		want    string // "" when the settings don't change
+		changes []string
+		wantErr bool
+	}{
+		{
+			name:   "empty file",
+			events: []string{"Notification", "Stop"},
+			want: `{
+  "hooks": {
+    "Notification": [
+      {
+        "hooks": [
+          {
+            "type": "command",
+            "command": "/data/claude-hook.sh"
+          }
+        ]
+      }
+    ],
+    "Stop": [
+      {
+        "hooks": [
+          {
+            "type": "command",
+            "command": "/data/claude-hook.sh"
+          }
+        ]
+      }
+    ]
+  }
+}
+`,
+			changes: []string{"add the Notification hook: " + command, "add the Stop hook: " + command},
+		},
+		{
+			name:   "existing houston hook",
+			in:     `{"model":"opus","hooks":{"Stop":[{"hooks":[{"command":"/old/claude-hook.sh","type":"command","timeout":5}]}]},"env":{}}`,
+			events: []string{"Stop"},
+			want: `{
+  "model": "opus",
+  "hooks": {
+    "Stop": [
+      {
+        "hooks": [
+          {
+            "command": "/data/claude-hook.sh",
+            "type": "command",
+            "timeout": 5
+          }
+        ]
+      }
+    ]
+  },
+  "env": {}
+}
+`,
+			changes: []string{"update the Stop hook: " + command},
+		},
+		{
+			name:   "up to date",
+			in:     `{"hooks":{"Stop":[{"hooks":[{"type":"command","command":"/data/claude-hook.sh"}]}]}}`,
+			events: []string{"Stop"},
+		},
+		{
+			name:   "foreign hook",
+			in:     `{"hooks":{"Stop":[{"matcher":"","hooks":[{"type":"command","command":"notify-send done"}]}]}}`,
+			events: []string{"Stop"},
+			want: `{
+  "hooks": {
+    "Stop": [
+      {
+        "matcher": "",
+        "hooks": [
+          {
+            "type": "command",
+            "command": "notify-send done"
+          }
+        ]
+      },
+      {
+        "hooks": [
+          {
+            "type": "command",
+            "command": "/data/claude-hook.sh"
+          }
+        ]
+      }
+    ]
+  }
+}
+`,
+			changes: []string{"add the Stop hook: " + command},
+		},
+		{name: "malformed JSON", in: `{"hooks": {`, events: []string{"Stop"}, wantErr: true},
+		{name: "hooks not an object", in: `{"hooks": []}`, events: []string{"Stop"}, wantErr: true},
+		{name: "event not a list", in: `{"hooks": {"Stop": {}}}`, events: []string{"Stop"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changes, err := mergeHooks([]byte(tt.in), command, tt.events)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("mergeHooks(%s) = %s, want an error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeHooks(%s): %v", tt.in, err)
+			}
+			want := tt.want
+			if want == "" {
+				want = tt.in
+			}
+			if string(got) != want {
+				t.Errorf("mergeHooks(%s) = %s, want %s", tt.in, got, want)
+			}
+			if !reflect.DeepEqual(changes, tt.changes) {
+				t.Errorf("mergeHooks(%s) changes = %q, want %q", tt.in, changes, tt.changes)
+			}
+		})
+	}
+}
+
+func TestHookCommand(t *testing.T) {
+	const defaultDir = "/home/me/.local/state/houston"
+	tests := []struct {
+		url, statusDir string
+		want           string
+	}{
+		{"", defaultDir, "/data/claude-hook.sh"},
+		{"http://dash:9090", defaultDir, "HOUSTON_URL=http://dash:9090 /data/claude-hook.sh"},
+		{"", "/tmp/my status", "HOUSTON_STATUS_DIR='/tmp/my status' /data/claude-hook.sh"},
+	}
+
+	for _, tt := range tests {
+		if got := hookCommand("/data/claude-hook.sh", tt.url, tt.statusDir, defaultDir); got != tt.want {
+			t.Errorf("hookCommand(%q, %q) = %s, want %s", tt.url, tt.statusDir, got, tt.want)
+		}
+	}
+}
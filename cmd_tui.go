@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/noamsto/houston/server"
+	"github.com/noamsto/houston/tmux"
+	"github.com/noamsto/houston/tui"
+)
+
+// runTUI implements `houston tui`: the dashboard in the terminal, from a
+// running server. Enter switches this tmux client to the selected pane.
+func runTUI(args []string) int {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	addr := fs.String("addr", "", "houston server address (default from $HOUSTON_ADDR or the config file)")
+	interval := fs.Duration("interval", 0, "How often to refresh (default 2s)")
+	_ = fs.Parse(args)
+
+	a, err := serverAddr(*addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	err = tui.Run(tui.Config{
+		Fetch:    func() (*server.SessionsData, error) { return fetchSessions(a) },
+		Focus:    func(p tmux.Pane) error { return focusPane(a, p) },
+		Interval: *interval,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tui: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// focusPane asks the server to switch a tmux client to the pane: the one
+// running this command when inside tmux on the same host, otherwise the
+// most recently active one. The terminal window is left where it is.
+func focusPane(addr string, p tmux.Pane) error {
+	q := url.Values{"raise": {"false"}}
+	if p.Host != "" {
+		q.Set("host", p.Host)
+	} else if os.Getenv("TMUX") != "" {
+		if out, err := exec.Command("tmux", "display-message", "-p", "#{client_tty}").Output(); err == nil {
+			q.Set("client", strings.TrimSpace(string(out)))
+		}
+	}
+	client, base := apiEndpoint(addr)
+	u := base + "/api/pane/" + url.PathEscape(p.Target()) + "/focus?" + q.Encode()
+	resp, err := client.Post(u, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/noamsto/houston/update"
+)
+
+// runUpdate implements `houston update`: check GitHub for a newer release
+// and replace this binary with it after verifying its checksum.
+func runUpdate(args []string) int {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	channel := fs.String("channel", "stable", "Release channel: stable or prerelease")
+	checkOnly := fs.Bool("check", false, "Only report whether an update is available")
+	force := fs.Bool("force", false, "Install the latest release even if it is not newer")
+	_ = fs.Parse(args)
+
+	ch, err := update.ParseChannel(*channel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	current := update.BuildVersion(version)
+	checker := update.NewChecker(current, ch)
+	rel, err := checker.Latest(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "update check failed: %v\n", err)
+		return 1
+	}
+
+	newer := update.Newer(rel.Tag, current)
+	fmt.Printf("current: %s\nlatest:  %s (%s)\n", current, rel.Tag, ch)
+	if *checkOnly {
+		if newer {
+			fmt.Printf("update available: %s\n", rel.URL)
+		}
+		return 0
+	}
+	if !newer && !*force {
+		fmt.Println("already up to date")
+		return 0
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "locate executable: %v\n", err)
+		return 1
+	}
+
+	if err := update.Apply(ctx, checker.HTTP, rel, runtime.GOOS, runtime.GOARCH, exe); err != nil {
+		fmt.Fprintf(os.Stderr, "update failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("updated %s to %s; restart houston to use it\n", exe, rel.Tag)
+	return 0
+}
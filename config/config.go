@@ -0,0 +1,183 @@
+// Package config fills houston's command-line flags from a YAML file and
+// HOUSTON_* environment variables.
+//
+// File keys are flag names. Nested sections are joined with "-", so
+//
+//	notify:
+//	  cmd: notify-send "$HOUSTON_TITLE" "$HOUSTON_BODY"
+//	  webhook: https://ntfy.sh/my-topic
+//
+// sets -notify-cmd and -notify-webhook. Repeatable flags take lists. A flag
+// given on the command line wins over its environment variable
+// (HOUSTON_NOTIFY_CMD), which wins over the file.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// List is a repeatable flag value collecting every occurrence. Config
+// files give it as a YAML list, environment variables as space-separated
+// values.
+type List []string
+
+func (l *List) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *List) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// DefaultPath returns ~/.config/houston/config.yaml, honoring
+// XDG_CONFIG_HOME.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "houston", "config.yaml")
+}
+
+// Values are settings read from a file, by flag name. Lists hold every
+// value of a repeatable flag.
+type Values map[string][]string
+
+// Load reads a config file. A missing file is an error only when
+// required; otherwise it yields no values.
+func Load(path string, required bool) (Values, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return Values{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a YAML config document.
+func Parse(data []byte) (Values, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	values := Values{}
+	if len(doc.Content) == 0 {
+		return values, nil // Empty file
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config line %d: expected a mapping of settings", root.Line)
+	}
+	if err := flatten(values, "", root); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flatten collects the scalars and lists under node, naming each by its
+// path of keys joined with "-".
+func flatten(values Values, prefix string, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if prefix != "" {
+				key = prefix + "-" + key
+			}
+			if err := flatten(values, key, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return fmt.Errorf("config line %d: %s: list items must be plain values", item.Line, prefix)
+			}
+			values[prefix] = append(values[prefix], item.Value)
+		}
+		if len(node.Content) == 0 {
+			values[prefix] = []string{}
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return nil // "key:" with no value leaves the default
+		}
+		values[prefix] = append(values[prefix], node.Value)
+	case yaml.AliasNode:
+		return flatten(values, prefix, node.Alias)
+	default:
+		return fmt.Errorf("config line %d: unsupported value for %s", node.Line, prefix)
+	}
+	return nil
+}
+
+// EnvName returns the environment variable overriding a flag:
+// HOUSTON_ followed by the flag name in upper case, "-" as "_".
+func EnvName(flagName string) string {
+	return "HOUSTON_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Apply sets every flag not given on the command line from its environment
+// variable, or else from the file values. Unknown file keys and invalid
+// values are errors. getenv is usually os.LookupEnv.
+func Apply(fs *flag.FlagSet, values Values, getenv func(string) (string, bool)) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	var unknown []string
+	for name := range values {
+		if fs.Lookup(name) == nil {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("config: unknown settings: %s", strings.Join(unknown, ", "))
+	}
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		if explicit[f.Name] || f.Name == "config" {
+			return
+		}
+		_, repeatable := f.Value.(*List)
+
+		var vals []string
+		source := "config"
+		if env, ok := getenv(EnvName(f.Name)); ok {
+			source = EnvName(f.Name)
+			vals = []string{env}
+			if repeatable {
+				vals = strings.Fields(env)
+			}
+		} else if v, ok := values[f.Name]; ok {
+			vals = v
+			if len(vals) > 1 && !repeatable {
+				errs = append(errs, fmt.Errorf("%s: %s takes a single value, not a list", source, f.Name))
+				return
+			}
+		}
+		for _, v := range vals {
+			if err := f.Value.Set(v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid value %q for %s: %w", source, v, f.Name, err))
+				return
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
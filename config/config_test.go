@@ -0,0 +1,111 @@
+package config
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	values, err := Parse([]byte(`
+addr: 127.0.0.1:9191
+debug: true
+remote:
+  - me@devbox
+  - ci@builder
+notify:
+  cmd: notify-send "$HOUSTON_TITLE"
+  webhook:
+poll:
+  pane: 500ms
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Values{
+		"addr":       {"127.0.0.1:9191"},
+		"debug":      {"true"},
+		"remote":     {"me@devbox", "ci@builder"},
+		"notify-cmd": {`notify-send "$HOUSTON_TITLE"`},
+		"poll-pane":  {"500ms"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("Parse() = %v, want %v", values, want)
+	}
+
+	if _, err := Parse([]byte("- a\n- b\n")); err == nil {
+		t.Error("Parse(top-level list) succeeded, want error")
+	}
+	if values, err := Parse(nil); err != nil || len(values) != 0 {
+		t.Errorf("Parse(empty) = %v, %v; want no values", values, err)
+	}
+}
+
+func testFlags() (*flag.FlagSet, *string, *time.Duration, *bool, *List) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:9090", "")
+	poll := fs.Duration("poll-pane", 200*time.Millisecond, "")
+	debug := fs.Bool("debug", false, "")
+	var remotes List
+	fs.Var(&remotes, "remote", "")
+	return fs, addr, poll, debug, &remotes
+}
+
+func TestApplyPrecedence(t *testing.T) {
+	fs, addr, poll, debug, remotes := testFlags()
+	if err := fs.Parse([]string{"-addr", "0.0.0.0:1"}); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"HOUSTON_POLL_PANE": "1s", "HOUSTON_REMOTE": "a@x b@y"}
+	getenv := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+	values := Values{
+		"addr":      {"127.0.0.1:7"},
+		"poll-pane": {"5s"},
+		"debug":     {"true"},
+		"remote":    {"file@host"},
+	}
+
+	if err := Apply(fs, values, getenv); err != nil {
+		t.Fatal(err)
+	}
+	if *addr != "0.0.0.0:1" {
+		t.Errorf("addr = %q, want the command line value", *addr)
+	}
+	if *poll != time.Second {
+		t.Errorf("poll-pane = %v, want the environment value", *poll)
+	}
+	if !*debug {
+		t.Error("debug = false, want the file value")
+	}
+	if want := (List{"a@x", "b@y"}); !reflect.DeepEqual(*remotes, want) {
+		t.Errorf("remote = %v, want %v", *remotes, want)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+	tests := []struct {
+		name   string
+		values Values
+		want   string
+	}{
+		{"unknown key", Values{"adress": {"x"}}, "unknown settings: adress"},
+		{"bad value", Values{"poll-pane": {"often"}}, `invalid value "often" for poll-pane`},
+		{"list for single value", Values{"addr": {"a", "b"}}, "addr takes a single value"},
+	}
+	for _, tt := range tests {
+		fs, _, _, _, _ := testFlags()
+		err := Apply(fs, tt.values, noEnv)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Apply() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("notify-cmd"); got != "HOUSTON_NOTIFY_CMD" {
+		t.Errorf("EnvName(notify-cmd) = %q", got)
+	}
+}
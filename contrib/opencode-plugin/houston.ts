@@ -8,12 +8,15 @@
  * - Adds houston origin to CORS allowlist
  * 
  * Discovery file: ~/.local/state/houston/opencode-servers/{pid}.json
+ * Beacon (optional): set HOUSTON_BEACON=host:port to also announce the
+ * server over UDP to a houston running with -opencode-beacon
  */
 
 import type { Plugin } from "@opencode-ai/plugin"
 import * as fs from "fs"
 import * as path from "path"
 import * as os from "os"
+import * as dgram from "dgram"
 
 const DISCOVERY_DIR = path.join(os.homedir(), ".local", "state", "houston", "opencode-servers")
 
@@ -56,6 +59,26 @@ function removeServerInfo(pid: number) {
   }
 }
 
+// Beacons repeat so a restarted houston finds the server again
+const BEACON_INTERVAL_MS = 30_000
+
+function startBeacon(target: string, info: ServerInfo) {
+  const idx = target.lastIndexOf(":")
+  const host = target.slice(0, idx)
+  const port = parseInt(target.slice(idx + 1), 10)
+  if (idx <= 0 || isNaN(port)) return
+
+  const socket = dgram.createSocket("udp4")
+  socket.unref()
+  const send = () => {
+    socket.send(JSON.stringify(info), port, host, () => {
+      // Ignore: houston may not be running
+    })
+  }
+  send()
+  setInterval(send, BEACON_INTERVAL_MS).unref()
+}
+
 function cleanupStale() {
   ensureDir(DISCOVERY_DIR)
   try {
@@ -105,6 +128,11 @@ export const HoustonPlugin: Plugin = async ({ project, directory, serverUrl }) =
   }
   
   writeServerInfo(info)
+
+  const beacon = process.env.HOUSTON_BEACON
+  if (beacon) {
+    startBeacon(beacon, info)
+  }
   
   return {}
 }
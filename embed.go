@@ -4,3 +4,8 @@ import "embed"
 
 //go:embed ui/dist
 var uiFS embed.FS
+
+// claudeHookScript is installed by `houston install-hooks`.
+//
+//go:embed scripts/claude-hook.sh
+var claudeHookScript []byte
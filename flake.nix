@@ -50,7 +50,7 @@
             pname = "houston";
             version = "0.1.0";
             src = pkgs.lib.cleanSource ./.;
-            vendorHash = "sha256-0Qxw+MUYVgzgWB8vi3HBYtVXSq/btfh4ZfV/m1chNrA=";
+            vendorHash = "sha256-rLa0MX+N9CWXcpq3fOYncq0PF+Sk8LPJckZTG3yuGFc=";
 
             preBuild = ''
               mkdir -p ui/dist
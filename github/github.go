@@ -0,0 +1,190 @@
+// Package github looks up the open pull request of a branch with the gh
+// CLI: its number, CI status and review state, so work waiting on a red
+// build or a reviewer shows up next to the window that produced it.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// refreshAfter is how long a lookup is reused before it is refreshed in
+	// the background.
+	refreshAfter = 2 * time.Minute
+
+	// lookupTimeout bounds a single gh call.
+	lookupTimeout = 30 * time.Second
+
+	// maxLookups bounds concurrent gh calls.
+	maxLookups = 2
+)
+
+// CI statuses, summarizing every check of the PR's head commit.
+const (
+	CIPassing = "passing"
+	CIFailing = "failing"
+	CIPending = "pending"
+)
+
+// PR is an open pull request.
+type PR struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Draft  bool   `json:"draft,omitempty"`
+	CI     string `json:"ci,omitempty"`     // passing, failing, pending; empty without checks
+	Review string `json:"review,omitempty"` // approved, changes_requested, review_required; empty when no review is required
+}
+
+// ghPR is the subset of gh pr list --json output a PR is made from.
+type ghPR struct {
+	Number            int       `json:"number"`
+	Title             string    `json:"title"`
+	URL               string    `json:"url"`
+	IsDraft           bool      `json:"isDraft"`
+	ReviewDecision    string    `json:"reviewDecision"`
+	StatusCheckRollup []ghCheck `json:"statusCheckRollup"`
+}
+
+// ghCheck is a check run (Status, Conclusion) or a commit status (State).
+type ghCheck struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+const ghFields = "number,title,url,isDraft,reviewDecision,statusCheckRollup"
+
+// Lookup returns the open pull request whose head is branch in the
+// repository containing dir, or nil if there is none.
+func Lookup(ctx context.Context, dir, branch string) (*PR, error) {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "list", "--head", branch, "--state", "open", "--limit", "1", "--json", ghFields)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return nil, fmt.Errorf("gh pr list: %s", strings.TrimSpace(string(exit.Stderr)))
+		}
+		return nil, fmt.Errorf("gh pr list: %w", err)
+	}
+	return parsePRs(out)
+}
+
+// parsePRs parses gh pr list --json output, returning its first PR.
+func parsePRs(data []byte) (*PR, error) {
+	var prs []ghPR
+	if err := json.Unmarshal(data, &prs); err != nil {
+		return nil, fmt.Errorf("parse gh output: %w", err)
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+	p := prs[0]
+	return &PR{
+		Number: p.Number,
+		Title:  p.Title,
+		URL:    p.URL,
+		Draft:  p.IsDraft,
+		CI:     ciStatus(p.StatusCheckRollup),
+		Review: strings.ToLower(p.ReviewDecision),
+	}, nil
+}
+
+// ciStatus summarizes checks: failing if any failed, else pending if any
+// hasn't finished, else passing.
+func ciStatus(checks []ghCheck) string {
+	if len(checks) == 0 {
+		return ""
+	}
+	status := CIPassing
+	for _, c := range checks {
+		if c.State != "" { // Commit status
+			switch c.State {
+			case "FAILURE", "ERROR":
+				return CIFailing
+			case "PENDING", "EXPECTED":
+				status = CIPending
+			}
+			continue
+		}
+		if c.Status != "COMPLETED" {
+			status = CIPending
+			continue
+		}
+		switch c.Conclusion {
+		case "FAILURE", "TIMED_OUT", "CANCELLED", "ACTION_REQUIRED", "STARTUP_FAILURE":
+			return CIFailing
+		}
+	}
+	return status
+}
+
+// Tracker caches pull request lookups per directory and branch. Lookups
+// run in the background, so callers get the last known result at once.
+type Tracker struct {
+	lookup func(ctx context.Context, dir, branch string) (*PR, error)
+	sem    chan struct{}
+
+	mu      sync.Mutex
+	entries map[string]*trackerEntry
+}
+
+type trackerEntry struct {
+	pr      *PR
+	checked time.Time
+	pending bool // A lookup is running
+}
+
+// NewTracker returns a tracker looking pull requests up with gh.
+func NewTracker() *Tracker {
+	return &Tracker{lookup: Lookup, sem: make(chan struct{}, maxLookups), entries: make(map[string]*trackerEntry)}
+}
+
+// PR returns the last known open pull request for branch in dir, and
+// starts refreshing it when it is older than a couple of minutes. It
+// returns nil until the first lookup finishes.
+func (t *Tracker) PR(dir, branch string) *PR {
+	if dir == "" || branch == "" {
+		return nil
+	}
+	key := dir + "\x00" + branch
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		e = &trackerEntry{}
+		t.entries[key] = e
+	}
+	if !e.pending && time.Since(e.checked) >= refreshAfter {
+		e.pending = true
+		go t.refresh(e, dir, branch)
+	}
+	return e.pr
+}
+
+func (t *Tracker) refresh(e *trackerEntry, dir, branch string) {
+	t.sem <- struct{}{}
+	defer func() { <-t.sem }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	pr, err := t.lookup(ctx, dir, branch)
+	if err != nil {
+		slog.Debug("pull request lookup failed", "dir", dir, "branch", branch, "error", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Keep the last result through a failed lookup (offline, rate limited)
+	if err == nil {
+		e.pr = pr
+	}
+	e.checked, e.pending = time.Now(), false
+}
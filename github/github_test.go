@@ -0,0 +1,107 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParsePRs(t *testing.T) {
+	out := `[{"number":42,"title":"Add things","url":"https://github.com/o/r/pull/42","isDraft":true,"reviewDecision":"CHANGES_REQUESTED",
+		"statusCheckRollup":[{"__typename":"CheckRun","status":"COMPLETED","conclusion":"SUCCESS"},{"__typename":"StatusContext","state":"PENDING"}]}]`
+	pr, err := parsePRs([]byte(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PR{Number: 42, Title: "Add things", URL: "https://github.com/o/r/pull/42", Draft: true, CI: CIPending, Review: "changes_requested"}
+	if pr == nil || *pr != want {
+		t.Errorf("parsePRs() = %+v, want %+v", pr, want)
+	}
+
+	if pr, err := parsePRs([]byte("[]")); pr != nil || err != nil {
+		t.Errorf("parsePRs([]) = %+v, %v, want nil", pr, err)
+	}
+	if _, err := parsePRs([]byte("not json")); err == nil {
+		t.Error("parsePRs(invalid) = nil error")
+	}
+}
+
+func TestCIStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []ghCheck
+		want   string
+	}{
+		{"none", nil, ""},
+		{"passing", []ghCheck{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {Status: "COMPLETED", Conclusion: "SKIPPED"}, {State: "SUCCESS"}}, CIPassing},
+		{"running", []ghCheck{{Status: "COMPLETED", Conclusion: "SUCCESS"}, {Status: "IN_PROGRESS"}}, CIPending},
+		{"failed run", []ghCheck{{Status: "IN_PROGRESS"}, {Status: "COMPLETED", Conclusion: "FAILURE"}}, CIFailing},
+		{"failed status", []ghCheck{{State: "PENDING"}, {State: "ERROR"}}, CIFailing},
+	}
+	for _, tt := range tests {
+		if got := ciStatus(tt.checks); got != tt.want {
+			t.Errorf("%s: ciStatus() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	calls := make(chan string, 10)
+	fail := false
+	tr := NewTracker()
+	tr.lookup = func(ctx context.Context, dir, branch string) (*PR, error) {
+		calls <- branch
+		if fail {
+			return nil, errors.New("offline")
+		}
+		return &PR{Number: 7}, nil
+	}
+	wait := func() {
+		t.Helper()
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatal("no lookup")
+		}
+		// Let refresh store the result
+		for i := 0; i < 100; i++ {
+			tr.mu.Lock()
+			pending := tr.entries["/repo\x00feature"].pending
+			tr.mu.Unlock()
+			if !pending {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatal("lookup didn't finish")
+	}
+
+	if pr := tr.PR("/repo", "feature"); pr != nil {
+		t.Errorf("PR() before the first lookup = %+v, want nil", pr)
+	}
+	wait()
+	if pr := tr.PR("/repo", "feature"); pr == nil || pr.Number != 7 {
+		t.Errorf("PR() = %+v, want #7", pr)
+	}
+	select {
+	case <-calls:
+		t.Error("fresh result was looked up again")
+	default:
+	}
+
+	// A failed refresh keeps the last result
+	fail = true
+	tr.mu.Lock()
+	tr.entries["/repo\x00feature"].checked = time.Time{}
+	tr.mu.Unlock()
+	tr.PR("/repo", "feature")
+	wait()
+	if pr := tr.PR("/repo", "feature"); pr == nil || pr.Number != 7 {
+		t.Errorf("PR() after a failed refresh = %+v, want #7", pr)
+	}
+
+	if pr := tr.PR("/repo", ""); pr != nil {
+		t.Errorf("PR() without a branch = %+v", pr)
+	}
+}
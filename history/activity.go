@@ -0,0 +1,79 @@
+package history
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/noamsto/houston/store"
+)
+
+// activityLogPrefix names the store logs holding activity samples, one log
+// per day ("activity-2006-01-02") so a timeline reads only the days it
+// covers.
+const activityLogPrefix = "activity-"
+
+// ActivityStep is the interval between activity samples.
+const ActivityStep = time.Minute
+
+// activitySample is one line of an activity log: the state of every
+// session at a minute.
+type activitySample struct {
+	At       time.Time         `json:"at"`
+	Sessions map[string]string `json:"sessions"` // Session key (host|name) -> state
+}
+
+// Sample is a session's state at one minute of its timeline.
+type Sample struct {
+	At    time.Time `json:"at"`
+	State string    `json:"state"` // working, attention or idle
+}
+
+// ActivityRecorder appends per-minute session states to daily logs in the
+// store.
+type ActivityRecorder struct {
+	store *store.Store
+}
+
+// NewActivityRecorder returns a recorder persisting to st.
+func NewActivityRecorder(st *store.Store) *ActivityRecorder {
+	return &ActivityRecorder{store: st}
+}
+
+// Record appends the state of every session (by session key) at now,
+// truncated to the minute.
+func (r *ActivityRecorder) Record(states map[string]string, now time.Time) error {
+	if len(states) == 0 {
+		return nil
+	}
+	at := now.Truncate(ActivityStep)
+	return r.store.Append(activityLog(at), activitySample{At: at, Sessions: states})
+}
+
+// Timeline returns a session's samples from since up to until, oldest
+// first. Minutes houston wasn't running, or the session didn't exist,
+// have no sample.
+func (r *ActivityRecorder) Timeline(session string, since, until time.Time) ([]Sample, error) {
+	result := []Sample{}
+	day := time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, since.Location())
+	for ; !day.After(until); day = day.AddDate(0, 0, 1) {
+		err := r.store.ReadLog(activityLog(day), func(raw json.RawMessage) error {
+			var s activitySample
+			if err := json.Unmarshal(raw, &s); err != nil {
+				return nil
+			}
+			state, ok := s.Sessions[session]
+			if ok && !s.At.Before(since) && !s.At.After(until) {
+				result = append(result, Sample{At: s.At, State: state})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func activityLog(day time.Time) string {
+	return activityLogPrefix + day.Format(time.DateOnly)
+}
@@ -0,0 +1,50 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/store"
+)
+
+func TestActivityTimeline(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewActivityRecorder(st)
+	start := time.Date(2026, 3, 1, 23, 58, 30, 0, time.Local) // Spans midnight
+
+	for i, state := range []string{StateWorking, StateWorking, StateAttention, StateIdle} {
+		states := map[string]string{"work": state, "devbox|api": StateIdle}
+		if err := r.Record(states, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Record(map[string]string{}, start.Add(5*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	samples, err := r.Timeline("work", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first sample is truncated to 23:58, before since
+	want := []Sample{
+		{At: time.Date(2026, 3, 1, 23, 59, 0, 0, time.Local), State: StateWorking},
+		{At: time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local), State: StateAttention},
+		{At: time.Date(2026, 3, 2, 0, 1, 0, 0, time.Local), State: StateIdle},
+	}
+	if len(samples) != len(want) {
+		t.Fatalf("Timeline() = %+v, want %+v", samples, want)
+	}
+	for i := range want {
+		if !samples[i].At.Equal(want[i].At) || samples[i].State != want[i].State {
+			t.Errorf("sample %d = %+v, want %+v", i, samples[i], want[i])
+		}
+	}
+
+	if samples, err := r.Timeline("gone", start, start.Add(time.Hour)); err != nil || len(samples) != 0 {
+		t.Errorf("Timeline(gone) = %+v, %v, want no samples", samples, err)
+	}
+}
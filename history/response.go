@@ -0,0 +1,184 @@
+// Package history records how agent sessions and their operator behave over
+// time, persisted as append-only logs in the store.
+package history
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/store"
+)
+
+// responsesLog is the store log holding answered prompts.
+const responsesLog = "responses"
+
+// Response is one prompt that needed attention and was answered.
+type Response struct {
+	Window   string    `json:"window"`  // Window key (host|session:window)
+	Session  string    `json:"session"` // tmux session name
+	Kind     string    `json:"kind"`    // question, choice, or error
+	Started  time.Time `json:"started"` // When the window started needing attention
+	Answered time.Time `json:"answered"`
+	Via      string    `json:"via"` // "houston" (input sent through houston) or "terminal"
+}
+
+// Wait returns how long the prompt waited for an answer.
+func (r Response) Wait() time.Duration {
+	return r.Answered.Sub(r.Started)
+}
+
+// Pending is a prompt currently waiting for an answer.
+type Pending struct {
+	Window  string    `json:"window"`
+	Session string    `json:"session"`
+	Kind    string    `json:"kind"`
+	Started time.Time `json:"started"`
+	Waiting float64   `json:"waiting_seconds"`
+}
+
+type pendingPrompt struct {
+	session  string
+	kind     string
+	started  time.Time
+	seen     time.Time // Last observed; zero for a prompt restored from a previous process
+	answered bool      // Input was sent; waiting for the prompt to clear
+}
+
+// ResponseTracker measures the time between a window entering an attention
+// state and an answer arriving, and appends each answer to the store.
+type ResponseTracker struct {
+	store   *store.Store
+	mu      sync.Mutex
+	pending map[string]*pendingPrompt
+}
+
+// NewResponseTracker returns a tracker persisting to st.
+func NewResponseTracker(st *store.Store) *ResponseTracker {
+	return &ResponseTracker{store: st, pending: make(map[string]*pendingPrompt)}
+}
+
+// Observe records a window's current attention state. kind is the parse
+// result type when needsAttention is true. A prompt that clears without
+// input from houston was answered in the terminal.
+func (t *ResponseTracker) Observe(window, session, kind string, needsAttention bool, now time.Time) {
+	t.mu.Lock()
+	p, ok := t.pending[window]
+	switch {
+	case needsAttention && !ok:
+		t.pending[window] = &pendingPrompt{session: session, kind: kind, started: now, seen: now}
+		t.mu.Unlock()
+	case !needsAttention && ok:
+		delete(t.pending, window)
+		t.mu.Unlock()
+		if !p.answered {
+			t.record(Response{Window: window, Session: p.session, Kind: p.kind, Started: p.started, Answered: now, Via: "terminal"})
+		}
+	default:
+		if ok {
+			p.seen = now
+		}
+		t.mu.Unlock()
+	}
+}
+
+// Prune forgets the prompts of windows not observed since before, such as
+// windows closed while they waited. Call it after observing every window.
+func (t *ResponseTracker) Prune(before time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for window, p := range t.pending {
+		if p.seen.Before(before) {
+			delete(t.pending, window)
+		}
+	}
+}
+
+// Answered records that input was sent to a window through houston.
+func (t *ResponseTracker) Answered(window string, now time.Time) {
+	t.mu.Lock()
+	p, ok := t.pending[window]
+	if !ok || p.answered {
+		t.mu.Unlock()
+		return
+	}
+	p.answered = true
+	t.mu.Unlock()
+
+	t.record(Response{Window: window, Session: p.session, Kind: p.kind, Started: p.started, Answered: now, Via: "houston"})
+}
+
+// Since returns when a window started needing attention. ok is false when
+// it doesn't need attention or the prompt was already answered through
+// houston.
+func (t *ResponseTracker) Since(window string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.pending[window]
+	if !ok || p.answered {
+		return time.Time{}, false
+	}
+	return p.started, true
+}
+
+// Pending returns prompts currently waiting for an answer, longest first.
+func (t *ResponseTracker) Pending(now time.Time) []Pending {
+	t.mu.Lock()
+	result := []Pending{}
+	for window, p := range t.pending {
+		if p.answered {
+			continue
+		}
+		result = append(result, Pending{
+			Window:  window,
+			Session: p.session,
+			Kind:    p.kind,
+			Started: p.started,
+			Waiting: now.Sub(p.started).Seconds(),
+		})
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Started.Before(result[j].Started) })
+	return result
+}
+
+// Restore carries over prompts that were waiting in a previous houston
+// process, keeping their original start times.
+func (t *ResponseTracker) Restore(pending []Pending) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range pending {
+		if cur, ok := t.pending[p.Window]; ok {
+			if p.Started.Before(cur.started) {
+				cur.started = p.Started
+			}
+			continue
+		}
+		t.pending[p.Window] = &pendingPrompt{session: p.Session, kind: p.Kind, started: p.Started}
+	}
+}
+
+// Responses returns answered prompts since the given time, oldest first.
+func (t *ResponseTracker) Responses(since time.Time) ([]Response, error) {
+	var result []Response
+	err := t.store.ReadLog(responsesLog, func(raw json.RawMessage) error {
+		var r Response
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return nil
+		}
+		if !r.Answered.Before(since) {
+			result = append(result, r)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (t *ResponseTracker) record(r Response) {
+	if err := t.store.Append(responsesLog, r); err != nil {
+		slog.Warn("failed to record response time", "window", r.Window, "error", err)
+	}
+}
@@ -0,0 +1,149 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/store"
+)
+
+func newTestTracker(t *testing.T) *ResponseTracker {
+	t.Helper()
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewResponseTracker(st)
+}
+
+func TestResponseTrackerAnsweredViaHouston(t *testing.T) {
+	tr := newTestTracker(t)
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+
+	tr.Observe("work:1", "work", "question", true, start)
+	tr.Observe("work:1", "work", "question", true, start.Add(10*time.Second)) // still waiting
+	if p := tr.Pending(start.Add(20 * time.Second)); len(p) != 1 || p[0].Waiting != 20 {
+		t.Fatalf("Pending() = %+v, want one prompt waiting 20s", p)
+	}
+
+	tr.Answered("work:1", start.Add(30*time.Second))
+	// Prompt is still on screen right after sending; must not restart the clock
+	tr.Observe("work:1", "work", "question", true, start.Add(31*time.Second))
+	tr.Observe("work:1", "work", "", false, start.Add(32*time.Second))
+
+	responses, err := tr.Responses(time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 1 {
+		t.Fatalf("Responses() = %+v, want 1 response", responses)
+	}
+	if r := responses[0]; r.Wait() != 30*time.Second || r.Via != "houston" || r.Kind != "question" {
+		t.Errorf("response = %+v (wait %v), want 30s via houston", r, r.Wait())
+	}
+	if p := tr.Pending(start.Add(time.Minute)); len(p) != 0 {
+		t.Errorf("Pending() after answer = %+v, want none", p)
+	}
+}
+
+func TestResponseTrackerAnsweredInTerminal(t *testing.T) {
+	tr := newTestTracker(t)
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+
+	tr.Observe("work:2", "work", "choice", true, start)
+	tr.Observe("work:2", "work", "", false, start.Add(5*time.Second))
+	tr.Answered("work:2", start.Add(6*time.Second)) // nothing pending: ignored
+
+	responses, _ := tr.Responses(time.Time{})
+	if len(responses) != 1 || responses[0].Via != "terminal" || responses[0].Wait() != 5*time.Second {
+		t.Errorf("Responses() = %+v, want one 5s terminal answer", responses)
+	}
+}
+
+func TestResponseTrackerPrune(t *testing.T) {
+	tr := newTestTracker(t)
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+
+	tr.Observe("work:1", "work", "question", true, start)
+	tr.Observe("work:2", "work", "choice", true, start)
+	tr.Restore([]Pending{{Window: "old:1", Session: "old", Kind: "question", Started: start.Add(-time.Hour)}})
+
+	// The next build sees only work:1; work:2 was closed and old:1 is gone
+	build := start.Add(3 * time.Second)
+	tr.Observe("work:1", "work", "question", true, build)
+	tr.Prune(build)
+	if p := tr.Pending(build); len(p) != 1 || p[0].Window != "work:1" || !p[0].Started.Equal(start) {
+		t.Errorf("Pending() after Prune = %+v, want work:1 waiting since the start", p)
+	}
+	if responses, _ := tr.Responses(time.Time{}); len(responses) != 0 {
+		t.Errorf("pruned prompts recorded as answered: %+v", responses)
+	}
+}
+
+func TestSummarizeResponses(t *testing.T) {
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	resp := func(start time.Time, wait time.Duration) Response {
+		return Response{Started: start, Answered: start.Add(wait)}
+	}
+
+	stats := SummarizeResponses([]Response{
+		resp(day2, 4*time.Second),
+		resp(day1, 10*time.Second),
+		resp(day1, 30*time.Second),
+		resp(day1, 20*time.Second),
+	}, 2)
+
+	if len(stats.Days) != 2 {
+		t.Fatalf("Days = %+v, want 2 days", stats.Days)
+	}
+	if d := stats.Days[0]; d.Date != "2026-03-01" || d.Count != 3 || d.MedianSeconds != 20 || d.MaxSeconds != 30 || d.TotalSeconds != 60 {
+		t.Errorf("day 1 = %+v", d)
+	}
+	if d := stats.Days[1]; d.MedianSeconds != 4 {
+		t.Errorf("day 2 median = %v, want 4", d.MedianSeconds)
+	}
+	if len(stats.Longest) != 2 || stats.Longest[0].Wait() != 30*time.Second {
+		t.Errorf("Longest = %+v, want 30s first", stats.Longest)
+	}
+}
+
+func TestTransitionTracker(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTransitionTracker(st)
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+
+	tr.Observe("work:1", "work", "main", "claude-code", StateWorking, start)
+	tr.Observe("work:1", "work", "main", "claude-code", StateWorking, start.Add(5*time.Second)) // unchanged
+	tr.Observe("work:1", "work", "main", "claude-code", StateAttention, start.Add(40*time.Second))
+	tr.Observe("work:1", "work", "main", "claude-code", StateIdle, start.Add(time.Minute))
+
+	all, err := tr.Transitions(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		from, to string
+		duration float64
+	}{
+		{"", StateWorking, 0},
+		{StateWorking, StateAttention, 40},
+		{StateAttention, StateIdle, 20},
+	}
+	if len(all) != len(want) {
+		t.Fatalf("Transitions() = %+v, want %d transitions", all, len(want))
+	}
+	for i, w := range want {
+		if got := all[i]; got.From != w.from || got.To != w.to || got.Duration != w.duration || got.Branch != "main" {
+			t.Errorf("transition %d = %+v, want %s -> %s after %vs", i, got, w.from, w.to, w.duration)
+		}
+	}
+
+	ranged, _ := tr.Transitions(start.Add(time.Second), start.Add(time.Minute))
+	if len(ranged) != 1 || ranged[0].To != StateAttention {
+		t.Errorf("Transitions(range) = %+v, want only the attention transition", ranged)
+	}
+}
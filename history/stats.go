@@ -0,0 +1,62 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// DayStats summarizes response times for one calendar day.
+type DayStats struct {
+	Date          string  `json:"date"` // YYYY-MM-DD, local time
+	Count         int     `json:"count"`
+	MedianSeconds float64 `json:"median_seconds"`
+	MaxSeconds    float64 `json:"max_seconds"`
+	TotalSeconds  float64 `json:"total_seconds"` // Agent time spent waiting on you
+}
+
+// ResponseStats is the response-time report served by the API.
+type ResponseStats struct {
+	Days    []DayStats `json:"days"`    // Oldest first; days without prompts are omitted
+	Longest []Response `json:"longest"` // Slowest answers in the period, slowest first
+	Pending []Pending  `json:"pending"` // Prompts waiting right now, longest first
+}
+
+// SummarizeResponses groups responses by local calendar day and picks the
+// limit slowest ones.
+func SummarizeResponses(responses []Response, limit int) ResponseStats {
+	byDay := make(map[string][]float64)
+	for _, r := range responses {
+		day := r.Started.Local().Format(time.DateOnly)
+		byDay[day] = append(byDay[day], r.Wait().Seconds())
+	}
+
+	stats := ResponseStats{Days: []DayStats{}, Longest: []Response{}, Pending: []Pending{}}
+	for day, waits := range byDay {
+		sort.Float64s(waits)
+		d := DayStats{Date: day, Count: len(waits), MedianSeconds: median(waits), MaxSeconds: waits[len(waits)-1]}
+		for _, w := range waits {
+			d.TotalSeconds += w
+		}
+		stats.Days = append(stats.Days, d)
+	}
+	sort.Slice(stats.Days, func(i, j int) bool { return stats.Days[i].Date < stats.Days[j].Date })
+
+	stats.Longest = append(stats.Longest, responses...)
+	sort.SliceStable(stats.Longest, func(i, j int) bool { return stats.Longest[i].Wait() > stats.Longest[j].Wait() })
+	if len(stats.Longest) > limit {
+		stats.Longest = stats.Longest[:limit]
+	}
+	return stats
+}
+
+// median of sorted values.
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
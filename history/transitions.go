@@ -0,0 +1,119 @@
+package history
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/store"
+)
+
+// transitionsLog is the store log holding agent window state changes.
+const transitionsLog = "transitions"
+
+// Window states recorded in transitions.
+const (
+	StateWorking   = "working"
+	StateAttention = "attention"
+	StateIdle      = "idle"
+)
+
+// Transition is an agent window moving from one state to another.
+type Transition struct {
+	At       time.Time `json:"at"`
+	Window   string    `json:"window"`  // Window key (host|session:window)
+	Session  string    `json:"session"` // tmux session name
+	Branch   string    `json:"branch,omitempty"`
+	Agent    string    `json:"agent"`
+	From     string    `json:"from"` // Empty for the first state seen after startup
+	To       string    `json:"to"`
+	Duration float64   `json:"duration_seconds"` // Time spent in From
+}
+
+type windowState struct {
+	state string
+	since time.Time
+}
+
+// TransitionTracker appends a Transition to the store whenever an observed
+// window changes state.
+type TransitionTracker struct {
+	store *store.Store
+	mu    sync.Mutex
+	last  map[string]windowState
+}
+
+// NewTransitionTracker returns a tracker persisting to st.
+func NewTransitionTracker(st *store.Store) *TransitionTracker {
+	return &TransitionTracker{store: st, last: make(map[string]windowState)}
+}
+
+// Observe records a window's current state.
+func (t *TransitionTracker) Observe(window, session, branch, agent, state string, now time.Time) {
+	t.mu.Lock()
+	prev, ok := t.last[window]
+	if ok && prev.state == state {
+		t.mu.Unlock()
+		return
+	}
+	t.last[window] = windowState{state: state, since: now}
+	t.mu.Unlock()
+
+	tr := Transition{At: now, Window: window, Session: session, Branch: branch, Agent: agent, To: state}
+	if ok {
+		tr.From = prev.state
+		tr.Duration = now.Sub(prev.since).Seconds()
+	}
+	if err := t.store.Append(transitionsLog, tr); err != nil {
+		slog.Warn("failed to record state transition", "window", window, "error", err)
+	}
+}
+
+// WindowState is the state a window was last seen in.
+type WindowState struct {
+	Window string    `json:"window"`
+	State  string    `json:"state"`
+	Since  time.Time `json:"since"`
+}
+
+// States returns the last observed state of every window.
+func (t *TransitionTracker) States() []WindowState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	states := make([]WindowState, 0, len(t.last))
+	for window, ws := range t.last {
+		states = append(states, WindowState{Window: window, State: ws.state, Since: ws.since})
+	}
+	return states
+}
+
+// Restore carries over window states from a previous houston process, so
+// a restart doesn't record every window as newly seen.
+func (t *TransitionTracker) Restore(states []WindowState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ws := range states {
+		if _, ok := t.last[ws.Window]; !ok {
+			t.last[ws.Window] = windowState{state: ws.State, since: ws.Since}
+		}
+	}
+}
+
+// Transitions returns transitions in [since, until), oldest first. A zero
+// until means no upper bound.
+func (t *TransitionTracker) Transitions(since, until time.Time) ([]Transition, error) {
+	var result []Transition
+	err := t.store.ReadLog(transitionsLog, func(raw json.RawMessage) error {
+		var tr Transition
+		if err := json.Unmarshal(raw, &tr); err != nil {
+			return nil
+		}
+		if tr.At.Before(since) || (!until.IsZero() && !tr.At.Before(until)) {
+			return nil
+		}
+		result = append(result, tr)
+		return nil
+	})
+	return result, err
+}
@@ -35,3 +35,17 @@ func StripOrphaned(s string) string {
 	s = OrphanedSGRPattern.ReplaceAllString(s, "")
 	return s
 }
+
+// controlPattern matches escape sequences other than CSI (OSC strings such
+// as hyperlinks, window titles and clipboard writes, DCS/APC/PM strings,
+// two-byte escapes) and control characters other than tab and newline.
+var controlPattern = regexp.MustCompile(`\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)?|\x1b[P^_][^\x1b]*(?:\x1b\\)?|\x1b[^\[]|[\x00-\x08\x0b-\x1f\x7f\x{80}-\x{9f}]`)
+
+// Sanitize reduces output to plain display text: every escape sequence and
+// control character is removed, so it can't carry hyperlinks, titles or
+// clipboard writes into places that render it.
+func Sanitize(s string) string {
+	s = Pattern.ReplaceAllString(s, "")
+	s = controlPattern.ReplaceAllString(s, "")
+	return OrphanedSGRPattern.ReplaceAllString(s, "")
+}
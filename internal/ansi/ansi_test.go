@@ -102,3 +102,46 @@ func TestStripOrphaned(t *testing.T) {
 		})
 	}
 }
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "colors",
+			input:    "\x1b[32mok\x1b[0m",
+			expected: "ok",
+		},
+		{
+			name:     "hyperlink keeps text",
+			input:    "\x1b]8;;https://evil.example\x1b\\1. Approve all\x1b]8;;\x1b\\",
+			expected: "1. Approve all",
+		},
+		{
+			name:     "window title and clipboard",
+			input:    "\x1b]0;houston\x07a\x1b]52;c;cm0gLXJmIH4=\x07b",
+			expected: "ab",
+		},
+		{
+			name:     "control characters",
+			input:    "line\r\x08\x07\tend",
+			expected: "line\tend",
+		},
+		{
+			name:     "two-byte escape",
+			input:    "\x1bcreset",
+			expected: "reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Sanitize(tt.input)
+			if got != tt.expected {
+				t.Errorf("Sanitize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
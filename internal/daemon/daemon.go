@@ -0,0 +1,102 @@
+// Package daemon lets houston run as a service: readiness, stopping and
+// watchdog notifications for systemd (sd_notify), and a PID file for
+// supervisors that track one.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notify sends state ("READY=1", "STOPPING=1", ...) to the service manager
+// over $NOTIFY_SOCKET. sent is false, without an error, when houston was
+// not started by one that listens (Type=notify).
+func Notify(state string) (sent bool, err error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:] // Linux abstract namespace
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("sd_notify: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("sd_notify: %w", err)
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns how often the service manager expects
+// "WATCHDOG=1" (WatchdogSec=), or 0 when it doesn't watch this process.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// RunWatchdog pings the watchdog at half its interval until ctx is done.
+// It returns at once when there is no watchdog.
+func RunWatchdog(ctx context.Context) {
+	interval := WatchdogInterval()
+	if interval == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = Notify("WATCHDOG=1")
+		}
+	}
+}
+
+// WritePIDFile writes this process's PID to path, replacing the file
+// atomically. The returned function removes it again, unless a houston
+// that took over (-reuse-port) has written its own since.
+func WritePIDFile(path string) (remove func(), err error) {
+	pid := strconv.Itoa(os.Getpid())
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("writing pid file: %w", err)
+	}
+	_, err = tmp.WriteString(pid + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, fmt.Errorf("writing pid file: %w", err)
+	}
+
+	return func() {
+		data, err := os.ReadFile(path)
+		if err != nil || strings.TrimSpace(string(data)) != pid {
+			return
+		}
+		_ = os.Remove(path)
+	}, nil
+}
@@ -0,0 +1,107 @@
+// Package fswatch reports changes to files in a single directory, with
+// fsnotify: inotify on Linux, kqueue on macOS.
+package fswatch
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Event describes a change to a file in the watched directory.
+type Event struct {
+	Name    string // Base name of the file
+	Removed bool   // File was deleted or moved away
+}
+
+// Watcher delivers Events for a directory until closed.
+type Watcher struct {
+	Events chan Event
+
+	dir       string
+	done      chan struct{}
+	closeOnce sync.Once
+	closeFn   func() error
+}
+
+// WatchDir starts watching dir.
+func WatchDir(dir string) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("watch %s: %w", dir, err)
+	}
+	if err := fw.Add(dir); err != nil {
+		_ = fw.Close()
+		return nil, fmt.Errorf("watch %s: %w", dir, err)
+	}
+
+	w := &Watcher{
+		Events:  make(chan Event, 64),
+		dir:     dir,
+		done:    make(chan struct{}),
+		closeFn: fw.Close,
+	}
+	go w.readLoop(fw)
+	return w, nil
+}
+
+// Close stops watching. Events is closed once the watcher goroutine exits.
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		if w.closeFn != nil {
+			err = w.closeFn()
+		}
+	})
+	return err
+}
+
+func (w *Watcher) readLoop(fw *fsnotify.Watcher) {
+	defer close(w.Events)
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return
+			}
+			// E.g. an overflowed queue; later changes still arrive
+			slog.Warn("watch error", "dir", w.dir, "error", err)
+		case ev, ok := <-fw.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Base(ev.Name)
+			switch {
+			case ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename):
+				if !w.send(Event{Name: name, Removed: true}) {
+					return
+				}
+			case ev.Has(fsnotify.Create) || ev.Has(fsnotify.Write):
+				if info, err := os.Lstat(ev.Name); err == nil && info.IsDir() {
+					continue
+				}
+				if !w.send(Event{Name: name}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// send delivers an event unless the watcher has been closed.
+func (w *Watcher) send(ev Event) bool {
+	select {
+	case w.Events <- ev:
+		return true
+	case <-w.done:
+		return false
+	}
+}
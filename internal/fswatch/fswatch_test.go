@@ -0,0 +1,81 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDir(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := WatchDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = w.Close() }()
+
+	path := filepath.Join(dir, "main.json")
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, Event{Name: "main.json"})
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w, Event{Name: "main.json", Removed: true})
+
+	// Written to a temporary file and renamed into place, as hooks do
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tmp := filepath.Join(dir, ".main.json.tmp")
+	if err := os.WriteFile(tmp, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+	for ev := range waitFor(t, w, Event{Name: "main.json"}) {
+		if ev.Name == "sub" {
+			t.Errorf("got %+v for a directory", ev)
+		}
+	}
+}
+
+func TestCloseStopsEvents(t *testing.T) {
+	w, err := WatchDir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	select {
+	case _, ok := <-w.Events:
+		if ok {
+			t.Error("expected Events to be closed")
+		}
+	case <-time.After(3 * time.Second):
+		t.Error("Events not closed after Close()")
+	}
+}
+
+// waitFor waits for want and returns the events before it.
+func waitFor(t *testing.T, w *Watcher, want Event) map[Event]bool {
+	t.Helper()
+	seen := make(map[Event]bool)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-w.Events:
+			if ev == want {
+				return seen
+			}
+			seen[ev] = true
+		case <-timeout:
+			t.Fatalf("timed out waiting for %+v", want)
+		}
+	}
+}
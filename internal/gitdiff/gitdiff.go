@@ -0,0 +1,184 @@
+// Package gitdiff parses git's unified diff output into files, hunks and
+// numbered lines.
+package gitdiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// File statuses.
+const (
+	Added    = "added"
+	Deleted  = "deleted"
+	Modified = "modified"
+	Renamed  = "renamed"
+	Copied   = "copied"
+)
+
+// Line kinds.
+const (
+	Context = "context"
+	Add     = "add"
+	Del     = "del"
+)
+
+// File is the diff of one file.
+type File struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"` // Renamed or copied from
+	Status    string `json:"status"`             // added, deleted, modified, renamed, copied
+	Binary    bool   `json:"binary,omitempty"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Hunks     []Hunk `json:"hunks"`
+}
+
+// Hunk is one @@ block of a file's diff.
+type Hunk struct {
+	Header   string `json:"header"` // The @@ line, with the enclosing function git found
+	OldStart int    `json:"old_start"`
+	OldLines int    `json:"old_lines"`
+	NewStart int    `json:"new_start"`
+	NewLines int    `json:"new_lines"`
+	Lines    []Line `json:"lines"`
+}
+
+// Line is a line of a hunk with its line numbers in the old and new file
+// (0 where it doesn't exist).
+type Line struct {
+	Kind      string `json:"kind"` // context, add, del
+	Text      string `json:"text"`
+	Old       int    `json:"old,omitempty"`
+	New       int    `json:"new,omitempty"`
+	NoNewline bool   `json:"no_newline,omitempty"` // Last line of its file, without a newline
+}
+
+// Parse parses the output of git diff (unified format, -M for renames).
+// Lines before the first "diff --git" header, such as a --stat, are
+// ignored.
+func Parse(patch string) []File {
+	files := []File{}
+	var file *File
+	var hunk *Hunk
+	var oldLine, newLine int
+
+	flush := func() {
+		if file != nil {
+			if hunk != nil {
+				file.Hunks = append(file.Hunks, *hunk)
+			}
+			files = append(files, *file)
+		}
+		file, hunk = nil, nil
+	}
+
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			file = &File{Status: Modified, Hunks: []Hunk{}}
+			file.OldPath, file.Path = headerPaths(strings.TrimPrefix(line, "diff --git "))
+			continue
+		}
+		if file == nil {
+			continue
+		}
+
+		if strings.HasPrefix(line, "@@") {
+			if hunk != nil {
+				file.Hunks = append(file.Hunks, *hunk)
+			}
+			hunk = parseHunkHeader(line)
+			oldLine, newLine = hunk.OldStart, hunk.NewStart
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		if hunk == nil {
+			// Extended header lines, before the first hunk
+			switch {
+			case strings.HasPrefix(line, "new file mode"):
+				file.Status = Added
+			case strings.HasPrefix(line, "deleted file mode"):
+				file.Status = Deleted
+			case strings.HasPrefix(line, "rename from "):
+				file.Status, file.OldPath = Renamed, strings.TrimPrefix(line, "rename from ")
+			case strings.HasPrefix(line, "rename to "):
+				file.Path = strings.TrimPrefix(line, "rename to ")
+			case strings.HasPrefix(line, "copy from "):
+				file.Status, file.OldPath = Copied, strings.TrimPrefix(line, "copy from ")
+			case strings.HasPrefix(line, "copy to "):
+				file.Path = strings.TrimPrefix(line, "copy to ")
+			case strings.HasPrefix(line, "Binary files "):
+				file.Binary = true
+			case strings.HasPrefix(line, "+++ b/"):
+				file.Path = strings.TrimPrefix(line, "+++ b/")
+			case strings.HasPrefix(line, "--- a/") && file.Status == Deleted:
+				file.Path = strings.TrimPrefix(line, "--- a/")
+			}
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			hunk.Lines = append(hunk.Lines, Line{Kind: Add, Text: line[1:], New: newLine})
+			newLine++
+			file.Additions++
+		case '-':
+			hunk.Lines = append(hunk.Lines, Line{Kind: Del, Text: line[1:], Old: oldLine})
+			oldLine++
+			file.Deletions++
+		case ' ':
+			hunk.Lines = append(hunk.Lines, Line{Kind: Context, Text: line[1:], Old: oldLine, New: newLine})
+			oldLine++
+			newLine++
+		case '\\': // "\ No newline at end of file"
+			if n := len(hunk.Lines); n > 0 {
+				hunk.Lines[n-1].NoNewline = true
+			}
+		}
+	}
+	flush()
+
+	for i := range files {
+		if files[i].OldPath == files[i].Path {
+			files[i].OldPath = ""
+		}
+	}
+	return files
+}
+
+// headerPaths splits "a/old b/new" from a diff --git header. Paths with
+// " b/" in them are ambiguous; the ---/+++ or rename lines that follow
+// correct them when there are any.
+func headerPaths(s string) (oldPath, newPath string) {
+	s = strings.TrimPrefix(s, "a/")
+	if i := strings.Index(s, " b/"); i >= 0 {
+		return s[:i], s[i+3:]
+	}
+	return s, s
+}
+
+// parseHunkHeader parses "@@ -1,4 +1,5 @@ func main() {".
+func parseHunkHeader(line string) *Hunk {
+	h := &Hunk{Header: line, Lines: []Line{}}
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return h
+	}
+	h.OldStart, h.OldLines = parseRange(strings.TrimPrefix(fields[1], "-"))
+	h.NewStart, h.NewLines = parseRange(strings.TrimPrefix(fields[2], "+"))
+	return h
+}
+
+// parseRange parses "start,count"; a missing count is 1.
+func parseRange(s string) (start, count int) {
+	startStr, countStr, ok := strings.Cut(s, ",")
+	start, _ = strconv.Atoi(startStr)
+	count = 1
+	if ok {
+		count, _ = strconv.Atoi(countStr)
+	}
+	return start, count
+}
@@ -0,0 +1,89 @@
+package gitdiff
+
+import (
+	"testing"
+)
+
+const patch = ` main.go   | 3 ++-
+ new.txt   | 1 +
+ 2 files changed, 3 insertions(+), 1 deletion(-)
+
+diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,5 @@ package main
+ import "fmt"
+-func main() { fmt.Println("hi") }
++func main() {
++	fmt.Println("hello")
+ }
+@@ -10 +11 @@ func helper() {
+-	return 1
+\ No newline at end of file
++	return 2
+\ No newline at end of file
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++--- not a header
+diff --git a/old name.go b/new name.go
+similarity index 90%
+rename from old name.go
+rename to new name.go
+diff --git a/gone.bin b/gone.bin
+deleted file mode 100644
+index 4444444..0000000
+Binary files a/gone.bin and /dev/null differ
+`
+
+func TestParse(t *testing.T) {
+	files := Parse(patch)
+	if len(files) != 4 {
+		t.Fatalf("got %d files, want 4: %+v", len(files), files)
+	}
+
+	main := files[0]
+	if main.Path != "main.go" || main.Status != Modified || main.Additions != 3 || main.Deletions != 2 || len(main.Hunks) != 2 {
+		t.Fatalf("main.go = %+v", main)
+	}
+	h := main.Hunks[0]
+	if h.Header != "@@ -1,4 +1,5 @@ package main" || h.OldStart != 1 || h.OldLines != 4 || h.NewStart != 1 || h.NewLines != 5 {
+		t.Errorf("hunk header = %+v", h)
+	}
+	want := []Line{
+		{Kind: Context, Text: `import "fmt"`, Old: 1, New: 1},
+		{Kind: Del, Text: `func main() { fmt.Println("hi") }`, Old: 2},
+		{Kind: Add, Text: "func main() {", New: 2},
+		{Kind: Add, Text: "\tfmt.Println(\"hello\")", New: 3},
+		{Kind: Context, Text: "}", Old: 3, New: 4},
+	}
+	if len(h.Lines) != len(want) {
+		t.Fatalf("lines = %+v", h.Lines)
+	}
+	for i := range want {
+		if h.Lines[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, h.Lines[i], want[i])
+		}
+	}
+	h = main.Hunks[1]
+	if h.OldLines != 1 || h.NewStart != 11 || len(h.Lines) != 2 || !h.Lines[0].NoNewline || !h.Lines[1].NoNewline || h.Lines[1].New != 11 {
+		t.Errorf("second hunk = %+v", h)
+	}
+
+	added := files[1]
+	if added.Path != "new.txt" || added.Status != Added || added.Additions != 1 || added.Hunks[0].Lines[0].Text != "--- not a header" {
+		t.Errorf("new.txt = %+v", added)
+	}
+	renamed := files[2]
+	if renamed.Path != "new name.go" || renamed.OldPath != "old name.go" || renamed.Status != Renamed || len(renamed.Hunks) != 0 {
+		t.Errorf("rename = %+v", renamed)
+	}
+	deleted := files[3]
+	if deleted.Path != "gone.bin" || deleted.Status != Deleted || !deleted.Binary || deleted.OldPath != "" {
+		t.Errorf("gone.bin = %+v", deleted)
+	}
+}
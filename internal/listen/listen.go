@@ -0,0 +1,128 @@
+// Package listen opens houston's HTTP listener. It uses a socket handed
+// over by systemd socket activation or inherited on a numbered file
+// descriptor, or else listens on a Unix domain socket (unix:PATH) or on
+// TCP, optionally sharing the address with another process (SO_REUSEPORT)
+// during a restart.
+package listen
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd (SD_LISTEN_FDS_START).
+const listenFDsStart = 3
+
+// unixPrefix marks an address as a Unix domain socket path.
+const unixPrefix = "unix:"
+
+// Listen returns the systemd-activated socket when LISTEN_FDS is set for
+// this process, and otherwise listens on addr. activated reports which one
+// was used.
+func Listen(addr string, reusePort bool) (ln net.Listener, activated bool, err error) {
+	if ln, err := activatedListener(); ln != nil || err != nil {
+		return ln, true, err
+	}
+
+	if path, ok := UnixPath(addr); ok {
+		if reusePort {
+			return nil, false, fmt.Errorf("-reuse-port needs a TCP address, not %s", addr)
+		}
+		ln, err = listenUnix(path)
+		return ln, false, err
+	}
+
+	lc := net.ListenConfig{}
+	if reusePort {
+		lc.Control = reusePortControl
+	}
+	ln, err = lc.Listen(context.Background(), "tcp", addr)
+	return ln, false, err
+}
+
+// activatedListener returns the first socket passed by systemd, or nil
+// when the process was not socket activated.
+func activatedListener() (net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+	// Children must not inherit the sockets a second time.
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(uintptr(listenFDsStart), "systemd-socket")
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("socket activation: %w", err)
+	}
+	return ln, nil
+}
+
+// FD returns a listener for the socket inherited on file descriptor fd
+// (-listen-fd), for supervisors that pass sockets without systemd's
+// LISTEN_FDS protocol.
+func FD(fd int) (net.Listener, error) {
+	if fd < listenFDsStart {
+		return nil, fmt.Errorf("listen fd %d: must be %d or higher", fd, listenFDsStart)
+	}
+	f := os.NewFile(uintptr(fd), "listen-fd")
+	if f == nil {
+		return nil, fmt.Errorf("listen fd %d: not open", fd)
+	}
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("listen fd %d: %w", fd, err)
+	}
+	return ln, nil
+}
+
+// UnixPath returns the socket path of a unix:PATH address.
+func UnixPath(addr string) (path string, ok bool) {
+	return strings.CutPrefix(addr, unixPrefix)
+}
+
+// listenUnix listens on a Unix domain socket at path, replacing a socket
+// file left behind by a houston that didn't exit cleanly. Who may connect
+// follows the umask: writing to the socket is what connecting takes.
+func listenUnix(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode().Type() != os.ModeSocket {
+			return nil, fmt.Errorf("listen %s: exists and is not a socket", path)
+		}
+		if InUse(unixPrefix + path) {
+			return nil, fmt.Errorf("listen %s: another process is serving it", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("removing stale socket: %w", err)
+		}
+	}
+	// Closing the listener removes the socket file again
+	return net.Listen("unix", path)
+}
+
+// InUse reports whether something already accepts connections on addr,
+// such as a previous houston that is about to hand over.
+func InUse(addr string) bool {
+	network := "tcp"
+	if path, ok := UnixPath(addr); ok {
+		network, addr = "unix", path
+	}
+	conn, err := net.DialTimeout(network, addr, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
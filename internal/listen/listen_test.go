@@ -0,0 +1,46 @@
+package listen
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "houston.sock")
+	addr := "unix:" + path
+
+	// A socket left by a process that died is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	ln, activated, err := Listen(addr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activated {
+		t.Error("unix socket reported as activated")
+	}
+	if !InUse(addr) {
+		t.Error("InUse = false while serving")
+	}
+
+	// A live one is not
+	if _, _, err := Listen(addr, false); err == nil || !strings.Contains(err.Error(), "another process") {
+		t.Errorf("second listener: err = %v", err)
+	}
+	if _, _, err := Listen(addr, true); err == nil {
+		t.Error("-reuse-port accepted a unix address")
+	}
+
+	_ = ln.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket file left after close: %v", err)
+	}
+}
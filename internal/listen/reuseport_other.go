@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package listen
+
+import (
+	"errors"
+	"syscall"
+)
+
+// reusePortControl fails: SO_REUSEPORT is not available on this platform.
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	return errors.New("-reuse-port is not supported on this platform")
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin
+
+package listen
+
+import "syscall"
+
+// reusePortControl lets several processes bind the same address, so a new
+// houston can start accepting before the old one stops.
+func reusePortControl(network, address string, c syscall.RawConn) error {
+	var serr error
+	err := c.Control(func(fd uintptr) {
+		serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+		if serr == nil {
+			serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, soReusePort, 1)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return serr
+}
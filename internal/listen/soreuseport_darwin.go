@@ -0,0 +1,5 @@
+package listen
+
+import "syscall"
+
+const soReusePort = syscall.SO_REUSEPORT
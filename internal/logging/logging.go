@@ -0,0 +1,188 @@
+// Package logging sets up houston's slog handler: text or JSON output,
+// a log level per module (the package a record is logged from) and the
+// ID of the HTTP request a record belongs to.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// modulePath prefixes houston's packages; modules are named by the rest
+// of their import path ("server", "agents/claude"), and "main" for the
+// command itself.
+const modulePath = "github.com/noamsto/houston/"
+
+// Formats are the output formats NewHandler accepts.
+var Formats = []string{"text", "json"}
+
+// ValidFormat reports whether format is one NewHandler accepts.
+func ValidFormat(format string) bool {
+	return slices.Contains(Formats, format)
+}
+
+// Levels are the minimum levels records are logged at: Default, or the
+// level of the longest module name in Modules that names the record's
+// module or a parent of it ("agents" covers "agents/claude").
+type Levels struct {
+	Default slog.Level
+	Modules map[string]slog.Level
+}
+
+// ParseLevels parses a -log-level value: comma-separated levels (debug,
+// info, warn, error), each optionally prefixed with a module and "=", as
+// in "info,server=debug,tmux=warn". A level without a module sets the
+// default, which is info when none is given.
+func ParseLevels(s string) (Levels, error) {
+	levels := Levels{Default: slog.LevelInfo}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		module, name, ok := strings.Cut(part, "=")
+		if !ok {
+			module, name = "", part
+		}
+		module = strings.Trim(strings.TrimSpace(module), "/")
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+			return Levels{}, fmt.Errorf("log level %q: want debug, info, warn or error", name)
+		}
+		switch {
+		case !ok:
+			levels.Default = level
+		case module == "":
+			return Levels{}, fmt.Errorf("log level %q: module is empty", part)
+		default:
+			if levels.Modules == nil {
+				levels.Modules = make(map[string]slog.Level)
+			}
+			levels.Modules[module] = level
+		}
+	}
+	return levels, nil
+}
+
+// level returns the minimum level of module.
+func (l Levels) level(module string) slog.Level {
+	level, matched := l.Default, -1
+	for name, lv := range l.Modules {
+		if (module == name || strings.HasPrefix(module, name+"/")) && len(name) > matched {
+			level, matched = lv, len(name)
+		}
+	}
+	return level
+}
+
+// min returns the lowest level any module logs at.
+func (l Levels) min() slog.Level {
+	level := l.Default
+	for _, lv := range l.Modules {
+		level = min(level, lv)
+	}
+	return level
+}
+
+// NewHandler returns a handler writing records to w in format (text or
+// json) at the given levels. Records carry their module and, when logged
+// with the context of a request, its request_id (and trace_id, when it's
+// traced).
+func NewHandler(w io.Writer, format string, levels Levels) (slog.Handler, error) {
+	opts := &slog.HandlerOptions{Level: levels.min()}
+	var h slog.Handler
+	switch format {
+	case "", "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("log format %q: want %s", format, strings.Join(Formats, " or "))
+	}
+	return &handler{next: h, levels: levels, min: opts.Level.Level()}, nil
+}
+
+type handler struct {
+	next   slog.Handler
+	levels Levels
+	min    slog.Level
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	// Which module logs isn't known yet; Handle filters by it
+	return level >= h.min
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	module := recordModule(r.PC)
+	if len(h.levels.Modules) > 0 && r.Level < h.levels.level(module) {
+		return nil
+	}
+	if module != "" {
+		r.AddAttrs(slog.String("module", module))
+	}
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{next: h.next.WithAttrs(attrs), levels: h.levels, min: h.min}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name), levels: h.levels, min: h.min}
+}
+
+// recordModule names the module of the function that logged at pc.
+func recordModule(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return funcModule(frame.Function)
+}
+
+// funcModule returns the module of a function's full name, such as
+// "github.com/noamsto/houston/server.(*Server).Handler": its package path
+// without modulePath.
+func funcModule(function string) string {
+	if i := strings.IndexByte(function, '['); i >= 0 {
+		function = function[:i] // Type arguments of a generic function
+	}
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return ""
+	}
+	pkg := function[:slash+1+dot]
+	return strings.TrimPrefix(pkg, modulePath)
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns ctx carrying a request's ID, which records
+// logged with it show as request_id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID ctx carries, if any.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels("warn, server=debug,agents/=error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if levels.Default != slog.LevelWarn {
+		t.Errorf("default = %v, want WARN", levels.Default)
+	}
+	tests := []struct {
+		module string
+		want   slog.Level
+	}{
+		{"server", slog.LevelDebug},
+		{"serverless", slog.LevelWarn},
+		{"agents/claude", slog.LevelError},
+		{"main", slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		if got := levels.level(tt.module); got != tt.want {
+			t.Errorf("level(%q) = %v, want %v", tt.module, got, tt.want)
+		}
+	}
+	if got := levels.min(); got != slog.LevelDebug {
+		t.Errorf("min = %v, want DEBUG", got)
+	}
+
+	if levels, err := ParseLevels(""); err != nil || levels.Default != slog.LevelInfo {
+		t.Errorf(`ParseLevels("") = %v, %v; want INFO`, levels, err)
+	}
+	for _, bad := range []string{"loud", "server=", "=debug"} {
+		if _, err := ParseLevels(bad); err == nil {
+			t.Errorf("ParseLevels(%q) accepted", bad)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	levels, _ := ParseLevels("warn,internal/logging=debug,server=error")
+	var buf bytes.Buffer
+	h, err := NewHandler(&buf, "json", levels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slog.New(h)
+
+	log.DebugContext(WithRequestID(context.Background(), "abc123"), "hello", "n", 1)
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("not JSON: %q", buf.String())
+	}
+	if rec["module"] != "internal/logging" || rec["request_id"] != "abc123" || rec["msg"] != "hello" {
+		t.Errorf("record = %v", rec)
+	}
+
+	// Records of a module logging at error are dropped below it
+	levels, _ = ParseLevels("debug,internal=error")
+	h, _ = NewHandler(&buf, "text", levels)
+	buf.Reset()
+	slog.New(h).Warn("quiet")
+	if buf.Len() != 0 {
+		t.Errorf("warning logged at error level: %q", buf.String())
+	}
+	slog.New(h).Error("loud")
+	if !strings.Contains(buf.String(), "module=internal/logging") {
+		t.Errorf("text record = %q", buf.String())
+	}
+
+	if _, err := NewHandler(&buf, "logfmt", levels); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestFuncModule(t *testing.T) {
+	tests := map[string]string{
+		"github.com/noamsto/houston/server.(*Server).Handler":       "server",
+		"github.com/noamsto/houston/agents/claude.detect.func1":     "agents/claude",
+		"github.com/noamsto/houston/server.run[go.shape.struct {}]": "server",
+		"main.main":                              "main",
+		"golang.org/x/sync/errgroup.(*Group).Go": "golang.org/x/sync/errgroup",
+		"":                                       "",
+	}
+	for function, want := range tests {
+		if got := funcModule(function); got != want {
+			t.Errorf("funcModule(%q) = %q, want %q", function, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name     string          `json:"name"`
+	Note     string          `json:"note,omitempty"`
+	Hidden   string          `json:"-"`
+	Children []node          `json:"children"`
+	Parent   *node           `json:"parent,omitempty"`
+	Count    *int            `json:"count"`
+	Labels   map[string]bool `json:"labels,omitempty"`
+	Raw      json.RawMessage `json:"raw,omitempty"`
+	Data     []byte          `json:"data,omitempty"`
+	Created  time.Time       `json:"created"`
+	Untagged int
+	embedded
+	private int
+}
+
+type embedded struct {
+	Depth int64 `json:"depth"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	d := New(Info{Title: "test", Version: "1"})
+	ref := d.SchemaOf([]node{})
+	if ref.Type != "array" || ref.Items.Ref != "#/components/schemas/Node" {
+		t.Fatalf("SchemaOf([]node) = %+v", ref)
+	}
+
+	got := d.Components.Schemas["Node"]
+	nodeRef := &Schema{Ref: "#/components/schemas/Node"}
+	want := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":     {Type: "string"},
+			"note":     {Type: "string"},
+			"children": {Type: "array", Items: nodeRef},
+			"parent":   nodeRef,
+			"count":    {Type: "integer", Format: "int32", Nullable: true},
+			"labels":   {Type: "object", AdditionalProperties: &Schema{Type: "boolean"}},
+			"raw":      {},
+			"data":     {Type: "string", Format: "byte"},
+			"created":  {Type: "string", Format: "date-time"},
+			"Untagged": {Type: "integer", Format: "int32"},
+			"depth":    {Type: "integer", Format: "int64"},
+		},
+		Required: []string{"Untagged", "children", "count", "created", "depth", "name"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("Node schema =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
+
+func TestAddTwice(t *testing.T) {
+	d := New(Info{Title: "test", Version: "1"})
+	d.Add("GET", "/things", &Operation{OperationID: "listThings"})
+	d.Add("POST", "/things", &Operation{OperationID: "createThing"})
+	if len(*d.Paths["/things"]) != 2 {
+		t.Errorf("paths = %v", d.Paths)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("adding GET /things twice didn't panic")
+		}
+	}()
+	d.Add("get", "/things", &Operation{OperationID: "listThingsAgain"})
+}
@@ -0,0 +1,465 @@
+// Package screenshot renders terminal output, as tmux capture-pane -e
+// prints it (text with SGR color sequences), to an image with a
+// fixed-width font.
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultSize is the font size in pixels.
+const DefaultSize = 14
+
+// Theme is the palette output is drawn with.
+type Theme struct {
+	Background color.RGBA
+	Foreground color.RGBA
+	ANSI       [16]color.RGBA // Black, red, green, yellow, blue, magenta, cyan, white, then their bright variants
+}
+
+// Dark and Light match the dashboard's terminal themes.
+var (
+	Dark = Theme{
+		Background: rgb(0x000000),
+		Foreground: rgb(0xe1e1e6),
+		ANSI: [16]color.RGBA{
+			rgb(0x1a1b26), rgb(0xf7768e), rgb(0x9ece6a), rgb(0xe0af68), rgb(0x7aa2f7), rgb(0xbb9af7), rgb(0x7dcfff), rgb(0xc0caf5),
+			rgb(0x414868), rgb(0xf7768e), rgb(0x9ece6a), rgb(0xe0af68), rgb(0x7aa2f7), rgb(0xbb9af7), rgb(0x7dcfff), rgb(0xc0caf5),
+		},
+	}
+	Light = Theme{
+		Background: rgb(0xfafafa),
+		Foreground: rgb(0x1a1a2e),
+		ANSI: [16]color.RGBA{
+			rgb(0x1a1a2e), rgb(0xd32f2f), rgb(0x388e3c), rgb(0xf9a825), rgb(0x1976d2), rgb(0x7b1fa2), rgb(0x0097a7), rgb(0xe1e1e6),
+			rgb(0x5a5a6e), rgb(0xd32f2f), rgb(0x388e3c), rgb(0xf9a825), rgb(0x1976d2), rgb(0x7b1fa2), rgb(0x0097a7), rgb(0x1a1a2e),
+		},
+	}
+)
+
+func rgb(v uint32) color.RGBA {
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+// Options control rendering.
+type Options struct {
+	Theme Theme
+	Cols  int     // Cells per row; longer lines are cut. 0 fits the longest line
+	Size  float64 // Font size in pixels; 0 is DefaultSize
+}
+
+// fallbacks replace symbols Go Mono lacks with ones it has.
+var fallbacks = map[rune]rune{
+	'⏺': '●', '⎿': '└', '╭': '┌', '╮': '┐', '╰': '└', '╯': '┘', '✻': '*', '✽': '*', '✶': '*', '✳': '*', '✢': '*', '❯': '>', '✔': '√', '✓': '√', '✗': 'x', '✘': 'x',
+}
+
+// Render draws capture, one row per line.
+func Render(capture string, opts Options) (*image.RGBA, error) {
+	if opts.Size <= 0 {
+		opts.Size = DefaultSize
+	}
+	if opts.Theme == (Theme{}) {
+		opts.Theme = Dark
+	}
+	faces, err := facesFor(opts.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := parse(capture)
+	cols := opts.Cols
+	if cols <= 0 {
+		for _, row := range rows {
+			cols = max(cols, len(row))
+		}
+	}
+	cols = max(cols, 1)
+
+	metrics := faces.regular.Metrics()
+	advance, _ := faces.regular.GlyphAdvance('M')
+	cellW, cellH := advance.Ceil(), metrics.Height.Ceil()
+	ascent := metrics.Ascent.Ceil()
+	pad := cellW
+
+	img := image.NewRGBA(image.Rect(0, 0, cols*cellW+2*pad, len(rows)*cellH+2*pad))
+	draw.Draw(img, img.Bounds(), image.NewUniform(opts.Theme.Background), image.Point{}, draw.Src)
+
+	for y, row := range rows {
+		for x, c := range row {
+			if x >= cols {
+				break
+			}
+			if c.r == 0 {
+				continue // Second half of a wide character
+			}
+			fg, bg := c.colors(opts.Theme)
+			cell := image.Rect(pad+x*cellW, pad+y*cellH, pad+(x+c.width())*cellW, pad+(y+1)*cellH)
+			if bg != opts.Theme.Background {
+				draw.Draw(img, cell, image.NewUniform(bg), image.Point{}, draw.Src)
+			}
+			if c.r == ' ' || c.hidden {
+				continue
+			}
+			face, f := faces.regular, faces.regularFont
+			switch {
+			case c.bold && c.italic:
+				face, f = faces.boldItalic, faces.boldItalicFont
+			case c.bold:
+				face, f = faces.bold, faces.boldFont
+			case c.italic:
+				face, f = faces.italic, faces.italicFont
+			}
+			r := c.r
+			if !faces.hasGlyph(f, r) {
+				if alt, ok := fallbacks[r]; ok {
+					r = alt
+				}
+			}
+			d := font.Drawer{Dst: img, Src: image.NewUniform(fg), Face: face, Dot: fixed.P(cell.Min.X, cell.Min.Y+ascent)}
+			d.DrawString(string(r))
+
+			thickness := max(1, int(opts.Size/14))
+			if c.underline {
+				line := image.Rect(cell.Min.X, cell.Min.Y+ascent+1, cell.Max.X, cell.Min.Y+ascent+1+thickness)
+				draw.Draw(img, line, image.NewUniform(fg), image.Point{}, draw.Src)
+			}
+			if c.strike {
+				mid := cell.Min.Y + cellH/2
+				draw.Draw(img, image.Rect(cell.Min.X, mid, cell.Max.X, mid+thickness), image.NewUniform(fg), image.Point{}, draw.Src)
+			}
+		}
+	}
+	return img, nil
+}
+
+// PNG renders capture and writes it to w as a PNG.
+func PNG(w io.Writer, capture string, opts Options) error {
+	img, err := Render(capture, opts)
+	if err != nil {
+		return err
+	}
+	enc := png.Encoder{CompressionLevel: png.BestSpeed}
+	return enc.Encode(w, img)
+}
+
+// faceSet holds the Go Mono faces of one render. The fonts are parsed once
+// and shared; faces and buffers aren't safe for concurrent use, so each
+// render makes its own.
+type faceSet struct {
+	regular, bold, italic, boldItalic                 font.Face
+	regularFont, boldFont, italicFont, boldItalicFont *sfnt.Font
+	buf                                               sfnt.Buffer
+}
+
+func (s *faceSet) hasGlyph(f *sfnt.Font, r rune) bool {
+	i, err := f.GlyphIndex(&s.buf, r)
+	return err == nil && i != 0
+}
+
+var (
+	fontsOnce sync.Once
+	fonts     [4]*sfnt.Font
+	fontsErr  error
+)
+
+func facesFor(size float64) (*faceSet, error) {
+	fontsOnce.Do(func() {
+		for i, ttf := range [][]byte{gomono.TTF, gomonobold.TTF, gomonoitalic.TTF, gomonobolditalic.TTF} {
+			if fonts[i], fontsErr = opentype.Parse(ttf); fontsErr != nil {
+				return
+			}
+		}
+	})
+	if fontsErr != nil {
+		return nil, fmt.Errorf("parse font: %w", fontsErr)
+	}
+	var set faceSet
+	faces := []*font.Face{&set.regular, &set.bold, &set.italic, &set.boldItalic}
+	for i, f := range fonts {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("font face: %w", err)
+		}
+		*faces[i] = face
+	}
+	set.regularFont, set.boldFont, set.italicFont, set.boldItalicFont = fonts[0], fonts[1], fonts[2], fonts[3]
+	return &set, nil
+}
+
+// colorKind tells how a cell color is given.
+type colorKind uint8
+
+const (
+	colorDefault colorKind = iota
+	colorIndex             // 256-color palette
+	colorRGB               // 24-bit
+)
+
+type cellColor struct {
+	kind colorKind
+	v    uint32 // Palette index or 0xRRGGBB
+}
+
+// cell is one character cell and its attributes. A wide character's
+// second cell has r == 0.
+type cell struct {
+	r                                                     rune
+	wide                                                  bool
+	fg, bg                                                cellColor
+	bold, dim, italic, underline, inverse, strike, hidden bool
+}
+
+func (c cell) width() int {
+	if c.wide {
+		return 2
+	}
+	return 1
+}
+
+// colors resolves the cell's foreground and background in theme.
+func (c cell) colors(theme Theme) (fg, bg color.RGBA) {
+	fgc := c.fg
+	if c.bold && fgc.kind == colorIndex && fgc.v < 8 {
+		fgc.v += 8 // Bold text in bright colors, as xterm draws it
+	}
+	fg, bg = resolve(fgc, theme, theme.Foreground), resolve(c.bg, theme, theme.Background)
+	if c.inverse {
+		fg, bg = bg, fg
+	}
+	if c.dim {
+		fg = color.RGBA{R: uint8((int(fg.R) + int(bg.R)) / 2), G: uint8((int(fg.G) + int(bg.G)) / 2), B: uint8((int(fg.B) + int(bg.B)) / 2), A: 0xff}
+	}
+	return fg, bg
+}
+
+func resolve(c cellColor, theme Theme, def color.RGBA) color.RGBA {
+	switch c.kind {
+	case colorRGB:
+		return rgb(c.v)
+	case colorIndex:
+		return paletteColor(int(c.v), theme)
+	}
+	return def
+}
+
+// paletteColor returns xterm's 256-color palette entry, with the theme's
+// first 16.
+func paletteColor(i int, theme Theme) color.RGBA {
+	switch {
+	case i < 16:
+		return theme.ANSI[i]
+	case i < 232:
+		i -= 16
+		level := func(v int) uint8 {
+			if v == 0 {
+				return 0
+			}
+			return uint8(55 + 40*v)
+		}
+		return color.RGBA{R: level(i / 36), G: level(i / 6 % 6), B: level(i % 6), A: 0xff}
+	default:
+		g := uint8(8 + 10*(i-232))
+		return color.RGBA{R: g, G: g, B: g, A: 0xff}
+	}
+}
+
+// parse splits capture into rows of cells, applying SGR sequences and
+// dropping other escape sequences.
+func parse(capture string) [][]cell {
+	var rows [][]cell
+	var pen cell
+	for _, line := range strings.Split(strings.TrimSuffix(capture, "\n"), "\n") {
+		var row []cell
+		for i := 0; i < len(line); {
+			if line[i] == 0x1b {
+				i += escape(line[i:], &pen)
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(line[i:])
+			i += size
+			switch {
+			case r == '\t':
+				for n := 8 - len(row)%8; n > 0; n-- {
+					c := pen
+					c.r = ' '
+					row = append(row, c)
+				}
+				continue
+			case r < 0x20 || r == 0x7f:
+				continue
+			}
+			w := runewidth.RuneWidth(r)
+			if w == 0 {
+				continue
+			}
+			c := pen
+			c.r, c.wide = r, w == 2
+			row = append(row, c)
+			if c.wide {
+				c.r, c.wide = 0, false
+				row = append(row, c)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// escape applies the escape sequence at the start of s to pen if it is an
+// SGR sequence and returns its length.
+func escape(s string, pen *cell) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case '[': // CSI: parameters, then a final byte in 0x40-0x7e
+		for j := 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				if s[j] == 'm' {
+					sgr(s[2:j], pen)
+				}
+				return j + 1
+			}
+		}
+		return len(s)
+	case ']': // OSC, ended by BEL or ST
+		for j := 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j + 1
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	}
+	return 2
+}
+
+// sgr applies Select Graphic Rendition parameters.
+func sgr(params string, pen *cell) {
+	if params == "" {
+		*pen = cell{}
+		return
+	}
+	fields := strings.Split(params, ";")
+	for i := 0; i < len(fields); i++ {
+		// Colon-separated forms (38:2::r:g:b) carry a whole color
+		if strings.Contains(fields[i], ":") {
+			sub := strings.Split(fields[i], ":")
+			if c, ok := extendedColor(sub[1:]); ok {
+				setColor(pen, sub[0], c)
+			}
+			continue
+		}
+		n, _ := strconv.Atoi(fields[i])
+		switch {
+		case n == 0:
+			*pen = cell{}
+		case n == 1:
+			pen.bold = true
+		case n == 2:
+			pen.dim = true
+		case n == 3:
+			pen.italic = true
+		case n == 4:
+			pen.underline = true
+		case n == 7:
+			pen.inverse = true
+		case n == 8:
+			pen.hidden = true
+		case n == 9:
+			pen.strike = true
+		case n == 21 || n == 22:
+			pen.bold, pen.dim = false, false
+		case n == 23:
+			pen.italic = false
+		case n == 24:
+			pen.underline = false
+		case n == 27:
+			pen.inverse = false
+		case n == 28:
+			pen.hidden = false
+		case n == 29:
+			pen.strike = false
+		case n >= 30 && n <= 37:
+			pen.fg = cellColor{colorIndex, uint32(n - 30)}
+		case n == 39:
+			pen.fg = cellColor{}
+		case n >= 40 && n <= 47:
+			pen.bg = cellColor{colorIndex, uint32(n - 40)}
+		case n == 49:
+			pen.bg = cellColor{}
+		case n >= 90 && n <= 97:
+			pen.fg = cellColor{colorIndex, uint32(n - 90 + 8)}
+		case n >= 100 && n <= 107:
+			pen.bg = cellColor{colorIndex, uint32(n - 100 + 8)}
+		case n == 38 || n == 48 || n == 58:
+			c, used := extendedColorArgs(fields[i+1:])
+			if used > 0 {
+				setColor(pen, fields[i], c)
+			}
+			i += used
+		}
+	}
+}
+
+func setColor(pen *cell, which string, c cellColor) {
+	switch which {
+	case "38":
+		pen.fg = c
+	case "48":
+		pen.bg = c
+	}
+}
+
+// extendedColorArgs parses the arguments of 38/48 given as separate
+// parameters (5;n or 2;r;g;b), returning how many it used.
+func extendedColorArgs(args []string) (cellColor, int) {
+	if len(args) >= 2 && args[0] == "5" {
+		n, _ := strconv.Atoi(args[1])
+		return cellColor{colorIndex, uint32(n & 0xff)}, 2
+	}
+	if len(args) >= 4 && args[0] == "2" {
+		c, _ := extendedColor(args[:4])
+		return c, 4
+	}
+	return cellColor{}, 0
+}
+
+// extendedColor parses 5:n or 2:[colorspace:]r:g:b.
+func extendedColor(args []string) (cellColor, bool) {
+	if len(args) >= 2 && args[0] == "5" {
+		n, _ := strconv.Atoi(args[1])
+		return cellColor{colorIndex, uint32(n & 0xff)}, true
+	}
+	if len(args) >= 4 && args[0] == "2" {
+		rgbArgs := args[len(args)-3:]
+		var v uint32
+		for _, a := range rgbArgs {
+			n, _ := strconv.Atoi(a)
+			v = v<<8 | uint32(n&0xff)
+		}
+		return cellColor{colorRGB, v}, true
+	}
+	return cellColor{}, false
+}
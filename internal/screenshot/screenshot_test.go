@@ -0,0 +1,90 @@
+package screenshot
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rows := parse("\x1b[1;31mERR\x1b[0m ok\n\x1b[38;5;196ma\x1b[48;2;1;2;3mb\x1b[38:2::4:5:6mc\x1b]8;;https://x\x07link\x1b]8;;\x07\n世界\tz")
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d, want 3", len(rows))
+	}
+
+	if c := rows[0][0]; c.r != 'E' || !c.bold || c.fg != (cellColor{colorIndex, 1}) {
+		t.Errorf("bold red = %+v", c)
+	}
+	if c := rows[0][4]; c.r != 'o' || c.bold || c.fg != (cellColor{}) {
+		t.Errorf("after reset = %+v", c)
+	}
+
+	if c := rows[1][0]; c.fg != (cellColor{colorIndex, 196}) {
+		t.Errorf("256-color fg = %+v", c.fg)
+	}
+	if c := rows[1][1]; c.bg != (cellColor{colorRGB, 0x010203}) || c.fg != (cellColor{colorIndex, 196}) {
+		t.Errorf("truecolor bg = %+v", c)
+	}
+	if c := rows[1][2]; c.fg != (cellColor{colorRGB, 0x040506}) {
+		t.Errorf("colon truecolor fg = %+v", c.fg)
+	}
+	if got := string(runes(rows[1][3:])); got != "link" {
+		t.Errorf("hyperlink text = %q", got)
+	}
+
+	// Wide characters take two cells; tabs go to the next multiple of 8
+	if len(rows[2]) != 9 || rows[2][0].r != '世' || !rows[2][0].wide || rows[2][1].r != 0 || rows[2][8].r != 'z' {
+		t.Errorf("wide row = %q", runes(rows[2]))
+	}
+}
+
+func runes(cells []cell) []rune {
+	var rs []rune
+	for _, c := range cells {
+		rs = append(rs, c.r)
+	}
+	return rs
+}
+
+func TestColors(t *testing.T) {
+	bold := cell{bold: true, fg: cellColor{colorIndex, 1}}
+	if fg, _ := bold.colors(Dark); fg != Dark.ANSI[9] {
+		t.Errorf("bold red = %v, want bright red", fg)
+	}
+	inverse := cell{inverse: true}
+	if fg, bg := inverse.colors(Light); fg != Light.Background || bg != Light.Foreground {
+		t.Errorf("inverse = %v on %v", fg, bg)
+	}
+	if c := paletteColor(16+36*5+6*0+1, Dark); c != (color.RGBA{255, 0, 95, 255}) {
+		t.Errorf("palette cube = %v", c)
+	}
+	if c := paletteColor(255, Dark); c != (color.RGBA{238, 238, 238, 255}) {
+		t.Errorf("palette gray = %v", c)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := PNG(&buf, "$ ls\n\x1b[42m  \x1b[0m done\n", Options{Cols: 20}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 20 cells and a cell of padding on each side
+	b := img.Bounds()
+	cellW := b.Dx() / 22
+	cellH := (b.Dy() - 2*cellW) / 2
+	if b.Dx()%22 != 0 || cellW < 7 || cellH < 14 || cellH > 24 {
+		t.Errorf("size = %v for 20x2 cells", b.Size())
+	}
+	// Padding keeps the background; the green cells are green
+	if got := color.RGBAModel.Convert(img.At(1, 1)); got != Dark.Background {
+		t.Errorf("corner = %v, want background", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(cellW+1, cellW+cellH+cellH/2)); got != Dark.ANSI[2] {
+		t.Errorf("green cell = %v, want %v", got, Dark.ANSI[2])
+	}
+}
@@ -0,0 +1,16 @@
+// Package textutil provides small string helpers shared across packages.
+package textutil
+
+import "unicode/utf8"
+
+// Truncate cuts s to at most n bytes, on a rune boundary so a multi-byte
+// character isn't split into invalid UTF-8.
+func Truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,32 @@
+package textutil
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"abcdef", 4, "abcd"},
+		{"日本語", 4, "日"},
+		{"日本語", 6, "日本"},
+		{"日本語", 2, ""},
+		{"héllo", 2, "h"},
+		{"", 0, ""},
+	}
+
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) = %q, not valid UTF-8", tt.s, tt.n, got)
+		}
+	}
+}
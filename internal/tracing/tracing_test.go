@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:4318":                   "http://localhost:4318/v1/traces",
+		"http://localhost:4318/":                  "http://localhost:4318/v1/traces",
+		"https://otel.example.com/otlp/v1/traces": "https://otel.example.com/otlp/v1/traces",
+	}
+	for endpoint, want := range tests {
+		if got, err := ParseEndpoint(endpoint); err != nil || got != want {
+			t.Errorf("ParseEndpoint(%q) = %q, %v; want %q", endpoint, got, err, want)
+		}
+	}
+	for _, bad := range []string{"localhost:4318", "grpc://localhost:4317", "http://"} {
+		if _, err := ParseEndpoint(bad); err == nil {
+			t.Errorf("ParseEndpoint(%q) accepted", bad)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	}()
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/session/abc", nil)
+	resp, err := (&http.Client{Transport: Transport(nil)}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans, want the call's and the request's", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /session/abc" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("span %q with parent %v", span.Name(), span.Parent().SpanID())
+	}
+	if span.Status().Description != "502 Bad Gateway" {
+		t.Errorf("status = %+v, want the 502", span.Status())
+	}
+	if want := span.SpanContext().TraceID().String(); traceparent == "" || traceparent[3:35] != want {
+		t.Errorf("traceparent = %q, want trace %s", traceparent, want)
+	}
+}
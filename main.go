@@ -1,48 +1,143 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/noamsto/houston/internal/daemon"
+	"github.com/noamsto/houston/internal/listen"
+	"github.com/noamsto/houston/internal/logging"
+	"github.com/noamsto/houston/internal/tracing"
+	"github.com/noamsto/houston/notify"
 	"github.com/noamsto/houston/server"
 	"github.com/noamsto/houston/terminal"
 )
 
+// version is set at build time with -ldflags "-X main.version=...".
+var version string
+
 func main() {
-	addr := flag.String("addr", "127.0.0.1:9090", "HTTP listen address")
-	statusDir := flag.String("status-dir", "", "Directory for hook status files")
-	debug := flag.Bool("debug", false, "Enable debug logging")
+	opts := newOptions(flag.CommandLine)
+	flag.Usage = usage
 
-	// OpenCode integration flags
-	openCodeURL := flag.String("opencode-url", "", "OpenCode server URL (skip discovery)")
-	noOpenCode := flag.Bool("no-opencode", false, "Disable OpenCode integration")
+	if len(os.Args) > 1 {
+		switch cmd := os.Args[1]; cmd {
+		case "serve":
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		case "list":
+			os.Exit(runList(os.Args[2:]))
+		case "send":
+			os.Exit(runSend(os.Args[2:]))
+		case "attention":
+			os.Exit(runAttention(os.Args[2:]))
+		case "tui":
+			os.Exit(runTUI(os.Args[2:]))
+		case "update":
+			os.Exit(runUpdate(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:]))
+		case "install-hooks":
+			os.Exit(runInstallHooks(os.Args[2:]))
+		default:
+			// Without a command, flags start the server as before
+			if !strings.HasPrefix(cmd, "-") {
+				fmt.Fprintf(os.Stderr, "houston: unknown command %q\n\n", cmd)
+				usage()
+				os.Exit(2)
+			}
+		}
+	}
 
 	flag.Parse()
+	configPath, configFound, err := opts.load(flag.CommandLine)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Configure slog
-	logLevel := slog.LevelInfo
-	if *debug {
-		logLevel = slog.LevelDebug
+	logHandler, err := logging.NewHandler(os.Stderr, opts.logFormat, opts.logLevels)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(slog.New(logHandler))
+	if configFound {
+		slog.Info("config file", "path", configPath)
 	}
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-		Level: logLevel,
-	})))
 
-	if *statusDir == "" {
+	// Spans go nowhere without a collector
+	if opts.otlpEndpoint != "" {
+		shutdown, err := tracing.Start(context.Background(), opts.otlpEndpoint, version)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				slog.Warn("failed to send the last spans", "error", err)
+			}
+		}()
+		slog.Info("tracing", "endpoint", opts.otlpEndpoint)
+	}
+
+	if opts.statusDir == "" {
 		home, _ := os.UserHomeDir()
-		*statusDir = filepath.Join(home, ".local", "state", "houston")
+		opts.statusDir = filepath.Join(home, ".local", "state", "houston")
+	}
+	if opts.dataDir == "" {
+		home, _ := os.UserHomeDir()
+		opts.dataDir = filepath.Join(home, ".local", "share", "houston")
+	}
+
+	var providers []notify.Provider
+	if opts.notifyCmd != "" {
+		providers = append(providers, notify.NewCommand(opts.notifyCmd))
+	}
+	if opts.notifyWebhook != "" {
+		providers = append(providers, notify.NewWebhook(opts.notifyWebhook))
+	}
+	var slack *notify.Slack
+	if opts.slackToken != "" {
+		slack = notify.NewSlack(opts.slackToken, opts.slackChannel, opts.slackSecret)
+		providers = append(providers, slack)
+	}
+	notifier := notify.New(providers...)
+	if err := notifier.SetRules(opts.rules); err != nil {
+		log.Fatal(err)
+	}
+
+	// Auto-detect terminal for font and appearance control, unless -terminal names it
+	var termCtrl terminal.TerminalController
+	if opts.terminal != "" {
+		if termCtrl, err = terminal.NewNamedController(opts.terminal); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		termCtrl = terminal.NewController()
+	}
+	if termCtrl.Name() != "" {
+		slog.Info("terminal control", "terminal", termCtrl.Name(), "supports", termCtrl.Supports())
 	}
 
-	// Auto-detect terminal for font size control
-	fontCtrl := terminal.NewFontController()
-	if fontCtrl.Name() != "" {
-		slog.Info("terminal font control", "terminal", fontCtrl.Name())
+	// Raising the terminal on focus uses the same detection (or HOUSTON_RAISE_CMD)
+	var raiser server.Raiser
+	if r := terminal.NewRaiser(termCtrl); r != nil {
+		raiser = r
 	}
 
 	// Embed React SPA: strip the ui/dist prefix so the FS root is the dist dir.
@@ -51,21 +146,134 @@ func main() {
 		log.Fatalf("failed to create UI sub-filesystem: %v", err)
 	}
 
+	// With -reuse-port, an address already in use belongs to the houston
+	// this one replaces; it hands over its state when it stops.
+	standby := opts.reusePort && listen.InUse(opts.addr)
+
 	srv, err := server.New(server.Config{
-		StatusDir:       *statusDir,
-		FontController:  fontCtrl,
-		OpenCodeEnabled: !*noOpenCode,
-		OpenCodeURL:     *openCodeURL,
-		UIFS:            uiSubFS,
+		StatusDir:             opts.statusDir,
+		DataDir:               opts.dataDir,
+		UploadDir:             opts.uploadDir,
+		UploadTTL:             opts.uploadTTL,
+		FileUploadMax:         int64(opts.fileUploadMax) << 20,
+		FileUploadExts:        opts.fileUploadExt,
+		RateLimit:             opts.rateLimit,
+		TmuxConcurrency:       opts.tmuxLimit,
+		Remotes:               opts.remotes,
+		ResurrectFile:         opts.resurrectFile,
+		Version:               version,
+		Terminal:              termCtrl,
+		TerminalThemes:        opts.themes,
+		Raiser:                raiser,
+		Notifier:              notifier,
+		Slack:                 slack,
+		DailyReport:           opts.reportAt,
+		Reminders:             opts.reminders,
+		ActivityWindow:        opts.activityWindow,
+		ActiveTTL:             opts.activeTTL,
+		TimerRules:            opts.timerRules,
+		PreviewLines:          opts.previewLines,
+		AttentionPreviewLines: opts.attentionPreviewLines,
+		SessionsInterval:      opts.pollSessions,
+		PaneInterval:          opts.pollPane,
+		DisabledAgents:        opts.disabled,
+		MCPRequired:           opts.mcpRequired,
+		AutoCompact:           opts.autoCompact,
+		AutoCompactCommand:    opts.compactCommand,
+		Standby:               standby,
+		UpdateCheck:           opts.updateCheck,
+		UpdateChannel:         opts.updateChannel,
+		GitHubPRs:             opts.githubPRs,
+		OpenCodeEnabled:       !opts.noOpenCode,
+		OpenCodeURL:           opts.openCodeURL,
+		OpenCodeMDNS:          opts.openCodeMDNS,
+		OpenCodeBeacon:        opts.openCodeBeacon,
+		UIFS:                  uiSubFS,
+		BasePath:              opts.basePath,
 	})
 	if err != nil {
 		log.Fatalf("failed to create server: %v", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "houston starting on http://%s\n", *addr)
-	fmt.Fprintf(os.Stderr, "status directory: %s\n", *statusDir)
-
-	if err := http.ListenAndServe(*addr, srv.Handler()); err != nil {
+	var ln net.Listener
+	activated := opts.listenFD > 0
+	if activated {
+		ln, err = listen.FD(opts.listenFD)
+	} else {
+		ln, activated, err = listen.Listen(opts.addr, opts.reusePort)
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
+	if activated {
+		fmt.Fprintf(os.Stderr, "houston starting on socket-activated %s\n", ln.Addr())
+	} else if _, unix := listen.UnixPath(opts.addr); unix {
+		fmt.Fprintf(os.Stderr, "houston starting on %s\n", opts.addr)
+	} else {
+		fmt.Fprintf(os.Stderr, "houston starting on http://%s\n", opts.addr)
+	}
+	fmt.Fprintf(os.Stderr, "status directory: %s\n", opts.statusDir)
+
+	if opts.pidFile != "" {
+		removePID, err := daemon.WritePIDFile(opts.pidFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer removePID()
+	}
+
+	httpSrv := &http.Server{Handler: srv.Handler()}
+	httpSrv.RegisterOnShutdown(srv.Drain)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		if err := httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// Tell systemd (Type=notify) the dashboard is up
+	if _, err := daemon.Notify("READY=1\nSTATUS=Serving " + ln.Addr().String()); err != nil {
+		slog.Warn("failed to notify service manager", "error", err)
+	}
+	go daemon.RunWatchdog(ctx)
+
+	<-ctx.Done()
+	stop()
+	_, _ = daemon.Notify("STOPPING=1")
+
+	// Stop background work and leave in-memory state for the next houston,
+	// then stop accepting and send stream clients over to it.
+	if err := srv.Close(); err != nil {
+		slog.Warn("failed to save state for handoff", "error", err)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("shutdown did not finish cleanly", "error", err)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprint(out, `usage: houston [serve] [flags]
+       houston <command> [flags]
+
+commands:
+  serve        Run the dashboard server (the default)
+  list         Print sessions and window states (-json for JSON)
+  send         Type text into a pane: houston send work:1.0 "run the tests"
+  attention    Exit 1 if any window needs attention, listing them
+  tui          The dashboard in the terminal; Enter jumps to a pane
+  update       Install the latest release
+  config       Check the config file: houston config validate
+  doctor       Check tmux, hooks, directories, agents and terminal, with fixes
+  install-hooks  Install the Claude Code hook script and add it to settings.json
+
+The list, send, attention and tui commands talk to a running server.
+
+serve flags:
+`)
+	flag.PrintDefaults()
 }
@@ -0,0 +1,170 @@
+// Package notify delivers attention notifications to external providers
+// (a local command, a webhook, Slack) and schedules reminders for prompts
+// that stay unanswered.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Notification is one attention notification for a window.
+type Notification struct {
+	Key      string    `json:"key"` // Window key; reminders for the same prompt share it
+	Title    string    `json:"title"`
+	Body     string    `json:"body"`
+	Reminder int       `json:"reminder"`          // 0 when the window starts needing attention, then 1, 2, ...
+	Since    time.Time `json:"since"`             // When the window started needing attention
+	Target   string    `json:"target"`            // Pane waiting, session:window.pane
+	Host     string    `json:"host,omitempty"`    // Remote host of Target (empty: local)
+	Choices  []string  `json:"choices,omitempty"` // Choices the agent verified; can be selected through houston
+	Session  string    `json:"session"`
+	Agent    string    `json:"agent,omitempty"`    // Agent type of the pane (claude-code, amp, ...)
+	Question string    `json:"question,omitempty"` // The agent's question, when it asks one
+
+	// Escalated is set by an escalate rule
+	Escalated bool `json:"escalated,omitempty"`
+}
+
+// Provider delivers notifications somewhere.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// Notifier sends each notification to every provider, unless a rule
+// routes it elsewhere.
+type Notifier struct {
+	providers []Provider
+	rules     []Rule
+}
+
+// New returns a notifier for the given providers.
+func New(providers ...Provider) *Notifier {
+	return &Notifier{providers: providers}
+}
+
+// Enabled reports whether any provider is configured.
+func (n *Notifier) Enabled() bool {
+	return n != nil && len(n.providers) > 0
+}
+
+// SetRules sets the rules notifications are routed by, first match
+// winning. Providers they name must be configured.
+func (n *Notifier) SetRules(rules []Rule) error {
+	for _, r := range rules {
+		for _, name := range r.Providers {
+			if !slices.ContainsFunc(n.providers, func(p Provider) bool { return p.Name() == name }) {
+				return fmt.Errorf("notify rule %q: provider %q is not configured", r, name)
+			}
+		}
+	}
+	n.rules = rules
+	return nil
+}
+
+// Send delivers a notification to the providers its first matching rule
+// picks, or to all without one. Failures are logged so one broken provider
+// doesn't hold up the others.
+func (n *Notifier) Send(ctx context.Context, note Notification) {
+	if n == nil {
+		return
+	}
+	providers := n.providers
+	if i := slices.IndexFunc(n.rules, func(r Rule) bool { return r.Match(note, time.Now()) }); i >= 0 {
+		rule := n.rules[i]
+		slog.Debug("notification rule matched", "key", note.Key, "rule", rule.String(), "action", rule.Action)
+		if rule.Action == ActionSuppress {
+			return
+		}
+		note.Escalated = rule.Action == ActionEscalate
+		if len(rule.Providers) > 0 {
+			providers = slices.DeleteFunc(slices.Clone(providers), func(p Provider) bool {
+				return !slices.Contains(rule.Providers, p.Name())
+			})
+		}
+	}
+	for _, p := range providers {
+		if err := p.Send(ctx, note); err != nil {
+			slog.Warn("notification failed", "provider", p.Name(), "key", note.Key, "error", err)
+		}
+	}
+}
+
+// Command runs a shell command per notification, with the notification in
+// HOUSTON_TITLE, HOUSTON_BODY, HOUSTON_KEY, HOUSTON_TARGET, HOUSTON_REMINDER
+// and HOUSTON_ESCALATED (1 or 0), e.g.
+// notify-send "$HOUSTON_TITLE" "$HOUSTON_BODY".
+type Command struct {
+	cmd string
+}
+
+// NewCommand returns a provider running cmd with sh -c.
+func NewCommand(cmd string) *Command {
+	return &Command{cmd: cmd}
+}
+
+func (c *Command) Name() string { return "command" }
+
+func (c *Command) Send(ctx context.Context, n Notification) error {
+	escalated := "0"
+	if n.Escalated {
+		escalated = "1"
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.cmd)
+	cmd.Env = append(os.Environ(),
+		"HOUSTON_TITLE="+n.Title,
+		"HOUSTON_BODY="+n.Body,
+		"HOUSTON_KEY="+n.Key,
+		"HOUSTON_TARGET="+n.Target,
+		"HOUSTON_REMINDER="+strconv.Itoa(n.Reminder),
+		"HOUSTON_ESCALATED="+escalated,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+// Webhook POSTs each notification as JSON to a URL.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook returns a provider posting to url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (w *Webhook) Name() string { return "webhook" }
+
+func (w *Webhook) Send(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
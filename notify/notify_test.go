@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseReminders(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []time.Duration
+		wantErr bool
+	}{
+		{"5m,15m,1h", DefaultReminders, false},
+		{"1h, 5m", []time.Duration{5 * time.Minute, time.Hour}, false},
+		{"off", nil, false},
+		{"", nil, false},
+		{"5m,soon", nil, true},
+		{"-5m", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseReminders(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("ParseReminders(%q) = %v, %v, want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		waiting time.Duration
+		want    int
+	}{
+		{0, 0},
+		{4 * time.Minute, 0},
+		{5 * time.Minute, 1},
+		{20 * time.Minute, 2},
+		{3 * time.Hour, 3},
+	}
+	for _, tt := range tests {
+		if got := Level(DefaultReminders, tt.waiting); got != tt.want {
+			t.Errorf("Level(%v) = %d, want %d", tt.waiting, got, tt.want)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	steps := []struct {
+		level int
+		want  bool
+	}{
+		{0, true},  // Entered attention
+		{0, false}, // Still waiting
+		{1, true},  // First reminder
+		{1, false},
+		{3, true}, // Skipped levels are sent once
+		{2, false},
+	}
+	for i, s := range steps {
+		if got := tr.Due("work:1", s.level); got != s.want {
+			t.Errorf("step %d: Due(level %d) = %v, want %v", i, s.level, got, s.want)
+		}
+	}
+
+	tr.Retain(func(string) bool { return false })
+	if !tr.Due("work:1", 0) {
+		t.Error("Due after Retain dropped the key = false, want true")
+	}
+}
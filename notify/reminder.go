@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReminders are the waiting times after which an unanswered prompt
+// is notified again.
+var DefaultReminders = []time.Duration{5 * time.Minute, 15 * time.Minute, time.Hour}
+
+// ParseReminders parses a comma-separated list of durations ("5m,15m,1h").
+// "off" or an empty string disables reminders.
+func ParseReminders(s string) ([]time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return nil, nil
+	}
+	var reminders []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid reminder interval %q", part)
+		}
+		reminders = append(reminders, d)
+	}
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i] < reminders[j] })
+	return reminders, nil
+}
+
+// Level returns how many reminder intervals a prompt waiting this long has
+// passed: 0 before the first reminder.
+func Level(reminders []time.Duration, waiting time.Duration) int {
+	level := 0
+	for _, d := range reminders {
+		if waiting >= d {
+			level++
+		}
+	}
+	return level
+}
+
+// Tracker remembers which reminder level was notified for each key, so
+// every level is sent once per prompt.
+type Tracker struct {
+	mu   sync.Mutex
+	sent map[string]int
+}
+
+// NewTracker returns an empty tracker.
+func NewTracker() *Tracker {
+	return &Tracker{sent: make(map[string]int)}
+}
+
+// Due reports whether level has not been notified for key yet, and marks
+// it notified.
+func (t *Tracker) Due(key string, level int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if sent, ok := t.sent[key]; ok && sent >= level {
+		return false
+	}
+	t.sent[key] = level
+	return true
+}
+
+// Retain forgets keys that no longer need attention, so a later prompt in
+// the same window starts from the first notification again.
+func (t *Tracker) Retain(keep func(key string) bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.sent {
+		if !keep(key) {
+			delete(t.sent, key)
+		}
+	}
+}
+
+// Levels returns the last level notified for each key.
+func (t *Tracker) Levels() map[string]int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return maps.Clone(t.sent)
+}
+
+// Restore marks levels as notified, e.g. by a previous houston process.
+func (t *Tracker) Restore(levels map[string]int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, level := range levels {
+		if sent, ok := t.sent[key]; !ok || level > sent {
+			t.sent[key] = level
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Action is what a matching rule does with a notification.
+type Action string
+
+const (
+	ActionNotify   Action = "notify"   // Send it, only to the rule's providers if it names any
+	ActionSuppress Action = "suppress" // Drop it
+	ActionEscalate Action = "escalate" // Send it marked escalated (Slack mentions the channel)
+)
+
+// Rule routes the notifications it matches. Empty conditions match
+// anything; the first matching rule of a notifier decides.
+type Rule struct {
+	Session   string         // Glob on the session name
+	Agent     string         // Agent type: claude-code, amp, opencode, generic
+	Question  *regexp.Regexp // On the agent's question; notifications without one don't match
+	From, To  int            // Local time of day in minutes, From inclusive, To exclusive; equal: any time
+	Reminder  int            // Matches this reminder and later ones (0: the first notification too)
+	Action    Action
+	Providers []string // Provider names for notify and escalate (empty: all)
+
+	spec string
+}
+
+// ParseRule parses "session:prod-*,agent:claude-code,hours:22:00-07:00 => escalate:slack".
+// Conditions are session, agent, question, hours and reminder; question is
+// a regular expression taking the rest of the conditions, commas included,
+// so it comes last. The action is notify or escalate, optionally with
+// provider names (notify:slack,command), or suppress.
+func ParseRule(s string) (Rule, error) {
+	conds, action, ok := strings.Cut(s, "=>")
+	conds, action = strings.TrimSpace(conds), strings.TrimSpace(action)
+	if !ok || conds == "" || action == "" {
+		return Rule{}, fmt.Errorf("notify rule %q: want conditions => action", s)
+	}
+
+	rule := Rule{spec: s}
+	for rest := conds; rest != ""; {
+		var part string
+		part, rest, _ = strings.Cut(rest, ",")
+		name, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+		value = strings.TrimSpace(value)
+		switch name {
+		case "session":
+			if _, err := path.Match(value, ""); err != nil || value == "" {
+				return Rule{}, fmt.Errorf("notify rule %q: bad session pattern %q", s, value)
+			}
+			rule.Session = value
+		case "agent":
+			if value == "claude" {
+				value = "claude-code"
+			}
+			if !slices.Contains([]string{"claude-code", "amp", "opencode", "generic"}, value) {
+				return Rule{}, fmt.Errorf("notify rule %q: unknown agent %q (want claude-code, amp, opencode or generic)", s, value)
+			}
+			rule.Agent = value
+		case "question":
+			if rest != "" {
+				value += "," + rest
+				rest = ""
+			}
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("notify rule %q: bad question pattern: %w", s, err)
+			}
+			rule.Question = re
+		case "hours":
+			from, to, ok := strings.Cut(value, "-")
+			var err1, err2 error
+			rule.From, err1 = parseClock(from)
+			rule.To, err2 = parseClock(to)
+			if !ok || err1 != nil || err2 != nil {
+				return Rule{}, fmt.Errorf("notify rule %q: invalid hours %q (want HH:MM-HH:MM)", s, value)
+			}
+		case "reminder":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return Rule{}, fmt.Errorf("notify rule %q: invalid reminder %q", s, value)
+			}
+			rule.Reminder = n
+		default:
+			return Rule{}, fmt.Errorf("notify rule %q: unknown condition %q (want session, agent, question, hours or reminder)", s, name)
+		}
+	}
+
+	name, providers, _ := strings.Cut(action, ":")
+	rule.Action = Action(strings.TrimSpace(name))
+	switch rule.Action {
+	case ActionNotify, ActionEscalate:
+		for _, p := range strings.Split(providers, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				rule.Providers = append(rule.Providers, p)
+			}
+		}
+	case ActionSuppress:
+		if providers != "" {
+			return Rule{}, fmt.Errorf("notify rule %q: suppress takes no providers", s)
+		}
+	default:
+		return Rule{}, fmt.Errorf("notify rule %q: unknown action %q (want notify, suppress or escalate)", s, name)
+	}
+	return rule, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (r Rule) String() string {
+	return r.spec
+}
+
+// Match reports whether the rule applies to n, sent at now.
+func (r Rule) Match(n Notification, now time.Time) bool {
+	if r.Session != "" {
+		if ok, _ := path.Match(r.Session, n.Session); !ok {
+			return false
+		}
+	}
+	if r.Agent != "" && r.Agent != n.Agent {
+		return false
+	}
+	if r.Question != nil && (n.Question == "" || !r.Question.MatchString(n.Question)) {
+		return false
+	}
+	if r.From != r.To {
+		minute := now.Hour()*60 + now.Minute()
+		if r.From < r.To && (minute < r.From || minute >= r.To) {
+			return false
+		}
+		if r.From > r.To && minute < r.From && minute >= r.To { // Past midnight, e.g. 22:00-07:00
+			return false
+		}
+	}
+	return n.Reminder >= r.Reminder
+}
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	rule, err := ParseRule("session:prod-*, agent:claude, hours:22:00-07:00, reminder:1, question:(?i)deploy, push => escalate:slack,command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Session != "prod-*" || rule.Agent != "claude-code" || rule.From != 22*60 || rule.To != 7*60 || rule.Reminder != 1 {
+		t.Errorf("conditions = %+v", rule)
+	}
+	if rule.Question == nil || rule.Question.String() != "(?i)deploy, push" {
+		t.Errorf("question = %v, want the rest of the conditions", rule.Question)
+	}
+	if rule.Action != ActionEscalate || !slices.Equal(rule.Providers, []string{"slack", "command"}) {
+		t.Errorf("action = %s %v", rule.Action, rule.Providers)
+	}
+
+	for _, bad := range []string{
+		"agent:amp",
+		"=> suppress",
+		"agent:amp =>",
+		"agent:codex => suppress",
+		"session:[ => suppress",
+		"hours:9-17 => suppress",
+		"reminder:-1 => suppress",
+		"question:( => suppress",
+		"branch:main => suppress",
+		"agent:amp => suppress:slack",
+		"agent:amp => page",
+	} {
+		if _, err := ParseRule(bad); err == nil {
+			t.Errorf("ParseRule(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	at := func(clock string) time.Time {
+		t, _ := time.Parse("15:04", clock)
+		return t
+	}
+	deploy := Notification{Session: "prod-api", Agent: "claude-code", Question: "Run deploy.sh?", Reminder: 1}
+	idle := Notification{Session: "prod-api", Agent: "claude-code", Body: "Idle"}
+	tests := []struct {
+		rule string
+		n    Notification
+		now  string
+		want bool
+	}{
+		{"session:prod-* => notify", deploy, "12:00", true},
+		{"session:dev-* => notify", deploy, "12:00", false},
+		{"agent:amp => notify", deploy, "12:00", false},
+		{"question:deploy => notify", deploy, "12:00", true},
+		{"question:deploy => notify", idle, "12:00", false}, // No question to match
+		{"question:^Delete => notify", deploy, "12:00", false},
+		{"hours:09:00-18:00 => notify", deploy, "12:00", true},
+		{"hours:09:00-18:00 => notify", deploy, "18:00", false},
+		{"hours:22:00-07:00 => notify", deploy, "23:30", true},
+		{"hours:22:00-07:00 => notify", deploy, "06:59", true},
+		{"hours:22:00-07:00 => notify", deploy, "12:00", false},
+		{"reminder:1 => notify", deploy, "12:00", true},
+		{"reminder:1 => notify", idle, "12:00", false},
+	}
+	for _, tt := range tests {
+		rule, err := ParseRule(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := rule.Match(tt.n, at(tt.now)); got != tt.want {
+			t.Errorf("%q.Match(%+v at %s) = %v, want %v", tt.rule, tt.n, tt.now, got, tt.want)
+		}
+	}
+}
+
+type recorder struct {
+	name string
+	sent []Notification
+}
+
+func (r *recorder) Name() string { return r.name }
+
+func (r *recorder) Send(_ context.Context, n Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+func TestNotifierRules(t *testing.T) {
+	slack, webhook := &recorder{name: "slack"}, &recorder{name: "webhook"}
+	n := New(slack, webhook)
+	var rules []Rule
+	for _, s := range []string{
+		"session:scratch => suppress",
+		"question:(?i)deploy => escalate:slack",
+		"agent:amp => notify:webhook",
+	} {
+		rule, err := ParseRule(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := n.SetRules(rules); err != nil {
+		t.Fatal(err)
+	}
+
+	n.Send(context.Background(), Notification{Key: "scratch", Session: "scratch", Question: "Deploy?"})
+	n.Send(context.Background(), Notification{Key: "deploy", Session: "api", Question: "Deploy?"})
+	n.Send(context.Background(), Notification{Key: "amp", Session: "api", Agent: "amp"})
+	n.Send(context.Background(), Notification{Key: "other", Session: "api", Agent: "claude-code"})
+
+	keys := func(r *recorder) (keys []string) {
+		for _, n := range r.sent {
+			keys = append(keys, n.Key)
+		}
+		return keys
+	}
+	if got := keys(slack); !slices.Equal(got, []string{"deploy", "other"}) {
+		t.Errorf("slack got %v", got)
+	}
+	if got := keys(webhook); !slices.Equal(got, []string{"amp", "other"}) {
+		t.Errorf("webhook got %v", got)
+	}
+	if !slack.sent[0].Escalated || slack.sent[1].Escalated {
+		t.Errorf("escalated = %v, %v, want only the deploy question", slack.sent[0].Escalated, slack.sent[1].Escalated)
+	}
+
+	rule, _ := ParseRule("agent:amp => notify:pager")
+	if err := n.SetRules([]Rule{rule}); err == nil {
+		t.Error("SetRules with an unconfigured provider succeeded")
+	}
+}
@@ -0,0 +1,253 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// slackAPI is Slack's Web API base URL.
+const slackAPI = "https://slack.com/api"
+
+// Slack limits: button labels and values, and elements per actions block.
+const (
+	slackMaxLabel   = 75
+	slackMaxValue   = 2000
+	slackMaxButtons = 25
+)
+
+// slackMaxSkew is how old a signed interaction request may be before it
+// is refused as a possible replay.
+const slackMaxSkew = 5 * time.Minute
+
+// Slack posts notifications to a channel with chat.postMessage. With the
+// app's signing secret, each verified choice gets a button; clicks come
+// back to houston's interactivity endpoint (/api/slack/interactions).
+type Slack struct {
+	token         string
+	channel       string
+	signingSecret string
+	api           string
+	client        *http.Client
+}
+
+// NewSlack returns a provider posting to channel with a bot token
+// (xoxb-...). signingSecret may be empty: messages then carry no buttons.
+func NewSlack(token, channel, signingSecret string) *Slack {
+	return &Slack{
+		token:         token,
+		channel:       channel,
+		signingSecret: signingSecret,
+		api:           slackAPI,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *Slack) Name() string { return "slack" }
+
+// Interactive reports whether messages carry choice buttons.
+func (s *Slack) Interactive() bool {
+	return s.signingSecret != ""
+}
+
+// SlackChoice is the value of a choice button: enough to select the
+// choice, and to refuse if the pane shows a different prompt by then.
+type SlackChoice struct {
+	Target string `json:"t"`
+	Host   string `json:"h,omitempty"`
+	Index  int    `json:"i"`
+	Choice string `json:"c"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackElement struct {
+	Type     string     `json:"type"`
+	Text     *slackText `json:"text,omitempty"`
+	ActionID string     `json:"action_id,omitempty"`
+	Value    string     `json:"value,omitempty"`
+}
+
+type slackBlock struct {
+	Type     string     `json:"type"`
+	Text     *slackText `json:"text,omitempty"`
+	Elements []any      `json:"elements,omitempty"`
+}
+
+func (s *Slack) Send(ctx context.Context, n Notification) error {
+	return s.call(ctx, s.api+"/chat.postMessage", s.message(n))
+}
+
+// message builds the chat.postMessage request for a notification.
+func (s *Slack) message(n Notification) map[string]any {
+	target := n.Target
+	if n.Host != "" {
+		target = n.Host + "|" + target
+	}
+	text := "*" + slackEscape(n.Title) + "*\n" + slackEscape(n.Body)
+	if n.Escalated {
+		text = "<!channel> " + text
+	}
+	blocks := []slackBlock{
+		{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}},
+		{Type: "context", Elements: []any{slackText{Type: "mrkdwn", Text: "`" + slackEscape(target) + "`"}}},
+	}
+
+	if s.Interactive() && len(n.Choices) > 0 {
+		var buttons []any
+		for i, choice := range n.Choices[:min(len(n.Choices), slackMaxButtons)] {
+			value, err := json.Marshal(SlackChoice{Target: n.Target, Host: n.Host, Index: i, Choice: choice})
+			if err != nil || len(value) > slackMaxValue {
+				continue
+			}
+			buttons = append(buttons, slackElement{
+				Type:     "button",
+				Text:     &slackText{Type: "plain_text", Text: truncate(choice, slackMaxLabel)},
+				ActionID: "choose-" + strconv.Itoa(i),
+				Value:    string(value),
+			})
+		}
+		if len(buttons) > 0 {
+			blocks = append(blocks, slackBlock{Type: "actions", Elements: buttons})
+		}
+	}
+
+	return map[string]any{
+		"channel": s.channel,
+		"text":    n.Title + ": " + n.Body, // Shown in notifications and by clients without blocks
+		"blocks":  blocks,
+	}
+}
+
+// Respond replaces the message a button was clicked on with text, through
+// the interaction's response_url.
+func (s *Slack) Respond(ctx context.Context, responseURL, text string) error {
+	return s.call(ctx, responseURL, map[string]any{"replace_original": true, "text": text})
+}
+
+// call POSTs a JSON body to a Slack URL and checks the result.
+func (s *Slack) call(ctx context.Context, u string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if strings.HasPrefix(u, s.api+"/") {
+		req.Header.Set("Authorization", "Bearer "+s.token) // Response URLs carry their own authorization
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned %s", resp.Status)
+	}
+	// Web API methods answer 200 with {"ok": false} on errors; response
+	// URLs answer "ok" as text
+	var result struct {
+		OK    *bool  `json:"ok"`
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&result) == nil && result.OK != nil && !*result.OK {
+		return fmt.Errorf("slack: %s", result.Error)
+	}
+	return nil
+}
+
+// Verify checks an interaction request's signature (X-Slack-Signature over
+// the raw body) and that it is recent.
+func (s *Slack) Verify(h http.Header, body []byte, now time.Time) error {
+	if s.signingSecret == "" {
+		return errors.New("no signing secret configured")
+	}
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("missing request timestamp")
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return errors.New("request timestamp too old")
+	}
+	mac := hmac.New(sha256.New, []byte(s.signingSecret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(h.Get("X-Slack-Signature"))) {
+		return errors.New("bad signature")
+	}
+	return nil
+}
+
+// SlackInteraction is a click on a choice button.
+type SlackInteraction struct {
+	User        string // Slack username, or user ID
+	ResponseURL string
+	Choice      SlackChoice
+}
+
+// ParseSlackInteraction decodes an interaction request body
+// (payload=<JSON>, form encoded) holding a choice button click.
+func ParseSlackInteraction(body []byte) (SlackInteraction, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return SlackInteraction{}, err
+	}
+	var payload struct {
+		Type string `json:"type"`
+		User struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+		} `json:"user"`
+		ResponseURL string `json:"response_url"`
+		Actions     []struct {
+			ActionID string `json:"action_id"`
+			Value    string `json:"value"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		return SlackInteraction{}, fmt.Errorf("decoding payload: %w", err)
+	}
+	if payload.Type != "block_actions" || len(payload.Actions) == 0 || !strings.HasPrefix(payload.Actions[0].ActionID, "choose-") {
+		return SlackInteraction{}, errors.New("not a choice button click")
+	}
+	in := SlackInteraction{User: payload.User.Username, ResponseURL: payload.ResponseURL}
+	if in.User == "" {
+		in.User = payload.User.ID
+	}
+	if err := json.Unmarshal([]byte(payload.Actions[0].Value), &in.Choice); err != nil {
+		return SlackInteraction{}, fmt.Errorf("decoding button value: %w", err)
+	}
+	return in, nil
+}
+
+// slackEscape escapes the characters mrkdwn treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// truncate shortens s to at most n runes, ending in "…" when cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
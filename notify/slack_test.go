@@ -0,0 +1,134 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackSend(t *testing.T) {
+	var auth string
+	var msg struct {
+		Channel string `json:"channel"`
+		Blocks  []struct {
+			Type     string `json:"type"`
+			Elements []struct {
+				Value string `json:"value"`
+				Text  struct {
+					Text string `json:"text"`
+				} `json:"text"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	s := NewSlack("xoxb-test", "C123", "secret")
+	s.api = srv.URL
+	n := Notification{Title: "api — fix-auth", Body: "Run <rm>?", Target: "api:1.0", Host: "devbox", Choices: []string{"Yes", "No, and tell Claude what to do differently"}}
+	if err := s.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer xoxb-test" || msg.Channel != "C123" {
+		t.Errorf("auth = %q, channel = %q", auth, msg.Channel)
+	}
+	if len(msg.Blocks) != 3 || msg.Blocks[2].Type != "actions" || len(msg.Blocks[2].Elements) != 2 {
+		t.Fatalf("blocks = %+v", msg.Blocks)
+	}
+	var choice SlackChoice
+	if err := json.Unmarshal([]byte(msg.Blocks[2].Elements[1].Value), &choice); err != nil {
+		t.Fatal(err)
+	}
+	if want := (SlackChoice{Target: "api:1.0", Host: "devbox", Index: 1, Choice: n.Choices[1]}); choice != want {
+		t.Errorf("button value = %+v, want %+v", choice, want)
+	}
+
+	// Without a signing secret clicks can't come back: no buttons
+	s = NewSlack("xoxb-test", "C123", "")
+	s.api = srv.URL
+	if err := s.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+	if len(msg.Blocks) != 2 {
+		t.Errorf("blocks without a secret = %d, want 2", len(msg.Blocks))
+	}
+}
+
+func TestSlackSendError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer srv.Close()
+	s := NewSlack("xoxb-test", "C404", "")
+	s.api = srv.URL
+	if err := s.Send(context.Background(), Notification{}); err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func signSlack(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + ts + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSlackVerify(t *testing.T) {
+	s := NewSlack("xoxb-test", "C123", "secret")
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte("payload=%7B%7D")
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	h := http.Header{}
+	h.Set("X-Slack-Request-Timestamp", ts)
+	h.Set("X-Slack-Signature", signSlack("secret", ts, body))
+	if err := s.Verify(h, body, now.Add(time.Minute)); err != nil {
+		t.Errorf("valid request: %v", err)
+	}
+	if err := s.Verify(h, body, now.Add(10*time.Minute)); err == nil {
+		t.Error("stale request accepted")
+	}
+	if err := s.Verify(h, []byte("payload=tampered"), now); err == nil {
+		t.Error("tampered body accepted")
+	}
+	h.Set("X-Slack-Signature", signSlack("other", ts, body))
+	if err := s.Verify(h, body, now); err == nil {
+		t.Error("wrong secret accepted")
+	}
+}
+
+func TestParseSlackInteraction(t *testing.T) {
+	value, _ := json.Marshal(SlackChoice{Target: "api:1.0", Index: 0, Choice: "Yes"})
+	payload, _ := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]string{"id": "U1", "username": "alice"},
+		"response_url": "https://hooks.slack.com/actions/T/1/x",
+		"actions":      []map[string]string{{"action_id": "choose-0", "value": string(value)}},
+	})
+	body := []byte(url.Values{"payload": {string(payload)}}.Encode())
+
+	in, err := ParseSlackInteraction(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in.User != "alice" || in.ResponseURL != "https://hooks.slack.com/actions/T/1/x" || in.Choice.Choice != "Yes" || in.Choice.Target != "api:1.0" {
+		t.Errorf("interaction = %+v", in)
+	}
+
+	if _, err := ParseSlackInteraction([]byte("payload=" + url.QueryEscape(`{"type":"view_submission"}`))); err == nil {
+		t.Error("non-button payload accepted")
+	}
+}
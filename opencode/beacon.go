@@ -0,0 +1,91 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/url"
+	"time"
+)
+
+// beaconTTL is how long a server stays a discovery candidate after its last
+// beacon. Beacons are expected every 30 seconds.
+const beaconTTL = 2 * time.Minute
+
+// listenBeacons receives UDP beacons: datagrams with the JSON of a discovery
+// file (at least "url"), sent by OpenCode instances on other machines. New
+// servers are probed right away instead of at the next scan.
+func (d *Discovery) listenBeacons(ctx context.Context) {
+	conn, err := net.ListenPacket("udp", d.beaconAddr)
+	if err != nil {
+		slog.Error("OpenCode beacon listener failed", "addr", d.beaconAddr, "error", err)
+		return
+	}
+	slog.Info("OpenCode listening for beacons", "addr", conn.LocalAddr())
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	buf := make([]byte, 4096)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("OpenCode beacon listener stopped", "error", err)
+			}
+			return
+		}
+		var srv DiscoveredServer
+		if err := json.Unmarshal(buf[:n], &srv); err != nil || srv.URL == "" {
+			slog.Debug("OpenCode beacon ignored", "from", from, "error", err)
+			continue
+		}
+		serverURL := beaconURL(srv.URL, from)
+		if serverURL == "" {
+			continue
+		}
+
+		d.beaconsMu.Lock()
+		_, known := d.beacons[serverURL]
+		d.beacons[serverURL] = time.Now()
+		d.beaconsMu.Unlock()
+		if !known {
+			slog.Info("OpenCode discovered via beacon", "url", serverURL, "project", srv.Project)
+			go d.probe(ctx, serverURL)
+		}
+	}
+}
+
+// beaconURL returns the URL a beacon announces, with a loopback or
+// unspecified host (OpenCode's own view of its address) replaced by the
+// address the beacon came from.
+func beaconURL(raw string, from net.Addr) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	ip := net.ParseIP(u.Hostname())
+	if u.Hostname() == "localhost" || (ip != nil && (ip.IsLoopback() || ip.IsUnspecified())) {
+		if udp, ok := from.(*net.UDPAddr); ok {
+			u.Host = net.JoinHostPort(udp.IP.String(), u.Port())
+		}
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// beaconURLs returns the servers heard from within the beacon TTL.
+func (d *Discovery) beaconURLs() []string {
+	d.beaconsMu.Lock()
+	defer d.beaconsMu.Unlock()
+	var urls []string
+	for u, seen := range d.beacons {
+		if time.Since(seen) > beaconTTL {
+			delete(d.beacons, u)
+			continue
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
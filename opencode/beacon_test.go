@@ -0,0 +1,24 @@
+package opencode
+
+import (
+	"net"
+	"testing"
+)
+
+func TestBeaconURL(t *testing.T) {
+	from := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 50000}
+	tests := []struct {
+		raw, want string
+	}{
+		{"http://127.0.0.1:4096/", "http://10.0.0.7:4096"},
+		{"http://localhost:4097", "http://10.0.0.7:4097"},
+		{"http://0.0.0.0:4098", "http://10.0.0.7:4098"},
+		{"http://devbox.lan:4096", "http://devbox.lan:4096"},
+		{"not a url", ""},
+	}
+	for _, tt := range tests {
+		if got := beaconURL(tt.raw, from); got != tt.want {
+			t.Errorf("beaconURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/noamsto/houston/internal/tracing"
 )
 
 // Client is an HTTP client for the OpenCode server API.
@@ -22,7 +24,8 @@ func NewClient(baseURL string) *Client {
 	return &Client{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: tracing.Transport(nil),
 		},
 	}
 }
@@ -214,6 +217,21 @@ func (c *Client) GetAgents(ctx context.Context) ([]Agent, error) {
 	return agents, nil
 }
 
+// GetProviders returns the configured providers with their models.
+func (c *Client) GetProviders(ctx context.Context) (*ProvidersResponse, error) {
+	resp, err := c.get(ctx, "/config/providers")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var providers ProvidersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&providers); err != nil {
+		return nil, fmt.Errorf("decode providers: %w", err)
+	}
+	return &providers, nil
+}
+
 // GetCurrentProject returns the current project.
 func (c *Client) GetCurrentProject(ctx context.Context) (*Project, error) {
 	resp, err := c.get(ctx, "/project/current")
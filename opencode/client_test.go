@@ -146,3 +146,35 @@ func TestIsAvailable(t *testing.T) {
 		t.Error("expected server to be unavailable")
 	}
 }
+
+func TestClient_GetProviders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/config/providers" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		// OpenCode keys models by ID
+		_, _ = w.Write([]byte(`{
+			"providers": [{"id": "anthropic", "name": "Anthropic", "models": {
+				"sonnet": {"name": "Sonnet"},
+				"haiku": {"id": "haiku", "name": "Haiku"}
+			}}],
+			"default": {"anthropic": "sonnet"}
+		}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewClient(server.URL).GetProviders(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Providers) != 1 || len(resp.Providers[0].Models) != 2 {
+		t.Fatalf("providers = %+v, want one provider with two models", resp.Providers)
+	}
+	want := Model{ID: "haiku", Name: "Haiku", Provider: "anthropic"}
+	if got := resp.Providers[0].Models[0]; got != want {
+		t.Errorf("first model = %+v, want %+v", got, want)
+	}
+	if resp.Default["anthropic"] != "sonnet" {
+		t.Errorf("default = %v, want anthropic: sonnet", resp.Default)
+	}
+}
@@ -93,14 +93,23 @@ type Server struct {
 // Discovery manages finding and tracking OpenCode servers.
 type Discovery struct {
 	servers   map[string]*Server // URL -> Server
+	pids      map[string]int     // URL -> PID, from the plugin's discovery files
 	serversMu sync.RWMutex
 
 	// Configuration
-	ports     []int
-	hostname  string
-	staticURL string // If set, only check this URL
+	ports      []int
+	hostname   string
+	staticURL  string // If set, only check this URL
+	mdns       bool   // Browse mDNS on each scan
+	beaconAddr string // UDP address to receive beacons on (empty: off)
+
+	beacons   map[string]time.Time // Server URL -> last beacon
+	beaconsMu sync.Mutex
 }
 
+// mdnsTimeout is how long a scan waits for mDNS answers.
+const mdnsTimeout = time.Second
+
 // DiscoveryOption configures discovery behavior.
 type DiscoveryOption func(*Discovery)
 
@@ -125,12 +134,29 @@ func WithStaticURL(url string) DiscoveryOption {
 	}
 }
 
+// WithMDNS browses the local network over mDNS on every scan.
+func WithMDNS() DiscoveryOption {
+	return func(d *Discovery) {
+		d.mdns = true
+	}
+}
+
+// WithBeacon receives UDP beacons from OpenCode servers on addr
+// (e.g. ":4095") once background scanning starts.
+func WithBeacon(addr string) DiscoveryOption {
+	return func(d *Discovery) {
+		d.beaconAddr = addr
+	}
+}
+
 // NewDiscovery creates a new OpenCode server discovery.
 func NewDiscovery(opts ...DiscoveryOption) *Discovery {
 	d := &Discovery{
 		servers:  make(map[string]*Server),
+		pids:     make(map[string]int),
 		ports:    DefaultPorts,
 		hostname: "127.0.0.1",
+		beacons:  make(map[string]time.Time),
 	}
 	for _, opt := range opts {
 		opt(d)
@@ -143,7 +169,8 @@ func NewDiscovery(opts ...DiscoveryOption) *Discovery {
 // Discovery sources:
 // 1. Static URL (if configured)
 // 2. Discovery files from houston plugin (~/.local/state/houston/opencode-servers/)
-// 3. Port scanning (default ports 4096-4100)
+// 3. UDP beacons and mDNS, if enabled
+// 4. Port scanning (default ports 4096-4100)
 func (d *Discovery) Scan(ctx context.Context) []*Server {
 	var urls []string
 
@@ -152,12 +179,36 @@ func (d *Discovery) Scan(ctx context.Context) []*Server {
 	} else {
 		// First, check discovery files from houston plugin
 		discovered := ReadDiscoveryFiles()
+		pids := make(map[string]int, len(discovered))
 		for _, srv := range discovered {
 			if srv.URL != "" {
 				urls = append(urls, srv.URL)
+				pids[srv.URL] = srv.PID
 				slog.Info("OpenCode discovered via plugin", "url", srv.URL, "project", srv.Project)
 			}
 		}
+		d.serversMu.Lock()
+		d.pids = pids
+		d.serversMu.Unlock()
+
+		// Servers on other machines or random ports
+		for _, url := range d.beaconURLs() {
+			if !slices.Contains(urls, url) {
+				urls = append(urls, url)
+			}
+		}
+		if d.mdns {
+			found, err := browseMDNS(ctx, mdnsTimeout)
+			if err != nil {
+				slog.Debug("OpenCode mDNS browse failed", "error", err)
+			}
+			for _, url := range found {
+				if !slices.Contains(urls, url) {
+					slog.Debug("OpenCode discovered via mDNS", "url", url)
+					urls = append(urls, url)
+				}
+			}
+		}
 
 		// Also scan default ports as fallback
 		for _, port := range d.ports {
@@ -183,48 +234,48 @@ func (d *Discovery) Scan(ctx context.Context) []*Server {
 		wg.Add(1)
 		go func(url string) {
 			defer wg.Done()
-
-			client := NewClient(url)
-			ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
-			defer cancel()
-
-			health, err := client.Health(ctx)
-			if err != nil {
-				// Server not available at this URL
-				d.removeServer(url)
-				return
-			}
-
-			if !health.Healthy {
-				d.removeServer(url)
-				return
-			}
-
-			// Get project info
-			project, _ := client.GetCurrentProject(ctx)
-
-			server := &Server{
-				URL:     url,
-				Version: health.Version,
-				Project: project,
+			if server := d.probe(ctx, url); server != nil {
+				foundMu.Lock()
+				found = append(found, server)
+				foundMu.Unlock()
 			}
+		}(url)
+	}
 
-			d.addServer(url, server)
+	wg.Wait()
+	return found
+}
 
-			foundMu.Lock()
-			found = append(found, server)
-			foundMu.Unlock()
+// probe checks whether an OpenCode server answers at url and adds it to
+// the known servers, or removes it if it doesn't.
+func (d *Discovery) probe(ctx context.Context, url string) *Server {
+	client := NewClient(url)
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	health, err := client.Health(ctx)
+	if err != nil || !health.Healthy {
+		// Server not available at this URL
+		d.removeServer(url)
+		return nil
+	}
 
-			slog.Info("OpenCode server found",
-				"url", url,
-				"version", health.Version,
-				"project", projectName(project))
+	// Get project info
+	project, _ := client.GetCurrentProject(ctx)
 
-		}(url)
+	server := &Server{
+		URL:     url,
+		Version: health.Version,
+		Project: project,
 	}
 
-	wg.Wait()
-	return found
+	d.addServer(url, server)
+
+	slog.Info("OpenCode server found",
+		"url", url,
+		"version", health.Version,
+		"project", projectName(project))
+	return server
 }
 
 // GetServers returns all currently known servers.
@@ -251,6 +302,10 @@ func (d *Discovery) GetServer(url string) *Server {
 func (d *Discovery) StartBackgroundScan(ctx context.Context, interval time.Duration) context.CancelFunc {
 	ctx, cancel := context.WithCancel(ctx)
 
+	if d.beaconAddr != "" && d.staticURL == "" {
+		go d.listenBeacons(ctx)
+	}
+
 	go func() {
 		// Initial scan
 		d.Scan(ctx)
@@ -271,6 +326,37 @@ func (d *Discovery) StartBackgroundScan(ctx context.Context, interval time.Durat
 	return cancel
 }
 
+// ServerForPane returns the server of an OpenCode TUI running in a tmux
+// pane: the one whose process (known from the plugin's discovery files)
+// runs under the pane's process, or else the only server whose project is
+// the pane's directory. It returns nil when neither identifies one server.
+func (d *Discovery) ServerForPane(panePID int, cwd string) *Server {
+	d.serversMu.RLock()
+	defer d.serversMu.RUnlock()
+
+	if panePID > 0 {
+		for url, pid := range d.pids {
+			if srv, ok := d.servers[url]; ok && processUnder(pid, panePID) {
+				return srv
+			}
+		}
+	}
+	if cwd == "" {
+		return nil
+	}
+	var match *Server
+	for _, srv := range d.servers {
+		if srv.Project == nil || filepath.Clean(srv.Project.Path) != filepath.Clean(cwd) {
+			continue
+		}
+		if match != nil {
+			return nil // Several servers in this directory; can't tell which
+		}
+		match = srv
+	}
+	return match
+}
+
 func (d *Discovery) addServer(url string, server *Server) {
 	d.serversMu.Lock()
 	d.servers[url] = server
@@ -0,0 +1,39 @@
+package opencode
+
+import (
+	"os"
+	"testing"
+)
+
+func TestServerForPane(t *testing.T) {
+	d := NewDiscovery()
+	d.addServer("http://a", &Server{URL: "http://a", Project: &Project{Path: "/work/app"}})
+	d.addServer("http://b", &Server{URL: "http://b", Project: &Project{Path: "/work/lib"}})
+	d.addServer("http://c", &Server{URL: "http://c", Project: &Project{Path: "/work/lib/"}})
+
+	if srv := d.ServerForPane(0, "/work/app"); srv == nil || srv.URL != "http://a" {
+		t.Errorf("ServerForPane(/work/app) = %+v, want http://a", srv)
+	}
+	if srv := d.ServerForPane(0, "/work/lib"); srv != nil {
+		t.Errorf("ServerForPane(/work/lib) = %+v, want nil for two servers in one directory", srv)
+	}
+
+	// This test process stands in for the OpenCode server, its parent for
+	// the pane's shell
+	d.pids["http://c"] = os.Getpid()
+	if srv := d.ServerForPane(os.Getppid(), "/work/lib"); srv == nil || srv.URL != "http://c" {
+		t.Errorf("ServerForPane(parent pid) = %+v, want http://c by process", srv)
+	}
+}
+
+func TestProcessUnder(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("no /proc")
+	}
+	if !processUnder(os.Getpid(), os.Getppid()) {
+		t.Error("process not found under its parent")
+	}
+	if processUnder(os.Getppid(), os.Getpid()) {
+		t.Error("parent found under its child")
+	}
+}
@@ -0,0 +1,232 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// eventProperties is the union of the event properties the manager uses.
+type eventProperties struct {
+	SessionID string          `json:"sessionID"`
+	Info      json.RawMessage `json:"info"`   // Session or Message
+	Status    json.RawMessage `json:"status"` // "busy" or {"type": "busy"}
+	Todos     []Todo          `json:"todos"`
+}
+
+// followServers subscribes to the event stream of every discovered server
+// that isn't followed yet, and drops the subscriptions of servers that are
+// gone.
+func (m *Manager) followServers(ctx context.Context) {
+	servers := m.discovery.GetServers()
+
+	m.eventsMu.Lock()
+	defer m.eventsMu.Unlock()
+	for url, cancel := range m.eventCtxs {
+		if !slices.ContainsFunc(servers, func(s *Server) bool { return s.URL == url }) {
+			cancel()
+			delete(m.eventCtxs, url)
+		}
+	}
+	for _, server := range servers {
+		if _, ok := m.eventCtxs[server.URL]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		m.eventCtxs[server.URL] = cancel
+		go func() {
+			m.follow(ctx, server)
+			cancel()
+			m.eventsMu.Lock()
+			delete(m.eventCtxs, server.URL)
+			m.eventsMu.Unlock()
+		}()
+	}
+}
+
+// follow applies a server's events to its cached sessions until the stream
+// drops. While it runs the server is not polled.
+func (m *Manager) follow(ctx context.Context, server *Server) {
+	events, err := NewClient(server.URL).SubscribeEvents(ctx)
+	if err != nil {
+		slog.Debug("OpenCode event stream unavailable, polling", "server", server.URL, "error", err)
+		return
+	}
+	slog.Debug("OpenCode following events", "server", server.URL)
+
+	defer m.setLive(server.URL, false)
+
+	// Catch up on what happened before the stream connected; events wait
+	// in the channel meanwhile
+	m.statesMu.Lock()
+	c, ok := m.states[server.URL]
+	if !ok {
+		c = &serverCache{}
+		m.states[server.URL] = c
+	}
+	c.live = true
+	busy := c.refreshing
+	c.refreshing = true
+	m.statesMu.Unlock()
+	if !busy {
+		m.refreshServer(ctx, server)
+	}
+
+	for event := range events {
+		m.applyEvent(ctx, server, event)
+	}
+	if ctx.Err() == nil {
+		slog.Info("OpenCode event stream dropped, polling until it reconnects", "server", server.URL)
+	}
+}
+
+func (m *Manager) setLive(serverURL string, live bool) {
+	m.statesMu.Lock()
+	defer m.statesMu.Unlock()
+	if c, ok := m.states[serverURL]; ok {
+		c.live = live
+	}
+}
+
+// applyEvent updates the cached session an event is about.
+func (m *Manager) applyEvent(ctx context.Context, server *Server, event Event) {
+	raw, err := json.Marshal(event.Properties)
+	if err != nil {
+		return
+	}
+	var props eventProperties
+	if err := json.Unmarshal(raw, &props); err != nil {
+		slog.Debug("OpenCode event not understood", "type", event.Type, "error", err)
+		return
+	}
+
+	switch event.Type {
+	case EventSessionCreated, EventSessionUpdated:
+		var sess Session
+		if json.Unmarshal(props.Info, &sess) != nil || sess.ID == "" {
+			return
+		}
+		m.updateSession(server, sess.ID, true, func(s *SessionState) { s.Session = sess })
+
+	case EventSessionDeleted:
+		var sess Session
+		if json.Unmarshal(props.Info, &sess) != nil {
+			return
+		}
+		m.removeSession(server.URL, sess.ID)
+
+	case EventSessionStatus, EventSessionIdle, EventSessionError:
+		status := "idle"
+		switch event.Type {
+		case EventSessionStatus:
+			status = eventStatus(props.Status)
+		case EventSessionError:
+			status = "error"
+		}
+		if props.SessionID == "" || status == "" {
+			return
+		}
+		m.updateSession(server, props.SessionID, false, func(s *SessionState) { s.Status = status })
+
+	case EventMessageUpdated:
+		var msg Message
+		if json.Unmarshal(props.Info, &msg) != nil || msg.SessionID == "" {
+			return
+		}
+		// Only the session's last message is cached; fetch just that one
+		ctx, cancel := context.WithTimeout(ctx, m.serverTimeout)
+		defer cancel()
+		msgs, err := NewClient(server.URL).GetMessages(ctx, msg.SessionID, 1)
+		if err != nil || len(msgs) == 0 {
+			return
+		}
+		last := &msgs[0]
+		m.updateSession(server, msg.SessionID, false, func(s *SessionState) {
+			s.LastMessage = last
+			s.LastActivity = extractActivity(last)
+		})
+
+	case EventTodoUpdated:
+		if props.SessionID == "" {
+			return
+		}
+		m.updateSession(server, props.SessionID, false, func(s *SessionState) { s.setTodos(props.Todos) })
+	}
+}
+
+// updateSession applies update to a cached session. Sessions the cache
+// doesn't have yet are added when create is set, and otherwise left to the
+// session.created event or the next full fetch.
+func (m *Manager) updateSession(server *Server, sessionID string, create bool, update func(*SessionState)) {
+	m.statesMu.Lock()
+	defer m.statesMu.Unlock()
+	c, ok := m.states[server.URL]
+	if !ok {
+		return
+	}
+
+	// Copy on write: callers may still hold the previous slice
+	states := slices.Clone(c.states)
+	i := slices.IndexFunc(states, func(s SessionState) bool { return s.Session.ID == sessionID })
+	if i < 0 && !create {
+		return
+	}
+	if i < 0 {
+		states = append(states, SessionState{
+			Session:   Session{ID: sessionID},
+			Status:    "idle",
+			Project:   server.Project,
+			ServerURL: server.URL,
+		})
+		i = len(states) - 1
+	}
+	update(&states[i])
+	c.states = states
+	c.eventAt = time.Now()
+}
+
+// removeSession drops a session from the cache.
+func (m *Manager) removeSession(serverURL, sessionID string) {
+	m.statesMu.Lock()
+	defer m.statesMu.Unlock()
+	if c, ok := m.states[serverURL]; ok {
+		c.states = slices.DeleteFunc(slices.Clone(c.states), func(s SessionState) bool { return s.Session.ID == sessionID })
+		c.eventAt = time.Now()
+	}
+}
+
+// eventStatus reads the status of a session.status event, which newer
+// OpenCode versions send as an object ({"type": "busy"}). Retrying counts
+// as busy.
+func eventStatus(raw json.RawMessage) string {
+	var status string
+	if json.Unmarshal(raw, &status) != nil {
+		var obj struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(raw, &obj) != nil {
+			return ""
+		}
+		status = obj.Type
+	}
+	if status == "retry" {
+		return "busy"
+	}
+	return status
+}
+
+// setTodos replaces the session's todos and recounts them.
+func (s *SessionState) setTodos(todos []Todo) {
+	s.Todos = todos
+	s.ActiveTodos, s.CompletedTodos = 0, 0
+	for _, t := range todos {
+		switch t.Status {
+		case "pending", "in_progress":
+			s.ActiveTodos++
+		case "completed":
+			s.CompletedTodos++
+		}
+	}
+}
@@ -20,12 +20,59 @@ type SessionState struct {
 	ServerURL      string
 }
 
+// Default cache tuning for GetAllSessions.
+const (
+	DefaultServerTimeout = 5 * time.Second // Per-server fetch budget
+	DefaultMaxAge        = 5 * time.Second // Age after which cached sessions are revalidated
+)
+
+// Freshness describes how current the cached sessions of one server are.
+type Freshness struct {
+	ServerURL  string    `json:"server_url"`
+	FetchedAt  time.Time `json:"fetched_at"` // Zero if never fetched successfully
+	AgeSeconds float64   `json:"age_seconds"`
+	Stale      bool      `json:"stale"`      // Older than the max age
+	Refreshing bool      `json:"refreshing"` // A background refresh is in flight
+	Live       bool      `json:"live"`       // Kept current by the server's event stream
+	Error      string    `json:"error,omitempty"`
+}
+
+// serverCache holds the last fetched sessions of one server.
+type serverCache struct {
+	states      []SessionState
+	fetchedAt   time.Time // Last successful fetch
+	attemptedAt time.Time // Last fetch attempt (successful or not)
+	err         error     // Error of the last attempt
+	refreshing  bool
+	live        bool      // Event stream connected; no polling needed
+	eventAt     time.Time // Last event applied
+}
+
+// ManagerOption configures manager behavior.
+type ManagerOption func(*Manager)
+
+// WithServerTimeout bounds how long a single server may take to list its sessions.
+func WithServerTimeout(d time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.serverTimeout = d
+	}
+}
+
+// WithMaxAge sets how old cached sessions may get before they are revalidated.
+func WithMaxAge(d time.Duration) ManagerOption {
+	return func(m *Manager) {
+		m.maxAge = d
+	}
+}
+
 // Manager provides high-level operations for OpenCode integration.
 type Manager struct {
-	discovery *Discovery
+	discovery     *Discovery
+	serverTimeout time.Duration
+	maxAge        time.Duration
 
 	// Cache of session states per server
-	states   map[string][]SessionState // serverURL -> session states
+	states   map[string]*serverCache // serverURL -> cached sessions
 	statesMu sync.RWMutex
 
 	// Event subscriptions per server
@@ -34,46 +81,166 @@ type Manager struct {
 }
 
 // NewManager creates a new OpenCode manager.
-func NewManager(discovery *Discovery) *Manager {
-	return &Manager{
-		discovery: discovery,
-		states:    make(map[string][]SessionState),
-		eventCtxs: make(map[string]context.CancelFunc),
+func NewManager(discovery *Discovery, opts ...ManagerOption) *Manager {
+	m := &Manager{
+		discovery:     discovery,
+		serverTimeout: DefaultServerTimeout,
+		maxAge:        DefaultMaxAge,
+		states:        make(map[string]*serverCache),
+		eventCtxs:     make(map[string]context.CancelFunc),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
-// GetAllSessions returns session states from all discovered servers.
+// GetAllSessions returns session states from all discovered servers with
+// stale-while-revalidate semantics: cached sessions are returned right away
+// and refreshed in the background once older than the max age. Only servers
+// that have never been fetched are waited on, bounded by the server timeout,
+// so one slow instance can't hold up the rest.
 func (m *Manager) GetAllSessions(ctx context.Context) []SessionState {
 	servers := m.discovery.GetServers()
 	if len(servers) == 0 {
 		return nil
 	}
 
-	var allStates []SessionState
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	var unfetched []*Server
+	now := time.Now()
 
+	m.statesMu.Lock()
 	for _, server := range servers {
+		c, ok := m.states[server.URL]
+		if !ok {
+			c = &serverCache{}
+			m.states[server.URL] = c
+		}
+		switch {
+		case c.refreshing:
+		case c.attemptedAt.IsZero():
+			c.refreshing = true
+			unfetched = append(unfetched, server)
+		case !c.live && now.Sub(c.attemptedAt) > m.maxAge:
+			c.refreshing = true
+			go m.refreshServer(context.Background(), server)
+		}
+	}
+	m.statesMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, server := range unfetched {
 		wg.Add(1)
 		go func(server *Server) {
 			defer wg.Done()
+			m.refreshServer(ctx, server)
+		}(server)
+	}
+	wg.Wait()
 
-			states, err := m.fetchServerSessions(ctx, server)
-			if err != nil {
-				slog.Warn("failed to fetch OpenCode sessions",
-					"server", server.URL,
-					"error", err)
-				return
-			}
+	m.statesMu.RLock()
+	defer m.statesMu.RUnlock()
+	var allStates []SessionState
+	for _, server := range servers {
+		if c, ok := m.states[server.URL]; ok {
+			allStates = append(allStates, c.states...)
+		}
+	}
+	return allStates
+}
+
+// RefreshAll fetches every discovered server now, waiting for all of them
+// (each bounded by the server timeout).
+func (m *Manager) RefreshAll(ctx context.Context) {
+	m.refreshServers(ctx, false)
+}
 
-			mu.Lock()
-			allStates = append(allStates, states...)
-			mu.Unlock()
+// refreshServers fetches the discovered servers, skipping those kept live
+// by their event stream unless all is set.
+func (m *Manager) refreshServers(ctx context.Context, all bool) {
+	var wg sync.WaitGroup
+	for _, server := range m.discovery.GetServers() {
+		m.statesMu.Lock()
+		c, ok := m.states[server.URL]
+		if !ok {
+			c = &serverCache{}
+			m.states[server.URL] = c
+		}
+		skip := c.refreshing || (c.live && !all)
+		if !skip {
+			c.refreshing = true
+		}
+		m.statesMu.Unlock()
+		if skip {
+			continue
+		}
+
+		wg.Add(1)
+		go func(server *Server) {
+			defer wg.Done()
+			m.refreshServer(ctx, server)
 		}(server)
 	}
-
 	wg.Wait()
-	return allStates
+}
+
+// refreshServer fetches one server's sessions within the server timeout and
+// updates its cache entry. On failure the previous sessions are kept.
+// The caller must have set the entry's refreshing flag.
+func (m *Manager) refreshServer(ctx context.Context, server *Server) {
+	ctx, cancel := context.WithTimeout(ctx, m.serverTimeout)
+	defer cancel()
+
+	started := time.Now()
+	states, err := m.fetchServerSessions(ctx, server)
+	if err != nil {
+		slog.Warn("failed to fetch OpenCode sessions",
+			"server", server.URL,
+			"error", err)
+	}
+
+	m.statesMu.Lock()
+	defer m.statesMu.Unlock()
+	c, ok := m.states[server.URL]
+	if !ok {
+		c = &serverCache{}
+		m.states[server.URL] = c
+	}
+	c.refreshing = false
+	c.err = err
+	// Events applied while fetching are newer than the fetched sessions
+	if err == nil && !c.eventAt.After(started) {
+		c.states = states
+		c.fetchedAt = started
+	}
+	c.attemptedAt = time.Now()
+}
+
+// Freshness reports cache age per discovered server.
+func (m *Manager) Freshness() []Freshness {
+	servers := m.discovery.GetServers()
+	now := time.Now()
+
+	m.statesMu.RLock()
+	defer m.statesMu.RUnlock()
+	result := make([]Freshness, 0, len(servers))
+	for _, server := range servers {
+		f := Freshness{ServerURL: server.URL, Stale: true}
+		if c, ok := m.states[server.URL]; ok {
+			f.FetchedAt = c.fetchedAt
+			f.Refreshing = c.refreshing
+			f.Live = c.live
+			if !c.fetchedAt.IsZero() {
+				f.AgeSeconds = now.Sub(c.fetchedAt).Seconds()
+				f.Stale = !c.live && now.Sub(c.fetchedAt) > m.maxAge
+			}
+			if c.err != nil {
+				f.Error = c.err.Error()
+			}
+		}
+		result = append(result, f)
+	}
+	return result
 }
 
 // fetchServerSessions gets sessions from a single server.
@@ -164,11 +331,6 @@ func (m *Manager) fetchServerSessions(ctx context.Context, server *Server) ([]Se
 		states = append(states, state)
 	}
 
-	// Cache states
-	m.statesMu.Lock()
-	m.states[server.URL] = states
-	m.statesMu.Unlock()
-
 	return states, nil
 }
 
@@ -253,15 +415,7 @@ func (m *Manager) GetSessionDetails(ctx context.Context, serverURL, sessionID st
 	}
 
 	if todoErr == nil {
-		state.Todos = todos
-		for _, t := range todos {
-			switch t.Status {
-			case "pending", "in_progress":
-				state.ActiveTodos++
-			case "completed":
-				state.CompletedTodos++
-			}
-		}
+		state.setTodos(todos)
 	}
 
 	// Get status
@@ -295,6 +449,81 @@ func (m *Manager) SendPrompt(ctx context.Context, serverURL, sessionID, text str
 	return client.SendPromptAsync(ctx, sessionID, req)
 }
 
+// NewSession describes a session to create.
+type NewSession struct {
+	Title  string
+	Prompt string         // Opening prompt (optional)
+	Agent  string         // Agent for the opening prompt (default: the server's)
+	Model  *ModelSelector // Model for the opening prompt (default: the server's)
+}
+
+// CreateSession creates a new session on a server and sends it the opening
+// prompt, if any.
+func (m *Manager) CreateSession(ctx context.Context, serverURL string, ns NewSession) (*Session, error) {
+	client := NewClient(serverURL)
+
+	session, err := client.CreateSession(ctx, ns.Title, nil)
+	if err != nil {
+		return nil, err
+	}
+	server := m.discovery.GetServer(serverURL)
+	if server == nil {
+		server = &Server{URL: serverURL}
+	}
+	m.updateSession(server, session.ID, true, func(s *SessionState) { s.Session = *session })
+
+	if ns.Prompt != "" {
+		req := PromptRequest{
+			Parts: []PromptPart{{Type: "text", Text: ns.Prompt}},
+			Agent: ns.Agent,
+			Model: ns.Model,
+		}
+		if err := client.SendPromptAsync(ctx, session.ID, req); err != nil {
+			return session, err
+		}
+	}
+	return session, nil
+}
+
+// DeleteSession deletes a session and drops it from the cache.
+func (m *Manager) DeleteSession(ctx context.Context, serverURL, sessionID string) error {
+	if err := NewClient(serverURL).DeleteSession(ctx, sessionID); err != nil {
+		return err
+	}
+	m.removeSession(serverURL, sessionID)
+	return nil
+}
+
+// Models lists what a server can run a prompt with: its providers and
+// models, the default model per provider, and its agents.
+func (m *Manager) Models(ctx context.Context, serverURL string) (*ProvidersResponse, []Agent, error) {
+	client := NewClient(serverURL)
+
+	var providers *ProvidersResponse
+	var agents []Agent
+	var provErr, agentErr error
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		providers, provErr = client.GetProviders(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		agents, agentErr = client.GetAgents(ctx)
+	}()
+	wg.Wait()
+
+	if provErr != nil {
+		return nil, nil, provErr
+	}
+	if agentErr != nil {
+		slog.Debug("could not get OpenCode agents", "server", serverURL, "error", agentErr)
+	}
+	return providers, agents, nil
+}
+
 // AbortSession aborts a running session.
 func (m *Manager) AbortSession(ctx context.Context, serverURL, sessionID string) error {
 	client := NewClient(serverURL)
@@ -343,30 +572,70 @@ func (m *Manager) UnsubscribeFromServer(serverURL string) {
 	m.eventsMu.Unlock()
 }
 
+// CurrentSession guesses the session an OpenCode TUI attached to a server
+// shows: a busy top-level session, else the most recently updated one.
+func (m *Manager) CurrentSession(serverURL string) (SessionState, bool) {
+	m.statesMu.RLock()
+	defer m.statesMu.RUnlock()
+	c, ok := m.states[serverURL]
+	if !ok {
+		return SessionState{}, false
+	}
+
+	best := -1
+	for i, s := range c.states {
+		if s.Session.ParentID != nil {
+			continue // Subagent sessions run under another
+		}
+		if best < 0 {
+			best = i
+			continue
+		}
+		b := c.states[best]
+		if (s.Status == "busy") != (b.Status == "busy") {
+			if s.Status == "busy" {
+				best = i
+			}
+			continue
+		}
+		if s.Session.UpdatedAt.After(b.Session.UpdatedAt) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return SessionState{}, false
+	}
+	return c.states[best], true
+}
+
 // GetCachedStates returns cached session states (for fast access).
 func (m *Manager) GetCachedStates() []SessionState {
 	m.statesMu.RLock()
 	defer m.statesMu.RUnlock()
 
 	var all []SessionState
-	for _, states := range m.states {
-		all = append(all, states...)
+	for _, c := range m.states {
+		all = append(all, c.states...)
 	}
 	return all
 }
 
-// StartBackgroundRefresh starts periodic session refresh.
+// StartBackgroundRefresh keeps the cached sessions current. Every interval
+// it subscribes to the event stream of servers that don't have one yet and
+// polls only the servers whose stream is down (older OpenCode versions, or
+// a dropped connection until it is re-established).
 func (m *Manager) StartBackgroundRefresh(ctx context.Context, interval time.Duration) {
 	go func() {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 
 		for {
+			m.followServers(ctx)
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				m.GetAllSessions(ctx)
+				m.refreshServers(ctx, false)
 			}
 		}
 	}()
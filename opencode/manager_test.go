@@ -0,0 +1,208 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSessionServer serves one session whose title is returned by title(),
+// delaying /session responses by delay.
+func newSessionServer(t *testing.T, delay time.Duration, title func() string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/session":
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+			_ = json.NewEncoder(w).Encode([]Session{{ID: "ses_1", Title: title()}})
+		case "/session/status":
+			_ = json.NewEncoder(w).Encode(map[string]SessionStatus{})
+		default:
+			_ = json.NewEncoder(w).Encode([]MessageWithParts{})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestManagerSlowServerDoesNotBlock(t *testing.T) {
+	fast := newSessionServer(t, 0, func() string { return "fast" })
+	slow := newSessionServer(t, 2*time.Second, func() string { return "slow" })
+
+	d := NewDiscovery()
+	d.addServer(fast.URL, &Server{URL: fast.URL})
+	d.addServer(slow.URL, &Server{URL: slow.URL})
+	m := NewManager(d, WithServerTimeout(200*time.Millisecond))
+
+	start := time.Now()
+	states := m.GetAllSessions(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetAllSessions() took %v, want bounded by server timeout", elapsed)
+	}
+	if len(states) != 1 || states[0].Session.Title != "fast" {
+		t.Errorf("GetAllSessions() = %+v, want only the fast server's session", states)
+	}
+
+	for _, f := range m.Freshness() {
+		if f.ServerURL == slow.URL && f.Error == "" {
+			t.Errorf("Freshness() for slow server has no error: %+v", f)
+		}
+		if f.ServerURL == fast.URL && (f.FetchedAt.IsZero() || f.Stale) {
+			t.Errorf("Freshness() for fast server = %+v, want fresh", f)
+		}
+	}
+}
+
+func TestManagerStaleWhileRevalidate(t *testing.T) {
+	var version atomic.Int32
+	version.Store(1)
+	title := func() string {
+		if version.Load() == 1 {
+			return "v1"
+		}
+		return "v2"
+	}
+	srv := newSessionServer(t, 0, title)
+
+	d := NewDiscovery()
+	d.addServer(srv.URL, &Server{URL: srv.URL})
+	m := NewManager(d, WithMaxAge(50*time.Millisecond))
+
+	if states := m.GetAllSessions(context.Background()); len(states) != 1 || states[0].Session.Title != "v1" {
+		t.Fatalf("first GetAllSessions() = %+v, want v1", states)
+	}
+
+	version.Store(2)
+	// Fresh cache: served without refetching
+	if states := m.GetAllSessions(context.Background()); states[0].Session.Title != "v1" {
+		t.Errorf("GetAllSessions() within max age = %q, want cached v1", states[0].Session.Title)
+	}
+
+	// Stale cache: served immediately, refreshed in the background
+	time.Sleep(60 * time.Millisecond)
+	if states := m.GetAllSessions(context.Background()); states[0].Session.Title != "v1" {
+		t.Errorf("stale GetAllSessions() = %q, want stale v1 served immediately", states[0].Session.Title)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cached := m.GetCachedStates(); len(cached) == 1 && cached[0].Session.Title == "v2" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("background refresh did not update the cache to v2")
+}
+
+func TestManagerFollowsEvents(t *testing.T) {
+	var fetches atomic.Int32
+	events := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/event":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case e := <-events:
+					_, _ = io.WriteString(w, "data: "+e+"\n\n")
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		case "/session":
+			fetches.Add(1)
+			_ = json.NewEncoder(w).Encode([]Session{{ID: "ses_1", Title: "one"}})
+		case "/session/status":
+			_ = json.NewEncoder(w).Encode(map[string]SessionStatus{})
+		default:
+			_ = json.NewEncoder(w).Encode([]MessageWithParts{})
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	d := NewDiscovery()
+	d.addServer(srv.URL, &Server{URL: srv.URL})
+	m := NewManager(d, WithMaxAge(time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m.StartBackgroundRefresh(ctx, time.Hour)
+
+	waitFor := func(what string, ok func([]SessionState) bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if ok(m.GetCachedStates()) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("%s: cache = %+v", what, m.GetCachedStates())
+	}
+	waitFor("catch-up fetch", func(s []SessionState) bool { return len(s) == 1 })
+
+	events <- `{"type":"session.status","properties":{"sessionID":"ses_1","status":{"type":"busy"}}}`
+	waitFor("status event", func(s []SessionState) bool { return s[0].Status == "busy" })
+
+	events <- `{"type":"todo.updated","properties":{"sessionID":"ses_1","todos":[{"id":"1","status":"in_progress"},{"id":"2","status":"completed"}]}}`
+	waitFor("todo event", func(s []SessionState) bool { return s[0].ActiveTodos == 1 && s[0].CompletedTodos == 1 })
+
+	events <- `{"type":"session.created","properties":{"info":{"id":"ses_2","title":"two"}}}`
+	waitFor("created event", func(s []SessionState) bool { return len(s) == 2 })
+
+	// Live servers are not revalidated by age
+	time.Sleep(5 * time.Millisecond)
+	m.GetAllSessions(ctx)
+	time.Sleep(50 * time.Millisecond)
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("sessions fetched %d times, want only the catch-up fetch", n)
+	}
+	if f := m.Freshness(); len(f) != 1 || !f[0].Live || f[0].Stale {
+		t.Errorf("Freshness() = %+v, want live and not stale", f)
+	}
+}
+
+func TestEventStatus(t *testing.T) {
+	for raw, want := range map[string]string{
+		`"busy"`:           "busy",
+		`{"type":"idle"}`:  "idle",
+		`{"type":"retry"}`: "busy",
+		`42`:               "",
+	} {
+		if got := eventStatus(json.RawMessage(raw)); got != want {
+			t.Errorf("eventStatus(%s) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestManagerCurrentSession(t *testing.T) {
+	now := time.Now()
+	parent := "ses_1"
+	m := NewManager(NewDiscovery())
+	m.states["http://a"] = &serverCache{states: []SessionState{
+		{Session: Session{ID: "ses_1", UpdatedAt: now.Add(-time.Hour)}, Status: "busy"},
+		{Session: Session{ID: "ses_2", UpdatedAt: now}, Status: "idle"},
+		{Session: Session{ID: "ses_3", UpdatedAt: now, ParentID: &parent}, Status: "busy"},
+	}}
+
+	if s, ok := m.CurrentSession("http://a"); !ok || s.Session.ID != "ses_1" {
+		t.Errorf("CurrentSession() = %q, want the busy top-level session", s.Session.ID)
+	}
+	m.states["http://a"].states[0].Status = "idle"
+	if s, ok := m.CurrentSession("http://a"); !ok || s.Session.ID != "ses_2" {
+		t.Errorf("CurrentSession() = %q, want the most recently updated", s.Session.ID)
+	}
+	if _, ok := m.CurrentSession("http://b"); ok {
+		t.Error("CurrentSession() of an unknown server: want false")
+	}
+}
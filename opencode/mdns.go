@@ -0,0 +1,237 @@
+package opencode
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// mDNS services OpenCode servers advertise. `opencode serve --mdns`
+// publishes an "opencode-<port>" instance of _http._tcp; _opencode._tcp is
+// browsed too for servers announced under their own type.
+var mdnsServices = []string{"_opencode._tcp.local.", "_http._tcp.local."}
+
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// DNS record types used by mDNS service discovery.
+const (
+	dnsTypeA   = 1
+	dnsTypePTR = 12
+	dnsTypeSRV = 33
+)
+
+// dnsRecord is a resource record, with the fields service discovery needs.
+type dnsRecord struct {
+	name   string
+	typ    uint16
+	target string // PTR: instance name; SRV: host name
+	port   int    // SRV
+	ip     net.IP // A
+}
+
+// browseMDNS sends a one-shot mDNS query for OpenCode services and returns
+// the URLs of the servers that answer within timeout. The query asks for
+// unicast replies, so nothing has to bind port 5353 next to avahi or
+// mDNSResponder.
+func browseMDNS(ctx context.Context, timeout time.Duration) ([]string, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.WriteToUDP(mdnsQuery(mdnsServices), mdnsGroup); err != nil {
+		return nil, fmt.Errorf("send mDNS query: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetReadDeadline(deadline)
+
+	seen := make(map[string]bool)
+	var urls []string
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// The read deadline ends the browse
+			return urls, nil
+		}
+		records, err := parseDNSMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		for _, url := range mdnsServerURLs(records, from.IP) {
+			if !seen[url] {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+}
+
+// mdnsServerURLs picks OpenCode servers out of an mDNS response: instances
+// of the browsed services, located by their SRV record and the host's A
+// record, or the responder's address without one.
+func mdnsServerURLs(records []dnsRecord, from net.IP) []string {
+	srv := make(map[string]dnsRecord)
+	hosts := make(map[string]net.IP)
+	for _, r := range records {
+		switch r.typ {
+		case dnsTypeSRV:
+			srv[strings.ToLower(r.name)] = r
+		case dnsTypeA:
+			hosts[strings.ToLower(r.name)] = r.ip
+		}
+	}
+
+	var urls []string
+	for _, r := range records {
+		if r.typ != dnsTypePTR || !isOpenCodeInstance(r.name, r.target) {
+			continue
+		}
+		s, ok := srv[strings.ToLower(r.target)]
+		if !ok || s.port == 0 {
+			continue
+		}
+		ip := hosts[strings.ToLower(s.target)]
+		if ip == nil {
+			ip = from
+		}
+		urls = append(urls, fmt.Sprintf("http://%s", net.JoinHostPort(ip.String(), fmt.Sprint(s.port))))
+	}
+	return urls
+}
+
+// isOpenCodeInstance reports whether a PTR answer names an OpenCode server:
+// any _opencode._tcp instance, or _http._tcp instances called opencode-*.
+func isOpenCodeInstance(service, instance string) bool {
+	switch strings.ToLower(service) {
+	case "_opencode._tcp.local.":
+		return true
+	case "_http._tcp.local.":
+		return strings.HasPrefix(strings.ToLower(instance), "opencode")
+	}
+	return false
+}
+
+// mdnsQuery builds a PTR query for services, asking for unicast responses.
+func mdnsQuery(services []string) []byte {
+	msg := make([]byte, 12, 512)
+	binary.BigEndian.PutUint16(msg[4:], uint16(len(services))) // QDCOUNT
+	for _, service := range services {
+		for _, label := range strings.Split(strings.TrimSuffix(service, "."), ".") {
+			msg = append(msg, byte(len(label)))
+			msg = append(msg, label...)
+		}
+		msg = append(msg, 0)
+		// QTYPE PTR, QCLASS IN with the unicast-response bit
+		msg = binary.BigEndian.AppendUint16(msg, dnsTypePTR)
+		msg = binary.BigEndian.AppendUint16(msg, 0x8001)
+	}
+	return msg
+}
+
+// parseDNSMessage returns the answer, authority and additional records of
+// a DNS message.
+func parseDNSMessage(msg []byte) ([]dnsRecord, error) {
+	if len(msg) < 12 {
+		return nil, errors.New("short DNS message")
+	}
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	count := int(binary.BigEndian.Uint16(msg[6:])) + int(binary.BigEndian.Uint16(msg[8:])) + int(binary.BigEndian.Uint16(msg[10:]))
+
+	off := 12
+	for range questions {
+		_, next, err := readDNSName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next + 4 // QTYPE, QCLASS
+	}
+
+	var records []dnsRecord
+	for range count {
+		name, next, err := readDNSName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		if next+10 > len(msg) {
+			return nil, errors.New("truncated record")
+		}
+		r := dnsRecord{name: name, typ: binary.BigEndian.Uint16(msg[next:])}
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		data := next + 10
+		if data+length > len(msg) {
+			return nil, errors.New("truncated record data")
+		}
+
+		switch r.typ {
+		case dnsTypePTR:
+			r.target, _, err = readDNSName(msg, data)
+		case dnsTypeSRV:
+			if length < 7 {
+				return nil, errors.New("short SRV record")
+			}
+			r.port = int(binary.BigEndian.Uint16(msg[data+4:]))
+			r.target, _, err = readDNSName(msg, data+6)
+		case dnsTypeA:
+			if length == 4 {
+				r.ip = net.IP(append([]byte(nil), msg[data:data+4]...))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+		off = data + length
+	}
+	return records, nil
+}
+
+// readDNSName reads a possibly compressed name at off. It returns the name
+// (dot-terminated) and the offset after it in the original position.
+func readDNSName(msg []byte, off int) (string, int, error) {
+	var b strings.Builder
+	next := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errors.New("name out of bounds")
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			if b.Len() == 0 {
+				b.WriteByte('.')
+			}
+			return b.String(), next, nil
+		case n&0xC0 == 0xC0:
+			if off+1 >= len(msg) {
+				return "", 0, errors.New("name pointer out of bounds")
+			}
+			if jumps++; jumps > 10 {
+				return "", 0, errors.New("name pointer loop")
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3FFF)
+		default:
+			if off+1+n > len(msg) {
+				return "", 0, errors.New("label out of bounds")
+			}
+			b.Write(msg[off+1 : off+1+n])
+			b.WriteByte('.')
+			off += 1 + n
+		}
+	}
+}
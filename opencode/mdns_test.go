@@ -0,0 +1,83 @@
+package opencode
+
+import (
+	"encoding/binary"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// dnsName encodes a name without compression.
+func dnsName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+func appendRecord(msg []byte, name []byte, typ uint16, data []byte) []byte {
+	msg = append(msg, name...)
+	msg = binary.BigEndian.AppendUint16(msg, typ)
+	msg = binary.BigEndian.AppendUint16(msg, 1)
+	msg = binary.BigEndian.AppendUint32(msg, 120)
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(data)))
+	return append(msg, data...)
+}
+
+func TestParseMDNSResponse(t *testing.T) {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[6:], 3)  // Answers
+	binary.BigEndian.PutUint16(msg[10:], 2) // Additionals
+
+	// PTR _http._tcp.local. -> opencode-4200._http._tcp.local., the target
+	// compressed against the question name
+	service := len(msg)
+	instance := append([]byte{13}, "opencode-4200"...)
+	instance = binary.BigEndian.AppendUint16(instance, 0xC000|uint16(service))
+	msg = appendRecord(msg, dnsName("_http._tcp.local."), dnsTypePTR, instance)
+	// An unrelated web server on the same service type
+	msg = appendRecord(msg, dnsName("_http._tcp.local."), dnsTypePTR, dnsName("printer._http._tcp.local."))
+	msg = appendRecord(msg, dnsName("printer._http._tcp.local."), dnsTypeSRV,
+		append([]byte{0, 0, 0, 0, 0, 80}, dnsName("printer.local.")...))
+
+	srv := append([]byte{0, 0, 0, 0, 0x10, 0x68}, dnsName("devbox.local.")...) // Port 4200
+	msg = appendRecord(msg, dnsName("opencode-4200._http._tcp.local."), dnsTypeSRV, srv)
+	msg = appendRecord(msg, dnsName("devbox.local."), dnsTypeA, []byte{192, 168, 1, 20})
+
+	records, err := parseDNSMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0].target != "opencode-4200._http._tcp.local." {
+		t.Errorf("compressed PTR target = %q", records[0].target)
+	}
+
+	got := mdnsServerURLs(records, net.IPv4(192, 168, 1, 99))
+	if want := []string{"http://192.168.1.20:4200"}; !slices.Equal(got, want) {
+		t.Errorf("mdnsServerURLs() = %v, want %v", got, want)
+	}
+}
+
+func TestMDNSQuery(t *testing.T) {
+	q := mdnsQuery([]string{"_opencode._tcp.local."})
+	if n := binary.BigEndian.Uint16(q[4:]); n != 1 {
+		t.Fatalf("QDCOUNT = %d, want 1", n)
+	}
+	name, off, err := readDNSName(q, 12)
+	if err != nil || name != "_opencode._tcp.local." {
+		t.Fatalf("question name = %q, %v", name, err)
+	}
+	if typ, class := binary.BigEndian.Uint16(q[off:]), binary.BigEndian.Uint16(q[off+2:]); typ != dnsTypePTR || class != 0x8001 {
+		t.Errorf("question type/class = %d/%#x, want PTR with unicast response", typ, class)
+	}
+}
+
+func TestReadDNSNameLoop(t *testing.T) {
+	msg := []byte{0xC0, 0x00}
+	if _, _, err := readDNSName(msg, 0); err == nil {
+		t.Error("pointer loop: want error")
+	}
+}
@@ -0,0 +1,45 @@
+package opencode
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processUnder reports whether pid is ancestor or one of its descendants,
+// following parent PIDs through /proc. Without /proc (macOS) it only
+// matches pid itself.
+func processUnder(pid, ancestor int) bool {
+	for depth := 0; pid > 1 && depth < 32; depth++ {
+		if pid == ancestor {
+			return true
+		}
+		ppid, ok := parentPID(pid)
+		if !ok {
+			return false
+		}
+		pid = ppid
+	}
+	return false
+}
+
+// parentPID reads a process's parent from /proc/<pid>/stat. The command
+// name in parentheses may contain spaces, so fields are counted after it.
+func parentPID(pid int) (int, bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, false
+	}
+	stat := string(data)
+	i := strings.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0, false
+	}
+	fields := strings.Fields(stat[i+1:]) // state, ppid, ...
+	if len(fields) < 2 {
+		return 0, false
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	return ppid, err == nil
+}
@@ -0,0 +1,199 @@
+package opencode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/internal/textutil"
+)
+
+// GetTranscript fetches up to limit recent messages of a session as a transcript.
+func (m *Manager) GetTranscript(ctx context.Context, serverURL, sessionID string, limit int) (*agents.Transcript, error) {
+	client := NewClient(serverURL)
+
+	session, err := client.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := client.GetMessages(ctx, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var project *Project
+	if srv := m.discovery.GetServer(serverURL); srv != nil {
+		project = srv.Project
+	}
+	return BuildTranscript(*session, project, messages), nil
+}
+
+// BuildTranscript converts OpenCode messages into a transcript.
+func BuildTranscript(session Session, project *Project, messages []MessageWithParts) *agents.Transcript {
+	t := &agents.Transcript{
+		Agent:     agents.AgentOpenCode,
+		SessionID: session.ID,
+		Title:     session.Title,
+		Entries:   []agents.TranscriptEntry{},
+	}
+	if project != nil {
+		t.CWD = project.Path
+	}
+
+	for _, msg := range messages {
+		role := agents.RoleAssistant
+		if msg.Info.Role == "user" {
+			role = agents.RoleUser
+		}
+		for _, part := range msg.Parts {
+			switch part.Type {
+			case "text":
+				if strings.TrimSpace(part.Text) == "" {
+					continue
+				}
+				t.Entries = append(t.Entries, agents.TranscriptEntry{
+					Role:      role,
+					Text:      part.Text,
+					Timestamp: msg.Info.CreatedAt,
+				})
+			case "tool-invocation", "tool":
+				input, _ := part.Args.(map[string]any)
+				summary := agents.SummarizeToolInput(input)
+				if summary == "" && part.Args != nil && input == nil {
+					summary = fmt.Sprint(part.Args)
+				}
+				t.Entries = append(t.Entries, agents.TranscriptEntry{
+					Role:      agents.RoleTool,
+					Tool:      part.ToolName,
+					ToolInput: summary,
+					Timestamp: msg.Info.CreatedAt,
+				})
+			}
+		}
+	}
+
+	return t
+}
+
+// maxResultLen bounds the tool results included in rendered messages.
+const maxResultLen = 2000
+
+// RenderedMessage is a message prepared for display: its text and tool
+// calls in order, with each tool's result attached to its call.
+type RenderedMessage struct {
+	ID        string         `json:"id"`
+	Role      string         `json:"role"` // "user" or "assistant"
+	CreatedAt time.Time      `json:"created_at"`
+	Blocks    []MessageBlock `json:"blocks"`
+}
+
+// MessageBlock is one displayable part of a message.
+type MessageBlock struct {
+	Type   string `json:"type"` // "text" or "tool"
+	Text   string `json:"text,omitempty"`
+	Tool   string `json:"tool,omitempty"`
+	Input  string `json:"input,omitempty"`  // Short summary of the tool input
+	State  string `json:"state,omitempty"`  // "pending", "running", "complete", "error"
+	Result string `json:"result,omitempty"` // Tool output, truncated
+}
+
+// GetMessages fetches up to limit recent messages of a session, rendered
+// for display.
+func (m *Manager) GetMessages(ctx context.Context, serverURL, sessionID string, limit int) ([]RenderedMessage, error) {
+	messages, err := NewClient(serverURL).GetMessages(ctx, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return RenderMessages(messages), nil
+}
+
+// RenderMessages converts OpenCode messages into display blocks. Results
+// sent as separate tool-result parts are attached to the matching call.
+func RenderMessages(messages []MessageWithParts) []RenderedMessage {
+	type blockRef struct{ msg, block int }
+	rendered := make([]RenderedMessage, 0, len(messages))
+	calls := make(map[string]blockRef) // Tool ID -> call block, for results
+
+	for _, msg := range messages {
+		rm := RenderedMessage{
+			ID:        msg.Info.ID,
+			Role:      msg.Info.Role,
+			CreatedAt: msg.Info.CreatedAt,
+			Blocks:    []MessageBlock{},
+		}
+		for _, part := range msg.Parts {
+			switch part.Type {
+			case "text":
+				if strings.TrimSpace(part.Text) != "" {
+					rm.Blocks = append(rm.Blocks, MessageBlock{Type: "text", Text: part.Text})
+				}
+			case "tool-invocation", "tool":
+				if part.ToolID != "" {
+					calls[part.ToolID] = blockRef{len(rendered), len(rm.Blocks)}
+				}
+				rm.Blocks = append(rm.Blocks, MessageBlock{
+					Type:   "tool",
+					Tool:   part.ToolName,
+					Input:  toolInputSummary(part.Args),
+					State:  part.State,
+					Result: renderResult(part.Result),
+				})
+			case "tool-result":
+				ref, ok := calls[part.ToolID]
+				if !ok || part.ToolID == "" {
+					rm.Blocks = append(rm.Blocks, MessageBlock{Type: "tool", Tool: part.ToolName, Result: renderResult(part.Result)})
+					continue
+				}
+				// The call is in an earlier message or this one
+				var call *MessageBlock
+				if ref.msg < len(rendered) {
+					call = &rendered[ref.msg].Blocks[ref.block]
+				} else {
+					call = &rm.Blocks[ref.block]
+				}
+				call.Result = renderResult(part.Result)
+				if call.State == "" || call.State == "pending" || call.State == "running" {
+					call.State = "complete"
+				}
+			}
+		}
+		rendered = append(rendered, rm)
+	}
+	return rendered
+}
+
+// toolInputSummary summarizes tool arguments like transcripts do.
+func toolInputSummary(args any) string {
+	input, _ := args.(map[string]any)
+	summary := agents.SummarizeToolInput(input)
+	if summary == "" && args != nil && input == nil {
+		summary = fmt.Sprint(args)
+	}
+	return summary
+}
+
+// renderResult formats a tool result as text: strings as they are, anything
+// else as JSON.
+func renderResult(result any) string {
+	var text string
+	switch v := result.(type) {
+	case nil:
+		return ""
+	case string:
+		text = v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		text = string(data)
+	}
+	text = strings.TrimSpace(text)
+	if len(text) > maxResultLen {
+		text = textutil.Truncate(text, maxResultLen) + " …"
+	}
+	return text
+}
@@ -0,0 +1,80 @@
+package opencode
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRenderMessages(t *testing.T) {
+	messages := []MessageWithParts{
+		{
+			Info: Message{ID: "msg_1", Role: "user"},
+			Parts: []Part{
+				{Type: "text", Text: "Run the tests"},
+			},
+		},
+		{
+			Info: Message{ID: "msg_2", Role: "assistant"},
+			Parts: []Part{
+				{Type: "text", Text: "  "},
+				{Type: "tool-invocation", ToolName: "bash", ToolID: "call_1", State: "running", Args: map[string]any{"command": "go test ./..."}},
+				{Type: "tool-invocation", ToolName: "read", ToolID: "call_2", Args: map[string]any{"path": "go.mod"}, Result: "module x"},
+			},
+		},
+		{
+			Info: Message{ID: "msg_3", Role: "assistant"},
+			Parts: []Part{
+				{Type: "tool-result", ToolID: "call_1", Result: map[string]any{"exit": 0}},
+				{Type: "text", Text: "All tests pass."},
+			},
+		},
+	}
+
+	got := RenderMessages(messages)
+	if len(got) != 3 {
+		t.Fatalf("RenderMessages() returned %d messages, want 3", len(got))
+	}
+	if b := got[0].Blocks; len(b) != 1 || b[0].Text != "Run the tests" {
+		t.Errorf("user blocks = %+v", b)
+	}
+
+	tools := got[1].Blocks
+	if len(tools) != 2 {
+		t.Fatalf("assistant blocks = %+v, want the two tool calls", tools)
+	}
+	want := MessageBlock{Type: "tool", Tool: "bash", Input: "go test ./...", State: "complete", Result: `{"exit":0}`}
+	if tools[0] != want {
+		t.Errorf("bash call = %+v, want %+v (result from the later message)", tools[0], want)
+	}
+	if tools[1].Result != "module x" || tools[1].Input != "go.mod" {
+		t.Errorf("read call = %+v", tools[1])
+	}
+
+	if b := got[2].Blocks; len(b) != 1 || b[0].Text != "All tests pass." {
+		t.Errorf("attached result should not get its own block: %+v", b)
+	}
+}
+
+func TestRenderResult(t *testing.T) {
+	tests := []struct {
+		result any
+		want   string
+	}{
+		{nil, ""},
+		{"  done\n", "done"},
+		{map[string]any{"exit": 1}, `{"exit":1}`},
+		{strings.Repeat("a", maxResultLen+5), strings.Repeat("a", maxResultLen) + " …"},
+		{"x" + strings.Repeat("é", maxResultLen), "x" + strings.Repeat("é", maxResultLen/2-1) + " …"},
+	}
+
+	for _, tt := range tests {
+		got := renderResult(tt.result)
+		if got != tt.want {
+			t.Errorf("renderResult(%.20v) = %.40q, want %.40q", tt.result, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("renderResult(%.20v) is not valid UTF-8", tt.result)
+		}
+	}
+}
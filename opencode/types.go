@@ -3,7 +3,12 @@
 // See: https://opencode.ai/docs/server
 package opencode
 
-import "time"
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"time"
+)
 
 // Session represents an OpenCode session.
 type Session struct {
@@ -79,6 +84,44 @@ type Provider struct {
 	Models []Model `json:"models,omitempty"`
 }
 
+// UnmarshalJSON accepts models as a list or, as OpenCode sends them, as an
+// object keyed by model ID.
+func (p *Provider) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		ID     string          `json:"id"`
+		Name   string          `json:"name"`
+		Models json.RawMessage `json:"models"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.ID, p.Name, p.Models = raw.ID, raw.Name, nil
+	if len(raw.Models) == 0 || string(raw.Models) == "null" {
+		return nil
+	}
+	if raw.Models[0] == '[' {
+		return json.Unmarshal(raw.Models, &p.Models)
+	}
+	var byID map[string]Model
+	if err := json.Unmarshal(raw.Models, &byID); err != nil {
+		return err
+	}
+	for id, m := range byID {
+		m.ID = cmp.Or(m.ID, id)
+		m.Provider = cmp.Or(m.Provider, p.ID)
+		p.Models = append(p.Models, m)
+	}
+	slices.SortFunc(p.Models, func(a, b Model) int { return cmp.Compare(a.ID, b.ID) })
+	return nil
+}
+
+// ProvidersResponse lists the configured providers and the default model
+// of each (provider ID -> model ID).
+type ProvidersResponse struct {
+	Providers []Provider        `json:"providers"`
+	Default   map[string]string `json:"default"`
+}
+
 // Model represents an LLM model.
 type Model struct {
 	ID       string `json:"id"`
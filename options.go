@@ -0,0 +1,308 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/config"
+	"github.com/noamsto/houston/internal/logging"
+	"github.com/noamsto/houston/internal/tracing"
+	"github.com/noamsto/houston/notify"
+	"github.com/noamsto/houston/schedule"
+	"github.com/noamsto/houston/server"
+	"github.com/noamsto/houston/terminal"
+	"github.com/noamsto/houston/update"
+)
+
+// options are houston's settings: command-line flags, filled in from
+// HOUSTON_* environment variables and the config file when not given.
+type options struct {
+	configPath    string
+	addr          string
+	statusDir     string
+	dataDir       string
+	uploadDir     string
+	uploadTTL     time.Duration
+	fileUploadMax int
+	fileUploadExt config.List
+	rateLimit     float64
+	tmuxLimit     int
+	resurrectFile string
+	debug         bool
+	logLevel      string
+	logFormat     string
+	otlpEndpoint  string
+	reusePort     bool
+	listenFD      int
+	pidFile       string
+	basePath      string
+	remotes       config.List
+
+	// Working/Active grace periods
+	activityWindow time.Duration
+	activeTTL      time.Duration
+	sessionTimers  config.List
+
+	// Window preview sizes
+	previewLines          int
+	attentionPreviewLines int
+
+	// Poll intervals
+	pollSessions time.Duration
+	pollPane     time.Duration
+
+	agentsDisabled config.List
+	mcpRequired    config.List
+	autoCompact    int
+	compactCommand string
+	terminal       string
+	terminalThemes config.List
+
+	openCodeURL    string
+	noOpenCode     bool
+	openCodeMDNS   bool
+	openCodeBeacon string
+
+	updateCheck bool
+	channel     string
+
+	githubPRs bool
+
+	notifyCmd     string
+	notifyWebhook string
+	slackToken    string
+	slackChannel  string
+	slackSecret   string
+	remind        string
+	notifyRules   config.List
+	dailyReport   string
+
+	// Parsed by load
+	timerRules    []server.TimerRule
+	reminders     []time.Duration
+	rules         []notify.Rule
+	reportAt      *schedule.Spec
+	updateChannel update.Channel
+	disabled      []agents.AgentType
+	themes        map[string]string
+	logLevels     logging.Levels
+}
+
+// newOptions defines houston's flags on fs.
+func newOptions(fs *flag.FlagSet) *options {
+	o := &options{}
+	fs.StringVar(&o.configPath, "config", "", "Config file (default ~/.config/houston/config.yaml, or $HOUSTON_CONFIG)")
+	fs.StringVar(&o.addr, "addr", "127.0.0.1:9090", "HTTP listen address, or unix:PATH for a Unix domain socket")
+	fs.StringVar(&o.statusDir, "status-dir", "", "Directory for hook status files")
+	fs.StringVar(&o.dataDir, "data-dir", "", "Directory for persistent state (default ~/.local/share/houston)")
+	fs.StringVar(&o.uploadDir, "upload-dir", "", "Directory for images sent to agents (default <data-dir>/uploads)")
+	fs.DurationVar(&o.uploadTTL, "upload-ttl", 24*time.Hour, "How long images sent to agents are kept")
+	fs.IntVar(&o.fileUploadMax, "file-upload-max", server.DefaultFileUploadMax>>20, "MiB of files one upload into a pane's directory may carry (0: uploads off)")
+	fs.Var(&o.fileUploadExt, "file-upload-ext", "Extension files uploaded into a pane's directory may have, e.g. .csv; repeatable (default: any)")
+	fs.Float64Var(&o.rateLimit, "rate-limit", server.DefaultRateLimit, "Changes (POST, PUT, DELETE) per second one client may make through the API (0: unlimited)")
+	fs.IntVar(&o.tmuxLimit, "tmux-concurrency", server.DefaultTmuxConcurrency, "API requests running tmux across panes (sessions, search, captures) served at once (0: unlimited)")
+	fs.StringVar(&o.resurrectFile, "resurrect-file", "", "tmux-resurrect save file (default: ~/.tmux/resurrect/last or ~/.local/share/tmux/resurrect/last)")
+	fs.BoolVar(&o.debug, "debug", false, "Enable debug logging")
+	fs.StringVar(&o.logLevel, "log-level", "info", "Log level, optionally per module (package), e.g. 'info,server=debug,tmux=warn'; -debug makes debug the default")
+	fs.StringVar(&o.logFormat, "log-format", "text", "Log output format: "+strings.Join(logging.Formats, " or "))
+	fs.StringVar(&o.otlpEndpoint, "otlp-endpoint", "", "OTLP/HTTP collector to send trace spans to, e.g. http://localhost:4318 (default: tracing off)")
+	fs.BoolVar(&o.reusePort, "reuse-port", false, "Share -addr with a running houston (SO_REUSEPORT) and take over when it stops")
+	fs.IntVar(&o.listenFD, "listen-fd", 0, "Serve on this inherited listening socket instead of -addr (systemd's LISTEN_FDS is detected without it)")
+	fs.StringVar(&o.pidFile, "pid-file", "", "Write the server's PID to this file")
+	fs.StringVar(&o.basePath, "base-path", "", "URL path prefix a reverse proxy serves houston under, e.g. /houston")
+	fs.Var(&o.remotes, "remote", "ssh destination (user@host) whose tmux sessions to include; repeatable")
+
+	// Working/Active grace periods
+	fs.DurationVar(&o.activityWindow, "activity-window", 30*time.Second, "Recent output that keeps a non-agent process counted as working")
+	fs.DurationVar(&o.activeTTL, "active-ttl", 2*time.Minute, "How long a session stays Active after its work stops")
+	fs.Var(&o.sessionTimers, "session-timers", "Grace periods and preview sizes for matching sessions, e.g. 'build-*=activity:5m,ttl:30m', 'prod-*=ttl:never,preview:40'; repeatable, first match wins")
+
+	// Window preview sizes
+	fs.IntVar(&o.previewLines, "preview-lines", 15, "Output lines in a window's preview")
+	fs.IntVar(&o.attentionPreviewLines, "attention-preview-lines", 25, "Output lines in the preview of a window that needs attention")
+
+	// Poll intervals
+	fs.DurationVar(&o.pollSessions, "poll-sessions", 3*time.Second, "How often the dashboard stream rescans sessions")
+	fs.DurationVar(&o.pollPane, "poll-pane", 200*time.Millisecond, "How often an open pane is captured")
+
+	fs.Var(&o.agentsDisabled, "agents-disabled", "Agent type not to detect (claude-code, amp); repeatable")
+	fs.Var(&o.mcpRequired, "mcp-required", "MCP server (glob, '*' for all) whose disconnect needs attention in Claude panes; repeatable")
+	fs.IntVar(&o.autoCompact, "auto-compact", 0, "Send -auto-compact-command to idle Claude panes whose context usage reaches this percent (0: off)")
+	fs.StringVar(&o.compactCommand, "auto-compact-command", server.DefaultCompactCommand, "Command -auto-compact sends")
+	fs.StringVar(&o.terminal, "terminal", "", "Terminal whose font houston controls: "+strings.Join(terminal.Controllers, ", ")+" (default: detect)")
+	fs.Var(&o.terminalThemes, "terminal-theme", "Theme alias for /api/terminal/theme, e.g. 'light=Solarized Light'; repeatable")
+
+	// OpenCode integration flags
+	fs.StringVar(&o.openCodeURL, "opencode-url", "", "OpenCode server URL (skip discovery)")
+	fs.BoolVar(&o.noOpenCode, "no-opencode", false, "Disable OpenCode integration")
+	fs.BoolVar(&o.openCodeMDNS, "opencode-mdns", true, "Discover OpenCode servers advertised over mDNS")
+	fs.StringVar(&o.openCodeBeacon, "opencode-beacon", "", "UDP address to receive OpenCode beacons on, e.g. :4095 (default: off)")
+
+	// Release check flags
+	fs.BoolVar(&o.updateCheck, "update-check", false, "Check GitHub daily for a newer release (shown in the dashboard)")
+	fs.StringVar(&o.channel, "channel", "stable", "Release channel for -update-check: stable or prerelease")
+
+	// GitHub flags
+	fs.BoolVar(&o.githubPRs, "github-prs", false, "Show the open pull request of each window's branch, with CI and review status (needs gh)")
+
+	// Attention notification flags
+	fs.StringVar(&o.notifyCmd, "notify-cmd", "", `Shell command run per attention notification (e.g. notify-send "$HOUSTON_TITLE" "$HOUSTON_BODY")`)
+	fs.StringVar(&o.notifyWebhook, "notify-webhook", "", "URL that attention notifications are POSTed to as JSON")
+	fs.StringVar(&o.slackToken, "slack-token", "", "Slack bot token (xoxb-...) to post attention notifications with")
+	fs.StringVar(&o.slackChannel, "slack-channel", "", "Slack channel ID or name for -slack-token")
+	fs.StringVar(&o.slackSecret, "slack-signing-secret", "", "Slack app signing secret; adds choice buttons answered at /api/slack/interactions")
+	fs.Var(&o.notifyRules, "notify-rule", "Route matching notifications, e.g. 'session:prod-*,hours:22:00-07:00 => escalate:slack', 'agent:amp => suppress'; repeatable, first match wins")
+	fs.StringVar(&o.dailyReport, "daily-report", "", `Send a report of the last 24 hours through the notification providers on this cron schedule, e.g. "0 18 * * mon-fri"`)
+	fs.StringVar(&o.remind, "remind", "5m,15m,1h", `Re-notify unanswered prompts after these waiting times ("off" to disable)`)
+	return o
+}
+
+// load fills flags not given on the command line from the environment and
+// the config file, then checks the values. It returns the config file
+// path and whether it exists.
+func (o *options) load(fs *flag.FlagSet) (path string, found bool, err error) {
+	path, required := o.configPath, true
+	if path == "" {
+		path = os.Getenv("HOUSTON_CONFIG")
+	}
+	if path == "" {
+		path, required = config.DefaultPath(), false
+	}
+	values, err := config.Load(path, required)
+	if err != nil {
+		return path, false, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		found = true
+	}
+	if err := config.Apply(fs, values, os.LookupEnv); err != nil {
+		return path, found, err
+	}
+	return path, found, o.check()
+}
+
+// check parses the settings that have their own syntax.
+func (o *options) check() error {
+	var err error
+	if o.updateChannel, err = update.ParseChannel(o.channel); err != nil {
+		return err
+	}
+	if o.reminders, err = notify.ParseReminders(o.remind); err != nil {
+		return err
+	}
+	for _, v := range o.notifyRules {
+		rule, err := notify.ParseRule(v)
+		if err != nil {
+			return err
+		}
+		o.rules = append(o.rules, rule)
+	}
+	if o.dailyReport != "" {
+		spec, err := schedule.Parse(o.dailyReport)
+		if err != nil {
+			return fmt.Errorf("daily-report: %w", err)
+		}
+		if o.notifyCmd == "" && o.notifyWebhook == "" && o.slackToken == "" {
+			return fmt.Errorf("-daily-report needs a notification provider (-notify-cmd, -notify-webhook or -slack-token)")
+		}
+		o.reportAt = &spec
+	}
+	if o.logLevels, err = logging.ParseLevels(o.logLevel); err != nil {
+		return err
+	}
+	if o.debug {
+		o.logLevels.Default = slog.LevelDebug
+	}
+	if !logging.ValidFormat(o.logFormat) {
+		return fmt.Errorf("-log-format must be %s", strings.Join(logging.Formats, " or "))
+	}
+	if o.otlpEndpoint != "" {
+		if _, err := tracing.ParseEndpoint(o.otlpEndpoint); err != nil {
+			return fmt.Errorf("-otlp-endpoint: %w", err)
+		}
+	}
+	if o.fileUploadMax < 0 {
+		return fmt.Errorf("-file-upload-max can't be negative")
+	}
+	if o.rateLimit < 0 {
+		return fmt.Errorf("-rate-limit can't be negative")
+	}
+	if o.tmuxLimit < 0 {
+		return fmt.Errorf("-tmux-concurrency can't be negative")
+	}
+	if o.uploadTTL <= 0 {
+		return fmt.Errorf("-upload-ttl must be positive")
+	}
+	if o.githubPRs {
+		if _, err := exec.LookPath("gh"); err != nil {
+			return fmt.Errorf("-github-prs needs the gh CLI: %w", err)
+		}
+	}
+	if (o.slackToken == "") != (o.slackChannel == "") {
+		return fmt.Errorf("-slack-token and -slack-channel go together")
+	}
+	if o.slackSecret != "" && o.slackToken == "" {
+		return fmt.Errorf("-slack-signing-secret needs -slack-token")
+	}
+	for _, v := range o.sessionTimers {
+		rule, err := server.ParseTimerRule(v)
+		if err != nil {
+			return err
+		}
+		o.timerRules = append(o.timerRules, rule)
+	}
+	for _, v := range o.agentsDisabled {
+		switch t := agents.AgentType(v); t {
+		case agents.AgentClaudeCode, agents.AgentAmp:
+			o.disabled = append(o.disabled, t)
+		default:
+			return fmt.Errorf("agents-disabled: unknown agent %q (want claude-code or amp)", v)
+		}
+	}
+	if o.terminal != "" && !slices.Contains(terminal.Controllers, o.terminal) {
+		return fmt.Errorf("terminal: unknown terminal %q (want one of %s)", o.terminal, strings.Join(terminal.Controllers, ", "))
+	}
+	for _, v := range o.terminalThemes {
+		alias, name, ok := strings.Cut(v, "=")
+		if !ok || alias == "" || name == "" {
+			return fmt.Errorf("terminal-theme: %q is not alias=theme", v)
+		}
+		if o.themes == nil {
+			o.themes = make(map[string]string)
+		}
+		o.themes[alias] = name
+	}
+	for _, v := range o.mcpRequired {
+		if _, err := path.Match(v, ""); err != nil {
+			return fmt.Errorf("mcp-required: bad pattern %q: %w", v, err)
+		}
+	}
+	if o.previewLines <= 0 || o.attentionPreviewLines <= 0 {
+		return fmt.Errorf("preview-lines and attention-preview-lines must be positive")
+	}
+	if o.autoCompact < 0 || o.autoCompact > 100 {
+		return fmt.Errorf("auto-compact must be a percent from 0 to 100, got %d", o.autoCompact)
+	}
+	if o.autoCompact > 0 && strings.TrimSpace(o.compactCommand) == "" {
+		return fmt.Errorf("auto-compact-command must not be empty")
+	}
+	for name, d := range map[string]time.Duration{
+		"activity-window": o.activityWindow,
+		"active-ttl":      o.activeTTL,
+		"poll-sessions":   o.pollSessions,
+		"poll-pane":       o.pollPane,
+	} {
+		if d <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", name, d)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/noamsto/houston/internal/textutil"
+)
+
+const (
+	// failureWindow is how many of the last lines are searched for a failure.
+	failureWindow = 80
+	// failureRecent is how many non-empty lines may follow the start of the
+	// last failure (its details, a summary, the prompt) before it is
+	// considered stale.
+	failureRecent = 15
+	// maxSnippetLine caps each line of a failure snippet.
+	maxSnippetLine = 200
+)
+
+// failureKind recognizes the first line of a failure and how many of the
+// lines after it belong in its snippet.
+type failureKind struct {
+	start  *regexp.Regexp
+	detail func(lines []string) []string // Lines after the start worth showing
+	part   *regexp.Regexp                // Lines after the start that belong to it (nil: none)
+}
+
+var failureKinds = []failureKind{
+	// Go panic: the panic message and the frame it happened in. The stack
+	// trace after it is part of the failure, however long.
+	{regexp.MustCompile(`^panic: `), panicFrame, regexp.MustCompile(`^(goroutine \d+ |\s|\S+\(.*\)$|created by |\[signal |exit status )`)},
+	// Go test: "--- FAIL: TestX" and its indented log lines
+	{regexp.MustCompile(`^\s*--- FAIL: `), indented(3), regexp.MustCompile(`^\s`)},
+	// Go compiler or vet: "pkg/file.go:12:5: undefined: x"
+	{regexp.MustCompile(`^\S+\.go:\d+(:\d+)?: `), nil, nil},
+	// tsc: "src/a.ts(3,5): error TS2322: ..." or "src/a.ts:3:5 - error TS2322: ..."
+	{regexp.MustCompile(`^\S+\.[cm]?[jt]sx?(\(\d+,\d+\):|:\d+:\d+ -) error TS\d+: `), nil, nil},
+	// rustc: "error[E0425]: ..." and the "--> file:line" after it
+	{regexp.MustCompile(`^error(\[E\d+\])?: `), arrowLine, nil},
+	// pytest: "FAILED tests/test_a.py::test_x - AssertionError: ..."
+	{regexp.MustCompile(`^(FAILED|ERROR) \S+\.py::`), nil, nil},
+}
+
+var (
+	goroutinePattern = regexp.MustCompile(`^goroutine \d+ \[`)
+	// rustc summaries ("error: could not compile", "aborting due to") are
+	// not failures of their own
+	rustSummaryPattern = regexp.MustCompile(`^error: (could not compile|aborting due to)`)
+)
+
+// ParseFailure looks for a compiler or test failure at the end of output,
+// as left by a build or test run in a pane without an agent: a Go panic or
+// failed test, Go, tsc or rustc errors, pytest failures. The last failure
+// found is returned as TypeError with a short snippet (the error and the
+// lines locating it). Failures followed by more than a few lines of other
+// output are stale and ignored.
+func ParseFailure(output string) (Result, bool) {
+	lines := lastN(strings.Split(output, "\n"), failureWindow)
+
+	last, kind := -1, failureKind{}
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if rustSummaryPattern.MatchString(line) {
+			continue
+		}
+		for _, k := range failureKinds {
+			if k.start.MatchString(line) {
+				last, kind = i, k
+				break
+			}
+		}
+	}
+	if last < 0 || trailing(lines[last+1:], kind.part) > failureRecent {
+		return Result{}, false
+	}
+
+	snippet := []string{lines[last]}
+	if kind.detail != nil {
+		snippet = append(snippet, kind.detail(lines[last+1:])...)
+	}
+	for i, line := range snippet {
+		line = strings.TrimSpace(line)
+		if len(line) > maxSnippetLine {
+			line = textutil.Truncate(line, maxSnippetLine) + "…"
+		}
+		snippet[i] = line
+	}
+	return Result{Type: TypeError, ErrorSnippet: strings.Join(snippet, "\n")}, true
+}
+
+// panicFrame returns the function and file:line a panic happened in: the
+// first frame after the goroutine header.
+func panicFrame(lines []string) []string {
+	for i, line := range lines {
+		if goroutinePattern.MatchString(line) && i+2 < len(lines) {
+			return lines[i+1 : i+3]
+		}
+	}
+	return nil
+}
+
+// indented returns up to n indented lines right after the start.
+func indented(n int) func([]string) []string {
+	return func(lines []string) []string {
+		var result []string
+		for _, line := range lines {
+			if len(result) == n || !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				break
+			}
+			if strings.TrimSpace(line) != "" {
+				result = append(result, line)
+			}
+		}
+		return result
+	}
+}
+
+// arrowLine returns the "--> file:line:col" rustc prints under an error.
+func arrowLine(lines []string) []string {
+	for i, line := range lines {
+		if i == 2 {
+			break
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "--> ") {
+			return []string{line}
+		}
+	}
+	return nil
+}
+
+// trailing counts the non-empty lines that don't belong to the failure.
+func trailing(lines []string, part *regexp.Regexp) int {
+	n := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" && (part == nil || !part.MatchString(line)) {
+			n++
+		}
+	}
+	return n
+}
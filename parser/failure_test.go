@@ -0,0 +1,104 @@
+// parser/failure_test.go
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		snippet string // Empty: no failure
+	}{
+		{
+			name: "go panic",
+			output: `$ go run .
+panic: runtime error: index out of range [3] with length 3
+
+goroutine 1 [running]:
+main.pick(...)
+	/src/app/main.go:12
+main.main()
+	/src/app/main.go:7 +0x1d
+exit status 2
+$`,
+			snippet: "panic: runtime error: index out of range [3] with length 3\nmain.pick(...)\n/src/app/main.go:12",
+		},
+		{
+			name: "go test",
+			output: `=== RUN   TestParse
+    parse_test.go:18: got "a", want "b"
+--- FAIL: TestParse (0.00s)
+    parse_test.go:18: got "a", want "b"
+FAIL
+FAIL	github.com/x/parse	0.003s
+FAIL
+$`,
+			snippet: "--- FAIL: TestParse (0.00s)\nparse_test.go:18: got \"a\", want \"b\"",
+		},
+		{
+			name:    "go build",
+			output:  "$ go build ./...\n# github.com/x/app\n./main.go:9:2: undefined: helper\n$",
+			snippet: "./main.go:9:2: undefined: helper",
+		},
+		{
+			name:    "long line",
+			output:  "$ go build ./...\n./main.go:9:2: undefined: x" + strings.Repeat("é", 100) + "\n$",
+			snippet: "./main.go:9:2: undefined: x" + strings.Repeat("é", 86) + "…",
+		},
+		{
+			name:    "tsc",
+			output:  "$ npx tsc\nsrc/app.ts(3,7): error TS2322: Type 'string' is not assignable to type 'number'.\n\nFound 1 error in src/app.ts:3\n\n$",
+			snippet: "src/app.ts(3,7): error TS2322: Type 'string' is not assignable to type 'number'.",
+		},
+		{
+			name:    "tsc pretty",
+			output:  "src/app.ts:3:7 - error TS2322: Type 'string' is not assignable to type 'number'.\n\n3 const n: number = 'x'\n        ~\n\nFound 1 error.\n",
+			snippet: "src/app.ts:3:7 - error TS2322: Type 'string' is not assignable to type 'number'.",
+		},
+		{
+			name: "rustc",
+			output: `error[E0425]: cannot find value ` + "`y`" + ` in this scope
+ --> src/main.rs:3:13
+  |
+3 |     let x = y;
+  |             ^ not found in this scope
+
+error: could not compile ` + "`demo`" + ` (bin "demo") due to 1 previous error
+$`,
+			snippet: "error[E0425]: cannot find value `y` in this scope\n--> src/main.rs:3:13",
+		},
+		{
+			name:    "pytest",
+			output:  "FAILED tests/test_math.py::test_add - assert 3 == 4\n========== 1 failed, 5 passed in 0.21s ==========\n$",
+			snippet: "FAILED tests/test_math.py::test_add - assert 3 == 4",
+		},
+		{
+			name:   "stale",
+			output: "./main.go:9:2: undefined: helper\n" + strings.Repeat("$ ls\nREADME.md\n", 10),
+		},
+		{
+			name:   "clean run",
+			output: "$ go test ./...\nok  \tgithub.com/x/app\t0.01s\n$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ParseFailure(tt.output)
+			if tt.snippet == "" {
+				if ok {
+					t.Errorf("unexpected failure %q", result.ErrorSnippet)
+				}
+				return
+			}
+			if !ok || result.Type != TypeError {
+				t.Fatalf("no failure found, got %+v", result)
+			}
+			if result.ErrorSnippet != tt.snippet {
+				t.Errorf("snippet = %q, want %q", result.ErrorSnippet, tt.snippet)
+			}
+		})
+	}
+}
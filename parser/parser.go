@@ -70,10 +70,11 @@ type Result struct {
 	Type         ResultType `json:"type"`
 	Mode         Mode       `json:"mode"`
 	Question     string     `json:"question,omitempty"`
-	Choices      []string   `json:"choices,omitempty"`
+	Choices      []string   `json:"choices,omitempty"`     // Options of a selector the agent verified; safe to offer as actions
+	RawChoices   []string   `json:"raw_choices,omitempty"` // Numbered lines read from output text; display only, never actions
 	ErrorSnippet string     `json:"error_snippet,omitempty"`
-	Activity     string     `json:"activity,omitempty"`     // What Claude is currently doing (for TypeWorking)
-	Suggestion   string     `json:"suggestion,omitempty"`   // Prompt suggestion from Claude Code subagent
+	Activity     string     `json:"activity,omitempty"`   // What Claude is currently doing (for TypeWorking)
+	Suggestion   string     `json:"suggestion,omitempty"` // Prompt suggestion from Claude Code subagent
 }
 
 var (
@@ -84,7 +85,7 @@ var (
 	// Error patterns - look for actual error messages, not just code containing "error"
 	// Requires colon after error keyword to avoid matching code/comments
 	// Matches: "Error: message" or "error: message" but not "// handle error" or "errorCount"
-	errorPattern = regexp.MustCompile(`(?mi)^(?:error|failed|fatal|panic):\s+(.+)`)
+	errorPattern    = regexp.MustCompile(`(?mi)^(?:error|failed|fatal|panic):\s+(.+)`)
 	approvalPattern = regexp.MustCompile(`(?i)(proceed|continue|look right|does this|should i)\?`)
 
 	// Claude Code working/activity patterns
@@ -156,7 +157,6 @@ func Parse(output string) Result {
 		}
 	}
 
-
 	// Check for approval/confirmation question
 	if approvalPattern.MatchString(text) {
 		if qMatches := questionPattern.FindAllStringSubmatch(text, -1); len(qMatches) > 0 {
@@ -0,0 +1,267 @@
+// Package project reads lightweight metadata about the project a session
+// works in (repository name, README summary, language), so an unfamiliar
+// session can be recognized at a glance.
+package project
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheTTL is how long a path's metadata is reused before it is read again.
+const cacheTTL = 5 * time.Minute
+
+// maxSummary caps the README summary, in runes.
+const maxSummary = 280
+
+// Info describes a project directory.
+type Info struct {
+	Root     string `json:"root"`               // Repository root (or the directory itself outside git)
+	Name     string `json:"name"`               // Repository name, from the origin remote or the root directory
+	Summary  string `json:"summary,omitempty"`  // First paragraph of the README, as plain text
+	Language string `json:"language,omitempty"` // From go.mod, package.json, Cargo.toml, ...
+}
+
+// languageMarkers map files at the project root to its language, in order
+// of precedence (a Go module with a package.json for tooling is Go).
+var languageMarkers = []struct {
+	file     string
+	language string
+}{
+	{"go.mod", "Go"},
+	{"Cargo.toml", "Rust"},
+	{"tsconfig.json", "TypeScript"},
+	{"package.json", "JavaScript"},
+	{"pyproject.toml", "Python"},
+	{"setup.py", "Python"},
+	{"requirements.txt", "Python"},
+	{"Gemfile", "Ruby"},
+	{"pom.xml", "Java"},
+	{"build.gradle", "Java"},
+	{"build.gradle.kts", "Kotlin"},
+	{"mix.exs", "Elixir"},
+	{"Package.swift", "Swift"},
+	{"flake.nix", "Nix"},
+}
+
+var readmeNames = []string{"README.md", "README", "README.rst", "README.txt", "readme.md", "Readme.md"}
+
+// Cache reads project metadata, reusing it per path for a few minutes.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	info *Info
+	read time.Time
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]cacheEntry)}
+}
+
+// Lookup returns the project metadata for a local directory, or nil if it
+// has none (not a repository, no README and no known language).
+func (c *Cache) Lookup(dir string) *Info {
+	if dir == "" {
+		return nil
+	}
+	c.mu.Lock()
+	e, ok := c.entries[dir]
+	c.mu.Unlock()
+	if ok && time.Since(e.read) < cacheTTL {
+		return e.info
+	}
+
+	info := Read(dir)
+	c.mu.Lock()
+	c.entries[dir] = cacheEntry{info: info, read: time.Now()}
+	c.mu.Unlock()
+	return info
+}
+
+// Read reads the project metadata for dir without caching.
+func Read(dir string) *Info {
+	root, gitDir := findRoot(dir)
+	info := &Info{
+		Root:     root,
+		Name:     filepath.Base(root),
+		Summary:  readmeSummary(root),
+		Language: language(root),
+	}
+	if gitDir != "" {
+		if name := originName(gitDir); name != "" {
+			info.Name = name
+		}
+	} else if info.Summary == "" && info.Language == "" {
+		return nil
+	}
+	return info
+}
+
+// findRoot walks up from dir to the directory holding .git and returns it
+// with the git directory; outside a repository it returns dir itself.
+func findRoot(dir string) (root, gitDir string) {
+	for d := dir; ; {
+		dotGit := filepath.Join(d, ".git")
+		if fi, err := os.Stat(dotGit); err == nil {
+			if fi.IsDir() {
+				return d, dotGit
+			}
+			// A worktree's .git file points at its git directory
+			return d, worktreeGitDir(d, dotGit)
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir, ""
+		}
+		d = parent
+	}
+}
+
+// worktreeGitDir resolves a linked worktree's .git file to the main
+// repository's git directory, where its config lives.
+func worktreeGitDir(root, dotGit string) string {
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return ""
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		c := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(c) {
+			c = filepath.Join(gitDir, c)
+		}
+		return filepath.Clean(c)
+	}
+	return gitDir
+}
+
+// originName returns the repository name from the origin remote's URL in
+// the git config ("git@github.com:noamsto/houston.git" -> "houston").
+func originName(gitDir string) string {
+	f, err := os.Open(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inOrigin := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != "url" {
+			continue
+		}
+		u := strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(value), "/"), ".git")
+		if i := strings.LastIndexAny(u, "/:"); i >= 0 {
+			u = u[i+1:]
+		}
+		return u
+	}
+	return ""
+}
+
+func language(root string) string {
+	for _, m := range languageMarkers {
+		if _, err := os.Stat(filepath.Join(root, m.file)); err == nil {
+			return m.language
+		}
+	}
+	return ""
+}
+
+var (
+	// ![alt](url), which includes badges
+	mdImagePattern = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	// [text](url)
+	mdLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// <img ...>, <p align="center">, ...
+	htmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+)
+
+// readmeSummary returns the first prose paragraph of the README at root,
+// skipping headings, badges, HTML and code blocks.
+func readmeSummary(root string) string {
+	var data []byte
+	for _, name := range readmeNames {
+		var err error
+		if data, err = os.ReadFile(filepath.Join(root, name)); err == nil {
+			break
+		}
+	}
+	if data == nil {
+		return ""
+	}
+	return firstParagraph(string(data))
+}
+
+func firstParagraph(text string) string {
+	var para []string
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		if line == "" {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		// An underline makes the lines above it a heading (setext, rst)
+		if strings.Trim(line, "=-~*_") == "" {
+			para = nil
+			continue
+		}
+		// Headings, quotes and tables
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, ">") || strings.HasPrefix(line, "|") {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		line = htmlTagPattern.ReplaceAllString(line, "")
+		line = mdImagePattern.ReplaceAllString(line, "")
+		line = mdLinkPattern.ReplaceAllString(line, "$1")
+		line = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+		line = strings.TrimSpace(line)
+		if line == "" {
+			// A line of only badges or HTML
+			continue
+		}
+		para = append(para, line)
+	}
+
+	summary := strings.Join(para, " ")
+	if r := []rune(summary); len(r) > maxSummary {
+		summary = strings.TrimSpace(string(r[:maxSummary-1])) + "…"
+	}
+	return summary
+}
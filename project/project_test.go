@@ -0,0 +1,91 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFirstParagraph(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "after heading and badges",
+			text: "# houston\n\n[![CI](https://x/badge.svg)](https://x) ![Go](https://y)\n\n" +
+				"Mission control for your **tmux** sessions.\nSee [the docs](https://docs) for `setup`.\n\nMore text.",
+			want: "Mission control for your tmux sessions. See the docs for setup.",
+		},
+		{
+			name: "setext heading",
+			text: "Widget\n======\n\nA small widget.\n",
+			want: "A small widget.",
+		},
+		{
+			name: "html header and code",
+			text: "<p align=\"center\">\n  <img src=\"logo.png\">\n</p>\n\n```sh\nmake\n```\n\nBuilds things.",
+			want: "Builds things.",
+		},
+		{
+			name: "only headings",
+			text: "# Title\n## Section\n",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstParagraph(tt.text); got != tt.want {
+				t.Errorf("firstParagraph() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".git/config", "[core]\n\tbare = false\n[remote \"origin\"]\n\turl = git@github.com:noamsto/houston.git\n")
+	write("go.mod", "module github.com/noamsto/houston\n")
+	write("ui/package.json", "{}")
+	write("README.md", "# houston\n\nMission control.\n")
+
+	info := Read(filepath.Join(root, "ui"))
+	want := Info{Root: root, Name: "houston", Summary: "Mission control.", Language: "Go"}
+	if info == nil || *info != want {
+		t.Errorf("Read() = %+v, want %+v", info, want)
+	}
+
+	if info := Read(t.TempDir()); info != nil {
+		t.Errorf("Read(empty dir) = %+v, want nil", info)
+	}
+}
+
+func TestReadWorktree(t *testing.T) {
+	base := t.TempDir()
+	gitDir := filepath.Join(base, "repo", ".git")
+	wtGit := filepath.Join(gitDir, "worktrees", "feature")
+	if err := os.MkdirAll(wtGit, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(gitDir, "config"), []byte("[remote \"origin\"]\n\turl = https://github.com/acme/widget\n"), 0o644)
+	_ = os.WriteFile(filepath.Join(wtGit, "commondir"), []byte("../..\n"), 0o644)
+
+	wt := filepath.Join(base, "widget-feature")
+	_ = os.MkdirAll(wt, 0o755)
+	_ = os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: "+wtGit+"\n"), 0o644)
+
+	if info := Read(wt); info == nil || info.Name != "widget" || info.Root != wt {
+		t.Errorf("Read(worktree) = %+v, want name widget at %s", info, wt)
+	}
+}
@@ -2,7 +2,7 @@
 # Claude Code hook script for houston
 # Writes structured status to JSON files for dashboard monitoring
 #
-# Install: Add to ~/.claude/settings.json:
+# Install: run `houston install-hooks`, or add to ~/.claude/settings.json:
 # {
 #   "hooks": {
 #     "Notification": [{"hooks": [{"type": "command", "command": "path/to/claude-hook.sh"}]}]
@@ -12,11 +12,14 @@
 # Optional hooks (not required - terminal parsing handles most cases):
 # - Stop: marks session as idle when agent finishes
 # - PreToolUse: shows current tool (noisy, fires constantly)
+#
+# Set HOUSTON_URL (e.g. http://dashboard-host:9090) to post events to
+# houston's /api/hooks/claude endpoint instead of writing status files.
+# Useful when tmux runs on a different machine than houston.
 
 set -euo pipefail
 
 STATUS_DIR="${HOUSTON_STATUS_DIR:-$HOME/.local/state/houston}"
-mkdir -p "$STATUS_DIR"
 
 # Get tmux session name (escape slashes for filename)
 TMUX_SESSION=$(tmux display-message -p '#S' 2>/dev/null || echo "unknown")
@@ -25,6 +28,15 @@ FILENAME=$(echo "$TMUX_SESSION" | tr '/' '%')
 # Read JSON from stdin
 INPUT=$(cat)
 
+if [ -n "${HOUSTON_URL:-}" ]; then
+    echo "$INPUT" | curl -fsS -m 5 -X POST -H 'Content-Type: application/json' \
+        --data-binary @- \
+        "${HOUSTON_URL%/}/api/hooks/claude?session=$(jq -rn --arg s "$TMUX_SESSION" '$s|@uri')" >/dev/null || true
+    exit 0
+fi
+
+mkdir -p "$STATUS_DIR"
+
 # Extract event type
 EVENT=$(echo "$INPUT" | jq -r '.hook_event_name // empty')
 
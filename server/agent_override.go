@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+// agentOverridesDocument is the store document holding pinned agent types.
+const agentOverridesDocument = "agent-overrides"
+
+// AgentOverride is the body of PUT /api/pane/{target}/agent and the
+// response of all methods on that route.
+type AgentOverride struct {
+	Agent  agents.AgentType `json:"agent"`
+	Manual bool             `json:"manual"` // Pinned by the user rather than detected
+}
+
+// loadAgentOverrides restores pinned agent types from the store into the registry.
+func (s *Server) loadAgentOverrides() {
+	overrides := make(map[string]agents.AgentType)
+	if err := s.store.Load(agentOverridesDocument, &overrides); err != nil {
+		slog.Warn("failed to load agent overrides", "error", err)
+	}
+	for paneKey, agentType := range overrides {
+		s.registry.SetOverride(paneKey, agentType)
+	}
+}
+
+// pruneAgentOverrides drops the pinned agent types of panes missing from
+// sessions, a full listing, so a pane that later takes a gone pane's place
+// starts from detection. Hosts with no sessions listed, e.g. unreachable
+// ones, keep theirs.
+func (s *Server) pruneAgentOverrides(sessions []tmux.SessionTree) {
+	hosts := make(map[string]bool)
+	live := make(map[string]bool)
+	for _, sess := range sessions {
+		hosts[sess.Host] = true
+		for _, win := range sess.Windows {
+			for _, p := range win.Panes {
+				live[tmux.Pane{Host: sess.Host, Session: sess.Name, Window: win.Index, Index: p.Index}.Key()] = true
+			}
+		}
+	}
+	removed := s.registry.PruneOverrides(func(paneKey string) bool {
+		host, _, remote := strings.Cut(paneKey, "|")
+		if !remote {
+			host = ""
+		}
+		return hosts[host] && !live[paneKey]
+	})
+	if removed == 0 {
+		return
+	}
+	slog.Info("dropped agent overrides of panes that are gone", "count", removed)
+	if err := s.store.Save(agentOverridesDocument, s.registry.Overrides()); err != nil {
+		slog.Error("failed to save agent overrides", "error", err)
+	}
+}
+
+// handlePaneAgent serves /api/pane/{target}/agent: GET returns the agent for
+// the pane, PUT pins it (overriding detection), DELETE returns to detection.
+func (s *Server) handlePaneAgent(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req AgentOverride
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.Agent == "claude" {
+			req.Agent = agents.AgentClaudeCode
+		}
+		if !s.registry.HasAgent(req.Agent) {
+			http.Error(w, "unknown agent type", http.StatusBadRequest)
+			return
+		}
+		if _, ok := s.lookupPaneInfo(pane); !ok {
+			http.Error(w, "pane not found", http.StatusNotFound)
+			return
+		}
+		s.registry.SetOverride(pane.Key(), req.Agent)
+		slog.InfoContext(r.Context(), "agent override set", "pane", pane.Key(), "agent", req.Agent)
+	case http.MethodDelete:
+		s.registry.ClearOverride(pane.Key())
+		slog.InfoContext(r.Context(), "agent override cleared", "pane", pane.Key())
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		if err := s.store.Save(agentOverridesDocument, s.registry.Overrides()); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save agent overrides", "error", err)
+			http.Error(w, "failed to save agent overrides", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	result := AgentOverride{}
+	if agentType, ok := s.registry.Override(pane.Key()); ok {
+		result = AgentOverride{Agent: agentType, Manual: true}
+	} else {
+		var command string
+		if info, ok := s.lookupPaneInfo(pane); ok {
+			command = info.Command
+		}
+		output, _ := s.client(pane.Host).CapturePane(pane, 100)
+		result.Agent = s.registry.Detect(pane.Key(), command, output).Type()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestHandlePaneAgent(t *testing.T) {
+	privateTmux(t)
+	runTmux(t, "new-session", "-d", "-s", "work", "-n", "w0", "-x", "80", "-y", "24", "cat")
+	runTmux(t, "new-window", "-d", "-t", "work:1", "-n", "w1", "cat")
+	dataDir := t.TempDir()
+	s, err := New(Config{DataDir: dataDir, StatusDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	pinned := tmux.Pane{Session: "work", Window: 1}
+	agent := func(s *Server, method string, pane tmux.Pane, body string) (int, AgentOverride) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.handlePaneAgent(w, httptest.NewRequest(method, "/api/pane/"+pane.URLTarget()+"/agent", strings.NewReader(body)), pane)
+		var got AgentOverride
+		if w.Code == 200 {
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, got
+	}
+
+	if code, got := agent(s, "PUT", pinned, `{"agent":"claude"}`); code != 200 || got != (AgentOverride{Agent: agents.AgentClaudeCode, Manual: true}) {
+		t.Fatalf("PUT = %d, %+v", code, got)
+	}
+	if code, _ := agent(s, "PUT", tmux.Pane{Session: "work", Window: 5}, `{"agent":"claude"}`); code != 404 {
+		t.Errorf("PUT on a missing pane = %d, want 404", code)
+	}
+	if code, _ := agent(s, "PUT", pinned, `{"agent":"vim"}`); code != 400 {
+		t.Errorf("PUT of an unknown agent = %d, want 400", code)
+	}
+	// A pin on a host that isn't listed can't be told gone
+	s.registry.SetOverride(tmux.Pane{Host: "devbox", Session: "work"}.Key(), agents.AgentAmp)
+
+	if _, got := agent(s, "GET", pinned, ""); !got.Manual {
+		t.Fatalf("GET of the pinned pane = %+v, want the pin", got)
+	}
+	var stored map[string]agents.AgentType
+	if err := s.store.Load(agentOverridesDocument, &stored); err != nil || stored[pinned.Key()] != agents.AgentClaudeCode {
+		t.Fatalf("stored overrides = %v, %v, want the pin", stored, err)
+	}
+
+	// A window that takes the place of a gone one doesn't inherit its pin
+	runTmux(t, "kill-window", "-t", "work:1")
+	s.buildSessionsData(context.Background(), sessionsQuery{})
+	runTmux(t, "new-window", "-d", "-t", "work:1", "-n", "w1", "cat")
+	if code, got := agent(s, "GET", pinned, ""); code != 200 || got.Manual {
+		t.Errorf("GET of the new window = %d, %+v, want detection", code, got)
+	}
+	if _, ok := s.registry.Override(tmux.Pane{Host: "devbox", Session: "work"}.Key()); !ok {
+		t.Error("pin of an unlisted host was dropped")
+	}
+	stored = nil
+	if err := s.store.Load(agentOverridesDocument, &stored); err != nil || len(stored) != 1 || stored[pinned.Key()] != "" {
+		t.Errorf("stored overrides = %v, %v, want only devbox's", stored, err)
+	}
+}
@@ -7,26 +7,39 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/noamsto/houston/agents"
 	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/internal/ansi"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/status"
 	"github.com/noamsto/houston/tmux"
 )
 
 func (s *Server) handleAPISessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		s.handleAPICreateSession(w, r)
+		return
+	}
+	q, err := parseSessionsQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if r.URL.Query().Get("stream") == "1" {
-		s.streamAPISessionsJSON(w, r)
+		s.streamAPISessionsJSON(w, r, q)
 		return
 	}
 
-	data := s.buildSessionsData()
+	data := s.buildSessionsData(r.Context(), q)
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(data)
 }
 
-func (s *Server) streamAPISessionsJSON(w http.ResponseWriter, r *http.Request) {
+func (s *Server) streamAPISessionsJSON(w http.ResponseWriter, r *http.Request, q sessionsQuery) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "streaming not supported", http.StatusInternalServerError)
@@ -40,13 +53,26 @@ func (s *Server) streamAPISessionsJSON(w http.ResponseWriter, r *http.Request) {
 	_, _ = fmt.Fprintf(w, ": connected\n\n")
 	flusher.Flush()
 
-	ticker := time.NewTicker(3 * time.Second)
+	ticker := time.NewTicker(s.sessionsInterval)
 	defer ticker.Stop()
 
+	events, unsubscribe := s.watcher.Subscribe()
+	defer unsubscribe()
+
+	states, unsubscribeStates := s.tailer.Subscribe()
+	defer unsubscribeStates()
+
 	var lastJSON []byte
 
+	// EventSource sends Last-Event-ID when it reconnects by itself; clients
+	// that recreate the stream pass it as a query parameter instead.
+	resumeID := r.Header.Get("Last-Event-ID")
+	if resumeID == "" {
+		resumeID = r.URL.Query().Get("last_event_id")
+	}
+
 	send := func() error {
-		data := s.buildSessionsData()
+		data := s.buildSessionsData(r.Context(), q)
 		jsonBytes, err := json.Marshal(data)
 		if err != nil {
 			return err
@@ -55,7 +81,32 @@ func (s *Server) streamAPISessionsJSON(w http.ResponseWriter, r *http.Request) {
 			return nil
 		}
 		lastJSON = jsonBytes
-		if _, err := fmt.Fprintf(w, "data: %s\n\n", jsonBytes); err != nil {
+		id := s.sessionsHistory.record(data, jsonBytes)
+
+		// First payload after a reconnect: send nothing if the client is
+		// current, or only the sessions that changed.
+		if resumeID != "" {
+			base := resumeID
+			resumeID = ""
+			if base == id {
+				_, err := fmt.Fprintf(w, ": resumed\n\n")
+				flusher.Flush()
+				return err
+			}
+			if patch, ok := s.sessionsHistory.patch(base, data); ok {
+				patchJSON, err := json.Marshal(patch)
+				if err != nil {
+					return err
+				}
+				if _, err := fmt.Fprintf(w, "id: %s\nevent: catchup\ndata: %s\n\n", id, patchJSON); err != nil {
+					return err
+				}
+				flusher.Flush()
+				return nil
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "id: %s\ndata: %s\n\n", id, jsonBytes); err != nil {
 			return err
 		}
 		flusher.Flush()
@@ -63,7 +114,7 @@ func (s *Server) streamAPISessionsJSON(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := send(); err != nil {
-		slog.Debug("SSE sessions initial write error", "error", err)
+		slog.DebugContext(r.Context(), "SSE sessions initial write error", "error", err)
 		return
 	}
 
@@ -71,15 +122,52 @@ func (s *Server) streamAPISessionsJSON(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-s.draining:
+			// Reconnect right away, to the houston taking over
+			_, _ = fmt.Fprintf(w, "retry: 500\n\n")
+			flusher.Flush()
+			return
 		case <-ticker.C:
 			if err := send(); err != nil {
-				slog.Debug("SSE sessions write error", "error", err)
+				slog.DebugContext(r.Context(), "SSE sessions write error", "error", err)
 				return
 			}
+		case ev := <-events:
+			if !hookPush(ev) {
+				continue
+			}
+			if err := send(); err != nil {
+				slog.DebugContext(r.Context(), "SSE sessions write error", "error", err)
+				return
+			}
+			ticker.Reset(s.sessionsInterval)
+		case ev := <-states:
+			if !statePush(ev) {
+				continue
+			}
+			if err := send(); err != nil {
+				slog.DebugContext(r.Context(), "SSE sessions write error", "error", err)
+				return
+			}
+			ticker.Reset(s.sessionsInterval)
 		}
 	}
 }
 
+// hookPush reports whether a hook status change should reach clients right
+// away instead of on the next poll. Stop and Notification hooks (idle,
+// waiting, permission) are what a user is waiting on; working updates from
+// tool hooks are frequent and can wait for the tick.
+func hookPush(ev status.Event) bool {
+	return !ev.Removed && ev.Status.Status != status.StatusWorking
+}
+
+// statePush is hookPush for session state read from Claude's files: a turn
+// ending (or a question) is pushed as soon as it is written.
+func statePush(ev claude.StateEvent) bool {
+	return ev.Result.Type != parser.TypeWorking
+}
+
 func (s *Server) handleAPIPane(w http.ResponseWriter, r *http.Request) {
 	// Rewrite path: strip /api prefix so parsePaneTarget (which expects /pane/...) works
 	path := strings.TrimPrefix(r.URL.Path, "/api")
@@ -88,13 +176,26 @@ func (s *Server) handleAPIPane(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid pane target", http.StatusBadRequest)
 		return
 	}
+	pane.Host = r.URL.Query().Get("host")
+	if s.client(pane.Host) == nil {
+		http.Error(w, "unknown host", http.StatusNotFound)
+		return
+	}
 
 	// Route based on suffix
 	switch {
 	case strings.HasSuffix(path, "/ws"):
 		s.handlePaneWS(w, r, pane)
+	case strings.HasSuffix(path, "/events"):
+		s.handlePaneEvents(w, r, pane)
 	case strings.HasSuffix(path, "/send") && r.Method == http.MethodPost:
 		s.handlePaneSend(w, r, pane)
+	case strings.HasSuffix(path, "/send-template"):
+		s.handlePaneSendTemplate(w, r, pane)
+	case strings.HasSuffix(path, "/choose"):
+		s.handlePaneChoose(w, r, pane)
+	case strings.HasSuffix(path, "/macro"):
+		s.handlePaneMacro(w, r, pane)
 	case strings.HasSuffix(path, "/send-with-images") && r.Method == http.MethodPost:
 		s.handlePaneSendWithImages(w, r, pane)
 	case strings.HasSuffix(path, "/kill") && r.Method == http.MethodPost:
@@ -105,58 +206,185 @@ func (s *Server) handleAPIPane(w http.ResponseWriter, r *http.Request) {
 		s.handleWindowKill(w, r, pane)
 	case strings.HasSuffix(path, "/zoom") && r.Method == http.MethodPost:
 		s.handlePaneZoom(w, r, pane)
+	case strings.HasSuffix(path, "/transcript"):
+		s.handlePaneTranscript(w, r, pane)
+	case strings.HasSuffix(path, "/handoff") && r.Method == http.MethodPost:
+		s.handlePaneHandoff(w, r, pane)
+	case strings.HasSuffix(path, "/resume") && r.Method == http.MethodPost:
+		s.handlePaneResume(w, r, pane)
+	case strings.HasSuffix(path, "/auto-compact"):
+		s.handlePaneAutoCompact(w, r, pane)
+	case strings.HasSuffix(path, "/agent"):
+		s.handlePaneAgent(w, r, pane)
+	case strings.HasSuffix(path, "/history"):
+		s.handlePaneHistory(w, r, pane)
+	case strings.HasSuffix(path, "/todos"):
+		s.handlePaneTodos(w, r, pane)
+	case strings.HasSuffix(path, "/diff"):
+		s.handlePaneDiff(w, r, pane)
+	case strings.HasSuffix(path, "/commit"):
+		s.handlePaneGit(w, r, pane, gitCommit)
+	case strings.HasSuffix(path, "/push"):
+		s.handlePaneGit(w, r, pane, gitPush)
+	case strings.HasSuffix(path, "/upload"):
+		s.handlePaneUpload(w, r, pane)
+	case strings.HasSuffix(path, "/paste"):
+		s.handlePanePaste(w, r, pane)
+	case strings.HasSuffix(path, "/screenshot.png"):
+		s.handlePaneScreenshot(w, r, pane)
+	case strings.HasSuffix(path, "/recording"):
+		s.handlePaneRecording(w, r, pane)
+	case strings.HasSuffix(path, "/tags"):
+		s.handlePaneTags(w, r, pane)
+	case strings.HasSuffix(path, "/queue"):
+		s.handlePaneQueue(w, r, pane)
+	case strings.HasSuffix(path, "/focus"):
+		s.handlePaneFocus(w, r, pane)
+	case strings.HasSuffix(path, "/interrupt"):
+		s.handlePaneKeys(w, r, pane, "interrupt")
+	case strings.HasSuffix(path, "/escape"):
+		s.handlePaneKeys(w, r, pane, "escape")
+	case strings.HasSuffix(path, "/clear"):
+		s.handlePaneKeys(w, r, pane, "clear")
+	case strings.HasSuffix(path, "/copy-mode"):
+		s.handlePaneCopyMode(w, r, pane)
+	case strings.HasSuffix(path, "/scroll"):
+		s.handlePaneScroll(w, r, pane)
 	default:
 		s.handlePaneJSON(w, r, pane)
 	}
 }
 
-func (s *Server) handlePaneJSON(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
-	windows, _ := s.tmux.ListWindows(pane.Session)
-	paneInfos, _ := s.tmux.ListPanes(pane.Session, pane.Window)
-
-	capture, err := s.tmux.CapturePaneWithMode(pane, 500)
-	if err != nil {
-		http.Error(w, "failed to capture pane", http.StatusInternalServerError)
-		return
+// wantColors reports whether a pane capture should keep its ANSI escape
+// sequences. Colors are on by default because the SPA renders output with
+// xterm.js; colors=false returns plain text for simpler clients.
+func wantColors(r *http.Request) bool {
+	v := r.URL.Query().Get("colors")
+	if v == "" {
+		return true
 	}
+	colors, err := strconv.ParseBool(v)
+	return err != nil || colors
+}
+
+func (s *Server) handlePaneJSON(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	windows, _ := s.client(pane.Host).ListWindows(pane.Session)
+	paneInfos, _ := s.client(pane.Host).ListPanes(pane.Session, pane.Window)
 
-	var panePath, paneCommand string
+	var info tmux.PaneInfo
 	for _, p := range paneInfos {
 		if p.Index == pane.Index {
-			panePath = p.Path
-			paneCommand = p.Command
+			info = p
 			break
 		}
 	}
 
-	paneID := pane.Target()
-	agent := s.registry.Detect(paneID, paneCommand, capture.Output)
-	parseResult := getAgentState(agent, panePath, capture.Output)
+	// Shared with the pane's streams, which captured it a moment ago if
+	// someone is watching it
+	snap, err := s.paneService.get(r.Context(), pane, info)
+	if err != nil {
+		http.Error(w, "failed to capture pane", http.StatusInternalServerError)
+		return
+	}
+	paneID := pane.Key()
+	agent, parseResult := snap.agent, snap.result
 
 	suggestion := ""
 	if agent.Type() == agents.AgentClaudeCode {
-		suggestion = claude.ExtractSuggestion(capture.Output)
+		suggestion = claude.ExtractSuggestion(snap.output)
 	}
 
-	width, height, _ := s.tmux.GetPaneSize(pane)
+	width, height, _ := s.client(pane.Host).GetPaneSize(pane)
+
+	output := snap.view
+	if !snap.mode.Scrolled() {
+		output = agent.FilterStatusBar(snap.view)
+	}
+	if !wantColors(r) {
+		output = ansi.Strip(output)
+	}
+
+	_, agentManual := s.registry.Override(paneID)
 
 	data := PaneData{
 		Pane:        pane,
-		Output:      agent.FilterStatusBar(capture.Output),
+		AgentType:   agent.Type(),
+		AgentManual: agentManual,
+		Output:      output,
 		ParseResult: parseResult,
 		Windows:     windows,
 		Panes:       paneInfos,
 		PaneWidth:   width,
 		PaneHeight:  height,
 		Suggestion:  suggestion,
-		StripItems:  s.buildAgentStripItems(pane.Session, pane.Window, pane.Index),
+		StripItems:  s.agentStrip(r.Context(), pane),
+		TmuxMode:    snap.mode,
+	}
+	if agent.Type() == agents.AgentClaudeCode {
+		health := s.mcp.observe(paneID, claude.ParseMCPStatus(snap.output))
+		data.MCP = &health
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(data)
 }
 
+// handlePaneTranscript renders the agent conversation running in a pane as
+// Markdown (default) or HTML, reconstructed from the agent's log files.
+func (s *Server) handlePaneTranscript(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "md"
+	}
+	if format != "md" && format != "html" {
+		http.Error(w, "format must be md or html", http.StatusBadRequest)
+		return
+	}
+
+	if pane.Host != "" {
+		http.Error(w, "transcripts are not available for remote panes", http.StatusNotFound)
+		return
+	}
+
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok || info.Path == "" {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	output, _ := s.client(pane.Host).CapturePane(pane, 100)
+	agent := s.registry.Detect(pane.Key(), info.Command, output)
+	provider, ok := agent.(agents.TranscriptProvider)
+	if !ok {
+		http.Error(w, "no transcript available for "+string(agent.Type())+" panes", http.StatusNotFound)
+		return
+	}
+
+	transcript, err := provider.Transcript(info.Path)
+	if err != nil {
+		slog.DebugContext(r.Context(), "transcript unavailable", "pane", pane.Target(), "error", err)
+		http.Error(w, "transcript not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if format == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(transcript.HTML()))
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, _ = w.Write([]byte(transcript.Markdown()))
+}
+
 func (s *Server) handleAPIOpenCodeSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		if s.ocManager == nil {
+			http.Error(w, "OpenCode integration not enabled", http.StatusNotImplemented)
+			return
+		}
+		s.handleOpenCodeCreate(w, r)
+		return
+	}
 	if s.ocManager == nil {
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(OpenCodeData{})
@@ -198,9 +426,13 @@ func (s *Server) streamAPIOpenCodeJSON(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-s.draining:
+			_, _ = fmt.Fprintf(w, "retry: 500\n\n")
+			flusher.Flush()
+			return
 		case <-ticker.C:
 			if err := s.sendAPIOpenCodeEvent(r.Context(), w, flusher); err != nil {
-				slog.Debug("SSE opencode write error", "error", err)
+				slog.DebugContext(r.Context(), "SSE opencode write error", "error", err)
 				return
 			}
 		}
@@ -231,8 +463,9 @@ func (s *Server) handleAPIOpenCodeSession(w http.ResponseWriter, r *http.Request
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, "+apiVersionHeader+", "+requestIDHeader)
+		w.Header().Set("Access-Control-Expose-Headers", apiVersionHeader+", "+requestIDHeader)
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/noamsto/houston/status"
+)
+
+func TestWantColors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"?colors=true", true},
+		{"?colors=1", true},
+		{"?colors=false", false},
+		{"?colors=0", false},
+		{"?colors=bogus", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/pane/main:0.0"+tt.query, nil)
+		if got := wantColors(r); got != tt.want {
+			t.Errorf("wantColors(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHookPush(t *testing.T) {
+	tests := []struct {
+		name string
+		ev   status.Event
+		want bool
+	}{
+		{"stop", status.Event{Status: status.SessionStatus{Status: status.StatusIdle}}, true},
+		{"permission", status.Event{Status: status.SessionStatus{Status: status.StatusPermission}}, true},
+		{"waiting", status.Event{Status: status.SessionStatus{Status: status.StatusWaiting}}, true},
+		{"working", status.Event{Status: status.SessionStatus{Status: status.StatusWorking}}, false},
+		{"removed", status.Event{Status: status.SessionStatus{Status: status.StatusIdle}, Removed: true}, false},
+	}
+	for _, tt := range tests {
+		if got := hookPush(tt.ev); got != tt.want {
+			t.Errorf("hookPush(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderSnapshot(t *testing.T) {
+	got := string(renderSnapshot("one\ntwo\n", 3, 1, false))
+	want := "\x1b[H\x1b[2Jone\x1b[0m\r\ntwo\x1b[0m\x1b[2;4H"
+	if got != want {
+		t.Errorf("renderSnapshot() = %q, want %q", got, want)
+	}
+
+	got = string(renderSnapshot("x\n", 0, 0, true))
+	want = "\x1b[?1049h\x1b[H\x1b[2Jx\x1b[0m\x1b[1;1H"
+	if got != want {
+		t.Errorf("renderSnapshot(alternate) = %q, want %q", got, want)
+	}
+}
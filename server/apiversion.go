@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is the current version of the JSON API. A breaking change to
+// it (say, a new SSE protocol) comes as the next version, served next to
+// the old one, so existing clients keep working.
+const APIVersion = 1
+
+// apiVersions are the versions served, oldest first.
+var apiVersions = []int{APIVersion}
+
+// apiVersionHeader names the API version: in a request, the version the
+// client is written against; in every response, the version served.
+const apiVersionHeader = "Houston-API-Version"
+
+// withAPIVersion serves /api/v{N}/... as /api/... for each version in
+// apiVersions. Unversioned paths remain an alias of the current version
+// for existing clients, which can pin one with the Houston-API-Version
+// header instead. A version that isn't served is refused rather than
+// answered in a format the client doesn't expect: in the path it names no
+// resource, a 404, and in the header it is a 406.
+func withAPIVersion(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version := APIVersion
+		pathVersion, rest, ok := cutAPIVersion(r.URL.Path)
+		if ok {
+			if !slices.Contains(apiVersions, pathVersion) {
+				http.Error(w, fmt.Sprintf("unknown API version %d; served: %s", pathVersion, apiVersionList()), http.StatusNotFound)
+				return
+			}
+			version = pathVersion
+		}
+		if v := r.Header.Get(apiVersionHeader); v != "" {
+			requested, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(v), "v"))
+			if err != nil || !slices.Contains(apiVersions, requested) {
+				http.Error(w, fmt.Sprintf("unsupported %s %q; served: %s", apiVersionHeader, v, apiVersionList()), http.StatusNotAcceptable)
+				return
+			}
+			if ok && requested != pathVersion {
+				http.Error(w, fmt.Sprintf("%s %d contradicts the path's version %d", apiVersionHeader, requested, pathVersion), http.StatusBadRequest)
+				return
+			}
+			version = requested
+		}
+		w.Header().Set(apiVersionHeader, strconv.Itoa(version))
+
+		if ok {
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = rest
+			if r.URL.RawPath != "" {
+				_, r2.URL.RawPath, _ = cutAPIVersion(r.URL.RawPath)
+			}
+			r = r2
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// cutAPIVersion splits /api/v{N}/rest into N and /api/rest.
+func cutAPIVersion(path string) (version int, rest string, ok bool) {
+	after, found := strings.CutPrefix(path, "/api/v")
+	if !found {
+		return 0, path, false
+	}
+	n, tail, _ := strings.Cut(after, "/")
+	version, err := strconv.Atoi(n)
+	if err != nil || version <= 0 || strconv.Itoa(version) != n {
+		return 0, path, false // e.g. /api/views
+	}
+	return version, "/api/" + tail, true
+}
+
+func apiVersionList() string {
+	versions := make([]string, len(apiVersions))
+	for i, v := range apiVersions {
+		versions[i] = strconv.Itoa(v)
+	}
+	return strings.Join(versions, ", ")
+}
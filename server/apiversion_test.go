@@ -0,0 +1,59 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithAPIVersion(t *testing.T) {
+	h := withAPIVersion(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.EscapedPath())
+	}))
+
+	tests := []struct {
+		path, header string
+		code         int
+		body         string
+	}{
+		{"/api/sessions", "", http.StatusOK, "/api/sessions"},
+		{"/api/v1/sessions", "", http.StatusOK, "/api/sessions"},
+		{"/api/v1/opencode/session/http%3A%2F%2Fhost%3A4096/ses1", "", http.StatusOK, "/api/opencode/session/http%3A%2F%2Fhost%3A4096/ses1"},
+		{"/api/views/work", "", http.StatusOK, "/api/views/work"},
+		{"/api/sessions", "1", http.StatusOK, "/api/sessions"},
+		{"/api/v1/sessions", "v1", http.StatusOK, "/api/sessions"},
+		{"/api/v2/sessions", "", http.StatusNotFound, "unknown API version 2; served: 1\n"},
+		{"/api/v2/sessions", "1", http.StatusNotFound, "unknown API version 2; served: 1\n"},
+		{"/api/v2", "", http.StatusNotFound, "unknown API version 2; served: 1\n"},
+		{"/api/v1/sessions", "2", http.StatusNotAcceptable, ""},
+		{"/api/v0/sessions", "", http.StatusOK, "/api/v0/sessions"},
+		{"/api/v01/sessions", "", http.StatusOK, "/api/v01/sessions"},
+		{"/api/sessions", "2", http.StatusNotAcceptable, ""},
+		{"/api/sessions", "latest", http.StatusNotAcceptable, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			r.Header.Set(apiVersionHeader, tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s (%s %q) = %d, want %d", tt.path, apiVersionHeader, tt.header, w.Code, tt.code)
+			continue
+		}
+		if tt.code != http.StatusOK {
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("%s (%s %q) = %q, want %q", tt.path, apiVersionHeader, tt.header, w.Body, tt.body)
+			}
+		} else {
+			if w.Body.String() != tt.body {
+				t.Errorf("%s served as %s, want %s", tt.path, w.Body, tt.body)
+			}
+			if v := w.Header().Get(apiVersionHeader); v != "1" {
+				t.Errorf("%s: %s = %q, want 1", tt.path, apiVersionHeader, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/internal/textutil"
+)
+
+// auditLog is the store log recording every mutating action.
+const auditLog = "audit"
+
+// maxAuditDetail bounds the detail kept per entry; pasted prompts can be
+// long.
+const maxAuditDetail = 4096
+
+// auditSelf is the client of actions houston takes on its own (policies,
+// schedules, the prompt queue, auto-compaction).
+const auditSelf = "houston"
+
+// identityHeaders name the user an authenticating proxy in front of
+// houston (Tailscale Serve, oauth2-proxy, ...) vouches for. houston has
+// no authentication of its own, so they are recorded as sent.
+var identityHeaders = []string{
+	"Tailscale-User-Login",
+	"X-Forwarded-User",
+	"X-Forwarded-Email",
+	"X-Auth-Request-User",
+	"X-Auth-Request-Email",
+	"Remote-User",
+}
+
+// AuditEntry is a record of one action that changed a pane, session or
+// setting.
+type AuditEntry struct {
+	At       time.Time `json:"at"`
+	Action   string    `json:"action"`             // "POST /api/pane/main:1.0/send"; auto-approve, schedule, queue, auto-compact for houston's own
+	Target   string    `json:"target,omitempty"`   // Pane key, when the action names one
+	Detail   string    `json:"detail,omitempty"`   // Text sent, choice made, session aborted, ...
+	Status   int       `json:"status,omitempty"`   // HTTP status of the request
+	Client   string    `json:"client"`             // Client IP, or "houston"
+	Identity string    `json:"identity,omitempty"` // From identityHeaders or basic auth
+}
+
+type auditEntryKey struct{}
+
+// auditMiddleware records each mutating API request after it completes.
+// Handlers add what a path doesn't show (the text sent) with auditDetail.
+// Claude hook events are status reports, not actions, and are skipped, as
+// are batches, which only read (see mutating).
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !mutating(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := &AuditEntry{
+			Action:   r.Method + " " + r.URL.Path,
+			Client:   clientIP(r),
+			Identity: requestIdentity(r),
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/pane/") {
+			if pane, err := parsePaneTarget(strings.TrimPrefix(r.URL.Path, "/api")); err == nil {
+				pane.Host = r.URL.Query().Get("host")
+				entry.Target = pane.Key()
+			}
+		}
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), auditEntryKey{}, entry)))
+
+		entry.At = time.Now()
+		entry.Status = statusOrOK(sw.status)
+		s.audit(*entry)
+	})
+}
+
+// auditDetail sets the detail of the request's audit entry.
+func auditDetail(r *http.Request, detail string) {
+	if entry, ok := r.Context().Value(auditEntryKey{}).(*AuditEntry); ok {
+		entry.Detail = detail
+	}
+}
+
+// audit appends an entry to the audit log.
+func (s *Server) audit(entry AuditEntry) {
+	if entry.At.IsZero() {
+		entry.At = time.Now()
+	}
+	if len(entry.Detail) > maxAuditDetail {
+		entry.Detail = textutil.Truncate(entry.Detail, maxAuditDetail) + "…"
+	}
+	if err := s.store.Append(auditLog, entry); err != nil {
+		slog.Warn("failed to record audit entry", "action", entry.Action, "error", err)
+	}
+}
+
+// auditRequest returns an entry for actions a long-lived request takes
+// (WebSocket input), attributed to the request's client.
+func auditRequest(r *http.Request, action, target string) AuditEntry {
+	return AuditEntry{
+		Action:   action,
+		Target:   target,
+		Client:   clientIP(r),
+		Identity: requestIdentity(r),
+	}
+}
+
+// clientIP returns the request's remote address. X-Forwarded-For is only
+// believed from a proxy on this machine.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	return host
+}
+
+// requestIdentity returns who the request claims to come from, if anyone.
+func requestIdentity(r *http.Request) string {
+	for _, h := range identityHeaders {
+		if v := r.Header.Get(h); v != "" {
+			return v
+		}
+	}
+	if user, _, ok := r.BasicAuth(); ok {
+		return user
+	}
+	return ""
+}
+
+// auditEntries returns audit entries at or after since, newest first.
+func (s *Server) auditEntries(since time.Time) ([]AuditEntry, error) {
+	result := []AuditEntry{}
+	err := s.store.ReadLog(auditLog, func(raw json.RawMessage) error {
+		var e AuditEntry
+		if err := json.Unmarshal(raw, &e); err != nil || e.At.Before(since) {
+			return nil
+		}
+		result = append(result, e)
+		return nil
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].At.After(result[j].At) })
+	return result, err
+}
+
+// handleAPIAudit serves GET /api/audit?since=RFC3339 (default: the last 7
+// days), optionally narrowed to one pane with ?target=.
+func (s *Server) handleAPIAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	since := time.Now().AddDate(0, 0, -defaultHistoryDays)
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "since must be RFC3339", http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	entries, err := s.auditEntries(since)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read audit log", "error", err)
+		http.Error(w, "failed to read audit log", http.StatusInternalServerError)
+		return
+	}
+	if target := r.URL.Query().Get("target"); target != "" {
+		filtered := []AuditEntry{}
+		for _, e := range entries {
+			if e.Target == target {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+// statusWriter remembers the status a handler wrote.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.status == 0 {
+		sw.status = code
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(p)
+}
+
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/store"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st}
+	h := s.auditMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auditDetail(r, "git push --force")
+		w.WriteHeader(http.StatusConflict)
+	}))
+
+	send := httptest.NewRequest("POST", "/api/pane/work:1.0/send?host=devbox", nil)
+	send.RemoteAddr = "127.0.0.1:5000"
+	send.Header.Set("X-Forwarded-For", "100.64.0.7, 127.0.0.1")
+	send.Header.Set("Tailscale-User-Login", "alice@example.com")
+	h.ServeHTTP(httptest.NewRecorder(), send)
+
+	// Reads and hook events aren't actions
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/sessions", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/hooks/claude", nil))
+
+	s.audit(AuditEntry{Action: "auto-approve", Target: "work:2.0", Detail: strings.Repeat("é", maxAuditDetail), Client: auditSelf})
+
+	w := httptest.NewRecorder()
+	s.handleAPIAudit(w, httptest.NewRequest("GET", "/api/audit", nil))
+	var entries []AuditEntry
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+
+	approve, sent := entries[0], entries[1]
+	if approve.Action != "auto-approve" || len(approve.Detail) > maxAuditDetail+len("…") || !strings.HasSuffix(approve.Detail, "é…") {
+		t.Errorf("newest entry = %q, detail of %d bytes", approve.Action, len(approve.Detail))
+	}
+	want := AuditEntry{
+		At:       sent.At,
+		Action:   "POST /api/pane/work:1.0/send",
+		Target:   "devbox|work:1.0",
+		Detail:   "git push --force",
+		Status:   http.StatusConflict,
+		Client:   "100.64.0.7",
+		Identity: "alice@example.com",
+	}
+	if sent != want {
+		t.Errorf("entry = %+v, want %+v", sent, want)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPIAudit(w, httptest.NewRequest("GET", "/api/audit?target=work:2.0", nil))
+	entries = nil
+	if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Target != "work:2.0" {
+		t.Errorf("filtered entries = %+v", entries)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		remote, forwarded, want string
+	}{
+		{"192.168.1.9:4410", "", "192.168.1.9"},
+		{"192.168.1.9:4410", "10.0.0.1", "192.168.1.9"}, // Only a local proxy is believed
+		{"127.0.0.1:4410", "10.0.0.1, 127.0.0.1", "10.0.0.1"},
+		{"[::1]:4410", "", "::1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/api/broadcast", nil)
+		r.RemoteAddr = tt.remote
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if got := clientIP(r); got != tt.want {
+			t.Errorf("clientIP(%s, %q) = %q, want %q", tt.remote, tt.forwarded, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// CleanBasePath normalizes a -base-path: "" for the root, otherwise a
+// leading slash and no trailing one ("houston/" becomes "/houston").
+func CleanBasePath(p string) string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return ""
+	}
+	return "/" + p
+}
+
+// withBasePath serves h under prefix for a reverse proxy that forwards
+// /houston/... as is. The bare prefix redirects to prefix/, and paths
+// outside it are served unchanged, for proxies that strip the prefix
+// themselves.
+func withBasePath(prefix string, h http.Handler) http.Handler {
+	if prefix == "" {
+		return h
+	}
+	stripped := http.StripPrefix(prefix, h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == prefix:
+			target := prefix + "/"
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusMovedPermanently)
+		case strings.HasPrefix(r.URL.Path, prefix+"/"):
+			stripped.ServeHTTP(w, r)
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+}
+
+// serveIndex serves the SPA's index.html with a <base href> naming the
+// base path, so the UI's relative asset and API URLs resolve under the
+// prefix from whatever path the page was loaded at.
+func serveIndex(w http.ResponseWriter, r *http.Request, uiFS fs.FS, basePath string) {
+	data, err := fs.ReadFile(uiFS, "index.html")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	tag := `<base href="` + html.EscapeString(basePath+"/") + `">`
+	page := string(data)
+	if i := strings.Index(page, "<head>"); i >= 0 {
+		i += len("<head>")
+		page = page[:i] + tag + page[i:]
+	} else {
+		page = tag + page
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.WriteString(w, page)
+}
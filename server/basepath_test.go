@@ -0,0 +1,54 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCleanBasePath(t *testing.T) {
+	for in, want := range map[string]string{"": "", "/": "", "houston": "/houston", "/houston/": "/houston", "/a/b/": "/a/b"} {
+		if got := CleanBasePath(in); got != want {
+			t.Errorf("CleanBasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	ui := fstest.MapFS{
+		"index.html":    {Data: []byte(`<html><head><script src="./assets/app.js"></script></head></html>`)},
+		"assets/app.js": {Data: []byte("app()")},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/meta", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "meta") })
+	mux.Handle("/", SPAHandler(ui, "/houston"))
+	h := withBasePath("/houston", mux)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	if w := get("/houston?x=1"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/houston/?x=1" {
+		t.Errorf("bare prefix: %d to %q", w.Code, w.Header().Get("Location"))
+	}
+	for _, path := range []string{"/houston/", "/houston/some/deep/route"} {
+		body := get(path).Body.String()
+		if !strings.Contains(body, `<head><base href="/houston/"><script`) {
+			t.Errorf("%s: index without base href: %s", path, body)
+		}
+	}
+	if body := get("/houston/assets/app.js").Body.String(); body != "app()" {
+		t.Errorf("asset = %q", body)
+	}
+	// Prefixed for a proxy forwarding as is, bare for one that strips it
+	for _, path := range []string{"/houston/api/meta", "/api/meta"} {
+		if body := get(path).Body.String(); body != "meta" {
+			t.Errorf("%s = %q", path, body)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Limits of /api/batch.
+const (
+	maxBatchQueries     = 20
+	maxBatchBody        = 64 << 10
+	batchConcurrency    = 4 // Queries run at once; most of them run tmux
+	maxBatchResultBytes = 8 << 20
+)
+
+// BatchRequest is the body of POST /api/batch: GET requests to the JSON
+// API, such as "/api/sessions" or "/api/pane/work:1.0?colors=false".
+type BatchRequest struct {
+	Paths []string `json:"paths"`
+}
+
+// BatchResult is the response to one path of a BatchRequest, as GET on it
+// would have answered.
+type BatchResult struct {
+	Path   string          `json:"path"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"` // JSON responses
+	Text   string          `json:"text,omitempty"` // Text responses and errors
+}
+
+// handleAPIBatch serves POST /api/batch: several GET requests answered in
+// one round trip, which matters on a phone far from the server. Results
+// come in the order of the paths; each has the status its request would
+// have had, so one failing query doesn't fail the others. Streams
+// (WebSockets, SSE) and batches can't be batched.
+func (s *Server) handleAPIBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req BatchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBody)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.Paths) == 0 {
+		http.Error(w, "paths is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Paths) > maxBatchQueries {
+		http.Error(w, fmt.Sprintf("at most %d paths per batch", maxBatchQueries), http.StatusBadRequest)
+		return
+	}
+
+	api := withAPIVersion(s.limitMiddleware(s.newAPIMux()))
+	results := make([]BatchResult, len(req.Paths))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, path := range req.Paths {
+		results[i].Path = path
+		u, err := batchURL(path)
+		if err != nil {
+			results[i].Status = http.StatusBadRequest
+			results[i].Text = err.Error()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			sub, err := http.NewRequestWithContext(r.Context(), http.MethodGet, u.String(), nil)
+			if err != nil {
+				results[i].Status = http.StatusBadRequest
+				results[i].Text = err.Error()
+				return
+			}
+			sub.RemoteAddr = r.RemoteAddr
+			if v := r.Header.Get(apiVersionHeader); v != "" {
+				sub.Header.Set(apiVersionHeader, v)
+			}
+			rec := &batchRecorder{header: make(http.Header)}
+			api.ServeHTTP(rec, sub)
+			results[i] = rec.result(path)
+		}()
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
+// batchURL checks a batched path: an API path that answers with a single
+// response.
+func batchURL(path string) (*url.URL, error) {
+	u, err := url.Parse(path)
+	if err != nil || u.IsAbs() || u.Host != "" || !strings.HasPrefix(u.Path, "/api/") {
+		return nil, fmt.Errorf("path must be an /api/ path")
+	}
+	_, p, _ := cutAPIVersion(u.Path)
+	switch {
+	case p == "/api/batch":
+		return nil, fmt.Errorf("batches can't be nested")
+	case isStream(u):
+		return nil, fmt.Errorf("streams can't be batched")
+	}
+	return u, nil
+}
+
+// batchRecorder keeps a batched response in memory.
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *batchRecorder) Header() http.Header { return b.header }
+
+func (b *batchRecorder) WriteHeader(code int) {
+	if b.status == 0 {
+		b.status = code
+	}
+}
+
+func (b *batchRecorder) Write(p []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	if b.body.Len()+len(p) > maxBatchResultBytes {
+		return 0, fmt.Errorf("batched response is larger than %d MiB", maxBatchResultBytes>>20)
+	}
+	return b.body.Write(p)
+}
+
+func (b *batchRecorder) result(path string) BatchResult {
+	res := BatchResult{Path: path, Status: b.status}
+	if res.Status == 0 {
+		res.Status = http.StatusOK
+	}
+	mediaType, _, _ := mime.ParseMediaType(b.header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" && json.Valid(b.body.Bytes()):
+		res.Body = bytes.TrimSpace(b.body.Bytes())
+	case strings.HasPrefix(mediaType, "text/"):
+		res.Text = b.body.String()
+	case b.body.Len() > 0:
+		res.Status = http.StatusUnsupportedMediaType
+		res.Text = mediaType + " responses can't be batched; GET " + path + " instead"
+	}
+	return res
+}
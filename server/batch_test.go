@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/store"
+)
+
+func TestHandleAPIBatch(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, compact: newCompactRule(0, "")}
+	s.loadPrefs()
+	s.prefs["laptop"] = Prefs{CaptureLines: 500}
+
+	body := `{"paths": ["/api/prefs/laptop", "/api/v1/prefs", "/api/prefs/phone", "/api/pane/work:1.0/events", "/api/batch", "https://example.com/api/prefs"]}`
+	w := httptest.NewRecorder()
+	s.handleAPIBatch(w, httptest.NewRequest("POST", "/api/batch", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d %s", w.Code, w.Body)
+	}
+	var results []BatchResult
+	if err := json.NewDecoder(w.Body).Decode(&results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("got %d results, want 6", len(results))
+	}
+
+	var p Prefs
+	if results[0].Status != http.StatusOK || json.Unmarshal(results[0].Body, &p) != nil || p.CaptureLines != 500 {
+		t.Errorf("prefs result = %+v", results[0])
+	}
+	var all map[string]Prefs
+	if results[1].Status != http.StatusOK || json.Unmarshal(results[1].Body, &all) != nil || len(all) != 1 {
+		t.Errorf("versioned result = %+v", results[1])
+	}
+	if results[2].Status != http.StatusNotFound || results[2].Text == "" {
+		t.Errorf("missing prefs result = %+v", results[2])
+	}
+	for _, res := range results[3:] {
+		if res.Status != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", res.Path, res.Status)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPIBatch(w, httptest.NewRequest("POST", "/api/batch", strings.NewReader(`{"paths": []}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("empty batch = %d, want 400", w.Code)
+	}
+}
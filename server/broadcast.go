@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+// BroadcastRequest sends the same input to several panes at once.
+// Panes are chosen by explicit targets, window tags, a view filter, or any
+// of them (union).
+type BroadcastRequest struct {
+	Targets    []string `json:"targets,omitempty"`     // e.g. ["work:1.0", "api:2"]
+	Host       string   `json:"host,omitempty"`        // Remote host for Targets (default: local)
+	Tags       []string `json:"tags,omitempty"`        // Windows carrying all of these tags
+	Filter     string   `json:"filter,omitempty"`      // View filter, e.g. "agent=claude AND branch~=^feat/"
+	AgentsOnly bool     `json:"agents_only,omitempty"` // Skip windows without a detected agent
+	Input      string   `json:"input"`
+	Special    bool     `json:"special,omitempty"` // Input is a special key name (Escape, C-c, ...)
+	NoEnter    bool     `json:"noenter,omitempty"` // Don't press Enter after the input
+}
+
+// BroadcastResult is the outcome of sending to one pane.
+type BroadcastResult struct {
+	Pane    tmux.Pane `json:"pane"`
+	OK      bool      `json:"ok"`
+	Skipped bool      `json:"skipped,omitempty"` // Not sent: no agent in the window (agents_only)
+	Error   string    `json:"error,omitempty"`
+}
+
+// maxBroadcastPanes guards against a filter that accidentally matches everything.
+const maxBroadcastPanes = 50
+
+// handleAPIBroadcast handles POST /api/broadcast.
+func (s *Server) handleAPIBroadcast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Input == "" {
+		http.Error(w, "input is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Targets) == 0 && len(req.Tags) == 0 && req.Filter == "" {
+		http.Error(w, "targets, tags or filter is required", http.StatusBadRequest)
+		return
+	}
+
+	panes, skipped, err := s.broadcastPanes(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(panes) == 0 && len(skipped) == 0 {
+		http.Error(w, "no panes matched", http.StatusNotFound)
+		return
+	}
+	if len(panes) > maxBroadcastPanes {
+		http.Error(w, "too many panes matched", http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "broadcast", "panes", len(panes), "skipped", len(skipped), "input", req.Input, "special", req.Special)
+	auditDetail(r, req.Input)
+	results := append(s.broadcast(panes, req), skipped...)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
+// broadcastPanes resolves the request's targets, tags and filter into a
+// de-duplicated list of panes, explicit targets first. With agents_only,
+// panes in windows without an agent are returned as skipped results.
+func (s *Server) broadcastPanes(ctx context.Context, req BroadcastRequest) (panes []tmux.Pane, skipped []BroadcastResult, err error) {
+	if s.client(req.Host) == nil {
+		return nil, nil, fmt.Errorf("unknown host %q", req.Host)
+	}
+	filter, err := ParseFilter(req.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Windows by key, to select by tag or filter and to tell agent windows
+	var windows []WindowWithStatus
+	byKey := make(map[string]WindowWithStatus)
+	if len(tags) > 0 || req.Filter != "" || req.AgentsOnly {
+		windows = s.buildSessionsData(ctx, sessionsQuery{}).allWindows()
+		for _, w := range windows {
+			byKey[windowKey(w.Pane)] = w
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(p tmux.Pane) {
+		if seen[p.Key()] {
+			return
+		}
+		seen[p.Key()] = true
+		if w, ok := byKey[windowKey(p)]; req.AgentsOnly && (!ok || w.AgentType == agents.AgentGeneric) {
+			skipped = append(skipped, BroadcastResult{Pane: p, Skipped: true, Error: "no agent in this window"})
+			return
+		}
+		panes = append(panes, p)
+	}
+
+	for _, t := range req.Targets {
+		p := parseTarget(t)
+		p.Host = req.Host
+		add(p)
+	}
+	for _, w := range windows {
+		if len(tags) > 0 && (sessionsQuery{tags: tags}).matchWindow(w) || req.Filter != "" && filter.Match(w) {
+			add(w.Pane)
+		}
+	}
+	return panes, skipped, nil
+}
+
+// broadcast sends the input to all panes concurrently. Results keep the
+// order of panes.
+func (s *Server) broadcast(panes []tmux.Pane, req BroadcastRequest) []BroadcastResult {
+	results := make([]BroadcastResult, len(panes))
+	var wg sync.WaitGroup
+	for i, pane := range panes {
+		wg.Add(1)
+		go func(i int, pane tmux.Pane) {
+			defer wg.Done()
+			var err error
+			if req.Special {
+				var result SendResult
+				if result, err = s.sendKey(context.Background(), pane, req.Input); err == nil && result.Error != "" {
+					err = errors.New(result.Error)
+				}
+			} else {
+				err = s.sendText(context.Background(), pane, req.Input, !req.NoEnter)
+			}
+			results[i] = BroadcastResult{Pane: pane, OK: err == nil}
+			if err != nil {
+				slog.Warn("broadcast send failed", "pane", pane.Target(), "error", err)
+				results[i].Error = err.Error()
+				return
+			}
+			s.responses.Answered(windowKey(pane), time.Now())
+		}(i, pane)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   tmux.Pane
+	}{
+		{"work", tmux.Pane{Session: "work"}},
+		{"work:2", tmux.Pane{Session: "work", Window: 2}},
+		{"work:2.1", tmux.Pane{Session: "work", Window: 2, Index: 1}},
+		{"feat/login:0.3", tmux.Pane{Session: "feat/login", Window: 0, Index: 3}},
+	}
+
+	for _, tt := range tests {
+		if got := parseTarget(tt.target); got != tt.want {
+			t.Errorf("parseTarget(%q) = %+v, want %+v", tt.target, got, tt.want)
+		}
+	}
+}
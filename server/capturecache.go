@@ -0,0 +1,140 @@
+package server
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+// captureTTL is how long a pane capture is reused, so a sessions build and
+// an agent strip built alongside it share captures instead of each running
+// capture-pane.
+const captureTTL = time.Second
+
+// maxCapturedPanes bounds the cache; beyond it, panes not captured within
+// a minute are forgotten.
+const maxCapturedPanes = 1024
+
+// captureCache keeps each pane's last capture, and what findBestPane made
+// of it. The verdict outlives the capture: it's reused for as long as the
+// pane shows the same output, so a quiet pane isn't detected and parsed
+// again on every build. Only its agent is reused for an agent whose state
+// is read from files (see stateFromFiles), which change behind a still
+// screen. A nil *captureCache captures every time.
+type captureCache struct {
+	mu    sync.Mutex
+	panes map[string]*capturedPane
+}
+
+type capturedPane struct {
+	lines  int
+	output string
+	hash   uint64
+	at     time.Time
+
+	verdict *paneVerdict // Of the output with hash; nil until scored
+}
+
+// paneVerdict is the agent, state and score found in a pane's output,
+// valid while its command, directory and agent override are unchanged.
+type paneVerdict struct {
+	command, path string
+	override      agents.AgentType
+
+	agent  agents.Agent
+	result parser.Result
+	score  int
+}
+
+func newCaptureCache() *captureCache {
+	return &captureCache{panes: make(map[string]*capturedPane)}
+}
+
+// capture returns the last lines of pane, captured at most captureTTL ago,
+// and their hash.
+func (c *captureCache) capture(client *tmux.Client, pane tmux.Pane, lines int) (string, uint64, error) {
+	if c == nil {
+		output, err := client.CapturePane(pane, lines)
+		return output, hashOutput(output), err
+	}
+
+	key := pane.Key()
+	c.mu.Lock()
+	if p, ok := c.panes[key]; ok && p.lines == lines && time.Since(p.at) < captureTTL {
+		c.mu.Unlock()
+		return p.output, p.hash, nil
+	}
+	c.mu.Unlock()
+
+	output, err := client.CapturePane(pane, lines)
+	if err != nil {
+		return "", 0, err
+	}
+	hash := hashOutput(output)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.panes[key]
+	if !ok {
+		if len(c.panes) >= maxCapturedPanes {
+			c.pruneLocked()
+		}
+		p = &capturedPane{}
+		c.panes[key] = p
+	}
+	if p.hash != hash || p.lines != lines {
+		p.verdict = nil
+	}
+	p.lines, p.output, p.hash, p.at = lines, output, hash, time.Now()
+	return output, hash, nil
+}
+
+// verdict returns what was made of pane's output with hash, if the
+// output, command, directory and agent override are still the same.
+func (c *captureCache) verdict(key string, hash uint64, command, path string, override agents.AgentType) (*paneVerdict, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.panes[key]
+	if !ok || p.hash != hash || p.verdict == nil {
+		return nil, false
+	}
+	v := p.verdict
+	if v.command != command || v.path != path || v.override != override {
+		return nil, false
+	}
+	return v, true
+}
+
+// keep records the verdict on pane's output with hash.
+func (c *captureCache) keep(key string, hash uint64, v *paneVerdict) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.panes[key]; ok && p.hash == hash {
+		p.verdict = v
+	}
+}
+
+// pruneLocked forgets panes not captured within a minute. c.mu must be held.
+func (c *captureCache) pruneLocked() {
+	for key, p := range c.panes {
+		if time.Since(p.at) > time.Minute {
+			delete(c.panes, key)
+		}
+	}
+}
+
+func hashOutput(output string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(output))
+	return h.Sum64()
+}
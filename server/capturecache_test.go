@@ -0,0 +1,54 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestCaptureCacheVerdict(t *testing.T) {
+	c := newCaptureCache()
+	pane := tmux.Pane{Session: "work", Window: 1}
+	key := pane.Key()
+	hash := hashOutput("✻ Thinking…")
+	c.panes[key] = &capturedPane{lines: 100, output: "✻ Thinking…", hash: hash, at: time.Now()}
+
+	// A fresh capture is served from the cache, without tmux
+	if output, h, err := c.capture(nil, pane, 100); err != nil || output != "✻ Thinking…" || h != hash {
+		t.Fatalf("capture = %q, %v, %v", output, h, err)
+	}
+
+	if _, ok := c.verdict(key, hash, "claude", "/src", ""); ok {
+		t.Fatal("verdict before one was kept")
+	}
+	c.keep(key, hash, &paneVerdict{command: "claude", path: "/src", score: 50, result: parser.Result{Type: parser.TypeWorking}})
+	if v, ok := c.verdict(key, hash, "claude", "/src", ""); !ok || v.score != 50 {
+		t.Errorf("verdict = %+v, %v", v, ok)
+	}
+
+	tests := []struct {
+		name          string
+		hash          uint64
+		command, path string
+		override      agents.AgentType
+	}{
+		{"output changed", hashOutput("❯ "), "claude", "/src", ""},
+		{"command changed", hash, "zsh", "/src", ""},
+		{"directory changed", hash, "claude", "/other", ""},
+		{"agent overridden", hash, "claude", "/src", agents.AgentAmp},
+	}
+	for _, tt := range tests {
+		if _, ok := c.verdict(key, tt.hash, tt.command, tt.path, tt.override); ok {
+			t.Errorf("%s: verdict reused", tt.name)
+		}
+	}
+
+	// The verdict on older output isn't kept
+	c.keep(key, hashOutput("❯ "), &paneVerdict{command: "claude", path: "/src", score: 30})
+	if v, ok := c.verdict(key, hash, "claude", "/src", ""); !ok || v.score != 50 {
+		t.Errorf("verdict after a stale keep = %+v, %v", v, ok)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+// ChooseRequest is the body of POST /api/pane/{target}/choose.
+type ChooseRequest struct {
+	Index  int    `json:"index"`            // Position in the pane's choices (0-based)
+	Choice string `json:"choice,omitempty"` // Text the client saw at Index; refused if the menu changed since
+}
+
+// ChooseResult is the response of a successful choose.
+type ChooseResult struct {
+	Choice string   `json:"choice"`
+	Keys   []string `json:"keys"` // tmux keys sent
+}
+
+// handlePaneChoose serves POST /api/pane/{target}/choose: it picks one of
+// the choices the pane's agent shows with the keys that agent's selector
+// takes (Claude: the option's number, Amp: arrows and Enter). Choices are
+// read again before sending, so a stale index can't answer a different
+// prompt.
+func (s *Server) handlePaneChoose(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ChooseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	result, code, err := s.choose(r.Context(), pane, req)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	auditDetail(r, result.Choice)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// choose selects a choice in the pane, or returns an error with its HTTP
+// status.
+func (s *Server) choose(ctx context.Context, pane tmux.Pane, req ChooseRequest) (ChooseResult, int, error) {
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		return ChooseResult{}, http.StatusNotFound, fmt.Errorf("pane not found")
+	}
+
+	c := s.client(pane.Host).WithContext(ctx)
+	capture, err := c.CapturePaneWithMode(pane, 500)
+	if err != nil {
+		return ChooseResult{}, http.StatusInternalServerError, fmt.Errorf("failed to capture pane")
+	}
+	agent := s.registry.Detect(pane.Key(), info.Command, capture.Output)
+	result := getAgentState(ctx, agent, agentStatePath(pane.Host, info.Path), capture.Output)
+
+	choice, keys, code, err := chooseKeys(agent, result.Choices, capture.Output, req)
+	if err != nil {
+		return ChooseResult{}, code, err
+	}
+	for _, key := range keys {
+		if err := c.SendSpecialKey(pane, key); err != nil {
+			slog.Error("choose failed", "pane", pane.Target(), "choice", choice, "error", err)
+			return ChooseResult{}, http.StatusInternalServerError, fmt.Errorf("failed to send keys: %w", err)
+		}
+	}
+	s.responses.Answered(windowKey(pane), time.Now())
+	slog.Info("choice selected", "pane", pane.Target(), "choice", choice, "keys", keys)
+	return ChooseResult{Choice: choice, Keys: keys}, http.StatusOK, nil
+}
+
+// chooseKeys resolves a choose request against the pane's verified choices,
+// returning the chosen text and its keys, or an error with its HTTP status.
+func chooseKeys(agent agents.Agent, choices []string, output string, req ChooseRequest) (string, []string, int, error) {
+	if len(choices) == 0 {
+		return "", nil, http.StatusConflict, fmt.Errorf("no choices on screen")
+	}
+	if req.Index < 0 || req.Index >= len(choices) {
+		return "", nil, http.StatusBadRequest, fmt.Errorf("index must be 0-%d", len(choices)-1)
+	}
+	choice := choices[req.Index]
+	if req.Choice != "" && strings.TrimSpace(req.Choice) != strings.TrimSpace(choice) {
+		return "", nil, http.StatusConflict, fmt.Errorf("choices changed: option %d is now %q", req.Index, choice)
+	}
+	selector, ok := agent.(agents.ChoiceSelector)
+	if !ok {
+		return "", nil, http.StatusBadRequest, fmt.Errorf("%s choices can't be selected", agent.Type())
+	}
+	keys, ok := selector.ChoiceKeys(output, choice)
+	if !ok {
+		return "", nil, http.StatusConflict, fmt.Errorf("%q not found in the selector on screen", choice)
+	}
+	return choice, keys, http.StatusOK, nil
+}
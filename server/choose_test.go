@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/agents/generic"
+)
+
+func TestChooseKeys(t *testing.T) {
+	output := ` Do you want to proceed?
+ ❯ 1. Yes
+   2. No, and tell Claude what to do differently (esc)
+
+ Esc to cancel`
+	choices := []string{"Yes", "No, and tell Claude what to do differently (esc)"}
+
+	choice, keys, _, err := chooseKeys(claude.New(), choices, output, ChooseRequest{Index: 1})
+	if err != nil || choice != choices[1] || !slices.Equal(keys, []string{"2"}) {
+		t.Errorf("chooseKeys = %q, %v, %v; want option 2", choice, keys, err)
+	}
+
+	tests := []struct {
+		name    string
+		choices []string
+		req     ChooseRequest
+		code    int
+	}{
+		{"no choices", nil, ChooseRequest{}, http.StatusConflict},
+		{"out of range", choices, ChooseRequest{Index: 2}, http.StatusBadRequest},
+		{"negative", choices, ChooseRequest{Index: -1}, http.StatusBadRequest},
+		{"menu changed", choices, ChooseRequest{Index: 0, Choice: "No"}, http.StatusConflict},
+		{"not on screen", []string{"Yes", "Maybe"}, ChooseRequest{Index: 1}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if _, _, code, err := chooseKeys(claude.New(), tt.choices, output, tt.req); err == nil || code != tt.code {
+			t.Errorf("%s: status %d (%v), want %d", tt.name, code, err, tt.code)
+		}
+	}
+
+	if _, _, code, err := chooseKeys(generic.New(), choices, output, ChooseRequest{}); err == nil || code != http.StatusBadRequest {
+		t.Errorf("generic agent: status %d (%v), want 400", code, err)
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/tmux"
+)
+
+// ResumeRequest is the body of POST /api/pane/{target}/resume.
+type ResumeRequest struct {
+	SessionID string `json:"session_id"`    // From GET /api/claude/sessions
+	CWD       string `json:"cwd,omitempty"` // Project of the session (default: the pane's directory)
+}
+
+// ResumeResult is the command a resume typed into the pane.
+type ResumeResult struct {
+	Pane    tmux.Pane `json:"pane"`
+	Command string    `json:"command"`
+}
+
+// handleAPIClaudeSessions serves GET /api/claude/sessions?cwd=DIR: the
+// Claude Code conversations recorded for a project, most recent first.
+func (s *Server) handleAPIClaudeSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cwd := r.URL.Query().Get("cwd")
+	if cwd == "" {
+		http.Error(w, "cwd is required", http.StatusBadRequest)
+		return
+	}
+
+	sessions, err := claude.ListSessions(cwd)
+	if errors.Is(err, fs.ErrNotExist) {
+		sessions = []claude.SessionInfo{}
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list Claude sessions", "cwd", cwd, "error", err)
+		http.Error(w, "failed to list sessions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sessions)
+}
+
+// handlePaneResume starts `claude --resume <id>` in a pane sitting at a
+// shell, changing to the session's directory first when the pane is
+// elsewhere (Claude looks sessions up by directory).
+func (s *Server) handlePaneResume(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ResumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if pane.Host != "" {
+		// Sessions are looked up on local disk
+		http.Error(w, "resume is not available for remote panes", http.StatusBadRequest)
+		return
+	}
+
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	if !agents.IsShellCommand(info.Command) {
+		http.Error(w, "pane is running "+info.Command+", not a shell", http.StatusConflict)
+		return
+	}
+
+	cwd := req.CWD
+	if cwd == "" {
+		cwd = info.Path
+	}
+	if _, ok := claude.FindSession(cwd, req.SessionID); !ok {
+		http.Error(w, "session not found for "+cwd, http.StatusNotFound)
+		return
+	}
+
+	command := "claude --resume " + req.SessionID
+	if cwd != info.Path {
+		command = "cd " + shellQuote(cwd) + " && " + command
+	}
+	if err := s.sendText(r.Context(), pane, command, true); err != nil {
+		slog.ErrorContext(r.Context(), "resume failed", "pane", pane.Target(), "error", err)
+		http.Error(w, "failed to send command: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.registry.InvalidateCache(pane.Key())
+	slog.InfoContext(r.Context(), "resumed Claude session", "pane", pane.Target(), "session", req.SessionID)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ResumeResult{Pane: pane, Command: command})
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
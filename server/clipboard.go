@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// maxClipboard bounds text stored in a paste buffer through the API.
+const maxClipboard = 1 << 20
+
+// Clipboard is the response of GET /api/clipboard and the body of
+// POST /api/clipboard and /api/pane/{target}/paste.
+type Clipboard struct {
+	Text string `json:"text"`
+}
+
+// handleAPIClipboard serves GET /api/clipboard, the most recent tmux paste
+// buffer, and POST (or PUT) /api/clipboard, which stores text as a new paste
+// buffer. ?host= picks the tmux server, so text copied in the browser can
+// be pasted on any host.
+func (s *Server) handleAPIClipboard(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Query().Get("host")
+	c := s.client(host)
+	if c == nil {
+		http.Error(w, "unknown host", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		text, err := c.ShowBuffer()
+		if errors.Is(err, tmux.ErrNoBuffer) {
+			http.Error(w, "paste buffer is empty", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(r.Context(), "read paste buffer failed", "host", host, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(Clipboard{Text: text})
+
+	case http.MethodPost, http.MethodPut:
+		text, ok := decodeClipboard(w, r, false)
+		if !ok {
+			return
+		}
+		if err := c.SetBuffer(text); err != nil {
+			slog.ErrorContext(r.Context(), "set paste buffer failed", "host", host, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The text itself may be a secret; keep it out of the audit log
+		auditDetail(r, plural(len(text), "byte"))
+		slog.InfoContext(r.Context(), "paste buffer set", "host", host, "bytes", len(text))
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePanePaste serves POST /api/pane/{target}/paste, which pastes the
+// most recent paste buffer into the pane (bracketed, if the application
+// enables it). A body with text stores it as the buffer first.
+func (s *Server) handlePanePaste(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	text, ok := decodeClipboard(w, r, true)
+	if !ok {
+		return
+	}
+
+	c := s.client(pane.Host)
+	if text != "" {
+		if err := c.SetBuffer(text); err != nil {
+			slog.ErrorContext(r.Context(), "set paste buffer failed", "pane", pane.Target(), "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		auditDetail(r, plural(len(text), "byte"))
+	}
+	if err := c.PasteBuffer(pane); err != nil {
+		slog.ErrorContext(r.Context(), "paste buffer failed", "pane", pane.Target(), "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.responses.Answered(windowKey(pane), time.Now())
+	slog.InfoContext(r.Context(), "paste buffer pasted", "pane", pane.Target(), "bytes", len(text))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeClipboard reads a Clipboard body, answering bad ones itself. With
+// optional, an empty body (or text) is allowed.
+func decodeClipboard(w http.ResponseWriter, r *http.Request, optional bool) (string, bool) {
+	var req Clipboard
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxClipboard+1024)).Decode(&req)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, "text too large", http.StatusRequestEntityTooLarge)
+		return "", false
+	case err != nil && !(optional && errors.Is(err, io.EOF)):
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return "", false
+	case len(req.Text) > maxClipboard:
+		http.Error(w, "text too large", http.StatusRequestEntityTooLarge)
+		return "", false
+	case req.Text == "" && !optional:
+		http.Error(w, "text is required", http.StatusBadRequest)
+		return "", false
+	}
+	return req.Text, true
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeClipboard(t *testing.T) {
+	big := `{"text":"` + strings.Repeat("x", maxClipboard+1) + `"}`
+	tests := []struct {
+		name     string
+		body     string
+		optional bool
+		want     string
+		wantCode int // 0 when the body is accepted
+	}{
+		{"text", `{"text":"panic: boom\n"}`, false, "panic: boom\n", 0},
+		{"empty body", ``, false, "", http.StatusBadRequest},
+		{"empty text", `{"text":""}`, false, "", http.StatusBadRequest},
+		{"optional empty body", ``, true, "", 0},
+		{"optional empty text", `{}`, true, "", 0},
+		{"invalid", `{"text":`, true, "", http.StatusBadRequest},
+		{"too large", big, false, "", http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			got, ok := decodeClipboard(w, httptest.NewRequest("POST", "/api/clipboard", strings.NewReader(tt.body)), tt.optional)
+			if ok != (tt.wantCode == 0) {
+				t.Fatalf("ok = %v (%d %s)", ok, w.Code, w.Body)
+			}
+			if !ok && w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("text = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
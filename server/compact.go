@@ -0,0 +1,280 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/tmux"
+)
+
+// compactOptOutsDocument is the store document holding the panes excluded
+// from automatic compaction.
+const compactOptOutsDocument = "auto-compact-optouts"
+
+// compactionsLog is the store log holding every automatic compaction.
+const compactionsLog = "compactions"
+
+// compactInterval is how often Claude panes are checked for context usage
+// while the auto-compact rule is on.
+const compactInterval = 10 * time.Second
+
+// DefaultCompactCommand is sent when no other command is configured.
+const DefaultCompactCommand = "/compact"
+
+// Compaction is an audit record of one automatic compaction.
+type Compaction struct {
+	At             time.Time `json:"at"`
+	Pane           tmux.Pane `json:"pane"`
+	Path           string    `json:"path"`
+	ContextPercent float64   `json:"context_percent"`
+	Command        string    `json:"command"`
+}
+
+// AutoCompactSetting is the body of PUT /api/pane/{target}/auto-compact and
+// the response of all methods on that route.
+type AutoCompactSetting struct {
+	Enabled   bool   `json:"enabled"`           // The pane is not opted out
+	Threshold int    `json:"threshold"`         // Context percent that triggers it (0: rule off)
+	Command   string `json:"command,omitempty"` // Sent to the idle agent
+}
+
+// compactRule sends the compact command to idle Claude panes whose context
+// usage reached the threshold, once per crossing: a pane is compacted again
+// only after its usage has dropped below the threshold in between.
+type compactRule struct {
+	threshold int
+	command   string
+
+	mu     sync.Mutex
+	optOut map[string]bool // Pane key -> excluded
+	fired  map[string]bool // Pane key -> compacted since last below threshold
+}
+
+func newCompactRule(threshold int, command string) *compactRule {
+	if command == "" {
+		command = DefaultCompactCommand
+	}
+	return &compactRule{
+		threshold: threshold,
+		command:   command,
+		optOut:    make(map[string]bool),
+		fired:     make(map[string]bool),
+	}
+}
+
+func (c *compactRule) enabled() bool {
+	return c.threshold > 0
+}
+
+// decide reports whether to compact a pane now, given its context usage
+// and the agent state.
+func (c *compactRule) decide(paneKey string, percent float64, state queueState) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if percent < float64(c.threshold) {
+		delete(c.fired, paneKey)
+		return false
+	}
+	if c.optOut[paneKey] || c.fired[paneKey] || state != queueReady {
+		return false
+	}
+	c.fired[paneKey] = true
+	return true
+}
+
+// retry forgets a compaction that failed to send.
+func (c *compactRule) retry(paneKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.fired, paneKey)
+}
+
+func (c *compactRule) setOptOut(paneKey string, out bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if out {
+		c.optOut[paneKey] = true
+	} else {
+		delete(c.optOut, paneKey)
+	}
+}
+
+func (c *compactRule) optedOut(paneKey string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.optOut[paneKey]
+}
+
+func (c *compactRule) optOuts() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.optOut))
+	for key := range c.optOut {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadCompactOptOuts restores the panes excluded from automatic compaction.
+func (s *Server) loadCompactOptOuts() {
+	var keys []string
+	if err := s.store.Load(compactOptOutsDocument, &keys); err != nil {
+		slog.Warn("failed to load auto-compact opt-outs", "error", err)
+	}
+	for _, key := range keys {
+		s.compact.setOptOut(key, true)
+	}
+}
+
+// runAutoCompact compacts Claude panes whose context usage crossed the
+// threshold, once the agent is idle.
+func (s *Server) runAutoCompact(ctx context.Context) {
+	if !s.compact.enabled() {
+		return
+	}
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	ticker := time.NewTicker(compactInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.applyAutoCompact()
+	}
+}
+
+func (s *Server) applyAutoCompact() {
+	for _, sess := range s.listAllSessions(context.Background()) {
+		c := s.client(sess.Host)
+		for _, win := range sess.Windows {
+			for _, info := range win.Panes {
+				pane := tmux.Pane{Host: sess.Host, Session: sess.Name, Window: win.Index, Index: info.Index}
+				s.autoCompactPane(c, pane, info)
+			}
+		}
+	}
+}
+
+func (s *Server) autoCompactPane(c *tmux.Client, pane tmux.Pane, info tmux.PaneInfo) {
+	output, err := c.CapturePane(pane, 100)
+	if err != nil {
+		return
+	}
+	agent := s.registry.Detect(pane.Key(), info.Command, output)
+	if agent.Type() != agents.AgentClaudeCode {
+		return
+	}
+	status := claude.ParseStatus(claude.ExtractStatusLine(output))
+	if status.ContextPercent == 0 {
+		return
+	}
+	result := getAgentState(context.Background(), agent, agentStatePath(pane.Host, info.Path), output)
+	if !s.compact.decide(pane.Key(), status.ContextPercent, queueStateFor(result)) {
+		return
+	}
+
+	if err := s.sendPrompt(context.Background(), pane, s.compact.command); err != nil {
+		slog.Error("auto-compact failed", "pane", pane.Key(), "error", err)
+		s.compact.retry(pane.Key())
+		return
+	}
+	slog.Info("auto-compacted", "pane", pane.Key(), "context_percent", status.ContextPercent, "command", s.compact.command)
+
+	compaction := Compaction{
+		At:             time.Now(),
+		Pane:           pane,
+		Path:           info.Path,
+		ContextPercent: status.ContextPercent,
+		Command:        s.compact.command,
+	}
+	if err := s.store.Append(compactionsLog, compaction); err != nil {
+		slog.Warn("failed to record compaction", "error", err)
+	}
+	s.audit(AuditEntry{Action: "auto-compact", Target: pane.Key(), Detail: s.compact.command, Client: auditSelf})
+}
+
+// compactions returns recorded compactions at or after since, newest first.
+func (s *Server) compactions(since time.Time) ([]Compaction, error) {
+	result := []Compaction{}
+	err := s.store.ReadLog(compactionsLog, func(raw json.RawMessage) error {
+		var c Compaction
+		if err := json.Unmarshal(raw, &c); err != nil || c.At.Before(since) {
+			return nil
+		}
+		result = append(result, c)
+		return nil
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].At.After(result[j].At) })
+	return result, err
+}
+
+// handleAPIAutoCompactAudit serves GET /api/auto-compact/audit?since=RFC3339,
+// the automatic compaction log (default: the last 7 days).
+func (s *Server) handleAPIAutoCompactAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	since := time.Now().AddDate(0, 0, -defaultHistoryDays)
+	if v := r.URL.Query().Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "since must be RFC3339", http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+	compactions, err := s.compactions(since)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read compactions", "error", err)
+		http.Error(w, "failed to read compactions", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(compactions)
+}
+
+// handlePaneAutoCompact serves /api/pane/{target}/auto-compact: GET returns
+// whether the pane is compacted automatically, PUT {"enabled": false} opts
+// it out (true opts back in).
+func (s *Server) handlePaneAutoCompact(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req AutoCompactSetting
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		s.compact.setOptOut(pane.Key(), !req.Enabled)
+		if err := s.store.Save(compactOptOutsDocument, s.compact.optOuts()); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save auto-compact opt-outs", "error", err)
+			http.Error(w, "failed to save auto-compact setting", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(r.Context(), "auto-compact setting changed", "pane", pane.Key(), "enabled", req.Enabled)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(AutoCompactSetting{
+		Enabled:   !s.compact.optedOut(pane.Key()),
+		Threshold: s.compact.threshold,
+		Command:   s.compact.command,
+	})
+}
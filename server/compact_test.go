@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/store"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestCompactRuleDecide(t *testing.T) {
+	c := newCompactRule(80, "")
+	if c.command != DefaultCompactCommand {
+		t.Errorf("command = %q, want %q", c.command, DefaultCompactCommand)
+	}
+
+	steps := []struct {
+		name    string
+		percent float64
+		state   queueState
+		want    bool
+	}{
+		{"below threshold", 60, queueReady, false},
+		{"high but working", 85, queueWorking, false},
+		{"high and idle", 85, queueReady, true},
+		{"already compacted", 90, queueReady, false},
+		{"dropped after compact", 20, queueReady, false},
+		{"crossed again", 81, queueReady, true},
+	}
+	for _, step := range steps {
+		if got := c.decide("work:1.0", step.percent, step.state); got != step.want {
+			t.Errorf("%s: decide = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	c.setOptOut("api:1.0", true)
+	if c.decide("api:1.0", 95, queueReady) {
+		t.Error("opted-out pane was compacted")
+	}
+	c.setOptOut("api:1.0", false)
+	if !c.decide("api:1.0", 95, queueReady) {
+		t.Error("pane opted back in was not compacted")
+	}
+}
+
+func TestHandlePaneAutoCompact(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, compact: newCompactRule(85, "/compact focus on the plan")}
+	pane := tmux.Pane{Session: "work", Window: 1}
+
+	w := httptest.NewRecorder()
+	s.handlePaneAutoCompact(w, httptest.NewRequest("PUT", "/api/pane/work:1.0/auto-compact", strings.NewReader(`{"enabled":false}`)), pane)
+	var got AutoCompactSetting
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	want := AutoCompactSetting{Enabled: false, Threshold: 85, Command: "/compact focus on the plan"}
+	if got != want {
+		t.Errorf("setting = %+v, want %+v", got, want)
+	}
+
+	// The opt-out survives a restart
+	restarted := &Server{store: st, compact: newCompactRule(85, "")}
+	restarted.loadCompactOptOuts()
+	if !restarted.compact.optedOut(pane.Key()) {
+		t.Error("opt-out not restored from the store")
+	}
+}
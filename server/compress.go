@@ -0,0 +1,192 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"hash/fnv"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// minGzipSize is the smallest response worth compressing.
+const minGzipSize = 1024
+
+var gzipPool = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+// compressMiddleware gzips responses for clients that accept it and tags
+// complete responses with an ETag, answering a matching If-None-Match with
+// 304 Not Modified. Responses are buffered until the handler returns;
+// streams (SSE) switch to pass-through on their first Flush and are
+// gzipped as they go. WebSocket upgrades are left alone.
+func compressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, r: r, gzip: acceptsGzip(r)}
+		defer cw.finish()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// compressible reports whether a content type is text that gzip shrinks.
+func compressible(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/javascript",
+		mediaType == "application/x-ndjson", mediaType == "image/svg+xml":
+		return true
+	}
+	return false
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+	r    *http.Request
+	gzip bool // The client accepts gzip
+
+	status    int
+	buf       bytes.Buffer
+	streaming bool         // Flushed: writes go straight out
+	gz        *gzip.Writer // Gzipping a stream
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.streaming {
+		return
+	}
+	if cw.status == 0 {
+		cw.status = code
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	switch {
+	case cw.gz != nil:
+		return cw.gz.Write(p)
+	case cw.streaming:
+		return cw.ResponseWriter.Write(p)
+	default:
+		return cw.buf.Write(p)
+	}
+}
+
+// Flush switches to streaming: headers and what was buffered go out, and
+// so does everything written after.
+func (cw *compressWriter) Flush() {
+	if !cw.streaming {
+		cw.streaming = true
+		h := cw.Header()
+		if cw.gzip && compressible(h.Get("Content-Type")) && h.Get("Content-Encoding") == "" {
+			cw.startGzip()
+		}
+		cw.ResponseWriter.WriteHeader(statusOrOK(cw.status))
+		if cw.gz != nil {
+			cw.gz.Reset(cw.ResponseWriter)
+		}
+		if cw.buf.Len() > 0 {
+			_, _ = cw.Write(cw.buf.Bytes())
+			cw.buf.Reset()
+		}
+	}
+	if cw.gz != nil {
+		_ = cw.gz.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) startGzip() {
+	h := cw.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	cw.gz = gzipPool.Get().(*gzip.Writer)
+}
+
+// finish sends a buffered response, or ends a gzipped stream.
+func (cw *compressWriter) finish() {
+	if cw.streaming {
+		if cw.gz != nil {
+			_ = cw.gz.Close()
+			gzipPool.Put(cw.gz)
+		}
+		return
+	}
+
+	status := statusOrOK(cw.status)
+	body := cw.buf.Bytes()
+	h := cw.Header()
+	if cw.r.Method == http.MethodGet && status == http.StatusOK && h.Get("ETag") == "" {
+		sum := fnv.New64a()
+		_, _ = sum.Write(body)
+		etag := fmt.Sprintf(`W/"%x"`, sum.Sum64())
+		h.Set("ETag", etag)
+		if etagMatch(cw.r.Header.Get("If-None-Match"), etag) {
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			cw.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	if cw.gzip && status == http.StatusOK && len(body) >= minGzipSize && compressible(h.Get("Content-Type")) && h.Get("Content-Encoding") == "" {
+		cw.startGzip()
+		cw.ResponseWriter.WriteHeader(status)
+		cw.gz.Reset(cw.ResponseWriter)
+		_, _ = cw.gz.Write(body)
+		_ = cw.gz.Close()
+		gzipPool.Put(cw.gz)
+		return
+	}
+	cw.ResponseWriter.WriteHeader(status)
+	_, _ = cw.ResponseWriter.Write(body)
+}
+
+func statusOrOK(status int) int {
+	if status == 0 {
+		return http.StatusOK
+	}
+	return status
+}
+
+// etagMatch reports whether an If-None-Match header lists etag. Weak
+// comparison: W/ prefixes are ignored.
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
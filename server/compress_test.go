@@ -0,0 +1,92 @@
+package server
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressMiddleware(t *testing.T) {
+	big := `{"sessions":"` + strings.Repeat("abc", 1000) + `"}`
+	h := compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/small" {
+			_, _ = io.WriteString(w, `{}`)
+			return
+		}
+		_, _ = io.WriteString(w, big)
+	}))
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("/big", http.Header{"Accept-Encoding": {"br, gzip;q=0.8"}})
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", w.Header().Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != big {
+		t.Error("gzipped body does not decompress to the response")
+	}
+
+	if w := get("/big", nil); w.Header().Get("Content-Encoding") != "" || w.Body.String() != big {
+		t.Error("response gzipped for a client that didn't accept it")
+	}
+	if w := get("/small", http.Header{"Accept-Encoding": {"gzip"}}); w.Header().Get("Content-Encoding") != "" {
+		t.Error("small response gzipped")
+	}
+	if w := get("/big", http.Header{"Accept-Encoding": {"gzip;q=0"}}); w.Header().Get("Content-Encoding") != "" {
+		t.Error("gzip;q=0 not respected")
+	}
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	w = get("/big", http.Header{"If-None-Match": {etag}, "Accept-Encoding": {"gzip"}})
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("If-None-Match: status %d with %d bytes, want 304 without a body", w.Code, w.Body.Len())
+	}
+	if w := get("/small", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusOK {
+		t.Errorf("other content with a stale ETag: status %d, want 200", w.Code)
+	}
+}
+
+func TestCompressMiddlewareStream(t *testing.T) {
+	h := compressMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 3 {
+			_, _ = fmt.Fprintf(w, "data: %d\n\n", i)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	r := httptest.NewRequest("GET", "/api/sessions?stream=1", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("ETag") != "" {
+		t.Fatalf("stream headers = %v, want gzip without an ETag", w.Header())
+	}
+	zr, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(zr); string(body) != "data: 0\n\ndata: 1\n\ndata: 2\n\n" {
+		t.Errorf("stream = %q", body)
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// maxScrollRepeat bounds the lines or pages of one scroll request.
+const maxScrollRepeat = 10000
+
+// ScrollRequest is the body of POST /api/pane/{target}/scroll.
+type ScrollRequest struct {
+	Direction string `json:"direction"`       // up, down, top or bottom (back to the live output)
+	Lines     int    `json:"lines,omitempty"` // Lines to scroll up or down
+	Pages     int    `json:"pages,omitempty"` // Or pages (default: one page)
+}
+
+// scrollCommand returns the copy-mode command and its repeat count that
+// carry out req. Scrolling to the bottom leaves copy-mode instead, and has
+// no command.
+func scrollCommand(req ScrollRequest) (command string, repeat int, err error) {
+	if req.Lines < 0 || req.Pages < 0 || req.Lines > maxScrollRepeat || req.Pages > maxScrollRepeat {
+		return "", 0, errors.New("lines and pages must be between 0 and 10000")
+	}
+	if req.Lines > 0 && req.Pages > 0 {
+		return "", 0, errors.New("set lines or pages, not both")
+	}
+	switch req.Direction {
+	case "up", "down":
+		if req.Lines > 0 {
+			return "scroll-" + req.Direction, req.Lines, nil
+		}
+		return "page-" + req.Direction, max(req.Pages, 1), nil
+	case "top":
+		return "history-top", 1, nil
+	case "bottom":
+		return "", 0, nil
+	}
+	return "", 0, errors.New("direction must be up, down, top or bottom")
+}
+
+// handlePaneCopyMode serves /api/pane/{target}/copy-mode: GET returns the
+// pane's tmux mode and scroll position, POST puts it in copy-mode and
+// DELETE takes it out of any mode, back to the live output.
+func (s *Server) handlePaneCopyMode(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	c := s.client(pane.Host).WithContext(r.Context())
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		err = c.EnterCopyMode(pane)
+	case http.MethodDelete:
+		err = c.ExitMode(pane)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "copy-mode failed", "pane", pane.Target(), "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Show the pane's streams where it's scrolled to now
+	s.paneService.refresh(pane)
+	s.writeModeState(w, r, c, pane)
+}
+
+// handlePaneScroll handles POST /api/pane/{target}/scroll: scroll the
+// pane's history in copy-mode, entering it first when scrolling up.
+func (s *Server) handlePaneScroll(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req ScrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	command, repeat, err := scrollCommand(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	c := s.client(pane.Host).WithContext(r.Context())
+	state, err := c.PaneModeState(pane)
+	if err != nil {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	switch {
+	case state.InMode && state.Mode != "copy-mode":
+		http.Error(w, "pane is in "+state.Mode, http.StatusConflict)
+		return
+	case command == "":
+		if state.InMode {
+			err = c.ExitMode(pane)
+		}
+	case !state.InMode && req.Direction == "down":
+		// Already at the bottom
+	default:
+		if !state.InMode {
+			err = c.EnterCopyMode(pane)
+		}
+		if err == nil {
+			err = c.CopyModeCommand(pane, command, repeat)
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "scroll failed", "pane", pane.Target(), "direction", req.Direction, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Show the pane's streams where it's scrolled to now
+	s.paneService.refresh(pane)
+	s.writeModeState(w, r, c, pane)
+}
+
+func (s *Server) writeModeState(w http.ResponseWriter, r *http.Request, c *tmux.Client, pane tmux.Pane) {
+	state, err := c.PaneModeState(pane)
+	if err != nil {
+		slog.WarnContext(r.Context(), "pane mode lookup failed", "pane", pane.Target(), "error", err)
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,34 @@
+package server
+
+import "testing"
+
+func TestScrollCommand(t *testing.T) {
+	tests := []struct {
+		req     ScrollRequest
+		command string
+		repeat  int
+	}{
+		{ScrollRequest{Direction: "up"}, "page-up", 1},
+		{ScrollRequest{Direction: "up", Lines: 10}, "scroll-up", 10},
+		{ScrollRequest{Direction: "down", Pages: 3}, "page-down", 3},
+		{ScrollRequest{Direction: "top"}, "history-top", 1},
+		{ScrollRequest{Direction: "bottom"}, "", 0},
+	}
+	for _, tt := range tests {
+		command, repeat, err := scrollCommand(tt.req)
+		if err != nil || command != tt.command || repeat != tt.repeat {
+			t.Errorf("scrollCommand(%+v) = %q, %d, %v; want %q, %d", tt.req, command, repeat, err, tt.command, tt.repeat)
+		}
+	}
+
+	for _, req := range []ScrollRequest{
+		{Direction: "left"},
+		{Direction: "up", Lines: -1},
+		{Direction: "up", Lines: 2, Pages: 1},
+		{Direction: "down", Pages: maxScrollRepeat + 1},
+	} {
+		if _, _, err := scrollCommand(req); err == nil {
+			t.Errorf("scrollCommand(%+v) accepted", req)
+		}
+	}
+}
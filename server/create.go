@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// CreateSessionRequest is the body of POST /api/sessions.
+type CreateSessionRequest struct {
+	Name    string `json:"name"`
+	Dir     string `json:"dir,omitempty"`     // Start directory (default: tmux's default)
+	Command string `json:"command,omitempty"` // Command for the first window (default: shell)
+	Host    string `json:"host,omitempty"`    // Remote host (default: local)
+}
+
+// CreateWindowRequest is the body of POST /api/sessions/{name}/windows.
+type CreateWindowRequest struct {
+	Name    string `json:"name,omitempty"`
+	Dir     string `json:"dir,omitempty"`
+	Command string `json:"command,omitempty"`
+}
+
+// CreateWorktreeRequest is the body of POST /api/worktrees: create a git
+// worktree, open a session in it, and launch an agent there.
+type CreateWorktreeRequest struct {
+	Repo    string `json:"repo"`              // Any directory inside the repository
+	Branch  string `json:"branch"`            // Created from Base if it doesn't exist
+	Base    string `json:"base,omitempty"`    // Start point for a new branch (default: HEAD)
+	Path    string `json:"path,omitempty"`    // Worktree directory (default: <repo>-<branch> next to the repo)
+	Session string `json:"session,omitempty"` // Session name (default: branch)
+	Command string `json:"command,omitempty"` // Agent to launch (default: claude)
+	Host    string `json:"host,omitempty"`
+}
+
+// CreatedPane is returned when a session, window or worktree is created.
+type CreatedPane struct {
+	Pane     tmux.Pane `json:"pane"`
+	Worktree string    `json:"worktree,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+}
+
+const defaultWorktreeCommand = "claude"
+
+// validSessionName reports whether tmux accepts name as a session name
+// (tmux reserves ':' and '.' for targets).
+func validSessionName(name string) bool {
+	return name != "" && len(name) <= 100 && !strings.ContainsAny(name, ":.") && strings.TrimSpace(name) == name
+}
+
+// sessionNameForBranch derives a session name from a branch name.
+func sessionNameForBranch(branch string) string {
+	return strings.NewReplacer(":", "-", ".", "-").Replace(branch)
+}
+
+// defaultWorktreePath places a worktree next to the repository root,
+// e.g. /src/app + feat/login -> /src/app-feat-login.
+func defaultWorktreePath(root, branch string) string {
+	name := strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(branch)
+	return filepath.Join(filepath.Dir(root), filepath.Base(root)+"-"+name)
+}
+
+// handleAPICreateSession handles POST /api/sessions.
+func (s *Server) handleAPICreateSession(w http.ResponseWriter, r *http.Request) {
+	var req CreateSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !validSessionName(req.Name) {
+		http.Error(w, "invalid session name", http.StatusBadRequest)
+		return
+	}
+	c := s.client(req.Host)
+	if c == nil {
+		http.Error(w, "unknown host", http.StatusNotFound)
+		return
+	}
+	if c.HasSession(req.Name) {
+		http.Error(w, "session already exists", http.StatusConflict)
+		return
+	}
+
+	if err := c.NewSession(req.Name, req.Dir, req.Command); err != nil {
+		slog.ErrorContext(r.Context(), "create session failed", "session", req.Name, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "session created", "session", req.Name, "host", req.Host, "dir", req.Dir, "command", req.Command)
+	writeCreated(w, CreatedPane{Pane: tmux.Pane{Host: req.Host, Session: req.Name}})
+}
+
+// handleAPISession routes /api/sessions/{name}/... actions.
+func (s *Server) handleAPISession(w http.ResponseWriter, r *http.Request) {
+	// Split on the last slash: session names may contain "/" (sent as %2F)
+	rest := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
+	var name, action string
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		name, action = rest[:i], rest[i+1:]
+	}
+
+	switch {
+	case action == "windows" && r.Method == http.MethodPost:
+		s.handleAPICreateWindow(w, r, name)
+	case action == "windows":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	case action == "pin" || action == "hide":
+		s.handleSessionMark(w, r, name, action)
+	case action == "timeline":
+		s.handleSessionTimeline(w, r, name)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleAPICreateWindow handles POST /api/sessions/{name}/windows.
+func (s *Server) handleAPICreateWindow(w http.ResponseWriter, r *http.Request, session string) {
+	var req CreateWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	host := r.URL.Query().Get("host")
+	c := s.client(host)
+	if c == nil {
+		http.Error(w, "unknown host", http.StatusNotFound)
+		return
+	}
+	if !c.HasSession(session) {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	idx, err := c.NewWindow(session, req.Name, req.Dir, req.Command)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "create window failed", "session", session, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "window created", "session", session, "window", idx, "host", host, "command", req.Command)
+	writeCreated(w, CreatedPane{Pane: tmux.Pane{Host: host, Session: session, Window: idx}})
+}
+
+// handleAPIWorktrees handles POST /api/worktrees.
+func (s *Server) handleAPIWorktrees(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CreateWorktreeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Repo == "" || req.Branch == "" {
+		http.Error(w, "repo and branch are required", http.StatusBadRequest)
+		return
+	}
+	c := s.client(req.Host)
+	if c == nil {
+		http.Error(w, "unknown host", http.StatusNotFound)
+		return
+	}
+
+	if req.Session == "" {
+		req.Session = sessionNameForBranch(req.Branch)
+	}
+	if !validSessionName(req.Session) {
+		http.Error(w, "invalid session name", http.StatusBadRequest)
+		return
+	}
+	if c.HasSession(req.Session) {
+		http.Error(w, "session already exists", http.StatusConflict)
+		return
+	}
+	if req.Command == "" {
+		req.Command = defaultWorktreeCommand
+	}
+
+	root, err := c.RepoRoot(req.Repo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Path == "" {
+		req.Path = defaultWorktreePath(root, req.Branch)
+	}
+
+	if err := c.AddWorktree(root, req.Path, req.Branch, req.Base); err != nil {
+		slog.ErrorContext(r.Context(), "create worktree failed", "repo", root, "branch", req.Branch, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := c.NewSession(req.Session, req.Path, req.Command); err != nil {
+		slog.ErrorContext(r.Context(), "create worktree session failed", "session", req.Session, "error", err)
+		http.Error(w, fmt.Sprintf("worktree created at %s but %v", req.Path, err), http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "worktree workspace created", "repo", root, "branch", req.Branch, "path", req.Path, "session", req.Session, "command", req.Command)
+	writeCreated(w, CreatedPane{
+		Pane:     tmux.Pane{Host: req.Host, Session: req.Session},
+		Worktree: req.Path,
+		Branch:   req.Branch,
+	})
+}
+
+func writeCreated(w http.ResponseWriter, result CreatedPane) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,46 @@
+package server
+
+import "testing"
+
+func TestValidSessionName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"work", true},
+		{"feat/login", true},
+		{"", false},
+		{"v1.2", false},
+		{"a:b", false},
+		{" padded", false},
+	}
+
+	for _, tt := range tests {
+		if got := validSessionName(tt.name); got != tt.want {
+			t.Errorf("validSessionName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultWorktreePath(t *testing.T) {
+	tests := []struct {
+		root   string
+		branch string
+		want   string
+	}{
+		{"/src/app", "fix-login", "/src/app-fix-login"},
+		{"/src/app", "feat/login", "/src/app-feat-login"},
+	}
+
+	for _, tt := range tests {
+		if got := defaultWorktreePath(tt.root, tt.branch); got != tt.want {
+			t.Errorf("defaultWorktreePath(%q, %q) = %q, want %q", tt.root, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestSessionNameForBranch(t *testing.T) {
+	if got := sessionNameForBranch("release/v1.2"); got != "release/v1-2" {
+		t.Errorf("sessionNameForBranch(%q) = %q, want %q", "release/v1.2", got, "release/v1-2")
+	}
+}
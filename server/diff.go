@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/noamsto/houston/internal/gitdiff"
+	"github.com/noamsto/houston/tmux"
+)
+
+// maxDiffBytes bounds the patch parsed for /api/pane/{target}/diff; files
+// past it are left out.
+const maxDiffBytes = 1 << 20
+
+// PaneDiff is the response of GET /api/pane/{target}/diff: the uncommitted
+// changes in the repository of the pane's working directory.
+type PaneDiff struct {
+	Pane      tmux.Pane      `json:"pane"`
+	Dir       string         `json:"dir"`    // The pane's working directory
+	Repo      string         `json:"repo"`   // Top level of its repository; paths are relative to it
+	Staged    bool           `json:"staged"` // Only changes staged for the next commit
+	Stat      string         `json:"stat"`   // git diff --stat
+	Files     []gitdiff.File `json:"files"`
+	Untracked []string       `json:"untracked"`           // New files git doesn't track yet, not in Files
+	Truncated bool           `json:"truncated,omitempty"` // Files past maxDiffBytes of patch were left out
+}
+
+// handlePaneDiff serves GET /api/pane/{target}/diff?staged=1, so what an
+// agent changed can be reviewed before answering it.
+func (s *Server) handlePaneDiff(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var staged bool
+	if v := r.URL.Query().Get("staged"); v != "" {
+		var err error
+		if staged, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "staged must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	c := s.client(pane.Host)
+	repo, err := c.RepoRoot(info.Path)
+	if info.Path == "" || err != nil {
+		http.Error(w, "pane directory is not in a git repository", http.StatusNotFound)
+		return
+	}
+
+	stat, patch, err := c.Diff(info.Path, staged)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "pane diff failed", "pane", pane.Target(), "dir", info.Path, "error", err)
+		http.Error(w, "git diff failed", http.StatusInternalServerError)
+		return
+	}
+	untracked, err := c.Untracked(info.Path)
+	if err != nil {
+		slog.WarnContext(r.Context(), "listing untracked files failed", "pane", pane.Target(), "dir", info.Path, "error", err)
+	}
+
+	result := PaneDiff{Pane: pane, Dir: info.Path, Repo: repo, Staged: staged, Stat: strings.TrimRight(stat, "\n"), Untracked: untracked}
+	if len(patch) > maxDiffBytes {
+		// Cut before the file that crosses the limit
+		patch, result.Truncated = patch[:strings.LastIndex(patch[:maxDiffBytes], "\ndiff --git ")+1], true
+	}
+	result.Files = gitdiff.Parse(patch)
+	if result.Untracked == nil {
+		result.Untracked = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/history"
+)
+
+// exportTable is a history export as header plus rows, written as CSV or
+// as a JSON array of the underlying records.
+type exportTable struct {
+	header  []string
+	rows    [][]string
+	records any
+}
+
+// parseDateRange turns inclusive YYYY-MM-DD bounds (local time) into a
+// half-open [since, until) range. Missing bounds default to the last
+// defaultHistoryDays days.
+func parseDateRange(from, to string, now time.Time) (since, until time.Time, err error) {
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+
+	until = today.AddDate(0, 0, 1)
+	if to != "" {
+		t, err := time.ParseInLocation(time.DateOnly, to, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("to must be YYYY-MM-DD")
+		}
+		until = t.AddDate(0, 0, 1)
+	}
+
+	since = until.AddDate(0, 0, -defaultHistoryDays)
+	if from != "" {
+		t, err := time.ParseInLocation(time.DateOnly, from, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("from must be YYYY-MM-DD")
+		}
+		since = t
+	}
+
+	if !since.Before(until) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+	if until.Sub(since) > maxHistoryDays*24*time.Hour+time.Hour { // Allow for a DST shift
+		return time.Time{}, time.Time{}, fmt.Errorf("range must be at most %d days", maxHistoryDays)
+	}
+	return since, until, nil
+}
+
+// handleAPIHistoryExport serves GET /api/history/export: recorded history
+// as a spreadsheet-friendly CSV (or JSON with format=json).
+//
+//	kind=transitions  agent window state changes (default)
+//	kind=responses    answered prompts and how long they waited
+//	kind=usage        Claude token usage and cost per session, day, model and branch
+//
+// from/to (YYYY-MM-DD, inclusive) select the date range; session and branch
+// filter rows where the kind records them.
+func (s *Server) handleAPIHistoryExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		http.Error(w, "format must be csv or json", http.StatusBadRequest)
+		return
+	}
+	kind := q.Get("kind")
+	if kind == "" {
+		kind = "transitions"
+	}
+
+	since, until, err := parseDateRange(q.Get("from"), q.Get("to"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	session, branch := q.Get("session"), q.Get("branch")
+
+	var table exportTable
+	switch kind {
+	case "transitions":
+		table, err = s.exportTransitions(since, until, session, branch)
+	case "responses":
+		if branch != "" {
+			http.Error(w, "branch filter is not available for responses", http.StatusBadRequest)
+			return
+		}
+		table, err = s.exportResponses(since, until, session)
+	case "usage":
+		if session != "" {
+			http.Error(w, "session filter is not available for usage", http.StatusBadRequest)
+			return
+		}
+		table, err = exportUsage(since, until, branch)
+	default:
+		http.Error(w, "kind must be transitions, responses or usage", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "history export failed", "kind", kind, "error", err)
+		http.Error(w, "failed to read history", http.StatusInternalServerError)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(table.records)
+		return
+	}
+
+	filename := fmt.Sprintf("houston-%s-%s-%s.csv", kind,
+		since.Format(time.DateOnly), until.AddDate(0, 0, -1).Format(time.DateOnly))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(table.header)
+	_ = cw.WriteAll(table.rows)
+}
+
+func (s *Server) exportTransitions(since, until time.Time, session, branch string) (exportTable, error) {
+	transitions, err := s.transitions.Transitions(since, until)
+	if err != nil {
+		return exportTable{}, err
+	}
+	records := []history.Transition{}
+	table := exportTable{header: []string{"time", "session", "window", "branch", "agent", "from", "to", "duration_seconds"}}
+	for _, t := range transitions {
+		if (session != "" && t.Session != session) || (branch != "" && t.Branch != branch) {
+			continue
+		}
+		records = append(records, t)
+		table.rows = append(table.rows, []string{
+			t.At.Local().Format(time.RFC3339), t.Session, t.Window, t.Branch, t.Agent, t.From, t.To, formatSeconds(t.Duration),
+		})
+	}
+	table.records = records
+	return table, nil
+}
+
+func (s *Server) exportResponses(since, until time.Time, session string) (exportTable, error) {
+	responses, err := s.responses.Responses(since)
+	if err != nil {
+		return exportTable{}, err
+	}
+	records := []history.Response{}
+	table := exportTable{header: []string{"started", "answered", "session", "window", "kind", "via", "wait_seconds"}}
+	for _, resp := range responses {
+		if !resp.Answered.Before(until) || (session != "" && resp.Session != session) {
+			continue
+		}
+		records = append(records, resp)
+		table.rows = append(table.rows, []string{
+			resp.Started.Local().Format(time.RFC3339), resp.Answered.Local().Format(time.RFC3339),
+			resp.Session, resp.Window, resp.Kind, resp.Via, formatSeconds(resp.Wait().Seconds()),
+		})
+	}
+	table.records = records
+	return table, nil
+}
+
+func exportUsage(since, until time.Time, branch string) (exportTable, error) {
+	usage, err := claude.UsageBetween(claude.ProjectsDir(), since, until)
+	if err != nil {
+		return exportTable{}, err
+	}
+	records := []claude.UsageRecord{}
+	table := exportTable{header: []string{
+		"date", "session_id", "cwd", "branch", "model", "messages",
+		"input_tokens", "output_tokens", "cache_creation_input_tokens", "cache_read_input_tokens", "cost_usd",
+	}}
+	for _, u := range usage {
+		if branch != "" && u.Branch != branch {
+			continue
+		}
+		records = append(records, u)
+		cost := "" // Unknown for models without a price
+		if u.Cost != nil {
+			cost = strconv.FormatFloat(*u.Cost, 'f', 4, 64)
+		}
+		table.rows = append(table.rows, []string{
+			u.Date, u.SessionID, u.CWD, u.Branch, u.Model, strconv.Itoa(u.Messages),
+			strconv.Itoa(u.InputTokens), strconv.Itoa(u.OutputTokens),
+			strconv.Itoa(u.CacheCreationInputTokens), strconv.Itoa(u.CacheReadInputTokens), cost,
+		})
+	}
+	table.records = records
+	return table, nil
+}
+
+func formatSeconds(v float64) string {
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	loc := time.FixedZone("test", 2*60*60)
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, loc)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, loc) }
+
+	tests := []struct {
+		from, to             string
+		wantSince, wantUntil time.Time
+		wantErr              bool
+	}{
+		{"", "", day(4), day(11), false},
+		{"2026-03-01", "", day(1), day(11), false},
+		{"2026-03-01", "2026-03-05", day(1), day(6), false},
+		{"", "2026-03-05", time.Date(2026, 2, 27, 0, 0, 0, 0, loc), day(6), false},
+		{"2026-03-05", "2026-03-05", day(5), day(6), false},
+		{"2026-03-06", "2026-03-05", time.Time{}, time.Time{}, true},
+		{"03/01/2026", "", time.Time{}, time.Time{}, true},
+		{"2024-01-01", "", time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		since, until, err := parseDateRange(tt.from, tt.to, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDateRange(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+			continue
+		}
+		if !since.Equal(tt.wantSince) || !until.Equal(tt.wantUntil) {
+			t.Errorf("parseDateRange(%q, %q) = %v, %v, want %v, %v", tt.from, tt.to, since, until, tt.wantSince, tt.wantUntil)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// DefaultFileUploadMax is the -file-upload-max default: the total size, in
+// bytes, of the files one /api/pane/{target}/upload request may carry.
+const DefaultFileUploadMax = 25 << 20
+
+// fileUploadMemory is how much of an upload is buffered in memory; the
+// rest goes to temporary files until every file has been checked.
+const fileUploadMemory = 8 << 20
+
+// UploadedFile is a file written by /api/pane/{target}/upload.
+type UploadedFile struct {
+	Name string `json:"name"` // As sent
+	Path string `json:"path"` // Where it was written, on the pane's host
+	Size int64  `json:"size"`
+}
+
+// fileUploadPolicy is what /api/pane/{target}/upload accepts.
+type fileUploadPolicy struct {
+	maxSize int64    // Total bytes per request; zero disables uploads
+	exts    []string // Allowed extensions, lowercase with the dot; empty: any
+}
+
+func newFileUploadPolicy(maxSize int64, exts []string) fileUploadPolicy {
+	p := fileUploadPolicy{maxSize: maxSize}
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if ext != "" {
+			p.exts = append(p.exts, ext)
+		}
+	}
+	return p
+}
+
+// check returns why files named names with a total of size bytes can't be
+// uploaded, or nil.
+func (p fileUploadPolicy) check(names []string, size int64) error {
+	if size > p.maxSize {
+		return fmt.Errorf("files are larger than %d MiB in total", p.maxSize>>20)
+	}
+	for _, name := range names {
+		safe := safeFileName(name)
+		if safe == "" {
+			return fmt.Errorf("invalid file name %q", name)
+		}
+		if len(p.exts) > 0 && !slices.Contains(p.exts, strings.ToLower(filepath.Ext(safe))) {
+			return fmt.Errorf("%s: only %s files can be uploaded", name, strings.Join(p.exts, ", "))
+		}
+	}
+	return nil
+}
+
+// handlePaneUpload serves POST /api/pane/{target}/upload: multipart/form-data
+// with one or more "file" parts, written into the pane's working directory.
+// Existing files are never replaced; a number is added to the name instead.
+// With a "prompt" field, the written paths and the prompt are then sent to
+// the pane as one line, like send-with-images.
+func (s *Server) handlePaneUpload(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.fileUploads.maxSize <= 0 {
+		http.Error(w, "file uploads are disabled", http.StatusForbidden)
+		return
+	}
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	if info.Path == "" {
+		http.Error(w, "pane has no working directory", http.StatusConflict)
+		return
+	}
+
+	// Leave room for the multipart framing and the prompt
+	r.Body = http.MaxBytesReader(w, r.Body, s.fileUploads.maxSize+1<<20)
+	if err := r.ParseMultipartForm(fileUploadMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, fmt.Sprintf("files are larger than %d MiB in total", s.fileUploads.maxSize>>20), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "expected multipart/form-data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer func() { _ = r.MultipartForm.RemoveAll() }()
+
+	files := r.MultipartForm.File["file"]
+	if len(files) == 0 {
+		http.Error(w, "no files provided", http.StatusBadRequest)
+		return
+	}
+	var names []string
+	var size int64
+	for _, fh := range files {
+		names = append(names, fh.Filename)
+		size += fh.Size
+	}
+	if err := s.fileUploads.check(names, size); err != nil {
+		status := http.StatusUnsupportedMediaType
+		if size > s.fileUploads.maxSize {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	c := s.client(pane.Host)
+	written := make([]UploadedFile, 0, len(files))
+	paths := make([]string, 0, len(files))
+	for _, fh := range files {
+		f, err := fh.Open()
+		if err != nil {
+			http.Error(w, "failed to read upload", http.StatusInternalServerError)
+			return
+		}
+		path, err := c.CreateFile(filepath.Join(info.Path, safeFileName(fh.Filename)), f)
+		_ = f.Close()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "upload to pane failed", "pane", pane.Target(), "file", fh.Filename, "error", err, "written", paths)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		written = append(written, UploadedFile{Name: fh.Filename, Path: path, Size: fh.Size})
+		paths = append(paths, path)
+	}
+	slog.InfoContext(r.Context(), "files uploaded to pane", "pane", pane.Target(), "paths", paths)
+
+	detail := strings.Join(paths, " ")
+	if prompt := strings.TrimSpace(r.FormValue("prompt")); prompt != "" {
+		detail += " " + prompt
+		if err := s.sendText(r.Context(), pane, detail, true); err != nil {
+			auditDetail(r, detail)
+			slog.ErrorContext(r.Context(), "send upload prompt failed", "pane", pane.Target(), "error", err)
+			http.Error(w, "files uploaded, but sending the prompt failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.responses.Answered(windowKey(pane), time.Now())
+	}
+	auditDetail(r, detail)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(written)
+}
@@ -0,0 +1,32 @@
+package server
+
+import "testing"
+
+func TestFileUploadPolicy(t *testing.T) {
+	p := newFileUploadPolicy(1<<20, []string{"csv", ".JSON", " "})
+	if len(p.exts) != 2 || p.exts[0] != ".csv" || p.exts[1] != ".json" {
+		t.Fatalf("exts = %q", p.exts)
+	}
+	tests := []struct {
+		names []string
+		size  int64
+		ok    bool
+	}{
+		{[]string{"data.csv"}, 100, true},
+		{[]string{"Data.CSV", "schema.json"}, 1 << 20, true},
+		{[]string{"data.csv"}, 1<<20 + 1, false},
+		{[]string{"run.sh"}, 10, false},
+		{[]string{"data.csv", "notes"}, 10, false},
+		{[]string{"..."}, 10, false},
+	}
+	for _, tt := range tests {
+		if err := p.check(tt.names, tt.size); (err == nil) != tt.ok {
+			t.Errorf("check(%q, %d) = %v, want ok %v", tt.names, tt.size, err, tt.ok)
+		}
+	}
+
+	any := newFileUploadPolicy(1<<20, nil)
+	if err := any.check([]string{"Makefile", "run.sh"}, 10); err != nil {
+		t.Errorf("check() without extensions = %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// FocusResult is the response of POST /api/pane/{target}/focus.
+type FocusResult struct {
+	Client     string `json:"client"`                // tty of the tmux client that was switched
+	Raised     bool   `json:"raised"`                // Terminal window was brought to the front
+	RaiseError string `json:"raise_error,omitempty"` // Why raising failed; the switch still happened
+}
+
+// pickClient returns the client to focus: the one with tty, or the most
+// recently active one when tty is empty.
+func pickClient(clients []tmux.AttachedClient, tty string) (tmux.AttachedClient, bool) {
+	for _, cl := range clients {
+		if tty == "" || cl.TTY == tty {
+			return cl, true
+		}
+	}
+	return tmux.AttachedClient{}, false
+}
+
+// handlePaneFocus serves POST /api/pane/{target}/focus: the tmux client
+// attached on the pane's host switches to the pane, so the terminal on the
+// desk follows what was tapped on the phone. ?client= picks a client by tty
+// (default: the most recently active one) and raise=false leaves the
+// terminal window where it is. Only local panes can raise a terminal.
+func (s *Server) handlePaneFocus(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	c := s.client(pane.Host)
+	clients, err := c.ListClients()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "list clients failed", "host", pane.Host, "error", err)
+		http.Error(w, "failed to list tmux clients", http.StatusInternalServerError)
+		return
+	}
+	tty := r.URL.Query().Get("client")
+	target, ok := pickClient(clients, tty)
+	if !ok {
+		if tty != "" {
+			http.Error(w, "client not attached", http.StatusNotFound)
+		} else {
+			http.Error(w, "no tmux client attached", http.StatusConflict)
+		}
+		return
+	}
+
+	slog.InfoContext(r.Context(), "focus pane", "pane", pane.Key(), "client", target.TTY)
+	if err := c.FocusPane(target.TTY, pane); err != nil {
+		slog.ErrorContext(r.Context(), "focus pane failed", "pane", pane.Key(), "error", err)
+		http.Error(w, "failed to focus pane: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := FocusResult{Client: target.TTY}
+	raise, err := strconv.ParseBool(r.URL.Query().Get("raise"))
+	if err != nil {
+		raise = true
+	}
+	if raise && pane.Host == "" && s.raiser != nil {
+		if err := s.raiser.Raise(); err != nil {
+			slog.WarnContext(r.Context(), "raise terminal failed", "terminal", s.raiser.Name(), "error", err)
+			result.RaiseError = err.Error()
+		} else {
+			result.Raised = true
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestPickClient(t *testing.T) {
+	clients := []tmux.AttachedClient{{TTY: "/dev/pts/2"}, {TTY: "/dev/pts/5"}}
+	tests := []struct {
+		tty    string
+		want   string
+		wantOK bool
+	}{
+		{"", "/dev/pts/2", true}, // Most recently active
+		{"/dev/pts/5", "/dev/pts/5", true},
+		{"/dev/pts/9", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := pickClient(clients, tt.tty)
+		if ok != tt.wantOK || got.TTY != tt.want {
+			t.Errorf("pickClient(%q) = %q, %v, want %q, %v", tt.tty, got.TTY, ok, tt.want, tt.wantOK)
+		}
+	}
+	if _, ok := pickClient(nil, ""); ok {
+		t.Error("pickClient(nil) found a client")
+	}
+}
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// gitConfirmTTL is how long a commit or push preview's token stays valid.
+const gitConfirmTTL = 2 * time.Minute
+
+// Git actions.
+const (
+	gitCommit = "commit"
+	gitPush   = "push"
+)
+
+// GitRequest is the body of POST /api/pane/{target}/commit and /push.
+type GitRequest struct {
+	Message string `json:"message"` // Commit message; ignored by push
+	Token   string `json:"token"`   // From the GitPreview, to run it
+}
+
+// GitPreview is the response of POST /api/pane/{target}/commit or /push
+// without a token: what would run, and the token confirming it.
+type GitPreview struct {
+	Action    string        `json:"action"` // commit or push
+	Token     string        `json:"token"`
+	Expires   time.Time     `json:"expires"`
+	Repo      string        `json:"repo"`
+	Message   string        `json:"message,omitempty"`   // commit: the commit message
+	Stat      string        `json:"stat,omitempty"`      // commit: git diff --stat of everything git add -A stages
+	Untracked []string      `json:"untracked,omitempty"` // commit: new files it adds
+	Branch    string        `json:"branch,omitempty"`    // push
+	Upstream  string        `json:"upstream,omitempty"`  // push
+	Commits   []tmux.Commit `json:"commits,omitempty"`   // push: commits the upstream doesn't have, newest first
+}
+
+// GitResult is the response of a confirmed commit or push.
+type GitResult struct {
+	Action string `json:"action"`
+	Repo   string `json:"repo"`
+	Commit string `json:"commit,omitempty"` // commit: the new commit's hash
+	Output string `json:"output"`           // What git printed
+}
+
+// gitConfirmation is an issued token: the action it confirms and the state
+// of the repository it was previewed against.
+type gitConfirmation struct {
+	pane        string
+	action      string
+	message     string
+	fingerprint string
+	expires     time.Time
+}
+
+// gitConfirmations holds outstanding tokens. Each is used at most once.
+type gitConfirmations struct {
+	mu     sync.Mutex
+	tokens map[string]gitConfirmation
+}
+
+func newGitConfirmations() *gitConfirmations {
+	return &gitConfirmations{tokens: make(map[string]gitConfirmation)}
+}
+
+// issue stores c under a new token and returns the token.
+func (g *gitConfirmations) issue(c gitConfirmation) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	for token, old := range g.tokens {
+		if now.After(old.expires) {
+			delete(g.tokens, token)
+		}
+	}
+	token := newPromptID() + newPromptID()
+	g.tokens[token] = c
+	return token
+}
+
+// take removes token and returns its confirmation, if it exists and hasn't
+// expired.
+func (g *gitConfirmations) take(token string) (gitConfirmation, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c, ok := g.tokens[token]
+	delete(g.tokens, token)
+	if !ok || time.Now().After(c.expires) {
+		return gitConfirmation{}, false
+	}
+	return c, true
+}
+
+// gitState is what a commit or push would act on, and a fingerprint of it
+// so a confirmation can tell whether it changed since the preview.
+type gitState struct {
+	preview     GitPreview
+	fingerprint string
+}
+
+// gitActionState inspects the repository at dir for action. An error
+// return is meant for the client: the action can't be done.
+func gitActionState(c *tmux.Client, action, dir string) (gitState, error) {
+	var st gitState
+	h := sha256.New()
+	switch action {
+	case gitCommit:
+		stat, patch, err := c.Diff(dir, false)
+		if err != nil {
+			return st, fmt.Errorf("git diff failed: %w", err)
+		}
+		untracked, err := c.Untracked(dir)
+		if err != nil {
+			return st, fmt.Errorf("listing untracked files failed: %w", err)
+		}
+		if patch == "" && len(untracked) == 0 {
+			return st, fmt.Errorf("nothing to commit")
+		}
+		// git add -A takes the untracked files' contents too, not just
+		// their names
+		blobs, err := c.HashUntracked(dir, untracked)
+		if err != nil {
+			return st, fmt.Errorf("reading untracked files failed: %w", err)
+		}
+		st.preview.Stat = strings.TrimRight(stat, "\n")
+		st.preview.Untracked = untracked
+		fmt.Fprintf(h, "%s", patch)
+		for i, f := range untracked {
+			fmt.Fprintf(h, "\x00%s\x00%s", f, blobs[i])
+		}
+	case gitPush:
+		branch, upstream, ahead, err := c.Upstream(dir)
+		if err != nil {
+			return st, err
+		}
+		if len(ahead) == 0 {
+			return st, fmt.Errorf("%s is up to date with %s", branch, upstream)
+		}
+		st.preview.Branch, st.preview.Upstream, st.preview.Commits = branch, upstream, ahead
+		fmt.Fprintf(h, "%s\x00%s", branch, upstream)
+		for _, commit := range ahead {
+			fmt.Fprintf(h, "\x00%s", commit.Hash)
+		}
+	}
+	st.fingerprint = hex.EncodeToString(h.Sum(nil))
+	return st, nil
+}
+
+// handlePaneGit serves POST /api/pane/{target}/commit and /push, which run
+// git add -A && git commit -m <message>, or git push, in the repository of
+// the pane's working directory. git runs directly, not through the pane.
+//
+// Each takes two requests. Without a token the response is a GitPreview of
+// what would be committed or pushed, with a token; posting the same request
+// with that token within two minutes runs it. If the changes (or the
+// commits to push) differ from the preview by then, the request fails with
+// 409 and has to be previewed again.
+func (s *Server) handlePaneGit(w http.ResponseWriter, r *http.Request, pane tmux.Pane, action string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req GitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if action == gitCommit && req.Message == "" {
+		http.Error(w, "message is required", http.StatusBadRequest)
+		return
+	}
+	if action == gitPush {
+		req.Message = ""
+	}
+
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	c := s.client(pane.Host)
+	repo, err := c.RepoRoot(info.Path)
+	if info.Path == "" || err != nil {
+		http.Error(w, "pane directory is not in a git repository", http.StatusNotFound)
+		return
+	}
+	st, err := gitActionState(c, action, info.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	if req.Token == "" {
+		preview := st.preview
+		preview.Action, preview.Repo, preview.Message = action, repo, req.Message
+		preview.Expires = time.Now().Add(gitConfirmTTL)
+		preview.Token = s.gitConfirms.issue(gitConfirmation{
+			pane:        pane.Key(),
+			action:      action,
+			message:     req.Message,
+			fingerprint: st.fingerprint,
+			expires:     preview.Expires,
+		})
+		auditDetail(r, "preview")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(preview)
+		return
+	}
+
+	confirm, ok := s.gitConfirms.take(req.Token)
+	if !ok || confirm.pane != pane.Key() || confirm.action != action || confirm.message != req.Message {
+		http.Error(w, "invalid or expired token", http.StatusForbidden)
+		return
+	}
+	if confirm.fingerprint != st.fingerprint {
+		http.Error(w, "repository changed since the preview; preview again", http.StatusConflict)
+		return
+	}
+
+	result := GitResult{Action: action, Repo: repo}
+	switch action {
+	case gitCommit:
+		result.Commit, result.Output, err = c.CommitAll(info.Path, req.Message)
+		auditDetail(r, req.Message)
+	case gitPush:
+		result.Output, err = c.Push(info.Path)
+		auditDetail(r, fmt.Sprintf("%s to %s (%s)", st.preview.Branch, st.preview.Upstream, plural(len(st.preview.Commits), "commit")))
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "git "+action+" failed", "pane", pane.Target(), "dir", info.Path, "error", err, "output", result.Output)
+		http.Error(w, err.Error()+"\n"+result.Output, http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "git "+action, "pane", pane.Target(), "repo", repo, "commit", result.Commit)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
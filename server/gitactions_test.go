@@ -0,0 +1,68 @@
+package server
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestGitConfirmations(t *testing.T) {
+	g := newGitConfirmations()
+	token := g.issue(gitConfirmation{pane: "main:0.0", action: gitCommit, expires: time.Now().Add(time.Minute)})
+	if c, ok := g.take(token); !ok || c.pane != "main:0.0" {
+		t.Fatalf("take() = %+v, %v", c, ok)
+	}
+	if _, ok := g.take(token); ok {
+		t.Error("take() reused a token")
+	}
+	expired := g.issue(gitConfirmation{expires: time.Now().Add(-time.Second)})
+	if _, ok := g.take(expired); ok {
+		t.Error("take() accepted an expired token")
+	}
+}
+
+func TestGitActionState(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	c := tmux.NewClient()
+	if _, err := gitActionState(c, gitCommit, dir); err == nil {
+		t.Error("gitActionState(commit) with no changes: want error")
+	}
+	if _, err := gitActionState(c, gitPush, dir); err == nil {
+		t.Error("gitActionState(push) without upstream: want error")
+	}
+
+	if err := os.WriteFile(dir+"/a.txt", []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := gitActionState(c, gitCommit, dir)
+	if err != nil || len(before.preview.Untracked) != 1 {
+		t.Fatalf("gitActionState(commit) = %+v, %v", before, err)
+	}
+	// Edited after the preview, the untracked file would commit other content
+	if err := os.WriteFile(dir+"/a.txt", []byte("b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := gitActionState(c, gitCommit, dir)
+	if err != nil || edited.fingerprint == before.fingerprint {
+		t.Errorf("fingerprint unchanged after an untracked file was edited: %v", err)
+	}
+	if again, err := gitActionState(c, gitCommit, dir); err != nil || again.fingerprint != edited.fingerprint {
+		t.Errorf("fingerprint changed with nothing edited: %v", err)
+	}
+	if err := exec.Command("git", "-C", dir, "add", "a.txt").Run(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := gitActionState(c, gitCommit, dir)
+	if err != nil || after.fingerprint == edited.fingerprint {
+		t.Errorf("fingerprint unchanged after the changes moved: %v", err)
+	}
+}
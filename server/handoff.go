@@ -0,0 +1,239 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/opencode"
+	"github.com/noamsto/houston/tmux"
+)
+
+// HandoffRequest asks houston to continue a conversation in another agent.
+type HandoffRequest struct {
+	To           string `json:"to"`                     // "opencode", "claude", or "amp"
+	Server       string `json:"server,omitempty"`       // OpenCode server URL (default: match by project dir)
+	Session      string `json:"session,omitempty"`      // tmux session for the new window (default: source session)
+	Instructions string `json:"instructions,omitempty"` // Appended as the next step for the new agent
+	Entries      int    `json:"entries,omitempty"`      // Recent transcript entries to include (default 30)
+}
+
+// HandoffResult describes where the conversation was handed off to.
+type HandoffResult struct {
+	To              string     `json:"to"`
+	Pane            *tmux.Pane `json:"pane,omitempty"`             // New tmux pane (claude/amp)
+	OpenCodeServer  string     `json:"opencode_server,omitempty"`  // OpenCode server URL
+	OpenCodeSession string     `json:"opencode_session,omitempty"` // New OpenCode session ID
+	ContextFile     string     `json:"context_file,omitempty"`     // Handoff prompt file read by tmux agents
+}
+
+// handoffCommands are the commands used to launch tmux-based agents.
+var handoffCommands = map[string]string{
+	"claude": "claude",
+	"amp":    "amp",
+}
+
+const defaultHandoffEntries = 30
+
+// handlePaneHandoff hands the conversation of a tmux agent pane to another agent.
+func (s *Server) handlePaneHandoff(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req HandoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if pane.Host != "" {
+		// Transcripts and the handoff file are read from local disk
+		http.Error(w, "handoff is not available for remote panes", http.StatusBadRequest)
+		return
+	}
+
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok || info.Path == "" {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	output, _ := s.client(pane.Host).CapturePane(pane, 100)
+	agent := s.registry.Detect(pane.Key(), info.Command, output)
+	provider, ok := agent.(agents.TranscriptProvider)
+	if !ok {
+		http.Error(w, "no conversation to hand off in "+string(agent.Type())+" pane", http.StatusBadRequest)
+		return
+	}
+	transcript, err := provider.Transcript(info.Path)
+	if err != nil {
+		http.Error(w, "transcript not found: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	if transcript.CWD == "" {
+		transcript.CWD = info.Path
+	}
+	if req.Session == "" {
+		req.Session = pane.Session
+	}
+
+	s.deliverHandoff(w, r.Context(), req, transcript)
+}
+
+// handleOpenCodeHandoff hands an OpenCode session's conversation to another agent.
+func (s *Server) handleOpenCodeHandoff(w http.ResponseWriter, r *http.Request, serverURL, sessionID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req HandoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.To != "opencode" && req.Session == "" {
+		http.Error(w, "session is required when handing off to a tmux agent", http.StatusBadRequest)
+		return
+	}
+
+	transcript, err := s.ocManager.GetTranscript(r.Context(), serverURL, sessionID, 100)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read OpenCode conversation", "error", err)
+		http.Error(w, "failed to read session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.deliverHandoff(w, r.Context(), req, transcript)
+}
+
+func (s *Server) deliverHandoff(w http.ResponseWriter, ctx context.Context, req HandoffRequest, transcript *agents.Transcript) {
+	entries := req.Entries
+	if entries <= 0 {
+		entries = defaultHandoffEntries
+	}
+	prompt := transcript.HandoffPrompt(entries, req.Instructions)
+
+	var result HandoffResult
+	var err error
+	switch req.To {
+	case "opencode":
+		result, err = s.handoffToOpenCode(ctx, req, transcript, prompt)
+	case "claude", "amp":
+		result, err = s.handoffToTmux(req, transcript, prompt)
+	default:
+		http.Error(w, "to must be opencode, claude or amp", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("handoff failed", "from", transcript.Agent, "to", req.To, "error", err)
+		http.Error(w, "handoff failed: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	slog.Info("handoff", "from", transcript.Agent, "to", req.To, "opencode_session", result.OpenCodeSession, "context_file", result.ContextFile)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) handoffToOpenCode(ctx context.Context, req HandoffRequest, transcript *agents.Transcript, prompt string) (HandoffResult, error) {
+	if s.ocManager == nil {
+		return HandoffResult{}, fmt.Errorf("OpenCode integration not enabled")
+	}
+
+	serverURL := req.Server
+	if serverURL == "" {
+		servers := s.ocDiscovery.GetServers()
+		for _, srv := range servers {
+			if srv.Project != nil && srv.Project.Path == transcript.CWD {
+				serverURL = srv.URL
+				break
+			}
+		}
+		if serverURL == "" && len(servers) == 1 {
+			serverURL = servers[0].URL
+		}
+		if serverURL == "" {
+			return HandoffResult{}, fmt.Errorf("no OpenCode server for %s (pass server)", transcript.CWD)
+		}
+	}
+
+	title := "Handoff from " + string(transcript.Agent)
+	if transcript.Title != "" {
+		title = transcript.Title
+	}
+	session, err := s.ocManager.CreateSession(ctx, serverURL, opencode.NewSession{Title: title, Prompt: prompt})
+	if err != nil {
+		return HandoffResult{}, err
+	}
+
+	return HandoffResult{To: req.To, OpenCodeServer: serverURL, OpenCodeSession: session.ID}, nil
+}
+
+// handoffToTmux writes the prompt to a file, opens a new window running the
+// agent, and tells it to read the file once it has started. Multi-line prompts
+// can't be typed reliably into agent TUIs, so the file carries the context.
+func (s *Server) handoffToTmux(req HandoffRequest, transcript *agents.Transcript, prompt string) (HandoffResult, error) {
+	f, err := os.CreateTemp("", "houston-handoff-*.md")
+	if err != nil {
+		return HandoffResult{}, err
+	}
+	if _, err := f.WriteString(prompt); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return HandoffResult{}, err
+	}
+	_ = f.Close()
+	contextFile := f.Name()
+
+	// Clean up after a day (the agent reads it right away)
+	time.AfterFunc(24*time.Hour, func() { _ = os.Remove(contextFile) })
+
+	windowIdx, err := s.tmux.NewWindow(req.Session, req.To, transcript.CWD, handoffCommands[req.To])
+	if err != nil {
+		_ = os.Remove(contextFile)
+		return HandoffResult{}, err
+	}
+	pane := tmux.Pane{Session: req.Session, Window: windowIdx}
+
+	message := fmt.Sprintf("Read %s for context handed off from %s, then continue the work described there.", contextFile, transcript.Agent)
+	go s.sendWhenAgentReady(pane, message)
+
+	return HandoffResult{To: req.To, Pane: &pane, ContextFile: contextFile}, nil
+}
+
+// sendWhenAgentReady waits for an agent to start in a fresh pane, then types
+// the message. Gives up waiting after a timeout and sends anyway.
+func (s *Server) sendWhenAgentReady(pane tmux.Pane, message string) {
+	deadline := time.Now().Add(20 * time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(500 * time.Millisecond)
+
+		info, ok := s.lookupPaneInfo(pane)
+		if !ok {
+			slog.Warn("handoff pane disappeared", "pane", pane.Target())
+			return
+		}
+		output, err := s.client(pane.Host).CapturePane(pane, 50)
+		if err != nil {
+			continue
+		}
+		s.registry.InvalidateCache(pane.Key())
+		if s.registry.Detect(pane.Key(), info.Command, output).Type() != agents.AgentGeneric {
+			// Give the TUI a moment to finish drawing its input box
+			time.Sleep(time.Second)
+			break
+		}
+	}
+
+	if err := s.sendText(context.Background(), pane, message, true); err != nil {
+		slog.Error("handoff send failed", "pane", pane.Target(), "error", err)
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/noamsto/houston/history"
+)
+
+const (
+	defaultHistoryDays = 7
+	maxHistoryDays     = 365
+	longestResponses   = 10
+)
+
+// handleAPIResponseTimes serves GET /api/history/response-times?days=N:
+// per-day median time to answer agent prompts, the slowest answers, and
+// prompts waiting right now.
+func (s *Server) handleAPIResponseTimes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days := defaultHistoryDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistoryDays {
+			http.Error(w, "days must be between 1 and 365", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	now := time.Now()
+	year, month, day := now.Date()
+	since := time.Date(year, month, day, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(days - 1))
+
+	responses, err := s.responses.Responses(since)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read response history", "error", err)
+		http.Error(w, "failed to read history", http.StatusInternalServerError)
+		return
+	}
+
+	stats := history.SummarizeResponses(responses, longestResponses)
+	stats.Pending = s.responses.Pending(now)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/noamsto/houston/status"
+)
+
+// maxHookBody limits hook payloads; tool inputs can carry whole file contents.
+const maxHookBody = 1 << 20
+
+// handleAPIHookClaude receives Claude Code hook events over HTTP, as an
+// alternative to hook scripts writing status files into --status-dir.
+// The tmux session comes from ?session= or the payload's session field.
+func (s *Server) handleAPIHookClaude(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxHookBody))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	st, err := status.ParseHookPayload(body, r.URL.Query().Get("session"))
+	if errors.Is(err, status.ErrIgnoredHook) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.watcher.Update(st)
+	slog.DebugContext(r.Context(), "hook status", "session", st.Session, "status", st.Status, "tool", st.Tool)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+// LaunchRequest is the body of POST /api/launch: start an agent in a new
+// window, or in an idle one with Reuse.
+type LaunchRequest struct {
+	Agent   string `json:"agent,omitempty"`   // claude, amp or opencode (default: claude, unless Command is set)
+	Command string `json:"command,omitempty"` // Custom command to run instead of an agent
+	Dir     string `json:"dir,omitempty"`     // Working directory (default: tmux's default)
+	Prompt  string `json:"prompt,omitempty"`  // Initial prompt
+	Session string `json:"session,omitempty"` // Created if missing (default: Dir's base name)
+	Name    string `json:"name,omitempty"`    // Window name (default: the agent)
+	Reuse   bool   `json:"reuse,omitempty"`   // Type into a window of Session that sits at a shell, if there is one
+	Host    string `json:"host,omitempty"`
+}
+
+// LaunchResult is where a launch started its command.
+type LaunchResult struct {
+	Pane    tmux.Pane `json:"pane"`
+	Command string    `json:"command"`
+	Reused  bool      `json:"reused,omitempty"`  // Typed into an idle window rather than a new one
+	Queued  bool      `json:"queued,omitempty"`  // Prompt is queued until the agent is ready
+	Created bool      `json:"created,omitempty"` // The session was created
+}
+
+// launchAgent is how an agent is started with an initial prompt: as an
+// argument after promptFlag (a bare argument when promptFlag is empty),
+// or, when promptArg is false, typed in once the agent is ready.
+type launchAgent struct {
+	command    string
+	promptArg  bool
+	promptFlag string
+}
+
+const defaultLaunchAgent = "claude"
+
+var launchAgents = map[string]launchAgent{
+	"claude":   {command: "claude", promptArg: true},
+	"opencode": {command: "opencode", promptArg: true, promptFlag: "--prompt"},
+	"amp":      {command: "amp"},
+}
+
+// launchCommand returns the command line starting req's agent or custom
+// command, and whether its prompt must be typed in rather than passed on
+// the command line.
+func launchCommand(req LaunchRequest) (command string, typePrompt bool, ok bool) {
+	if req.Command != "" {
+		return req.Command, req.Prompt != "", true
+	}
+	name := req.Agent
+	if name == "" {
+		name = defaultLaunchAgent
+	}
+	agent, ok := launchAgents[name]
+	if !ok {
+		return "", false, false
+	}
+	command = agent.command
+	switch {
+	case req.Prompt == "":
+	case !agent.promptArg:
+		typePrompt = true
+	case agent.promptFlag != "":
+		command += " " + agent.promptFlag + " " + shellQuote(req.Prompt)
+	default:
+		command += " " + shellQuote(req.Prompt)
+	}
+	return command, typePrompt, true
+}
+
+// launchNames lists the agents POST /api/launch starts by name.
+func launchNames() []string {
+	names := make([]string, 0, len(launchAgents))
+	for name := range launchAgents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// idleWindow picks a window of session to reuse: one with a single pane
+// sitting at a shell, preferring one already in dir.
+func idleWindow(session tmux.SessionTree, dir string) (tmux.WindowTree, bool) {
+	var idle []tmux.WindowTree
+	for _, w := range session.Windows {
+		if len(w.Panes) == 1 && agents.IsShellCommand(w.Panes[0].Command) {
+			idle = append(idle, w)
+		}
+	}
+	if len(idle) == 0 {
+		return tmux.WindowTree{}, false
+	}
+	if i := slices.IndexFunc(idle, func(w tmux.WindowTree) bool { return w.Panes[0].Path == dir }); i >= 0 {
+		return idle[i], true
+	}
+	return idle[0], true
+}
+
+// findSessionTree returns session from the client's tree.
+func findSessionTree(c *tmux.Client, session string) (tmux.SessionTree, bool) {
+	tree, err := c.ListTree()
+	if err != nil {
+		return tmux.SessionTree{}, false
+	}
+	i := slices.IndexFunc(tree, func(t tmux.SessionTree) bool { return t.Name == session })
+	if i < 0 {
+		return tmux.SessionTree{}, false
+	}
+	return tree[i], true
+}
+
+// handleAPILaunch handles POST /api/launch.
+func (s *Server) handleAPILaunch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req LaunchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Agent != "" && req.Command != "" {
+		http.Error(w, "agent and command are exclusive", http.StatusBadRequest)
+		return
+	}
+	command, typePrompt, ok := launchCommand(req)
+	if !ok {
+		http.Error(w, "unknown agent "+req.Agent+"; want "+strings.Join(launchNames(), ", ")+" or a command", http.StatusBadRequest)
+		return
+	}
+	if req.Session == "" && req.Dir != "" {
+		req.Session = sessionNameForBranch(filepath.Base(filepath.Clean(req.Dir)))
+	}
+	if !validSessionName(req.Session) {
+		http.Error(w, "invalid session name", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" {
+		req.Name = strings.Fields(command)[0]
+	}
+	c := s.client(req.Host)
+	if c == nil {
+		http.Error(w, "unknown host", http.StatusNotFound)
+		return
+	}
+
+	result := LaunchResult{Command: command, Queued: typePrompt}
+	session, exists := findSessionTree(c, req.Session)
+	var window tmux.WindowTree
+	switch {
+	case !exists:
+		if err := c.NewSession(req.Session, req.Dir, command); err != nil {
+			slog.ErrorContext(r.Context(), "launch failed", "session", req.Session, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.Created = true
+	case req.Reuse:
+		window, result.Reused = idleWindow(session, req.Dir)
+	}
+
+	if result.Reused {
+		pane := window.Panes[0]
+		result.Pane = tmux.Pane{Host: req.Host, Session: req.Session, Window: window.Index, Index: pane.Index}
+		typed := command
+		if req.Dir != "" && req.Dir != pane.Path {
+			typed = "cd " + shellQuote(req.Dir) + " && " + command
+		}
+		if err := s.sendText(r.Context(), result.Pane, typed, true); err != nil {
+			slog.ErrorContext(r.Context(), "launch failed", "pane", result.Pane.Target(), "error", err)
+			http.Error(w, "failed to send command: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		result.Command = typed
+		s.registry.InvalidateCache(result.Pane.Key())
+	} else {
+		index := -1
+		if exists {
+			idx, err := c.NewWindow(req.Session, req.Name, req.Dir, command)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "launch failed", "session", req.Session, "error", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			index = idx
+		}
+		// Look up the pane tmux made, whose indexes follow base-index
+		result.Pane = tmux.Pane{Host: req.Host, Session: req.Session, Window: max(index, 0)}
+		if session, ok := findSessionTree(c, req.Session); ok {
+			for _, win := range session.Windows {
+				if (index < 0 || win.Index == index) && len(win.Panes) > 0 {
+					result.Pane.Window, result.Pane.Index = win.Index, win.Panes[0].Index
+					break
+				}
+			}
+		}
+	}
+
+	if typePrompt {
+		s.queues.launch(result.Pane, req.Prompt, time.Now())
+		s.savePromptQueues()
+	}
+	auditDetail(r, result.Command)
+	slog.InfoContext(r.Context(), "agent launched", "pane", result.Pane.Key(), "command", command, "reused", result.Reused)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Reused {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
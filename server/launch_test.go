@@ -0,0 +1,52 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestLaunchCommand(t *testing.T) {
+	tests := []struct {
+		req        LaunchRequest
+		want       string
+		typePrompt bool
+		ok         bool
+	}{
+		{LaunchRequest{}, "claude", false, true},
+		{LaunchRequest{Agent: "claude", Prompt: "it's broken"}, `claude 'it'\''s broken'`, false, true},
+		{LaunchRequest{Agent: "opencode", Prompt: "fix"}, "opencode --prompt 'fix'", false, true},
+		{LaunchRequest{Agent: "amp", Prompt: "fix"}, "amp", true, true},
+		{LaunchRequest{Command: "aider --yes", Prompt: "fix"}, "aider --yes", true, true},
+		{LaunchRequest{Command: "make watch"}, "make watch", false, true},
+		{LaunchRequest{Agent: "cursor"}, "", false, false},
+	}
+	for _, tt := range tests {
+		got, typePrompt, ok := launchCommand(tt.req)
+		if got != tt.want || typePrompt != tt.typePrompt || ok != tt.ok {
+			t.Errorf("launchCommand(%+v) = %q, %v, %v; want %q, %v, %v", tt.req, got, typePrompt, ok, tt.want, tt.typePrompt, tt.ok)
+		}
+	}
+}
+
+func TestIdleWindow(t *testing.T) {
+	window := func(index int, panes ...tmux.PaneInfo) tmux.WindowTree {
+		return tmux.WindowTree{Window: tmux.Window{Index: index}, Panes: panes}
+	}
+	session := tmux.SessionTree{Windows: []tmux.WindowTree{
+		window(1, tmux.PaneInfo{Command: "claude", Path: "/src/app"}),
+		window(2, tmux.PaneInfo{Command: "zsh", Path: "/src/api"}, tmux.PaneInfo{Command: "zsh", Path: "/src/app"}),
+		window(3, tmux.PaneInfo{Command: "bash", Path: "/tmp"}),
+		window(4, tmux.PaneInfo{Index: 1, Command: "zsh", Path: "/src/app"}),
+	}}
+
+	if w, ok := idleWindow(session, "/src/app"); !ok || w.Index != 4 {
+		t.Errorf("idleWindow(/src/app) = %d, %v; want the shell in /src/app (4)", w.Index, ok)
+	}
+	if w, ok := idleWindow(session, "/elsewhere"); !ok || w.Index != 3 {
+		t.Errorf("idleWindow(/elsewhere) = %d, %v; want the first idle window (3)", w.Index, ok)
+	}
+	if _, ok := idleWindow(tmux.SessionTree{Windows: session.Windows[:2]}, ""); ok {
+		t.Error("idleWindow picked an agent or split window")
+	}
+}
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// macrosDocument is the store document holding keystroke macros.
+const macrosDocument = "macros"
+
+const (
+	// maxMacroSteps and maxMacroDelay bound a macro, since it holds the
+	// request open while it plays.
+	maxMacroSteps = 64
+	maxMacroDelay = 10 * time.Second
+)
+
+// Macro is a named sequence of keystrokes, e.g. Escape, Escape, "/clear",
+// Enter to reset a stuck agent UI.
+type Macro struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Steps       []MacroStep `json:"steps"`
+}
+
+// MacroStep is one step of a macro; exactly one field is set.
+type MacroStep struct {
+	Text    string `json:"text,omitempty"`     // Literal text, typed as is
+	Key     string `json:"key,omitempty"`      // tmux key name: Enter, Escape, C-c, Up, ...
+	DelayMS int    `json:"delay_ms,omitempty"` // Pause before the next step
+}
+
+// RunMacroRequest is the body of POST /api/pane/{target}/macro.
+type RunMacroRequest struct {
+	Name string `json:"name"`
+}
+
+// keyNamePattern accepts tmux key names with modifiers (C-c, M-Left, F5)
+// and rejects anything tmux would type as text.
+var keyNamePattern = regexp.MustCompile(`^(?:[CMS]-)*[A-Za-z][A-Za-z0-9]*$|^(?:[CMS]-)+.$`)
+
+// keySender is the part of a tmux client that plays macros.
+type keySender interface {
+	SendKeys(p tmux.Pane, keys string, enter bool) error
+	SendSpecialKey(p tmux.Pane, key string) error
+}
+
+func validateMacro(m Macro) error {
+	if !snippetNamePattern.MatchString(m.Name) {
+		return fmt.Errorf("macro name must match %s", snippetNamePattern)
+	}
+	if len(m.Steps) == 0 {
+		return errors.New("macro needs at least one step")
+	}
+	if len(m.Steps) > maxMacroSteps {
+		return fmt.Errorf("macro has more than %d steps", maxMacroSteps)
+	}
+	var delay time.Duration
+	for i, step := range m.Steps {
+		set := 0
+		for _, ok := range []bool{step.Text != "", step.Key != "", step.DelayMS != 0} {
+			if ok {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("step %d: set exactly one of text, key and delay_ms", i+1)
+		}
+		if step.Key != "" && !keyNamePattern.MatchString(step.Key) {
+			return fmt.Errorf("step %d: invalid key %q", i+1, step.Key)
+		}
+		if step.DelayMS < 0 {
+			return fmt.Errorf("step %d: delay_ms must be positive", i+1)
+		}
+		delay += time.Duration(step.DelayMS) * time.Millisecond
+	}
+	if delay > maxMacroDelay {
+		return fmt.Errorf("macro delays add up to more than %s", maxMacroDelay)
+	}
+	return nil
+}
+
+// playMacro sends a macro's steps to a pane in order, stopping at the
+// first that fails.
+func playMacro(c keySender, pane tmux.Pane, steps []MacroStep, sleep func(time.Duration)) error {
+	for i, step := range steps {
+		var err error
+		switch {
+		case step.Text != "":
+			err = c.SendKeys(pane, step.Text, false)
+		case step.Key != "":
+			err = c.SendSpecialKey(pane, step.Key)
+		default:
+			sleep(time.Duration(step.DelayMS) * time.Millisecond)
+		}
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// loadMacros reads keystroke macros from the store.
+func (s *Server) loadMacros() {
+	macros := make(map[string]Macro)
+	if err := s.store.Load(macrosDocument, &macros); err != nil {
+		slog.Warn("failed to load macros", "error", err)
+	}
+	s.macrosMu.Lock()
+	s.macros = macros
+	s.macrosMu.Unlock()
+}
+
+// handleAPIMacros serves GET (list) and POST (create/update) on /api/macros.
+func (s *Server) handleAPIMacros(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.macrosMu.RLock()
+		macros := make([]Macro, 0, len(s.macros))
+		for _, m := range s.macros {
+			macros = append(macros, m)
+		}
+		s.macrosMu.RUnlock()
+		sort.Slice(macros, func(i, j int) bool { return macros[i].Name < macros[j].Name })
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(macros)
+	case http.MethodPost:
+		var m Macro
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		s.saveMacro(w, m)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPIMacro serves /api/macros/{name}: GET returns the macro, PUT
+// replaces it, DELETE removes it.
+func (s *Server) handleAPIMacro(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/macros/")
+
+	switch r.Method {
+	case http.MethodGet:
+		s.macrosMu.RLock()
+		m, ok := s.macros[name]
+		s.macrosMu.RUnlock()
+		if !ok {
+			http.Error(w, "macro not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(m)
+	case http.MethodPut:
+		var m Macro
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		m.Name = name
+		s.saveMacro(w, m)
+	case http.MethodDelete:
+		s.macrosMu.Lock()
+		if _, ok := s.macros[name]; !ok {
+			s.macrosMu.Unlock()
+			http.Error(w, "macro not found", http.StatusNotFound)
+			return
+		}
+		delete(s.macros, name)
+		err := s.store.Save(macrosDocument, s.macros)
+		s.macrosMu.Unlock()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to save macros", "error", err)
+			http.Error(w, "failed to save macros", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(r.Context(), "macro deleted", "name", name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) saveMacro(w http.ResponseWriter, m Macro) {
+	if err := validateMacro(m); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.macrosMu.Lock()
+	s.macros[m.Name] = m
+	err := s.store.Save(macrosDocument, s.macros)
+	s.macrosMu.Unlock()
+	if err != nil {
+		slog.Error("failed to save macros", "error", err)
+		http.Error(w, "failed to save macros", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("macro saved", "name", m.Name, "steps", len(m.Steps))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(m)
+}
+
+// handlePaneMacro serves POST /api/pane/{target}/macro: it plays a saved
+// macro in the pane. The response is sent once every step has run.
+func (s *Server) handlePaneMacro(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req RunMacroRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.macrosMu.RLock()
+	m, ok := s.macros[req.Name]
+	s.macrosMu.RUnlock()
+	if !ok {
+		http.Error(w, "macro not found", http.StatusNotFound)
+		return
+	}
+	if _, ok := s.lookupPaneInfo(pane); !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	// Keys meant for the program would drive copy-mode instead
+	c := s.client(pane.Host).WithContext(r.Context())
+	var left SendResult
+	if !leaveMode(c, pane, &left) {
+		http.Error(w, left.Error, http.StatusConflict)
+		return
+	}
+	if err := playMacro(c, pane, m.Steps, time.Sleep); err != nil {
+		slog.ErrorContext(r.Context(), "macro failed", "pane", pane.Target(), "macro", m.Name, "error", err)
+		http.Error(w, "failed to send keys: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.responses.Answered(windowKey(pane), time.Now())
+	slog.InfoContext(r.Context(), "macro played", "pane", pane.Target(), "macro", m.Name, "steps", len(m.Steps))
+	auditDetail(r, m.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+type recordingSender struct {
+	sent   []string
+	failAt int // 1-based send to fail (0: never)
+}
+
+func (r *recordingSender) SendKeys(_ tmux.Pane, keys string, enter bool) error {
+	return r.record("text:" + keys)
+}
+
+func (r *recordingSender) SendSpecialKey(_ tmux.Pane, key string) error {
+	return r.record("key:" + key)
+}
+
+func (r *recordingSender) record(s string) error {
+	r.sent = append(r.sent, s)
+	if len(r.sent) == r.failAt {
+		return errors.New("pane gone")
+	}
+	return nil
+}
+
+func TestPlayMacro(t *testing.T) {
+	steps := []MacroStep{{Key: "Escape"}, {Key: "Escape"}, {DelayMS: 200}, {Text: "/clear"}, {Key: "Enter"}}
+	var slept []time.Duration
+	sender := &recordingSender{}
+	if err := playMacro(sender, tmux.Pane{Session: "work"}, steps, func(d time.Duration) { slept = append(slept, d) }); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"key:Escape", "key:Escape", "text:/clear", "key:Enter"}; !slices.Equal(sender.sent, want) {
+		t.Errorf("sent %v, want %v", sender.sent, want)
+	}
+	if !slices.Equal(slept, []time.Duration{200 * time.Millisecond}) {
+		t.Errorf("slept %v", slept)
+	}
+
+	sender = &recordingSender{failAt: 2}
+	if err := playMacro(sender, tmux.Pane{Session: "work"}, steps, func(time.Duration) {}); err == nil {
+		t.Error("failed step not reported")
+	}
+	if len(sender.sent) != 2 {
+		t.Errorf("kept sending after a failure: %v", sender.sent)
+	}
+}
+
+func TestValidateMacro(t *testing.T) {
+	valid := Macro{Name: "reset", Steps: []MacroStep{{Key: "Escape"}, {Key: "C-c"}, {Key: "M-Left"}, {Text: "/clear"}, {Key: "Enter"}}}
+	if err := validateMacro(valid); err != nil {
+		t.Errorf("validateMacro(%+v) = %v", valid, err)
+	}
+	for _, m := range []Macro{
+		{Name: "bad name", Steps: valid.Steps},
+		{Name: "empty"},
+		{Name: "both", Steps: []MacroStep{{Text: "x", Key: "Enter"}}},
+		{Name: "none", Steps: []MacroStep{{}}},
+		{Name: "key", Steps: []MacroStep{{Key: "Enter; kill-server"}}},
+		{Name: "negative", Steps: []MacroStep{{DelayMS: -1}}},
+		{Name: "slow", Steps: []MacroStep{{DelayMS: 6000}, {DelayMS: 6000}}},
+	} {
+		if err := validateMacro(m); err == nil {
+			t.Errorf("validateMacro(%q) = nil, want error", m.Name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// sessionMarksDocument is the store document holding pinned and hidden
+// sessions.
+const sessionMarksDocument = "session-marks"
+
+// SessionMark is how the user categorized a session, overriding its
+// computed state. It is the response of /api/sessions/{name}/pin and
+// /api/sessions/{name}/hide.
+type SessionMark struct {
+	Pinned bool `json:"pinned"` // Sorted first in its section, never demoted to Idle
+	Hidden bool `json:"hidden"` // Left out of the sections (archived)
+}
+
+// sessionMarks holds the marked sessions by session key.
+type sessionMarks struct {
+	mu    sync.Mutex
+	marks map[string]SessionMark
+}
+
+func newSessionMarks() *sessionMarks {
+	return &sessionMarks{marks: make(map[string]SessionMark)}
+}
+
+func (m *sessionMarks) get(key string) SessionMark {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.marks[key]
+}
+
+// set marks a session; an empty mark forgets it.
+func (m *sessionMarks) set(key string, mark SessionMark) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mark == (SessionMark{}) {
+		delete(m.marks, key)
+	} else {
+		m.marks[key] = mark
+	}
+}
+
+func (m *sessionMarks) all() map[string]SessionMark {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make(map[string]SessionMark, len(m.marks))
+	for key, mark := range m.marks {
+		result[key] = mark
+	}
+	return result
+}
+
+// loadSessionMarks restores pinned and hidden sessions from the store.
+func (s *Server) loadSessionMarks() {
+	marks := make(map[string]SessionMark)
+	if err := s.store.Load(sessionMarksDocument, &marks); err != nil {
+		slog.Warn("failed to load session marks", "error", err)
+	}
+	for key, mark := range marks {
+		s.marks.set(key, mark)
+	}
+}
+
+// handleSessionMark serves /api/sessions/{name}/pin and /hide: POST sets
+// the mark, DELETE clears it. Both return the session's marks.
+func (s *Server) handleSessionMark(w http.ResponseWriter, r *http.Request, session, action string) {
+	host := r.URL.Query().Get("host")
+	key := tmux.Pane{Host: host, Session: session}.Key()
+
+	mark := s.marks.get(key)
+	on := r.Method == http.MethodPost
+	switch r.Method {
+	case http.MethodPost:
+		c := s.client(host)
+		if c == nil {
+			http.Error(w, "unknown host", http.StatusNotFound)
+			return
+		}
+		if !c.HasSession(session) {
+			http.Error(w, "session not found", http.StatusNotFound)
+			return
+		}
+	case http.MethodDelete:
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if action == "pin" {
+		mark.Pinned = on
+	} else {
+		mark.Hidden = on
+	}
+
+	s.marks.set(key, mark)
+	if err := s.store.Save(sessionMarksDocument, s.marks.all()); err != nil {
+		slog.ErrorContext(r.Context(), "failed to save session marks", "error", err)
+		http.Error(w, "failed to save session marks", http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "session marked", "session", key, "pinned", mark.Pinned, "hidden", mark.Hidden)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(mark)
+}
+
+// sortPinnedFirst moves pinned sessions to the top of a section, keeping
+// the order within pinned and unpinned sessions.
+func sortPinnedFirst(sessions []SessionWithWindows) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Pinned && !sessions[j].Pinned
+	})
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/noamsto/houston/store"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestHandleSessionMark(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, marks: newSessionMarks()}
+	s.marks.set("prod-incident", SessionMark{Pinned: true, Hidden: true})
+
+	w := httptest.NewRecorder()
+	s.handleAPISession(w, httptest.NewRequest("DELETE", "/api/sessions/prod-incident/hide", nil))
+	var got SessionMark
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (SessionMark{Pinned: true}); got != want {
+		t.Errorf("mark = %+v, want %+v", got, want)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPISession(w, httptest.NewRequest("PUT", "/api/sessions/prod-incident/pin", nil))
+	if w.Code != 405 {
+		t.Errorf("PUT status = %d, want 405", w.Code)
+	}
+
+	// The pin survives a restart
+	restarted := &Server{store: st, marks: newSessionMarks()}
+	restarted.loadSessionMarks()
+	if got := restarted.marks.get("prod-incident"); got != (SessionMark{Pinned: true}) {
+		t.Errorf("restored mark = %+v, want pinned", got)
+	}
+}
+
+func TestSortPinnedFirst(t *testing.T) {
+	sessions := []SessionWithWindows{
+		{Session: tmux.Session{Name: "a"}},
+		{Session: tmux.Session{Name: "b"}, Pinned: true},
+		{Session: tmux.Session{Name: "c"}},
+		{Session: tmux.Session{Name: "d"}, Pinned: true},
+	}
+	sortPinnedFirst(sessions)
+	var names string
+	for _, sess := range sessions {
+		names += sess.Session.Name
+	}
+	if names != "bdac" {
+		t.Errorf("order = %s, want bdac", names)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/noamsto/houston/agents/claude"
+)
+
+// MCPHealth is the MCP server health of a Claude pane.
+type MCPHealth struct {
+	Servers []claude.MCPServer `json:"servers"`
+	Failed  int                `json:"failed,omitempty"` // Failures Claude reported without naming the servers
+	Down    []string           `json:"down,omitempty"`   // Required servers (-mcp-required) that failed
+}
+
+// mcpTracker remembers MCP server health per pane, so a disconnect stays
+// reported after its error scrolls off screen, until the server is seen
+// connected again.
+type mcpTracker struct {
+	mu       sync.Mutex
+	required []string // Server name globs whose failure needs attention
+	panes    map[string]map[string]claude.MCPServer
+}
+
+func newMCPTracker(required []string) *mcpTracker {
+	return &mcpTracker{required: required, panes: make(map[string]map[string]claude.MCPServer)}
+}
+
+// observe merges the status on screen into what is known for a pane.
+func (t *mcpTracker) observe(key string, st claude.MCPStatus) MCPHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	servers := t.panes[key]
+	if servers == nil && len(st.Servers) > 0 {
+		servers = make(map[string]claude.MCPServer)
+		t.panes[key] = servers
+	}
+	for _, srv := range st.Servers {
+		servers[srv.Name] = srv
+	}
+
+	health := MCPHealth{Servers: []claude.MCPServer{}, Failed: st.Failed}
+	for _, srv := range servers {
+		health.Servers = append(health.Servers, srv)
+		if srv.Status == claude.MCPFailed && t.isRequired(srv.Name) {
+			health.Down = append(health.Down, srv.Name)
+		}
+	}
+	sort.Slice(health.Servers, func(i, j int) bool { return health.Servers[i].Name < health.Servers[j].Name })
+	sort.Strings(health.Down)
+	return health
+}
+
+func (t *mcpTracker) isRequired(name string) bool {
+	for _, pattern := range t.required {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// forget drops a pane that no longer runs Claude.
+func (t *mcpTracker) forget(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.panes, key)
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/noamsto/houston/agents/claude"
+)
+
+func TestMCPTracker(t *testing.T) {
+	tr := newMCPTracker([]string{"github", "linear*"})
+	failed := claude.MCPStatus{Servers: []claude.MCPServer{
+		{Name: "github", Status: claude.MCPFailed, Error: "Error: Connection closed"},
+		{Name: "context7", Status: claude.MCPFailed},
+	}}
+
+	h := tr.observe("work:1.0", failed)
+	if want := []string{"github"}; !reflect.DeepEqual(h.Down, want) {
+		t.Errorf("Down = %v, want %v (context7 is not required)", h.Down, want)
+	}
+	if len(h.Servers) != 2 || h.Servers[0].Name != "context7" {
+		t.Errorf("Servers = %+v, want both, sorted by name", h.Servers)
+	}
+
+	// The error scrolled off screen: still down
+	if h := tr.observe("work:1.0", claude.MCPStatus{}); !reflect.DeepEqual(h.Down, []string{"github"}) {
+		t.Errorf("Down after scroll-off = %v, want [github]", h.Down)
+	}
+
+	// A later successful call clears it
+	ok := claude.MCPStatus{Servers: []claude.MCPServer{{Name: "github", Status: claude.MCPConnected}}}
+	if h := tr.observe("work:1.0", ok); len(h.Down) != 0 {
+		t.Errorf("Down after reconnect = %v, want none", h.Down)
+	}
+
+	tr.observe("other:1.0", failed)
+	tr.forget("other:1.0")
+	if h := tr.observe("other:1.0", claude.MCPStatus{}); len(h.Servers) != 0 {
+		t.Errorf("Servers after forget = %+v, want none", h.Servers)
+	}
+}
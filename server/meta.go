@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/noamsto/houston/update"
+)
+
+// Meta describes the running server so the SPA can hide unsupported UI.
+type Meta struct {
+	Version        string          `json:"version"`
+	APIVersions    []int           `json:"api_versions"`              // JSON API versions served, under /api/v{N}/
+	Auth           string          `json:"auth"`                      // Authentication mode ("none": rely on network access control)
+	Features       map[string]bool `json:"features"`                  // Optional integrations and whether they are enabled
+	FontController string          `json:"font_controller,omitempty"` // Detected terminal, if terminal control is available (see /api/terminal)
+	Hosts          []string        `json:"hosts"`                     // Remote tmux hosts
+	Routes         []string        `json:"routes"`                    // Usable API route patterns
+	Update         *update.Status  `json:"update,omitempty"`          // Latest release check, if enabled
+}
+
+// handleAPIMeta serves GET /api/meta.
+func (s *Server) handleAPIMeta(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.meta())
+}
+
+func (s *Server) meta() Meta {
+	m := Meta{
+		Version:     update.BuildVersion(s.version),
+		APIVersions: apiVersions,
+		Auth:        "none",
+		Features: map[string]bool{
+			"opencode":      s.ocManager != nil,
+			"history":       s.responses != nil,
+			"remote":        len(s.hosts) > 0,
+			"resurrect":     s.resurrectState() != nil,
+			"notifications": s.notifier.Enabled(),
+			"update_check":  s.updates != nil,
+			"raise":         s.raiser != nil,
+			"auto_approve":  s.policies.enabled(),
+			"slack":         s.slack != nil,
+		},
+		Hosts:  append([]string{}, s.hosts...),
+		Routes: []string{},
+	}
+	if s.updates != nil {
+		st := s.updates.Status()
+		m.Update = &st
+	}
+	if s.terminal != nil && s.terminal.Name() != "" {
+		m.FontController = s.terminal.Name()
+	}
+	for _, route := range s.apiRoutes() {
+		if route.available {
+			m.Routes = append(m.Routes, route.pattern)
+		}
+	}
+	return m
+}
@@ -0,0 +1,302 @@
+package server
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/history"
+	"github.com/noamsto/houston/internal/openapi"
+	"github.com/noamsto/houston/opencode"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+	"github.com/noamsto/houston/update"
+)
+
+// apiOperation is one method on a JSON API path, described in the OpenAPI
+// spec. Request and response bodies are given as values of the Go types
+// the handler decodes and encodes, so the spec follows the types.
+type apiOperation struct {
+	id           string // operationId, stable for generated clients
+	method       string
+	path         string // With {param}s
+	summary      string
+	query        []string // Query parameters
+	request      any      // Request body, or nil
+	requestType  string   // Request media type, if not JSON
+	optional     bool     // The request body may be left out
+	response     any      // JSON response body, or nil
+	responseType string   // Other response media type, alone or next to JSON
+	status       int      // Success status; 200 if zero
+}
+
+// oneOf is a response that is one of several types, e.g. depending on a
+// query parameter.
+type oneOf []any
+
+// paneForm is the body of POST /api/pane/{target}/send.
+type paneForm struct {
+	Input   string `json:"input"`
+	Special string `json:"special,omitempty"` // "true": input is a key name, e.g. C-c
+	NoEnter string `json:"noenter,omitempty"` // "true": don't press Enter after it
+	Mode    string `json:"mode,omitempty"`    // "type" or "paste" (default: paste multi-line or long input)
+	Verify  string `json:"verify,omitempty"`  // "false": don't wait for the input to show up, e.g. at a password prompt
+}
+
+// inputForm is the body of POST /api/opencode/session/{server}/{id}/send.
+type inputForm struct {
+	Input string `json:"input"`
+}
+
+// slackForm is the body Slack posts to /api/slack/interactions.
+type slackForm struct {
+	Payload string `json:"payload"`
+}
+
+// fileForm is the body of POST /api/pane/{target}/upload.
+var fileForm = &openapi.Schema{
+	Type: "object",
+	Properties: map[string]*openapi.Schema{
+		"file":   {Type: "array", Items: &openapi.Schema{Type: "string", Format: "binary"}},
+		"prompt": {Type: "string"},
+	},
+	Required: []string{"file"},
+}
+
+// apiOperations lists every JSON API operation. TestAPISpecCoversRoutes
+// fails on routes missing here, and TestAPISpec on changes to the spec not
+// yet in docs/openapi.json.
+func apiOperations() []apiOperation {
+	return []apiOperation{
+		{id: "getMeta", method: "GET", path: "/api/meta", summary: "Server version, features and usable routes", response: Meta{}},
+		{id: "getOpenAPI", method: "GET", path: "/api/openapi.json", summary: "This OpenAPI spec", response: map[string]any{}},
+		{id: "batch", method: "POST", path: "/api/batch", summary: "Several GET requests in one round trip", request: BatchRequest{}, response: []BatchResult{}},
+
+		{id: "listSessions", method: "GET", path: "/api/sessions", summary: "Sessions, windows and what their agents are doing; an SSE stream of them with stream=1", query: []string{"stream", "last_event_id"}, response: SessionsData{}, responseType: "text/event-stream"},
+		{id: "createSession", method: "POST", path: "/api/sessions", summary: "Create a session", request: CreateSessionRequest{}, response: CreatedPane{}, status: http.StatusCreated},
+		{id: "createWindow", method: "POST", path: "/api/sessions/{name}/windows", summary: "Create a window in a session", query: []string{"host"}, request: CreateWindowRequest{}, response: CreatedPane{}, status: http.StatusCreated},
+		{id: "pinSession", method: "POST", path: "/api/sessions/{name}/pin", summary: "Pin a session", query: []string{"host"}, response: SessionMark{}},
+		{id: "unpinSession", method: "DELETE", path: "/api/sessions/{name}/pin", summary: "Unpin a session", query: []string{"host"}, response: SessionMark{}},
+		{id: "hideSession", method: "POST", path: "/api/sessions/{name}/hide", summary: "Hide a session", query: []string{"host"}, response: SessionMark{}},
+		{id: "unhideSession", method: "DELETE", path: "/api/sessions/{name}/hide", summary: "Show a hidden session", query: []string{"host"}, response: SessionMark{}},
+		{id: "getSessionTimeline", method: "GET", path: "/api/sessions/{name}/timeline", summary: "Agent states of a session's windows over time", query: []string{"range", "host"}, response: Timeline{}},
+		{id: "search", method: "GET", path: "/api/search", summary: "Search pane output and agent conversations", query: []string{"q"}, response: SearchResults{}},
+		{id: "agentStrip", method: "GET", path: "/api/strip", summary: "Agent windows across sessions, for the pane page's navigation strip", query: []string{"active", "host"}, response: []AgentStripItem{}},
+		{id: "createWorktree", method: "POST", path: "/api/worktrees", summary: "Create a git worktree with a session in it", request: CreateWorktreeRequest{}, response: CreatedPane{}, status: http.StatusCreated},
+		{id: "launch", method: "POST", path: "/api/launch", summary: "Start an agent in a new or idle window", request: LaunchRequest{}, response: LaunchResult{}, status: http.StatusCreated},
+		{id: "listRelaunch", method: "GET", path: "/api/relaunch", summary: "Panes whose agent needs relaunching", response: []RelaunchInfo{}},
+		{id: "relaunch", method: "POST", path: "/api/relaunch", summary: "Relaunch agents", request: RelaunchRequest{}, response: []RelaunchResult{}},
+		{id: "getResponseTimes", method: "GET", path: "/api/history/response-times", summary: "How long agents waited for answers", query: []string{"days"}, response: history.ResponseStats{}},
+		{id: "exportHistory", method: "GET", path: "/api/history/export", summary: "Recorded history as CSV, or JSON with format=json", query: []string{"kind", "format", "from", "to", "session", "branch"}, response: oneOf{[]history.Transition{}, []history.Response{}, []claude.UsageRecord{}}, responseType: "text/csv"},
+		{id: "getDailyReport", method: "GET", path: "/api/reports/daily", summary: "A day's report as Markdown, or JSON with format=json", query: []string{"date", "format"}, response: DailyReport{}, responseType: "text/markdown"},
+		{id: "sendDailyReport", method: "POST", path: "/api/reports/daily", summary: "Deliver a day's report", query: []string{"date", "format"}, response: DailyReport{}, responseType: "text/markdown"},
+
+		{id: "getPane", method: "GET", path: "/api/pane/{target}", summary: "A pane's output and agent state", query: []string{"colors"}, response: PaneData{}},
+		{id: "paneSocket", method: "GET", path: "/api/pane/{target}/ws", summary: "WebSocket of pane output and input", query: []string{"mode", "colors", "patch", "resume"}, status: http.StatusSwitchingProtocols},
+		{id: "paneEvents", method: "GET", path: "/api/pane/{target}/events", summary: "SSE stream of pane output", query: []string{"colors", "patch", "resume"}, responseType: "text/event-stream"},
+		{id: "sendKeys", method: "POST", path: "/api/pane/{target}/send", summary: "Send input or a key; confirmed is false when sent input didn't show up, 409 when nothing was sent", request: paneForm{}, requestType: "application/x-www-form-urlencoded", response: SendResult{}},
+		{id: "sendImages", method: "POST", path: "/api/pane/{target}/send-with-images", summary: "Send images with a prompt", request: SendImagesRequest{}, response: []Upload{}},
+		{id: "sendTemplate", method: "POST", path: "/api/pane/{target}/send-template", summary: "Send a rendered snippet", request: SendTemplateRequest{}, response: SendTemplateResult{}},
+		{id: "runMacro", method: "POST", path: "/api/pane/{target}/macro", summary: "Play a macro", request: RunMacroRequest{}, status: http.StatusNoContent},
+		{id: "choose", method: "POST", path: "/api/pane/{target}/choose", summary: "Answer the prompt the agent shows", request: ChooseRequest{}, response: parser.Result{}},
+		{id: "killPane", method: "POST", path: "/api/pane/{target}/kill", summary: "Kill a pane", status: http.StatusSeeOther},
+		{id: "respawnPane", method: "POST", path: "/api/pane/{target}/respawn", summary: "Restart a pane's command"},
+		{id: "killWindow", method: "POST", path: "/api/pane/{target}/kill-window", summary: "Kill a pane's window", status: http.StatusSeeOther},
+		{id: "zoomPane", method: "POST", path: "/api/pane/{target}/zoom", summary: "Toggle a pane's zoom"},
+		{id: "getTranscript", method: "GET", path: "/api/pane/{target}/transcript", summary: "The agent conversation as Markdown, or HTML with format=html", query: []string{"format"}, responseType: "text/markdown"},
+		{id: "handoffPane", method: "POST", path: "/api/pane/{target}/handoff", summary: "Hand the conversation to another agent", request: HandoffRequest{}, response: HandoffResult{}, status: http.StatusAccepted},
+		{id: "resumeConversation", method: "POST", path: "/api/pane/{target}/resume", summary: "Resume a Claude conversation in a new window", request: ResumeRequest{}, response: ResumeResult{}},
+		{id: "getPaneAgent", method: "GET", path: "/api/pane/{target}/agent", summary: "The pane's agent", response: AgentOverride{}},
+		{id: "setPaneAgent", method: "PUT", path: "/api/pane/{target}/agent", summary: "Pin the pane's agent", request: AgentOverride{}, response: AgentOverride{}},
+		{id: "clearPaneAgent", method: "DELETE", path: "/api/pane/{target}/agent", summary: "Detect the pane's agent again", response: AgentOverride{}},
+		{id: "getPaneAutoCompact", method: "GET", path: "/api/pane/{target}/auto-compact", summary: "Whether the pane is compacted automatically", response: AutoCompactSetting{}},
+		{id: "setPaneAutoCompact", method: "PUT", path: "/api/pane/{target}/auto-compact", summary: "Opt the pane out of (or back into) auto-compaction", request: AutoCompactSetting{}, response: AutoCompactSetting{}},
+		{id: "getPaneHistory", method: "GET", path: "/api/pane/{target}/history", summary: "A page of scrollback", query: []string{"before", "lines"}, response: HistoryPage{}},
+		{id: "getPaneTodos", method: "GET", path: "/api/pane/{target}/todos", summary: "The agent's task list", response: PaneTodos{}},
+		{id: "getPaneDiff", method: "GET", path: "/api/pane/{target}/diff", summary: "Uncommitted changes in the pane's repository", query: []string{"staged"}, response: PaneDiff{}},
+		{id: "commit", method: "POST", path: "/api/pane/{target}/commit", summary: "Preview, or with a token run, a commit of every change", request: GitRequest{}, response: oneOf{GitPreview{}, GitResult{}}},
+		{id: "push", method: "POST", path: "/api/pane/{target}/push", summary: "Preview, or with a token run, a push", request: GitRequest{}, response: oneOf{GitPreview{}, GitResult{}}},
+		{id: "getPaneRecording", method: "GET", path: "/api/pane/{target}/recording", summary: "Whether the pane is recorded, and its recordings", response: PaneRecording{}},
+		{id: "startRecording", method: "POST", path: "/api/pane/{target}/recording", summary: "Start recording the pane", response: PaneRecording{}},
+		{id: "stopRecording", method: "DELETE", path: "/api/pane/{target}/recording", summary: "Stop recording the pane", response: PaneRecording{}},
+		{id: "getScreenshot", method: "GET", path: "/api/pane/{target}/screenshot.png", summary: "The pane's screen as a PNG", query: []string{"theme", "scale", "lines"}, responseType: "image/png"},
+		{id: "paste", method: "POST", path: "/api/pane/{target}/paste", summary: "Paste the tmux buffer, or the given text", request: Clipboard{}, optional: true, status: http.StatusNoContent},
+		{id: "uploadFiles", method: "POST", path: "/api/pane/{target}/upload", summary: "Write files into the pane's working directory", request: fileForm, requestType: "multipart/form-data", response: []UploadedFile{}},
+		{id: "getWindowTags", method: "GET", path: "/api/pane/{target}/tags", summary: "The window's tags", response: WindowTags{}},
+		{id: "setWindowTags", method: "PUT", path: "/api/pane/{target}/tags", summary: "Replace the window's tags", request: WindowTags{}, response: WindowTags{}},
+		{id: "addWindowTags", method: "POST", path: "/api/pane/{target}/tags", summary: "Add window tags", request: WindowTags{}, response: WindowTags{}},
+		{id: "removeWindowTags", method: "DELETE", path: "/api/pane/{target}/tags", summary: "Remove window tags, or all without a body", request: WindowTags{}, optional: true, response: WindowTags{}},
+		{id: "listQueue", method: "GET", path: "/api/pane/{target}/queue", summary: "Prompts queued for the pane", response: []QueuedPrompt{}},
+		{id: "queuePrompt", method: "POST", path: "/api/pane/{target}/queue", summary: "Queue a prompt", request: QueueRequest{}, response: QueuedPrompt{}, status: http.StatusCreated},
+		{id: "reorderQueue", method: "PUT", path: "/api/pane/{target}/queue", summary: "Reorder queued prompts", request: QueueRequest{}, response: []QueuedPrompt{}},
+		{id: "dequeuePrompt", method: "DELETE", path: "/api/pane/{target}/queue", summary: "Remove a queued prompt, or all without an id", query: []string{"id"}, response: []QueuedPrompt{}},
+		{id: "interruptPane", method: "POST", path: "/api/pane/{target}/interrupt", summary: "Stop what the pane runs: Escape for agents, C-c for shells", response: PaneKeysResult{}},
+		{id: "escapePane", method: "POST", path: "/api/pane/{target}/escape", summary: "Press Escape", response: PaneKeysResult{}},
+		{id: "clearPane", method: "POST", path: "/api/pane/{target}/clear", summary: "Start a new conversation, or clear a shell's screen", response: PaneKeysResult{}},
+		{id: "getCopyMode", method: "GET", path: "/api/pane/{target}/copy-mode", summary: "The pane's tmux mode and scroll position", response: tmux.ModeState{}},
+		{id: "enterCopyMode", method: "POST", path: "/api/pane/{target}/copy-mode", summary: "Enter copy-mode, freezing the view to read earlier output", response: tmux.ModeState{}},
+		{id: "exitCopyMode", method: "DELETE", path: "/api/pane/{target}/copy-mode", summary: "Leave copy-mode, back to the live output", response: tmux.ModeState{}},
+		{id: "scrollPane", method: "POST", path: "/api/pane/{target}/scroll", summary: "Scroll the pane's history in copy-mode; bottom leaves it", request: ScrollRequest{}, response: tmux.ModeState{}},
+		{id: "focusPane", method: "POST", path: "/api/pane/{target}/focus", summary: "Show the pane in a tmux client", query: []string{"client", "raise"}, response: FocusResult{}},
+
+		{id: "claudeHook", method: "POST", path: "/api/hooks/claude", summary: "Claude Code hook events", query: []string{"session"}, request: json.RawMessage{}, status: http.StatusNoContent},
+		{id: "broadcast", method: "POST", path: "/api/broadcast", summary: "Send input to several panes", request: BroadcastRequest{}, response: []BroadcastResult{}},
+
+		{id: "listViews", method: "GET", path: "/api/views", summary: "Saved views", response: []View{}},
+		{id: "saveView", method: "POST", path: "/api/views", summary: "Create or update a view", request: View{}, response: View{}},
+		{id: "getView", method: "GET", path: "/api/views/{name}", summary: "The windows a view matches", response: ViewData{}},
+		{id: "replaceView", method: "PUT", path: "/api/views/{name}", summary: "Replace a view", request: View{}, response: View{}},
+		{id: "deleteView", method: "DELETE", path: "/api/views/{name}", summary: "Delete a view", status: http.StatusNoContent},
+		{id: "listSnippets", method: "GET", path: "/api/snippets", summary: "Prompt snippets", response: []Snippet{}},
+		{id: "saveSnippet", method: "POST", path: "/api/snippets", summary: "Create or update a snippet", request: Snippet{}, response: Snippet{}},
+		{id: "getSnippet", method: "GET", path: "/api/snippets/{name}", summary: "A snippet", response: Snippet{}},
+		{id: "replaceSnippet", method: "PUT", path: "/api/snippets/{name}", summary: "Replace a snippet", request: Snippet{}, response: Snippet{}},
+		{id: "deleteSnippet", method: "DELETE", path: "/api/snippets/{name}", summary: "Delete a snippet", status: http.StatusNoContent},
+		{id: "listPrefs", method: "GET", path: "/api/prefs", summary: "Every client's dashboard preferences", response: map[string]Prefs{}},
+		{id: "getPrefs", method: "GET", path: "/api/prefs/{client}", summary: "A client's dashboard preferences", response: Prefs{}},
+		{id: "replacePrefs", method: "PUT", path: "/api/prefs/{client}", summary: "Replace a client's preferences", request: Prefs{}, response: Prefs{}},
+		{id: "updatePrefs", method: "PATCH", path: "/api/prefs/{client}", summary: "Change some of a client's preferences", request: Prefs{}, response: Prefs{}},
+		{id: "deletePrefs", method: "DELETE", path: "/api/prefs/{client}", summary: "Forget a client's preferences", status: http.StatusNoContent},
+		{id: "listMacros", method: "GET", path: "/api/macros", summary: "Macros", response: []Macro{}},
+		{id: "saveMacro", method: "POST", path: "/api/macros", summary: "Create or update a macro", request: Macro{}, response: Macro{}},
+		{id: "getMacro", method: "GET", path: "/api/macros/{name}", summary: "A macro", response: Macro{}},
+		{id: "replaceMacro", method: "PUT", path: "/api/macros/{name}", summary: "Replace a macro", request: Macro{}, response: Macro{}},
+		{id: "deleteMacro", method: "DELETE", path: "/api/macros/{name}", summary: "Delete a macro", status: http.StatusNoContent},
+		{id: "listPolicies", method: "GET", path: "/api/policies", summary: "Auto-approval policies", response: []Policy{}},
+		{id: "createPolicy", method: "POST", path: "/api/policies", summary: "Create a policy", request: Policy{}, response: Policy{}, status: http.StatusCreated},
+		{id: "getApprovals", method: "GET", path: "/api/policies/audit", summary: "Prompts approved by policies", query: []string{"since"}, response: []Approval{}},
+		{id: "getPolicy", method: "GET", path: "/api/policies/{id}", summary: "A policy", response: Policy{}},
+		{id: "replacePolicy", method: "PUT", path: "/api/policies/{id}", summary: "Replace a policy", request: Policy{}, response: Policy{}},
+		{id: "deletePolicy", method: "DELETE", path: "/api/policies/{id}", summary: "Delete a policy", status: http.StatusNoContent},
+		{id: "listSchedules", method: "GET", path: "/api/schedules", summary: "Scheduled prompts", response: []Schedule{}},
+		{id: "createSchedule", method: "POST", path: "/api/schedules", summary: "Create a schedule", request: Schedule{}, response: Schedule{}, status: http.StatusCreated},
+		{id: "getSchedule", method: "GET", path: "/api/schedules/{id}", summary: "A schedule", response: Schedule{}},
+		{id: "replaceSchedule", method: "PUT", path: "/api/schedules/{id}", summary: "Replace a schedule", request: Schedule{}, response: Schedule{}},
+		{id: "deleteSchedule", method: "DELETE", path: "/api/schedules/{id}", summary: "Delete a schedule", status: http.StatusNoContent},
+		{id: "runSchedule", method: "POST", path: "/api/schedules/{id}/run", summary: "Run a schedule now", response: Schedule{}},
+
+		{id: "listRecordings", method: "GET", path: "/api/recordings", summary: "Every recording, newest first", response: []Recording{}},
+		{id: "getRecording", method: "GET", path: "/api/recordings/{id}", summary: "A recording as an asciicast file", responseType: "application/x-asciicast"},
+		{id: "deleteRecording", method: "DELETE", path: "/api/recordings/{id}", summary: "Delete a finished recording", status: http.StatusNoContent},
+		{id: "replayRecording", method: "GET", path: "/api/recordings/{id}/replay", summary: "SSE replay of a recording", query: []string{"speed", "idle"}, responseType: "text/event-stream"},
+		{id: "getClipboard", method: "GET", path: "/api/clipboard", summary: "The most recent tmux paste buffer", query: []string{"host"}, response: Clipboard{}},
+		{id: "setClipboard", method: "POST", path: "/api/clipboard", summary: "Store text as a tmux paste buffer", query: []string{"host"}, request: Clipboard{}, status: http.StatusNoContent},
+		{id: "listUploads", method: "GET", path: "/api/uploads", summary: "Images sent to agents", response: []Upload{}},
+		{id: "getUpload", method: "GET", path: "/api/uploads/{id}", summary: "A sent image", responseType: "image/*"},
+		{id: "deleteUpload", method: "DELETE", path: "/api/uploads/{id}", summary: "Delete a sent image", status: http.StatusNoContent},
+		{id: "listClaudeSessions", method: "GET", path: "/api/claude/sessions", summary: "Claude conversations of a directory", query: []string{"cwd"}, response: []claude.SessionInfo{}},
+		{id: "getAudit", method: "GET", path: "/api/audit", summary: "Changes made through the API", query: []string{"since", "target"}, response: []AuditEntry{}},
+		{id: "getCompactions", method: "GET", path: "/api/auto-compact/audit", summary: "Automatic compactions", query: []string{"since"}, response: []Compaction{}},
+		{id: "slackInteraction", method: "POST", path: "/api/slack/interactions", summary: "Slack button clicks", request: slackForm{}, requestType: "application/x-www-form-urlencoded"},
+		{id: "getTerminal", method: "GET", path: "/api/terminal", summary: "The controlled terminal and what it supports", response: TerminalInfo{}},
+		{id: "controlTerminal", method: "POST", path: "/api/terminal/{action}", summary: "Change the terminal's font, theme or opacity", request: TerminalRequest{}, optional: true, status: http.StatusNoContent},
+
+		{id: "listOpenCodeSessions", method: "GET", path: "/api/opencode/sessions", summary: "OpenCode sessions; an SSE stream of them with stream=1", query: []string{"stream"}, response: OpenCodeData{}, responseType: "text/event-stream"},
+		{id: "createOpenCodeSession", method: "POST", path: "/api/opencode/sessions", summary: "Create an OpenCode session", request: OpenCodeCreateRequest{}, response: opencode.Session{}, status: http.StatusCreated},
+		{id: "getOpenCodeSession", method: "GET", path: "/api/opencode/session/{server}/{id}", summary: "An OpenCode session", response: opencode.SessionState{}},
+		{id: "deleteOpenCodeSession", method: "DELETE", path: "/api/opencode/session/{server}/{id}", summary: "Delete an OpenCode session", status: http.StatusNoContent},
+		{id: "sendOpenCode", method: "POST", path: "/api/opencode/session/{server}/{id}/send", summary: "Send a prompt", request: inputForm{}, requestType: "application/x-www-form-urlencoded"},
+		{id: "abortOpenCode", method: "POST", path: "/api/opencode/session/{server}/{id}/abort", summary: "Abort the running prompt"},
+		{id: "handoffOpenCode", method: "POST", path: "/api/opencode/session/{server}/{id}/handoff", summary: "Hand the conversation to another agent", request: HandoffRequest{}, response: HandoffResult{}, status: http.StatusAccepted},
+		{id: "getOpenCodeMessages", method: "GET", path: "/api/opencode/session/{server}/{id}/messages", summary: "Recent messages", query: []string{"limit"}, response: []opencode.RenderedMessage{}},
+		{id: "getOpenCodeModels", method: "GET", path: "/api/opencode/models", summary: "Models an OpenCode server offers", query: []string{"server"}, response: OpenCodeModels{}},
+	}
+}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// apiSpec builds the OpenAPI document of the JSON API.
+func apiSpec(version string) *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "houston",
+		Version:     version,
+		Description: "The JSON API the houston dashboard is built on. Paths without the version prefix are an alias of the current version.",
+	})
+	for _, op := range apiOperations() {
+		o := &openapi.Operation{
+			OperationID: op.id,
+			Summary:     op.summary,
+			Tags:        []string{strings.Split(op.path, "/")[2]},
+			Responses:   map[string]openapi.Response{},
+		}
+		for _, m := range pathParamPattern.FindAllStringSubmatch(op.path, -1) {
+			o.Parameters = append(o.Parameters, openapi.Parameter{
+				Name: m[1], In: "path", Required: true, Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+		query := op.query
+		if strings.HasPrefix(op.path, "/api/pane/") {
+			query = append([]string{"host"}, query...)
+		}
+		for _, name := range query {
+			o.Parameters = append(o.Parameters, openapi.Parameter{
+				Name: name, In: "query", Schema: &openapi.Schema{Type: "string"},
+			})
+		}
+
+		if op.request != nil {
+			o.RequestBody = &openapi.RequestBody{
+				Required: !op.optional,
+				Content: map[string]openapi.MediaType{
+					cmp.Or(op.requestType, "application/json"): {Schema: specSchema(doc, op.request)},
+				},
+			}
+		}
+
+		status := op.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := openapi.Response{Description: http.StatusText(status)}
+		if op.response != nil || op.responseType != "" {
+			resp.Content = map[string]openapi.MediaType{}
+		}
+		if op.response != nil {
+			resp.Content["application/json"] = openapi.MediaType{Schema: specSchema(doc, op.response)}
+		}
+		if op.responseType != "" {
+			schema := &openapi.Schema{Type: "string"}
+			if !strings.HasPrefix(op.responseType, "text/") {
+				schema.Format = "binary"
+			}
+			resp.Content[op.responseType] = openapi.MediaType{Schema: schema}
+		}
+		o.Responses[strconv.Itoa(status)] = resp
+		o.Responses["default"] = openapi.Response{
+			Description: "Error",
+			Content:     map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}},
+		}
+		doc.Add(op.method, "/api/v"+strconv.Itoa(APIVersion)+strings.TrimPrefix(op.path, "/api"), o)
+	}
+	return doc
+}
+
+func specSchema(doc *openapi.Document, v any) *openapi.Schema {
+	switch v := v.(type) {
+	case *openapi.Schema:
+		return v
+	case oneOf:
+		s := &openapi.Schema{}
+		for _, alt := range v {
+			s.OneOf = append(s.OneOf, doc.SchemaOf(alt))
+		}
+		return s
+	default:
+		return doc.SchemaOf(v)
+	}
+}
+
+// handleAPIOpenAPI serves GET /api/openapi.json, the OpenAPI 3 spec of the
+// JSON API, to generate clients from.
+func (s *Server) handleAPIOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiSpec(update.BuildVersion(s.version)))
+}
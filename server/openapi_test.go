@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var updateSpec = flag.Bool("update", false, "rewrite docs/openapi.json")
+
+const specFile = "../docs/openapi.json"
+
+// TestAPISpec gates changes to the API: any change to a request or
+// response type, or to the operations, shows up as a diff to the committed
+// spec. Review it, then run go test ./server -run TestAPISpec -update.
+func TestAPISpec(t *testing.T) {
+	got, err := json.MarshalIndent(apiSpec("dev"), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+	if *updateSpec {
+		if err := os.WriteFile(specFile, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(specFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("the API changed; if intended, run go test ./server -run TestAPISpec -update and commit %s", strings.TrimPrefix(specFile, "../"))
+	}
+}
+
+func TestAPISpecCoversRoutes(t *testing.T) {
+	s := &Server{compact: newCompactRule(0, "")}
+	paths := make(map[string]bool)
+	ids := make(map[string]bool)
+	for _, op := range apiOperations() {
+		paths[op.path] = true
+		if ids[op.id] {
+			t.Errorf("operation ID %s is used twice", op.id)
+		}
+		ids[op.id] = true
+	}
+
+	for _, route := range s.apiRoutes() {
+		covered := paths[route.pattern]
+		for path := range paths {
+			if strings.HasSuffix(route.pattern, "/") && strings.HasPrefix(path, route.pattern) {
+				covered = true
+			}
+		}
+		if !covered {
+			t.Errorf("route %s is missing from apiOperations", route.pattern)
+		}
+	}
+
+	// Pane actions are routed by handleAPIPane
+	src, err := os.ReadFile("api.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	suffixes := regexp.MustCompile(`strings\.HasSuffix\(path, "(/[^"]+)"\)`).FindAllSubmatch(src, -1)
+	if len(suffixes) == 0 {
+		t.Fatal("no pane actions found in api.go")
+	}
+	for _, m := range suffixes {
+		if path := "/api/pane/{target}" + string(m[1]); !paths[path] {
+			t.Errorf("pane action %s is missing from apiOperations", path)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/noamsto/houston/opencode"
+	"github.com/noamsto/houston/tmux"
+)
+
+// OpenCodeCreateRequest is the body of POST /api/opencode/sessions.
+type OpenCodeCreateRequest struct {
+	Server string                  `json:"server,omitempty"` // OpenCode server URL (default: the only one)
+	Title  string                  `json:"title,omitempty"`
+	Prompt string                  `json:"prompt,omitempty"` // Sent once the session exists
+	Agent  string                  `json:"agent,omitempty"`  // From GET /api/opencode/models
+	Model  *opencode.ModelSelector `json:"model,omitempty"`  // From GET /api/opencode/models
+}
+
+// OpenCodeModels is the response of GET /api/opencode/models: what a model
+// picker offers for a server.
+type OpenCodeModels struct {
+	Server    string              `json:"server"`
+	Providers []opencode.Provider `json:"providers"`
+	Default   map[string]string   `json:"default,omitempty"` // Provider ID -> default model ID
+	Agents    []opencode.Agent    `json:"agents"`
+}
+
+// openCodeServer resolves the server a request names, defaulting to the
+// only discovered one.
+func (s *Server) openCodeServer(serverURL string) (string, bool) {
+	if serverURL != "" {
+		return serverURL, s.ocDiscovery.GetServer(serverURL) != nil
+	}
+	if servers := s.ocDiscovery.GetServers(); len(servers) == 1 {
+		return servers[0].URL, true
+	}
+	return "", false
+}
+
+// handleOpenCodeCreate creates an OpenCode session, optionally starting it
+// with a prompt for a chosen agent and model.
+func (s *Server) handleOpenCodeCreate(w http.ResponseWriter, r *http.Request) {
+	var req OpenCodeCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	serverURL, ok := s.openCodeServer(req.Server)
+	if !ok {
+		http.Error(w, "unknown OpenCode server (pass server)", http.StatusBadRequest)
+		return
+	}
+	if req.Model != nil && (req.Model.ProviderID == "" || req.Model.ModelID == "") {
+		http.Error(w, "model needs providerID and modelID", http.StatusBadRequest)
+		return
+	}
+
+	session, err := s.ocManager.CreateSession(r.Context(), serverURL, opencode.NewSession{
+		Title:  req.Title,
+		Prompt: req.Prompt,
+		Agent:  req.Agent,
+		Model:  req.Model,
+	})
+	if session == nil {
+		slog.ErrorContext(r.Context(), "failed to create OpenCode session", "server", serverURL, "error", err)
+		http.Error(w, "failed to create session: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if err != nil {
+		// The session exists; only the opening prompt failed
+		slog.WarnContext(r.Context(), "OpenCode session created, prompt failed", "server", serverURL, "session", session.ID, "error", err)
+	}
+
+	slog.InfoContext(r.Context(), "created OpenCode session", "server", serverURL, "session", session.ID, "agent", req.Agent)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(session)
+}
+
+func (s *Server) handleOpenCodeDelete(w http.ResponseWriter, r *http.Request, serverURL, sessionID string) {
+	slog.InfoContext(r.Context(), "delete OpenCode session", "server", serverURL, "session", sessionID)
+
+	if err := s.ocManager.DeleteSession(r.Context(), serverURL, sessionID); err != nil {
+		slog.ErrorContext(r.Context(), "failed to delete OpenCode session", "error", err)
+		http.Error(w, "failed to delete: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAPIOpenCodeModels serves GET /api/opencode/models?server=URL.
+func (s *Server) handleAPIOpenCodeModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.ocManager == nil {
+		http.Error(w, "OpenCode integration not enabled", http.StatusNotImplemented)
+		return
+	}
+	serverURL, ok := s.openCodeServer(r.URL.Query().Get("server"))
+	if !ok {
+		http.Error(w, "unknown OpenCode server (pass server)", http.StatusBadRequest)
+		return
+	}
+
+	providers, agents, err := s.ocManager.Models(r.Context(), serverURL)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list OpenCode models", "server", serverURL, "error", err)
+		http.Error(w, "failed to list models: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	resp := OpenCodeModels{
+		Server:    serverURL,
+		Providers: providers.Providers,
+		Default:   providers.Default,
+		Agents:    agents,
+	}
+	if resp.Providers == nil {
+		resp.Providers = []opencode.Provider{}
+	}
+	if resp.Agents == nil {
+		resp.Agents = []opencode.Agent{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Message history limits for GET /api/opencode/session/{server}/{id}/messages.
+const (
+	defaultOpenCodeMessages = 50
+	maxOpenCodeMessages     = 500
+)
+
+// handleOpenCodeMessages serves a session's recent messages (?limit=N),
+// rendered into text and tool call blocks.
+func (s *Server) handleOpenCodeMessages(w http.ResponseWriter, r *http.Request, serverURL, sessionID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	limit := defaultOpenCodeMessages
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxOpenCodeMessages)
+	}
+
+	messages, err := s.ocManager.GetMessages(r.Context(), serverURL, sessionID, limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to get OpenCode messages", "server", serverURL, "session", sessionID, "error", err)
+		http.Error(w, "failed to get messages: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(messages)
+}
+
+// OpenCodeLink ties a window running the OpenCode TUI to the session it
+// shows, whose detail is at /api/opencode/session/{server}/{session_id}.
+type OpenCodeLink struct {
+	Server    string `json:"server"`
+	SessionID string `json:"session_id"`
+	Title     string `json:"title,omitempty"`
+	Status    string `json:"status"` // "idle", "busy", "error"
+}
+
+// openCodeLink finds the OpenCode session of a local pane running the
+// OpenCode TUI, from the cached session states.
+func (s *Server) openCodeLink(pane tmux.Pane, info *tmux.PaneInfo) *OpenCodeLink {
+	if s.ocManager == nil || pane.Host != "" || info == nil ||
+		!strings.Contains(strings.ToLower(info.Command), "opencode") {
+		return nil
+	}
+	srv := s.ocDiscovery.ServerForPane(info.PID, info.Path)
+	if srv == nil {
+		return nil
+	}
+	state, ok := s.ocManager.CurrentSession(srv.URL)
+	if !ok {
+		return nil
+	}
+	return &OpenCodeLink{
+		Server:    srv.URL,
+		SessionID: state.Session.ID,
+		Title:     state.Session.Title,
+		Status:    state.Status,
+	}
+}
+
+// openCodeKey identifies an OpenCode session across servers.
+func openCodeKey(serverURL, sessionID string) string {
+	return serverURL + " " + sessionID
+}
+
+// openCodeInPane reports whether an OpenCode session is shown by a tmux
+// window, so the OpenCode list doesn't repeat it.
+func (s *Server) openCodeInPane(serverURL, sessionID string) bool {
+	s.ocPanesMu.RLock()
+	defer s.ocPanesMu.RUnlock()
+	_, ok := s.ocPanes[openCodeKey(serverURL, sessionID)]
+	return ok
+}
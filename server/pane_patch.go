@@ -0,0 +1,78 @@
+package server
+
+import "strings"
+
+// WSPatch updates the output a pane stream sent last (Base) to the output
+// with ID, so scrolling output costs its new lines rather than the whole
+// 500-line capture. To apply it, split the base output on "\n", drop the
+// first Drop lines, replace Delete lines at Start with Lines, and join with
+// "\n".
+type WSPatch struct {
+	Base   string   `json:"base"` // ID of the output the patch applies to
+	ID     string   `json:"id"`   // ID of the result; pass back as ?resume= like WSOutput.ID
+	Drop   int      `json:"drop,omitempty"`
+	Start  int      `json:"start"`
+	Delete int      `json:"delete,omitempty"`
+	Lines  []string `json:"lines"`
+}
+
+// maxPatchScroll bounds how far output may have scrolled for a patch to
+// look for it; beyond that the full output is about as cheap.
+const maxPatchScroll = 400
+
+// diffOutput returns the patch from prev to next, and whether it is worth
+// sending: it must carry well under the full output.
+func diffOutput(prev, next string) (WSPatch, bool) {
+	old := strings.Split(prev, "\n")
+	cur := strings.Split(next, "\n")
+
+	best := WSPatch{Lines: cur, Delete: len(old)}
+	bestCost := len(cur)
+	for drop := 0; drop < len(old) && drop <= maxPatchScroll; drop++ {
+		// Output scrolled by drop lines: the top of cur continues old[drop:]
+		if drop > 0 && old[drop] != cur[0] {
+			continue
+		}
+		base := old[drop:]
+		prefix := 0
+		for prefix < len(base) && prefix < len(cur) && base[prefix] == cur[prefix] {
+			prefix++
+		}
+		suffix := 0
+		for suffix < len(base)-prefix && suffix < len(cur)-prefix && base[len(base)-1-suffix] == cur[len(cur)-1-suffix] {
+			suffix++
+		}
+		if cost := len(cur) - prefix - suffix; cost < bestCost || (cost == bestCost && drop < best.Drop) {
+			bestCost = cost
+			best = WSPatch{
+				Drop:   drop,
+				Start:  prefix,
+				Delete: len(base) - prefix - suffix,
+				Lines:  cur[prefix : len(cur)-suffix],
+			}
+		}
+	}
+	if best.Lines == nil {
+		best.Lines = []string{}
+	}
+
+	size := 0
+	for _, line := range best.Lines {
+		size += len(line) + 1
+	}
+	return best, size < len(next)/2
+}
+
+// outputMessage returns the message type and payload bringing a client
+// from prev (if shown already shows it) to next: a patch when the client
+// accepts them and it is small, else the full output.
+func outputMessage(prev string, shown bool, next string, patches bool) (string, any) {
+	id := outputID(next)
+	if patches && shown {
+		if p, ok := diffOutput(prev, next); ok {
+			p.Base, p.ID = outputID(prev), id
+			return "patch", p
+		}
+	}
+	return "output", WSOutput{Data: next, ID: id}
+}
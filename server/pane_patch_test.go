@@ -0,0 +1,88 @@
+package server
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestDiffOutputScroll(t *testing.T) {
+	var lines []string
+	for i := range 500 {
+		lines = append(lines, fmt.Sprintf("line %d of the agent's output", i))
+	}
+	prev := strings.Join(lines, "\n") + "\n> "
+	// Three new lines scroll the capture; the prompt line is redrawn
+	next := strings.Join(append(lines[3:], "new 1", "new 2", "new 3"), "\n") + "\n> "
+
+	p, ok := diffOutput(prev, next)
+	if !ok {
+		t.Fatal("scroll patch not worth sending")
+	}
+	if p.Drop != 3 || len(p.Lines) != 3 {
+		t.Errorf("patch = drop %d, %d lines; want drop 3, 3 lines", p.Drop, len(p.Lines))
+	}
+	if got := applyPatch(prev, p); got != next {
+		t.Error("applyPatch did not reproduce the new output")
+	}
+}
+
+func TestDiffOutputRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"", "", "foo", "bar", "❯ 1. Yes", "● Read", "\x1b[31merr\x1b[0m"}
+	randomLines := func(n int) []string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = words[rng.Intn(len(words))]
+		}
+		return lines
+	}
+
+	for i := range 500 {
+		old := randomLines(rng.Intn(40))
+		cur := append([]string{}, old[min(rng.Intn(5), len(old)):]...)
+		for range rng.Intn(4) {
+			at := rng.Intn(len(cur) + 1)
+			cur = append(cur[:at], append(randomLines(rng.Intn(3)), cur[at:]...)...)
+		}
+		if len(cur) > 0 && rng.Intn(2) == 0 {
+			cur = cur[:rng.Intn(len(cur))]
+		}
+		prev, next := strings.Join(old, "\n"), strings.Join(cur, "\n")
+
+		p, _ := diffOutput(prev, next)
+		if got := applyPatch(prev, p); got != next {
+			t.Fatalf("case %d: applyPatch(%q, %+v) = %q, want %q", i, prev, p, got, next)
+		}
+	}
+}
+
+func TestOutputMessage(t *testing.T) {
+	prev := strings.Repeat("same line\n", 100)
+	next := prev + "one more"
+
+	if typ, _ := outputMessage(prev, true, next, false); typ != "output" {
+		t.Errorf("without ?patch=1: %s, want output", typ)
+	}
+	if typ, _ := outputMessage(prev, false, next, true); typ != "output" {
+		t.Errorf("client shows nothing yet: %s, want output", typ)
+	}
+	typ, payload := outputMessage(prev, true, next, true)
+	p, ok := payload.(WSPatch)
+	if typ != "patch" || !ok || p.Base != outputID(prev) || p.ID != outputID(next) {
+		t.Errorf("outputMessage = %s %+v, want a patch from prev to next", typ, payload)
+	}
+	if typ, _ := outputMessage("a", true, "b", true); typ != "output" {
+		t.Errorf("full rewrite: %s, want output", typ)
+	}
+}
+
+// applyPatch applies p to the output it was computed from, as clients do.
+func applyPatch(base string, p WSPatch) string {
+	lines := strings.Split(base, "\n")[p.Drop:]
+	result := append([]string{}, lines[:p.Start]...)
+	result = append(result, p.Lines...)
+	result = append(result, lines[p.Start+p.Delete:]...)
+	return strings.Join(result, "\n")
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/noamsto/houston/tmux"
+)
+
+// handlePaneRawWS serves ?mode=raw on the pane WebSocket: a real terminal
+// attached through a tmux control-mode client instead of polled snapshots.
+//
+// Server → client binary frames carry the pane's raw output. Client → server
+// binary frames are written to the pane byte-for-byte; text frames use the
+// regular JSON protocol, of which only "resize" applies here.
+func (s *Server) handlePaneRawWS(conn *websocket.Conn, pane tmux.Pane) {
+	c := s.client(pane.Host)
+	stream, err := c.AttachControl(pane)
+	if err != nil {
+		slog.Error("control mode attach failed", "pane", pane.Target(), "error", err)
+		_ = conn.WriteMessage(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "attach failed"))
+		return
+	}
+	defer func() { _ = stream.Close() }()
+
+	slog.Info("pane raw websocket connected", "target", pane.Target())
+
+	// Start from the current screen so the terminal is correct before the
+	// program writes anything new.
+	if snapshot, err := rawSnapshot(c, pane); err == nil {
+		if err := conn.WriteMessage(websocket.BinaryMessage, snapshot); err != nil {
+			return
+		}
+	}
+
+	go s.paneRawReadLoop(conn, pane, stream)
+
+	for data := range stream.Output() {
+		if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) paneRawReadLoop(conn *websocket.Conn, pane tmux.Pane, stream *tmux.ControlStream) {
+	// Detaching ends the output loop, which closes the connection
+	defer func() { _ = stream.Close() }()
+
+	for {
+		msgType, msgBytes, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.Debug("websocket read error", "error", err)
+			}
+			return
+		}
+
+		if msgType == websocket.BinaryMessage {
+			if err := stream.SendBytes(msgBytes); err != nil {
+				slog.Debug("raw input failed", "error", err)
+				return
+			}
+			if bytes.ContainsRune(msgBytes, '\r') {
+				s.responses.Answered(windowKey(pane), time.Now())
+			}
+			continue
+		}
+
+		var msg WSMessage
+		if err := json.Unmarshal(msgBytes, &msg); err != nil || msg.Type != "resize" {
+			continue
+		}
+		var resize WSResize
+		if err := json.Unmarshal(msg.Data, &resize); err != nil || resize.Cols <= 0 || resize.Rows <= 0 {
+			continue
+		}
+		if err := s.client(pane.Host).ResizeWindow(pane.Session, pane.Window, resize.Cols, resize.Rows); err != nil {
+			slog.Debug("resize window failed", "error", err)
+		}
+	}
+}
+
+// rawSnapshot renders the visible screen followed by a cursor move, as bytes
+// a fresh terminal can replay.
+func rawSnapshot(c *tmux.Client, pane tmux.Pane) ([]byte, error) {
+	_, height, err := c.GetPaneSize(pane)
+	if err != nil {
+		return nil, err
+	}
+	x, y, alternate, err := c.GetCursor(pane)
+	if err != nil {
+		return nil, err
+	}
+	screen, err := c.CaptureRange(pane, 0, height-1)
+	if err != nil {
+		return nil, err
+	}
+	return renderSnapshot(screen, x, y, alternate), nil
+}
+
+func renderSnapshot(screen string, x, y int, alternate bool) []byte {
+	var b bytes.Buffer
+	if alternate {
+		b.WriteString("\x1b[?1049h")
+	}
+	b.WriteString("\x1b[H\x1b[2J")
+	lines := strings.Split(strings.TrimSuffix(screen, "\n"), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[0m")
+	}
+	fmt.Fprintf(&b, "\x1b[%d;%dH", y+1, x+1)
+	return b.Bytes()
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// PaneChoices is the payload of a pane stream's choices event.
+type PaneChoices struct {
+	Choices    []string `json:"choices"`               // Verified by the agent; may be offered as actions
+	RawChoices []string `json:"raw_choices,omitempty"` // Read from output text; display only
+}
+
+// handlePaneEvents serves GET /api/pane/{target}/events: a read-only pane
+// stream over SSE for clients that can't hold a WebSocket (EventSource,
+// curl, scripts). Each change is a named event carrying JSON:
+//
+//	event: output   WSOutput, with its id as the event id
+//	event: patch    WSPatch against the previous output (with ?patch=1)
+//	event: meta     WSMeta without choices
+//	event: choices  PaneChoices, sent when they change (empty when answered)
+//
+// A reconnect with Last-Event-ID (or ?resume=) naming the current output
+// skips resending it.
+func (s *Server) handlePaneEvents(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	_, _ = fmt.Fprintf(w, ": connected\n\n")
+	flusher.Flush()
+
+	resume := r.Header.Get("Last-Event-ID")
+	if resume == "" {
+		resume = r.URL.Query().Get("resume")
+	}
+	colors := wantColors(r)
+	patches := r.URL.Query().Get("patch") == "1"
+
+	// Hook status changes for this pane's session trigger an immediate capture
+	nudge := make(chan struct{}, 1)
+	if pane.Host == "" {
+		events, unsubscribe := s.watcher.Subscribe()
+		defer unsubscribe()
+		go func() {
+			for ev := range events {
+				if ev.Session != pane.Session || !hookPush(ev) {
+					continue
+				}
+				select {
+				case nudge <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+
+	snapshots, unsubscribe := s.paneService.subscribe(pane, info)
+	defer unsubscribe()
+
+	var lastOutput string
+	shown := false // The client shows lastOutput
+	var lastMeta WSMeta
+	var lastChoices PaneChoices
+	first := true
+	for {
+		var snap paneSnapshot
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.draining:
+			_, _ = fmt.Fprintf(w, "retry: 500\n\n")
+			flusher.Flush()
+			return
+		case <-nudge:
+			s.paneService.refresh(pane)
+			continue
+		case next, ok := <-snapshots:
+			if !ok {
+				return
+			}
+			snap = next
+		}
+		output, meta := paneView(snap, colors)
+		choices := PaneChoices{Choices: meta.Choices, RawChoices: meta.RawChoices}
+		if choices.Choices == nil {
+			choices.Choices = []string{}
+		}
+		meta.Choices, meta.RawChoices = nil, nil
+
+		if output != lastOutput {
+			// A reconnecting client already shows this output
+			if id := outputID(output); id != resume {
+				event, payload := outputMessage(lastOutput, shown, output, patches)
+				if err := writePaneEvent(w, event, id, payload); err != nil {
+					return
+				}
+			}
+			lastOutput, shown = output, true
+			resume = ""
+		}
+		if first || !metaEqual(meta, lastMeta) {
+			lastMeta = meta
+			if err := writePaneEvent(w, "meta", "", meta); err != nil {
+				return
+			}
+		}
+		if first || !slices.Equal(choices.Choices, lastChoices.Choices) || !slices.Equal(choices.RawChoices, lastChoices.RawChoices) {
+			lastChoices = choices
+			if err := writePaneEvent(w, "choices", "", choices); err != nil {
+				return
+			}
+		}
+		first = false
+		flusher.Flush()
+	}
+}
+
+// writePaneEvent writes one named SSE event with a JSON payload. Events
+// without an id keep the client's Last-Event-ID pointing at the last
+// output.
+func writePaneEvent(w http.ResponseWriter, event, id string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		_, err = fmt.Fprintf(w, "event: %s\nid: %s\ndata: %s\n\n", event, id, data)
+	} else {
+		_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	}
+	return err
+}
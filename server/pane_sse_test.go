@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestWritePaneEvent(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := writePaneEvent(w, "output", "abc", WSOutput{Data: "$ ls\n", ID: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePaneEvent(w, "choices", "", PaneChoices{Choices: []string{}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "event: output\nid: abc\ndata: {\"data\":\"$ ls\\n\",\"id\":\"abc\"}\n\n" +
+		"event: choices\ndata: {\"choices\":[]}\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("events =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestParsePaneTargetSuffixes(t *testing.T) {
+	for _, path := range []string{"/pane/work/events", "/pane/work/tags", "/pane/work/choose", "/pane/work/send-template"} {
+		got, _ := parsePaneTarget(path)
+		if want := (tmux.Pane{Session: "work"}); got != want {
+			t.Errorf("parsePaneTarget(%q) = %+v, want %+v", path, got, want)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/noamsto/houston/agents"
 	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/internal/ansi"
 	"github.com/noamsto/houston/parser"
 	"github.com/noamsto/houston/tmux"
 )
@@ -26,16 +27,21 @@ type WSMessage struct {
 
 type WSOutput struct {
 	Data string `json:"data"`
+	ID   string `json:"id"` // Pass back as ?resume= on reconnect to skip unchanged output
 }
 
 type WSMeta struct {
 	Agent      agents.AgentType `json:"agent"`
 	Mode       string           `json:"mode"`
 	Status     string           `json:"status"`
-	Choices    []string         `json:"choices,omitempty"`
+	Choices    []string         `json:"choices,omitempty"`     // Verified by the agent; rendered as buttons
+	RawChoices []string         `json:"raw_choices,omitempty"` // Read from output text; display only
 	Suggestion string           `json:"suggestion,omitempty"`
 	StatusLine string           `json:"status_line,omitempty"`
 	Activity   string           `json:"activity,omitempty"`
+
+	ClaudeStatus *claude.ClaudeStatus `json:"claude_status,omitempty"` // Parsed from StatusLine
+	TmuxMode     *tmux.ModeState      `json:"tmux_mode,omitempty"`     // Set while the pane is in copy-mode or another tmux mode
 }
 
 type WSInput struct {
@@ -50,21 +56,62 @@ type WSResize struct {
 func (s *Server) handlePaneWS(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		slog.Error("websocket upgrade failed", "error", err)
+		slog.ErrorContext(r.Context(), "websocket upgrade failed", "error", err)
 		return
 	}
 	defer func() { _ = conn.Close() }()
 
-	slog.Info("pane websocket connected", "target", pane.Target())
+	// On shutdown, tell the client to reconnect (to the houston taking
+	// over). Closing the connection ends the read and write loops.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-s.draining:
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseServiceRestart, "houston restarting"), time.Now().Add(time.Second))
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	if r.URL.Query().Get("mode") == "raw" {
+		s.handlePaneRawWS(conn, pane)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "pane websocket connected", "target", pane.Target())
 
 	// nudge signals the write loop to capture immediately after input
 	nudge := make(chan struct{}, 1)
 
-	go s.paneWSReadLoop(conn, pane, nudge)
-	s.paneWSWriteLoop(conn, pane, nudge)
+	// Hook status changes for this pane's session trigger an immediate capture
+	if pane.Host == "" {
+		events, unsubscribe := s.watcher.Subscribe()
+		defer unsubscribe()
+		go func() {
+			for ev := range events {
+				if ev.Session != pane.Session || !hookPush(ev) {
+					continue
+				}
+				select {
+				case nudge <- struct{}{}:
+				default:
+				}
+			}
+		}()
+	}
+
+	// The write loop ends with the read loop, once the client is gone
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		s.paneWSReadLoop(conn, pane, nudge, auditRequest(r, "ws input", pane.Key()))
+	}()
+	s.paneWSWriteLoop(conn, pane, nudge, closed, wantColors(r), r.URL.Query().Get("patch") == "1", r.URL.Query().Get("resume"))
 }
 
-func (s *Server) paneWSReadLoop(conn *websocket.Conn, pane tmux.Pane, nudge chan<- struct{}) {
+func (s *Server) paneWSReadLoop(conn *websocket.Conn, pane tmux.Pane, nudge chan<- struct{}, sent AuditEntry) {
 	defer func() { _ = conn.Close() }()
 
 	for {
@@ -88,8 +135,13 @@ func (s *Server) paneWSReadLoop(conn *websocket.Conn, pane tmux.Pane, nudge chan
 			if err := json.Unmarshal(msg.Data, &input); err != nil {
 				continue
 			}
-			if err := s.tmux.SendKeys(pane, input.Data, false); err != nil {
+			// Keystrokes, Enter included as \r: typed as they come
+			if err := s.client(pane.Host).SendText(pane, input.Data, false, tmux.InputType); err != nil {
 				slog.Error("send keys failed", "error", err)
+			} else {
+				s.responses.Answered(windowKey(pane), time.Now())
+				sent.Detail = input.Data
+				s.audit(sent)
 			}
 			// Signal write loop to capture immediately
 			select {
@@ -105,10 +157,10 @@ func (s *Server) paneWSReadLoop(conn *websocket.Conn, pane tmux.Pane, nudge chan
 			if resize.Cols > 0 && resize.Rows > 0 {
 				// Resize the window (not just the pane) so tmux allows the
 				// full dimensions even when another smaller client is attached.
-				if err := s.tmux.ResizeWindow(pane.Session, pane.Window, resize.Cols, resize.Rows); err != nil {
+				if err := s.client(pane.Host).ResizeWindow(pane.Session, pane.Window, resize.Cols, resize.Rows); err != nil {
 					slog.Debug("resize window failed, falling back to pane resize", "error", err)
-					_ = s.tmux.ResizePane(pane, "x", resize.Cols)
-					_ = s.tmux.ResizePane(pane, "y", resize.Rows)
+					_ = s.client(pane.Host).ResizePane(pane, "x", resize.Cols)
+					_ = s.client(pane.Host).ResizePane(pane, "y", resize.Rows)
 				}
 				// Signal write loop to capture immediately with new dimensions
 				select {
@@ -120,74 +172,45 @@ func (s *Server) paneWSReadLoop(conn *websocket.Conn, pane tmux.Pane, nudge chan
 	}
 }
 
-func (s *Server) paneWSWriteLoop(conn *websocket.Conn, pane tmux.Pane, nudge <-chan struct{}) {
-	ticker := time.NewTicker(200 * time.Millisecond)
-	defer ticker.Stop()
-
+func (s *Server) paneWSWriteLoop(conn *websocket.Conn, pane tmux.Pane, nudge, closed <-chan struct{}, colors, patches bool, resume string) {
 	var lastOutput string
 	var lastMeta WSMeta
+	shown := false // The client shows lastOutput
 
 	// Get initial pane info for agent detection
-	panes, _ := s.tmux.ListPanes(pane.Session, pane.Window)
-	var panePath, paneCommand string
-	for _, p := range panes {
-		if p.Index == pane.Index {
-			panePath = p.Path
-			paneCommand = p.Command
-			break
-		}
-	}
+	info, _ := s.lookupPaneInfo(pane)
+	snapshots, unsubscribe := s.paneService.subscribe(pane, info)
+	defer unsubscribe()
 
 	for {
+		var snap paneSnapshot
 		select {
-		case <-ticker.C:
-		case <-nudge:
-			// Brief pause to let the process update its output after receiving input
-			time.Sleep(50 * time.Millisecond)
-			ticker.Reset(200 * time.Millisecond)
-		}
-		capture, err := s.tmux.CapturePaneWithMode(pane, 500)
-		if err != nil {
-			slog.Debug("capture failed", "error", err)
+		case <-closed:
 			return
+		case <-nudge:
+			s.paneService.refresh(pane)
+			continue
+		case next, ok := <-snapshots:
+			if !ok {
+				return
+			}
+			snap = next
 		}
-
-		// Detect agent and parse state
-		paneID := pane.Target()
-		agent := s.registry.Detect(paneID, paneCommand, capture.Output)
-		parseResult := getAgentState(agent, panePath, capture.Output)
-		filteredOutput := agent.FilterStatusBar(capture.Output)
-
-		// Build metadata
-		meta := WSMeta{
-			Agent:    agent.Type(),
-			Mode:     modeToString(parseResult.Mode),
-			Activity: parseResult.Activity,
-		}
-
-		if len(parseResult.Choices) > 0 {
-			meta.Choices = parseResult.Choices
-		}
-
-		statusLine := agent.ExtractStatusLine(capture.Output)
-		if statusLine != "" {
-			meta.StatusLine = statusLine
-		}
-
-		if agent.Type() == agents.AgentClaudeCode {
-			meta.Suggestion = claude.ExtractSuggestion(capture.Output)
-		}
-
-		meta.Status = resultTypeToString(parseResult.Type)
+		filteredOutput, meta := paneView(snap, colors)
 
 		// Send output if changed
 		if filteredOutput != lastOutput {
-			lastOutput = filteredOutput
-			outputJSON, _ := json.Marshal(WSOutput{Data: filteredOutput})
-			msg, _ := json.Marshal(WSMessage{Type: "output", Data: outputJSON})
-			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
-				return
+			// A reconnecting client already shows this output
+			if outputID(filteredOutput) != resume {
+				msgType, payload := outputMessage(lastOutput, shown, filteredOutput, patches)
+				outputJSON, _ := json.Marshal(payload)
+				msg, _ := json.Marshal(WSMessage{Type: msgType, Data: outputJSON})
+				if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+					return
+				}
 			}
+			lastOutput, shown = filteredOutput, true
+			resume = ""
 		}
 
 		// Send meta if changed
@@ -202,6 +225,54 @@ func (s *Server) paneWSWriteLoop(conn *websocket.Conn, pane tmux.Pane, nudge <-c
 	}
 }
 
+// paneView renders a pane snapshot for pane streams: its output without
+// the agent's status bar, or the history it's scrolled back to in
+// copy-mode, and the agent state read from it.
+func paneView(snap paneSnapshot, colors bool) (string, WSMeta) {
+	agent, parseResult := snap.agent, snap.result
+	filteredOutput := snap.view
+	if !snap.mode.Scrolled() {
+		filteredOutput = agent.FilterStatusBar(snap.view)
+	}
+	if !colors {
+		filteredOutput = ansi.Strip(filteredOutput)
+	}
+
+	// Build metadata
+	meta := WSMeta{
+		Agent:    agent.Type(),
+		Mode:     modeToString(parseResult.Mode),
+		Activity: parseResult.Activity,
+	}
+
+	if len(parseResult.Choices) > 0 {
+		meta.Choices = parseResult.Choices
+	}
+	if len(parseResult.RawChoices) > 0 {
+		meta.RawChoices = parseResult.RawChoices
+	}
+
+	statusLine := agent.ExtractStatusLine(snap.output)
+	if statusLine != "" {
+		meta.StatusLine = statusLine
+	}
+
+	if agent.Type() == agents.AgentClaudeCode {
+		meta.Suggestion = claude.ExtractSuggestion(snap.output)
+		if status := claude.ParseStatus(statusLine); status.Found() {
+			meta.ClaudeStatus = &status
+		}
+	}
+
+	if snap.mode.InMode {
+		mode := snap.mode
+		meta.TmuxMode = &mode
+	}
+
+	meta.Status = resultTypeToString(parseResult.Type)
+	return filteredOutput, meta
+}
+
 func metaEqual(a, b WSMeta) bool {
 	return a.Agent == b.Agent &&
 		a.Mode == b.Mode &&
@@ -209,7 +280,24 @@ func metaEqual(a, b WSMeta) bool {
 		a.Suggestion == b.Suggestion &&
 		a.StatusLine == b.StatusLine &&
 		a.Activity == b.Activity &&
-		slices.Equal(a.Choices, b.Choices)
+		statusEqual(a.ClaudeStatus, b.ClaudeStatus) &&
+		modeEqual(a.TmuxMode, b.TmuxMode) &&
+		slices.Equal(a.Choices, b.Choices) &&
+		slices.Equal(a.RawChoices, b.RawChoices)
+}
+
+func statusEqual(a, b *claude.ClaudeStatus) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func modeEqual(a, b *tmux.ModeState) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 func modeToString(m parser.Mode) string {
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+// PaneKeysResult is the reply of /api/pane/{target}/interrupt, /escape and
+// /clear: the agent the keys were chosen for and what was sent.
+type PaneKeysResult struct {
+	Agent agents.AgentType `json:"agent"`
+	Steps []MacroStep      `json:"steps"`
+}
+
+// paneKeyActions maps each key action to its steps per agent. Agents
+// without their own entry get AgentGeneric's, which suit shells and most
+// programs; a nil entry means the agent has no such action.
+var paneKeyActions = map[string]map[agents.AgentType][]MacroStep{
+	// Stop what's running: agents cancel their turn on Escape and would
+	// exit on C-c
+	"interrupt": {
+		agents.AgentClaudeCode: {{Key: "Escape"}},
+		agents.AgentAmp:        {{Key: "Escape"}},
+		agents.AgentOpenCode:   {{Key: "Escape"}},
+		agents.AgentGeneric:    {{Key: "C-c"}},
+	},
+	// Dismiss a menu or prompt
+	"escape": {
+		agents.AgentGeneric: {{Key: "Escape"}},
+	},
+	// Start over: a new conversation, or a clear screen in a shell
+	"clear": {
+		agents.AgentClaudeCode: {{Text: "/clear"}, {Key: "Enter"}},
+		agents.AgentOpenCode:   {{Text: "/clear"}, {Key: "Enter"}},
+		agents.AgentAmp:        nil,
+		agents.AgentGeneric:    {{Key: "C-l"}},
+	},
+}
+
+// paneKeySteps returns the steps of a key action for an agent, and false
+// when the agent has no such action.
+func paneKeySteps(action string, agent agents.AgentType) ([]MacroStep, bool) {
+	byAgent := paneKeyActions[action]
+	steps, ok := byAgent[agent]
+	if !ok {
+		steps = byAgent[agents.AgentGeneric]
+	}
+	return steps, len(steps) > 0
+}
+
+// handlePaneKeys handles POST /api/pane/{target}/{interrupt,escape,clear}:
+// send the keys that do action in whatever the pane runs, so clients
+// needn't know that Claude stops on Escape while a shell wants C-c.
+func (s *Server) handlePaneKeys(w http.ResponseWriter, r *http.Request, pane tmux.Pane, action string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	c := s.client(pane.Host).WithContext(r.Context())
+	output, err := c.CapturePane(pane, 100)
+	if err != nil {
+		http.Error(w, "failed to capture pane", http.StatusInternalServerError)
+		return
+	}
+	agent := s.registry.Detect(pane.Key(), info.Command, output).Type()
+
+	steps, ok := paneKeySteps(action, agent)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%s has no %s action", agent, action), http.StatusConflict)
+		return
+	}
+	keys := make([]string, 0, len(steps))
+	for _, step := range steps {
+		keys = append(keys, step.Key+step.Text)
+	}
+	auditDetail(r, action+": "+strings.Join(keys, " "))
+	if err := playMacro(c, pane, steps, time.Sleep); err != nil {
+		slog.ErrorContext(r.Context(), "pane keys failed", "pane", pane.Target(), "action", action, "error", err)
+		http.Error(w, "failed to send keys: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "pane keys sent", "pane", pane.Target(), "action", action, "agent", agent, "keys", keys)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(PaneKeysResult{Agent: agent, Steps: steps})
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/noamsto/houston/agents"
+)
+
+func TestPaneKeySteps(t *testing.T) {
+	tests := []struct {
+		action string
+		agent  agents.AgentType
+		want   []MacroStep
+	}{
+		{"interrupt", agents.AgentClaudeCode, []MacroStep{{Key: "Escape"}}},
+		{"interrupt", agents.AgentGeneric, []MacroStep{{Key: "C-c"}}},
+		{"escape", agents.AgentAmp, []MacroStep{{Key: "Escape"}}},
+		{"clear", agents.AgentClaudeCode, []MacroStep{{Text: "/clear"}, {Key: "Enter"}}},
+		{"clear", agents.AgentGeneric, []MacroStep{{Key: "C-l"}}},
+	}
+	for _, tt := range tests {
+		got, ok := paneKeySteps(tt.action, tt.agent)
+		if !ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("paneKeySteps(%s, %s) = %v, %v; want %v", tt.action, tt.agent, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := paneKeySteps("clear", agents.AgentAmp); ok {
+		t.Error("clear for amp has steps")
+	}
+	if _, ok := paneKeySteps("reboot", agents.AgentGeneric); ok {
+		t.Error("unknown action has steps")
+	}
+}
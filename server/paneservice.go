@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+// paneCaptureLines is how much scrollback pane views capture.
+const paneCaptureLines = 500
+
+// paneSnapshot is a pane's capture and what its agent makes of it.
+type paneSnapshot struct {
+	output string // capture-pane output with escapes, before filtering
+	view   string // What the pane shows: output, or the history a pane in copy-mode is scrolled to
+	mode   tmux.ModeState
+	agent  agents.Agent
+	result parser.Result
+	at     time.Time
+}
+
+// paneStateService owns capturing a pane, detecting its agent and parsing
+// its state, so the pane page, its streams and the sessions build read the
+// same result rather than each capturing and parsing the pane again.
+// Streams subscribe: a watched pane is captured by a single poller, however
+// many clients watch it, and each gets the snapshots that differ from the
+// last.
+type paneStateService struct {
+	capturePane func(ctx context.Context, pane tmux.Pane, lines int) (string, error)
+	// captureView captures what a pane in copy-mode is scrolled to, or
+	// returns "" when it shows its bottom; nil treats every pane as live.
+	captureView func(ctx context.Context, pane tmux.Pane, lines int) (string, tmux.ModeState, error)
+	registry    *agents.Registry
+	interval    time.Duration // Between captures of a watched pane, and how long a snapshot is fresh
+
+	mu    sync.Mutex
+	panes map[string]*watchedPane // Pane key -> state
+}
+
+type watchedPane struct {
+	pane tmux.Pane
+	info tmux.PaneInfo // Command and directory, for detection
+	last paneSnapshot  // Zero until captured
+
+	subs  map[chan paneSnapshot]struct{}
+	nudge chan struct{}
+	stop  chan struct{} // nil while no poller runs
+}
+
+func newPaneStateService(capturePane func(context.Context, tmux.Pane, int) (string, error), registry *agents.Registry, interval time.Duration) *paneStateService {
+	return &paneStateService{capturePane: capturePane, registry: registry, interval: interval, panes: make(map[string]*watchedPane)}
+}
+
+// get returns the pane's state, captured now unless a snapshot is fresh.
+func (p *paneStateService) get(ctx context.Context, pane tmux.Pane, info tmux.PaneInfo) (paneSnapshot, error) {
+	if snap, ok := p.peek(pane); ok {
+		return snap, nil
+	}
+	snap, err := p.capture(ctx, pane, info)
+	if err != nil {
+		return paneSnapshot{}, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w := p.watchLocked(pane)
+	w.info = info
+	if snap.at.After(w.last.at) {
+		w.last = snap
+	}
+	return snap, nil
+}
+
+// peek returns the pane's snapshot if it's fresh, without capturing.
+func (p *paneStateService) peek(pane tmux.Pane) (paneSnapshot, bool) {
+	if p == nil {
+		return paneSnapshot{}, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w, ok := p.panes[pane.Key()]
+	if !ok || w.last.at.IsZero() || time.Since(w.last.at) >= p.interval {
+		return paneSnapshot{}, false
+	}
+	return w.last, true
+}
+
+// subscribe delivers the pane's snapshots as they change, starting with
+// the current one. The channel holds only the latest snapshot, so a slow
+// reader skips states rather than holding up the others. It's closed when
+// the pane can't be captured any more, e.g. once it's gone.
+func (p *paneStateService) subscribe(pane tmux.Pane, info tmux.PaneInfo) (<-chan paneSnapshot, func()) {
+	ch := make(chan paneSnapshot, 1)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w := p.watchLocked(pane)
+	w.subs[ch] = struct{}{}
+	if w.stop == nil {
+		w.info = info
+		w.stop = make(chan struct{})
+		go p.poll(w, w.stop)
+	} else if !w.last.at.IsZero() {
+		ch <- w.last
+	}
+
+	return ch, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if _, ok := w.subs[ch]; !ok {
+			return
+		}
+		delete(w.subs, ch)
+		if len(w.subs) == 0 && w.stop != nil {
+			close(w.stop)
+			w.stop = nil
+		}
+	}
+}
+
+// refresh captures a watched pane now, e.g. right after input was sent.
+func (p *paneStateService) refresh(pane tmux.Pane) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w, ok := p.panes[pane.Key()]; ok {
+		select {
+		case w.nudge <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// poll captures a watched pane until its last subscriber leaves.
+func (p *paneStateService) poll(w *watchedPane, stop <-chan struct{}) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	var last paneSnapshot
+	for {
+		p.mu.Lock()
+		info := w.info
+		p.mu.Unlock()
+
+		snap, err := p.capture(context.Background(), w.pane, info)
+		p.mu.Lock()
+		select {
+		case <-stop:
+			p.mu.Unlock()
+			return
+		default:
+		}
+		if err != nil {
+			// The pane is gone; its watchers are done
+			slog.Debug("capture failed", "pane", w.pane.Key(), "error", err)
+			for ch := range w.subs {
+				close(ch)
+				delete(w.subs, ch)
+			}
+			close(w.stop)
+			w.stop = nil
+			p.mu.Unlock()
+			return
+		}
+		w.last = snap
+		if last.at.IsZero() || snap.output != last.output || snap.view != last.view || snap.mode != last.mode ||
+			snap.agent.Type() != last.agent.Type() || !reflect.DeepEqual(snap.result, last.result) {
+			for ch := range w.subs {
+				select {
+				case <-ch: // Replace a snapshot not read yet
+				default:
+				}
+				ch <- snap
+			}
+		}
+		p.mu.Unlock()
+		last = snap
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-w.nudge:
+			// Brief pause to let the process update its output after receiving input
+			time.Sleep(50 * time.Millisecond)
+			ticker.Reset(p.interval)
+		}
+	}
+}
+
+// capture captures the pane and reads its agent's state. The state is read
+// from the live output even while the pane is scrolled back.
+func (p *paneStateService) capture(ctx context.Context, pane tmux.Pane, info tmux.PaneInfo) (paneSnapshot, error) {
+	output, err := p.capturePane(ctx, pane, paneCaptureLines)
+	if err != nil {
+		return paneSnapshot{}, err
+	}
+	agent := p.registry.Detect(pane.Key(), info.Command, output)
+	snap := paneSnapshot{
+		output: output,
+		view:   output,
+		agent:  agent,
+		result: getAgentState(ctx, agent, agentStatePath(pane.Host, info.Path), output),
+		at:     time.Now(),
+	}
+	if p.captureView != nil {
+		if view, mode, err := p.captureView(ctx, pane, paneCaptureLines); err == nil {
+			snap.mode = mode
+			if view != "" {
+				snap.view = view
+			}
+		}
+	}
+	return snap, nil
+}
+
+// watchLocked returns the pane's entry, adding it. Entries nobody watches
+// or asked about for a minute are dropped on the way. p.mu must be held.
+func (p *paneStateService) watchLocked(pane tmux.Pane) *watchedPane {
+	key := pane.Key()
+	if w, ok := p.panes[key]; ok {
+		return w
+	}
+	for k, w := range p.panes {
+		if len(w.subs) == 0 && time.Since(w.last.at) > time.Minute {
+			delete(p.panes, k)
+		}
+	}
+	w := &watchedPane{pane: pane, subs: make(map[chan paneSnapshot]struct{}), nudge: make(chan struct{}, 1)}
+	p.panes[key] = w
+	return w
+}
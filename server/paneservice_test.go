@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/agents/generic"
+	"github.com/noamsto/houston/tmux"
+)
+
+// fakeScreen stands in for capture-pane.
+type fakeScreen struct {
+	mu       sync.Mutex
+	output   string
+	gone     bool
+	captures int
+}
+
+func (f *fakeScreen) capture(context.Context, tmux.Pane, int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.captures++
+	if f.gone {
+		return "", errors.New("can't find pane")
+	}
+	return f.output, nil
+}
+
+func (f *fakeScreen) set(output string, gone bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.output, f.gone = output, gone
+}
+
+func TestPaneStateService(t *testing.T) {
+	screen := &fakeScreen{output: "$ make"}
+	p := newPaneStateService(screen.capture, agents.NewRegistry(generic.New()), 50*time.Millisecond)
+	pane := tmux.Pane{Session: "work", Window: 1}
+	next := func(ch <-chan paneSnapshot) (paneSnapshot, bool) {
+		select {
+		case snap, ok := <-ch:
+			return snap, ok
+		case <-time.After(time.Second):
+			t.Fatal("no snapshot within a second")
+			return paneSnapshot{}, false
+		}
+	}
+
+	a, unsubscribeA := p.subscribe(pane, tmux.PaneInfo{Command: "zsh"})
+	if snap, _ := next(a); snap.output != "$ make" || snap.agent.Type() != agents.AgentGeneric {
+		t.Fatalf("first snapshot = %+v", snap)
+	}
+	// A second watcher shares the poller and starts from the current state
+	b, unsubscribeB := p.subscribe(pane, tmux.PaneInfo{Command: "zsh"})
+	if snap, _ := next(b); snap.output != "$ make" {
+		t.Fatalf("second watcher's first snapshot = %q", snap.output)
+	}
+	if snap, ok := p.peek(pane); !ok || snap.output != "$ make" {
+		t.Errorf("peek = %q, %v", snap.output, ok)
+	}
+
+	// Unchanged output isn't sent again
+	time.Sleep(120 * time.Millisecond)
+	select {
+	case snap := <-a:
+		t.Errorf("unchanged snapshot sent: %q", snap.output)
+	default:
+	}
+
+	screen.set("$ make\nok", false)
+	p.refresh(pane)
+	for _, ch := range []<-chan paneSnapshot{a, b} {
+		if snap, _ := next(ch); snap.output != "$ make\nok" {
+			t.Errorf("snapshot after a change = %q", snap.output)
+		}
+	}
+
+	unsubscribeA()
+	screen.set("", true)
+	if _, ok := next(b); ok {
+		t.Error("watching a pane that's gone didn't end")
+	}
+	unsubscribeB()
+
+	// With nobody watching, get captures once the snapshot is stale
+	screen.set("$ ", false)
+	time.Sleep(60 * time.Millisecond)
+	screen.mu.Lock()
+	before := screen.captures
+	screen.mu.Unlock()
+	if snap, err := p.get(context.Background(), pane, tmux.PaneInfo{}); err != nil || snap.output != "$ " {
+		t.Fatalf("get = %q, %v", snap.output, err)
+	}
+	if _, err := p.get(context.Background(), pane, tmux.PaneInfo{}); err != nil {
+		t.Fatal(err)
+	}
+	screen.mu.Lock()
+	defer screen.mu.Unlock()
+	if screen.captures-before != 1 {
+		t.Errorf("two gets in a row captured %d times, want once", screen.captures-before)
+	}
+}
+
+func TestPaneStateServiceScrolledView(t *testing.T) {
+	screen := &fakeScreen{output: "$ make\nok"}
+	p := newPaneStateService(screen.capture, agents.NewRegistry(generic.New()), 50*time.Millisecond)
+	scrolled := tmux.ModeState{InMode: true, Mode: "copy-mode", ScrollPosition: 40}
+	p.captureView = func(context.Context, tmux.Pane, int) (string, tmux.ModeState, error) {
+		return "$ make\nbuilding...", scrolled, nil
+	}
+	pane := tmux.Pane{Session: "work", Window: 1}
+
+	snap, err := p.get(context.Background(), pane, tmux.PaneInfo{Command: "zsh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Shown scrolled back, while the state is read from the live bottom
+	if snap.output != "$ make\nok" || snap.view != "$ make\nbuilding..." || snap.mode != scrolled {
+		t.Errorf("scrolled snapshot = %q, %q, %+v", snap.output, snap.view, snap.mode)
+	}
+	if view, meta := paneView(snap, true); view != snap.view || meta.TmuxMode == nil || *meta.TmuxMode != scrolled {
+		t.Errorf("paneView = %q, %+v", view, meta.TmuxMode)
+	}
+
+	p.captureView = func(context.Context, tmux.Pane, int) (string, tmux.ModeState, error) {
+		return "", tmux.ModeState{}, nil
+	}
+	if snap, _ := p.capture(context.Background(), pane, tmux.PaneInfo{Command: "zsh"}); snap.view != snap.output {
+		t.Errorf("live view = %q, want the output %q", snap.view, snap.output)
+	}
+}
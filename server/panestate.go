@@ -0,0 +1,135 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// Settled window states (PaneState.State).
+const (
+	PaneIdle      = "idle"
+	PaneWorking   = "working"
+	PaneCooling   = "cooling" // Stopped working within the session's active TTL
+	PaneAttention = "attention"
+)
+
+// workingHold is how long a window stays working after the last capture
+// that showed it working, so a spinner missed by one capture doesn't flip
+// it to idle and back.
+const workingHold = 10 * time.Second
+
+// paneStateExpiry is how long a window that is no longer seen keeps its
+// state, e.g. while its session is briefly unreachable.
+const paneStateExpiry = time.Hour
+
+// PaneState is the settled state of a window. Views read it rather than
+// the latest capture, so a window flapping between working and idle from
+// one capture to the next doesn't jump between sections.
+type PaneState struct {
+	State string    `json:"state"`          // idle, working, cooling, attention
+	Since time.Time `json:"since"`          // When the state was entered
+	From  string    `json:"from,omitempty"` // State before the last transition
+}
+
+// active reports whether the window keeps its session in Active.
+func (p PaneState) active() bool {
+	return p.State == PaneWorking || p.State == PaneCooling
+}
+
+// paneStates holds the state machine of every window:
+//   - attention is entered as soon as a capture shows it, and left as soon
+//     as one doesn't
+//   - working is entered as soon as a capture shows it, and held for
+//     workingHold after the last one that did (unless the agent reported
+//     its turn done)
+//   - a window that stopped working cools down for the session's active
+//     TTL before it is idle
+type paneStates struct {
+	mu     sync.Mutex
+	states map[string]*paneEntry // Window key -> state
+}
+
+type paneEntry struct {
+	PaneState
+	LastWorking time.Time `json:"last_working"` // Last capture that showed it working
+	seen        time.Time
+}
+
+func newPaneStates() *paneStates {
+	return &paneStates{states: make(map[string]*paneEntry)}
+}
+
+// observe feeds a capture of a window to its state machine and returns the
+// settled state. finished is set when the agent reported its turn done.
+func (p *paneStates) observe(key string, attention, working, finished bool, ttl time.Duration, now time.Time) PaneState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.states[key]
+	if !ok {
+		e = &paneEntry{}
+		p.states[key] = e
+	}
+	e.seen = now
+	if working {
+		e.LastWorking = now
+	}
+
+	next := PaneIdle
+	quiet := now.Sub(e.LastWorking)
+	switch {
+	case attention:
+		next = PaneAttention
+	case working:
+		next = PaneWorking
+	case e.LastWorking.IsZero():
+	case quiet < workingHold && !finished && e.State == PaneWorking:
+		next = PaneWorking
+	case quiet < ttl:
+		next = PaneCooling
+	}
+
+	if !ok {
+		e.State, e.Since = next, now
+	} else if next != e.State {
+		e.From, e.State, e.Since = e.State, next, now
+	}
+	return e.PaneState
+}
+
+// prune forgets windows not seen for paneStateExpiry.
+func (p *paneStates) prune(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, e := range p.states {
+		if now.Sub(e.seen) > paneStateExpiry {
+			delete(p.states, key)
+		}
+	}
+}
+
+// export returns every window's state machine, for the handoff to the next
+// process.
+func (p *paneStates) export() map[string]paneEntry {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	result := make(map[string]paneEntry, len(p.states))
+	for key, e := range p.states {
+		result[key] = *e
+	}
+	return result
+}
+
+// restore carries over window states from a previous houston process, so a
+// restart doesn't demote every cooling session to Idle.
+func (p *paneStates) restore(states map[string]paneEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for key, e := range states {
+		if _, ok := p.states[key]; !ok {
+			e.seen = now
+			p.states[key] = &e
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaneStatesHysteresis(t *testing.T) {
+	p := newPaneStates()
+	ttl := 2 * time.Minute
+	start := time.Now()
+
+	steps := []struct {
+		name      string
+		at        time.Duration
+		attention bool
+		working   bool
+		finished  bool
+		want      string
+	}{
+		{"first seen idle", 0, false, false, false, PaneIdle},
+		{"starts working", 1 * time.Second, false, true, false, PaneWorking},
+		{"one idle capture", 2 * time.Second, false, false, false, PaneWorking},
+		{"working again", 3 * time.Second, false, true, false, PaneWorking},
+		{"quiet past the hold", 3*time.Second + workingHold, false, false, false, PaneCooling},
+		{"still within ttl", time.Minute, false, false, false, PaneCooling},
+		{"past ttl", 2*time.Minute + 4*time.Second, false, false, false, PaneIdle},
+		{"prompt", 3 * time.Minute, true, false, false, PaneAttention},
+		{"answered", 3*time.Minute + time.Second, false, true, false, PaneWorking},
+		{"turn done", 3*time.Minute + 2*time.Second, false, false, true, PaneCooling},
+	}
+	for _, step := range steps {
+		got := p.observe("api:1", step.attention, step.working, step.finished, ttl, start.Add(step.at))
+		if got.State != step.want {
+			t.Errorf("%s: state = %s, want %s", step.name, got.State, step.want)
+		}
+	}
+
+	got := p.observe("api:1", false, false, false, ttl, start.Add(3*time.Minute+3*time.Second))
+	if got.From != PaneWorking || !got.Since.Equal(start.Add(3*time.Minute+2*time.Second)) {
+		t.Errorf("state = %+v, want cooling since the turn ended, from working", got)
+	}
+}
+
+func TestPaneStatesPinned(t *testing.T) {
+	p := newPaneStates()
+	ttl := EffectiveTimers{Pinned: true}.activeTTL()
+	start := time.Now()
+
+	p.observe("prod:1", false, true, false, ttl, start)
+	if got := p.observe("prod:1", false, false, true, ttl, start.Add(48*time.Hour)); got.State != PaneCooling {
+		t.Errorf("pinned window state = %s after two quiet days, want %s", got.State, PaneCooling)
+	}
+	if got := p.observe("prod:2", false, false, false, ttl, start); got.State != PaneIdle {
+		t.Errorf("pinned window that never worked = %s, want %s", got.State, PaneIdle)
+	}
+}
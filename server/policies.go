@@ -0,0 +1,457 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/status"
+	"github.com/noamsto/houston/tmux"
+)
+
+// policiesDocument is the store document holding auto-approve policies.
+// It can be edited by hand while houston is stopped.
+const policiesDocument = "policies"
+
+// approvalsLog is the store log holding every auto-approval.
+const approvalsLog = "approvals"
+
+// policyInterval is how often agent panes are checked for permission
+// prompts while any policy is enabled.
+const policyInterval = 2 * time.Second
+
+// policyRepeatDelay keeps the same prompt from being approved twice while
+// the agent redraws after the keystroke.
+const policyRepeatDelay = 10 * time.Second
+
+// Policy auto-approves Claude Code permission prompts it matches: tool, the
+// command (or file, URL) the tool acts on, and the pane's directory.
+type Policy struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Tool    string `json:"tool"`              // Tool name or glob: Bash, Edit, mcp__github__*, *
+	Command string `json:"command,omitempty"` // Regexp the whole detail must match (empty: any)
+	Path    string `json:"path,omitempty"`    // Glob on the pane directory or a parent, ~ for home (empty: any)
+	Enabled bool   `json:"enabled"`
+}
+
+// Approval is an audit record of one auto-approved prompt.
+type Approval struct {
+	At         time.Time `json:"at"`
+	Pane       tmux.Pane `json:"pane"`
+	Path       string    `json:"path"`
+	Tool       string    `json:"tool"`
+	Detail     string    `json:"detail"`
+	Question   string    `json:"question"`
+	Policy     string    `json:"policy"` // Policy ID
+	PolicyName string    `json:"policy_name,omitempty"`
+}
+
+// shellOperatorPattern finds chaining, substitution and redirection, which
+// would let a matching prefix smuggle in another command.
+var shellOperatorPattern = regexp.MustCompile("[;&|<>`\n]|\\$\\(")
+
+type compiledPolicy struct {
+	Policy
+	command *regexp.Regexp
+}
+
+func compilePolicy(p Policy) (compiledPolicy, error) {
+	if strings.TrimSpace(p.Tool) == "" {
+		return compiledPolicy{}, errors.New("tool is required (use * for any tool)")
+	}
+	if _, err := path.Match(p.Tool, ""); err != nil {
+		return compiledPolicy{}, fmt.Errorf("invalid tool pattern: %w", err)
+	}
+	if _, err := path.Match(p.Path, ""); err != nil {
+		return compiledPolicy{}, fmt.Errorf("invalid path pattern: %w", err)
+	}
+	cp := compiledPolicy{Policy: p}
+	if p.Command != "" {
+		re, err := regexp.Compile(`^(?:` + p.Command + `)$`)
+		if err != nil {
+			return compiledPolicy{}, fmt.Errorf("invalid command regexp: %w", err)
+		}
+		cp.command = re
+	}
+	return cp, nil
+}
+
+// match reports whether the policy approves a prompt shown in a pane whose
+// working directory is dir. Bash prompts whose command chains or redirects
+// are never approved, whatever the regexp.
+func (p compiledPolicy) match(prompt claude.PermissionPrompt, dir, home string) bool {
+	if !p.Enabled {
+		return false
+	}
+	if ok, _ := path.Match(p.Tool, prompt.Tool); !ok {
+		return false
+	}
+	if prompt.Tool == "Bash" {
+		for _, line := range prompt.Body {
+			if shellOperatorPattern.MatchString(line) {
+				return false
+			}
+		}
+	}
+	if p.command != nil && !p.command.MatchString(prompt.Detail) {
+		return false
+	}
+	return p.Path == "" || matchPathGlob(p.Path, dir, home)
+}
+
+// matchPathGlob reports whether dir or one of its parents matches pattern.
+func matchPathGlob(pattern, dir, home string) bool {
+	if dir == "" {
+		return false
+	}
+	if home != "" && (pattern == "~" || strings.HasPrefix(pattern, "~/")) {
+		pattern = home + strings.TrimPrefix(pattern, "~")
+	}
+	for d := path.Clean(dir); ; d = path.Dir(d) {
+		if ok, _ := path.Match(pattern, d); ok {
+			return true
+		}
+		if d == "/" || d == "." {
+			return false
+		}
+	}
+}
+
+// policyEngine holds the policies and remembers recent approvals per pane.
+type policyEngine struct {
+	mu       sync.Mutex
+	policies []compiledPolicy
+	recent   map[string]recentApproval // pane key -> last approval
+}
+
+type recentApproval struct {
+	prompt string
+	at     time.Time
+}
+
+func newPolicyEngine() *policyEngine {
+	return &policyEngine{recent: make(map[string]recentApproval)}
+}
+
+func (e *policyEngine) list() []Policy {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	policies := make([]Policy, 0, len(e.policies))
+	for _, p := range e.policies {
+		policies = append(policies, p.Policy)
+	}
+	return policies
+}
+
+func (e *policyEngine) enabled() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, p := range e.policies {
+		if p.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// set replaces or adds a policy, keeping the list ordered by ID.
+func (e *policyEngine) set(p compiledPolicy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range e.policies {
+		if e.policies[i].ID == p.ID {
+			e.policies[i] = p
+			return
+		}
+	}
+	e.policies = append(e.policies, p)
+	sort.Slice(e.policies, func(i, j int) bool { return e.policies[i].ID < e.policies[j].ID })
+}
+
+func (e *policyEngine) get(id string) (Policy, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, p := range e.policies {
+		if p.ID == id {
+			return p.Policy, true
+		}
+	}
+	return Policy{}, false
+}
+
+func (e *policyEngine) remove(id string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, p := range e.policies {
+		if p.ID == id {
+			e.policies = append(e.policies[:i], e.policies[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// decide returns the first policy approving the prompt, unless the same
+// prompt in this pane was approved moments ago.
+func (e *policyEngine) decide(paneKey string, prompt claude.PermissionPrompt, dir, home string, now time.Time) (Policy, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	fingerprint := prompt.Tool + "\x00" + strings.Join(prompt.Body, "\n") + "\x00" + prompt.Question
+	if last, ok := e.recent[paneKey]; ok && last.prompt == fingerprint && now.Sub(last.at) < policyRepeatDelay {
+		return Policy{}, false
+	}
+	for _, p := range e.policies {
+		if p.match(prompt, dir, home) {
+			e.recent[paneKey] = recentApproval{prompt: fingerprint, at: now}
+			return p.Policy, true
+		}
+	}
+	return Policy{}, false
+}
+
+// loadPolicies restores policies from the store. Invalid entries are
+// logged and skipped.
+func (s *Server) loadPolicies() {
+	var policies []Policy
+	if err := s.store.Load(policiesDocument, &policies); err != nil {
+		slog.Warn("failed to load policies", "error", err)
+	}
+	for _, p := range policies {
+		if p.ID == "" {
+			p.ID = newPromptID()
+		}
+		cp, err := compilePolicy(p)
+		if err != nil {
+			slog.Warn("skipping invalid policy", "id", p.ID, "error", err)
+			continue
+		}
+		s.policies.set(cp)
+	}
+}
+
+func (s *Server) savePolicies() error {
+	return s.store.Save(policiesDocument, s.policies.list())
+}
+
+// runPolicies answers matching permission prompts. Panes are checked on a
+// short interval, and right away when a hook reports a permission prompt.
+func (s *Server) runPolicies(ctx context.Context) {
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	ticker := time.NewTicker(policyInterval)
+	defer ticker.Stop()
+	events, unsubscribe := s.watcher.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case ev := <-events:
+			if ev.Removed || ev.Status.Status != status.StatusPermission {
+				continue
+			}
+		}
+		if s.policies.enabled() {
+			s.applyPolicies()
+		}
+	}
+}
+
+func (s *Server) applyPolicies() {
+	home, _ := os.UserHomeDir()
+	for _, sess := range s.listAllSessions(context.Background()) {
+		c := s.client(sess.Host)
+		for _, win := range sess.Windows {
+			for _, info := range win.Panes {
+				pane := tmux.Pane{Host: sess.Host, Session: sess.Name, Window: win.Index, Index: info.Index}
+				s.applyPoliciesToPane(c, pane, info, home)
+			}
+		}
+	}
+}
+
+func (s *Server) applyPoliciesToPane(c *tmux.Client, pane tmux.Pane, info tmux.PaneInfo, home string) {
+	output, err := c.CapturePane(pane, 60)
+	if err != nil {
+		return
+	}
+	if s.registry.Detect(pane.Key(), info.Command, output).Type() != agents.AgentClaudeCode {
+		return
+	}
+	prompt, ok := claude.ParsePermissionPrompt(output)
+	if !ok {
+		return
+	}
+	if pane.Host != "" {
+		home = "" // ~ in a policy means the local home directory
+	}
+	policy, ok := s.policies.decide(pane.Key(), prompt, info.Path, home, time.Now())
+	if !ok {
+		return
+	}
+
+	if err := s.sendText(context.Background(), pane, prompt.Approve, false); err != nil {
+		slog.Error("auto-approve failed", "pane", pane.Key(), "error", err)
+		return
+	}
+	slog.Info("auto-approved", "pane", pane.Key(), "tool", prompt.Tool, "detail", prompt.Detail, "policy", policy.ID)
+
+	approval := Approval{
+		At:         time.Now(),
+		Pane:       pane,
+		Path:       info.Path,
+		Tool:       prompt.Tool,
+		Detail:     prompt.Detail,
+		Question:   prompt.Question,
+		Policy:     policy.ID,
+		PolicyName: policy.Name,
+	}
+	if err := s.store.Append(approvalsLog, approval); err != nil {
+		slog.Warn("failed to record approval", "error", err)
+	}
+	s.audit(AuditEntry{
+		Action: "auto-approve",
+		Target: pane.Key(),
+		Detail: prompt.Tool + " " + prompt.Detail + " (policy " + policy.ID + ")",
+		Client: auditSelf,
+	})
+}
+
+// approvals returns recorded approvals at or after since, newest first.
+func (s *Server) approvals(since time.Time) ([]Approval, error) {
+	result := []Approval{}
+	err := s.store.ReadLog(approvalsLog, func(raw json.RawMessage) error {
+		var a Approval
+		if err := json.Unmarshal(raw, &a); err != nil || a.At.Before(since) {
+			return nil
+		}
+		result = append(result, a)
+		return nil
+	})
+	sort.SliceStable(result, func(i, j int) bool { return result[i].At.After(result[j].At) })
+	return result, err
+}
+
+// handleAPIPolicies serves GET (list) and POST (create) on /api/policies.
+func (s *Server) handleAPIPolicies(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.policies.list())
+	case http.MethodPost:
+		var p Policy
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if p.ID == "" {
+			p.ID = newPromptID()
+		} else if _, exists := s.policies.get(p.ID); exists {
+			http.Error(w, "policy already exists", http.StatusConflict)
+			return
+		}
+		s.savePolicy(w, p, http.StatusCreated)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPIPolicy serves /api/policies/{id} (GET, PUT replaces, DELETE) and
+// GET /api/policies/audit?since=RFC3339, the auto-approval log (default:
+// the last 7 days).
+func (s *Server) handleAPIPolicy(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/policies/")
+
+	if id == "audit" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		since := time.Now().AddDate(0, 0, -defaultHistoryDays)
+		if v := r.URL.Query().Get("since"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "since must be RFC3339", http.StatusBadRequest)
+				return
+			}
+			since = t
+		}
+		approvals, err := s.approvals(since)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to read approvals", "error", err)
+			http.Error(w, "failed to read approvals", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(approvals)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		p, ok := s.policies.get(id)
+		if !ok {
+			http.Error(w, "policy not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(p)
+	case http.MethodPut:
+		var p Policy
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		p.ID = id
+		s.savePolicy(w, p, http.StatusOK)
+	case http.MethodDelete:
+		if !s.policies.remove(id) {
+			http.Error(w, "policy not found", http.StatusNotFound)
+			return
+		}
+		if err := s.savePolicies(); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save policies", "error", err)
+			http.Error(w, "failed to save policies", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(r.Context(), "policy deleted", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) savePolicy(w http.ResponseWriter, p Policy, code int) {
+	cp, err := compilePolicy(p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.policies.set(cp)
+	if err := s.savePolicies(); err != nil {
+		slog.Error("failed to save policies", "error", err)
+		http.Error(w, "failed to save policies", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("policy saved", "id", p.ID, "tool", p.Tool, "command", p.Command, "path", p.Path, "enabled", p.Enabled)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(p)
+}
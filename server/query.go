@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sessionsQuery narrows /api/sessions to matching windows, so clients
+// don't download and discard most of the payload. A window must match
+// every parameter given, and any of a parameter's values.
+type sessionsQuery struct {
+	states  []string       // attention, working, done, idle
+	agents  []string       // Agent types, "claude" for claude-code
+	session *regexp.Regexp // Session name
+	branch  *regexp.Regexp
+	tags    []string // Every one must be set on the window
+}
+
+// parseSessionsQuery reads ?state=, ?agent=, ?session=, ?branch= and
+// ?tag= from a sessions request. session and branch are regular
+// expressions; the others are repeatable.
+func parseSessionsQuery(values url.Values) (sessionsQuery, error) {
+	var q sessionsQuery
+	for _, state := range values["state"] {
+		switch state = strings.ToLower(state); state {
+		case "attention", "working", "done", "idle":
+			q.states = append(q.states, state)
+		default:
+			return sessionsQuery{}, fmt.Errorf("unknown state %q (want attention, working, done or idle)", state)
+		}
+	}
+	for _, agent := range values["agent"] {
+		q.agents = append(q.agents, strings.ToLower(agent))
+	}
+	for name, re := range map[string]**regexp.Regexp{"session": &q.session, "branch": &q.branch} {
+		expr := values.Get(name)
+		if expr == "" {
+			continue
+		}
+		compiled, err := regexp.Compile(expr)
+		if err != nil {
+			return sessionsQuery{}, fmt.Errorf("invalid %s regex: %w", name, err)
+		}
+		*re = compiled
+	}
+	for _, tag := range values["tag"] {
+		q.tags = append(q.tags, strings.ToLower(tag))
+	}
+	return q, nil
+}
+
+// unfiltered reports whether the query asks for every session and window.
+func (q sessionsQuery) unfiltered() bool {
+	return q.session == nil && len(q.states) == 0 && len(q.agents) == 0 && q.branch == nil && len(q.tags) == 0
+}
+
+// matchSession reports whether a session's windows can match, before they
+// are captured.
+func (q sessionsQuery) matchSession(name string) bool {
+	return q.session == nil || q.session.MatchString(name)
+}
+
+// matchWindow reports whether a window matches the query.
+func (q sessionsQuery) matchWindow(w WindowWithStatus) bool {
+	if len(q.states) > 0 && !slices.Contains(q.states, windowState(w)) {
+		return false
+	}
+	if len(q.agents) > 0 && !slices.ContainsFunc(windowFieldValues(w, "agent"), func(agent string) bool {
+		return slices.Contains(q.agents, agent)
+	}) {
+		return false
+	}
+	if q.branch != nil && !q.branch.MatchString(w.Branch) {
+		return false
+	}
+	for _, tag := range q.tags {
+		if !slices.Contains(w.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter keeps a session's matching windows, reporting whether any are
+// left. Attention and working counts follow the windows kept; the session
+// stays in the section all its windows put it in.
+func (q sessionsQuery) filter(sess *SessionWithWindows) bool {
+	if len(q.states) == 0 && len(q.agents) == 0 && q.branch == nil && len(q.tags) == 0 {
+		return true
+	}
+	var windows []WindowWithStatus
+	sess.AttentionCount, sess.HasWorking = 0, false
+	for _, win := range sess.Windows {
+		if !q.matchWindow(win) {
+			continue
+		}
+		windows = append(windows, win)
+		if win.NeedsAttention {
+			sess.AttentionCount++
+		}
+		if win.State.State == PaneWorking {
+			sess.HasWorking = true
+		}
+	}
+	sess.Windows = windows
+	return len(windows) > 0
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestSessionsQuery(t *testing.T) {
+	windows := []WindowWithStatus{
+		{Window: tmux.Window{Name: "ui"}, AgentType: agents.AgentClaudeCode, Branch: "feat/login", NeedsAttention: true, Tags: []string{"frontend"}},
+		{Window: tmux.Window{Name: "api"}, AgentType: agents.AgentAmp, Branch: "main", State: PaneState{State: PaneWorking}},
+		{Window: tmux.Window{Name: "shell"}, AgentType: agents.AgentGeneric, Branch: "main", State: PaneState{State: PaneIdle}},
+	}
+
+	tests := []struct {
+		query string
+		want  string // Names of the windows kept
+	}{
+		{"", "ui api shell"},
+		{"state=attention", "ui"},
+		{"state=working&state=idle", "api shell"},
+		{"agent=claude", "ui"},
+		{"agent=amp&agent=claude-code", "ui api"},
+		{"branch=^feat/", "ui"},
+		{"branch=main&state=working", "api"},
+		{"tag=Frontend", "ui"},
+		{"agent=claude&state=idle", ""},
+	}
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		q, err := parseSessionsQuery(values)
+		if err != nil {
+			t.Fatalf("parseSessionsQuery(%q): %v", tt.query, err)
+		}
+		sess := SessionWithWindows{Windows: windows, AttentionCount: 1, HasWorking: true}
+		kept := q.filter(&sess)
+		var got string
+		for _, w := range sess.Windows {
+			if got != "" {
+				got += " "
+			}
+			got += w.Window.Name
+		}
+		if got != tt.want || kept != (tt.want != "") {
+			t.Errorf("%q: kept %v windows %q, want %q", tt.query, kept, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"state=busy", "session=[", "branch=("} {
+		values, _ := url.ParseQuery(bad)
+		if _, err := parseSessionsQuery(values); err == nil {
+			t.Errorf("parseSessionsQuery(%q) succeeded, want error", bad)
+		}
+	}
+
+	values, _ := url.ParseQuery("session=^prod-")
+	q, _ := parseSessionsQuery(values)
+	if !q.matchSession("prod-api") || q.matchSession("dev") {
+		t.Error("session regex not applied")
+	}
+}
+
+func TestSessionsQueryCounts(t *testing.T) {
+	values, _ := url.ParseQuery("tag=backend")
+	q, _ := parseSessionsQuery(values)
+	sess := SessionWithWindows{
+		AttentionCount: 2,
+		Windows: []WindowWithStatus{
+			{NeedsAttention: true, Tags: []string{"frontend"}},
+			{NeedsAttention: true, Tags: []string{"backend"}, State: PaneState{State: PaneAttention}},
+		},
+	}
+	if !q.filter(&sess) || sess.AttentionCount != 1 || sess.HasWorking {
+		t.Errorf("session = %+v, want one attention window, not working", sess)
+	}
+}
@@ -0,0 +1,402 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+// promptQueuesDocument is the store document holding queued prompts.
+const promptQueuesDocument = "prompt-queues"
+
+// promptQueueInterval is how often panes with queued prompts are checked.
+const promptQueueInterval = 2 * time.Second
+
+// QueuedPrompt is a prompt waiting to be sent to an agent.
+type QueuedPrompt struct {
+	ID      string    `json:"id"`
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+}
+
+// PromptQueue is the queue of one pane, in send order.
+type PromptQueue struct {
+	Pane    tmux.Pane      `json:"pane"`
+	Prompts []QueuedPrompt `json:"prompts"`
+}
+
+// QueueRequest is the body of POST (text) and PUT (ids, the new order) on
+// /api/pane/{target}/queue.
+type QueueRequest struct {
+	Text string   `json:"text,omitempty"`
+	IDs  []string `json:"ids,omitempty"`
+}
+
+// Agent states as seen by the queue.
+type queueState int
+
+const (
+	queueBusy    queueState = iota // Needs attention or unknown: hold
+	queueWorking                   // Agent is working
+	queueReady                     // Agent finished and is waiting for a new prompt
+)
+
+func queueStateFor(result parser.Result) queueState {
+	switch result.Type {
+	case parser.TypeWorking:
+		return queueWorking
+	case parser.TypeIdle, parser.TypeDone:
+		return queueReady
+	}
+	return queueBusy
+}
+
+var errQueueOrder = errors.New("ids must list every queued prompt exactly once")
+
+// promptQueues holds queued prompts per pane key. A prompt is sent when its
+// pane's agent goes from working to idle, so each prompt runs only after the
+// previous task (queued or typed) has finished. A pane an agent was just
+// launched in gets its first prompt as soon as the agent is ready.
+type promptQueues struct {
+	mu       sync.Mutex
+	queues   map[string]*PromptQueue
+	working  map[string]bool // Pane seen working since the last send
+	launched map[string]bool // Agent starting; its first prompt waits only for it to be ready
+}
+
+func newPromptQueues() *promptQueues {
+	return &promptQueues{
+		queues:   make(map[string]*PromptQueue),
+		working:  make(map[string]bool),
+		launched: make(map[string]bool),
+	}
+}
+
+func newPromptID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (q *promptQueues) list(pane tmux.Pane) PromptQueue {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	result := PromptQueue{Pane: pane, Prompts: []QueuedPrompt{}}
+	if pq, ok := q.queues[pane.Key()]; ok {
+		result.Prompts = append(result.Prompts, pq.Prompts...)
+	}
+	return result
+}
+
+func (q *promptQueues) add(pane tmux.Pane, text string, now time.Time) QueuedPrompt {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	p := QueuedPrompt{ID: newPromptID(), Text: text, Created: now}
+	pq, ok := q.queues[pane.Key()]
+	if !ok {
+		pq = &PromptQueue{Pane: pane}
+		q.queues[pane.Key()] = pq
+	}
+	pq.Prompts = append(pq.Prompts, p)
+	return p
+}
+
+// launch queues the initial prompt of an agent being started in pane.
+func (q *promptQueues) launch(pane tmux.Pane, text string, now time.Time) QueuedPrompt {
+	p := q.add(pane, text, now)
+	q.mu.Lock()
+	q.launched[pane.Key()] = true
+	q.mu.Unlock()
+	return p
+}
+
+// launching reports whether pane's agent was launched and hasn't had its
+// first prompt yet.
+func (q *promptQueues) launching(pane tmux.Pane) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.launched[pane.Key()]
+}
+
+func (q *promptQueues) remove(pane tmux.Pane, id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pq, ok := q.queues[pane.Key()]
+	if !ok {
+		return false
+	}
+	for i, p := range pq.Prompts {
+		if p.ID == id {
+			pq.Prompts = append(pq.Prompts[:i], pq.Prompts[i+1:]...)
+			q.dropIfEmpty(pane.Key())
+			return true
+		}
+	}
+	return false
+}
+
+func (q *promptQueues) clear(pane tmux.Pane) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.queues, pane.Key())
+	delete(q.working, pane.Key())
+	delete(q.launched, pane.Key())
+}
+
+// reorder puts the pane's prompts in the order of ids.
+func (q *promptQueues) reorder(pane tmux.Pane, ids []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pq, ok := q.queues[pane.Key()]
+	if !ok {
+		if len(ids) == 0 {
+			return nil
+		}
+		return errQueueOrder
+	}
+	if len(ids) != len(pq.Prompts) {
+		return errQueueOrder
+	}
+	byID := make(map[string]QueuedPrompt, len(pq.Prompts))
+	for _, p := range pq.Prompts {
+		byID[p.ID] = p
+	}
+	reordered := make([]QueuedPrompt, 0, len(ids))
+	for _, id := range ids {
+		p, ok := byID[id]
+		if !ok {
+			return errQueueOrder
+		}
+		delete(byID, id)
+		reordered = append(reordered, p)
+	}
+	pq.Prompts = reordered
+	return nil
+}
+
+// observe records a pane's agent state and returns the prompt to send when
+// the agent has just finished working.
+func (q *promptQueues) observe(pane tmux.Pane, state queueState) (QueuedPrompt, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	key := pane.Key()
+	switch state {
+	case queueWorking:
+		q.working[key] = true
+	case queueReady:
+		pq, ok := q.queues[key]
+		if !ok || !(q.working[key] || q.launched[key]) || len(pq.Prompts) == 0 {
+			return QueuedPrompt{}, false
+		}
+		p := pq.Prompts[0]
+		pq.Prompts = pq.Prompts[1:]
+		q.working[key] = false
+		delete(q.launched, key)
+		q.dropIfEmpty(key)
+		return p, true
+	}
+	return QueuedPrompt{}, false
+}
+
+// requeue puts back a prompt that failed to send, first in line, to be
+// sent again the next time the pane's agent is seen ready.
+func (q *promptQueues) requeue(pane tmux.Pane, p QueuedPrompt) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pq, ok := q.queues[pane.Key()]
+	if !ok {
+		pq = &PromptQueue{Pane: pane}
+		q.queues[pane.Key()] = pq
+	}
+	pq.Prompts = append([]QueuedPrompt{p}, pq.Prompts...)
+	q.working[pane.Key()] = true
+}
+
+// panes returns the panes that have queued prompts.
+func (q *promptQueues) panes() []tmux.Pane {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	panes := make([]tmux.Pane, 0, len(q.queues))
+	for _, pq := range q.queues {
+		panes = append(panes, pq.Pane)
+	}
+	sort.Slice(panes, func(i, j int) bool { return panes[i].Key() < panes[j].Key() })
+	return panes
+}
+
+// countWindow returns how many prompts are queued across a window's panes.
+func (q *promptQueues) countWindow(window string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, pq := range q.queues {
+		if windowKey(pq.Pane) == window {
+			n += len(pq.Prompts)
+		}
+	}
+	return n
+}
+
+func (q *promptQueues) dropIfEmpty(key string) {
+	if pq, ok := q.queues[key]; ok && len(pq.Prompts) == 0 {
+		delete(q.queues, key)
+	}
+}
+
+func (q *promptQueues) snapshot() map[string]PromptQueue {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	doc := make(map[string]PromptQueue, len(q.queues))
+	for key, pq := range q.queues {
+		doc[key] = PromptQueue{Pane: pq.Pane, Prompts: append([]QueuedPrompt{}, pq.Prompts...)}
+	}
+	return doc
+}
+
+// loadPromptQueues restores queued prompts from the store.
+func (s *Server) loadPromptQueues() {
+	doc := make(map[string]PromptQueue)
+	if err := s.store.Load(promptQueuesDocument, &doc); err != nil {
+		slog.Warn("failed to load prompt queues", "error", err)
+	}
+	s.queues.mu.Lock()
+	for key, pq := range doc {
+		if len(pq.Prompts) > 0 {
+			s.queues.queues[key] = &pq
+		}
+	}
+	s.queues.mu.Unlock()
+}
+
+func (s *Server) savePromptQueues() {
+	if err := s.store.Save(promptQueuesDocument, s.queues.snapshot()); err != nil {
+		slog.Error("failed to save prompt queues", "error", err)
+	}
+}
+
+// runPromptQueues sends queued prompts as agents finish. Panes are checked
+// on a short interval and right away when a hook reports an agent stopped.
+func (s *Server) runPromptQueues(ctx context.Context) {
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	ticker := time.NewTicker(promptQueueInterval)
+	defer ticker.Stop()
+	events, unsubscribe := s.watcher.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case ev := <-events:
+			if !hookPush(ev) {
+				continue
+			}
+		}
+		s.dispatchPromptQueues()
+	}
+}
+
+func (s *Server) dispatchPromptQueues() {
+	for _, pane := range s.queues.panes() {
+		c := s.client(pane.Host)
+		info, ok := s.lookupPaneInfo(pane)
+		if c == nil || !ok {
+			continue // Host or pane gone; keep the queue until it returns or is cleared
+		}
+		if s.queues.launching(pane) && agents.IsShellCommand(info.Command) {
+			continue // The launched agent hasn't started yet
+		}
+		output, err := c.CapturePane(pane, 100)
+		if err != nil {
+			continue
+		}
+		agent := s.registry.Detect(pane.Key(), info.Command, output)
+		result := getAgentState(context.Background(), agent, agentStatePath(pane.Host, info.Path), output)
+
+		prompt, ok := s.queues.observe(pane, queueStateFor(result))
+		if !ok {
+			continue
+		}
+		if err := s.sendPrompt(context.Background(), pane, prompt.Text); err != nil {
+			slog.Error("send queued prompt failed", "pane", pane.Key(), "error", err)
+			s.queues.requeue(pane, prompt)
+			continue
+		}
+		slog.Info("sent queued prompt", "pane", pane.Key(), "id", prompt.ID)
+		s.audit(AuditEntry{Action: "queue", Target: pane.Key(), Detail: prompt.Text, Client: auditSelf})
+		s.savePromptQueues()
+	}
+}
+
+// handlePaneQueue serves /api/pane/{target}/queue: GET lists the pane's
+// queued prompts, POST appends one, PUT reorders them and DELETE removes
+// one (?id=) or all.
+func (s *Server) handlePaneQueue(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req QueueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(req.Text) == "" {
+			http.Error(w, "text is required", http.StatusBadRequest)
+			return
+		}
+		if _, ok := s.lookupPaneInfo(pane); !ok {
+			http.Error(w, "pane not found", http.StatusNotFound)
+			return
+		}
+		prompt := s.queues.add(pane, req.Text, time.Now())
+		s.savePromptQueues()
+		slog.InfoContext(r.Context(), "prompt queued", "pane", pane.Key(), "id", prompt.ID)
+		auditDetail(r, req.Text)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(prompt)
+		return
+	case http.MethodPut:
+		var req QueueRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := s.queues.reorder(pane, req.IDs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.savePromptQueues()
+	case http.MethodDelete:
+		if id := r.URL.Query().Get("id"); id != "" {
+			if !s.queues.remove(pane, id) {
+				http.Error(w, "prompt not found", http.StatusNotFound)
+				return
+			}
+		} else {
+			s.queues.clear(pane)
+		}
+		s.savePromptQueues()
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.queues.list(pane))
+}
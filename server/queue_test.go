@@ -0,0 +1,148 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+func queueTexts(q PromptQueue) []string {
+	texts := []string{}
+	for _, p := range q.Prompts {
+		texts = append(texts, p.Text)
+	}
+	return texts
+}
+
+func TestPromptQueueDispatch(t *testing.T) {
+	q := newPromptQueues()
+	pane := tmux.Pane{Session: "work", Window: 1}
+	now := time.Now()
+	q.add(pane, "first", now)
+	q.add(pane, "second", now)
+
+	// Idle before the agent has worked: hold (the user may be mid-thought)
+	if _, ok := q.observe(pane, queueReady); ok {
+		t.Fatal("observe(ready) before working sent a prompt")
+	}
+	q.observe(pane, queueWorking)
+	// Attention states never trigger a send
+	if _, ok := q.observe(pane, queueBusy); ok {
+		t.Fatal("observe(busy) sent a prompt")
+	}
+
+	p, ok := q.observe(pane, queueReady)
+	if !ok || p.Text != "first" {
+		t.Fatalf("observe(working -> ready) = %q, %v, want first", p.Text, ok)
+	}
+	// Still idle right after sending: wait for the agent to pick it up
+	if _, ok := q.observe(pane, queueReady); ok {
+		t.Fatal("observe(ready) right after send sent another prompt")
+	}
+	q.observe(pane, queueWorking)
+	if p, ok := q.observe(pane, queueReady); !ok || p.Text != "second" {
+		t.Fatalf("second dispatch = %q, %v, want second", p.Text, ok)
+	}
+	if panes := q.panes(); len(panes) != 0 {
+		t.Errorf("panes() after draining = %v, want none", panes)
+	}
+}
+
+func TestPromptQueueRequeue(t *testing.T) {
+	q := newPromptQueues()
+	pane := tmux.Pane{Session: "work", Window: 1}
+	q.add(pane, "first", time.Now())
+	q.add(pane, "second", time.Now())
+	q.observe(pane, queueWorking)
+	p, ok := q.observe(pane, queueReady)
+	if !ok {
+		t.Fatal("observe(working -> ready) sent nothing")
+	}
+
+	// A send that failed is tried again at the next check, ahead of the rest
+	q.requeue(pane, p)
+	if p, ok := q.observe(pane, queueReady); !ok || p.Text != "first" {
+		t.Fatalf("observe(ready) after requeue = %q, %v, want first", p.Text, ok)
+	}
+	if got := queueTexts(q.list(pane)); !reflect.DeepEqual(got, []string{"second"}) {
+		t.Errorf("queue after the retry = %v, want [second]", got)
+	}
+}
+
+func TestPromptQueueLaunch(t *testing.T) {
+	q := newPromptQueues()
+	pane := tmux.Pane{Session: "work", Window: 2, Index: 1}
+	q.launch(pane, "fix the build", time.Now())
+	q.add(pane, "then the tests", time.Now())
+
+	// A launched agent gets its first prompt as soon as it's ready
+	if p, ok := q.observe(pane, queueReady); !ok || p.Text != "fix the build" {
+		t.Fatalf("observe(ready) after launch = %q, %v, want the launch prompt", p.Text, ok)
+	}
+	if q.launching(pane) {
+		t.Error("still launching after the first prompt")
+	}
+	// Later prompts wait for the agent to work, as usual
+	if _, ok := q.observe(pane, queueReady); ok {
+		t.Fatal("observe(ready) sent the next prompt before the agent worked")
+	}
+}
+
+func TestPromptQueueEdit(t *testing.T) {
+	q := newPromptQueues()
+	pane := tmux.Pane{Session: "work", Window: 1}
+	a := q.add(pane, "a", time.Now())
+	b := q.add(pane, "b", time.Now())
+	c := q.add(pane, "c", time.Now())
+
+	if err := q.reorder(pane, []string{c.ID, a.ID, b.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if got := queueTexts(q.list(pane)); !reflect.DeepEqual(got, []string{"c", "a", "b"}) {
+		t.Errorf("after reorder = %v, want [c a b]", got)
+	}
+	for _, ids := range [][]string{{a.ID, b.ID}, {a.ID, a.ID, b.ID}, {a.ID, b.ID, "nope"}} {
+		if err := q.reorder(pane, ids); err == nil {
+			t.Errorf("reorder(%v) succeeded, want error", ids)
+		}
+	}
+
+	if !q.remove(pane, a.ID) || q.remove(pane, a.ID) {
+		t.Error("remove(a) should succeed once")
+	}
+	if got := q.countWindow(windowKey(pane)); got != 2 {
+		t.Errorf("countWindow() = %d, want 2", got)
+	}
+
+	q.requeue(pane, a)
+	if got := queueTexts(q.list(pane)); !reflect.DeepEqual(got, []string{"a", "c", "b"}) {
+		t.Errorf("after requeue = %v, want [a c b]", got)
+	}
+
+	q.clear(pane)
+	if got := q.list(pane); len(got.Prompts) != 0 {
+		t.Errorf("after clear = %v, want empty", got.Prompts)
+	}
+}
+
+func TestQueueStateFor(t *testing.T) {
+	tests := []struct {
+		typ  parser.ResultType
+		want queueState
+	}{
+		{parser.TypeWorking, queueWorking},
+		{parser.TypeIdle, queueReady},
+		{parser.TypeDone, queueReady},
+		{parser.TypeQuestion, queueBusy},
+		{parser.TypeChoice, queueBusy},
+		{parser.TypeError, queueBusy},
+	}
+	for _, tt := range tests {
+		if got := queueStateFor(parser.Result{Type: tt.typ}); got != tt.want {
+			t.Errorf("queueStateFor(%v) = %v, want %v", tt.typ, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of -rate-limit and -tmux-concurrency.
+const (
+	DefaultRateLimit       = 10 // Mutating requests per second per client
+	DefaultTmuxConcurrency = 8  // tmux-heavy requests at once
+)
+
+// tmuxQueueWait is how long a tmux-heavy request waits for a slot before
+// it's refused, so a burst from the SPA is queued rather than failed.
+const tmuxQueueWait = time.Second
+
+// maxRateClients bounds the clients whose request rate is tracked.
+const maxRateClients = 1000
+
+// limits keeps a misbehaving client from flooding tmux: mutating requests
+// are rate-limited per client, with bursts of twice the rate, and requests
+// that run tmux for every pane share a few slots. A nil *limits limits
+// nothing.
+type limits struct {
+	rate  float64       // Mutating requests per second per client; zero: unlimited
+	burst float64       // Tokens a bucket holds
+	tmux  chan struct{} // Slots for tmux-heavy requests; nil: unlimited
+
+	mu      sync.Mutex
+	clients map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newLimits(rate float64, tmuxConcurrency int) *limits {
+	l := &limits{rate: rate, burst: math.Max(1, 2*rate), clients: make(map[string]*tokenBucket)}
+	if tmuxConcurrency > 0 {
+		l.tmux = make(chan struct{}, tmuxConcurrency)
+	}
+	return l
+}
+
+// allow takes a token from client's bucket, or returns how long until
+// there is one.
+func (l *limits) allow(client string, now time.Time) (time.Duration, bool) {
+	if l == nil || l.rate <= 0 {
+		return 0, true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.clients[client]
+	if !ok {
+		if len(l.clients) >= maxRateClients {
+			l.pruneLocked(now)
+		}
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.clients[client] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// pruneLocked forgets clients whose bucket has refilled, which are as
+// good as new. l.mu must be held.
+func (l *limits) pruneLocked(now time.Time) {
+	for client, b := range l.clients {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// acquireTmux waits up to tmuxQueueWait for a tmux slot and returns its
+// release, or false.
+func (l *limits) acquireTmux(ctx context.Context) (func(), bool) {
+	if l == nil || l.tmux == nil {
+		return func() {}, true
+	}
+	timer := time.NewTimer(tmuxQueueWait)
+	defer timer.Stop()
+	select {
+	case l.tmux <- struct{}{}:
+		return func() { <-l.tmux }, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// limitMiddleware answers requests over the limits with 429 and a
+// Retry-After.
+func (s *Server) limitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mutating(r) {
+			if wait, ok := s.limits.allow(clientIP(r), time.Now()); !ok {
+				tooManyRequests(w, wait, fmt.Sprintf("rate limited: at most %g changes per second", s.limits.rate))
+				return
+			}
+		}
+		if tmuxHeavy(r) {
+			release, ok := s.limits.acquireTmux(r.Context())
+			if !ok {
+				tooManyRequests(w, time.Second, "too many requests running tmux; try again")
+				return
+			}
+			defer release()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tooManyRequests(w http.ResponseWriter, wait time.Duration, msg string) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(w, msg, http.StatusTooManyRequests)
+}
+
+// mutating reports whether r changes something. Claude hook events are
+// status reports and batches only read.
+func mutating(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return !strings.HasPrefix(r.URL.Path, "/api/hooks/") && r.URL.Path != "/api/batch"
+}
+
+// tmuxHeavy reports whether r runs tmux for every pane, or captures one:
+// building the sessions list or the agent strip, searching, and pane
+// captures. Streams are long-lived and not counted.
+func tmuxHeavy(r *http.Request) bool {
+	if r.Method != http.MethodGet {
+		return false
+	}
+	switch path := r.URL.Path; {
+	case path == "/api/sessions":
+		return r.URL.Query().Get("stream") != "1"
+	case path == "/api/search", path == "/api/strip":
+		return true
+	case strings.HasPrefix(path, "/api/pane/"):
+		action := path[strings.LastIndex(path, "/")+1:]
+		return action == "history" || action == "screenshot.png" || !paneActions[action]
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLimitsAllow(t *testing.T) {
+	l := newLimits(2, 0) // Bursts of 4
+	now := time.Now()
+	for i := range 4 {
+		if _, ok := l.allow("a", now); !ok {
+			t.Fatalf("request %d of the burst refused", i+1)
+		}
+	}
+	wait, ok := l.allow("a", now)
+	if ok || wait != 500*time.Millisecond {
+		t.Errorf("after the burst: wait %v, ok %v; want 500ms, false", wait, ok)
+	}
+	if _, ok := l.allow("b", now); !ok {
+		t.Error("another client was refused")
+	}
+	if _, ok := l.allow("a", now.Add(500*time.Millisecond)); !ok {
+		t.Error("refused after the bucket refilled a token")
+	}
+
+	var unlimited *limits
+	if _, ok := unlimited.allow("a", now); !ok {
+		t.Error("nil limits refused a request")
+	}
+}
+
+func TestLimitMiddleware(t *testing.T) {
+	s := &Server{limits: newLimits(1, 1)}
+	h := s.limitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	do := func(r *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	for range 2 {
+		if w := do(httptest.NewRequest("POST", "/api/pane/work:1.0/send", nil)); w.Code != http.StatusOK {
+			t.Fatalf("POST within the burst = %d", w.Code)
+		}
+	}
+	w := do(httptest.NewRequest("POST", "/api/pane/work:1.0/send", nil))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("POST over the rate = %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := do(httptest.NewRequest("POST", "/api/hooks/claude", nil)); w.Code != http.StatusOK {
+		t.Errorf("hook event = %d, want it unlimited", w.Code)
+	}
+
+	// With the only tmux slot taken, a capture is refused once it gives up waiting
+	release, _ := s.limits.acquireTmux(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if w := do(httptest.NewRequest("GET", "/api/pane/work:1.0", nil).WithContext(ctx)); w.Code != http.StatusTooManyRequests {
+		t.Errorf("capture with no slot = %d, want 429", w.Code)
+	}
+	if w := do(httptest.NewRequest("GET", "/api/pane/work:1.0/todos", nil)); w.Code != http.StatusOK {
+		t.Errorf("todos with no slot = %d, want it unlimited", w.Code)
+	}
+	release()
+	if w := do(httptest.NewRequest("GET", "/api/pane/work:1.0", nil)); w.Code != http.StatusOK {
+		t.Errorf("capture after release = %d", w.Code)
+	}
+}
+
+func TestTmuxHeavy(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/api/sessions", true},
+		{"GET", "/api/sessions?stream=1", false},
+		{"POST", "/api/sessions", false},
+		{"GET", "/api/search?q=panic", true},
+		{"GET", "/api/strip?active=work:1.0", true},
+		{"GET", "/api/pane/work:1.0", true},
+		{"GET", "/api/pane/work:1.0/history?lines=500", true},
+		{"GET", "/api/pane/work:1.0/screenshot.png", true},
+		{"GET", "/api/pane/work:1.0/ws", false},
+		{"GET", "/api/pane/work:1.0/diff", false},
+		{"GET", "/api/meta", false},
+	}
+	for _, tt := range tests {
+		if got := tmuxHeavy(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("tmuxHeavy(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
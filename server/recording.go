@@ -0,0 +1,544 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// recordingsDocument is the store document listing recordings and the
+// panes being recorded.
+const recordingsDocument = "recordings"
+
+// recordingsDir is the directory under the data directory holding the
+// asciicast files.
+const recordingsDir = "recordings"
+
+// maxRecordingSize is where a recording is closed and the pane's output
+// continues in a new one, keeping files a size players load.
+const maxRecordingSize = 64 << 20
+
+// recordingRetry is how often panes whose recorder stopped are attached
+// again.
+const recordingRetry = 30 * time.Second
+
+// Recording describes an asciicast v2 file of a pane's output.
+type Recording struct {
+	ID       string     `json:"id"`
+	Pane     tmux.Pane  `json:"pane"`
+	Width    int        `json:"width"`
+	Height   int        `json:"height"`
+	Started  time.Time  `json:"started"`
+	Ended    *time.Time `json:"ended,omitempty"`
+	Duration float64    `json:"duration_seconds"` // Up to the last output
+	Size     int64      `json:"size"`             // Bytes
+	Active   bool       `json:"active"`           // Still being written
+}
+
+// PaneRecording is the response of /api/pane/{target}/recording.
+type PaneRecording struct {
+	Pane       tmux.Pane   `json:"pane"`
+	Enabled    bool        `json:"enabled"`
+	Recordings []Recording `json:"recordings"` // This pane's, newest first
+}
+
+// recordingsState is the persisted form of recordings.
+type recordingsState struct {
+	Panes      []tmux.Pane `json:"panes"` // Recording is enabled for these
+	Recordings []Recording `json:"recordings"`
+}
+
+// recordings tracks which panes are recorded, their running recorders and
+// the recordings made so far.
+type recordings struct {
+	mu         sync.Mutex
+	ctx        context.Context // Set once this houston is primary; recorders start then
+	panes      map[string]tmux.Pane
+	running    map[string]context.CancelFunc // By pane key
+	recorders  sync.WaitGroup
+	recordings []Recording
+}
+
+func newRecordings() *recordings {
+	return &recordings{panes: make(map[string]tmux.Pane), running: make(map[string]context.CancelFunc)}
+}
+
+func (rs *recordings) state() recordingsState {
+	st := recordingsState{Panes: []tmux.Pane{}, Recordings: slices.Clone(rs.recordings)}
+	for _, p := range rs.panes {
+		st.Panes = append(st.Panes, p)
+	}
+	return st
+}
+
+// update applies fn to the recording with the given id.
+func (rs *recordings) update(id string, fn func(*Recording)) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for i := range rs.recordings {
+		if rs.recordings[i].ID == id {
+			fn(&rs.recordings[i])
+			return
+		}
+	}
+}
+
+func (rs *recordings) get(id string) (Recording, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, rec := range rs.recordings {
+		if rec.ID == id {
+			return rec, true
+		}
+	}
+	return Recording{}, false
+}
+
+// list returns the recordings of pane (all with a zero pane key), newest
+// first.
+func (rs *recordings) list(paneKey string) []Recording {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	result := []Recording{}
+	for _, rec := range slices.Backward(rs.recordings) {
+		if paneKey == "" || rec.Pane.Key() == paneKey {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
+// loadRecordings restores the recording list and enabled panes from the
+// store. Recordings that were being written when houston stopped are
+// closed at their last output.
+func (s *Server) loadRecordings() {
+	var st recordingsState
+	if err := s.store.Load(recordingsDocument, &st); err != nil {
+		slog.Warn("failed to load recordings", "error", err)
+	}
+	s.recordings.mu.Lock()
+	defer s.recordings.mu.Unlock()
+	for _, p := range st.Panes {
+		s.recordings.panes[p.Key()] = p
+	}
+	for _, rec := range st.Recordings {
+		if rec.Active {
+			ended := rec.Started.Add(time.Duration(rec.Duration * float64(time.Second)))
+			rec.Active, rec.Ended = false, &ended
+		}
+		if info, err := os.Stat(s.recordingPath(rec.ID)); err == nil {
+			rec.Size = info.Size()
+			s.recordings.recordings = append(s.recordings.recordings, rec)
+		}
+	}
+}
+
+func (s *Server) saveRecordings() {
+	s.recordings.mu.Lock()
+	st := s.recordings.state()
+	s.recordings.mu.Unlock()
+	if err := s.store.Save(recordingsDocument, st); err != nil {
+		slog.Error("failed to save recordings", "error", err)
+	}
+}
+
+func (s *Server) recordingPath(id string) string {
+	return filepath.Join(s.store.Dir(), recordingsDir, id+".cast")
+}
+
+// runRecordings records the enabled panes while this houston is primary,
+// attaching again to panes whose recorder stopped. It returns once the
+// recorders have saved their recordings.
+func (s *Server) runRecordings(ctx context.Context) {
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	s.recordings.mu.Lock()
+	s.recordings.ctx = ctx
+	s.recordings.mu.Unlock()
+	defer s.recordings.recorders.Wait()
+
+	ticker := time.NewTicker(recordingRetry)
+	defer ticker.Stop()
+	for {
+		s.recordings.mu.Lock()
+		for key, pane := range s.recordings.panes {
+			if _, ok := s.recordings.running[key]; !ok {
+				s.startRecorderLocked(pane)
+			}
+		}
+		s.recordings.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startRecorderLocked starts recording pane. recordings.mu must be held.
+func (s *Server) startRecorderLocked(pane tmux.Pane) {
+	if s.recordings.ctx == nil || s.recordings.ctx.Err() != nil || s.client(pane.Host) == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(s.recordings.ctx)
+	key := pane.Key()
+	s.recordings.running[key] = cancel
+	s.recordings.recorders.Add(1)
+	go func() {
+		defer s.recordings.recorders.Done()
+		err := s.record(ctx, pane)
+		cancel()
+		s.recordings.mu.Lock()
+		delete(s.recordings.running, key)
+		if errors.Is(err, errPaneGone) {
+			// Nothing left to record
+			delete(s.recordings.panes, key)
+		}
+		s.recordings.mu.Unlock()
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("pane recording stopped", "pane", key, "error", err)
+		}
+		if errors.Is(err, errPaneGone) {
+			s.saveRecordings()
+		}
+	}()
+}
+
+var errPaneGone = errors.New("pane no longer exists")
+
+// record writes pane's output to recordings until ctx is done or the
+// control client exits, starting a new recording at maxRecordingSize.
+func (s *Server) record(ctx context.Context, pane tmux.Pane) error {
+	c := s.client(pane.Host)
+	if _, err := c.PaneID(pane); err != nil {
+		return errPaneGone
+	}
+	stream, err := c.AttachControl(pane)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close() }()
+
+	for {
+		w, err := s.newRecordingWriter(pane)
+		if err != nil {
+			return err
+		}
+		slog.Info("pane recording started", "pane", pane.Key(), "recording", w.rec.ID)
+		// Start from the current screen, like the raw terminal
+		if snapshot, snapErr := rawSnapshot(c, pane); snapErr == nil {
+			err = w.write(snapshot)
+		}
+		for err == nil && w.rec.Size < maxRecordingSize {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+			case data, ok := <-stream.Output():
+				if !ok {
+					err = errors.New("control client exited")
+					break
+				}
+				err = w.write(data)
+			}
+		}
+		s.closeRecording(w)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// recordingWriter appends output events to an asciicast v2 file, keeping
+// its entry in recordings current.
+type recordingWriter struct {
+	file    *os.File
+	rs      *recordings
+	rec     Recording
+	partial []byte // Start of a UTF-8 sequence the next output completes
+}
+
+func (s *Server) newRecordingWriter(pane tmux.Pane) (*recordingWriter, error) {
+	c := s.client(pane.Host)
+	width, height, err := c.GetPaneSize(pane)
+	if err != nil {
+		return nil, errPaneGone
+	}
+	now := time.Now()
+	rec := Recording{
+		ID:      now.UTC().Format("20060102-150405") + "-" + newPromptID()[:6],
+		Pane:    pane,
+		Width:   width,
+		Height:  height,
+		Started: now,
+		Active:  true,
+	}
+	if err := os.MkdirAll(filepath.Dir(s.recordingPath(rec.ID)), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(s.recordingPath(rec.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	header, _ := json.Marshal(map[string]any{
+		"version":   2,
+		"width":     width,
+		"height":    height,
+		"timestamp": now.Unix(),
+		"title":     pane.Key(),
+		"env":       map[string]string{"TERM": "tmux-256color"},
+	})
+	n, err := fmt.Fprintf(f, "%s\n", header)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	rec.Size = int64(n)
+
+	s.recordings.mu.Lock()
+	s.recordings.recordings = append(s.recordings.recordings, rec)
+	s.recordings.mu.Unlock()
+	s.saveRecordings()
+	return &recordingWriter{file: f, rs: s.recordings, rec: rec}, nil
+}
+
+// write appends an output event for data at the time since the recording
+// started. A UTF-8 sequence split across outputs is held back until its
+// end arrives, so each event's text is valid.
+func (w *recordingWriter) write(data []byte) error {
+	data = append(w.partial, data...)
+	data, w.partial = splitUTF8(data)
+	if len(data) == 0 {
+		return nil
+	}
+	text, _ := json.Marshal(string(data))
+	t := time.Since(w.rec.Started).Seconds()
+	n, err := fmt.Fprintf(w.file, "[%.6f, \"o\", %s]\n", t, text)
+	w.rec.Size += int64(n)
+	w.rec.Duration = t
+	w.rs.update(w.rec.ID, func(rec *Recording) { rec.Size, rec.Duration = w.rec.Size, w.rec.Duration })
+	return err
+}
+
+// splitUTF8 splits off an incomplete UTF-8 sequence at the end of b.
+func splitUTF8(b []byte) (complete, rest []byte) {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i], slices.Clone(b[i:])
+			}
+			break
+		}
+	}
+	return b, nil
+}
+
+func (s *Server) closeRecording(w *recordingWriter) {
+	if err := w.file.Close(); err != nil {
+		slog.Warn("closing recording failed", "recording", w.rec.ID, "error", err)
+	}
+	ended := time.Now()
+	s.recordings.update(w.rec.ID, func(rec *Recording) { rec.Active, rec.Ended = false, &ended })
+	s.saveRecordings()
+	slog.Info("pane recording saved", "pane", w.rec.Pane.Key(), "recording", w.rec.ID, "size", w.rec.Size)
+}
+
+// handlePaneRecording serves /api/pane/{target}/recording: GET shows
+// whether the pane is recorded and its recordings, POST starts recording
+// it (kept across restarts) and DELETE stops.
+func (s *Server) handlePaneRecording(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	key := pane.Key()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if _, err := s.client(pane.Host).PaneID(pane); err != nil {
+			http.Error(w, "pane not found", http.StatusNotFound)
+			return
+		}
+		s.recordings.mu.Lock()
+		s.recordings.panes[key] = pane
+		if _, ok := s.recordings.running[key]; !ok {
+			s.startRecorderLocked(pane)
+		}
+		s.recordings.mu.Unlock()
+		s.saveRecordings()
+		slog.InfoContext(r.Context(), "pane recording enabled", "pane", key)
+	case http.MethodDelete:
+		s.recordings.mu.Lock()
+		delete(s.recordings.panes, key)
+		if cancel, ok := s.recordings.running[key]; ok {
+			cancel()
+		}
+		s.recordings.mu.Unlock()
+		s.saveRecordings()
+		slog.InfoContext(r.Context(), "pane recording disabled", "pane", key)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.recordings.mu.Lock()
+	_, enabled := s.recordings.panes[key]
+	s.recordings.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(PaneRecording{Pane: pane, Enabled: enabled, Recordings: s.recordings.list(key)})
+}
+
+// handleAPIRecordings serves GET /api/recordings, every recording newest
+// first.
+func (s *Server) handleAPIRecordings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.recordings.list(""))
+}
+
+// handleAPIRecording serves /api/recordings/{id}: GET downloads the
+// asciicast file, DELETE removes a finished recording, and
+// /api/recordings/{id}/replay plays it back.
+func (s *Server) handleAPIRecording(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/recordings/")
+	id, replay := strings.CutSuffix(id, "/replay")
+	rec, ok := s.recordings.get(id)
+	if !ok {
+		http.Error(w, "recording not found", http.StatusNotFound)
+		return
+	}
+	if replay {
+		s.handleRecordingReplay(w, r, rec)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		f, err := os.Open(s.recordingPath(id))
+		if err != nil {
+			http.Error(w, "recording not found", http.StatusNotFound)
+			return
+		}
+		defer func() { _ = f.Close() }()
+		w.Header().Set("Content-Type", "application/x-asciicast")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+".cast"))
+		http.ServeContent(w, r, id+".cast", rec.Started, f)
+	case http.MethodDelete:
+		if rec.Active {
+			http.Error(w, "recording in progress; stop recording the pane first", http.StatusConflict)
+			return
+		}
+		if err := os.Remove(s.recordingPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.ErrorContext(r.Context(), "failed to delete recording", "recording", id, "error", err)
+			http.Error(w, "failed to delete recording", http.StatusInternalServerError)
+			return
+		}
+		s.recordings.mu.Lock()
+		s.recordings.recordings = slices.DeleteFunc(s.recordings.recordings, func(rec Recording) bool { return rec.ID == id })
+		s.recordings.mu.Unlock()
+		s.saveRecordings()
+		slog.InfoContext(r.Context(), "recording deleted", "recording", id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRecordingReplay serves GET /api/recordings/{id}/replay as an SSE
+// stream replaying the recording in real time:
+//
+//	event: header  the asciicast header (width, height, timestamp)
+//	event: output  the output as a JSON string, at the time it was written
+//	event: end     after the last output
+//
+// ?speed= plays faster (up to 100), ?idle= caps pauses at that many
+// seconds.
+func (s *Server) handleRecordingReplay(w http.ResponseWriter, r *http.Request, rec Recording) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	speed, idle := 1.0, 0.0
+	if v := q.Get("speed"); v != "" {
+		var err error
+		if speed, err = strconv.ParseFloat(v, 64); err != nil || speed <= 0 || speed > 100 {
+			http.Error(w, "speed must be a number between 0 and 100", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("idle"); v != "" {
+		var err error
+		if idle, err = strconv.ParseFloat(v, 64); err != nil || idle <= 0 {
+			http.Error(w, "idle must be a positive number of seconds", http.StatusBadRequest)
+			return
+		}
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	f, err := os.Open(s.recordingPath(rec.ID))
+	if err != nil {
+		http.Error(w, "recording not found", http.StatusNotFound)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	if !scanner.Scan() {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "event: header\ndata: %s\n\n", scanner.Bytes())
+	flusher.Flush()
+
+	var last float64 // Time of the previous event in the recording
+	for scanner.Scan() {
+		var event []json.RawMessage
+		var t float64
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 || json.Unmarshal(event[0], &t) != nil {
+			continue
+		}
+		pause := t - last
+		if idle > 0 {
+			pause = min(pause, idle)
+		}
+		last = t
+		if pause > 0 {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(time.Duration(pause / speed * float64(time.Second))):
+			}
+		}
+		if _, err := fmt.Fprintf(w, "event: output\ndata: %s\n\n", event[2]); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+	_, _ = fmt.Fprintf(w, "event: end\ndata: {}\n\n")
+	flusher.Flush()
+}
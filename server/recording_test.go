@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/store"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestSplitUTF8(t *testing.T) {
+	tests := []struct {
+		in, complete, rest string
+	}{
+		{"abc", "abc", ""},
+		{"h\xc3\xa9", "h\xc3\xa9", ""},
+		{"h\xc3", "h", "\xc3"},
+		{"x\xe2\x94", "x", "\xe2\x94"},
+		{"\xf0\x9f\x98", "", "\xf0\x9f\x98"},
+		{"bad\xff", "bad\xff", ""}, // Not the start of a sequence; json replaces it
+	}
+	for _, tt := range tests {
+		complete, rest := splitUTF8([]byte(tt.in))
+		if string(complete) != tt.complete || string(rest) != tt.rest {
+			t.Errorf("splitUTF8(%q) = %q, %q, want %q, %q", tt.in, complete, rest, tt.complete, tt.rest)
+		}
+	}
+}
+
+// newTestRecording creates a recording file with the given output events
+// the way a recorder would.
+func newTestRecording(t *testing.T, s *Server, pane tmux.Pane, outputs ...string) Recording {
+	t.Helper()
+	rec := Recording{ID: "20260301-120000-abcdef", Pane: pane, Width: 80, Height: 24, Started: time.Now(), Active: true}
+	if err := os.MkdirAll(filepath.Dir(s.recordingPath(rec.ID)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(s.recordingPath(rec.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := f.WriteString(`{"version":2,"width":80,"height":24}` + "\n")
+	rec.Size = int64(n)
+	s.recordings.recordings = append(s.recordings.recordings, rec)
+	w := &recordingWriter{file: f, rs: s.recordings, rec: rec}
+	for _, out := range outputs {
+		if err := w.write([]byte(out)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.closeRecording(w)
+	got, _ := s.recordings.get(rec.ID)
+	return got
+}
+
+func TestRecordingWriter(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, recordings: newRecordings()}
+	pane := tmux.Pane{Session: "web", Window: 1}
+	// "é" split across two outputs
+	rec := newTestRecording(t, s, pane, "h\xc3", "\xa9llo\r\n", "\x1b[1mdone\x1b[0m")
+
+	f, err := os.Open(s.recordingPath(rec.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var outputs []string
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		var event []any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 || event[1] != "o" {
+			t.Fatalf("event %s: %v", scanner.Text(), err)
+		}
+		outputs = append(outputs, event[2].(string))
+	}
+	if want := []string{"h", "éllo\r\n", "\x1b[1mdone\x1b[0m"}; strings.Join(outputs, "|") != strings.Join(want, "|") {
+		t.Errorf("outputs = %q, want %q", outputs, want)
+	}
+	if info, _ := f.Stat(); rec.Active || rec.Ended == nil || rec.Size != info.Size() {
+		t.Errorf("recording = %+v, want closed with size %d", rec, info.Size())
+	}
+
+	// The list survives a restart
+	restarted := &Server{store: st, recordings: newRecordings()}
+	restarted.loadRecordings()
+	if got := restarted.recordings.list(pane.Key()); len(got) != 1 || got[0].ID != rec.ID {
+		t.Errorf("restored recordings = %+v", got)
+	}
+}
+
+func TestHandleAPIRecording(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, recordings: newRecordings()}
+	rec := newTestRecording(t, s, tmux.Pane{Session: "web"}, "one", "two")
+
+	w := httptest.NewRecorder()
+	s.handleAPIRecording(w, httptest.NewRequest("GET", "/api/recordings/"+rec.ID, nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != "application/x-asciicast" || !strings.Contains(w.Body.String(), `"o", "two"`) {
+		t.Errorf("download = %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPIRecording(w, httptest.NewRequest("GET", "/api/recordings/"+rec.ID+"/replay?speed=100", nil))
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "event: header\n") || !strings.Contains(body, "event: output\ndata: \"two\"\n\n") || !strings.HasSuffix(body, "event: end\ndata: {}\n\n") {
+		t.Errorf("replay = %q", body)
+	}
+	w = httptest.NewRecorder()
+	s.handleAPIRecording(w, httptest.NewRequest("GET", "/api/recordings/"+rec.ID+"/replay?speed=0", nil))
+	if w.Code != 400 {
+		t.Errorf("replay speed=0 status = %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPIRecording(w, httptest.NewRequest("DELETE", "/api/recordings/"+rec.ID, nil))
+	if w.Code != 204 {
+		t.Errorf("delete status = %d", w.Code)
+	}
+	if _, err := os.Stat(s.recordingPath(rec.ID)); !os.IsNotExist(err) {
+		t.Errorf("file after delete: %v", err)
+	}
+	w = httptest.NewRecorder()
+	s.handleAPIRecording(w, httptest.NewRequest("GET", "/api/recordings/"+rec.ID, nil))
+	if w.Code != 404 {
+		t.Errorf("deleted recording status = %d, want 404", w.Code)
+	}
+}
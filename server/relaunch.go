@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/tmux"
+)
+
+// RelaunchInfo marks a window restored by tmux-resurrect whose agent isn't
+// running: the pane was saved running an agent but is now a bare shell.
+type RelaunchInfo struct {
+	Pane    tmux.Pane        `json:"pane"`
+	Agent   agents.AgentType `json:"agent"`
+	Command string           `json:"command"` // Saved start command
+}
+
+// RelaunchRequest selects panes to relaunch; empty Targets means all.
+type RelaunchRequest struct {
+	Targets []string `json:"targets,omitempty"`
+}
+
+// RelaunchResult is the outcome of relaunching one pane.
+type RelaunchResult struct {
+	Pane    tmux.Pane `json:"pane"`
+	Command string    `json:"command"`
+	OK      bool      `json:"ok"`
+	Error   string    `json:"error,omitempty"`
+}
+
+// resurrectState returns the parsed tmux-resurrect save file, re-reading it
+// when it changes. Returns nil if there is no save file.
+func (s *Server) resurrectState() *tmux.ResurrectState {
+	path := s.resurrectFile
+	if path == "" {
+		path = tmux.FindResurrectFile()
+	}
+	if path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	s.resurrectMu.Lock()
+	defer s.resurrectMu.Unlock()
+	if s.resurrect != nil && s.resurrect.Path == path && s.resurrectMod.Equal(info.ModTime()) {
+		return s.resurrect
+	}
+
+	state, err := tmux.LoadResurrect(path)
+	if err != nil {
+		slog.Warn("failed to read tmux-resurrect file", "path", path, "error", err)
+		return nil
+	}
+	s.resurrect = state
+	s.resurrectMod = info.ModTime()
+	return state
+}
+
+// savedAgentType returns the agent a saved pane was running, from its
+// command name or the first word of its full command line.
+func savedAgentType(saved tmux.SavedPane) agents.AgentType {
+	if t := agents.DetectFromCommand(saved.Command); t != agents.AgentGeneric {
+		return t
+	}
+	if fields := strings.Fields(saved.Full); len(fields) > 0 {
+		return agents.DetectFromCommand(filepath.Base(fields[0]))
+	}
+	return agents.AgentGeneric
+}
+
+// relaunchCandidate returns the pane in a window that needs its agent
+// relaunched, if any. Only sessions created after the save (i.e. restored
+// from it) are considered, so agents the user exited on purpose in
+// long-running sessions aren't flagged.
+func (s *Server) relaunchCandidate(sess tmux.Session, window int, panes []tmux.PaneInfo) *RelaunchInfo {
+	if sess.Host != "" {
+		return nil // Save files are only read locally
+	}
+	state := s.resurrectState()
+	if state == nil || sess.Created.Before(state.SavedAt.Truncate(time.Second)) {
+		return nil
+	}
+
+	for _, p := range panes {
+		if !agents.IsShellCommand(p.Command) {
+			continue
+		}
+		saved, ok := state.Lookup(sess.Name, window, p.Index)
+		if !ok {
+			continue
+		}
+		agentType := savedAgentType(saved)
+		if agentType == agents.AgentGeneric {
+			continue
+		}
+		return &RelaunchInfo{
+			Pane:    tmux.Pane{Session: sess.Name, Window: window, Index: p.Index},
+			Agent:   agentType,
+			Command: saved.StartCommand(),
+		}
+	}
+	return nil
+}
+
+// handleAPIRelaunch serves /api/relaunch: GET lists panes that need their
+// agent relaunched, POST relaunches them (all, or the given targets).
+func (s *Server) handleAPIRelaunch(w http.ResponseWriter, r *http.Request) {
+	var candidates []RelaunchInfo
+	for _, win := range s.buildSessionsData(r.Context(), sessionsQuery{}).allWindows() {
+		if win.Relaunch != nil {
+			candidates = append(candidates, *win.Relaunch)
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if candidates == nil {
+			candidates = []RelaunchInfo{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(candidates)
+	case http.MethodPost:
+		var req RelaunchRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+
+		selected := candidates
+		if len(req.Targets) > 0 {
+			wanted := make(map[string]bool, len(req.Targets))
+			for _, t := range req.Targets {
+				p := parseTarget(t)
+				wanted[p.Target()] = true
+				wanted[tmux.Pane{Session: p.Session, Window: p.Window}.Target()] = true
+			}
+			selected = nil
+			for _, c := range candidates {
+				if wanted[c.Pane.Target()] || wanted[tmux.Pane{Session: c.Pane.Session, Window: c.Pane.Window}.Target()] {
+					selected = append(selected, c)
+				}
+			}
+		}
+
+		results := make([]RelaunchResult, 0, len(selected))
+		for _, c := range selected {
+			result := RelaunchResult{Pane: c.Pane, Command: c.Command, OK: true}
+			if err := s.sendText(r.Context(), c.Pane, c.Command, true); err != nil {
+				result.OK = false
+				result.Error = err.Error()
+				slog.WarnContext(r.Context(), "relaunch failed", "pane", c.Pane.Target(), "error", err)
+			} else {
+				s.registry.InvalidateCache(c.Pane.Key())
+				slog.InfoContext(r.Context(), "relaunched agent", "pane", c.Pane.Target(), "command", c.Command)
+			}
+			results = append(results, result)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(results)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
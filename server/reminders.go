@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/notify"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/status"
+)
+
+// reminderInterval is how often windows are checked for due notifications
+// when a provider is configured.
+const reminderInterval = 15 * time.Second
+
+// runReminders notifies providers when a window starts needing attention
+// and again at each reminder interval while it stays unanswered. Windows
+// are checked on a short interval, and right away when a hook reports the
+// agent stopped or asked for permission.
+func (s *Server) runReminders(ctx context.Context) {
+	if !s.notifier.Enabled() {
+		return
+	}
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	ticker := time.NewTicker(reminderInterval)
+	defer ticker.Stop()
+	events, unsubscribe := s.watcher.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case ev := <-events:
+			if !hookPush(ev) && ev.Status.Status != status.StatusPermission {
+				continue
+			}
+		}
+		s.notifyAttention(ctx, s.buildSessionsData(ctx, sessionsQuery{}))
+	}
+}
+
+// notifyAttention sends the notifications that are due for data.
+func (s *Server) notifyAttention(ctx context.Context, data SessionsData) {
+	attention := make(map[string]bool)
+	for _, w := range data.allWindows() {
+		if !w.NeedsAttention || w.AttentionSince == nil {
+			continue
+		}
+		key := windowKey(w.Pane)
+		attention[key] = true
+		if !s.notified.Due(key, w.Reminder) {
+			continue
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		s.notifier.Send(sendCtx, attentionNotification(key, w))
+		cancel()
+	}
+	s.notified.Retain(func(key string) bool { return attention[key] })
+}
+
+func attentionNotification(key string, w WindowWithStatus) notify.Notification {
+	label := w.Window.Name
+	if w.Branch != "" && w.Branch != "main" && w.Branch != "master" {
+		label = w.Branch
+	}
+	body, question := windowState(w), ""
+	switch w.ParseResult.Type {
+	case parser.TypeError:
+		body = "Error: " + w.ParseResult.ErrorSnippet
+	case parser.TypeQuestion, parser.TypeChoice:
+		body, question = w.ParseResult.Question, w.ParseResult.Question
+	default:
+		if len(w.MCPDown) > 0 {
+			body = "MCP server disconnected: " + strings.Join(w.MCPDown, ", ")
+		}
+	}
+	if w.Reminder > 0 {
+		body = fmt.Sprintf("Waiting %d min: %s", w.WaitingMinutes, body)
+	}
+	return notify.Notification{
+		Key:      key,
+		Title:    fmt.Sprintf("%s — %s", w.Pane.Session, label),
+		Body:     body,
+		Reminder: w.Reminder,
+		Since:    *w.AttentionSince,
+		Target:   w.Pane.Target(),
+		Host:     w.Pane.Host,
+		Choices:  w.ParseResult.Choices,
+		Session:  w.Pane.Session,
+		Agent:    string(w.AgentType),
+		Question: question,
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/notify"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+type recordingProvider struct {
+	sent []notify.Notification
+}
+
+func (p *recordingProvider) Name() string { return "recording" }
+
+func (p *recordingProvider) Send(_ context.Context, n notify.Notification) error {
+	p.sent = append(p.sent, n)
+	return nil
+}
+
+func TestNotifyAttention(t *testing.T) {
+	provider := &recordingProvider{}
+	s := &Server{notifier: notify.New(provider), notified: notify.NewTracker()}
+
+	since := time.Now().Add(-6 * time.Minute)
+	window := func(reminder int, attention bool) SessionsData {
+		w := WindowWithStatus{
+			Window:         tmux.Window{Name: "api"},
+			Pane:           tmux.Pane{Session: "work", Window: 1},
+			ParseResult:    parser.Result{Type: parser.TypeQuestion, Question: "Proceed?"},
+			NeedsAttention: attention,
+			WaitingMinutes: 6,
+			Reminder:       reminder,
+		}
+		if attention {
+			w.AttentionSince = &since
+		}
+		return SessionsData{NeedsAttention: []SessionWithWindows{{Windows: []WindowWithStatus{w}}}}
+	}
+
+	ctx := context.Background()
+	s.notifyAttention(ctx, window(0, true))
+	s.notifyAttention(ctx, window(0, true))
+	s.notifyAttention(ctx, window(1, true))
+	if len(provider.sent) != 2 {
+		t.Fatalf("sent %d notifications, want 2 (entry and first reminder)", len(provider.sent))
+	}
+	if got := provider.sent[1]; got.Reminder != 1 || got.Body != "Waiting 6 min: Proceed?" || got.Title != "work — api" {
+		t.Errorf("reminder = %+v", got)
+	}
+
+	// Answered, then a new prompt starts over
+	s.notifyAttention(ctx, window(0, false))
+	s.notifyAttention(ctx, window(0, true))
+	if len(provider.sent) != 3 || provider.sent[2].Reminder != 0 {
+		t.Errorf("after a new prompt sent %d notifications, want 3 with the last at reminder 0", len(provider.sent))
+	}
+}
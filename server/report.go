@@ -0,0 +1,327 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/history"
+	"github.com/noamsto/houston/notify"
+	"github.com/noamsto/houston/tmux"
+)
+
+// Report limits: tools and commits listed per session in the Markdown.
+const (
+	reportTools   = 5
+	reportCommits = 10
+)
+
+// DailyReport summarizes what every session did over a day.
+type DailyReport struct {
+	Since    time.Time       `json:"since"`
+	Until    time.Time       `json:"until"`
+	Sessions []SessionReport `json:"sessions"` // Sessions with activity, busiest first
+	Markdown string          `json:"markdown"`
+}
+
+// SessionReport is one session's part of a daily report.
+type SessionReport struct {
+	Session  string           `json:"session"`
+	Host     string           `json:"host,omitempty"`
+	Minutes  map[string]int   `json:"minutes"`          // Sampled minutes per state (see /api/sessions/{name}/timeline)
+	Answered int              `json:"answered"`         // Prompts answered
+	Claude   *claude.Activity `json:"claude,omitempty"` // Transcripts of its directories (local sessions)
+	Commits  []tmux.Commit    `json:"commits"`          // In the repositories of its directories, newest first
+}
+
+func (r SessionReport) empty() bool {
+	return r.Minutes[history.StateWorking] == 0 && r.Minutes[history.StateAttention] == 0 && r.Answered == 0 &&
+		(r.Claude == nil || r.Claude.Messages == 0) && len(r.Commits) == 0
+}
+
+// dailyReport builds the report for [since, until) from the sessions open
+// now: their activity samples and answered prompts, the Claude transcripts
+// of their windows' directories, and the commits in those repositories.
+func (s *Server) dailyReport(since, until time.Time) (DailyReport, error) {
+	report := DailyReport{Since: since, Until: until, Sessions: []SessionReport{}}
+	responses, err := s.responses.Responses(since)
+	if err != nil {
+		return report, err
+	}
+
+	for _, sess := range s.listAllSessions(context.Background()) {
+		c := s.client(sess.Host)
+		key := tmux.Pane{Host: sess.Host, Session: sess.Name}.Key()
+		r := SessionReport{Session: sess.Name, Host: sess.Host, Minutes: map[string]int{}, Commits: []tmux.Commit{}}
+
+		samples, err := s.activity.Timeline(key, since, until)
+		if err != nil {
+			return report, err
+		}
+		for _, sample := range samples {
+			r.Minutes[sample.State]++
+		}
+		for _, resp := range responses {
+			if strings.HasPrefix(resp.Window, key+":") && resp.Answered.Before(until) {
+				r.Answered++
+			}
+		}
+
+		projects, repos := make(map[string]bool), make(map[string]bool)
+		for _, win := range sess.Windows {
+			if win.Path == "" {
+				continue
+			}
+			if sess.Host == "" && !projects[claude.ProjectDir(win.Path)] {
+				projects[claude.ProjectDir(win.Path)] = true
+				activity, err := claude.ProjectActivity(claude.ProjectDir(win.Path), since, until)
+				if err != nil {
+					slog.Warn("daily report: reading transcripts failed", "dir", win.Path, "error", err)
+				}
+				r.Claude = addActivity(r.Claude, activity)
+			}
+			if root, err := c.RepoRoot(win.Path); err == nil && !repos[root] {
+				repos[root] = true
+				commits, err := c.Commits(root, since, until)
+				if err != nil {
+					slog.Warn("daily report: listing commits failed", "repo", root, "error", err)
+				}
+				r.Commits = append(r.Commits, commits...)
+			}
+		}
+		sort.SliceStable(r.Commits, func(i, j int) bool { return r.Commits[i].At.After(r.Commits[j].At) })
+
+		if !r.empty() {
+			report.Sessions = append(report.Sessions, r)
+		}
+	}
+
+	sort.SliceStable(report.Sessions, func(i, j int) bool {
+		return report.Sessions[i].Minutes[history.StateWorking] > report.Sessions[j].Minutes[history.StateWorking]
+	})
+	report.Markdown = renderReport(report)
+	return report, nil
+}
+
+// addActivity adds b to a, which may be nil.
+func addActivity(a *claude.Activity, b claude.Activity) *claude.Activity {
+	if b.Conversations == 0 {
+		return a
+	}
+	if a == nil {
+		return &b
+	}
+	a.Conversations += b.Conversations
+	a.Messages += b.Messages
+	a.InputTokens += b.InputTokens
+	a.OutputTokens += b.OutputTokens
+	a.CacheCreationInputTokens += b.CacheCreationInputTokens
+	a.CacheReadInputTokens += b.CacheReadInputTokens
+	for tool, n := range b.ToolCalls {
+		a.ToolCalls[tool] += n
+	}
+	return a
+}
+
+func totalTokens(u claude.Usage) int {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// renderReport writes a report as Markdown.
+func renderReport(report DailyReport) string {
+	var b strings.Builder
+	var working, commits, tokens int
+	for _, r := range report.Sessions {
+		working += r.Minutes[history.StateWorking]
+		commits += len(r.Commits)
+		if r.Claude != nil {
+			tokens += totalTokens(r.Claude.Usage)
+		}
+	}
+
+	fmt.Fprintf(&b, "# Daily report\n\n%s – %s", report.Since.Local().Format("Jan 2 15:04"), report.Until.Local().Format("Jan 2 15:04"))
+	if len(report.Sessions) == 0 {
+		b.WriteString("\n\n_No activity._\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, " · %s · %s working · %s · %s tokens\n",
+		plural(len(report.Sessions), "session"), formatMinutes(working), plural(commits, "commit"), formatCount(tokens))
+
+	for _, r := range report.Sessions {
+		name := r.Session
+		if r.Host != "" {
+			name = r.Host + "|" + name
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", name)
+		fmt.Fprintf(&b, "- Working %s, waiting on you %s, %s answered\n",
+			formatMinutes(r.Minutes[history.StateWorking]), formatMinutes(r.Minutes[history.StateAttention]), plural(r.Answered, "prompt"))
+
+		if a := r.Claude; a != nil {
+			fmt.Fprintf(&b, "- Claude: %s, %s, %s tokens (%s in, %s out, %s cache read)\n",
+				plural(a.Conversations, "conversation"), plural(a.Messages, "response"), formatCount(totalTokens(a.Usage)),
+				formatCount(a.InputTokens), formatCount(a.OutputTokens), formatCount(a.CacheReadInputTokens))
+			if len(a.ToolCalls) > 0 {
+				tools := slices.SortedFunc(maps.Keys(a.ToolCalls), func(x, y string) int {
+					if a.ToolCalls[x] != a.ToolCalls[y] {
+						return a.ToolCalls[y] - a.ToolCalls[x]
+					}
+					return strings.Compare(x, y)
+				})
+				var parts []string
+				for _, tool := range tools[:min(len(tools), reportTools)] {
+					parts = append(parts, fmt.Sprintf("%s %d", tool, a.ToolCalls[tool]))
+				}
+				if more := len(tools) - reportTools; more > 0 {
+					parts = append(parts, fmt.Sprintf("%d more", more))
+				}
+				fmt.Fprintf(&b, "- Tools: %s\n", strings.Join(parts, ", "))
+			}
+		}
+
+		if len(r.Commits) > 0 {
+			fmt.Fprintf(&b, "- %s:\n", plural(len(r.Commits), "commit"))
+			for _, c := range r.Commits[:min(len(r.Commits), reportCommits)] {
+				fmt.Fprintf(&b, "  - `%s` %s\n", c.Hash, c.Subject)
+			}
+			if more := len(r.Commits) - reportCommits; more > 0 {
+				fmt.Fprintf(&b, "  - and %d more\n", more)
+			}
+		}
+	}
+	return b.String()
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatMinutes formats a number of minutes as "3h 12m".
+func formatMinutes(m int) string {
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh %dm", m/60, m%60)
+}
+
+// formatCount shortens a token count: 950, 12.3k, 1.2M.
+func formatCount(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprint(n)
+	case n < 1_000_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1e3)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1e6)
+	}
+}
+
+// handleAPIDailyReport serves /api/reports/daily: GET returns the report
+// as Markdown (format=json for the data), for the last 24 hours or the
+// calendar day given as date=YYYY-MM-DD. POST also delivers it through
+// the notification providers.
+func (s *Server) handleAPIDailyReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = "md"
+	}
+	if format != "md" && format != "json" {
+		http.Error(w, "format must be md or json", http.StatusBadRequest)
+		return
+	}
+	if r.Method == http.MethodPost && !s.notifier.Enabled() {
+		http.Error(w, "no notification provider configured", http.StatusConflict)
+		return
+	}
+
+	until := time.Now()
+	since := until.Add(-24 * time.Hour)
+	if date := q.Get("date"); date != "" {
+		day, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			http.Error(w, "date must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		since, until = day, day.AddDate(0, 0, 1)
+	}
+
+	report, err := s.dailyReport(since, until)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "daily report failed", "error", err)
+		http.Error(w, "failed to build report", http.StatusInternalServerError)
+		return
+	}
+	if r.Method == http.MethodPost {
+		s.sendReport(r.Context(), report)
+		auditDetail(r, since.Format(time.RFC3339)+" – "+until.Format(time.RFC3339))
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	_, _ = w.Write([]byte(report.Markdown))
+}
+
+// sendReport delivers a report through the notification providers.
+func (s *Server) sendReport(ctx context.Context, report DailyReport) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	s.notifier.Send(ctx, notify.Notification{
+		Key:   "daily-report",
+		Title: "houston daily report",
+		Body:  report.Markdown,
+		Since: report.Since,
+	})
+}
+
+// runDailyReport sends the report for the preceding 24 hours at each time
+// of the -daily-report schedule. Days without activity are skipped.
+func (s *Server) runDailyReport(ctx context.Context) {
+	if s.reportSchedule == nil || !s.notifier.Enabled() {
+		return
+	}
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	for {
+		next := s.reportSchedule.Next(time.Now())
+		if next.IsZero() {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+		report, err := s.dailyReport(next.Add(-24*time.Hour), next)
+		if err != nil {
+			slog.Error("daily report failed", "error", err)
+			continue
+		}
+		if len(report.Sessions) == 0 {
+			slog.Info("daily report skipped: no activity")
+			continue
+		}
+		s.sendReport(ctx, report)
+		s.audit(AuditEntry{Action: "daily-report", Client: auditSelf, Detail: plural(len(report.Sessions), "session")})
+		slog.Info("daily report sent", "sessions", len(report.Sessions))
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestRenderReport(t *testing.T) {
+	since := time.Date(2026, 3, 1, 18, 0, 0, 0, time.Local)
+	report := DailyReport{
+		Since: since,
+		Until: since.Add(24 * time.Hour),
+		Sessions: []SessionReport{{
+			Session:  "work",
+			Minutes:  map[string]int{"working": 192, "attention": 18, "idle": 400},
+			Answered: 5,
+			Claude: &claude.Activity{
+				Conversations: 2,
+				Messages:      140,
+				ToolCalls:     map[string]int{"Bash": 40, "Edit": 22, "Read": 22, "Grep": 3, "Glob": 2, "Write": 1, "Task": 1},
+				Usage:         claude.Usage{InputTokens: 20_000, OutputTokens: 80_000, CacheReadInputTokens: 1_100_000},
+			},
+			Commits: []tmux.Commit{{Hash: "abc1234", Subject: "Fix the flaky test"}},
+		}, {
+			Session: "api",
+			Host:    "devbox",
+			Minutes: map[string]int{"working": 30},
+			Commits: []tmux.Commit{},
+		}},
+	}
+	md := renderReport(report)
+	for _, want := range []string{
+		"# Daily report\n\nMar 1 18:00 – Mar 2 18:00 · 2 sessions · 3h 42m working · 1 commit · 1.2M tokens\n",
+		"## work\n\n- Working 3h 12m, waiting on you 18m, 5 prompts answered\n",
+		"- Claude: 2 conversations, 140 responses, 1.2M tokens (20.0k in, 80.0k out, 1.1M cache read)\n",
+		"- Tools: Bash 40, Edit 22, Read 22, Grep 3, Glob 2, 2 more\n",
+		"- 1 commit:\n  - `abc1234` Fix the flaky test\n",
+		"## devbox|api\n\n- Working 30m, waiting on you 0m, 0 prompts answered\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("report missing %q:\n%s", want, md)
+		}
+	}
+
+	empty := renderReport(DailyReport{Since: since, Until: since.Add(24 * time.Hour)})
+	if !strings.HasSuffix(empty, "Mar 1 18:00 – Mar 2 18:00\n\n_No activity._\n") {
+		t.Errorf("empty report = %q", empty)
+	}
+}
+
+func TestAddActivity(t *testing.T) {
+	var a *claude.Activity
+	a = addActivity(a, claude.Activity{ToolCalls: map[string]int{}}) // No conversations
+	if a != nil {
+		t.Fatalf("addActivity(nil, empty) = %+v, want nil", a)
+	}
+	a = addActivity(a, claude.Activity{Conversations: 1, Messages: 3, ToolCalls: map[string]int{"Bash": 2}})
+	a = addActivity(a, claude.Activity{Conversations: 2, Messages: 4, ToolCalls: map[string]int{"Bash": 1, "Edit": 1}, Usage: claude.Usage{OutputTokens: 9}})
+	if a.Conversations != 3 || a.Messages != 7 || a.OutputTokens != 9 || a.ToolCalls["Bash"] != 3 || a.ToolCalls["Edit"] != 1 {
+		t.Errorf("sum = %+v", a)
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/noamsto/houston/internal/logging"
+)
+
+// requestIDHeader carries a request's ID: taken from the request when a
+// proxy in front of houston assigned one, and set on every response.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds IDs taken from requests.
+const maxRequestIDLen = 128
+
+// requestIDMiddleware gives each request an ID, sent back in
+// X-Request-ID and added as request_id to what its handlers log with the
+// request's context, so the lines one request logged can be picked out.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID reports whether id, sent by a client, is fit to log:
+// short, of letters, digits and a few separators.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
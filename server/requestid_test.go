@@ -0,0 +1,33 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/noamsto/houston/internal/logging"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	h := requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/meta", nil))
+	if id := w.Header().Get(requestIDHeader); len(id) != 16 || id != seen {
+		t.Errorf("generated ID %q, handler saw %q", id, seen)
+	}
+
+	// A proxy's ID is kept, unless it's unfit to log
+	for sent, kept := range map[string]bool{"req-42.a:b_c": true, "two words": false, "x\ny": false} {
+		r := httptest.NewRequest("GET", "/api/meta", nil)
+		r.Header.Set(requestIDHeader, sent)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got := w.Header().Get(requestIDHeader); (got == sent) != kept || got != seen {
+			t.Errorf("sent %q: response ID %q, handler saw %q", sent, got, seen)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// sessionsSnapshotCount is how many recent sessions payloads are kept for
+// resuming SSE clients. At one payload per change this covers a phone that
+// was locked for a few minutes of normal activity.
+const sessionsSnapshotCount = 32
+
+// SessionsPatch is the catch-up payload for a resumed sessions stream: only
+// the sessions that changed since the client's last event, plus the full
+// ordering of each section by session key and the hidden sessions.
+// Sessions absent from every section were removed or hidden.
+type SessionsPatch struct {
+	Base           string                        `json:"base"` // Event ID the patch applies to
+	Changed        map[string]SessionWithWindows `json:"changed"`
+	NeedsAttention []string                      `json:"needs_attention"`
+	Active         []string                      `json:"active"`
+	Idle           []string                      `json:"idle"`
+	Hidden         []tmux.Session                `json:"hidden"`
+}
+
+type sessionsSnapshot struct {
+	id       string
+	raw      []byte
+	sessions map[string][]byte // session key -> encoded SessionWithWindows
+}
+
+// sessionsHistory numbers each distinct sessions payload so clients that
+// reconnect with Last-Event-ID can be sent only what changed. IDs carry a
+// per-process epoch so IDs from before a restart are never matched.
+type sessionsHistory struct {
+	mu    sync.Mutex
+	epoch string
+	seq   uint64
+	snaps []sessionsSnapshot
+}
+
+func newSessionsHistory() *sessionsHistory {
+	return &sessionsHistory{epoch: strconv.FormatInt(time.Now().UnixNano(), 36)}
+}
+
+// sessionKey identifies a session across hosts.
+func sessionKey(sess SessionWithWindows) string {
+	return tmux.Pane{Host: sess.Session.Host, Session: sess.Session.Name}.Key()
+}
+
+// record returns the event ID for a payload, reusing the latest ID when
+// another connection already sent the same payload.
+func (h *sessionsHistory) record(data SessionsData, raw []byte) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if n := len(h.snaps); n > 0 && bytes.Equal(h.snaps[n-1].raw, raw) {
+		return h.snaps[n-1].id
+	}
+
+	h.seq++
+	snap := sessionsSnapshot{
+		id:       h.epoch + "-" + strconv.FormatUint(h.seq, 10),
+		raw:      raw,
+		sessions: make(map[string][]byte),
+	}
+	for _, section := range [][]SessionWithWindows{data.NeedsAttention, data.Active, data.Idle} {
+		for _, sess := range section {
+			encoded, _ := json.Marshal(sess)
+			snap.sessions[sessionKey(sess)] = encoded
+		}
+	}
+	h.snaps = append(h.snaps, snap)
+	if len(h.snaps) > sessionsSnapshotCount {
+		h.snaps = h.snaps[len(h.snaps)-sessionsSnapshotCount:]
+	}
+	return snap.id
+}
+
+// patch builds the catch-up from base to data. ok is false when base is
+// unknown (evicted, or from another process) and a full payload is needed.
+func (h *sessionsHistory) patch(base string, data SessionsData) (SessionsPatch, bool) {
+	h.mu.Lock()
+	var baseSessions map[string][]byte
+	for _, snap := range h.snaps {
+		if snap.id == base {
+			baseSessions = snap.sessions
+			break
+		}
+	}
+	h.mu.Unlock()
+	if baseSessions == nil {
+		return SessionsPatch{}, false
+	}
+
+	p := SessionsPatch{
+		Base:           base,
+		Changed:        make(map[string]SessionWithWindows),
+		NeedsAttention: []string{},
+		Active:         []string{},
+		Idle:           []string{},
+		Hidden:         data.Hidden,
+	}
+	sections := []struct {
+		sessions []SessionWithWindows
+		keys     *[]string
+	}{
+		{data.NeedsAttention, &p.NeedsAttention},
+		{data.Active, &p.Active},
+		{data.Idle, &p.Idle},
+	}
+	for _, section := range sections {
+		for _, sess := range section.sessions {
+			key := sessionKey(sess)
+			*section.keys = append(*section.keys, key)
+			encoded, _ := json.Marshal(sess)
+			if !bytes.Equal(baseSessions[key], encoded) {
+				p.Changed[key] = sess
+			}
+		}
+	}
+	return p, true
+}
+
+// savedSnapshot is a sessions payload handed over to the next process.
+type savedSnapshot struct {
+	ID       string                     `json:"id"`
+	Sessions map[string]json.RawMessage `json:"sessions"`
+}
+
+// export returns the recent payloads, so clients of this process can
+// resume against the next one.
+func (h *sessionsHistory) export() []savedSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	saved := make([]savedSnapshot, 0, len(h.snaps))
+	for _, snap := range h.snaps {
+		sessions := make(map[string]json.RawMessage, len(snap.sessions))
+		for key, encoded := range snap.sessions {
+			sessions[key] = encoded
+		}
+		saved = append(saved, savedSnapshot{ID: snap.id, Sessions: sessions})
+	}
+	return saved
+}
+
+// restore adds payloads from a previous process before this one's own.
+// Their IDs carry the other process's epoch, so they never collide.
+func (h *sessionsHistory) restore(saved []savedSnapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snaps := make([]sessionsSnapshot, 0, len(saved)+len(h.snaps))
+	for _, sv := range saved {
+		snap := sessionsSnapshot{id: sv.ID, sessions: make(map[string][]byte, len(sv.Sessions))}
+		for key, encoded := range sv.Sessions {
+			// The store indents documents; patch compares compact encodings.
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, encoded); err != nil {
+				continue
+			}
+			snap.sessions[key] = buf.Bytes()
+		}
+		snaps = append(snaps, snap)
+	}
+	h.snaps = append(snaps, h.snaps...)
+	if len(h.snaps) > sessionsSnapshotCount {
+		h.snaps = h.snaps[len(h.snaps)-sessionsSnapshotCount:]
+	}
+}
+
+// outputID fingerprints pane output so a reconnecting pane socket can skip
+// resending output the client already shows.
+func outputID(output string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(output))
+	return strconv.FormatUint(h.Sum64(), 36)
+}
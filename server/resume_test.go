@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+func testSession(name string, attention int) SessionWithWindows {
+	return SessionWithWindows{Session: tmux.Session{Name: name}, AttentionCount: attention}
+}
+
+func TestSessionsHistoryPatch(t *testing.T) {
+	h := newSessionsHistory()
+	encode := func(d SessionsData) []byte {
+		raw, _ := json.Marshal(d)
+		return raw
+	}
+
+	first := SessionsData{
+		NeedsAttention: []SessionWithWindows{},
+		Active:         []SessionWithWindows{testSession("api", 0)},
+		Idle:           []SessionWithWindows{testSession("docs", 0), testSession("old", 0)},
+	}
+	id1 := h.record(first, encode(first))
+	if again := h.record(first, encode(first)); again != id1 {
+		t.Errorf("record(same payload) = %q, want reused %q", again, id1)
+	}
+
+	// api needs attention, docs unchanged, old removed
+	second := SessionsData{
+		NeedsAttention: []SessionWithWindows{testSession("api", 1)},
+		Active:         []SessionWithWindows{},
+		Idle:           []SessionWithWindows{testSession("docs", 0)},
+	}
+	id2 := h.record(second, encode(second))
+	if id2 == id1 {
+		t.Fatalf("record(changed payload) reused id %q", id1)
+	}
+
+	p, ok := h.patch(id1, second)
+	if !ok {
+		t.Fatal("patch(known base) not ok")
+	}
+	if len(p.Changed) != 1 || p.Changed["api"].AttentionCount != 1 {
+		t.Errorf("Changed = %+v, want only api", p.Changed)
+	}
+	if !reflect.DeepEqual(p.NeedsAttention, []string{"api"}) || len(p.Active) != 0 || !reflect.DeepEqual(p.Idle, []string{"docs"}) {
+		t.Errorf("order = %v / %v / %v, want [api] / [] / [docs]", p.NeedsAttention, p.Active, p.Idle)
+	}
+
+	if _, ok := h.patch("other-1", second); ok {
+		t.Error("patch(unknown base) ok, want full payload")
+	}
+}
+
+func TestSessionsHistoryEviction(t *testing.T) {
+	h := newSessionsHistory()
+	var ids []string
+	for i := 0; i <= sessionsSnapshotCount; i++ {
+		d := SessionsData{Idle: []SessionWithWindows{testSession("s", i)}}
+		raw, _ := json.Marshal(d)
+		ids = append(ids, h.record(d, raw))
+	}
+	if _, ok := h.patch(ids[0], SessionsData{}); ok {
+		t.Error("patch(evicted base) ok, want full payload")
+	}
+	if _, ok := h.patch(ids[1], SessionsData{}); !ok {
+		t.Error("patch(oldest kept base) not ok")
+	}
+}
@@ -0,0 +1,22 @@
+package server
+
+import (
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+)
+
+// verifyChoices keeps a result's choices only when the agent confirms they
+// are the options of its own selector on screen. Otherwise they move to
+// RawChoices: agent output can print lines such as "1. Approve all" to look
+// like a prompt, and clients must never turn those into buttons.
+func verifyChoices(agent agents.Agent, result parser.Result, output string) parser.Result {
+	if len(result.Choices) == 0 {
+		return result
+	}
+	if v, ok := agent.(agents.ChoiceVerifier); ok && v.VerifyChoices(output, result.Choices) {
+		return result
+	}
+	result.RawChoices = result.Choices
+	result.Choices = nil
+	return result
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/agents/generic"
+	"github.com/noamsto/houston/parser"
+)
+
+func TestVerifyChoices(t *testing.T) {
+	selector := "Do you want to proceed?\n❯ 1. Yes\n  2. No\n"
+	bait := "Do you want to proceed?\n  1. Approve all\n  2. Deny\n● Reading file…\n"
+
+	tests := []struct {
+		name      string
+		output    string
+		wantFinal bool // Choices kept as actions
+	}{
+		{"claude selector", selector, true},
+		{"claude bait", bait, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parser.Parse(tt.output)
+			choices := result.Choices
+			got := verifyChoices(claude.New(), result, tt.output)
+			if tt.wantFinal {
+				if !slices.Equal(got.Choices, choices) || got.RawChoices != nil {
+					t.Errorf("verifyChoices() = %v / raw %v, want choices %v", got.Choices, got.RawChoices, choices)
+				}
+				return
+			}
+			if got.Choices != nil || !slices.Equal(got.RawChoices, choices) {
+				t.Errorf("verifyChoices() = %v / raw %v, want raw %v", got.Choices, got.RawChoices, choices)
+			}
+		})
+	}
+
+	// Agents without a verifier never produce actionable choices
+	result := verifyChoices(generic.New(), parser.Parse(selector), selector)
+	if result.Choices != nil || len(result.RawChoices) != 2 {
+		t.Errorf("generic verifyChoices() = %v / raw %v, want only raw choices", result.Choices, result.RawChoices)
+	}
+}
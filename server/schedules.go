@@ -0,0 +1,387 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/schedule"
+	"github.com/noamsto/houston/tmux"
+)
+
+// schedulesDocument is the store document holding scheduled prompts.
+const schedulesDocument = "schedules"
+
+// scheduleInterval is how often schedules are checked. Cron fires at most
+// once a minute, so a schedule runs within this long of its time.
+const scheduleInterval = 15 * time.Second
+
+// Schedule sends a prompt to a pane, or adds it to the pane's prompt
+// queue, whenever its cron expression fires (local time).
+type Schedule struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name,omitempty"`
+	Cron      string     `json:"cron"`           // e.g. "0 9 * * mon-fri": weekdays at 9:00
+	Target    string     `json:"target"`         // Pane ("work:1.0") or session ("standup": its first agent window)
+	Host      string     `json:"host,omitempty"` // Remote host of Target (default: local)
+	Text      string     `json:"text"`
+	Queue     bool       `json:"queue,omitempty"` // Queue until the agent is done instead of sending right away
+	Enabled   bool       `json:"enabled"`
+	Next      time.Time  `json:"next"` // Next run (zero when disabled); set by the server
+	LastRun   *time.Time `json:"last_run,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+type compiledSchedule struct {
+	Schedule
+	spec schedule.Spec
+}
+
+func compileSchedule(sc Schedule) (compiledSchedule, error) {
+	spec, err := schedule.Parse(sc.Cron)
+	if err != nil {
+		return compiledSchedule{}, err
+	}
+	if strings.TrimSpace(sc.Target) == "" {
+		return compiledSchedule{}, errors.New("target is required")
+	}
+	if strings.TrimSpace(sc.Text) == "" {
+		return compiledSchedule{}, errors.New("text is required")
+	}
+	return compiledSchedule{Schedule: sc, spec: spec}, nil
+}
+
+// scheduler holds the schedules and when each runs next.
+type scheduler struct {
+	mu        sync.Mutex
+	schedules []compiledSchedule
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{}
+}
+
+func (sc *scheduler) list() []Schedule {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	schedules := make([]Schedule, 0, len(sc.schedules))
+	for _, s := range sc.schedules {
+		schedules = append(schedules, s.Schedule)
+	}
+	return schedules
+}
+
+// set replaces or adds a schedule, keeping the list ordered by ID. Its next
+// run is computed from now; runs missed while it was disabled or houston
+// was down are skipped.
+func (sc *scheduler) set(cs compiledSchedule, now time.Time) Schedule {
+	cs.Next = time.Time{}
+	if cs.Enabled {
+		cs.Next = cs.spec.Next(now)
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for i := range sc.schedules {
+		if sc.schedules[i].ID == cs.ID {
+			sc.schedules[i] = cs
+			return cs.Schedule
+		}
+	}
+	sc.schedules = append(sc.schedules, cs)
+	sort.Slice(sc.schedules, func(i, j int) bool { return sc.schedules[i].ID < sc.schedules[j].ID })
+	return cs.Schedule
+}
+
+func (sc *scheduler) get(id string) (Schedule, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for _, s := range sc.schedules {
+		if s.ID == id {
+			return s.Schedule, true
+		}
+	}
+	return Schedule{}, false
+}
+
+func (sc *scheduler) remove(id string) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for i, s := range sc.schedules {
+		if s.ID == id {
+			sc.schedules = append(sc.schedules[:i], sc.schedules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// due returns the enabled schedules whose time has come and moves each to
+// its next run.
+func (sc *scheduler) due(now time.Time) []Schedule {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	var due []Schedule
+	for i := range sc.schedules {
+		s := &sc.schedules[i]
+		if !s.Enabled || s.Next.IsZero() || s.Next.After(now) {
+			continue
+		}
+		due = append(due, s.Schedule)
+		s.Next = s.spec.Next(now)
+	}
+	return due
+}
+
+// record notes the outcome of a run.
+func (sc *scheduler) record(id string, at time.Time, err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for i := range sc.schedules {
+		if sc.schedules[i].ID != id {
+			continue
+		}
+		sc.schedules[i].LastRun = &at
+		sc.schedules[i].LastError = ""
+		if err != nil {
+			sc.schedules[i].LastError = err.Error()
+		}
+	}
+}
+
+// loadSchedules restores schedules from the store. Invalid entries are
+// logged and skipped.
+func (s *Server) loadSchedules() {
+	var schedules []Schedule
+	if err := s.store.Load(schedulesDocument, &schedules); err != nil {
+		slog.Warn("failed to load schedules", "error", err)
+	}
+	now := time.Now()
+	for _, sc := range schedules {
+		if sc.ID == "" {
+			sc.ID = newPromptID()
+		}
+		cs, err := compileSchedule(sc)
+		if err != nil {
+			slog.Warn("skipping invalid schedule", "id", sc.ID, "error", err)
+			continue
+		}
+		s.schedules.set(cs, now)
+	}
+}
+
+func (s *Server) saveSchedules() error {
+	return s.store.Save(schedulesDocument, s.schedules.list())
+}
+
+// runSchedules sends scheduled prompts when they are due.
+func (s *Server) runSchedules(ctx context.Context) {
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			due := s.schedules.due(now)
+			for _, sc := range due {
+				s.schedules.record(sc.ID, now, s.runSchedule(sc, now))
+			}
+			if len(due) > 0 {
+				if err := s.saveSchedules(); err != nil {
+					slog.Error("failed to save schedules", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// runSchedule sends or queues a schedule's prompt.
+func (s *Server) runSchedule(sc Schedule, now time.Time) error {
+	pane, err := s.scheduleTarget(sc)
+	if err != nil {
+		slog.Warn("scheduled prompt not sent", "schedule", sc.ID, "target", sc.Target, "error", err)
+		return err
+	}
+	if sc.Queue {
+		s.queues.add(pane, sc.Text, now)
+		s.savePromptQueues()
+		slog.Info("scheduled prompt queued", "schedule", sc.ID, "pane", pane.Target())
+		return nil
+	}
+	if err := s.sendPrompt(context.Background(), pane, sc.Text); err != nil {
+		// Queued, it's sent the next time the agent is ready
+		s.queues.requeue(pane, QueuedPrompt{ID: newPromptID(), Text: sc.Text, Created: now})
+		s.savePromptQueues()
+		slog.Error("scheduled prompt failed; queued to try again", "schedule", sc.ID, "pane", pane.Target(), "error", err)
+		return err
+	}
+	s.responses.Answered(windowKey(pane), now)
+	slog.Info("scheduled prompt sent", "schedule", sc.ID, "pane", pane.Target())
+	s.audit(AuditEntry{Action: "schedule", Target: pane.Key(), Detail: sc.Text, Client: auditSelf})
+	return nil
+}
+
+// scheduleTarget resolves a schedule's target to a live pane. A session
+// name resolves to its lowest-numbered window running an agent, or its
+// first window if none does.
+func (s *Server) scheduleTarget(sc Schedule) (tmux.Pane, error) {
+	c := s.client(sc.Host)
+	if c == nil {
+		return tmux.Pane{}, fmt.Errorf("unknown host %q", sc.Host)
+	}
+	if strings.Contains(sc.Target, ":") {
+		pane := parseTarget(sc.Target)
+		pane.Host = sc.Host
+		if _, ok := s.lookupPaneInfo(pane); !ok {
+			return tmux.Pane{}, fmt.Errorf("pane %s not found", sc.Target)
+		}
+		return pane, nil
+	}
+
+	q := sessionsQuery{session: regexp.MustCompile("^" + regexp.QuoteMeta(sc.Target) + "$")}
+	var windows []WindowWithStatus
+	for _, w := range s.buildSessionsData(context.Background(), q).allWindows() {
+		if w.Pane.Host == sc.Host {
+			windows = append(windows, w)
+		}
+	}
+	if len(windows) == 0 {
+		return tmux.Pane{}, fmt.Errorf("session %s not found", sc.Target)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].Pane.Window < windows[j].Pane.Window })
+	for _, w := range windows {
+		if w.AgentType != agents.AgentGeneric {
+			return w.Pane, nil
+		}
+	}
+	return windows[0].Pane, nil
+}
+
+// handleAPISchedules serves GET (list) and POST (create) on /api/schedules.
+func (s *Server) handleAPISchedules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.schedules.list())
+	case http.MethodPost:
+		var sc Schedule
+		if err := json.NewDecoder(r.Body).Decode(&sc); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if sc.ID == "" {
+			sc.ID = newPromptID()
+		} else if _, exists := s.schedules.get(sc.ID); exists {
+			http.Error(w, "schedule already exists", http.StatusConflict)
+			return
+		}
+		s.saveSchedule(w, sc, http.StatusCreated)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPISchedule serves /api/schedules/{id} (GET, PUT replaces, DELETE)
+// and POST /api/schedules/{id}/run, which runs a schedule right away.
+func (s *Server) handleAPISchedule(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/schedules/")
+
+	if id, ok := strings.CutSuffix(id, "/run"); ok {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		sc, ok := s.schedules.get(id)
+		if !ok {
+			http.Error(w, "schedule not found", http.StatusNotFound)
+			return
+		}
+		now := time.Now()
+		runErr := s.runSchedule(sc, now)
+		s.schedules.record(id, now, runErr)
+		if err := s.saveSchedules(); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save schedules", "error", err)
+		}
+		if runErr != nil {
+			http.Error(w, runErr.Error(), http.StatusBadGateway)
+			return
+		}
+		sc, _ = s.schedules.get(id)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sc)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		sc, ok := s.schedules.get(id)
+		if !ok {
+			http.Error(w, "schedule not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sc)
+	case http.MethodPut:
+		var sc Schedule
+		if err := json.NewDecoder(r.Body).Decode(&sc); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		sc.ID = id
+		// Run history survives edits
+		if old, ok := s.schedules.get(id); ok {
+			sc.LastRun, sc.LastError = old.LastRun, old.LastError
+		}
+		s.saveSchedule(w, sc, http.StatusOK)
+	case http.MethodDelete:
+		if !s.schedules.remove(id) {
+			http.Error(w, "schedule not found", http.StatusNotFound)
+			return
+		}
+		if err := s.saveSchedules(); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save schedules", "error", err)
+			http.Error(w, "failed to save schedules", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(r.Context(), "schedule deleted", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) saveSchedule(w http.ResponseWriter, sc Schedule, code int) {
+	if s.client(sc.Host) == nil {
+		http.Error(w, fmt.Sprintf("unknown host %q", sc.Host), http.StatusBadRequest)
+		return
+	}
+	cs, err := compileSchedule(sc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sc = s.schedules.set(cs, time.Now())
+	if err := s.saveSchedules(); err != nil {
+		slog.Error("failed to save schedules", "error", err)
+		http.Error(w, "failed to save schedules", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("schedule saved", "id", sc.ID, "cron", sc.Cron, "target", sc.Target, "queue", sc.Queue, "enabled", sc.Enabled, "next", sc.Next)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(sc)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedulerDue(t *testing.T) {
+	sc := newScheduler()
+	now := time.Date(2026, 10, 16, 8, 59, 40, 0, time.Local)
+
+	daily, err := compileSchedule(Schedule{ID: "a", Cron: "0 9 * * *", Target: "standup", Text: "summarize", Enabled: true})
+	if err != nil {
+		t.Fatalf("compileSchedule: %v", err)
+	}
+	if got := sc.set(daily, now); !got.Next.Equal(time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)) {
+		t.Fatalf("Next = %v, want 9:00 today", got.Next)
+	}
+	paused := daily
+	paused.ID, paused.Enabled = "b", false
+	if got := sc.set(paused, now); !got.Next.IsZero() {
+		t.Errorf("disabled schedule Next = %v, want zero", got.Next)
+	}
+
+	if due := sc.due(now); len(due) != 0 {
+		t.Errorf("due before 9:00 = %v", due)
+	}
+	nine := now.Add(25 * time.Second)
+	due := sc.due(nine)
+	if len(due) != 1 || due[0].ID != "a" {
+		t.Fatalf("due at 9:00 = %+v, want a", due)
+	}
+	if due := sc.due(nine.Add(scheduleInterval)); len(due) != 0 {
+		t.Errorf("schedule ran twice: %+v", due)
+	}
+	got, _ := sc.get("a")
+	if !got.Next.Equal(time.Date(2026, 10, 17, 9, 0, 0, 0, time.Local)) {
+		t.Errorf("Next after run = %v, want 9:00 tomorrow", got.Next)
+	}
+
+	sc.record("a", nine, nil)
+	if got, _ := sc.get("a"); got.LastRun == nil || !got.LastRun.Equal(nine) || got.LastError != "" {
+		t.Errorf("after record: %+v", got)
+	}
+}
+
+func TestCompileScheduleErrors(t *testing.T) {
+	for _, sc := range []Schedule{
+		{ID: "a", Cron: "0 9 * *", Target: "x", Text: "hi"},
+		{ID: "b", Cron: "0 9 * * *", Text: "hi"},
+		{ID: "c", Cron: "0 9 * * *", Target: "x", Text: "  "},
+	} {
+		if _, err := compileSchedule(sc); err == nil {
+			t.Errorf("compileSchedule(%+v) = nil error, want error", sc)
+		}
+	}
+}
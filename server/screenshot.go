@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/noamsto/houston/internal/ansi"
+	"github.com/noamsto/houston/internal/screenshot"
+	"github.com/noamsto/houston/tmux"
+)
+
+// maxScreenshotLines bounds ?lines= on /api/pane/{target}/screenshot.png.
+const maxScreenshotLines = 500
+
+// handlePaneScreenshot serves GET /api/pane/{target}/screenshot.png: the
+// pane's visible screen, with its colors, rendered to a PNG for
+// notifications and chat messages. ?lines=N takes the last N lines
+// including scrollback instead, ?theme=light uses the light palette and
+// ?scale=2 doubles the resolution. Blank lines at the bottom are left out.
+func (s *Server) handlePaneScreenshot(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	opts := screenshot.Options{Theme: screenshot.Dark, Size: screenshot.DefaultSize}
+	switch q.Get("theme") {
+	case "", "dark":
+	case "light":
+		opts.Theme = screenshot.Light
+	default:
+		http.Error(w, "theme must be dark or light", http.StatusBadRequest)
+		return
+	}
+	if v := q.Get("scale"); v != "" {
+		scale, err := strconv.ParseFloat(v, 64)
+		if err != nil || scale < 0.5 || scale > 4 {
+			http.Error(w, "scale must be between 0.5 and 4", http.StatusBadRequest)
+			return
+		}
+		opts.Size *= scale
+	}
+
+	c := s.client(pane.Host)
+	width, height, err := c.GetPaneSize(pane)
+	if err != nil || height == 0 {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	lines := height
+	if v := q.Get("lines"); v != "" {
+		if lines, err = strconv.Atoi(v); err != nil || lines < 1 || lines > maxScreenshotLines {
+			http.Error(w, "lines must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+	}
+	capture, err := c.CaptureRange(pane, height-lines, height-1)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "screenshot capture failed", "pane", pane.Target(), "error", err)
+		http.Error(w, "failed to capture pane", http.StatusInternalServerError)
+		return
+	}
+	opts.Cols = width
+
+	var buf bytes.Buffer
+	if err := screenshot.PNG(&buf, trimBlankLines(capture), opts); err != nil {
+		slog.ErrorContext(r.Context(), "screenshot render failed", "pane", pane.Target(), "error", err)
+		http.Error(w, "failed to render screenshot", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// trimBlankLines drops the lines at the end of a capture that show
+// nothing, keeping at least one.
+func trimBlankLines(capture string) string {
+	lines := strings.Split(strings.TrimRight(capture, "\n"), "\n")
+	for len(lines) > 1 && strings.TrimSpace(ansi.Strip(lines[len(lines)-1])) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
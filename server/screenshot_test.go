@@ -0,0 +1,17 @@
+package server
+
+import "testing"
+
+func TestTrimBlankLines(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"$ ls\nfile\n\n  \n\x1b[0m\n", "$ ls\nfile"},
+		{"\n\n", ""},
+		{"one", "one"},
+		{"top\n\nbottom\n", "top\n\nbottom"},
+	}
+	for _, tt := range tests {
+		if got := trimBlankLines(tt.in); got != tt.want {
+			t.Errorf("trimBlankLines(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
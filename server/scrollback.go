@@ -0,0 +1,101 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/noamsto/houston/internal/ansi"
+	"github.com/noamsto/houston/tmux"
+)
+
+const (
+	defaultHistoryLines = 500
+	maxHistoryLines     = 5000
+)
+
+// HistoryPage is one page of pane scrollback, counted from the bottom of the
+// pane. The pane view already holds the last 500 lines, so the SPA requests
+// before=500 first and then follows NextBefore while HasMore is set.
+type HistoryPage struct {
+	Output     string `json:"output"`
+	Before     int    `json:"before"`
+	Lines      int    `json:"lines"`
+	NextBefore int    `json:"next_before"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// historyRange converts a before/lines window (measured upward from the last
+// visible line) into tmux capture-pane -S/-E line numbers. It clamps to the
+// top of the scrollback and reports whether older lines remain. ok is false
+// when the window starts above the oldest line.
+func historyRange(height, historySize, before, lines int) (start, end int, more, ok bool) {
+	end = height - 1 - before
+	start = end - lines + 1
+	oldest := -historySize
+	if end < oldest {
+		return 0, 0, false, false
+	}
+	if start <= oldest {
+		start = oldest
+	} else {
+		more = true
+	}
+	return start, end, more, true
+}
+
+func (s *Server) handlePaneHistory(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	before, lines := 0, defaultHistoryLines
+	if v := r.URL.Query().Get("before"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			http.Error(w, "before must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		before = n
+	}
+	if v := r.URL.Query().Get("lines"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistoryLines {
+			http.Error(w, "lines must be between 1 and "+strconv.Itoa(maxHistoryLines), http.StatusBadRequest)
+			return
+		}
+		lines = n
+	}
+
+	client := s.client(pane.Host)
+	_, height, err := client.GetPaneSize(pane)
+	if err != nil {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+	historySize, err := client.GetHistorySize(pane)
+	if err != nil {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	page := HistoryPage{Before: before, NextBefore: before}
+	if start, end, more, ok := historyRange(height, historySize, before, lines); ok {
+		output, err := client.CaptureRange(pane, start, end)
+		if err != nil {
+			http.Error(w, "failed to capture pane", http.StatusInternalServerError)
+			return
+		}
+		if !wantColors(r) {
+			output = ansi.Strip(output)
+		}
+		page.Output = output
+		page.Lines = end - start + 1
+		page.NextBefore = before + page.Lines
+		page.HasMore = more
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(page)
+}
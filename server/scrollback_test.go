@@ -0,0 +1,27 @@
+package server
+
+import "testing"
+
+func TestHistoryRange(t *testing.T) {
+	tests := []struct {
+		name                       string
+		height, history, before, n int
+		wantStart, wantEnd         int
+		wantMore, wantOK           bool
+	}{
+		{"first page above view", 40, 1000, 40, 100, -100, -1, true, true},
+		{"includes visible lines", 40, 1000, 0, 50, -10, 39, true, true},
+		{"clamped at top", 40, 100, 100, 500, -100, -61, false, true},
+		{"exactly reaches top", 40, 100, 40, 100, -100, -1, false, true},
+		{"past top", 40, 100, 140, 100, 0, 0, false, false},
+		{"no scrollback", 40, 0, 0, 500, 0, 39, false, true},
+	}
+	for _, tt := range tests {
+		start, end, more, ok := historyRange(tt.height, tt.history, tt.before, tt.n)
+		if start != tt.wantStart || end != tt.wantEnd || more != tt.wantMore || ok != tt.wantOK {
+			t.Errorf("%s: historyRange(%d, %d, %d, %d) = %d, %d, %v, %v, want %d, %d, %v, %v",
+				tt.name, tt.height, tt.history, tt.before, tt.n,
+				start, end, more, ok, tt.wantStart, tt.wantEnd, tt.wantMore, tt.wantOK)
+		}
+	}
+}
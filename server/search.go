@@ -0,0 +1,210 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/internal/ansi"
+	"github.com/noamsto/houston/tmux"
+)
+
+const (
+	// searchCaptureLines is how much scrollback of each pane is searched.
+	searchCaptureLines = 2000
+	// searchDetectLines is the bottom of the capture the pane's agent is
+	// detected from, as on the dashboard.
+	searchDetectLines = 100
+	// searchMaxMatches caps the matches returned.
+	searchMaxMatches = 200
+	// searchExcerpt is how many bytes of a matching line or transcript
+	// entry are returned.
+	searchExcerpt = 160
+)
+
+// SearchMatch is a hit of GET /api/search, linked to the window it was
+// found in.
+type SearchMatch struct {
+	Source    string           `json:"source"`              // "pane", or the agent type of a transcript
+	Pane      tmux.Pane        `json:"pane"`                // Pane to open for the match
+	Target    string           `json:"target"`              // URL-safe pane target
+	Window    string           `json:"window"`              // Window name
+	Line      int              `json:"line,omitempty"`      // Pane matches: lines above the bottom of the pane
+	Role      agents.EntryRole `json:"role,omitempty"`      // Transcript matches: who wrote it
+	Timestamp *time.Time       `json:"timestamp,omitempty"` // Transcript matches: when it was written
+	Text      string           `json:"text"`                // The matching line, or an excerpt around the match
+}
+
+// SearchResults is the response of GET /api/search.
+type SearchResults struct {
+	Query     string        `json:"query"`
+	Matches   []SearchMatch `json:"matches"`
+	Truncated bool          `json:"truncated,omitempty"` // More matches than searchMaxMatches
+}
+
+// handleAPISearch serves GET /api/search?q=TEXT: a case-insensitive search
+// of the recent output of every pane and the agent conversations running
+// in local panes (Claude Code and Amp transcripts). Pane matches come
+// newest first.
+func (s *Server) handleAPISearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if utf8.RuneCountInString(q) < 2 {
+		http.Error(w, "q must be at least 2 characters", http.StatusBadRequest)
+		return
+	}
+
+	results := SearchResults{Query: q, Matches: []SearchMatch{}}
+	add := func(m SearchMatch) bool {
+		if len(results.Matches) == searchMaxMatches {
+			results.Truncated = true
+			return false
+		}
+		m.Target = m.Pane.URLTarget()
+		results.Matches = append(results.Matches, m)
+		return true
+	}
+
+	// A conversation is searched once, however many panes sit in its directory
+	searched := make(map[string]bool)
+
+search:
+	for _, sess := range s.listAllSessions(r.Context()) {
+		c := s.client(sess.Host)
+		for _, win := range sess.Windows {
+			for _, p := range win.Panes {
+				pane := tmux.Pane{Host: sess.Host, Session: sess.Name, Window: win.Index, Index: p.Index}
+				output, err := c.CapturePane(pane, searchCaptureLines)
+				if err != nil {
+					continue
+				}
+				output = ansi.Strip(output)
+
+				for _, m := range searchLines(output, q) {
+					m.Pane, m.Window = pane, win.Name
+					if !add(m) {
+						break search
+					}
+				}
+
+				// Agent files are local
+				if sess.Host != "" || p.Path == "" {
+					continue
+				}
+				agent := s.registry.Detect(pane.Key(), p.Command, lastLines(output, searchDetectLines))
+				provider, ok := agent.(agents.TranscriptProvider)
+				if !ok || searched[string(agent.Type())+"|"+p.Path] {
+					continue
+				}
+				searched[string(agent.Type())+"|"+p.Path] = true
+				transcript, err := provider.Transcript(p.Path)
+				if err != nil {
+					continue
+				}
+				for _, m := range searchTranscript(transcript, q) {
+					m.Pane, m.Window = pane, win.Name
+					if !add(m) {
+						break search
+					}
+				}
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(results)
+}
+
+// searchLines returns the lines of output containing q, bottom first.
+func searchLines(output, q string) []SearchMatch {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	var matches []SearchMatch
+	for i := len(lines) - 1; i >= 0; i-- {
+		if indexFold(lines[i], q) >= 0 {
+			matches = append(matches, SearchMatch{
+				Source: "pane",
+				Line:   len(lines) - 1 - i,
+				Text:   excerpt(lines[i], q),
+			})
+		}
+	}
+	return matches
+}
+
+// searchTranscript returns the entries of a conversation mentioning q,
+// most recent first.
+func searchTranscript(t *agents.Transcript, q string) []SearchMatch {
+	var matches []SearchMatch
+	for i := len(t.Entries) - 1; i >= 0; i-- {
+		e := t.Entries[i]
+		text := e.Text
+		if e.Role == agents.RoleTool {
+			text = strings.TrimSpace(e.Tool + " " + e.ToolInput)
+		}
+		if indexFold(text, q) < 0 {
+			continue
+		}
+		m := SearchMatch{
+			Source: string(t.Agent),
+			Role:   e.Role,
+			Text:   excerpt(text, q),
+		}
+		if !e.Timestamp.IsZero() {
+			ts := e.Timestamp
+			m.Timestamp = &ts
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// indexFold returns the byte offset of the first case-insensitive match
+// of q in s, or -1.
+func indexFold(s, q string) int {
+	// Lowering keeps ASCII offsets; other text is matched but offsets may
+	// drift, which only shifts the excerpt.
+	return strings.Index(strings.ToLower(s), strings.ToLower(q))
+}
+
+// excerpt returns up to searchExcerpt bytes of text around the first
+// match of q, on one line.
+func excerpt(text, q string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= searchExcerpt {
+		return text
+	}
+	at := min(max(indexFold(text, q), 0), len(text))
+	start := max(0, at-(searchExcerpt-len(q))/2)
+	end := min(len(text), start+searchExcerpt)
+	start = max(0, end-searchExcerpt)
+	// Don't cut runes in half
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	result := text[start:end]
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(text) {
+		result += "…"
+	}
+	return result
+}
+
+// lastLines returns the last n lines of output.
+func lastLines(output string, n int) string {
+	lines := strings.Split(output, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+)
+
+func TestSearchLines(t *testing.T) {
+	output := "$ ls\nmigrate.sh\n$ ./Migrate.sh --dry-run\nok\n$\n"
+	got := searchLines(output, "migrate")
+	if len(got) != 2 {
+		t.Fatalf("got %d matches, want 2: %+v", len(got), got)
+	}
+	if got[0].Text != "$ ./Migrate.sh --dry-run" || got[0].Line != 2 {
+		t.Errorf("first match = %+v, want the newest line, 2 above the bottom", got[0])
+	}
+	if got[1].Text != "migrate.sh" || got[1].Line != 3 {
+		t.Errorf("second match = %+v", got[1])
+	}
+}
+
+func TestSearchTranscript(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	transcript := &agents.Transcript{
+		Agent: agents.AgentClaudeCode,
+		Entries: []agents.TranscriptEntry{
+			{Role: agents.RoleUser, Text: "Write a migration script for the users table", Timestamp: at},
+			{Role: agents.RoleTool, Tool: "Bash", ToolInput: "psql -f migrations/002_users.sql"},
+			{Role: agents.RoleAssistant, Text: "Done."},
+		},
+	}
+	got := searchTranscript(transcript, "MIGRATION")
+	if len(got) != 2 {
+		t.Fatalf("got %d matches, want 2: %+v", len(got), got)
+	}
+	if got[0].Role != agents.RoleTool || got[0].Text != "Bash psql -f migrations/002_users.sql" {
+		t.Errorf("first match = %+v, want the tool call", got[0])
+	}
+	if got[1].Source != "claude-code" || got[1].Timestamp == nil || !got[1].Timestamp.Equal(at) {
+		t.Errorf("second match = %+v, want the prompt with its time", got[1])
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	text := strings.Repeat("a ", 200) + "needle " + strings.Repeat("é ", 200)
+	got := excerpt(text, "needle")
+	if !strings.Contains(got, "needle") || !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") {
+		t.Errorf("excerpt = %q, want the match with both ends cut", got)
+	}
+	if len(got) > searchExcerpt+2*len("…")+1 {
+		t.Errorf("excerpt is %d bytes", len(got))
+	}
+	if got := excerpt("  short\n\tline ", "short"); got != "short line" {
+		t.Errorf("excerpt = %q, want whitespace collapsed", got)
+	}
+}
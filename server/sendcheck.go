@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/internal/ansi"
+	"github.com/noamsto/houston/tmux"
+)
+
+const (
+	// echoWait is how long a send waits for its input to show on the
+	// pane, looking every echoPoll.
+	echoWait = 1500 * time.Millisecond
+	echoPoll = 100 * time.Millisecond
+	// echoLines is the scrollback captured around a send, enough that the
+	// output of a command run by it doesn't push the input out.
+	echoLines = 200
+	// echoProbeLen is how much of the end of the input is looked for.
+	echoProbeLen = 32
+)
+
+// pastedMarker is how Claude Code shows a long paste in its prompt instead
+// of the text, without whitespace as echoCount compares.
+const pastedMarker = "[Pastedtext"
+
+// SendResult is the reply of POST /api/pane/{target}/send. Input that was
+// sent but not seen on the pane (the command may have cleared the screen)
+// is Checked without being Confirmed; don't send it again. A pane that
+// couldn't be taken out of copy-mode was sent nothing and fails with 409
+// and Error set.
+type SendResult struct {
+	ExitedMode string `json:"exited_mode,omitempty"` // tmux mode the pane was taken out of first
+	Checked    bool   `json:"checked"`               // The input was looked for on the pane
+	Confirmed  bool   `json:"confirmed"`             // and showed up
+	Error      string `json:"error,omitempty"`       // Why nothing was sent
+}
+
+// echoProbe returns what to look for on a pane to confirm text reached
+// it: the end of its last non-blank line, without whitespace since panes
+// wrap and pad lines. It's "" when there's nothing to look for.
+func echoProbe(text string) string {
+	lines := strings.Split(text, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		probe := []rune(strings.Join(strings.Fields(lines[i]), ""))
+		if len(probe) > 0 {
+			return string(probe[max(len(probe)-echoProbeLen, 0):])
+		}
+	}
+	return ""
+}
+
+// echoCount counts the sightings of probe in a capture, counting pastes
+// an agent collapsed as well.
+func echoCount(capture, probe string) int {
+	flat := strings.Join(strings.Fields(ansi.Strip(capture)), "")
+	return strings.Count(flat, probe) + strings.Count(flat, pastedMarker)
+}
+
+// leaveMode takes pane out of copy-mode (or any other tmux mode), which
+// would otherwise swallow input, noting the mode in result. It's false,
+// with result.Error set, when the pane stays in the mode and nothing
+// should be sent.
+func leaveMode(c *tmux.Client, pane tmux.Pane, result *SendResult) bool {
+	tmuxMode, err := c.PaneMode(pane)
+	if err != nil || tmuxMode == "" {
+		return true
+	}
+	if err := c.ExitMode(pane); err != nil {
+		result.Error = "pane is in " + tmuxMode + ": " + err.Error()
+		return false
+	}
+	result.ExitedMode = tmuxMode
+	return true
+}
+
+// sendKey sends a special key (Enter, C-c, ...) to pane, first taking it
+// out of any tmux mode.
+func (s *Server) sendKey(ctx context.Context, pane tmux.Pane, key string) (SendResult, error) {
+	c := s.client(pane.Host).WithContext(ctx)
+	var result SendResult
+	if !leaveMode(c, pane, &result) {
+		return result, nil
+	}
+	return result, c.SendSpecialKey(pane, key)
+}
+
+// sendChecked types or pastes text into pane, first taking it out of any
+// tmux mode. With verify, it then waits for the text to show up on the
+// pane. The error is for a send that failed outright; a send that went
+// unseen is reported in the result.
+func (s *Server) sendChecked(ctx context.Context, pane tmux.Pane, text string, enter bool, mode tmux.InputMode, verify bool) (SendResult, error) {
+	c := s.client(pane.Host).WithContext(ctx)
+	var result SendResult
+	if !leaveMode(c, pane, &result) {
+		return result, nil
+	}
+
+	probe := echoProbe(text)
+	result.Checked = verify && probe != ""
+	before := 0
+	if result.Checked {
+		capture, err := c.CapturePane(pane, echoLines)
+		if err != nil {
+			result.Checked = false
+		}
+		before = echoCount(capture, probe)
+	}
+
+	if err := c.SendText(pane, text, enter, mode); err != nil {
+		return result, err
+	}
+	if !result.Checked {
+		return result, nil
+	}
+
+	result.Confirmed = awaitEcho(func() (string, error) { return c.CapturePane(pane, echoLines) }, probe, before, echoWait)
+	return result, nil
+}
+
+// sendText types text into pane like SendKeys, first taking it out of any
+// tmux mode, which is an error when the pane stays in it.
+func (s *Server) sendText(ctx context.Context, pane tmux.Pane, text string, enter bool) error {
+	result, err := s.sendChecked(ctx, pane, text, enter, tmux.InputAuto, false)
+	if err == nil && result.Error != "" {
+		err = errors.New(result.Error)
+	}
+	return err
+}
+
+// errUnconfirmed fails a prompt houston sent itself whose text didn't show
+// up on the pane.
+var errUnconfirmed = errors.New("sent, but the text didn't show up on the pane")
+
+// sendPrompt types text and Enter into pane for houston itself: queued,
+// scheduled and compaction prompts. Nobody watches these go in, so a pane
+// that stays in a tmux mode, or text that doesn't show up, is an error
+// like a failed send, and the caller keeps the prompt to try again.
+func (s *Server) sendPrompt(ctx context.Context, pane tmux.Pane, text string) error {
+	result, err := s.sendChecked(ctx, pane, text, true, tmux.InputAuto, true)
+	switch {
+	case err != nil:
+		return err
+	case result.Error != "":
+		return errors.New(result.Error)
+	case result.Checked && !result.Confirmed:
+		return errUnconfirmed
+	}
+	return nil
+}
+
+// awaitEcho captures a pane until probe is seen more often than the before
+// sightings, or wait has passed.
+func awaitEcho(capture func() (string, error), probe string, before int, wait time.Duration) bool {
+	deadline := time.Now().Add(wait)
+	for {
+		if out, err := capture(); err == nil && echoCount(out, probe) > before {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(echoPoll)
+	}
+}
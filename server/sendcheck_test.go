@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestEchoProbe(t *testing.T) {
+	tests := map[string]string{
+		"run the tests":                          "runthetests",
+		"fix this:\n  it fails  \n\n":            "itfails",
+		strings.Repeat("abcd", 20):               strings.Repeat("abcd", 8),
+		"   \n\t":                                "",
+		"naïve café, " + strings.Repeat("é", 31): "," + strings.Repeat("é", 31),
+	}
+	for text, want := range tests {
+		if got := echoProbe(text); got != want {
+			t.Errorf("echoProbe(%.30q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestEchoCount(t *testing.T) {
+	// Wrapped across lines, with colors
+	capture := "$ echo run the \x1b[1mte\x1b[0m\nsts\n> [Pasted text #1 +40 lines]\n"
+	if got := echoCount(capture, echoProbe("echo run the tests")); got != 2 {
+		t.Errorf("echoCount = %d, want the echo and the collapsed paste", got)
+	}
+}
+
+func TestAwaitEcho(t *testing.T) {
+	captures := []string{"$ ", "$ ", "$ make"}
+	capture := func() (string, error) {
+		out := captures[0]
+		if len(captures) > 1 {
+			captures = captures[1:]
+		}
+		return out, nil
+	}
+	if !awaitEcho(capture, "make", 0, time.Second) {
+		t.Error("echo not seen")
+	}
+	// Already on screen before the send: a new sighting is needed
+	if awaitEcho(func() (string, error) { return "$ make\n", nil }, "make", 1, 0) {
+		t.Error("old input taken for the echo")
+	}
+}
+
+func TestSendLeavesModeAndReportsUnseenInput(t *testing.T) {
+	privateTmux(t)
+	// A pane that reads without echoing, like a password prompt
+	runTmux(t, "new-session", "-d", "-s", "work", "-x", "80", "-y", "24", "stty -echo; cat >/dev/null")
+	s := &Server{tmux: tmux.NewClient()}
+	pane := tmux.Pane{Session: "work"}
+	ctx := context.Background()
+
+	runTmux(t, "copy-mode", "-t", "work")
+	result, err := s.sendKey(ctx, pane, "Enter")
+	if err != nil || result.ExitedMode != "copy-mode" || result.Error != "" {
+		t.Fatalf("sendKey in copy-mode = %+v, %v", result, err)
+	}
+	if mode, _ := s.tmux.PaneMode(pane); mode != "" {
+		t.Errorf("pane still in %s after sendKey", mode)
+	}
+
+	result, err = s.sendChecked(ctx, pane, "hunter2", true, tmux.InputType, true)
+	if err != nil || result.Error != "" || !result.Checked || result.Confirmed {
+		t.Errorf("sendChecked(unechoed) = %+v, %v; want sent and unconfirmed", result, err)
+	}
+}
+
+func TestSendPrompt(t *testing.T) {
+	privateTmux(t)
+	runTmux(t, "new-session", "-d", "-s", "work", "-x", "80", "-y", "24", "cat")
+	runTmux(t, "new-session", "-d", "-s", "quiet", "-x", "80", "-y", "24", "stty -echo; cat >/dev/null")
+	s := &Server{tmux: tmux.NewClient()}
+	ctx := context.Background()
+
+	// In copy-mode the prompt would drive copy-mode; it's taken out first
+	runTmux(t, "copy-mode", "-t", "work")
+	if err := s.sendPrompt(ctx, tmux.Pane{Session: "work"}, "run the tests"); err != nil {
+		t.Errorf("sendPrompt(copy-mode) = %v", err)
+	}
+	if out, _ := s.tmux.CapturePane(tmux.Pane{Session: "work"}, 10); !strings.Contains(out, "run the tests") {
+		t.Errorf("prompt not on the pane:\n%s", out)
+	}
+
+	if err := s.sendPrompt(ctx, tmux.Pane{Session: "quiet"}, "run the tests"); err != errUnconfirmed {
+		t.Errorf("sendPrompt(unechoed) = %v, want %v", err, errUnconfirmed)
+	}
+}
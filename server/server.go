@@ -2,16 +2,16 @@
 package server
 
 import (
+	"cmp"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -21,21 +21,42 @@ import (
 	"github.com/noamsto/houston/agents/amp"
 	"github.com/noamsto/houston/agents/claude"
 	"github.com/noamsto/houston/agents/generic"
+	"github.com/noamsto/houston/github"
+	"github.com/noamsto/houston/history"
 	"github.com/noamsto/houston/internal/ansi"
+	"github.com/noamsto/houston/notify"
 	"github.com/noamsto/houston/opencode"
 	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/project"
+	"github.com/noamsto/houston/schedule"
 	"github.com/noamsto/houston/status"
+	"github.com/noamsto/houston/store"
 	"github.com/noamsto/houston/tmux"
+	"github.com/noamsto/houston/update"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
 )
 
-// getAgentState gets state from the detected agent.
+// getAgentState gets state from the detected agent. Choices the agent can't
+// verify as its own selector are moved to RawChoices.
+func getAgentState(ctx context.Context, agent agents.Agent, panePath, terminalOutput string) parser.Result {
+	return verifyChoices(agent, agentState(ctx, agent, panePath, terminalOutput), terminalOutput)
+}
+
+// agentState reads the agent's state without verifying choices.
 // For Amp: prefer terminal parsing (real-time status) over file-based state.
 // For Claude: prefer file-based state, with terminal fallback for choices.
-func getAgentState(agent agents.Agent, panePath, terminalOutput string) parser.Result {
+func agentState(ctx context.Context, agent agents.Agent, panePath, terminalOutput string) parser.Result {
 	if agent == nil {
 		return parser.Result{Type: parser.TypeIdle}
 	}
 
+	// Generic panes have no files; their output may show a build or test run
+	if agent.Type() == agents.AgentGeneric {
+		return agent.ParseOutput(terminalOutput).Result
+	}
+
 	// For Amp, always use terminal parsing as it shows real-time status
 	// (thread files only update when messages complete, not during streaming)
 	if agent.Type() == agents.AgentAmp {
@@ -44,7 +65,9 @@ func getAgentState(agent agents.Agent, panePath, terminalOutput string) parser.R
 
 	// For Claude, try file-based state first for richer info
 	if panePath != "" {
+		_, span := tracer.Start(ctx, "agent.state_files", trace.WithAttributes(attribute.String("houston.agent", string(agent.Type()))))
 		state, err := agent.GetStateFromFiles(panePath)
+		span.End()
 		if err == nil {
 			// Check if waiting for permission and use terminal for choices
 			if agent.Type() == agents.AgentClaudeCode {
@@ -65,60 +88,422 @@ func getAgentState(agent agents.Agent, panePath, terminalOutput string) parser.R
 	return agent.ParseOutput(terminalOutput).Result
 }
 
-// recentActivityTTL is how long a session stays in "Active" after becoming idle
-const recentActivityTTL = 2 * time.Minute
+// stateFromFiles reports whether agentState reads agent's state in
+// panePath from its files (Claude's transcript) rather than its output.
+func stateFromFiles(agent agents.Agent, panePath string) bool {
+	return panePath != "" && agent.Type() != agents.AgentGeneric && agent.Type() != agents.AgentAmp
+}
+
+// agentStatePath returns the path used for file-based agent state. Agent
+// files live on the machine running the agent, so remote panes fall back to
+// terminal parsing.
+func agentStatePath(host, panePath string) string {
+	if host != "" {
+		return ""
+	}
+	return panePath
+}
+
+// Default poll intervals for the sessions stream and pane sockets.
+const (
+	defaultSessionsInterval = 3 * time.Second
+	defaultPaneInterval     = 200 * time.Millisecond
+)
 
 type Server struct {
-	tmux     *tmux.Client
+	tmux     *tmux.Client            // Local tmux server
+	remotes  map[string]*tmux.Client // Remote tmux servers by host spec
+	hosts    []string                // Remote host specs in configured order
 	watcher  *status.Watcher
+	tailer   *claude.Tailer // Claude session state, kept current from file writes
 	registry *agents.Registry
-	font     FontController
-	uiFS     fs.FS // embedded React SPA
+	terminal TerminalController
+	raiser   Raiser // Brings the local terminal to the front on focus (nil: unsupported)
+	uiFS     fs.FS  // embedded React SPA
+	basePath string // URL prefix behind a reverse proxy ("/houston"), "" at the root
+	version  string
+	store    *store.Store
+
+	// tmux-resurrect save file (empty: plugin default locations)
+	resurrectFile string
+	resurrect     *tmux.ResurrectState
+	resurrectMod  time.Time
+	resurrectMu   sync.Mutex
+
+	// Theme aliases ("dark", "light") for /api/terminal/theme
+	terminalThemes map[string]string
+
+	// Attention response latency, agent state changes and per-minute
+	// session activity, persisted in the store
+	responses   *history.ResponseTracker
+	transitions *history.TransitionTracker
+	activity    *history.ActivityRecorder
+
+	// Background release check (nil unless enabled)
+	updates *update.Checker
+
+	// Attention notifications and reminders for unanswered prompts
+	notifier  *notify.Notifier
+	reminders []time.Duration
+	notified  *notify.Tracker
+	slack     *notify.Slack // Also in notifier; answers its choice buttons
+
+	// When the daily report is sent (nil: never)
+	reportSchedule *schedule.Spec
+
+	// Saved dashboard views (name -> view), persisted in the store
+	views   map[string]View
+	viewsMu sync.RWMutex
+
+	// Prompt templates (name -> snippet), persisted in the store
+	snippets   map[string]Snippet
+	snippetsMu sync.RWMutex
+
+	// Dashboard preferences (client ID -> prefs), persisted in the store
+	prefs   map[string]Prefs
+	prefsMu sync.RWMutex
+
+	// Keystroke macros (name -> macro), persisted in the store
+	macros   map[string]Macro
+	macrosMu sync.RWMutex
+
+	// Pinned and hidden sessions, persisted in the store
+	marks *sessionMarks
+
+	// Window labels, persisted in the store
+	tags *windowTags
+
+	// Prompts waiting for their agent to finish, persisted in the store
+	queues *promptQueues
+
+	// Outstanding commit/push confirmation tokens
+	gitConfirms *gitConfirmations
+
+	// Panes recorded to asciicast files, persisted in the store
+	recordings *recordings
+
+	// Images received by send-with-images, removed when they expire
+	uploads *uploads
+
+	// Size and type limits of files uploaded into a pane's directory
+	fileUploads fileUploadPolicy
+
+	// Request rate and tmux concurrency limits
+	limits *limits
+
+	// Auto-approve policies for permission prompts, persisted in the store
+	policies *policyEngine
+
+	// Prompts sent on a cron schedule, persisted in the store
+	schedules *scheduler
+
+	// Automatic compaction of Claude panes near a full context
+	compact *compactRule
+
+	// MCP server health per Claude pane
+	mcp *mcpTracker
+
+	// Recent pane captures and the agent state found in them
+	captures *captureCache
 
-	// Track when sessions last had activity (for keeping recently-active in Active section)
-	lastActivity   map[string]time.Time // session name -> last working timestamp
-	lastActivityMu sync.RWMutex
+	// Agent state of the panes being viewed, shared by their streams
+	paneService *paneStateService
+
+	// The last full sessions build, for the agent strip
+	sessionsCache *sessionsCache
+
+	// Project metadata per working directory, for session cards
+	projects *project.Cache
+
+	// Open pull requests of window branches (-github-prs), nil when off
+	prs *github.Tracker
+
+	// Recent sessions stream payloads, for Last-Event-ID resume
+	sessionsHistory *sessionsHistory
+
+	// Settled state of each window, which categorization reads
+	states *paneStates
+
+	// Grace periods for working/Active categorization and preview sizes,
+	// with per-session overrides
+	activityWindow        time.Duration
+	activeTTL             time.Duration
+	previewLines          int
+	attentionPreviewLines int
+	timerRules            []TimerRule
+
+	// How often sessions streams rescan and pane sockets capture
+	sessionsInterval time.Duration
+	paneInterval     time.Duration
+
+	// Zero-downtime restarts: primary is closed once this process owns
+	// background work, draining once streams should move to the next one
+	primary   chan struct{}
+	draining  chan struct{}
+	drainOnce sync.Once
+
+	// Background loops (queues, policies, schedules, reminders,
+	// auto-compaction, activity samples, daily report, recordings),
+	// stopped by Close
+	stopWork context.CancelFunc
+	work     sync.WaitGroup
 
 	// OpenCode integration
 	ocDiscovery *opencode.Discovery
 	ocManager   *opencode.Manager
+	ocPanes     map[string]tmux.Pane // openCodeKey -> window running its TUI
+	ocPanesMu   sync.RWMutex
 }
 
-// FontController controls terminal font size.
-type FontController interface {
+// TerminalController controls the appearance of the terminal running tmux.
+// Methods the terminal can't support return an error; Supports lists the
+// features that work ("font", "theme", "opacity", "font-family").
+type TerminalController interface {
 	Increase() error
 	Decrease() error
 	Reset() error
+	SetTheme(theme string) error
+	SetOpacity(opacity float64) error
+	SetFontFamily(family string) error
+	Supports() []string
+	Name() string
+}
+
+// Raiser brings the terminal window running tmux to the front.
+type Raiser interface {
+	Raise() error
 	Name() string
 }
 
 type Config struct {
-	StatusDir      string
-	FontController FontController
+	StatusDir     string
+	DataDir       string   // Persistent state (views, ...)
+	Remotes       []string // ssh destinations whose tmux sessions are shown alongside local ones
+	ResurrectFile string   // tmux-resurrect save file (default: auto-detect)
+	Version       string   // Reported by /api/meta
+	Terminal      TerminalController
+	Raiser        Raiser // Optional: raise the terminal when a pane is focused
+
+	// Theme aliases for /api/terminal/theme ("light" -> a theme name)
+	TerminalThemes map[string]string
+
+	// Attention notifications: providers (nil: none) and reminder intervals
+	Notifier  *notify.Notifier
+	Reminders []time.Duration
+	Slack     *notify.Slack // Slack provider whose choice buttons to answer (nil: none)
+
+	// When to send the daily report through Notifier (nil: never)
+	DailyReport *schedule.Spec
+
+	// Grace periods (zero: 30s and 2m), overridable per session pattern
+	ActivityWindow time.Duration // Recent output that keeps a non-agent process working
+	ActiveTTL      time.Duration // How long a session stays Active after its work stops
+	TimerRules     []TimerRule
+
+	// Output lines in window previews (zero: 15, and 25 while the window
+	// needs attention), overridable per session pattern
+	PreviewLines          int
+	AttentionPreviewLines int
+
+	// MCP server name globs whose disconnect needs attention ("*": all)
+	MCPRequired []string
+
+	// Context percent at which idle Claude panes are sent AutoCompactCommand
+	// (0: off; empty command: /compact)
+	AutoCompact        int
+	AutoCompactCommand string
+
+	// Poll intervals (zero: 3s and 200ms)
+	SessionsInterval time.Duration // Sessions stream rescan
+	PaneInterval     time.Duration // Pane WebSocket capture
+
+	// Agents not to detect; their panes fall back to generic
+	DisabledAgents []agents.AgentType
+
+	// Another houston still serves this address (-reuse-port): stand by
+	// until it hands over its state on shutdown
+	Standby bool
+
+	// Release checking (opt-in)
+	UpdateCheck   bool           // Periodically check GitHub for a newer release
+	UpdateChannel update.Channel // stable or prerelease
+
+	// Show the open pull request of each window's branch (opt-in, needs gh)
+	GitHubPRs bool
+
+	// Where images sent with send-with-images are kept, and for how long
+	// (empty: <DataDir>/uploads; zero: 24h)
+	UploadDir string
+	UploadTTL time.Duration
+
+	// Limits of /api/pane/{target}/upload: total bytes per request (zero:
+	// uploads disabled) and allowed extensions (empty: any)
+	FileUploadMax  int64
+	FileUploadExts []string
+
+	// Mutating API requests per second per client, and tmux-heavy requests
+	// (sessions, search, pane captures) at once; zero is unlimited
+	RateLimit       float64
+	TmuxConcurrency int
 
 	// OpenCode configuration
 	OpenCodeEnabled bool   // Enable OpenCode integration
 	OpenCodeURL     string // Static URL (if set, skip discovery)
 	OpenCodePorts   []int  // Ports to scan (default: 4096-4100)
+	OpenCodeMDNS    bool   // Also browse mDNS for servers
+	OpenCodeBeacon  string // UDP address for server beacons (empty: off)
 
 	// UIFS is the embedded React SPA filesystem.
 	UIFS fs.FS
+
+	// BasePath is the URL prefix a reverse proxy serves houston under
+	// ("/houston"); empty for the root.
+	BasePath string
 }
 
 func New(cfg Config) (*Server, error) {
-	registry := agents.NewRegistry(
-		claude.New(),
-		amp.New(),
+	tailer := claude.NewTailer()
+	var detectors []agents.Agent
+	for _, a := range []agents.Agent{claude.New(claude.WithTailer(tailer)), amp.New()} {
+		if !slices.Contains(cfg.DisabledAgents, a.Type()) {
+			detectors = append(detectors, a)
+		}
+	}
+	registry := agents.NewRegistry(append(detectors,
 		generic.New(), // Must be last (fallback)
-	)
+	)...)
+
+	st, err := store.Open(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	up, err := newUploads(cmp.Or(cfg.UploadDir, filepath.Join(st.Dir(), uploadsDir)), cmp.Or(cfg.UploadTTL, defaultUploadTTL))
+	if err != nil {
+		return nil, err
+	}
 
 	s := &Server{
-		tmux:         tmux.NewClient(),
-		watcher:      status.NewWatcher(cfg.StatusDir),
-		registry:     registry,
-		font:         cfg.FontController,
-		uiFS:         cfg.UIFS,
-		lastActivity: make(map[string]time.Time),
+		tmux:          tmux.NewClient(),
+		watcher:       status.NewWatcher(cfg.StatusDir),
+		tailer:        tailer,
+		registry:      registry,
+		terminal:      cfg.Terminal,
+		raiser:        cfg.Raiser,
+		uiFS:          cfg.UIFS,
+		basePath:      CleanBasePath(cfg.BasePath),
+		version:       cfg.Version,
+		store:         st,
+		resurrectFile: cfg.ResurrectFile,
+		responses:     history.NewResponseTracker(st),
+		transitions:   history.NewTransitionTracker(st),
+		activity:      history.NewActivityRecorder(st),
+		remotes:       make(map[string]*tmux.Client),
+		states:        newPaneStates(),
+		marks:         newSessionMarks(),
+		tags:          newWindowTags(),
+
+		terminalThemes: cfg.TerminalThemes,
+
+		activityWindow: cmp.Or(cfg.ActivityWindow, defaultActivityWindow),
+		activeTTL:      cmp.Or(cfg.ActiveTTL, defaultActiveTTL),
+		timerRules:     cfg.TimerRules,
+
+		previewLines:          cmp.Or(cfg.PreviewLines, defaultPreviewLines),
+		attentionPreviewLines: cmp.Or(cfg.AttentionPreviewLines, defaultAttentionPreviewLines),
+
+		sessionsInterval: cmp.Or(cfg.SessionsInterval, defaultSessionsInterval),
+		paneInterval:     cmp.Or(cfg.PaneInterval, defaultPaneInterval),
+
+		sessionsHistory: newSessionsHistory(),
+		queues:          newPromptQueues(),
+		gitConfirms:     newGitConfirmations(),
+		recordings:      newRecordings(),
+		uploads:         up,
+		fileUploads:     newFileUploadPolicy(cfg.FileUploadMax, cfg.FileUploadExts),
+		limits:          newLimits(cfg.RateLimit, cfg.TmuxConcurrency),
+		policies:        newPolicyEngine(),
+		schedules:       newScheduler(),
+		compact:         newCompactRule(cfg.AutoCompact, cfg.AutoCompactCommand),
+		mcp:             newMCPTracker(cfg.MCPRequired),
+		captures:        newCaptureCache(),
+		sessionsCache:   &sessionsCache{},
+		projects:        project.NewCache(),
+		notifier:        cfg.Notifier,
+		slack:           cfg.Slack,
+		reportSchedule:  cfg.DailyReport,
+		reminders:       cfg.Reminders,
+		notified:        notify.NewTracker(),
+		primary:         make(chan struct{}),
+		draining:        make(chan struct{}),
+	}
+	for _, host := range cfg.Remotes {
+		if _, ok := s.remotes[host]; ok || host == "" {
+			continue
+		}
+		s.remotes[host] = tmux.NewRemoteClient(host)
+		s.hosts = append(s.hosts, host)
+		slog.Info("remote tmux host", "host", host)
+	}
+	s.paneService = newPaneStateService(func(ctx context.Context, p tmux.Pane, lines int) (string, error) {
+		return s.client(p.Host).WithContext(ctx).CapturePane(p, lines)
+	}, s.registry, s.paneInterval)
+	s.paneService.captureView = func(ctx context.Context, p tmux.Pane, lines int) (string, tmux.ModeState, error) {
+		c := s.client(p.Host).WithContext(ctx)
+		mode, err := c.PaneModeState(p)
+		if err != nil || !mode.Scrolled() {
+			return "", mode, err
+		}
+		view, err := c.CaptureScrolled(p, lines, mode)
+		return view, mode, err
+	}
+	s.loadViews()
+	s.loadSnippets()
+	s.loadMacros()
+	s.loadPrefs()
+	s.loadAgentOverrides()
+	s.loadSessionMarks()
+	s.loadWindowTags()
+	s.loadRecordings()
+	s.loadPromptQueues()
+	s.loadPolicies()
+	s.loadSchedules()
+	s.loadCompactOptOuts()
+
+	// Pick up where a restarted houston left off
+	if adopted := s.adoptHandoff(); cfg.Standby && !adopted {
+		go s.awaitHandoff(standbyTimeout)
+	} else {
+		close(s.primary)
+	}
+
+	// Everything run in the background stops with Close
+	var work context.Context
+	work, s.stopWork = context.WithCancel(context.Background())
+
+	// Watch hook status files so updates arrive without rescanning the dir
+	if err := s.watcher.Start(work); err != nil {
+		slog.Warn("status watcher unavailable, falling back to directory reads", "dir", cfg.StatusDir, "error", err)
+	}
+
+	for _, run := range []func(context.Context){s.runPromptQueues, s.runPolicies, s.runSchedules, s.runReminders, s.runAutoCompact, s.runActivity, s.runDailyReport, s.runRecordings, s.runUploads} {
+		s.work.Add(1)
+		go func() {
+			defer s.work.Done()
+			run(work)
+		}()
+	}
+
+	if cfg.GitHubPRs {
+		s.prs = github.NewTracker()
+	}
+
+	if cfg.UpdateCheck {
+		s.updates = update.NewChecker(update.BuildVersion(cfg.Version), cfg.UpdateChannel)
+		s.work.Add(1)
+		go func() {
+			defer s.work.Done()
+			s.updates.Run(work, update.DefaultInterval)
+		}()
 	}
 
 	// Initialize OpenCode integration if enabled
@@ -130,12 +515,18 @@ func New(cfg Config) (*Server, error) {
 		if len(cfg.OpenCodePorts) > 0 {
 			opts = append(opts, opencode.WithPorts(cfg.OpenCodePorts))
 		}
+		if cfg.OpenCodeMDNS {
+			opts = append(opts, opencode.WithMDNS())
+		}
+		if cfg.OpenCodeBeacon != "" {
+			opts = append(opts, opencode.WithBeacon(cfg.OpenCodeBeacon))
+		}
 
 		s.ocDiscovery = opencode.NewDiscovery(opts...)
 		s.ocManager = opencode.NewManager(s.ocDiscovery)
 
 		// Do initial scan synchronously
-		ctx := context.Background()
+		ctx := work
 		if cfg.OpenCodeURL != "" {
 			slog.Info("OpenCode scanning", "url", cfg.OpenCodeURL)
 		} else {
@@ -160,40 +551,131 @@ func (s *Server) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	if s.uiFS != nil {
-		mux.Handle("/", SPAHandler(s.uiFS))
+		mux.Handle("/", SPAHandler(s.uiFS, s.basePath))
 	}
 
-	// JSON API routes (always available)
+	// JSON API routes, also served under /api/v1/
+	api := s.newAPIMux()
+	mux.Handle("/api/", corsMiddleware(traceMiddleware(api, withAPIVersion(s.limitMiddleware(s.auditMiddleware(api))))))
+
+	return withBasePath(s.basePath, compressMiddleware(requestIDMiddleware(mux)))
+}
+
+// newAPIMux routes the JSON API. Routes are always registered; /api/meta
+// reports which are usable.
+func (s *Server) newAPIMux() *http.ServeMux {
 	apiMux := http.NewServeMux()
-	apiMux.HandleFunc("/api/sessions", s.handleAPISessions)
-	apiMux.HandleFunc("/api/pane/", s.handleAPIPane)
-	apiMux.HandleFunc("/api/opencode/sessions", s.handleAPIOpenCodeSessions)
-	apiMux.HandleFunc("/api/opencode/session/", s.handleAPIOpenCodeSession)
-	mux.Handle("/api/", corsMiddleware(apiMux))
+	for _, route := range s.apiRoutes() {
+		apiMux.HandleFunc(route.pattern, route.handler)
+	}
+	return apiMux
+}
+
+// apiRoute is a JSON API route. Unavailable routes stay registered (so
+// clients get a well-formed response) but are left out of /api/meta.
+type apiRoute struct {
+	pattern   string
+	handler   http.HandlerFunc
+	available bool
+}
 
-	return mux
+func (s *Server) apiRoutes() []apiRoute {
+	openCode := s.ocManager != nil
+	terminal := s.terminal != nil && len(s.terminal.Supports()) > 0
+	autoCompact := s.compact.enabled()
+	slack := s.slack != nil && s.slack.Interactive()
+	return []apiRoute{
+		{"/api/meta", s.handleAPIMeta, true},
+		{"/api/openapi.json", s.handleAPIOpenAPI, true},
+		{"/api/batch", s.handleAPIBatch, true},
+		{"/api/sessions", s.handleAPISessions, true},
+		{"/api/sessions/", s.handleAPISession, true},
+		{"/api/search", s.handleAPISearch, true},
+		{"/api/strip", s.handleAPIStrip, true},
+		{"/api/worktrees", s.handleAPIWorktrees, true},
+		{"/api/launch", s.handleAPILaunch, true},
+		{"/api/relaunch", s.handleAPIRelaunch, true},
+		{"/api/history/response-times", s.handleAPIResponseTimes, true},
+		{"/api/history/export", s.handleAPIHistoryExport, true},
+		{"/api/pane/", s.handleAPIPane, true},
+		{"/api/hooks/claude", s.handleAPIHookClaude, true},
+		{"/api/broadcast", s.handleAPIBroadcast, true},
+		{"/api/views", s.handleAPIViews, true},
+		{"/api/views/", s.handleAPIView, true},
+		{"/api/snippets", s.handleAPISnippets, true},
+		{"/api/snippets/", s.handleAPISnippet, true},
+		{"/api/prefs", s.handleAPIPrefs, true},
+		{"/api/prefs/", s.handleAPIClientPrefs, true},
+		{"/api/macros", s.handleAPIMacros, true},
+		{"/api/macros/", s.handleAPIMacro, true},
+		{"/api/policies", s.handleAPIPolicies, true},
+		{"/api/policies/", s.handleAPIPolicy, true},
+		{"/api/schedules", s.handleAPISchedules, true},
+		{"/api/reports/daily", s.handleAPIDailyReport, true},
+		{"/api/schedules/", s.handleAPISchedule, true},
+		{"/api/recordings", s.handleAPIRecordings, true},
+		{"/api/recordings/", s.handleAPIRecording, true},
+		{"/api/clipboard", s.handleAPIClipboard, true},
+		{"/api/uploads", s.handleAPIUploads, true},
+		{"/api/uploads/", s.handleAPIUpload, true},
+		{"/api/claude/sessions", s.handleAPIClaudeSessions, true},
+		{"/api/audit", s.handleAPIAudit, true},
+		{"/api/auto-compact/audit", s.handleAPIAutoCompactAudit, autoCompact},
+		{"/api/slack/interactions", s.handleAPISlackInteractions, slack},
+		{"/api/terminal", s.handleAPITerminal, terminal},
+		{"/api/terminal/", s.handleAPITerminalAction, terminal},
+		{"/api/opencode/sessions", s.handleAPIOpenCodeSessions, openCode},
+		{"/api/opencode/session/", s.handleAPIOpenCodeSession, openCode},
+		{"/api/opencode/models", s.handleAPIOpenCodeModels, openCode},
+	}
 }
 
 // SPAHandler serves an embedded filesystem with fallback to index.html for client-side routing.
-func SPAHandler(uiFS fs.FS) http.Handler {
+// index.html names basePath in a <base href> (see serveIndex).
+func SPAHandler(uiFS fs.FS, basePath string) http.Handler {
 	fileServer := http.FileServer(http.FS(uiFS))
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		path := strings.TrimPrefix(r.URL.Path, "/")
-		if path == "" {
-			path = "index.html"
-		}
 
 		// Serve the file if it exists; otherwise fallback to index.html (client-side routing).
-		if _, err := fs.Stat(uiFS, path); err == nil {
-			fileServer.ServeHTTP(w, r)
-			return
+		if path != "" && path != "index.html" {
+			if _, err := fs.Stat(uiFS, path); err == nil {
+				fileServer.ServeHTTP(w, r)
+				return
+			}
 		}
-
-		r.URL.Path = "/"
-		fileServer.ServeHTTP(w, r)
+		serveIndex(w, r, uiFS, basePath)
 	})
 }
 
+// client returns the tmux client for a host ("" is local), or nil if the
+// host isn't configured.
+func (s *Server) client(host string) *tmux.Client {
+	if host == "" {
+		return s.tmux
+	}
+	return s.remotes[host]
+}
+
+// listAllSessions lists sessions, with their windows and panes, on the
+// local and all remote tmux servers, one tmux call per server. An
+// unreachable host is logged and skipped so it can't blank the dashboard.
+func (s *Server) listAllSessions(ctx context.Context) []tmux.SessionTree {
+	sessions, err := s.tmux.WithContext(ctx).ListTree()
+	if err != nil {
+		slog.Warn("list sessions failed", "error", err)
+	}
+	for _, host := range s.hosts {
+		remote, err := s.remotes[host].WithContext(ctx).ListTree()
+		if err != nil {
+			slog.Warn("list remote sessions failed", "host", host, "error", err)
+			continue
+		}
+		sessions = append(sessions, remote...)
+	}
+	return sessions
+}
+
 // paneScore represents the priority score for a pane
 type paneScore struct {
 	info        *tmux.PaneInfo
@@ -206,7 +688,7 @@ type paneScore struct {
 
 // findBestPane selects the best pane to display for a window
 // Priority: Agent attention > Agent working > Agent idle > active > first
-func (s *Server) findBestPane(session string, windowIdx int, panes []tmux.PaneInfo) paneScore {
+func (s *Server) findBestPane(ctx context.Context, c *tmux.Client, session string, windowIdx int, panes []tmux.PaneInfo) paneScore {
 	if len(panes) == 0 {
 		return paneScore{}
 	}
@@ -216,35 +698,51 @@ func (s *Server) findBestPane(session string, windowIdx int, panes []tmux.PaneIn
 	for i := range panes {
 		p := &panes[i]
 
-		pane := tmux.Pane{Session: session, Window: windowIdx, Index: p.Index}
-		paneID := pane.Target()
-		output, err := s.tmux.CapturePane(pane, 100)
-		if err != nil {
-			slog.Warn("capture pane failed", "pane", paneID, "error", err)
-			continue
-		}
+		pane := tmux.Pane{Host: c.Host(), Session: session, Window: windowIdx, Index: p.Index}
+		paneID := pane.Key()
 
-		agent := s.registry.Detect(paneID, p.Command, output)
+		var output string
+		var agent agents.Agent
 		var parseResult parser.Result
-		score := 0
-
-		if agent.Type() != agents.AgentGeneric {
-			parseResult = getAgentState(agent, p.Path, output)
-
-			switch parseResult.Type {
-			case parser.TypeError, parser.TypeChoice, parser.TypeQuestion:
-				score = 100
-			case parser.TypeWorking:
-				score = 50
-			default:
-				score = 30
+		var score int
+		if snap, ok := s.paneService.peek(pane); ok {
+			// A watched pane was just captured and parsed for its viewers
+			output, agent, parseResult = snap.output, snap.agent, snap.result
+			if agent.Type() == agents.AgentGeneric {
+				parseResult = generic.ParseCommand(p.Command, output)
 			}
+			score = paneScoreFor(agent, parseResult, p.Active)
 		} else {
-			parseResult = parser.Result{Type: parser.TypeIdle}
-			if p.Active {
-				score = 10
+			var hash uint64
+			var err error
+			output, hash, err = s.captures.capture(c, pane, 100)
+			if err != nil {
+				slog.Warn("capture pane failed", "pane", paneID, "error", err)
+				continue
+			}
+
+			// Unchanged output needs no detecting and parsing again
+			override, _ := s.registry.Override(paneID)
+			if v, ok := s.captures.verdict(paneID, hash, p.Command, p.Path, override); ok {
+				agent, parseResult, score = v.agent, v.result, v.score
+				// State read from a transcript moves on while the screen stands still
+				if statePath := agentStatePath(c.Host(), p.Path); stateFromFiles(agent, statePath) {
+					parseResult = getAgentState(ctx, agent, statePath, output)
+					score = paneScoreFor(agent, parseResult, p.Active)
+				}
 			} else {
-				score = 1
+				agent = s.registry.Detect(paneID, p.Command, output)
+				if agent.Type() != agents.AgentGeneric {
+					parseResult = getAgentState(ctx, agent, agentStatePath(c.Host(), p.Path), output)
+				} else {
+					// A build or test run in the foreground, or its result
+					parseResult = generic.ParseCommand(p.Command, output)
+				}
+				score = paneScoreFor(agent, parseResult, p.Active)
+				s.captures.keep(paneID, hash, &paneVerdict{
+					command: p.Command, path: p.Path, override: override,
+					agent: agent, result: parseResult, score: score,
+				})
 			}
 		}
 
@@ -263,11 +761,83 @@ func (s *Server) findBestPane(session string, windowIdx int, panes []tmux.PaneIn
 	return best
 }
 
-func (s *Server) buildSessionsData() SessionsData {
-	sessions, err := s.tmux.ListSessions()
-	if err != nil {
-		slog.Warn("list sessions failed", "error", err)
+// paneScoreFor ranks a pane for findBestPane by its agent and state.
+func paneScoreFor(agent agents.Agent, result parser.Result, active bool) int {
+	if agent.Type() != agents.AgentGeneric {
+		switch result.Type {
+		case parser.TypeError, parser.TypeChoice, parser.TypeQuestion:
+			return 100
+		case parser.TypeWorking:
+			return 50
+		default:
+			return 30
+		}
+	}
+	switch {
+	case result.Type == parser.TypeError:
+		return 20
+	case result.Type == parser.TypeWorking:
+		return 15
+	case active:
+		return 10
+	default:
+		return 1
+	}
+}
+
+// sessionsWorkers bounds the windows of a sessions build processed at once;
+// each captures, detects and parses its panes.
+const sessionsWorkers = 8
+
+// windowBuild is a window built by a sessions build worker.
+type windowBuild struct {
+	status WindowWithStatus
+	path   string // The shown pane's directory, for the project card
+	active bool   // Working or cooling down
+	ocKey  string // OpenCode session shown in the window, if any
+}
+
+// sessionWorktrees loads the git worktrees of each repository a session's
+// windows are in, once per repository, for their branches. A window's
+// path finds its repository among those loaded already by being in one of
+// its worktrees; any other path loads its own repository's.
+type sessionWorktrees struct {
+	client *tmux.Client
+	mu     sync.Mutex
+	repos  []map[string]string // Each repository's worktree path -> branch
+	none   map[string]bool     // Paths outside any repository
+}
+
+func (w *sessionWorktrees) get(path string) map[string]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, worktrees := range w.repos {
+		for wt := range worktrees {
+			if path == wt || strings.HasPrefix(path, wt+"/") {
+				return worktrees
+			}
+		}
+	}
+	if w.none[path] {
+		return nil
 	}
+	worktrees, _ := w.client.GetWorktrees(path)
+	if len(worktrees) == 0 {
+		if w.none == nil {
+			w.none = make(map[string]bool)
+		}
+		w.none[path] = true
+		return nil
+	}
+	w.repos = append(w.repos, worktrees)
+	return worktrees
+}
+
+func (s *Server) buildSessionsData(ctx context.Context, q sessionsQuery) SessionsData {
+	ctx, span := tracer.Start(ctx, "sessions.build")
+	defer span.End()
+
+	sessions := s.listAllSessions(ctx)
 	statuses := s.watcher.GetAll()
 	_ = statuses // TODO: integrate hook status per-window
 
@@ -276,100 +846,75 @@ func (s *Server) buildSessionsData() SessionsData {
 		NeedsAttention: []SessionWithWindows{},
 		Active:         []SessionWithWindows{},
 		Idle:           []SessionWithWindows{},
+		Hidden:         []tmux.Session{},
 	}
-
+	ocPanes := make(map[string]tmux.Pane)
+
+	// Windows are built concurrently; each session keeps its windows in
+	// tmux order until they're sorted below, so the result doesn't depend
+	// on which worker finished first
+	type sessionBuild struct {
+		sess    tmux.SessionTree
+		mark    SessionMark
+		windows []windowBuild
+	}
+	var builds []*sessionBuild
+	started := time.Now()
+	var g errgroup.Group
+	g.SetLimit(sessionsWorkers)
 	for _, sess := range sessions {
-		// Get all windows for this session
-		windows, err := s.tmux.ListWindows(sess.Name)
-		if err != nil || len(windows) == 0 {
+		if !q.matchSession(sess.Name) {
 			continue
 		}
-
-		sessionData := SessionWithWindows{
-			Session: sess,
+		mark := s.marks.get(tmux.Pane{Host: sess.Host, Session: sess.Name}.Key())
+		if mark.Hidden {
+			data.Hidden = append(data.Hidden, sess.Session)
+			continue
+		}
+		if len(sess.Windows) == 0 {
+			continue
 		}
 
-		// Get worktrees once per session (using first window's pane path)
-		var worktrees map[string]string
-		var worktreesLoaded bool
-
-		for _, win := range windows {
-			// Get actual panes for this window
-			panes, err := s.tmux.ListPanes(sess.Name, win.Index)
-			if err != nil {
-				slog.Warn("list panes failed", "session", sess.Name, "window", win.Index, "error", err)
-			}
-
-			// Find best pane to display based on priority:
-			// 1. Agent pane needing attention (error/choice/question)
-			// 2. Agent pane that's working
-			// 3. Agent pane that's idle/done
-			// 4. Active pane (non-agent)
-			// 5. First pane
-			bestPane := s.findBestPane(sess.Name, win.Index, panes)
-			activePaneInfo := bestPane.info
-			paneIdx := bestPane.index
-
-			// Load worktrees on first window (lazy load)
-			if !worktreesLoaded && activePaneInfo != nil && activePaneInfo.Path != "" {
-				worktrees, _ = tmux.GetWorktrees(activePaneInfo.Path)
-				worktreesLoaded = true
-			}
-
-			// Get branch for this window's pane
-			var branch string
-			if activePaneInfo != nil {
-				branch = tmux.GetBranchForPath(activePaneInfo.Path, worktrees)
-			}
-			process := win.Name
+		b := &sessionBuild{sess: sess, mark: mark, windows: make([]windowBuild, len(sess.Windows))}
+		builds = append(builds, b)
+		c := s.client(sess.Host).WithContext(ctx)
+		timers := s.timersFor(sess.Name)
+		worktrees := &sessionWorktrees{client: c}
+		for i, win := range sess.Windows {
+			g.Go(func() error {
+				b.windows[i] = s.buildWindow(ctx, c, sess.Session, win, timers, worktrees)
+				return nil
+			})
+		}
+	}
+	_ = g.Wait() // Windows don't fail to build
+	span.SetAttributes(attribute.Int("houston.sessions", len(builds)))
 
-			pane := tmux.Pane{Session: sess.Name, Window: win.Index, Index: paneIdx}
+	for _, b := range builds {
+		sessionData := SessionWithWindows{
+			Session: b.sess.Session,
+			Pinned:  b.mark.Pinned,
+		}
+		var paths []string     // Each window's pane path, for the project card
+		var sessionActive bool // A window is working or cooling down
 
-			// Use cached values from findBestPane instead of re-capturing
-			output := bestPane.output
-			agent := bestPane.agent
-			if agent == nil {
-				agent = s.registry.Detect(pane.Target(), "", "")
+		for _, win := range b.windows {
+			sessionData.Windows = append(sessionData.Windows, win.status)
+			if win.path != "" {
+				paths = append(paths, win.path)
 			}
-			parseResult := bestPane.parseResult
-
-			// Only mark as needing attention if it's an agent window
-			isAgentWindow := agent.Type() != agents.AgentGeneric
-			windowNeedsAttention := isAgentWindow && (parseResult.Type == parser.TypeError ||
-				parseResult.Type == parser.TypeChoice ||
-				parseResult.Type == parser.TypeQuestion)
-
-			// Extract preview lines - more for attention states
-			previewLines := 15
-			if windowNeedsAttention {
-				previewLines = 25
+			if win.ocKey != "" {
+				ocPanes[win.ocKey] = win.status.Pane
 			}
-			preview := s.getPreviewLines(agent, output, previewLines)
-
-			windowStatus := WindowWithStatus{
-				Window:         win,
-				Pane:           pane,
-				ParseResult:    parseResult,
-				Preview:        preview,
-				NeedsAttention: windowNeedsAttention,
-				Branch:         branch,
-				Process:        process,
-				AgentType:      agent.Type(),
-			}
-
-			sessionData.Windows = append(sessionData.Windows, windowStatus)
-
-			if windowNeedsAttention {
+			if win.status.NeedsAttention {
 				sessionData.AttentionCount++
 			}
-			// Check if window is actively working using smarter heuristics
-			cmd := ""
-			if activePaneInfo != nil {
-				cmd = activePaneInfo.Command
-			}
-			if isWindowActive(cmd, win.LastActivity, isAgentWindow, parseResult) {
+			if win.status.State.State == PaneWorking {
 				sessionData.HasWorking = true
 			}
+			if win.active {
+				sessionActive = true
+			}
 		}
 
 		// Sort windows by activity: attention first, then working, then idle
@@ -381,121 +926,202 @@ func (s *Server) buildSessionsData() SessionsData {
 			return scoreI > scoreJ
 		})
 
-		// Update last activity tracking
-		if sessionData.HasWorking {
-			s.lastActivityMu.Lock()
-			s.lastActivity[sess.Name] = time.Now()
-			s.lastActivityMu.Unlock()
+		// Project files are only readable for local sessions
+		if b.sess.Host == "" {
+			sessionData.Project = s.projects.Lookup(primaryPath(paths))
 		}
 
-		// Check if session has recent activity (within TTL)
-		s.lastActivityMu.RLock()
-		lastActive, hasLastActive := s.lastActivity[sess.Name]
-		s.lastActivityMu.RUnlock()
-		recentlyActive := hasLastActive && time.Since(lastActive) < recentActivityTTL
-
-		// Categorize session based on its windows' actual status
-		if sessionData.AttentionCount > 0 {
+		// Categorize session based on its windows' settled states, then
+		// leave out the windows the query doesn't ask for
+		attention := sessionData.AttentionCount > 0
+		if !q.filter(&sessionData) {
+			continue
+		}
+		if attention {
 			data.NeedsAttention = append(data.NeedsAttention, sessionData)
-		} else if sessionData.HasWorking || recentlyActive {
-			// Keep in Active if currently working OR recently active
+		} else if sessionActive || b.mark.Pinned {
+			// Keep in Active while a window works or cools down
 			data.Active = append(data.Active, sessionData)
 		} else {
 			data.Idle = append(data.Idle, sessionData)
 		}
 	}
 
-	return data
-}
-
-// buildAgentStripItems returns strip items for all agent windows across all sessions,
-// for the desktop pane page navigation strip.
-func (s *Server) buildAgentStripItems(activeSession string, activeWindow, activePane int) []AgentStripItem {
-	sessions, err := s.tmux.ListSessions()
-	if err != nil {
-		slog.Warn("list sessions failed", "error", err)
+	for _, section := range [][]SessionWithWindows{data.NeedsAttention, data.Active, data.Idle} {
+		sortPinnedFirst(section)
 	}
-	var items []AgentStripItem
 
-	for _, sess := range sessions {
-		windows, err := s.tmux.ListWindows(sess.Name)
-		if err != nil || len(windows) == 0 {
-			continue
+	s.ocPanesMu.Lock()
+	s.ocPanes = ocPanes
+	s.ocPanesMu.Unlock()
+	s.states.prune(time.Now())
+	if q.unfiltered() {
+		s.sessionsCache.store(data)
+		// Every window was observed: prompts and pinned agents of the
+		// others are gone
+		if s.isPrimary() {
+			s.responses.Prune(started)
+			s.pruneAgentOverrides(sessions)
 		}
+	}
 
-		// Load worktrees once per session
-		var worktrees map[string]string
-		var worktreesLoaded bool
-
-		for _, win := range windows {
-			panes, err := s.tmux.ListPanes(sess.Name, win.Index)
-			if err != nil {
-				slog.Warn("list panes failed", "session", sess.Name, "window", win.Index, "error", err)
-			}
-			if len(panes) == 0 {
-				continue
-			}
-
-			bestPane := s.findBestPane(sess.Name, win.Index, panes)
-			activePaneInfo := bestPane.info
-			paneIdx := bestPane.index
+	return data
+}
 
-			if !worktreesLoaded && activePaneInfo != nil && activePaneInfo.Path != "" {
-				worktrees, _ = tmux.GetWorktrees(activePaneInfo.Path)
-				worktreesLoaded = true
-			}
+// buildWindow captures and parses a window's panes for the sessions list,
+// and feeds what it shows to the state machine and history. It runs on a
+// sessions build worker.
+func (s *Server) buildWindow(ctx context.Context, c *tmux.Client, sess tmux.Session, win tmux.WindowTree, timers EffectiveTimers, worktrees *sessionWorktrees) windowBuild {
+	ctx, span := tracer.Start(ctx, "sessions.window", trace.WithAttributes(
+		attribute.String("houston.session", sess.Name),
+		attribute.Int("houston.window", win.Index),
+	))
+	defer span.End()
+	c = c.WithContext(ctx)
+
+	// Find best pane to display based on priority:
+	// 1. Agent pane needing attention (error/choice/question)
+	// 2. Agent pane that's working
+	// 3. Agent pane that's idle/done
+	// 4. Active pane (non-agent)
+	// 5. First pane
+	bestPane := s.findBestPane(ctx, c, sess.Name, win.Index, win.Panes)
+	activePaneInfo := bestPane.info
+	paneIdx := bestPane.index
+
+	// Get branch for this window's pane
+	var build windowBuild
+	var branch string
+	if activePaneInfo != nil {
+		if activePaneInfo.Path != "" {
+			branch = c.GetBranchForPath(activePaneInfo.Path, worktrees.get(activePaneInfo.Path))
+		}
+		build.path = activePaneInfo.Path
+	}
+	process := win.Name
 
-			// Use cached agent from findBestPane; skip non-agent windows
-			agent := bestPane.agent
-			if agent == nil {
-				continue
-			}
-			if agent.Type() == agents.AgentGeneric {
-				continue
-			}
+	pane := tmux.Pane{Host: sess.Host, Session: sess.Name, Window: win.Index, Index: paneIdx}
 
-			parseResult := bestPane.parseResult
+	// Use cached values from findBestPane instead of re-capturing
+	output := bestPane.output
+	agent := bestPane.agent
+	if agent == nil {
+		agent = s.registry.Detect(pane.Key(), "", "")
+	}
+	parseResult := bestPane.parseResult
+
+	// A required MCP server that dropped also needs the user
+	var mcpDown []string
+	var subagents []claude.Subagent
+	var claudeStatus *claude.ClaudeStatus
+	if agent.Type() == agents.AgentClaudeCode {
+		mcpDown = s.mcp.observe(pane.Key(), claude.ParseMCPStatus(output)).Down
+		if status := claude.ParseStatus(claude.ExtractStatusLine(output)); status.Found() {
+			claudeStatus = &status
+		}
+		// Subagent transcripts are local files
+		if pane.Host == "" && activePaneInfo != nil {
+			_, span := tracer.Start(ctx, "claude.subagents")
+			subagents, _ = claude.Subagents(activePaneInfo.Path)
+			span.End()
+		}
+	} else {
+		s.mcp.forget(pane.Key())
+	}
 
-			var branch string
-			if activePaneInfo != nil {
-				branch = tmux.GetBranchForPath(activePaneInfo.Path, worktrees)
-			}
+	// An OpenCode TUI takes its state from the OpenCode API
+	agentType := agent.Type()
+	ocLink := s.openCodeLink(pane, activePaneInfo)
+	if ocLink != nil {
+		agentType = agents.AgentOpenCode
+		build.ocKey = openCodeKey(ocLink.Server, ocLink.SessionID)
+	}
 
-			indicator := "idle"
-			switch parseResult.Type {
-			case parser.TypeError, parser.TypeChoice, parser.TypeQuestion:
-				indicator = "attention"
-			case parser.TypeWorking:
-				indicator = "working"
-			case parser.TypeDone:
-				indicator = "done"
-			}
+	// Agent windows need attention when the agent waits on the user;
+	// other windows when a build or test run failed
+	isAgentWindow := agentType != agents.AgentGeneric
+	promptAttention := parseResult.Type == parser.TypeError ||
+		parseResult.Type == parser.TypeChoice ||
+		parseResult.Type == parser.TypeQuestion ||
+		(ocLink != nil && ocLink.Status == "error")
+	windowNeedsAttention := isAgentWindow && (promptAttention || len(mcpDown) > 0) ||
+		!isAgentWindow && parseResult.Type == parser.TypeError
+
+	// A standby process leaves history to the houston it replaces
+	recording := s.isPrimary()
+	if recording {
+		kind := parseResult.Type.String()
+		if !promptAttention && len(mcpDown) > 0 {
+			kind = "mcp"
+		}
+		s.responses.Observe(windowKey(pane), sess.Name, kind, windowNeedsAttention, time.Now())
+	}
 
-			var paneCommand string
-			if activePaneInfo != nil {
-				paneCommand = activePaneInfo.Command
-			}
+	// Extract preview lines - more for attention states
+	preview := s.getPreviewLines(agent, output, timers.previewLines(windowNeedsAttention))
 
-			displayName := branch
-			if displayName == "" {
-				displayName = paneCommand
-			}
-			if displayName == "" {
-				displayName = win.Name
-			}
+	// Check if window is actively working using smarter heuristics,
+	// then settle it through the window's state machine
+	cmd := ""
+	if activePaneInfo != nil {
+		cmd = activePaneInfo.Command
+	}
+	windowActive := isWindowActive(cmd, win.LastActivity, timers.activityWindow(), isAgentWindow, parseResult) ||
+		(ocLink != nil && ocLink.Status == "busy")
+	state := s.states.observe(windowKey(pane), windowNeedsAttention, windowActive,
+		parseResult.Type == parser.TypeDone, timers.activeTTL(), time.Now())
+
+	windowStatus := WindowWithStatus{
+		Window:         win.Window,
+		Pane:           pane,
+		ParseResult:    parseResult,
+		Preview:        preview,
+		NeedsAttention: windowNeedsAttention,
+		Branch:         branch,
+		Process:        process,
+		AgentType:      agentType,
+		Timers:         timers,
+		MCPDown:        mcpDown,
+		OpenCode:       ocLink,
+		Subagents:      subagents,
+		ClaudeStatus:   claudeStatus,
+		State:          state,
+	}
+	windowStatus.Window.Branch = branch // ListTree leaves it to us
+	windowStatus.Todos = s.paneTodos(agent, pane, activePaneInfo, ocLink)
+	windowStatus.Tags = s.tags.get(windowKey(pane))
+	_, windowStatus.AgentManual = s.registry.Override(pane.Key())
+	windowStatus.Queued = s.queues.countWindow(windowKey(pane))
+	// gh runs locally, so remote windows have no PR status
+	if s.prs != nil && pane.Host == "" && activePaneInfo != nil {
+		windowStatus.PR = s.prs.PR(activePaneInfo.Path, branch)
+	}
+	if !isAgentWindow {
+		windowStatus.Relaunch = s.relaunchCandidate(sess, win.Index, win.Panes)
+	}
+	if windowNeedsAttention {
+		if since, ok := s.responses.Since(windowKey(pane)); ok {
+			waiting := time.Since(since)
+			windowStatus.AttentionSince = &since
+			windowStatus.WaitingMinutes = int(waiting.Minutes())
+			windowStatus.Reminder = notify.Level(s.reminders, waiting)
+		}
+	}
 
-			items = append(items, AgentStripItem{
-				Session:   sess.Name,
-				Window:    win.Index,
-				Pane:      paneIdx,
-				Name:      displayName,
-				Indicator: indicator,
-				AgentType: agent.Type(),
-				Active:    sess.Name == activeSession && win.Index == activeWindow && paneIdx == activePane,
-			})
+	if isAgentWindow && recording {
+		histState := history.StateIdle
+		switch state.State {
+		case PaneAttention:
+			histState = history.StateAttention
+		case PaneWorking:
+			histState = history.StateWorking
 		}
+		s.transitions.Observe(windowKey(pane), sess.Name, branch, string(agentType), histState, time.Now())
 	}
-	return items
+
+	build.status = windowStatus
+	build.active = state.active()
+	return build
 }
 
 // getPreviewLines extracts the last n non-empty lines from output, using agent-specific filtering
@@ -520,8 +1146,9 @@ func (s *Server) getPreviewLines(agent agents.Agent, output string, n int) []str
 		if isAllSeparator(line) {
 			continue
 		}
-		// Strip ANSI codes for window card preview (ESC gets lost in HTML anyway)
-		line = ansi.StripOrphaned(line)
+		// Reduce to plain text for window card preview: ESC gets lost in HTML
+		// anyway, and hyperlinks or titles must not reach the card
+		line = ansi.Sanitize(line)
 		result = append([]string{line}, result...)
 	}
 
@@ -534,8 +1161,8 @@ func windowActivityScore(win WindowWithStatus) int {
 	if win.NeedsAttention {
 		return 4 // Highest priority - needs user attention
 	}
-	if win.ParseResult.Type == parser.TypeWorking {
-		return 3 // Claude actively working
+	if win.State.State == PaneWorking {
+		return 3 // Agent or build working
 	}
 	// Check process type for non-Claude windows
 	procType := classifyProcess(win.Process)
@@ -544,7 +1171,7 @@ func windowActivityScore(win WindowWithStatus) int {
 		return 2 // Servers running
 	case ProcessUnknown:
 		// Unknown process with recent activity
-		if time.Since(win.Window.LastActivity) < 30*time.Second {
+		if time.Since(win.Window.LastActivity) < win.Timers.activityWindow() {
 			return 2 // Recent activity
 		}
 		return 1
@@ -609,14 +1236,19 @@ func classifyProcess(cmd string) ProcessType {
 
 // isWindowActive determines if a window is actively working based on:
 // - Process type (shells/interactive are idle)
-// - Recent activity (output in last N seconds)
+// - Recent activity (output within activityWindow)
 // - For agent windows, use the parser
-func isWindowActive(cmd string, lastActivity time.Time, isAgentWindow bool, parseResult parser.Result) bool {
+func isWindowActive(cmd string, lastActivity time.Time, activityWindow time.Duration, isAgentWindow bool, parseResult parser.Result) bool {
 	// Agent windows use their own detection
 	if isAgentWindow {
 		return parseResult.Type == parser.TypeWorking
 	}
 
+	// A build or test tool in the foreground
+	if parseResult.Type == parser.TypeWorking {
+		return true
+	}
+
 	procType := classifyProcess(cmd)
 
 	switch procType {
@@ -631,8 +1263,8 @@ func isWindowActive(cmd string, lastActivity time.Time, isAgentWindow bool, pars
 		return true
 	default:
 		// Unknown process - check for recent activity
-		// If there was output in the last 30 seconds, consider it active
-		return time.Since(lastActivity) < 30*time.Second
+		// If there was output recently, consider it active
+		return time.Since(lastActivity) < activityWindow
 	}
 }
 
@@ -647,16 +1279,22 @@ func isAllSeparator(line string) bool {
 	return len(line) > 3 // Must be at least a few chars to be a separator
 }
 
+// paneActions are the /api/pane/{target}/{action} suffixes.
+var paneActions = map[string]bool{
+	"ws": true, "events": true, "send": true, "send-with-images": true, "send-with-image": true, "send-template": true,
+	"macro": true, "choose": true, "kill": true, "respawn": true, "kill-window": true, "zoom": true, "resize": true,
+	"transcript": true, "handoff": true, "resume": true, "agent": true, "history": true, "todos": true, "diff": true,
+	"commit": true, "push": true, "recording": true, "screenshot.png": true, "paste": true, "upload": true,
+	"tags": true, "queue": true, "focus": true, "auto-compact": true,
+	"interrupt": true, "escape": true, "clear": true, "copy-mode": true, "scroll": true,
+}
+
 func parsePaneTarget(path string) (tmux.Pane, error) {
 	path = strings.TrimPrefix(path, "/pane/")
 
 	// Strip known action suffixes from the end
-	if lastSlash := strings.LastIndex(path, "/"); lastSlash >= 0 {
-		suffix := path[lastSlash+1:]
-		switch suffix {
-		case "ws", "send", "send-with-images", "send-with-image", "kill", "respawn", "kill-window", "zoom", "resize":
-			path = path[:lastSlash]
-		}
+	if lastSlash := strings.LastIndex(path, "/"); lastSlash >= 0 && paneActions[path[lastSlash+1:]] {
+		path = path[:lastSlash]
 	}
 
 	// URL-decode the path (handles %2F -> / in session names)
@@ -665,13 +1303,18 @@ func parsePaneTarget(path string) (tmux.Pane, error) {
 		path = decoded
 	}
 
+	return parseTarget(path), nil
+}
+
+// parseTarget parses a tmux target of the form session[:window[.pane]].
+func parseTarget(path string) tmux.Pane {
 	// Parse session:window.pane
 	var session string
 	var window, pane int
 
 	colonIdx := strings.Index(path, ":")
 	if colonIdx == -1 {
-		return tmux.Pane{Session: path, Window: 0, Index: 0}, nil
+		return tmux.Pane{Session: path, Window: 0, Index: 0}
 	}
 
 	session = path[:colonIdx]
@@ -685,9 +1328,31 @@ func parsePaneTarget(path string) (tmux.Pane, error) {
 		_, _ = fmt.Sscanf(rest[dotIdx+1:], "%d", &pane)
 	}
 
-	return tmux.Pane{Session: session, Window: window, Index: pane}, nil
+	return tmux.Pane{Session: session, Window: window, Index: pane}
+}
+
+// windowKey identifies a pane's window across hosts.
+func windowKey(p tmux.Pane) string {
+	return tmux.Pane{Host: p.Host, Session: p.Session, Window: p.Window}.Key()
 }
 
+// lookupPaneInfo returns tmux's info (command, cwd, ...) for a single pane.
+func (s *Server) lookupPaneInfo(pane tmux.Pane) (tmux.PaneInfo, bool) {
+	c := s.client(pane.Host)
+	if c == nil {
+		return tmux.PaneInfo{}, false
+	}
+	panes, err := c.ListPanes(pane.Session, pane.Window)
+	if err != nil {
+		return tmux.PaneInfo{}, false
+	}
+	for _, p := range panes {
+		if p.Index == pane.Index {
+			return p, true
+		}
+	}
+	return tmux.PaneInfo{}, false
+}
 
 func (s *Server) handlePaneSend(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
 	if r.Method != http.MethodPost {
@@ -699,127 +1364,48 @@ func (s *Server) handlePaneSend(w http.ResponseWriter, r *http.Request, pane tmu
 	input := r.FormValue("input")
 	special := r.FormValue("special") == "true"
 	noEnter := r.FormValue("noenter") == "true"
+	verify := r.FormValue("verify") != "false"
+	mode := tmux.InputMode(r.FormValue("mode"))
+	if !tmux.ValidInputMode(mode) {
+		http.Error(w, "mode must be type or paste", http.StatusBadRequest)
+		return
+	}
 
-	slog.Info("send keys", "pane", pane.Target(), "input", input, "special", special, "noenter", noEnter)
+	slog.InfoContext(r.Context(), "send keys", "pane", pane.Target(), "input", input, "special", special, "noenter", noEnter)
+	if special {
+		auditDetail(r, "key "+input)
+	} else {
+		auditDetail(r, input)
+	}
 
+	var result SendResult
 	var err error
 	if special {
-		err = s.tmux.SendSpecialKey(pane, input)
+		result, err = s.sendKey(r.Context(), pane, input)
 	} else {
-		err = s.tmux.SendKeys(pane, input, !noEnter)
+		result, err = s.sendChecked(r.Context(), pane, input, !noEnter, mode, verify)
 	}
 
 	if err != nil {
-		slog.Error("send keys failed", "error", err)
+		slog.ErrorContext(r.Context(), "send keys failed", "error", err)
 		http.Error(w, "failed to send keys: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	slog.Debug("send keys success")
-	w.WriteHeader(http.StatusOK)
-}
-
-func (s *Server) handlePaneSendWithImages(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	r.Body = http.MaxBytesReader(w, r.Body, 50*1024*1024) // 50MB limit
-
-	var req struct {
-		Text   string `json:"text"`
-		Images []struct {
-			Name string `json:"name"`
-			Type string `json:"type"`
-			Data string `json:"data"` // base64 encoded
-		} `json:"images"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Error("failed to decode images request", "error", err)
-		http.Error(w, "invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	if len(req.Images) == 0 {
-		http.Error(w, "no images provided", http.StatusBadRequest)
-		return
-	}
-
-	// Process all images and create temp files
-	var tmpFiles []string
-	var cleanupOnError []string
-
-	for i, img := range req.Images {
-		// Decode base64 image
-		imageData, err := base64.StdEncoding.DecodeString(img.Data)
-		if err != nil {
-			slog.Error("failed to decode base64 image", "error", err, "index", i)
-			// Clean up any files created so far on error
-			for _, f := range cleanupOnError {
-				_ = os.Remove(f)
-			}
-			http.Error(w, fmt.Sprintf("invalid image data at index %d", i), http.StatusBadRequest)
-			return
-		}
-
-		// Write image to temp file with sanitized filename
-		safeName := filepath.Base(img.Name)
-		tmpPath := fmt.Sprintf("/tmp/houston-%d-%s", time.Now().UnixNano(), safeName)
-		tmpFile, err := os.Create(tmpPath)
-		if err != nil {
-			slog.Error("failed to create temp file", "error", err, "index", i)
-			// Clean up any files created so far on error
-			for _, f := range cleanupOnError {
-				_ = os.Remove(f)
-			}
-			http.Error(w, "failed to save image", http.StatusInternalServerError)
-			return
-		}
-
-		if _, err := tmpFile.Write(imageData); err != nil {
-			slog.Error("failed to write image", "error", err, "index", i)
-			_ = tmpFile.Close()
-			_ = os.Remove(tmpFile.Name())
-			// Clean up any files created so far on error
-			for _, f := range cleanupOnError {
-				_ = os.Remove(f)
-			}
-			http.Error(w, "failed to save image", http.StatusInternalServerError)
-			return
-		}
-		_ = tmpFile.Close()
-
-		tmpFiles = append(tmpFiles, tmpFile.Name())
-		cleanupOnError = append(cleanupOnError, tmpFile.Name())
-	}
-
-	// Clean up temp files after 1 hour (gives user time to reference them)
-	for _, f := range tmpFiles {
-		path := f
-		time.AfterFunc(1*time.Hour, func() {
-			_ = os.Remove(path)
-		})
-	}
-
-	// Send all image paths and text to Claude Code as a single prompt line
-	// Format: image1 image2 image3 text + Enter
-	message := strings.Join(tmpFiles, " ")
-	if req.Text != "" {
-		message = fmt.Sprintf("%s %s", message, req.Text)
-	}
-
-	slog.Info("send images with text", "pane", pane.Target(), "count", len(tmpFiles), "text", req.Text)
-
-	if err := s.tmux.SendKeys(pane, message, true); err != nil {
-		slog.Error("failed to send images", "error", err)
-		http.Error(w, "failed to send: "+err.Error(), http.StatusInternalServerError)
-		return
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case result.Error != "":
+		// Nothing was sent, so it's safe to try again
+		slog.WarnContext(r.Context(), "send keys refused", "pane", pane.Target(), "error", result.Error)
+		w.WriteHeader(http.StatusConflict)
+	case result.Checked && !result.Confirmed:
+		s.responses.Answered(windowKey(pane), time.Now())
+		slog.WarnContext(r.Context(), "send keys unconfirmed", "pane", pane.Target(), "exited_mode", result.ExitedMode)
+	default:
+		s.responses.Answered(windowKey(pane), time.Now())
+		slog.DebugContext(r.Context(), "send keys success", "confirmed", result.Confirmed, "exited_mode", result.ExitedMode)
 	}
-
-	slog.Debug("send images success", "count", len(tmpFiles))
-	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
 }
 
 func (s *Server) handlePaneKill(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
@@ -828,16 +1414,16 @@ func (s *Server) handlePaneKill(w http.ResponseWriter, r *http.Request, pane tmu
 		return
 	}
 
-	slog.Info("kill pane", "pane", pane.Target())
+	slog.InfoContext(r.Context(), "kill pane", "pane", pane.Target())
 
-	if err := s.tmux.KillPane(pane); err != nil {
-		slog.Error("kill pane failed", "error", err)
+	if err := s.client(pane.Host).KillPane(pane); err != nil {
+		slog.ErrorContext(r.Context(), "kill pane failed", "error", err)
 		http.Error(w, "failed to kill pane: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Redirect back to session or home
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	http.Redirect(w, r, s.basePath+"/", http.StatusSeeOther)
 }
 
 func (s *Server) handlePaneRespawn(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
@@ -846,15 +1432,15 @@ func (s *Server) handlePaneRespawn(w http.ResponseWriter, r *http.Request, pane
 		return
 	}
 
-	slog.Info("respawn pane", "pane", pane.Target())
+	slog.InfoContext(r.Context(), "respawn pane", "pane", pane.Target())
 
-	if err := s.tmux.RespawnPane(pane); err != nil {
-		slog.Error("respawn pane failed", "error", err)
+	if err := s.client(pane.Host).RespawnPane(pane); err != nil {
+		slog.ErrorContext(r.Context(), "respawn pane failed", "error", err)
 		http.Error(w, "failed to respawn pane: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	slog.Debug("respawn pane success")
+	slog.DebugContext(r.Context(), "respawn pane success")
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -864,16 +1450,16 @@ func (s *Server) handleWindowKill(w http.ResponseWriter, r *http.Request, pane t
 		return
 	}
 
-	slog.Info("kill window", "session", pane.Session, "window", pane.Window)
+	slog.InfoContext(r.Context(), "kill window", "session", pane.Session, "window", pane.Window)
 
-	if err := s.tmux.KillWindow(pane.Session, pane.Window); err != nil {
-		slog.Error("kill window failed", "error", err)
+	if err := s.client(pane.Host).KillWindow(pane.Session, pane.Window); err != nil {
+		slog.ErrorContext(r.Context(), "kill window failed", "error", err)
 		http.Error(w, "failed to kill window: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Redirect back to home
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	http.Redirect(w, r, s.basePath+"/", http.StatusSeeOther)
 }
 
 func (s *Server) handlePaneZoom(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
@@ -882,10 +1468,10 @@ func (s *Server) handlePaneZoom(w http.ResponseWriter, r *http.Request, pane tmu
 		return
 	}
 
-	slog.Info("zoom pane", "pane", pane.Target())
+	slog.InfoContext(r.Context(), "zoom pane", "pane", pane.Target())
 
-	if err := s.tmux.ZoomPane(pane); err != nil {
-		slog.Error("zoom pane failed", "error", err)
+	if err := s.client(pane.Host).ZoomPane(pane); err != nil {
+		slog.ErrorContext(r.Context(), "zoom pane failed", "error", err)
 		http.Error(w, "failed to zoom pane: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -905,9 +1491,14 @@ func (s *Server) buildOpenCodeData(ctx context.Context) OpenCodeData {
 		Active:         []OpenCodeSession{},
 		Idle:           []OpenCodeSession{},
 		Servers:        servers,
+		Freshness:      s.ocManager.Freshness(),
 	}
 
 	for _, state := range states {
+		// Sessions of a TUI in tmux are shown on its window instead
+		if s.openCodeInPane(state.ServerURL, state.Session.ID) {
+			continue
+		}
 		ocSession := OpenCodeSession{
 			State: state,
 		}
@@ -963,16 +1554,25 @@ func (s *Server) handleOpenCodeSession(w http.ResponseWriter, r *http.Request) {
 			s.handleOpenCodeSend(w, r, serverURL, sessionID)
 		case "abort":
 			s.handleOpenCodeAbort(w, r, serverURL, sessionID)
+		case "handoff":
+			s.handleOpenCodeHandoff(w, r, serverURL, sessionID)
+		case "messages":
+			s.handleOpenCodeMessages(w, r, serverURL, sessionID)
 		default:
 			http.Error(w, "unknown action", http.StatusBadRequest)
 		}
 		return
 	}
 
+	if r.Method == http.MethodDelete {
+		s.handleOpenCodeDelete(w, r, serverURL, sessionID)
+		return
+	}
+
 	// Get session details
 	state, err := s.ocManager.GetSessionDetails(r.Context(), serverURL, sessionID)
 	if err != nil {
-		slog.Error("failed to get OpenCode session", "error", err)
+		slog.ErrorContext(r.Context(), "failed to get OpenCode session", "error", err)
 		http.Error(w, "failed to get session: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -996,10 +1596,10 @@ func (s *Server) handleOpenCodeSend(w http.ResponseWriter, r *http.Request, serv
 		return
 	}
 
-	slog.Info("send to OpenCode", "server", serverURL, "session", sessionID, "text", text)
+	slog.InfoContext(r.Context(), "send to OpenCode", "server", serverURL, "session", sessionID, "text", text)
 
 	if err := s.ocManager.SendPrompt(r.Context(), serverURL, sessionID, text); err != nil {
-		slog.Error("failed to send to OpenCode", "error", err)
+		slog.ErrorContext(r.Context(), "failed to send to OpenCode", "error", err)
 		http.Error(w, "failed to send: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -1013,13 +1613,31 @@ func (s *Server) handleOpenCodeAbort(w http.ResponseWriter, r *http.Request, ser
 		return
 	}
 
-	slog.Info("abort OpenCode session", "server", serverURL, "session", sessionID)
+	slog.InfoContext(r.Context(), "abort OpenCode session", "server", serverURL, "session", sessionID)
+	auditDetail(r, serverURL+" "+sessionID)
 
 	if err := s.ocManager.AbortSession(r.Context(), serverURL, sessionID); err != nil {
-		slog.Error("failed to abort OpenCode session", "error", err)
+		slog.ErrorContext(r.Context(), "failed to abort OpenCode session", "error", err)
 		http.Error(w, "failed to abort: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// primaryPath returns the working directory most of a session's windows
+// are in, preferring the earliest window on a tie.
+func primaryPath(paths []string) string {
+	counts := make(map[string]int)
+	best := ""
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		counts[p]++
+		if counts[p] > counts[best] {
+			best = p
+		}
+	}
+	return best
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/agents/generic"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+// privateTmux points tmux at a server of the test's own, so the test
+// neither sees nor touches the user's sessions.
+func privateTmux(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
+	t.Cleanup(func() { _ = exec.Command("tmux", "kill-server").Run() })
+}
+
+func runTmux(t *testing.T, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("tmux", args...).CombinedOutput(); err != nil {
+		t.Fatalf("tmux %v: %v: %s", args, err, out)
+	}
+}
+
+func TestBuildSessionsDataOrder(t *testing.T) {
+	privateTmux(t)
+	for _, session := range []string{"alpha", "beta", "gamma"} {
+		runTmux(t, "new-session", "-d", "-s", session, "-n", "w0", "-x", "80", "-y", "24", "cat")
+		for i := 1; i < 12; i++ {
+			runTmux(t, "new-window", "-d", "-t", session, "-n", "w"+strconv.Itoa(i), "cat")
+		}
+	}
+
+	s, err := New(Config{DataDir: t.TempDir(), StatusDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	// Workers finish in any order; sessions and their windows don't move
+	order := func(data SessionsData) []string {
+		var names []string
+		for _, section := range [][]SessionWithWindows{data.NeedsAttention, data.Active, data.Idle} {
+			for _, sess := range section {
+				for _, w := range sess.Windows {
+					names = append(names, sess.Session.Name+":"+w.Window.Name)
+				}
+			}
+		}
+		return names
+	}
+	var want []string
+	for _, session := range []string{"alpha", "beta", "gamma"} {
+		for i := range 12 {
+			want = append(want, session+":w"+strconv.Itoa(i))
+		}
+	}
+	first := order(s.buildSessionsData(context.Background(), sessionsQuery{}))
+	if !reflect.DeepEqual(first, want) {
+		t.Fatalf("build order = %v, want tmux's %v", first, want)
+	}
+	for i := range 5 {
+		if got := order(s.buildSessionsData(context.Background(), sessionsQuery{})); !reflect.DeepEqual(got, first) {
+			t.Fatalf("build %d order = %v, want %v", i+2, got, first)
+		}
+	}
+}
+
+func TestSessionWorktrees(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	repos := map[string]string{filepath.Join(dir, "api"): "main", filepath.Join(dir, "web"): "develop"}
+	for repo, branch := range repos {
+		if err := os.MkdirAll(filepath.Join(repo, "src"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := exec.Command("git", "init", "-q", "-b", branch, repo).CombinedOutput(); err != nil {
+			t.Fatalf("git init: %v: %s", err, out)
+		}
+		if out, err := exec.Command("git", "-C", repo, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init").CombinedOutput(); err != nil {
+			t.Fatalf("git commit: %v: %s", err, out)
+		}
+	}
+
+	// One session spanning both repositories gets each one's branches
+	c := tmux.NewClient()
+	w := &sessionWorktrees{client: c}
+	for range 2 {
+		for repo, branch := range repos {
+			if got := c.GetBranchForPath(filepath.Join(repo, "src"), w.get(filepath.Join(repo, "src"))); got != branch {
+				t.Errorf("branch of %s = %q, want %q", repo, got, branch)
+			}
+		}
+	}
+	if len(w.repos) != 2 {
+		t.Errorf("loaded %d repositories, want 2", len(w.repos))
+	}
+	if got := w.get(dir); got != nil {
+		t.Errorf("worktrees outside a repository = %v", got)
+	}
+}
+
+// transcriptAgent is an agent whose state comes from its files, like
+// Claude's, and is set by the test.
+type transcriptAgent struct {
+	*generic.Agent
+	mu    sync.Mutex
+	state parser.ResultType
+}
+
+func (a *transcriptAgent) Type() agents.AgentType       { return agents.AgentClaudeCode }
+func (a *transcriptAgent) DetectFromOutput(string) bool { return true }
+
+func (a *transcriptAgent) GetStateFromFiles(string) (*agents.AgentState, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return &agents.AgentState{Agent: agents.AgentClaudeCode, Result: parser.Result{Type: a.state}}, nil
+}
+
+func (a *transcriptAgent) set(state parser.ResultType) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.state = state
+}
+
+func TestFindBestPaneRereadsFileState(t *testing.T) {
+	privateTmux(t)
+	runTmux(t, "new-session", "-d", "-s", "work", "-x", "80", "-y", "24", "-c", t.TempDir(), "cat")
+	agent := &transcriptAgent{Agent: generic.New(), state: parser.TypeWorking}
+	s := &Server{tmux: tmux.NewClient(), registry: agents.NewRegistry(agent, generic.New()), captures: newCaptureCache()}
+	c := s.tmux
+	tree, err := c.ListTree()
+	if err != nil || len(tree) != 1 {
+		t.Fatalf("ListTree() = %v, %v", tree, err)
+	}
+	win := tree[0].Windows[0]
+
+	if best := s.findBestPane(context.Background(), c, "work", win.Index, win.Panes); best.parseResult.Type != parser.TypeWorking {
+		t.Fatalf("first state = %v, want working", best.parseResult.Type)
+	}
+	// The turn ends in the transcript; the screen doesn't change
+	agent.set(parser.TypeDone)
+	if best := s.findBestPane(context.Background(), c, "work", win.Index, win.Panes); best.parseResult.Type != parser.TypeDone {
+		t.Errorf("state after the transcript moved on = %v, want done", best.parseResult.Type)
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/noamsto/houston/notify"
+)
+
+// maxSlackBody bounds an interaction request; Slack's are a few KB.
+const maxSlackBody = 1 << 20
+
+// handleAPISlackInteractions serves POST /api/slack/interactions, the
+// Request URL of the Slack app's interactivity. A click on a choice button
+// of an attention message selects that choice in the pane, refusing (like
+// /api/pane/{target}/choose) if the pane shows a different prompt by now.
+// The message is then replaced with who chose what.
+func (s *Server) handleAPISlackInteractions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.slack == nil {
+		http.Error(w, "slack not configured", http.StatusNotFound)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSlackBody))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if err := s.slack.Verify(r.Header, body, time.Now()); err != nil {
+		slog.WarnContext(r.Context(), "slack interaction refused", "error", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	in, err := notify.ParseSlackInteraction(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	text := s.slackChoose(r.Context(), in)
+	auditDetail(r, "slack "+in.User+": "+in.Choice.Choice)
+	w.WriteHeader(http.StatusOK)
+
+	if in.ResponseURL != "" {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := s.slack.Respond(ctx, in.ResponseURL, text); err != nil {
+				slog.WarnContext(r.Context(), "slack response failed", "error", err)
+			}
+		}()
+	}
+}
+
+// slackChoose selects a clicked choice and returns the text to replace
+// the message with.
+func (s *Server) slackChoose(ctx context.Context, in notify.SlackInteraction) string {
+	pane := parseTarget(in.Choice.Target)
+	pane.Host = in.Choice.Host
+	where := pane.Target()
+	if pane.Host != "" {
+		where = pane.Host + "|" + where
+	}
+	if s.client(pane.Host) == nil {
+		return fmt.Sprintf("⚠️ %s: unknown host %q", where, pane.Host)
+	}
+	result, _, err := s.choose(ctx, pane, ChooseRequest{Index: in.Choice.Index, Choice: in.Choice.Choice})
+	if err != nil {
+		slog.Info("slack choice not selected", "pane", pane.Target(), "user", in.User, "error", err)
+		return fmt.Sprintf("⚠️ %s: couldn't select %q: %v", where, in.Choice.Choice, err)
+	}
+	slog.Info("slack choice selected", "pane", pane.Target(), "user", in.User, "choice", result.Choice)
+	return fmt.Sprintf("✅ %s chose %q in %s", in.User, result.Choice, where)
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/notify"
+)
+
+func TestHandleAPISlackInteractions(t *testing.T) {
+	responses := make(chan string, 1)
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&msg)
+		responses <- msg.Text
+	}))
+	defer slackSrv.Close()
+
+	s := &Server{slack: notify.NewSlack("xoxb-test", "C123", "secret")}
+
+	value, _ := json.Marshal(notify.SlackChoice{Target: "api:1.0", Host: "gone", Index: 0, Choice: "Yes"})
+	payload, _ := json.Marshal(map[string]any{
+		"type":         "block_actions",
+		"user":         map[string]string{"username": "alice"},
+		"response_url": slackSrv.URL,
+		"actions":      []map[string]string{{"action_id": "choose-0", "value": string(value)}},
+	})
+	body := url.Values{"payload": {string(payload)}}.Encode()
+
+	post := func(signature string) *httptest.ResponseRecorder {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		if signature == "" {
+			mac := hmac.New(sha256.New, []byte("secret"))
+			io.WriteString(mac, "v0:"+ts+":"+body)
+			signature = "v0=" + hex.EncodeToString(mac.Sum(nil))
+		}
+		r := httptest.NewRequest("POST", "/api/slack/interactions", strings.NewReader(body))
+		r.Header.Set("X-Slack-Request-Timestamp", ts)
+		r.Header.Set("X-Slack-Signature", signature)
+		w := httptest.NewRecorder()
+		s.handleAPISlackInteractions(w, r)
+		return w
+	}
+
+	if w := post("v0=forged"); w.Code != http.StatusUnauthorized {
+		t.Errorf("forged request: status %d, want 401", w.Code)
+	}
+	if w := post(""); w.Code != http.StatusOK {
+		t.Fatalf("signed request: status %d: %s", w.Code, w.Body)
+	}
+	select {
+	case text := <-responses:
+		if !strings.Contains(text, `unknown host "gone"`) {
+			t.Errorf("response = %q", text)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no response to the message")
+	}
+}
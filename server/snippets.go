@@ -0,0 +1,254 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// snippetsDocument is the store document holding prompt snippets.
+const snippetsDocument = "snippets"
+
+// Snippet is a named prompt template. {{name}} placeholders are filled in
+// when it is sent: branch, cwd, session, window and clipboard from the
+// pane and request, others from the request's vars.
+type Snippet struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Text        string   `json:"text"`
+	Variables   []string `json:"variables"` // Placeholders in Text, in order of appearance
+}
+
+// SendTemplateRequest is the body of POST /api/pane/{target}/send-template.
+type SendTemplateRequest struct {
+	Name      string            `json:"name"`
+	Vars      map[string]string `json:"vars,omitempty"`      // Values for custom placeholders; also override built-ins
+	Clipboard string            `json:"clipboard,omitempty"` // The client's clipboard, for {{clipboard}}
+	NoEnter   bool              `json:"noenter,omitempty"`   // Don't press Enter after the text
+}
+
+// SendTemplateResult is the response of a successful send-template.
+type SendTemplateResult struct {
+	Text string `json:"text"` // The expanded text that was sent
+}
+
+var (
+	snippetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_-]*)\s*\}\}`)
+)
+
+// placeholders returns the distinct placeholder names in text.
+func placeholders(text string) []string {
+	names := []string{}
+	for _, m := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// expandSnippet fills in the placeholders of text. It fails listing the
+// placeholders vars has no value for.
+func expandSnippet(text string, vars map[string]string) (string, error) {
+	var missing []string
+	for _, name := range placeholders(text) {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no value for %s", strings.Join(missing, ", "))
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+		return vars[placeholderPattern.FindStringSubmatch(m)[1]]
+	}), nil
+}
+
+func validateSnippet(sn Snippet) error {
+	if !snippetNamePattern.MatchString(sn.Name) {
+		return fmt.Errorf("snippet name must match %s", snippetNamePattern)
+	}
+	if strings.TrimSpace(sn.Text) == "" {
+		return fmt.Errorf("snippet text must not be empty")
+	}
+	return nil
+}
+
+// loadSnippets reads prompt snippets from the store.
+func (s *Server) loadSnippets() {
+	snippets := make(map[string]Snippet)
+	if err := s.store.Load(snippetsDocument, &snippets); err != nil {
+		slog.Warn("failed to load snippets", "error", err)
+	}
+	s.snippetsMu.Lock()
+	s.snippets = snippets
+	s.snippetsMu.Unlock()
+}
+
+// handleAPISnippets serves GET (list) and POST (create/update) on /api/snippets.
+func (s *Server) handleAPISnippets(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.snippetsMu.RLock()
+		snippets := make([]Snippet, 0, len(s.snippets))
+		for _, sn := range s.snippets {
+			snippets = append(snippets, sn)
+		}
+		s.snippetsMu.RUnlock()
+		sort.Slice(snippets, func(i, j int) bool { return snippets[i].Name < snippets[j].Name })
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(snippets)
+	case http.MethodPost:
+		var sn Snippet
+		if err := json.NewDecoder(r.Body).Decode(&sn); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		s.saveSnippet(w, sn)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPISnippet serves /api/snippets/{name}: GET returns the snippet,
+// PUT replaces it, DELETE removes it.
+func (s *Server) handleAPISnippet(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/snippets/")
+
+	switch r.Method {
+	case http.MethodGet:
+		s.snippetsMu.RLock()
+		sn, ok := s.snippets[name]
+		s.snippetsMu.RUnlock()
+		if !ok {
+			http.Error(w, "snippet not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sn)
+	case http.MethodPut:
+		var sn Snippet
+		if err := json.NewDecoder(r.Body).Decode(&sn); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		sn.Name = name
+		s.saveSnippet(w, sn)
+	case http.MethodDelete:
+		s.snippetsMu.Lock()
+		if _, ok := s.snippets[name]; !ok {
+			s.snippetsMu.Unlock()
+			http.Error(w, "snippet not found", http.StatusNotFound)
+			return
+		}
+		delete(s.snippets, name)
+		err := s.store.Save(snippetsDocument, s.snippets)
+		s.snippetsMu.Unlock()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to save snippets", "error", err)
+			http.Error(w, "failed to save snippets", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(r.Context(), "snippet deleted", "name", name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) saveSnippet(w http.ResponseWriter, sn Snippet) {
+	if err := validateSnippet(sn); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sn.Variables = placeholders(sn.Text)
+
+	s.snippetsMu.Lock()
+	s.snippets[sn.Name] = sn
+	err := s.store.Save(snippetsDocument, s.snippets)
+	s.snippetsMu.Unlock()
+	if err != nil {
+		slog.Error("failed to save snippets", "error", err)
+		http.Error(w, "failed to save snippets", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("snippet saved", "name", sn.Name, "variables", sn.Variables)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sn)
+}
+
+// handlePaneSendTemplate serves POST /api/pane/{target}/send-template: it
+// expands a snippet for the pane and types it in.
+func (s *Server) handlePaneSendTemplate(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req SendTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	s.snippetsMu.RLock()
+	sn, ok := s.snippets[req.Name]
+	s.snippetsMu.RUnlock()
+	if !ok {
+		http.Error(w, "snippet not found", http.StatusNotFound)
+		return
+	}
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	c := s.client(pane.Host)
+	vars := map[string]string{
+		"cwd":       info.Path,
+		"session":   pane.Session,
+		"window":    strconv.Itoa(pane.Window),
+		"clipboard": req.Clipboard,
+	}
+	if slices.Contains(sn.Variables, "branch") {
+		vars["branch"] = c.GetBranchForPath(info.Path, nil)
+	}
+	for name, value := range req.Vars {
+		vars[name] = value
+	}
+	text, err := expandSnippet(sn.Text, vars)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.sendChecked(r.Context(), pane, text, !req.NoEnter, tmux.InputAuto, false)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "send template failed", "pane", pane.Target(), "snippet", sn.Name, "error", err)
+		http.Error(w, "failed to send keys: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if result.Error != "" {
+		// Nothing was sent, so it's safe to try again
+		http.Error(w, result.Error, http.StatusConflict)
+		return
+	}
+	s.responses.Answered(windowKey(pane), time.Now())
+	slog.InfoContext(r.Context(), "snippet sent", "pane", pane.Target(), "snippet", sn.Name)
+	auditDetail(r, text)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(SendTemplateResult{Text: text})
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/store"
+)
+
+func TestExpandSnippet(t *testing.T) {
+	text := "Review {{branch}} in {{ cwd }}: {{clipboard}} ({{branch}})"
+	if got := placeholders(text); !slices.Equal(got, []string{"branch", "cwd", "clipboard"}) {
+		t.Errorf("placeholders = %v", got)
+	}
+
+	got, err := expandSnippet(text, map[string]string{"branch": "feat/x", "cwd": "/src/app", "clipboard": "panic: nil map"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Review feat/x in /src/app: panic: nil map (feat/x)"; got != want {
+		t.Errorf("expandSnippet = %q, want %q", got, want)
+	}
+
+	// Values are not expanded again
+	if got, _ := expandSnippet("{{a}}", map[string]string{"a": "{{b}}"}); got != "{{b}}" {
+		t.Errorf("expandSnippet = %q, want the value verbatim", got)
+	}
+
+	if _, err := expandSnippet("Fix {{ticket}} on {{branch}}", map[string]string{"branch": "main"}); err == nil || !strings.Contains(err.Error(), "ticket") {
+		t.Errorf("error = %v, want the missing placeholder named", err)
+	}
+}
+
+func TestHandleAPISnippets(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st}
+	s.loadSnippets()
+
+	w := httptest.NewRecorder()
+	s.handleAPISnippet(w, httptest.NewRequest("PUT", "/api/snippets/review", strings.NewReader(`{"text":"Review the diff on {{branch}} for {{focus}}"}`)))
+	var saved Snippet
+	if err := json.NewDecoder(w.Body).Decode(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != "review" || !slices.Equal(saved.Variables, []string{"branch", "focus"}) {
+		t.Errorf("saved = %+v", saved)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPISnippets(w, httptest.NewRequest("POST", "/api/snippets", strings.NewReader(`{"name":"bad name","text":"x"}`)))
+	if w.Code != 400 {
+		t.Errorf("invalid name status = %d, want 400", w.Code)
+	}
+
+	// Snippets survive a restart
+	restarted := &Server{store: st}
+	restarted.loadSnippets()
+	w = httptest.NewRecorder()
+	restarted.handleAPISnippets(w, httptest.NewRequest("GET", "/api/snippets", nil))
+	var list []Snippet
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Text != saved.Text {
+		t.Errorf("snippets after restart = %+v", list)
+	}
+
+	w = httptest.NewRecorder()
+	restarted.handleAPISnippet(w, httptest.NewRequest("DELETE", "/api/snippets/review", nil))
+	if w.Code != 204 {
+		t.Errorf("DELETE status = %d, want 204", w.Code)
+	}
+}
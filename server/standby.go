@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/noamsto/houston/history"
+	"github.com/noamsto/houston/internal/fswatch"
+)
+
+// handoffDoc is the store document a stopping houston leaves for the
+// process that replaces it.
+const handoffDoc = "handoff"
+
+// handoffMaxAge is how old a handoff may be and still be adopted. Older
+// ones were left by a houston that was stopped rather than restarted.
+const handoffMaxAge = time.Minute
+
+// standbyTimeout bounds how long a standby process waits for the running
+// one to hand over before taking over anyway.
+const standbyTimeout = 2 * time.Minute
+
+// handoffState is the in-memory state carried across a restart.
+type handoffState struct {
+	Saved    time.Time             `json:"saved"`
+	Panes    map[string]paneEntry  `json:"panes"`    // Window state machines
+	Pending  []history.Pending     `json:"pending"`  // Prompts waiting for an answer
+	States   []history.WindowState `json:"states"`   // Last state per window, for transitions
+	Notified map[string]int        `json:"notified"` // Reminder level sent per window
+	Sessions []savedSnapshot       `json:"sessions"` // Recent stream payloads, for Last-Event-ID resume
+}
+
+// Handoff saves in-memory state for the next houston process. Call it on
+// shutdown, before the listener closes.
+func (s *Server) Handoff() error {
+	if !s.isPrimary() {
+		// Still waiting for our own predecessor; nothing to pass on.
+		return nil
+	}
+
+	return s.store.Save(handoffDoc, handoffState{
+		Saved:    time.Now(),
+		Panes:    s.states.export(),
+		Pending:  s.responses.Pending(time.Now()),
+		States:   s.transitions.States(),
+		Notified: s.notified.Levels(),
+		Sessions: s.sessionsHistory.export(),
+	})
+}
+
+// Close stops the background loops, the status watcher, the update checker
+// and OpenCode discovery, waiting for a pass in progress (a prompt being
+// sent, a document being saved) so nothing reaches the store after it,
+// then saves in-memory state with Handoff. Call it on shutdown, before the
+// listener closes.
+func (s *Server) Close() error {
+	if s.stopWork != nil {
+		s.stopWork()
+	}
+	s.work.Wait()
+	if s.ocManager != nil {
+		s.ocManager.Close()
+	}
+	return s.Handoff()
+}
+
+// adoptHandoff merges state left by a previous process and removes it.
+// ok is false when there is none, or it is too old to trust.
+func (s *Server) adoptHandoff() bool {
+	var state handoffState
+	if err := s.store.Load(handoffDoc, &state); err != nil {
+		slog.Warn("failed to load handoff state", "error", err)
+		return false
+	}
+	if state.Saved.IsZero() {
+		return false
+	}
+	if err := s.store.Remove(handoffDoc); err != nil {
+		slog.Warn("failed to remove handoff state", "error", err)
+	}
+	if time.Since(state.Saved) > handoffMaxAge {
+		slog.Info("ignoring stale handoff state", "saved", state.Saved)
+		return false
+	}
+
+	s.states.restore(state.Panes)
+	s.responses.Restore(state.Pending)
+	s.transitions.Restore(state.States)
+	s.notified.Restore(state.Notified)
+	s.sessionsHistory.restore(state.Sessions)
+
+	slog.Info("adopted state from previous houston", "saved", state.Saved, "pending", len(state.Pending))
+	return true
+}
+
+// awaitHandoff keeps this process in standby until the running houston
+// hands over its state on shutdown, or timeout passes.
+func (s *Server) awaitHandoff(timeout time.Duration) {
+	defer close(s.primary)
+
+	w, err := fswatch.WatchDir(s.store.Dir())
+	if err != nil {
+		slog.Warn("can't watch for handoff, taking over now", "error", err)
+		return
+	}
+	defer func() { _ = w.Close() }()
+
+	slog.Info("standing by until the running houston stops", "timeout", timeout)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if ev.Name == handoffDoc+".json" && !ev.Removed && s.adoptHandoff() {
+				return
+			}
+		case <-timer.C:
+			slog.Warn("no handoff from the running houston, taking over")
+			return
+		}
+	}
+}
+
+// isPrimary reports whether this process owns background work: it is not
+// standing by for another houston to hand over.
+func (s *Server) isPrimary() bool {
+	select {
+	case <-s.primary:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitPrimary blocks until this process owns background work. It returns
+// false if ctx ends first.
+func (s *Server) waitPrimary(ctx context.Context) bool {
+	select {
+	case <-s.primary:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Drain ends session streams and pane sockets so their clients reconnect,
+// to the process taking over. Register it with http.Server.RegisterOnShutdown.
+func (s *Server) Drain() {
+	s.drainOnce.Do(func() { close(s.draining) })
+}
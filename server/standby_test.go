@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/history"
+	"github.com/noamsto/houston/notify"
+	"github.com/noamsto/houston/store"
+)
+
+func testStandbyServer(t *testing.T, st *store.Store) *Server {
+	t.Helper()
+	s := &Server{
+		store:           st,
+		responses:       history.NewResponseTracker(st),
+		transitions:     history.NewTransitionTracker(st),
+		notified:        notify.NewTracker(),
+		sessionsHistory: newSessionsHistory(),
+		states:          newPaneStates(),
+		primary:         make(chan struct{}),
+	}
+	close(s.primary)
+	return s
+}
+
+func TestHandoffRoundTrip(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Now().Add(-10 * time.Minute).Truncate(time.Second)
+
+	old := testStandbyServer(t, st)
+	old.states.observe("api:1", false, true, false, time.Hour, started)
+	old.responses.Observe("api:1", "api", "question", true, started)
+	old.transitions.Observe("api:1", "api", "", "claude-code", history.StateAttention, started)
+	old.notified.Due("api:1", 1)
+	data := SessionsData{
+		NeedsAttention: []SessionWithWindows{testSession("api", 1)},
+		Active:         []SessionWithWindows{},
+		Idle:           []SessionWithWindows{testSession("docs", 0)},
+	}
+	raw, _ := json.Marshal(data)
+	id := old.sessionsHistory.record(data, raw)
+
+	if err := old.Handoff(); err != nil {
+		t.Fatalf("Handoff() error: %v", err)
+	}
+
+	next := testStandbyServer(t, st)
+	if !next.adoptHandoff() {
+		t.Fatal("adoptHandoff() = false, want true")
+	}
+	if next.adoptHandoff() {
+		t.Error("adoptHandoff() adopted the same handoff twice")
+	}
+
+	if got := next.states.export()["api:1"]; got.State != PaneWorking || !got.LastWorking.Equal(started) {
+		t.Errorf("pane state = %+v, want working since %v", got, started)
+	}
+	if since, ok := next.responses.Since("api:1"); !ok || !since.Equal(started) {
+		t.Errorf("Since() = %v, %v; want %v, true", since, ok, started)
+	}
+	if next.notified.Due("api:1", 1) {
+		t.Error("reminder level 1 would be notified again")
+	}
+	states := next.transitions.States()
+	if len(states) != 1 || states[0].State != history.StateAttention {
+		t.Errorf("States() = %+v, want api:1 in attention", states)
+	}
+
+	// A client of the old process resumes with nothing to catch up on
+	p, ok := next.sessionsHistory.patch(id, data)
+	if !ok {
+		t.Fatal("patch(old process id) not ok")
+	}
+	if len(p.Changed) != 0 {
+		t.Errorf("patch Changed = %v, want none", p.Changed)
+	}
+}
+
+func TestAdoptHandoffStale(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Save(handoffDoc, handoffState{Saved: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := testStandbyServer(t, st)
+	if s.adoptHandoff() {
+		t.Error("adoptHandoff() adopted an hour-old handoff")
+	}
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+// sessionsCache keeps the last full sessions build, so the agent strip is
+// read from it instead of walking every pane again. A nil *sessionsCache
+// keeps nothing.
+type sessionsCache struct {
+	mu   sync.Mutex
+	data SessionsData
+	at   time.Time
+}
+
+func (c *sessionsCache) store(data SessionsData) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data, c.at = data, time.Now()
+}
+
+// load returns the last build if it's younger than maxAge.
+func (c *sessionsCache) load(maxAge time.Duration) (SessionsData, bool) {
+	if c == nil {
+		return SessionsData{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.at.IsZero() || time.Since(c.at) > maxAge {
+		return SessionsData{}, false
+	}
+	return c.data, true
+}
+
+// agentStrip returns the agent windows across all sessions for the pane
+// page's navigation strip, with active marked. It's derived from the last
+// sessions build when the dashboard refreshed it recently.
+func (s *Server) agentStrip(ctx context.Context, active tmux.Pane) []AgentStripItem {
+	data, ok := s.sessionsCache.load(s.sessionsInterval)
+	if !ok {
+		data = s.buildSessionsData(ctx, sessionsQuery{})
+	}
+	return stripItems(data, active, s.hosts)
+}
+
+// stripItems picks the agent windows out of a sessions build, in tmux
+// order: local sessions first, then each remote host's in configured
+// order.
+func stripItems(data SessionsData, active tmux.Pane, hosts []string) []AgentStripItem {
+	items := []AgentStripItem{}
+	for _, win := range data.allWindows() {
+		if win.AgentType == agents.AgentGeneric {
+			continue
+		}
+
+		indicator := "idle"
+		switch win.ParseResult.Type {
+		case parser.TypeError, parser.TypeChoice, parser.TypeQuestion:
+			indicator = "attention"
+		case parser.TypeWorking:
+			indicator = "working"
+		case parser.TypeDone:
+			indicator = "done"
+		}
+
+		name := win.Branch
+		if name == "" {
+			name = win.Window.Name
+		}
+
+		pane := win.Pane
+		items = append(items, AgentStripItem{
+			Host:      pane.Host,
+			Session:   pane.Session,
+			Window:    pane.Window,
+			Pane:      pane.Index,
+			Name:      name,
+			Indicator: indicator,
+			AgentType: win.AgentType,
+			Active:    pane.Host == active.Host && pane.Session == active.Session && pane.Window == active.Window && pane.Index == active.Index,
+		})
+	}
+
+	hostOrder := func(host string) int {
+		if host == "" {
+			return -1
+		}
+		return slices.Index(hosts, host)
+	}
+	slices.SortStableFunc(items, func(a, b AgentStripItem) int {
+		return cmp.Or(
+			cmp.Compare(hostOrder(a.Host), hostOrder(b.Host)),
+			cmp.Compare(a.Session, b.Session),
+			cmp.Compare(a.Window, b.Window),
+		)
+	})
+	return items
+}
+
+// handleAPIStrip serves GET /api/strip: the agent strip of the pane page
+// without the pane's capture, for refreshing it on its own. ?active= (and
+// ?host=) names the pane being viewed.
+func (s *Server) handleAPIStrip(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var active tmux.Pane
+	if target := r.URL.Query().Get("active"); target != "" {
+		active = parseTarget(target)
+		active.Host = r.URL.Query().Get("host")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.agentStrip(r.Context(), active))
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestStripItems(t *testing.T) {
+	window := func(host, session string, index int, agent agents.AgentType, result parser.ResultType, branch string) WindowWithStatus {
+		return WindowWithStatus{
+			Window:      tmux.Window{Index: index, Name: "claude"},
+			Pane:        tmux.Pane{Host: host, Session: session, Window: index, Index: 1},
+			ParseResult: parser.Result{Type: result},
+			Branch:      branch,
+			AgentType:   agent,
+		}
+	}
+	data := SessionsData{
+		NeedsAttention: []SessionWithWindows{{Windows: []WindowWithStatus{
+			window("devbox", "api", 2, agents.AgentClaudeCode, parser.TypeQuestion, "fix-auth"),
+			window("", "work", 3, agents.AgentAmp, parser.TypeError, ""),
+		}}},
+		Active: []SessionWithWindows{{Windows: []WindowWithStatus{
+			window("", "work", 1, agents.AgentClaudeCode, parser.TypeWorking, "main"),
+			window("", "work", 2, agents.AgentGeneric, parser.TypeWorking, "main"),
+		}}},
+		Idle: []SessionWithWindows{{Windows: []WindowWithStatus{
+			window("", "notes", 1, agents.AgentClaudeCode, parser.TypeDone, ""),
+		}}},
+	}
+
+	items := stripItems(data, tmux.Pane{Session: "work", Window: 1, Index: 1}, []string{"devbox"})
+	want := []AgentStripItem{
+		{Session: "notes", Window: 1, Pane: 1, Name: "claude", Indicator: "done", AgentType: agents.AgentClaudeCode},
+		{Session: "work", Window: 1, Pane: 1, Name: "main", Indicator: "working", AgentType: agents.AgentClaudeCode, Active: true},
+		{Session: "work", Window: 3, Pane: 1, Name: "claude", Indicator: "attention", AgentType: agents.AgentAmp},
+		{Host: "devbox", Session: "api", Window: 2, Pane: 1, Name: "fix-auth", Indicator: "attention", AgentType: agents.AgentClaudeCode},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(items), len(want), items)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
+
+func TestSessionsCache(t *testing.T) {
+	var c sessionsCache
+	if _, ok := c.load(time.Minute); ok {
+		t.Error("empty cache loaded")
+	}
+	c.store(SessionsData{Hidden: []tmux.Session{{Name: "scratch"}}})
+	if data, ok := c.load(time.Minute); !ok || len(data.Hidden) != 1 {
+		t.Errorf("load = %+v, %v", data, ok)
+	}
+	if _, ok := c.load(0); ok {
+		t.Error("stale build loaded")
+	}
+}
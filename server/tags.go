@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// windowTagsDocument is the store document holding window tags.
+const windowTagsDocument = "window-tags"
+
+// maxWindowTags caps the tags on one window.
+const maxWindowTags = 16
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,31}$`)
+
+// WindowTags is the body of PUT, POST and DELETE on /api/pane/{target}/tags
+// and the response of all methods on that route.
+type WindowTags struct {
+	Tags []string `json:"tags"`
+}
+
+// windowTags holds the labels of windows ("frontend", "reviewing") by
+// window key.
+type windowTags struct {
+	mu   sync.Mutex
+	tags map[string][]string
+}
+
+func newWindowTags() *windowTags {
+	return &windowTags{tags: make(map[string][]string)}
+}
+
+func (t *windowTags) get(key string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.tags[key])
+}
+
+// set replaces the tags of a window; no tags forgets it.
+func (t *windowTags) set(key string, tags []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(tags) == 0 {
+		delete(t.tags, key)
+	} else {
+		t.tags[key] = tags
+	}
+}
+
+func (t *windowTags) all() map[string][]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make(map[string][]string, len(t.tags))
+	for key, tags := range t.tags {
+		result[key] = slices.Clone(tags)
+	}
+	return result
+}
+
+// normalizeTags lowercases tags, drops duplicates and sorts them. It fails
+// on tags that aren't short words.
+func normalizeTags(tags []string) ([]string, error) {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q: want letters, digits, '-', '_' or '.', up to 32", tag)
+		}
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	result = slices.Compact(result)
+	if len(result) > maxWindowTags {
+		return nil, fmt.Errorf("too many tags: at most %d per window", maxWindowTags)
+	}
+	return result, nil
+}
+
+// loadWindowTags restores window tags from the store.
+func (s *Server) loadWindowTags() {
+	tags := make(map[string][]string)
+	if err := s.store.Load(windowTagsDocument, &tags); err != nil {
+		slog.Warn("failed to load window tags", "error", err)
+	}
+	for key, t := range tags {
+		s.tags.set(key, t)
+	}
+}
+
+// handlePaneTags serves /api/pane/{target}/tags, the tags of the pane's
+// window: GET lists them, PUT replaces them, POST adds to them and DELETE
+// removes the given tags (all of them without a body).
+func (s *Server) handlePaneTags(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	key := windowKey(pane)
+	tags := s.tags.get(key)
+
+	if r.Method != http.MethodGet {
+		var req WindowTags
+		switch r.Method {
+		case http.MethodPut, http.MethodPost, http.MethodDelete:
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		given, err := normalizeTags(req.Tags)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPut:
+			tags = given
+		case r.Method == http.MethodPost:
+			tags = append(tags, given...)
+		case len(given) == 0:
+			tags = nil
+		default:
+			tags = slices.DeleteFunc(tags, func(tag string) bool { return slices.Contains(given, tag) })
+		}
+		if tags, err = normalizeTags(tags); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.tags.set(key, tags)
+		if err := s.store.Save(windowTagsDocument, s.tags.all()); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save window tags", "error", err)
+			http.Error(w, "failed to save window tags", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(r.Context(), "window tags set", "window", key, "tags", tags)
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(WindowTags{Tags: tags})
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/noamsto/houston/store"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestHandlePaneTags(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, tags: newWindowTags()}
+	pane := tmux.Pane{Session: "web", Window: 2, Index: 1}
+
+	steps := []struct {
+		method string
+		body   string
+		want   []string
+	}{
+		{"PUT", `{"tags":["Frontend","bugfix","frontend"]}`, []string{"bugfix", "frontend"}},
+		{"POST", `{"tags":["reviewing"]}`, []string{"bugfix", "frontend", "reviewing"}},
+		{"DELETE", `{"tags":["bugfix"]}`, []string{"frontend", "reviewing"}},
+		{"GET", "", []string{"frontend", "reviewing"}},
+	}
+	for _, step := range steps {
+		w := httptest.NewRecorder()
+		s.handlePaneTags(w, httptest.NewRequest(step.method, "/api/pane/web:2.1/tags", strings.NewReader(step.body)), pane)
+		var got WindowTags
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: %v", step.method, err)
+		}
+		if !slices.Equal(got.Tags, step.want) {
+			t.Errorf("%s: tags = %v, want %v", step.method, got.Tags, step.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	s.handlePaneTags(w, httptest.NewRequest("POST", "/api/pane/web:2.1/tags", strings.NewReader(`{"tags":["no spaces"]}`)), pane)
+	if w.Code != 400 {
+		t.Errorf("invalid tag status = %d, want 400", w.Code)
+	}
+
+	// Tags belong to the window and survive a restart
+	restarted := &Server{store: st, tags: newWindowTags()}
+	restarted.loadWindowTags()
+	if got := restarted.tags.get(windowKey(pane)); !slices.Equal(got, []string{"frontend", "reviewing"}) {
+		t.Errorf("restored tags = %v", got)
+	}
+
+	w = httptest.NewRecorder()
+	s.handlePaneTags(w, httptest.NewRequest("DELETE", "/api/pane/web:2.1/tags", nil), pane)
+	if got := s.tags.get(windowKey(pane)); len(got) != 0 {
+		t.Errorf("tags after DELETE without body = %v, want none", got)
+	}
+}
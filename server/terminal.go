@@ -0,0 +1,121 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// TerminalInfo is the response of GET /api/terminal.
+type TerminalInfo struct {
+	Name     string   `json:"name"`
+	Supports []string `json:"supports"`         // font, theme, opacity, font-family
+	Themes   []string `json:"themes,omitempty"` // Theme aliases configured with -terminal-theme
+}
+
+// TerminalRequest is the body of POST /api/terminal/{theme,opacity,font-family}.
+type TerminalRequest struct {
+	Theme   string   `json:"theme,omitempty"`   // Alias ("light") or a theme name the terminal knows
+	Opacity *float64 `json:"opacity,omitempty"` // 0-1
+	Family  string   `json:"family,omitempty"`
+}
+
+// handleAPITerminal serves GET /api/terminal: the controlled terminal and
+// what it supports.
+func (s *Server) handleAPITerminal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.terminal == nil {
+		http.Error(w, "no terminal control", http.StatusNotFound)
+		return
+	}
+
+	info := TerminalInfo{Name: s.terminal.Name(), Supports: s.terminal.Supports()}
+	for alias := range s.terminalThemes {
+		info.Themes = append(info.Themes, alias)
+	}
+	sort.Strings(info.Themes)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(info)
+}
+
+// handleAPITerminalAction serves POST /api/terminal/{action}: font/bigger,
+// font/smaller, font/reset, theme, opacity and font-family. Changes the
+// terminal can't make are answered with 501.
+func (s *Server) handleAPITerminalAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.terminal == nil {
+		http.Error(w, "no terminal control", http.StatusNotFound)
+		return
+	}
+
+	action := strings.TrimPrefix(r.URL.Path, "/api/terminal/")
+	feature := action
+	switch action {
+	case "font/bigger", "font/smaller", "font/reset":
+		feature = "font"
+	case "theme", "opacity", "font-family":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if !slices.Contains(s.terminal.Supports(), feature) {
+		http.Error(w, s.terminal.Name()+" can't change "+feature, http.StatusNotImplemented)
+		return
+	}
+
+	var req TerminalRequest
+	if feature != "font" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var err error
+	switch action {
+	case "font/bigger":
+		err = s.terminal.Increase()
+	case "font/smaller":
+		err = s.terminal.Decrease()
+	case "font/reset":
+		err = s.terminal.Reset()
+	case "theme":
+		if req.Theme == "" {
+			http.Error(w, "theme is required", http.StatusBadRequest)
+			return
+		}
+		theme := req.Theme
+		if name, ok := s.terminalThemes[theme]; ok {
+			theme = name
+		}
+		err = s.terminal.SetTheme(theme)
+	case "opacity":
+		if req.Opacity == nil || *req.Opacity < 0 || *req.Opacity > 1 {
+			http.Error(w, "opacity must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		err = s.terminal.SetOpacity(*req.Opacity)
+	case "font-family":
+		if req.Family == "" {
+			http.Error(w, "family is required", http.StatusBadRequest)
+			return
+		}
+		err = s.terminal.SetFontFamily(req.Family)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "terminal control failed", "terminal", s.terminal.Name(), "action", action, "error", err)
+		http.Error(w, "terminal control failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	slog.InfoContext(r.Context(), "terminal control", "terminal", s.terminal.Name(), "action", action)
+	w.WriteHeader(http.StatusNoContent)
+}
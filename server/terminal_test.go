@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeTerminal records the last change made through it.
+type fakeTerminal struct {
+	supports []string
+	last     string
+}
+
+func (f *fakeTerminal) Name() string       { return "fake" }
+func (f *fakeTerminal) Increase() error    { f.last = "bigger"; return nil }
+func (f *fakeTerminal) Decrease() error    { f.last = "smaller"; return nil }
+func (f *fakeTerminal) Reset() error       { f.last = "reset"; return nil }
+func (f *fakeTerminal) Supports() []string { return f.supports }
+
+func (f *fakeTerminal) SetTheme(theme string) error {
+	f.last = "theme " + theme
+	return nil
+}
+
+func (f *fakeTerminal) SetOpacity(opacity float64) error {
+	f.last = "opacity"
+	return nil
+}
+
+func (f *fakeTerminal) SetFontFamily(family string) error {
+	f.last = "family " + family
+	return nil
+}
+
+func TestHandleAPITerminalAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		want     int
+		wantLast string
+	}{
+		{"font", "/api/terminal/font/bigger", "", http.StatusNoContent, "bigger"},
+		{"theme alias", "/api/terminal/theme", `{"theme":"light"}`, http.StatusNoContent, "theme Solarized Light"},
+		{"theme name", "/api/terminal/theme", `{"theme":"Dracula"}`, http.StatusNoContent, "theme Dracula"},
+		{"no theme", "/api/terminal/theme", `{}`, http.StatusBadRequest, ""},
+		{"opacity", "/api/terminal/opacity", `{"opacity":0.9}`, http.StatusNoContent, "opacity"},
+		{"opacity out of range", "/api/terminal/opacity", `{"opacity":2}`, http.StatusBadRequest, ""},
+		{"unsupported", "/api/terminal/font-family", `{"family":"Iosevka"}`, http.StatusNotImplemented, ""},
+		{"unknown action", "/api/terminal/blink", `{}`, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := &fakeTerminal{supports: []string{"font", "theme", "opacity"}}
+			s := &Server{terminal: term, terminalThemes: map[string]string{"light": "Solarized Light"}}
+			w := httptest.NewRecorder()
+			s.handleAPITerminalAction(w, httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", w.Code, tt.want, w.Body)
+			}
+			if term.last != tt.wantLast {
+				t.Errorf("change = %q, want %q", term.last, tt.wantLast)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/history"
+	"github.com/noamsto/houston/tmux"
+)
+
+const (
+	defaultTimelineRange = 24 * time.Hour
+	maxTimelineRange     = 30 * 24 * time.Hour
+)
+
+// Timeline is the response of /api/sessions/{name}/timeline: what the
+// session was doing each minute of the range.
+type Timeline struct {
+	Session string           `json:"session"`
+	Host    string           `json:"host,omitempty"`
+	Since   time.Time        `json:"since"`
+	Until   time.Time        `json:"until"`
+	Step    float64          `json:"step_seconds"` // Time between samples
+	Samples []history.Sample `json:"samples"`      // Oldest first; minutes without a sample are left out
+	Minutes map[string]int   `json:"minutes"`      // Sampled minutes per state
+}
+
+// runActivity records the state of every session once a minute, on the
+// minute.
+func (s *Server) runActivity(ctx context.Context) {
+	if !s.waitPrimary(ctx) {
+		return
+	}
+	for {
+		next := time.Now().Truncate(history.ActivityStep).Add(history.ActivityStep)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+		if err := s.activity.Record(sessionActivity(s.buildSessionsData(ctx, sessionsQuery{})), time.Now()); err != nil {
+			slog.Warn("failed to record session activity", "error", err)
+		}
+	}
+}
+
+// sessionActivity returns the state of each session by session key: it
+// needs attention if any window does, else it is working if any window
+// is, else idle.
+func sessionActivity(data SessionsData) map[string]string {
+	states := make(map[string]string)
+	for _, group := range [][]SessionWithWindows{data.NeedsAttention, data.Active, data.Idle} {
+		for _, sess := range group {
+			state := history.StateIdle
+			for _, w := range sess.Windows {
+				if w.State.State == PaneAttention {
+					state = history.StateAttention
+					break
+				}
+				if w.State.State == PaneWorking {
+					state = history.StateWorking
+				}
+			}
+			states[tmux.Pane{Host: sess.Session.Host, Session: sess.Session.Name}.Key()] = state
+		}
+	}
+	return states
+}
+
+// handleSessionTimeline serves GET /api/sessions/{name}/timeline?range=24h
+// (up to 30d) with the session's per-minute activity.
+func (s *Server) handleSessionTimeline(w http.ResponseWriter, r *http.Request, session string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	span := defaultTimelineRange
+	if v := r.URL.Query().Get("range"); v != "" {
+		var err error
+		if span, err = parseTimelineRange(v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	host := r.URL.Query().Get("host")
+	until := time.Now()
+	timeline := Timeline{
+		Session: session,
+		Host:    host,
+		Since:   until.Add(-span),
+		Until:   until,
+		Step:    history.ActivityStep.Seconds(),
+		Minutes: map[string]int{},
+	}
+	key := tmux.Pane{Host: host, Session: session}.Key()
+	samples, err := s.activity.Timeline(key, timeline.Since, timeline.Until)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read session activity", "session", key, "error", err)
+		http.Error(w, "failed to read activity", http.StatusInternalServerError)
+		return
+	}
+	timeline.Samples = samples
+	for _, sample := range samples {
+		timeline.Minutes[sample.State]++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(timeline)
+}
+
+// parseTimelineRange parses a duration ("90m", "24h") or a number of
+// days ("7d"), from a minute up to 30 days.
+func parseTimelineRange(v string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(v, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid range %q", v)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(v); err != nil {
+			return 0, fmt.Errorf("invalid range %q", v)
+		}
+	}
+	if d < history.ActivityStep || d > maxTimelineRange {
+		return 0, fmt.Errorf("range must be between 1m and 30d")
+	}
+	return d, nil
+}
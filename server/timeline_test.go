@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/noamsto/houston/history"
+	"github.com/noamsto/houston/store"
+	"github.com/noamsto/houston/tmux"
+)
+
+func TestSessionActivity(t *testing.T) {
+	window := func(state string) WindowWithStatus {
+		return WindowWithStatus{State: PaneState{State: state}}
+	}
+	data := SessionsData{
+		NeedsAttention: []SessionWithWindows{{Session: tmux.Session{Name: "work"}, Windows: []WindowWithStatus{window(PaneWorking), window(PaneAttention)}}},
+		Active:         []SessionWithWindows{{Session: tmux.Session{Host: "devbox", Name: "api"}, Windows: []WindowWithStatus{window(PaneCooling), window(PaneWorking)}}},
+		Idle:           []SessionWithWindows{{Session: tmux.Session{Name: "notes"}, Windows: []WindowWithStatus{window(PaneCooling)}}},
+	}
+	got := sessionActivity(data)
+	want := map[string]string{"work": history.StateAttention, "devbox|api": history.StateWorking, "notes": history.StateIdle}
+	if len(got) != len(want) {
+		t.Fatalf("sessionActivity() = %v, want %v", got, want)
+	}
+	for key, state := range want {
+		if got[key] != state {
+			t.Errorf("%s = %q, want %q", key, got[key], state)
+		}
+	}
+}
+
+func TestHandleSessionTimeline(t *testing.T) {
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{store: st, activity: history.NewActivityRecorder(st)}
+	now := time.Now()
+	for i, state := range []string{history.StateWorking, history.StateWorking, history.StateIdle} {
+		at := now.Add(time.Duration(i-3) * time.Minute)
+		if err := s.activity.Record(map[string]string{"devbox|work/api": state}, at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.activity.Record(map[string]string{"devbox|work/api": history.StateAttention}, now.Add(-2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	s.handleAPISession(w, httptest.NewRequest("GET", "/api/sessions/work%2Fapi/timeline?range=1h&host=devbox", nil))
+	if w.Code != 200 {
+		t.Fatalf("status = %d: %s", w.Code, w.Body)
+	}
+	var timeline Timeline
+	if err := json.NewDecoder(w.Body).Decode(&timeline); err != nil {
+		t.Fatal(err)
+	}
+	if timeline.Session != "work/api" || timeline.Step != 60 || len(timeline.Samples) != 3 {
+		t.Errorf("timeline = %+v", timeline)
+	}
+	if timeline.Minutes[history.StateWorking] != 2 || timeline.Minutes[history.StateIdle] != 1 || timeline.Minutes[history.StateAttention] != 0 {
+		t.Errorf("minutes = %v, want 2 working and 1 idle", timeline.Minutes)
+	}
+
+	for _, v := range []string{"0s", "soon", "31d", "-1h"} {
+		w := httptest.NewRecorder()
+		s.handleAPISession(w, httptest.NewRequest("GET", "/api/sessions/work/timeline?range="+v, nil))
+		if w.Code != 400 {
+			t.Errorf("range=%s: status = %d, want 400", v, w.Code)
+		}
+	}
+	if d, err := parseTimelineRange("7d"); err != nil || d != 7*24*time.Hour {
+		t.Errorf("parseTimelineRange(7d) = %v, %v", d, err)
+	}
+}
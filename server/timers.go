@@ -0,0 +1,145 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default grace periods for deciding what counts as active.
+const (
+	// defaultActivityWindow is how recently a non-agent process must have
+	// written output to count as working.
+	defaultActivityWindow = 30 * time.Second
+	// defaultActiveTTL is how long a session stays in "Active" after its
+	// last working window went quiet.
+	defaultActiveTTL = 2 * time.Minute
+)
+
+// Default number of output lines in a window's preview.
+const (
+	defaultPreviewLines          = 15
+	defaultAttentionPreviewLines = 25 // Windows needing attention show more
+)
+
+// TimerRule overrides the grace periods and preview sizes for sessions
+// whose name matches Pattern (a glob). Zero values keep the default.
+type TimerRule struct {
+	Pattern               string
+	ActivityWindow        time.Duration
+	ActiveTTL             time.Duration
+	Pinned                bool // ttl:never: never demoted from Active once it has worked
+	PreviewLines          int
+	AttentionPreviewLines int
+}
+
+// ParseTimerRule parses "pattern=activity:5m,ttl:30m,preview:20,attention-preview:40";
+// any setting may be left out. "ttl:never" keeps the session Active.
+func ParseTimerRule(s string) (TimerRule, error) {
+	pattern, spec, ok := strings.Cut(s, "=")
+	if !ok || pattern == "" || spec == "" {
+		return TimerRule{}, fmt.Errorf("session timers %q: want pattern=activity:5m,ttl:30m", s)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return TimerRule{}, fmt.Errorf("session timers %q: bad pattern: %w", s, err)
+	}
+
+	rule := TimerRule{Pattern: pattern}
+	for _, part := range strings.Split(spec, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+		switch name {
+		case "activity", "ttl":
+			if name == "ttl" && value == "never" {
+				rule.Pinned = true
+				continue
+			}
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return TimerRule{}, fmt.Errorf("session timers %q: invalid duration %q", s, value)
+			}
+			if name == "activity" {
+				rule.ActivityWindow = d
+			} else {
+				rule.ActiveTTL = d
+			}
+		case "preview", "attention-preview":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return TimerRule{}, fmt.Errorf("session timers %q: invalid line count %q", s, value)
+			}
+			if name == "preview" {
+				rule.PreviewLines = n
+			} else {
+				rule.AttentionPreviewLines = n
+			}
+		default:
+			return TimerRule{}, fmt.Errorf("session timers %q: unknown setting %q (want activity, ttl, preview or attention-preview)", s, name)
+		}
+	}
+	return rule, nil
+}
+
+// EffectiveTimers are the grace periods and preview sizes applied to a
+// window, reported so categorization can be tuned.
+type EffectiveTimers struct {
+	ActivityWindow        float64 `json:"activity_window_seconds"` // Recent output that keeps a non-agent process working
+	ActiveTTL             float64 `json:"active_ttl_seconds"`      // How long the session stays Active after work stops
+	Pinned                bool    `json:"pinned,omitempty"`        // Stays Active once it has worked (ActiveTTL unused)
+	PreviewLines          int     `json:"preview_lines"`           // Output lines in the preview
+	AttentionPreviewLines int     `json:"attention_preview_lines"` // Output lines in the preview while it needs attention
+	Rule                  string  `json:"rule,omitempty"`          // Session pattern that set them (empty: defaults)
+}
+
+func (t EffectiveTimers) activityWindow() time.Duration {
+	return time.Duration(t.ActivityWindow * float64(time.Second))
+}
+
+func (t EffectiveTimers) activeTTL() time.Duration {
+	if t.Pinned {
+		return math.MaxInt64
+	}
+	return time.Duration(t.ActiveTTL * float64(time.Second))
+}
+
+// previewLines returns how many output lines a window's preview shows.
+func (t EffectiveTimers) previewLines(attention bool) int {
+	if attention {
+		return t.AttentionPreviewLines
+	}
+	return t.PreviewLines
+}
+
+// timersFor returns the grace periods for a session: the first matching
+// rule's timers, falling back to the server defaults.
+func (s *Server) timersFor(session string) EffectiveTimers {
+	t := EffectiveTimers{
+		ActivityWindow:        s.activityWindow.Seconds(),
+		ActiveTTL:             s.activeTTL.Seconds(),
+		PreviewLines:          s.previewLines,
+		AttentionPreviewLines: s.attentionPreviewLines,
+	}
+	for _, rule := range s.timerRules {
+		if ok, _ := path.Match(rule.Pattern, session); !ok {
+			continue
+		}
+		t.Rule = rule.Pattern
+		if rule.ActivityWindow > 0 {
+			t.ActivityWindow = rule.ActivityWindow.Seconds()
+		}
+		if rule.ActiveTTL > 0 {
+			t.ActiveTTL = rule.ActiveTTL.Seconds()
+		}
+		t.Pinned = rule.Pinned
+		if rule.PreviewLines > 0 {
+			t.PreviewLines = rule.PreviewLines
+		}
+		if rule.AttentionPreviewLines > 0 {
+			t.AttentionPreviewLines = rule.AttentionPreviewLines
+		}
+		break
+	}
+	return t
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimerRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    TimerRule
+		wantErr bool
+	}{
+		{in: "build-*=activity:5m,ttl:30m", want: TimerRule{Pattern: "build-*", ActivityWindow: 5 * time.Minute, ActiveTTL: 30 * time.Minute}},
+		{in: "ci=ttl:10m", want: TimerRule{Pattern: "ci", ActiveTTL: 10 * time.Minute}},
+		{in: "prod-*=ttl:never,preview:40,attention-preview:60", want: TimerRule{Pattern: "prod-*", Pinned: true, PreviewLines: 40, AttentionPreviewLines: 60}},
+		{in: "ci=preview:0", wantErr: true},
+		{in: "ci=preview:many", wantErr: true},
+		{in: "ci", wantErr: true},
+		{in: "=ttl:10m", wantErr: true},
+		{in: "ci=ttl:soon", wantErr: true},
+		{in: "ci=ttl:-1m", wantErr: true},
+		{in: "ci=grace:1m", wantErr: true},
+		{in: "[=ttl:1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimerRule(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTimerRule(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseTimerRule(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTimersFor(t *testing.T) {
+	s := &Server{
+		activityWindow:        defaultActivityWindow,
+		activeTTL:             defaultActiveTTL,
+		previewLines:          defaultPreviewLines,
+		attentionPreviewLines: defaultAttentionPreviewLines,
+		timerRules: []TimerRule{
+			{Pattern: "build-*", ActiveTTL: 30 * time.Minute},
+			{Pattern: "prod-incident", Pinned: true, PreviewLines: 40},
+			{Pattern: "*", ActivityWindow: time.Minute},
+		},
+	}
+
+	got := s.timersFor("build-web")
+	want := EffectiveTimers{ActivityWindow: 30, ActiveTTL: 1800, PreviewLines: 15, AttentionPreviewLines: 25, Rule: "build-*"}
+	if got != want {
+		t.Errorf("timersFor(build-web) = %+v, want %+v (first match only)", got, want)
+	}
+	if got := s.timersFor("api"); got.ActivityWindow != 60 || got.ActiveTTL != 120 || got.Rule != "*" {
+		t.Errorf("timersFor(api) = %+v, want activity 60s, ttl 120s from *", got)
+	}
+
+	got = s.timersFor("prod-incident")
+	if !got.Pinned || got.activeTTL() < 24*time.Hour || got.previewLines(false) != 40 || got.previewLines(true) != 25 {
+		t.Errorf("timersFor(prod-incident) = %+v, want pinned with 40 preview lines", got)
+	}
+
+	s.timerRules = nil
+	if got := s.timersFor("api"); got.activityWindow() != defaultActivityWindow || got.activeTTL() != defaultActiveTTL || got.Rule != "" {
+		t.Errorf("timersFor(api) without rules = %+v, want defaults", got)
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/opencode"
+	"github.com/noamsto/houston/tmux"
+)
+
+// PaneTodos is the response of GET /api/pane/{target}/todos.
+type PaneTodos struct {
+	Pane      tmux.Pane     `json:"pane"`
+	Todos     []agents.Todo `json:"todos"`
+	Completed int           `json:"completed"`
+	Total     int           `json:"total"` // Cancelled items don't count
+}
+
+// paneTodos returns the task list of the agent in a pane: from the agent's
+// files, or the linked session of an OpenCode TUI. Files are local, so
+// remote Claude panes have none.
+func (s *Server) paneTodos(agent agents.Agent, pane tmux.Pane, info *tmux.PaneInfo, link *OpenCodeLink) []agents.Todo {
+	if link != nil {
+		state, ok := s.ocManager.CurrentSession(link.Server)
+		if !ok || state.Session.ID != link.SessionID {
+			return nil
+		}
+		return openCodeTodos(state.Todos)
+	}
+	provider, ok := agent.(agents.TodoProvider)
+	if !ok || pane.Host != "" || info == nil || info.Path == "" {
+		return nil
+	}
+	todos, _ := provider.Todos(info.Path)
+	return todos
+}
+
+func openCodeTodos(todos []opencode.Todo) []agents.Todo {
+	if len(todos) == 0 {
+		return nil
+	}
+	result := make([]agents.Todo, 0, len(todos))
+	for _, t := range todos {
+		result = append(result, agents.Todo{Content: t.Content, Status: t.Status})
+	}
+	return result
+}
+
+// handlePaneTodos serves GET /api/pane/{target}/todos, the task list of
+// the pane's agent with its progress.
+func (s *Server) handlePaneTodos(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	info, ok := s.lookupPaneInfo(pane)
+	if !ok {
+		http.Error(w, "pane not found", http.StatusNotFound)
+		return
+	}
+
+	output, _ := s.client(pane.Host).CapturePane(pane, 100)
+	agent := s.registry.Detect(pane.Key(), info.Command, output)
+	result := PaneTodos{Pane: pane, Todos: s.paneTodos(agent, pane, &info, s.openCodeLink(pane, &info))}
+	if result.Todos == nil {
+		result.Todos = []agents.Todo{}
+	}
+	result.Completed, result.Total = agents.TodoProgress(result.Todos)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/noamsto/houston/internal/logging"
+)
+
+var tracer = otel.Tracer("github.com/noamsto/houston/server")
+
+// traceMiddleware traces each API request as a server span, which the
+// tmux commands, agent file reads and OpenCode calls made for it join.
+// Streams are left out: a span lasting as long as the page stays open
+// says nothing, and each sessions refresh they push is traced on its own.
+func traceMiddleware(api *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isStream(r.URL) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		route := spanRoute(api, r)
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("houston.request_id", logging.RequestID(r.Context())),
+			))
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		status := statusOrOK(sw.status)
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// spanRoute names a request's route for its span: the API pattern it
+// matches, with a pane's target and action told apart
+// ("/api/pane/{target}/send").
+func spanRoute(api *http.ServeMux, r *http.Request) string {
+	_, path, ok := cutAPIVersion(r.URL.Path)
+	if !ok {
+		path = r.URL.Path
+	}
+	if strings.HasPrefix(path, "/api/pane/") {
+		route := "/api/pane/{target}"
+		if action := path[strings.LastIndex(path, "/")+1:]; paneActions[action] {
+			route += "/" + action
+		}
+		return route
+	}
+	_, pattern := api.Handler(&http.Request{Method: r.Method, URL: &url.URL{Path: path}})
+	if pattern == "" {
+		return "/api/*" // Not found; its path could be anything
+	}
+	return pattern
+}
+
+// isStream reports whether u is a WebSocket or server-sent events stream.
+func isStream(u *url.URL) bool {
+	return strings.HasSuffix(u.Path, "/ws") || strings.HasSuffix(u.Path, "/events") ||
+		strings.HasSuffix(u.Path, "/replay") || u.Query().Get("stream") == "1"
+}
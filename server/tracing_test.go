@@ -0,0 +1,71 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestSpanRoute(t *testing.T) {
+	s := &Server{compact: newCompactRule(0, "")}
+	api := s.newAPIMux()
+	tests := []struct {
+		method, path, want string
+	}{
+		{"GET", "/api/sessions", "/api/sessions"},
+		{"GET", "/api/v1/sessions", "/api/sessions"},
+		{"DELETE", "/api/macros/deploy", "/api/macros/"},
+		{"GET", "/api/pane/work:1.0", "/api/pane/{target}"},
+		{"POST", "/api/pane/my%2Fapp:2.1/send", "/api/pane/{target}/send"},
+		{"GET", "/api/nope/123", "/api/*"},
+	}
+	for _, tt := range tests {
+		if got := spanRoute(api, httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("spanRoute(%s %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTraceMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	s := &Server{compact: newCompactRule(0, "")}
+	api := s.newAPIMux()
+	h := requestIDMiddleware(traceMiddleware(api, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracer.Start(r.Context(), "work")
+		span.End()
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/pane/work:1.0/send", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/sessions?stream=1", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("%d spans, want the request's, its work's and the stream's work", len(spans))
+	}
+	work, request := spans[0], spans[1]
+	if request.Name() != "POST /api/pane/{target}/send" || work.Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("request span %q, work's parent %v", request.Name(), work.Parent().SpanID())
+	}
+	var status int64
+	for _, kv := range request.Attributes() {
+		if kv.Key == attribute.Key("http.response.status_code") {
+			status = kv.Value.AsInt64()
+		}
+	}
+	if status != http.StatusInternalServerError || request.Status().Description != "Internal Server Error" {
+		t.Errorf("status %d, span status %+v", status, request.Status())
+	}
+	if spans[2].Parent().IsValid() {
+		t.Error("a stream's work joined a request span")
+	}
+}
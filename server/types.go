@@ -1,22 +1,42 @@
 package server
 
 import (
+	"time"
+
 	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/agents/claude"
+	"github.com/noamsto/houston/github"
 	"github.com/noamsto/houston/opencode"
 	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/project"
 	"github.com/noamsto/houston/tmux"
 )
 
 // WindowWithStatus combines window info with its parse result
 type WindowWithStatus struct {
-	Window         tmux.Window      `json:"window"`
-	Pane           tmux.Pane        `json:"pane"`
-	ParseResult    parser.Result    `json:"parse_result"`
-	Preview        []string         `json:"preview"`
-	NeedsAttention bool             `json:"needs_attention"`
-	Branch         string           `json:"branch"`
-	Process        string           `json:"process"`
-	AgentType      agents.AgentType `json:"agent_type"`
+	Window         tmux.Window          `json:"window"`
+	Pane           tmux.Pane            `json:"pane"`
+	ParseResult    parser.Result        `json:"parse_result"`
+	Preview        []string             `json:"preview"`
+	NeedsAttention bool                 `json:"needs_attention"`
+	Branch         string               `json:"branch"`
+	Process        string               `json:"process"`
+	AgentType      agents.AgentType     `json:"agent_type"`
+	Timers         EffectiveTimers      `json:"timers"`                    // Grace periods used to categorize it
+	AgentManual    bool                 `json:"agent_manual,omitempty"`    // Agent type pinned by the user
+	Relaunch       *RelaunchInfo        `json:"relaunch,omitempty"`        // Restored by tmux-resurrect, agent not running
+	Queued         int                  `json:"queued,omitempty"`          // Prompts waiting for this window's agent to finish
+	AttentionSince *time.Time           `json:"attention_since,omitempty"` // When the window started needing attention
+	WaitingMinutes int                  `json:"waiting_minutes,omitempty"` // How long the prompt has been waiting
+	Reminder       int                  `json:"reminder,omitempty"`        // Reminder intervals passed (-remind); bumps re-notify
+	MCPDown        []string             `json:"mcp_down,omitempty"`        // Required MCP servers that disconnected
+	OpenCode       *OpenCodeLink        `json:"opencode,omitempty"`        // OpenCode session of the TUI running here
+	Subagents      []claude.Subagent    `json:"subagents,omitempty"`       // Claude Code Task subagents, recent first
+	ClaudeStatus   *claude.ClaudeStatus `json:"claude_status,omitempty"`   // Model, context usage and cost from Claude's status bar
+	Todos          []agents.Todo        `json:"todos,omitempty"`           // The agent's task list
+	Tags           []string             `json:"tags,omitempty"`            // Labels set with /api/pane/{target}/tags
+	PR             *github.PR           `json:"pr,omitempty"`              // Open pull request of Branch (-github-prs)
+	State          PaneState            `json:"state"`                     // Settled state, which categorization uses
 }
 
 // SessionWithWindows holds a session and all its windows with status
@@ -25,6 +45,8 @@ type SessionWithWindows struct {
 	Windows        []WindowWithStatus `json:"windows"`
 	AttentionCount int                `json:"attention_count"`
 	HasWorking     bool               `json:"has_working"`
+	Pinned         bool               `json:"pinned,omitempty"`  // Pinned by the user: sorted first, never Idle
+	Project        *project.Info      `json:"project,omitempty"` // Project of the directory most windows are in
 }
 
 // SessionsData holds data for the sessions list
@@ -32,10 +54,12 @@ type SessionsData struct {
 	NeedsAttention []SessionWithWindows `json:"needs_attention"`
 	Active         []SessionWithWindows `json:"active"`
 	Idle           []SessionWithWindows `json:"idle"`
+	Hidden         []tmux.Session       `json:"hidden"` // Sessions the user hid, left out of the sections
 }
 
 // AgentStripItem represents one agent in the strip bar
 type AgentStripItem struct {
+	Host      string           `json:"host,omitempty"`
 	Session   string           `json:"session"`
 	Window    int              `json:"window"`
 	Pane      int              `json:"pane"`
@@ -56,6 +80,10 @@ type PaneData struct {
 	PaneHeight  int              `json:"pane_height"`
 	Suggestion  string           `json:"suggestion"`
 	StripItems  []AgentStripItem `json:"strip_items"`
+	TmuxMode    tmux.ModeState   `json:"tmux_mode"` // Copy-mode and scroll position
+	AgentType   agents.AgentType `json:"agent_type"`
+	AgentManual bool             `json:"agent_manual,omitempty"` // Agent type pinned by the user
+	MCP         *MCPHealth       `json:"mcp,omitempty"`          // Claude panes only
 }
 
 // OpenCodeSession represents an OpenCode session for display.
@@ -68,8 +96,9 @@ type OpenCodeSession struct {
 
 // OpenCodeData holds OpenCode sessions for display.
 type OpenCodeData struct {
-	NeedsAttention []OpenCodeSession  `json:"needs_attention"`
-	Active         []OpenCodeSession  `json:"active"`
-	Idle           []OpenCodeSession  `json:"idle"`
-	Servers        []*opencode.Server `json:"servers"`
+	NeedsAttention []OpenCodeSession    `json:"needs_attention"`
+	Active         []OpenCodeSession    `json:"active"`
+	Idle           []OpenCodeSession    `json:"idle"`
+	Servers        []*opencode.Server   `json:"servers"`
+	Freshness      []opencode.Freshness `json:"freshness"` // Cache age per server
 }
@@ -0,0 +1,402 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/tmux"
+)
+
+// uploadsDir is the default upload directory under the data directory.
+const uploadsDir = "uploads"
+
+// defaultUploadTTL is how long an uploaded image is kept (zero
+// Config.UploadTTL).
+const defaultUploadTTL = 24 * time.Hour
+
+// uploadSweepInterval is how often expired uploads are removed.
+const uploadSweepInterval = 10 * time.Minute
+
+// maxUploadName bounds the length of an upload's file name.
+const maxUploadName = 100
+
+// imageTypes maps the content types uploads accept to their file
+// extensions, the first being the one added to names without one.
+var imageTypes = map[string][]string{
+	"image/png":  {".png"},
+	"image/jpeg": {".jpg", ".jpeg"},
+	"image/gif":  {".gif"},
+	"image/webp": {".webp"},
+}
+
+// errUnsupportedUpload is returned for uploads that aren't an image type
+// agents read.
+var errUnsupportedUpload = errors.New("unsupported image type")
+
+// Upload is an image received by /api/pane/{target}/send-with-images.
+type Upload struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Type    string    `json:"type"`
+	Size    int64     `json:"size"`
+	Path    string    `json:"path"` // What the agent was sent
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+// SendImagesRequest is the body of POST /api/pane/{target}/send-with-images.
+type SendImagesRequest struct {
+	Text   string      `json:"text"`
+	Images []SentImage `json:"images"`
+}
+
+// SentImage is an image in a SendImagesRequest.
+type SentImage struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // Ignored: the type is sniffed from the data
+	Data string `json:"data"` // base64 encoded
+}
+
+// uploads stores uploaded images, each as <dir>/<id>/<name>: the random ID
+// keeps paths unguessable while the agent still sees the original name.
+// The files are the only state, so uploads outlive restarts until they
+// expire.
+type uploads struct {
+	dir string
+	ttl time.Duration
+}
+
+func newUploads(dir string, ttl time.Duration) (*uploads, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create upload directory: %w", err)
+	}
+	return &uploads{dir: dir, ttl: ttl}, nil
+}
+
+// save validates data as an image and writes it under a new ID.
+func (u *uploads) save(name string, data []byte) (Upload, error) {
+	typ := http.DetectContentType(data)
+	exts, ok := imageTypes[typ]
+	if !ok {
+		return Upload{}, fmt.Errorf("%w: %s", errUnsupportedUpload, typ)
+	}
+
+	id := newPromptID()
+	dir := filepath.Join(u.dir, id)
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		return Upload{}, err
+	}
+	path := filepath.Join(dir, uploadName(name, exts))
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		_ = os.RemoveAll(dir)
+		return Upload{}, err
+	}
+	return u.get(id)
+}
+
+// get returns the upload with id, or an error wrapping os.ErrNotExist.
+func (u *uploads) get(id string) (Upload, error) {
+	if !validUploadID(id) {
+		return Upload{}, os.ErrNotExist
+	}
+	dir := filepath.Join(u.dir, id)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Upload{}, err
+	}
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		typ := "application/octet-stream"
+		for t, exts := range imageTypes {
+			if slices.Contains(exts, strings.ToLower(filepath.Ext(e.Name()))) {
+				typ = t
+			}
+		}
+		return Upload{
+			ID:      id,
+			Name:    e.Name(),
+			Type:    typ,
+			Size:    info.Size(),
+			Path:    filepath.Join(dir, e.Name()),
+			Created: info.ModTime(),
+			Expires: info.ModTime().Add(u.ttl),
+		}, nil
+	}
+	return Upload{}, fmt.Errorf("upload %s: %w", id, os.ErrNotExist)
+}
+
+// list returns the uploads that haven't expired, newest first.
+func (u *uploads) list() ([]Upload, error) {
+	entries, err := os.ReadDir(u.dir)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	list := []Upload{}
+	for _, e := range entries {
+		up, err := u.get(e.Name())
+		if err != nil || now.After(up.Expires) {
+			continue
+		}
+		list = append(list, up)
+	}
+	slices.SortFunc(list, func(a, b Upload) int { return b.Created.Compare(a.Created) })
+	return list, nil
+}
+
+// remove deletes the upload with id.
+func (u *uploads) remove(id string) error {
+	if _, err := u.get(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(u.dir, id))
+}
+
+// sweep removes uploads that expired by now, and directories left empty
+// or half-written. It returns how many it removed.
+func (u *uploads) sweep(now time.Time) int {
+	entries, err := os.ReadDir(u.dir)
+	if err != nil {
+		slog.Warn("read upload directory failed", "dir", u.dir, "error", err)
+		return 0
+	}
+	removed := 0
+	for _, e := range entries {
+		if !e.IsDir() || !validUploadID(e.Name()) {
+			continue
+		}
+		up, err := u.get(e.Name())
+		if err == nil && !now.After(up.Expires) {
+			continue
+		}
+		if err != nil {
+			// Give a save in progress time to write its file
+			if info, err := e.Info(); err != nil || now.Sub(info.ModTime()) < time.Minute {
+				continue
+			}
+		}
+		if err := os.RemoveAll(filepath.Join(u.dir, e.Name())); err != nil {
+			slog.Warn("remove expired upload failed", "id", e.Name(), "error", err)
+			continue
+		}
+		removed++
+	}
+	return removed
+}
+
+// validUploadID reports whether id has the form newPromptID returns, so it
+// can't name anything outside the upload directory.
+func validUploadID(id string) bool {
+	if len(id) != 12 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// safeFileName makes name safe to store and to send an agent as part of a
+// space-separated prompt: its base name, with anything but letters,
+// digits, dots, dashes and underscores replaced, and no leading dots. It
+// returns "" if nothing is left.
+func safeFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, filepath.Base(name))
+	name = strings.TrimLeft(name, ".")
+	if len(name) > maxUploadName {
+		ext := filepath.Ext(name)
+		if len(ext) >= maxUploadName {
+			ext = ""
+		}
+		name = name[:maxUploadName-len(ext)] + ext
+	}
+	return name
+}
+
+// uploadName is safeFileName for an image, ending in one of exts.
+func uploadName(name string, exts []string) string {
+	name = safeFileName(name)
+	if !slices.Contains(exts, strings.ToLower(filepath.Ext(name))) {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+		if name == "" {
+			name = "image"
+		}
+		name = safeFileName(name + exts[0])
+	}
+	return name
+}
+
+// runUploads removes expired uploads until ctx is done.
+func (s *Server) runUploads(ctx context.Context) {
+	if s.uploads == nil || !s.waitPrimary(ctx) {
+		return
+	}
+	ticker := time.NewTicker(uploadSweepInterval)
+	defer ticker.Stop()
+	for {
+		if n := s.uploads.sweep(time.Now()); n > 0 {
+			slog.Info("removed expired uploads", "count", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// handlePaneSendWithImages serves POST /api/pane/{target}/send-with-images:
+// it saves the images to the upload directory and sends their paths, then
+// text, to the pane as one prompt line. The response lists the uploads.
+func (s *Server) handlePaneSendWithImages(w http.ResponseWriter, r *http.Request, pane tmux.Pane) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if pane.Host != "" {
+		// Images are saved to local files the remote agent can't read
+		http.Error(w, "images can't be sent to remote panes", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 50*1024*1024) // 50MB limit
+
+	var req SendImagesRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.ErrorContext(r.Context(), "failed to decode images request", "error", err)
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Images) == 0 {
+		http.Error(w, "no images provided", http.StatusBadRequest)
+		return
+	}
+
+	// Decode and check every image before saving any
+	images := make([][]byte, len(req.Images))
+	for i, img := range req.Images {
+		data, err := base64.StdEncoding.DecodeString(img.Data)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid image data at index %d", i), http.StatusBadRequest)
+			return
+		}
+		if typ := http.DetectContentType(data); imageTypes[typ] == nil {
+			http.Error(w, fmt.Sprintf("image %d: %v: %s", i, errUnsupportedUpload, typ), http.StatusUnsupportedMediaType)
+			return
+		}
+		images[i] = data
+	}
+
+	saved := make([]Upload, 0, len(images))
+	paths := make([]string, 0, len(images))
+	for i, data := range images {
+		up, err := s.uploads.save(req.Images[i].Name, data)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to save image", "error", err, "index", i)
+			for _, up := range saved {
+				_ = s.uploads.remove(up.ID)
+			}
+			http.Error(w, "failed to save image", http.StatusInternalServerError)
+			return
+		}
+		saved = append(saved, up)
+		paths = append(paths, up.Path)
+	}
+
+	// Send all image paths and text to the agent as a single prompt line
+	// Format: image1 image2 image3 text + Enter
+	message := strings.Join(paths, " ")
+	if req.Text != "" {
+		message = fmt.Sprintf("%s %s", message, req.Text)
+	}
+
+	slog.InfoContext(r.Context(), "send images with text", "pane", pane.Target(), "count", len(saved), "text", req.Text)
+	auditDetail(r, message)
+
+	if err := s.sendText(r.Context(), pane, message, true); err != nil {
+		slog.ErrorContext(r.Context(), "failed to send images", "error", err)
+		http.Error(w, "failed to send: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.responses.Answered(windowKey(pane), time.Now())
+	slog.DebugContext(r.Context(), "send images success", "count", len(saved))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(saved)
+}
+
+// handleAPIUploads serves GET /api/uploads: images sent with
+// send-with-images that haven't expired, newest first.
+func (s *Server) handleAPIUploads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	list, err := s.uploads.list()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// handleAPIUpload serves GET /api/uploads/{id}, the image itself, and
+// DELETE, which removes it before it expires.
+func (s *Server) handleAPIUpload(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/uploads/")
+	up, err := s.uploads.get(id)
+	if err != nil {
+		http.Error(w, "upload not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		f, err := os.Open(up.Path)
+		if err != nil {
+			http.Error(w, "upload not found", http.StatusNotFound)
+			return
+		}
+		defer f.Close()
+		w.Header().Set("Content-Type", up.Type)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", up.Name))
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+		http.ServeContent(w, r, up.Name, up.Created, f)
+
+	case http.MethodDelete:
+		if err := s.uploads.remove(id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		auditDetail(r, up.Name)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadName(t *testing.T) {
+	png, jpeg := imageTypes["image/png"], imageTypes["image/jpeg"]
+	tests := []struct {
+		name string
+		exts []string
+		want string
+	}{
+		{"shot.png", png, "shot.png"},
+		{"Screen Shot 1.PNG", png, "Screen_Shot_1.PNG"},
+		{"../../etc/passwd", png, "passwd.png"},
+		{"photo.jpeg", jpeg, "photo.jpeg"},
+		{"photo.png", jpeg, "photo.jpg"},
+		{"", png, "image.png"},
+		{".hidden", png, "hidden.png"},
+		{"$(rm -rf ~);.png", png, "__rm_-rf____.png"},
+		{strings.Repeat("a", 200) + ".png", png, strings.Repeat("a", maxUploadName-4) + ".png"},
+	}
+	for _, tt := range tests {
+		if got := uploadName(tt.name, tt.exts); got != tt.want {
+			t.Errorf("uploadName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploads(t *testing.T) {
+	u, err := newUploads(filepath.Join(t.TempDir(), "uploads"), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := u.save("notes.txt", []byte("just text")); !errors.Is(err, errUnsupportedUpload) {
+		t.Fatalf("save text: err = %v, want errUnsupportedUpload", err)
+	}
+
+	data := testPNG(t)
+	up, err := u.save("my shot", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up.Name != "my_shot.png" || up.Type != "image/png" || up.Size != int64(len(data)) {
+		t.Errorf("upload = %+v", up)
+	}
+	if up.Path != filepath.Join(u.dir, up.ID, up.Name) {
+		t.Errorf("path = %q", up.Path)
+	}
+
+	list, err := u.list()
+	if err != nil || len(list) != 1 || list[0].ID != up.ID {
+		t.Fatalf("list = %+v, %v", list, err)
+	}
+	for _, id := range []string{"", "..", "../uploads", "0123456789ab"} {
+		if _, err := u.get(id); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("get(%q): err = %v, want not exist", id, err)
+		}
+	}
+
+	// Not expired yet
+	if n := u.sweep(time.Now()); n != 0 {
+		t.Errorf("sweep removed %d, want 0", n)
+	}
+	if n := u.sweep(time.Now().Add(2 * time.Hour)); n != 1 {
+		t.Errorf("sweep removed %d, want 1", n)
+	}
+	if _, err := os.Stat(filepath.Dir(up.Path)); !os.IsNotExist(err) {
+		t.Errorf("upload directory still exists: %v", err)
+	}
+}
+
+func TestHandleAPIUpload(t *testing.T) {
+	u, err := newUploads(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := testPNG(t)
+	up, err := u.save("shot.png", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{uploads: u}
+
+	w := httptest.NewRecorder()
+	s.handleAPIUpload(w, httptest.NewRequest("GET", "/api/uploads/"+up.ID, nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" || !bytes.Equal(w.Body.Bytes(), data) {
+		t.Errorf("GET = %d %q (%d bytes)", w.Code, w.Header().Get("Content-Type"), w.Body.Len())
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPIUpload(w, httptest.NewRequest("DELETE", "/api/uploads/"+up.ID, nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.handleAPIUpload(w, httptest.NewRequest("GET", "/api/uploads/"+up.ID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,402 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+)
+
+// View is a named, saved dashboard: a filter plus grouping and sort order.
+type View struct {
+	Name    string `json:"name"`
+	Filter  string `json:"filter"`   // e.g. "branch~=^feat/ AND state!=idle"
+	GroupBy string `json:"group_by"` // session (default), branch, agent, state, none
+	Sort    string `json:"sort"`     // urgency (default), activity, name
+}
+
+// ViewGroup is one group of windows in an evaluated view.
+type ViewGroup struct {
+	Name    string             `json:"name"`
+	Windows []WindowWithStatus `json:"windows"`
+}
+
+// ViewData is the result of evaluating a view against current sessions.
+type ViewData struct {
+	View   View        `json:"view"`
+	Groups []ViewGroup `json:"groups"`
+}
+
+var viewNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// viewsDocument is the store document holding saved views.
+const viewsDocument = "views"
+
+// condition is a single "field op value" comparison in a filter.
+type condition struct {
+	field string
+	op    string // "=", "!=", "~=", "!~"
+	value string
+	re    *regexp.Regexp
+}
+
+// Filter is a parsed view filter: conditions joined by AND, groups joined by OR.
+// AND binds tighter than OR. An empty filter matches everything.
+type Filter struct {
+	any [][]condition
+}
+
+var filterFields = map[string]bool{
+	"session": true,
+	"window":  true,
+	"branch":  true,
+	"agent":   true,
+	"state":   true,
+	"process": true,
+	"tag":     true,
+}
+
+var conditionPattern = regexp.MustCompile(`^\s*([a-z_]+)\s*(!=|~=|!~|=)\s*(.*?)\s*$`)
+var orPattern = regexp.MustCompile(`(?i)\s+OR\s+`)
+var andPattern = regexp.MustCompile(`(?i)\s+AND\s+`)
+
+// ParseFilter parses expressions like "agent=claude AND state!=idle OR session~=^prod".
+// Supported operators are = and != (exact, case-insensitive) and ~= and !~ (regex).
+func ParseFilter(expr string) (Filter, error) {
+	var f Filter
+	if strings.TrimSpace(expr) == "" {
+		return f, nil
+	}
+
+	for _, group := range orPattern.Split(strings.TrimSpace(expr), -1) {
+		var conds []condition
+		for _, part := range andPattern.Split(group, -1) {
+			m := conditionPattern.FindStringSubmatch(part)
+			if m == nil {
+				return Filter{}, fmt.Errorf("invalid condition %q", strings.TrimSpace(part))
+			}
+			c := condition{field: m[1], op: m[2], value: strings.Trim(m[3], `"'`)}
+			if !filterFields[c.field] {
+				return Filter{}, fmt.Errorf("unknown filter field %q", c.field)
+			}
+			if c.op == "~=" || c.op == "!~" {
+				re, err := regexp.Compile(c.value)
+				if err != nil {
+					return Filter{}, fmt.Errorf("invalid regex in %q: %w", strings.TrimSpace(part), err)
+				}
+				c.re = re
+			}
+			conds = append(conds, c)
+		}
+		f.any = append(f.any, conds)
+	}
+	return f, nil
+}
+
+// Match reports whether a window satisfies the filter.
+func (f Filter) Match(w WindowWithStatus) bool {
+	if len(f.any) == 0 {
+		return true
+	}
+	for _, conds := range f.any {
+		ok := true
+		for _, c := range conds {
+			if !c.match(w) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c condition) match(w WindowWithStatus) bool {
+	values := windowFieldValues(w, c.field)
+	switch c.op {
+	case "=", "!=":
+		found := false
+		for _, v := range values {
+			if strings.EqualFold(v, c.value) {
+				found = true
+				break
+			}
+		}
+		return found == (c.op == "=")
+	default:
+		found := false
+		for _, v := range values {
+			if c.re.MatchString(v) {
+				found = true
+				break
+			}
+		}
+		return found == (c.op == "~=")
+	}
+}
+
+// windowFieldValues returns the values a filter field compares against.
+func windowFieldValues(w WindowWithStatus, field string) []string {
+	switch field {
+	case "session":
+		return []string{w.Pane.Session}
+	case "window":
+		return []string{w.Window.Name}
+	case "branch":
+		return []string{w.Branch}
+	case "agent":
+		values := []string{string(w.AgentType)}
+		if w.AgentType == agents.AgentClaudeCode {
+			values = append(values, "claude")
+		}
+		return values
+	case "state":
+		return []string{windowState(w)}
+	case "process":
+		return []string{w.Process}
+	case "tag":
+		return w.Tags
+	}
+	return nil
+}
+
+// windowState returns the coarse state used by filters and views:
+// attention, working, done, or idle. Working is the window's settled state
+// when it has one.
+func windowState(w WindowWithStatus) string {
+	if w.NeedsAttention {
+		return "attention"
+	}
+	switch {
+	case w.State.State == PaneWorking:
+		return "working"
+	case w.State.State != "":
+		// Settled but not working: done or idle, per the capture
+	case w.ParseResult.Type == parser.TypeWorking:
+		return "working"
+	}
+	switch w.ParseResult.Type {
+	case parser.TypeDone:
+		return "done"
+	default:
+		return "idle"
+	}
+}
+
+// allWindows flattens every window in the sessions data, keeping category order.
+func (d SessionsData) allWindows() []WindowWithStatus {
+	var windows []WindowWithStatus
+	for _, group := range [][]SessionWithWindows{d.NeedsAttention, d.Active, d.Idle} {
+		for _, sess := range group {
+			windows = append(windows, sess.Windows...)
+		}
+	}
+	return windows
+}
+
+// evaluateView applies a view's filter, grouping and sort to sessions data.
+func evaluateView(v View, data SessionsData) (ViewData, error) {
+	filter, err := ParseFilter(v.Filter)
+	if err != nil {
+		return ViewData{}, err
+	}
+
+	var matched []WindowWithStatus
+	for _, w := range data.allWindows() {
+		if filter.Match(w) {
+			matched = append(matched, w)
+		}
+	}
+
+	less := windowLess(v.Sort)
+	sort.SliceStable(matched, func(i, j int) bool { return less(matched[i], matched[j]) })
+
+	// Groups are ordered by their first (best-sorted) window.
+	result := ViewData{View: v, Groups: []ViewGroup{}}
+	index := make(map[string]int)
+	for _, w := range matched {
+		key := viewGroupKey(v.GroupBy, w)
+		i, ok := index[key]
+		if !ok {
+			i = len(result.Groups)
+			index[key] = i
+			result.Groups = append(result.Groups, ViewGroup{Name: key})
+		}
+		result.Groups[i].Windows = append(result.Groups[i].Windows, w)
+	}
+	return result, nil
+}
+
+func viewGroupKey(groupBy string, w WindowWithStatus) string {
+	switch groupBy {
+	case "none":
+		return "all"
+	case "branch":
+		if w.Branch == "" {
+			return "(no branch)"
+		}
+		return w.Branch
+	case "agent":
+		return string(w.AgentType)
+	case "state":
+		return windowState(w)
+	default:
+		return w.Pane.Session
+	}
+}
+
+func windowLess(order string) func(a, b WindowWithStatus) bool {
+	switch order {
+	case "activity":
+		return func(a, b WindowWithStatus) bool {
+			return a.Window.LastActivity.After(b.Window.LastActivity)
+		}
+	case "name":
+		return func(a, b WindowWithStatus) bool {
+			if a.Pane.Session != b.Pane.Session {
+				return a.Pane.Session < b.Pane.Session
+			}
+			return a.Window.Index < b.Window.Index
+		}
+	default:
+		return func(a, b WindowWithStatus) bool {
+			return windowActivityScore(a) > windowActivityScore(b)
+		}
+	}
+}
+
+func validateView(v View) error {
+	if !viewNamePattern.MatchString(v.Name) {
+		return fmt.Errorf("view name must match %s", viewNamePattern)
+	}
+	switch v.GroupBy {
+	case "", "session", "branch", "agent", "state", "none":
+	default:
+		return fmt.Errorf("unknown group_by %q", v.GroupBy)
+	}
+	switch v.Sort {
+	case "", "urgency", "activity", "name":
+	default:
+		return fmt.Errorf("unknown sort %q", v.Sort)
+	}
+	_, err := ParseFilter(v.Filter)
+	return err
+}
+
+// loadViews reads saved views from the store.
+func (s *Server) loadViews() {
+	views := make(map[string]View)
+	if err := s.store.Load(viewsDocument, &views); err != nil {
+		slog.Warn("failed to load views", "error", err)
+	}
+	s.viewsMu.Lock()
+	s.views = views
+	s.viewsMu.Unlock()
+}
+
+// handleAPIViews serves GET (list) and POST (create/update) on /api/views.
+func (s *Server) handleAPIViews(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.viewsMu.RLock()
+		views := make([]View, 0, len(s.views))
+		for _, v := range s.views {
+			views = append(views, v)
+		}
+		s.viewsMu.RUnlock()
+		sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(views)
+	case http.MethodPost:
+		var v View
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		s.saveView(w, v)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAPIView serves /api/views/{name}: GET evaluates the view,
+// PUT replaces its definition, DELETE removes it.
+func (s *Server) handleAPIView(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/api/views/")
+
+	switch r.Method {
+	case http.MethodGet:
+		s.viewsMu.RLock()
+		v, ok := s.views[name]
+		s.viewsMu.RUnlock()
+		if !ok {
+			http.Error(w, "view not found", http.StatusNotFound)
+			return
+		}
+
+		data, err := evaluateView(v, s.buildSessionsData(r.Context(), sessionsQuery{}))
+		if err != nil {
+			http.Error(w, "invalid view: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(data)
+	case http.MethodPut:
+		var v View
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		v.Name = name
+		s.saveView(w, v)
+	case http.MethodDelete:
+		s.viewsMu.Lock()
+		if _, ok := s.views[name]; !ok {
+			s.viewsMu.Unlock()
+			http.Error(w, "view not found", http.StatusNotFound)
+			return
+		}
+		delete(s.views, name)
+		err := s.store.Save(viewsDocument, s.views)
+		s.viewsMu.Unlock()
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to save views", "error", err)
+			http.Error(w, "failed to save views", http.StatusInternalServerError)
+			return
+		}
+		slog.InfoContext(r.Context(), "view deleted", "name", name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) saveView(w http.ResponseWriter, v View) {
+	if err := validateView(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.viewsMu.Lock()
+	s.views[v.Name] = v
+	err := s.store.Save(viewsDocument, s.views)
+	s.viewsMu.Unlock()
+	if err != nil {
+		slog.Error("failed to save views", "error", err)
+		http.Error(w, "failed to save views", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("view saved", "name", v.Name, "filter", v.Filter)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
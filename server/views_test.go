@@ -0,0 +1,95 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/noamsto/houston/agents"
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/tmux"
+)
+
+func testWindow(session, branch string, agent agents.AgentType, t parser.ResultType, attention bool) WindowWithStatus {
+	return WindowWithStatus{
+		Pane:           tmux.Pane{Session: session},
+		Branch:         branch,
+		AgentType:      agent,
+		ParseResult:    parser.Result{Type: t},
+		NeedsAttention: attention,
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	working := testWindow("client-a", "main", agents.AgentClaudeCode, parser.TypeWorking, false)
+	idle := testWindow("home", "feat/x", agents.AgentAmp, parser.TypeIdle, false)
+	attn := testWindow("client-b", "main", agents.AgentClaudeCode, parser.TypeChoice, true)
+
+	tests := []struct {
+		expr string
+		want []bool // working, idle, attn
+	}{
+		{"", []bool{true, true, true}},
+		{"state!=idle", []bool{true, false, true}},
+		{"agent=claude", []bool{true, false, true}},
+		{"session~=^client- AND state=attention", []bool{false, false, true}},
+		{"branch=feat/x OR state=working", []bool{true, true, false}},
+		{"session!~client", []bool{false, true, false}},
+		{"AGENT=amp", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			if tt.want == nil {
+				if err == nil {
+					t.Fatalf("ParseFilter(%q) expected error", tt.expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error: %v", tt.expr, err)
+			}
+			for i, w := range []WindowWithStatus{working, idle, attn} {
+				if got := f.Match(w); got != tt.want[i] {
+					t.Errorf("window %d: Match() = %v, want %v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{"nope=1", "state", "session~=(["} {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("ParseFilter(%q) expected error", expr)
+		}
+	}
+}
+
+func TestEvaluateViewGroupsByBranch(t *testing.T) {
+	data := SessionsData{
+		NeedsAttention: []SessionWithWindows{{Windows: []WindowWithStatus{
+			testWindow("a", "main", agents.AgentClaudeCode, parser.TypeChoice, true),
+		}}},
+		Active: []SessionWithWindows{{Windows: []WindowWithStatus{
+			testWindow("b", "feat", agents.AgentClaudeCode, parser.TypeWorking, false),
+			testWindow("c", "main", agents.AgentAmp, parser.TypeWorking, false),
+		}}},
+		Idle: []SessionWithWindows{{Windows: []WindowWithStatus{
+			testWindow("d", "main", agents.AgentGeneric, parser.TypeIdle, false),
+		}}},
+	}
+
+	got, err := evaluateView(View{Name: "urgent", Filter: "state!=idle", GroupBy: "branch"}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2: %+v", len(got.Groups), got.Groups)
+	}
+	if got.Groups[0].Name != "main" || len(got.Groups[0].Windows) != 2 {
+		t.Errorf("first group = %s (%d windows), want main (2)", got.Groups[0].Name, len(got.Groups[0].Windows))
+	}
+	if !got.Groups[0].Windows[0].NeedsAttention {
+		t.Error("expected attention window sorted first")
+	}
+}
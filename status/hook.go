@@ -0,0 +1,102 @@
+// status/hook.go
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/noamsto/houston/internal/textutil"
+)
+
+// HookPayload is the body accepted by the HTTP hook receiver. It is either a
+// pre-digested status (Session/Status set) or a raw Claude Code hook event
+// (HookEventName set), optionally with the tmux session supplied separately.
+type HookPayload struct {
+	// Digested form (same fields as status files)
+	Session          string `json:"session"`
+	TmuxSession      string `json:"tmux_session"`
+	Status           string `json:"status"`
+	Message          string `json:"message"`
+	Tool             string `json:"tool"`
+	NotificationType string `json:"notification_type"`
+	Timestamp        int64  `json:"timestamp"`
+
+	// Raw Claude Code hook fields
+	HookEventName string         `json:"hook_event_name"`
+	ToolName      string         `json:"tool_name"`
+	ToolInput     map[string]any `json:"tool_input"`
+}
+
+// ErrIgnoredHook is returned for hook events that don't change status.
+var ErrIgnoredHook = errors.New("hook event does not affect status")
+
+// ParseHookPayload converts a hook request body into a session status.
+// session overrides the session named in the body (e.g. from a query param).
+func ParseHookPayload(data []byte, session string) (SessionStatus, error) {
+	var p HookPayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return SessionStatus{}, fmt.Errorf("invalid hook payload: %w", err)
+	}
+
+	if session == "" {
+		session = p.Session
+	}
+	if session == "" {
+		session = p.TmuxSession
+	}
+	if session == "" {
+		return SessionStatus{}, errors.New("hook payload has no tmux session")
+	}
+
+	st := SessionStatus{
+		Session:   session,
+		Message:   p.Message,
+		Tool:      p.Tool,
+		UpdatedAt: time.Now(),
+	}
+	if p.Timestamp > 0 {
+		st.UpdatedAt = time.Unix(p.Timestamp, 0)
+	}
+
+	if p.Status != "" {
+		st.Status = parseStatus(p.Status)
+		return st, nil
+	}
+
+	// Same mapping as scripts/claude-hook.sh
+	switch p.HookEventName {
+	case "Notification":
+		switch p.NotificationType {
+		case "permission_prompt":
+			st.Status = StatusPermission
+		case "idle_prompt":
+			st.Status = StatusWaiting
+		default:
+			st.Status = StatusUnknown
+		}
+	case "Stop", "SubagentStop":
+		st.Status = StatusIdle
+		st.Message = "Agent stopped"
+	case "PreToolUse", "UserPromptSubmit":
+		st.Status = StatusWorking
+		st.Tool = p.ToolName
+		st.Message = toolDescription(p.ToolInput)
+	case "":
+		return SessionStatus{}, errors.New("hook payload has neither status nor hook_event_name")
+	default:
+		return SessionStatus{}, ErrIgnoredHook
+	}
+	return st, nil
+}
+
+func toolDescription(input map[string]any) string {
+	for _, key := range []string{"description", "command"} {
+		if v, ok := input[key].(string); ok && v != "" {
+			return strings.TrimSpace(textutil.Truncate(v, 100))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+// status/hook_test.go
+package status
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseHookPayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		session     string
+		wantSession string
+		wantStatus  Status
+		wantTool    string
+		wantErr     bool
+		wantIgnored bool
+	}{
+		{
+			name:        "digested status",
+			body:        `{"session":"work","status":"permission","message":"Allow Bash?"}`,
+			wantSession: "work",
+			wantStatus:  StatusPermission,
+		},
+		{
+			name:        "session from query overrides body",
+			body:        `{"tmux_session":"other","status":"idle"}`,
+			session:     "work",
+			wantSession: "work",
+			wantStatus:  StatusIdle,
+		},
+		{
+			name:        "raw permission notification",
+			body:        `{"hook_event_name":"Notification","notification_type":"permission_prompt","message":"Claude needs permission"}`,
+			session:     "work",
+			wantSession: "work",
+			wantStatus:  StatusPermission,
+		},
+		{
+			name:        "raw idle notification",
+			body:        `{"hook_event_name":"Notification","notification_type":"idle_prompt"}`,
+			session:     "work",
+			wantSession: "work",
+			wantStatus:  StatusWaiting,
+		},
+		{
+			name:        "raw pre tool use",
+			body:        `{"hook_event_name":"PreToolUse","tool_name":"Bash","tool_input":{"command":"go test ./..."}}`,
+			session:     "work",
+			wantSession: "work",
+			wantStatus:  StatusWorking,
+			wantTool:    "Bash",
+		},
+		{
+			name:        "raw stop",
+			body:        `{"hook_event_name":"Stop"}`,
+			session:     "work",
+			wantSession: "work",
+			wantStatus:  StatusIdle,
+		},
+		{
+			name:        "unrelated event",
+			body:        `{"hook_event_name":"SessionStart"}`,
+			session:     "work",
+			wantErr:     true,
+			wantIgnored: true,
+		},
+		{
+			name:    "missing session",
+			body:    `{"status":"idle"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			body:    `{`,
+			session: "work",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ParseHookPayload([]byte(tt.body), tt.session)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHookPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrIgnoredHook) != tt.wantIgnored {
+				t.Errorf("ParseHookPayload() ignored = %v, want %v", errors.Is(err, ErrIgnoredHook), tt.wantIgnored)
+			}
+			if tt.wantErr {
+				return
+			}
+			if st.Session != tt.wantSession {
+				t.Errorf("Session = %q, want %q", st.Session, tt.wantSession)
+			}
+			if st.Status != tt.wantStatus {
+				t.Errorf("Status = %v, want %v", st.Status, tt.wantStatus)
+			}
+			if st.Tool != tt.wantTool {
+				t.Errorf("Tool = %q, want %q", st.Tool, tt.wantTool)
+			}
+		})
+	}
+}
+
+func TestToolDescription(t *testing.T) {
+	tests := []struct {
+		input map[string]any
+		want  string
+	}{
+		{map[string]any{"description": "Run tests", "command": "go test ./..."}, "Run tests"},
+		{map[string]any{"command": "  ls  "}, "ls"},
+		{map[string]any{"command": strings.Repeat("x", 120)}, strings.Repeat("x", 100)},
+		{map[string]any{"description": "x" + strings.Repeat("é", 60)}, "x" + strings.Repeat("é", 49)},
+		{map[string]any{}, ""},
+	}
+
+	for _, tt := range tests {
+		got := toolDescription(tt.input)
+		if got != tt.want {
+			t.Errorf("toolDescription(%v) = %q, want %q", tt.input, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("toolDescription(%v) = %q, not valid UTF-8", tt.input, got)
+		}
+	}
+}
+
+func TestWatcherUpdate(t *testing.T) {
+	w := NewWatcher(t.TempDir())
+	events, unsubscribe := w.Subscribe()
+	defer unsubscribe()
+
+	w.Update(SessionStatus{Session: "remote", Status: StatusPermission})
+
+	st, ok := w.Get("remote")
+	if !ok || st.Status != StatusPermission {
+		t.Errorf("Get(remote) = %v, %v, want permission status", st, ok)
+	}
+	if _, ok := w.GetAll()["remote"]; !ok {
+		t.Error("GetAll() missing pushed status")
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Session != "remote" {
+			t.Errorf("event session = %q, want remote", ev.Session)
+		}
+	default:
+		t.Error("Update() did not publish an event")
+	}
+}
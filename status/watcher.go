@@ -2,11 +2,16 @@
 package status
 
 import (
+	"context"
 	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/noamsto/houston/internal/fswatch"
 )
 
 type Status int
@@ -40,12 +45,142 @@ func (s SessionStatus) IsFresh(d time.Duration) bool {
 	return time.Since(s.UpdatedAt) < d
 }
 
+// Event is emitted when a session's hook status changes.
+type Event struct {
+	Session string
+	Status  SessionStatus
+	Removed bool // Status file was deleted
+}
+
+// Watcher tracks hook status files in a directory. Until Start is called it
+// reads the directory on every call; once started it serves an in-memory
+// cache kept current by filesystem notifications.
 type Watcher struct {
 	dir string
+
+	mu      sync.RWMutex
+	started bool
+	cache   map[string]SessionStatus // session name -> status
+
+	subsMu sync.Mutex
+	subs   map[chan Event]struct{}
 }
 
 func NewWatcher(dir string) *Watcher {
-	return &Watcher{dir: dir}
+	return &Watcher{
+		dir:   dir,
+		cache: make(map[string]SessionStatus),
+		subs:  make(map[chan Event]struct{}),
+	}
+}
+
+// Start loads the status directory into the cache and watches it for
+// changes until ctx is cancelled.
+func (w *Watcher) Start(ctx context.Context) error {
+	if err := os.MkdirAll(w.dir, 0o755); err != nil {
+		return err
+	}
+
+	fw, err := fswatch.WatchDir(w.dir)
+	if err != nil {
+		return err
+	}
+
+	// Load after the watch is in place so no change slips between the two.
+	initial := w.readAll()
+	w.mu.Lock()
+	w.cache = initial
+	w.started = true
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		_ = fw.Close()
+	}()
+
+	go func() {
+		for ev := range fw.Events {
+			w.handleFileEvent(ev)
+		}
+		w.mu.Lock()
+		w.started = false
+		w.mu.Unlock()
+	}()
+
+	return nil
+}
+
+func (w *Watcher) handleFileEvent(ev fswatch.Event) {
+	if strings.HasPrefix(ev.Name, ".") || strings.HasSuffix(ev.Name, ".tmp") {
+		return
+	}
+	session := filenameToSession(ev.Name)
+
+	if ev.Removed {
+		w.mu.Lock()
+		old, ok := w.cache[session]
+		delete(w.cache, session)
+		w.mu.Unlock()
+		if ok {
+			w.publish(Event{Session: session, Status: old, Removed: true})
+		}
+		return
+	}
+
+	st, err := readStatusFile(filepath.Join(w.dir, ev.Name))
+	if err != nil {
+		slog.Debug("status file unreadable", "file", ev.Name, "error", err)
+		return
+	}
+	if st.Session == "" {
+		st.Session = session
+	}
+
+	w.mu.Lock()
+	w.cache[session] = st
+	w.mu.Unlock()
+	w.publish(Event{Session: session, Status: st})
+}
+
+// Update records a status pushed directly (e.g. by the HTTP hook receiver)
+// rather than read from a file, and notifies subscribers.
+func (w *Watcher) Update(st SessionStatus) {
+	if st.UpdatedAt.IsZero() {
+		st.UpdatedAt = time.Now()
+	}
+	w.mu.Lock()
+	w.cache[st.Session] = st
+	w.mu.Unlock()
+	w.publish(Event{Session: st.Session, Status: st})
+}
+
+// Subscribe returns a channel of status changes and a function to
+// unsubscribe. Slow subscribers miss events rather than block the watcher.
+func (w *Watcher) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 16)
+	w.subsMu.Lock()
+	w.subs[ch] = struct{}{}
+	w.subsMu.Unlock()
+
+	return ch, func() {
+		w.subsMu.Lock()
+		if _, ok := w.subs[ch]; ok {
+			delete(w.subs, ch)
+			close(ch)
+		}
+		w.subsMu.Unlock()
+	}
+}
+
+func (w *Watcher) publish(ev Event) {
+	w.subsMu.Lock()
+	defer w.subsMu.Unlock()
+	for ch := range w.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
 }
 
 // statusFile represents the JSON structure from the hook script
@@ -130,7 +265,31 @@ func sessionToFilename(session string) string {
 	return strings.ReplaceAll(session, "/", "%") + ".json"
 }
 
+// GetAll returns the status of every session with a status file.
 func (w *Watcher) GetAll() map[string]SessionStatus {
+	w.mu.RLock()
+	if w.started {
+		result := make(map[string]SessionStatus, len(w.cache))
+		for k, v := range w.cache {
+			result[k] = v
+		}
+		w.mu.RUnlock()
+		return result
+	}
+	defer w.mu.RUnlock()
+
+	// Not watching: read files, but keep newer pushed statuses
+	result := w.readAll()
+	for k, v := range w.cache {
+		if cur, ok := result[k]; !ok || v.UpdatedAt.After(cur.UpdatedAt) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// readAll reads every status file in the directory.
+func (w *Watcher) readAll() map[string]SessionStatus {
 	result := make(map[string]SessionStatus)
 
 	entries, err := os.ReadDir(w.dir)
@@ -160,7 +319,20 @@ func (w *Watcher) GetAll() map[string]SessionStatus {
 	return result
 }
 
+// Get returns the status of a single session.
 func (w *Watcher) Get(session string) (SessionStatus, bool) {
+	w.mu.RLock()
+	if w.started {
+		st, ok := w.cache[session]
+		w.mu.RUnlock()
+		return st, ok
+	}
+	pushed, hasPushed := w.cache[session]
+	w.mu.RUnlock()
+	if hasPushed {
+		return pushed, true
+	}
+
 	// Try JSON file first
 	jsonPath := filepath.Join(w.dir, sessionToFilename(session))
 	if status, err := readStatusFile(jsonPath); err == nil {
@@ -2,6 +2,7 @@
 package status
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -146,3 +147,38 @@ func TestWatcherGetAll(t *testing.T) {
 		t.Errorf("expected session2 to be working")
 	}
 }
+
+func TestWatcherStartAndSubscribe(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "existing.json"), []byte(`{"status":"idle","timestamp":1}`), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := NewWatcher(dir)
+	if err := w.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := w.Get("existing"); !ok {
+		t.Fatal("expected existing status to be loaded on start")
+	}
+
+	events, unsubscribe := w.Subscribe()
+	defer unsubscribe()
+
+	data, _ := json.Marshal(statusFile{TmuxSession: "mono/main", Status: "permission", Timestamp: time.Now().Unix()})
+	_ = os.WriteFile(filepath.Join(dir, sessionToFilename("mono/main")), data, 0644)
+
+	select {
+	case ev := <-events:
+		if ev.Session != "mono/main" || ev.Status.Status != StatusPermission {
+			t.Errorf("got event %+v, want mono/main permission", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for status event")
+	}
+
+	if st, ok := w.GetAll()["mono/main"]; !ok || st.Status != StatusPermission {
+		t.Errorf("cache not updated: %+v", w.GetAll())
+	}
+}
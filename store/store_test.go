@@ -0,0 +1,111 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := map[string][]string{"work": {"a", "b"}}
+	if err := s.Save("views", in); err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string][]string
+	if err := s.Load("views", &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out["work"]) != 2 || out["work"][1] != "b" {
+		t.Errorf("Load() = %v, want %v", out, in)
+	}
+
+	// No temp files left behind
+	entries, _ := os.ReadDir(s.Dir())
+	if len(entries) != 1 {
+		t.Errorf("expected 1 file in data dir, got %d", len(entries))
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := map[string]int{"keep": 1}
+	if err := s.Load("missing", &out); err != nil {
+		t.Fatalf("Load() of missing document returned error: %v", err)
+	}
+	if out["keep"] != 1 {
+		t.Errorf("Load() of missing document modified target: %v", out)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Save("doc", map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("doc"); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	out := map[string]int{}
+	if err := s.Load("doc", &out); err != nil || len(out) != 0 {
+		t.Errorf("Load() after Remove() = %v, %v; want empty", out, err)
+	}
+	if err := s.Remove("doc"); err != nil {
+		t.Errorf("Remove() of missing document returned error: %v", err)
+	}
+}
+
+func TestAppendReadLog(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if err := s.Append("events", map[string]int{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A torn write at the end is skipped
+	f, err := os.OpenFile(filepath.Join(s.Dir(), "events.jsonl"), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"n":`)
+	_ = f.Close()
+
+	var got []int
+	err = s.ReadLog("events", func(raw json.RawMessage) error {
+		var e map[string]int
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return err
+		}
+		got = append(got, e["n"])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("ReadLog() entries = %v, want [1 2 3]", got)
+	}
+
+	if err := s.ReadLog("missing", func(json.RawMessage) error { return nil }); err != nil {
+		t.Errorf("ReadLog() of missing log returned error: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// Features a TerminalController can support.
+const (
+	FeatureFont       = "font"
+	FeatureTheme      = "theme"
+	FeatureOpacity    = "opacity"
+	FeatureFontFamily = "font-family"
+)
+
+// ErrUnsupported is returned for appearance changes the terminal (or the
+// way houston reaches it) can't make.
+var ErrUnsupported = errors.New("not supported by this terminal")
+
+// TerminalController controls the appearance of the terminal houston runs
+// in: font size, plus color theme, background opacity and font family
+// where the terminal allows changing them from outside.
+type TerminalController interface {
+	FontController
+	// SetTheme switches the color theme. What a theme name means depends on
+	// the terminal: a kitty colors file, an iTerm2 color preset, a Windows
+	// Terminal color scheme.
+	SetTheme(theme string) error
+	// SetOpacity sets the background opacity, from 0 (clear) to 1.
+	SetOpacity(opacity float64) error
+	SetFontFamily(family string) error
+	// Supports lists the features that work, starting with FeatureFont.
+	Supports() []string
+}
+
+// fontOnly implements the appearance methods of terminals that only
+// support font size.
+type fontOnly struct{}
+
+func (fontOnly) SetTheme(string) error      { return ErrUnsupported }
+func (fontOnly) SetOpacity(float64) error   { return ErrUnsupported }
+func (fontOnly) SetFontFamily(string) error { return ErrUnsupported }
+func (fontOnly) Supports() []string         { return []string{FeatureFont} }
+
+// checkOpacity validates an opacity before it is handed to a terminal.
+func checkOpacity(opacity float64) error {
+	if opacity < 0 || opacity > 1 {
+		return fmt.Errorf("opacity %v out of range 0-1", opacity)
+	}
+	return nil
+}
+
+// Kitty: theme is a colors .conf file, applied to all windows; opacity
+// needs dynamic_background_opacity. Fonts are fixed at startup.
+
+func (k *KittyController) SetTheme(theme string) error {
+	return exec.Command("kitty", "@", "--to", "unix:"+k.socket, "set-colors", "--all", theme).Run()
+}
+
+func (k *KittyController) SetOpacity(opacity float64) error {
+	if err := checkOpacity(opacity); err != nil {
+		return err
+	}
+	return exec.Command("kitty", "@", "--to", "unix:"+k.socket, "set-background-opacity", "--all",
+		strconv.FormatFloat(opacity, 'f', 2, 64)).Run()
+}
+
+func (k *KittyController) SetFontFamily(string) error { return ErrUnsupported }
+
+func (k *KittyController) Supports() []string {
+	return []string{FeatureFont, FeatureTheme, FeatureOpacity}
+}
+
+// Alacritty: opacity and font family through IPC config overrides.
+// Themes are imports in its config file, which IPC can't change.
+
+func (a *AlacrittyController) SetTheme(string) error { return ErrUnsupported }
+
+func (a *AlacrittyController) SetOpacity(opacity float64) error {
+	if err := checkOpacity(opacity); err != nil {
+		return err
+	}
+	return exec.Command("alacritty", "msg", "config", "window.opacity="+strconv.FormatFloat(opacity, 'f', 2, 64)).Run()
+}
+
+func (a *AlacrittyController) SetFontFamily(family string) error {
+	return exec.Command("alacritty", "msg", "config", "font.normal.family="+strconv.Quote(family)).Run()
+}
+
+func (a *AlacrittyController) Supports() []string {
+	return []string{FeatureFont, FeatureOpacity, FeatureFontFamily}
+}
@@ -2,13 +2,17 @@
 package terminal
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
-// FontController controls terminal font size.
+// FontController controls terminal font size, which every terminal
+// controller supports (see TerminalController).
 type FontController interface {
 	Increase() error
 	Decrease() error
@@ -16,13 +20,33 @@ type FontController interface {
 	Name() string // Returns terminal name for display
 }
 
-// NewFontController auto-detects the terminal and returns appropriate controller.
-func NewFontController() FontController {
+// Controllers are the terminals that can be chosen instead of detected
+// (the -terminal flag).
+var Controllers = []string{"kitty", "alacritty", "wezterm", "ghostty", "iterm2", "windows-terminal", "custom", "none"}
+
+// NewController auto-detects the terminal and returns appropriate controller.
+func NewController() TerminalController {
 	// Check for custom command first
 	if cmd := os.Getenv("HOUSTON_FONT_CMD"); cmd != "" {
 		return &CustomController{cmd: cmd}
 	}
 
+	// Try Ghostty (detected from the environment it sets, so before the
+	// socket and CLI probes, which can find other terminals' leftovers)
+	if g := newGhosttyController(); g != nil {
+		return g
+	}
+
+	// Try iTerm2
+	if isITerm2() && runtime.GOOS == "darwin" {
+		return newITerm2Controller()
+	}
+
+	// Try Windows Terminal (WT_SESSION, natively or from WSL)
+	if settings := findWindowsTerminalSettings(); settings != "" {
+		return &WindowsTerminalController{settings: settings}
+	}
+
 	// Try kitty
 	if socket := findKittySocket(); socket != "" {
 		return &KittyController{socket: socket}
@@ -41,6 +65,46 @@ func NewFontController() FontController {
 	return &NoopController{}
 }
 
+// NewNamedController returns the controller for a terminal from
+// Controllers, skipping detection. It fails when the terminal can't be
+// reached from here.
+func NewNamedController(name string) (TerminalController, error) {
+	switch name {
+	case "kitty":
+		if socket := findKittySocket(); socket != "" {
+			return &KittyController{socket: socket}, nil
+		}
+		return nil, errors.New("kitty: no remote control socket (set allow_remote_control and listen_on)")
+	case "alacritty":
+		return &AlacrittyController{}, nil
+	case "wezterm":
+		return &WeztermController{}, nil
+	case "ghostty":
+		if send := ghosttyKeySender(); send != nil {
+			return &GhosttyController{send: send}, nil
+		}
+		return nil, errors.New("ghostty: needs osascript, xdotool (X11) or wtype (Wayland)")
+	case "iterm2":
+		if runtime.GOOS != "darwin" {
+			return nil, errors.New("iterm2: only on macOS")
+		}
+		return newITerm2Controller(), nil
+	case "windows-terminal":
+		if settings := findWindowsTerminalSettings(); settings != "" {
+			return &WindowsTerminalController{settings: settings}, nil
+		}
+		return nil, errors.New("windows-terminal: settings.json not found (is WT_SESSION set?)")
+	case "custom":
+		if cmd := os.Getenv("HOUSTON_FONT_CMD"); cmd != "" {
+			return &CustomController{cmd: cmd}, nil
+		}
+		return nil, errors.New("custom: HOUSTON_FONT_CMD is not set")
+	case "none":
+		return &NoopController{}, nil
+	}
+	return nil, fmt.Errorf("unknown terminal %q (want one of %s)", name, strings.Join(Controllers, ", "))
+}
+
 // KittyController controls kitty terminal font size.
 type KittyController struct {
 	socket string
@@ -101,7 +165,7 @@ func hasAlacrittyMsg() bool {
 }
 
 // WeztermController controls wezterm font size.
-type WeztermController struct{}
+type WeztermController struct{ fontOnly }
 
 func (w *WeztermController) Name() string { return "wezterm" }
 
@@ -130,6 +194,7 @@ func hasWeztermCLI() bool {
 // CustomController uses a user-provided command.
 // The command is called with "+1", "-1", or "0" as argument.
 type CustomController struct {
+	fontOnly
 	cmd string
 }
 
@@ -154,3 +219,8 @@ func (n *NoopController) Name() string    { return "" }
 func (n *NoopController) Increase() error { return nil }
 func (n *NoopController) Decrease() error { return nil }
 func (n *NoopController) Reset() error    { return nil }
+
+func (n *NoopController) SetTheme(string) error      { return ErrUnsupported }
+func (n *NoopController) SetOpacity(float64) error   { return ErrUnsupported }
+func (n *NoopController) SetFontFamily(string) error { return ErrUnsupported }
+func (n *NoopController) Supports() []string         { return nil }
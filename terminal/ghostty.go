@@ -0,0 +1,89 @@
+package terminal
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// ghosttyX11Class is Ghostty's window class on X11 (and XWayland).
+const ghosttyX11Class = "com.mitchellh.ghostty"
+
+// GhosttyController controls Ghostty font size. Ghostty has no IPC for
+// it, so the controller presses Ghostty's default font keybindings
+// (ctrl/cmd with =, - and 0) in its window, through osascript on macOS and
+// xdotool or wtype on Linux. Custom keybindings need HOUSTON_FONT_CMD.
+type GhosttyController struct {
+	fontOnly
+	send func(key string) error // Presses the font keybinding ending in key
+}
+
+func (g *GhosttyController) Name() string { return "ghostty" }
+
+func (g *GhosttyController) Increase() error { return g.send("=") }
+
+func (g *GhosttyController) Decrease() error { return g.send("-") }
+
+func (g *GhosttyController) Reset() error { return g.send("0") }
+
+// canRaise reports whether Raise can work: Wayland doesn't let other
+// programs focus a window.
+func (g *GhosttyController) canRaise() bool {
+	return runtime.GOOS == "darwin" || (os.Getenv("DISPLAY") != "" && hasCommand("xdotool"))
+}
+
+// Raise brings Ghostty's window to the front.
+func (g *GhosttyController) Raise() error {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("osascript", "-e", `tell application "Ghostty" to activate`).Run()
+	}
+	return exec.Command("xdotool", "search", "--class", ghosttyX11Class, "windowactivate").Run()
+}
+
+// newGhosttyController returns a controller when houston runs inside
+// Ghostty (GHOSTTY_RESOURCES_DIR is set in its shells) and a tool to press
+// keys is available.
+func newGhosttyController() *GhosttyController {
+	if os.Getenv("GHOSTTY_RESOURCES_DIR") == "" && os.Getenv("TERM_PROGRAM") != "ghostty" {
+		return nil
+	}
+	if send := ghosttyKeySender(); send != nil {
+		return &GhosttyController{send: send}
+	}
+	return nil
+}
+
+// ghosttyKeySender picks how to press keys in Ghostty on this system.
+func ghosttyKeySender() func(key string) error {
+	switch {
+	case runtime.GOOS == "darwin":
+		return func(key string) error {
+			return exec.Command("osascript",
+				"-e", `tell application "Ghostty" to activate`,
+				"-e", `tell application "System Events" to keystroke "`+key+`" using {command down}`,
+			).Run()
+		}
+	case os.Getenv("DISPLAY") != "" && hasCommand("xdotool"):
+		// Also reaches XWayland windows; activates Ghostty first since
+		// GTK ignores synthetic key events sent to unfocused windows
+		return func(key string) error {
+			return exec.Command("xdotool", "search", "--class", ghosttyX11Class,
+				"windowactivate", "--sync", "key", "--clearmodifiers", "ctrl+"+xdotoolKeys[key]).Run()
+		}
+	case os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wtype"):
+		// Wayland has no way to address another window: the keys go to
+		// whichever window is focused, normally Ghostty on the desk
+		return func(key string) error {
+			return exec.Command("wtype", "-M", "ctrl", "-k", xdotoolKeys[key], "-m", "ctrl").Run()
+		}
+	}
+	return nil
+}
+
+// xdotoolKeys are X keysym names, also used by wtype.
+var xdotoolKeys = map[string]string{"=": "equal", "-": "minus", "0": "0"}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
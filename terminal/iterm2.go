@@ -0,0 +1,122 @@
+package terminal
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// iterm2Script changes iTerm2's current session through its Python API.
+// argv: "font" with a size step (0 restores the profile's font), "theme"
+// with a color preset, "opacity" with 0-1, or "family" with a font name.
+const iterm2Script = `
+import sys, iterm2
+
+async def main(connection):
+    app = await iterm2.async_get_app(connection)
+    session = app.current_terminal_window.current_tab.current_session
+    what, arg = sys.argv[1], sys.argv[2]
+    profile = await session.async_get_profile()
+    name, size = profile.normal_font.rsplit(" ", 1)
+    change = iterm2.LocalWriteOnlyProfile()
+    if what == "font" and int(arg) == 0:
+        original = await iterm2.Profile.async_get(connection, [profile.original_guid])
+        change.set_normal_font(original[0].normal_font if original else profile.normal_font)
+    elif what == "font":
+        change.set_normal_font("%s %d" % (name, max(int(float(size)) + int(arg), 4)))
+    elif what == "family":
+        change.set_normal_font("%s %s" % (arg, size))
+    elif what == "opacity":
+        change.set_transparency(1 - float(arg))
+    elif what == "theme":
+        preset = await iterm2.ColorPreset.async_get(connection, arg)
+        if preset is None:
+            sys.exit("no color preset named " + arg)
+        # The session's profile: only this session changes
+        await profile.async_set_color_preset(preset)
+        return
+    await session.async_set_profile_properties(change)
+
+iterm2.run_until_complete(main)
+`
+
+// ITerm2Controller controls iTerm2. With the Python API enabled
+// (Settings > General > Magic) and the iterm2 module installed it changes
+// the current session's font, colors and transparency; otherwise it can
+// only press iTerm2's Make Text Bigger/Smaller keys (cmd with =, - and 0)
+// through osascript.
+type ITerm2Controller struct {
+	python bool // Use the Python API instead of keystrokes
+}
+
+func (t *ITerm2Controller) Name() string { return "iterm2" }
+
+func (t *ITerm2Controller) Increase() error { return t.step("+1", "=") }
+
+func (t *ITerm2Controller) Decrease() error { return t.step("-1", "-") }
+
+func (t *ITerm2Controller) Reset() error { return t.step("0", "0") }
+
+func (t *ITerm2Controller) step(arg, key string) error {
+	if t.python {
+		return t.script("font", arg)
+	}
+	return exec.Command("osascript",
+		"-e", `tell application "iTerm2" to activate`,
+		"-e", `tell application "System Events" to keystroke "`+key+`" using {command down}`,
+	).Run()
+}
+
+// Raise brings iTerm2's window to the front.
+func (t *ITerm2Controller) Raise() error {
+	return exec.Command("osascript", "-e", `tell application "iTerm2" to activate`).Run()
+}
+
+// isITerm2 reports whether houston runs inside iTerm2. LC_TERMINAL also
+// survives ssh, unlike TERM_PROGRAM.
+func isITerm2() bool {
+	return os.Getenv("TERM_PROGRAM") == "iTerm.app" || os.Getenv("LC_TERMINAL") == "iTerm2"
+}
+
+func newITerm2Controller() *ITerm2Controller {
+	python := exec.Command("python3", "-c", "import iterm2").Run() == nil
+	return &ITerm2Controller{python: python}
+}
+
+func (t *ITerm2Controller) script(what, arg string) error {
+	return exec.Command("python3", "-c", iterm2Script, what, arg).Run()
+}
+
+// SetTheme applies an iTerm2 color preset (Settings > Profiles > Colors).
+func (t *ITerm2Controller) SetTheme(theme string) error {
+	if !t.python {
+		return ErrUnsupported
+	}
+	return t.script("theme", theme)
+}
+
+func (t *ITerm2Controller) SetOpacity(opacity float64) error {
+	if !t.python {
+		return ErrUnsupported
+	}
+	if err := checkOpacity(opacity); err != nil {
+		return err
+	}
+	return t.script("opacity", strconv.FormatFloat(opacity, 'f', 2, 64))
+}
+
+// SetFontFamily takes the font's PostScript name, e.g. "JetBrainsMono-Regular".
+func (t *ITerm2Controller) SetFontFamily(family string) error {
+	if !t.python {
+		return ErrUnsupported
+	}
+	return t.script("family", family)
+}
+
+// Supports reports font size only when driven by keystrokes.
+func (t *ITerm2Controller) Supports() []string {
+	if !t.python {
+		return []string{FeatureFont}
+	}
+	return []string{FeatureFont, FeatureTheme, FeatureOpacity, FeatureFontFamily}
+}
@@ -0,0 +1,225 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// jsoncValue is the location of a value in a JSON-with-comments document.
+type jsoncValue struct {
+	start, end int  // Byte span of the value
+	object     bool // The value is an object; start is its '{'
+}
+
+// jsoncFind locates the value at a path of object keys in a JSONC document
+// (JSON with // and /* */ comments and trailing commas, as Windows Terminal
+// writes it), so it can be edited in place without losing the comments.
+func jsoncFind(data []byte, path ...string) (jsoncValue, bool, error) {
+	p := &jsoncParser{data: data, path: path}
+	p.skip()
+	if err := p.value(0); err != nil {
+		return jsoncValue{}, false, err
+	}
+	return p.found, p.ok, nil
+}
+
+type jsoncParser struct {
+	data  []byte
+	pos   int
+	path  []string
+	found jsoncValue
+	ok    bool
+}
+
+// value parses the value at pos; depth is how many path keys lead to it.
+func (p *jsoncParser) value(depth int) error {
+	start := p.pos
+	if p.pos >= len(p.data) {
+		return errors.New("unexpected end of input")
+	}
+	var err error
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		err = p.object(depth)
+	case c == '[':
+		err = p.array()
+	case c == '"':
+		_, err = p.str()
+	default:
+		for p.pos < len(p.data) && !isJSONDelim(p.data[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start {
+			err = fmt.Errorf("unexpected %q at offset %d", c, p.pos)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if depth == len(p.path) && !p.ok {
+		p.found = jsoncValue{start: start, end: p.pos, object: p.data[start] == '{'}
+		p.ok = true
+	}
+	return nil
+}
+
+// object parses an object. Its members are on the path only while every
+// key so far matched.
+func (p *jsoncParser) object(depth int) error {
+	p.pos++ // {
+	onPath := depth >= 0 && depth < len(p.path) && !p.ok
+	for {
+		p.skip()
+		if p.pos >= len(p.data) {
+			return errors.New("unterminated object")
+		}
+		if p.data[p.pos] == '}' {
+			p.pos++
+			return nil
+		}
+		key, err := p.str()
+		if err != nil {
+			return err
+		}
+		p.skip()
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return fmt.Errorf("expected ':' at offset %d", p.pos)
+		}
+		p.pos++
+		p.skip()
+
+		next := -1 // Not on the path
+		if onPath && key == p.path[depth] {
+			next = depth + 1
+		}
+		if err := p.value(next); err != nil {
+			return err
+		}
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
+
+func (p *jsoncParser) array() error {
+	p.pos++ // [
+	for {
+		p.skip()
+		if p.pos >= len(p.data) {
+			return errors.New("unterminated array")
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return nil
+		}
+		if err := p.value(-1); err != nil {
+			return err
+		}
+		p.skip()
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			p.pos++
+		}
+	}
+}
+
+// str parses a string and returns its raw contents (escapes kept, which is
+// enough to compare against plain keys).
+func (p *jsoncParser) str() (string, error) {
+	if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+		return "", fmt.Errorf("expected string at offset %d", p.pos)
+	}
+	start := p.pos + 1
+	for i := start; i < len(p.data); i++ {
+		switch p.data[i] {
+		case '\\':
+			i++
+		case '"':
+			p.pos = i + 1
+			return string(p.data[start:i]), nil
+		}
+	}
+	return "", errors.New("unterminated string")
+}
+
+// skip skips whitespace and comments.
+func (p *jsoncParser) skip() {
+	for p.pos < len(p.data) {
+		switch c := p.data[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.pos++
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '/':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '/' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '*':
+			p.pos += 2
+			for p.pos+1 < len(p.data) && !(p.data[p.pos] == '*' && p.data[p.pos+1] == '/') {
+				p.pos++
+			}
+			p.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+func isJSONDelim(c byte) bool {
+	switch c {
+	case ',', '}', ']', ' ', '\t', '\n', '\r', '/':
+		return true
+	}
+	return false
+}
+
+// jsoncSet returns data with the value at path set to raw (JSON text),
+// creating the objects on the path that are missing. Everything else,
+// comments included, is left as it was.
+func jsoncSet(data []byte, raw string, path ...string) ([]byte, error) {
+	v, ok, err := jsoncFind(data, path...)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return jsoncReplace(data, v.start, v.end, raw), nil
+	}
+
+	// Add the missing part of the path to the deepest object that exists
+	for i := len(path) - 1; i >= 0; i-- {
+		obj, ok, _ := jsoncFind(data, path[:i]...)
+		if !ok {
+			continue
+		}
+		if !obj.object {
+			return nil, fmt.Errorf("%s is not an object", strings.Join(path[:i], "."))
+		}
+		member := raw
+		for j := len(path) - 1; j > i; j-- {
+			member = `{ "` + path[j] + `": ` + member + ` }`
+		}
+		member = `"` + path[i] + `": ` + member
+		// Members go right after the '{', followed by a comma unless the
+		// object is empty
+		if !emptyJSONC(data[obj.start+1 : obj.end-1]) {
+			member += ","
+		}
+		return jsoncReplace(data, obj.start+1, obj.start+1, " "+member+" "), nil
+	}
+	return nil, errors.New("document is not an object")
+}
+
+// jsoncReplace returns data with data[start:end] replaced by text.
+func jsoncReplace(data []byte, start, end int, text string) []byte {
+	out := make([]byte, 0, len(data)+len(text))
+	out = append(out, data[:start]...)
+	out = append(out, text...)
+	return append(out, data[end:]...)
+}
+
+// emptyJSONC reports whether an object's contents are only comments.
+func emptyJSONC(inner []byte) bool {
+	p := &jsoncParser{data: inner}
+	p.skip()
+	return p.pos >= len(p.data)
+}
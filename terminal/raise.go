@@ -0,0 +1,46 @@
+package terminal
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Raiser brings the terminal window running tmux to the front.
+type Raiser interface {
+	Raise() error
+	Name() string
+}
+
+// NewRaiser returns a raiser for the detected terminal, or nil when the
+// terminal can't be raised. HOUSTON_RAISE_CMD overrides detection.
+func NewRaiser(font TerminalController) Raiser {
+	if cmd := os.Getenv("HOUSTON_RAISE_CMD"); cmd != "" {
+		return &CustomRaiser{cmd: cmd}
+	}
+	if k, ok := font.(*KittyController); ok {
+		return k
+	}
+	if g, ok := font.(*GhosttyController); ok && g.canRaise() {
+		return g
+	}
+	if t, ok := font.(*ITerm2Controller); ok {
+		return t
+	}
+	return nil
+}
+
+// Raise focuses kitty's active OS window.
+func (k *KittyController) Raise() error {
+	return exec.Command("kitty", "@", "--to", "unix:"+k.socket, "focus-window").Run()
+}
+
+// CustomRaiser runs a user-provided command to raise the terminal.
+type CustomRaiser struct {
+	cmd string
+}
+
+func (c *CustomRaiser) Name() string { return "custom" }
+
+func (c *CustomRaiser) Raise() error {
+	return exec.Command("sh", "-c", c.cmd).Run()
+}
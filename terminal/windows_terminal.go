@@ -0,0 +1,200 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// windowsTerminalDefaultSize is Windows Terminal's font size when the
+// settings don't set one.
+const windowsTerminalDefaultSize = 12
+
+// windowsTerminalSettings are the settings.json locations under
+// %LOCALAPPDATA%: stable, preview, then unpackaged installs.
+var windowsTerminalSettings = []string{
+	`Packages/Microsoft.WindowsTerminal_8wekyb3d8bbwe/LocalState/settings.json`,
+	`Packages/Microsoft.WindowsTerminalPreview_8wekyb3d8bbwe/LocalState/settings.json`,
+	`Microsoft/Windows Terminal/settings.json`,
+}
+
+// WindowsTerminalController controls Windows Terminal by editing
+// profiles.defaults in its settings.json, which the terminal reloads on
+// change. Comments and formatting in the file are kept.
+type WindowsTerminalController struct {
+	settings string // Path to settings.json
+
+	mu       sync.Mutex
+	original int // Size before houston changed it; 0 until then
+}
+
+func (w *WindowsTerminalController) Name() string { return "windows-terminal" }
+
+func (w *WindowsTerminalController) Increase() error { return w.adjust(1) }
+
+func (w *WindowsTerminalController) Decrease() error { return w.adjust(-1) }
+
+// Reset restores the size the settings had before houston changed them.
+func (w *WindowsTerminalController) Reset() error { return w.adjust(0) }
+
+func (w *WindowsTerminalController) adjust(step int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data, err := os.ReadFile(w.settings)
+	if err != nil {
+		return err
+	}
+	size, err := windowsTerminalFontSize(data)
+	if err != nil {
+		return err
+	}
+	if w.original == 0 {
+		w.original = size
+	}
+	next := max(size+step, 4)
+	if step == 0 {
+		next = w.original
+	}
+	if next == size {
+		return nil
+	}
+
+	patched, err := setWindowsTerminalFontSize(data, next)
+	if err != nil {
+		return err
+	}
+	return w.write(patched)
+}
+
+// windowsTerminalFontSize reads profiles.defaults.font.size (or the older
+// fontSize), defaulting to Windows Terminal's own default.
+func windowsTerminalFontSize(data []byte) (int, error) {
+	for _, path := range [][]string{
+		{"profiles", "defaults", "font", "size"},
+		{"profiles", "defaults", "fontSize"},
+	} {
+		v, ok, err := jsoncFind(data, path...)
+		if err != nil {
+			return 0, fmt.Errorf("parse settings.json: %w", err)
+		}
+		if ok {
+			size, err := strconv.ParseFloat(string(data[v.start:v.end]), 64)
+			if err != nil {
+				return 0, fmt.Errorf("settings.json: font size %q is not a number", data[v.start:v.end])
+			}
+			return int(size), nil
+		}
+	}
+	return windowsTerminalDefaultSize, nil
+}
+
+// setWindowsTerminalFontSize returns data with profiles.defaults.font.size
+// set, or the older fontSize when that is what the settings use.
+func setWindowsTerminalFontSize(data []byte, size int) ([]byte, error) {
+	n := strconv.Itoa(size)
+	if _, ok, _ := jsoncFind(data, "profiles", "defaults", "font", "size"); !ok {
+		if v, ok, _ := jsoncFind(data, "profiles", "defaults", "fontSize"); ok {
+			return jsoncReplace(data, v.start, v.end, n), nil
+		}
+	}
+	return jsoncSet(data, n, "profiles", "defaults", "font", "size")
+}
+
+// SetTheme sets the color scheme of all profiles (profiles.defaults.colorScheme).
+func (w *WindowsTerminalController) SetTheme(theme string) error {
+	return w.set(theme, "profiles", "defaults", "colorScheme")
+}
+
+// SetOpacity sets profiles.defaults.opacity, which Windows Terminal takes
+// in percent.
+func (w *WindowsTerminalController) SetOpacity(opacity float64) error {
+	if err := checkOpacity(opacity); err != nil {
+		return err
+	}
+	return w.set(int(math.Round(opacity*100)), "profiles", "defaults", "opacity")
+}
+
+// SetFontFamily sets profiles.defaults.font.face.
+func (w *WindowsTerminalController) SetFontFamily(family string) error {
+	return w.set(family, "profiles", "defaults", "font", "face")
+}
+
+func (w *WindowsTerminalController) Supports() []string {
+	return []string{FeatureFont, FeatureTheme, FeatureOpacity, FeatureFontFamily}
+}
+
+// set writes a JSON value at path in settings.json.
+func (w *WindowsTerminalController) set(value any, path ...string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(w.settings)
+	if err != nil {
+		return err
+	}
+	patched, err := jsoncSet(data, string(raw), path...)
+	if err != nil {
+		return err
+	}
+	return w.write(patched)
+}
+
+func (w *WindowsTerminalController) write(data []byte) error {
+	info, err := os.Stat(w.settings)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(w.settings, data, info.Mode().Perm())
+}
+
+// findWindowsTerminalSettings returns the settings.json of the Windows
+// Terminal houston runs in (WT_SESSION is set in its shells), natively or
+// from WSL.
+func findWindowsTerminalSettings() string {
+	if os.Getenv("WT_SESSION") == "" {
+		return ""
+	}
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if runtime.GOOS != "windows" {
+		localAppData = wslLocalAppData()
+	}
+	if localAppData == "" {
+		return ""
+	}
+	for _, rel := range windowsTerminalSettings {
+		path := filepath.Join(localAppData, filepath.FromSlash(rel))
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// wslLocalAppData asks Windows for %LOCALAPPDATA% and maps it into WSL.
+func wslLocalAppData() string {
+	out, err := exec.Command("cmd.exe", "/c", "echo %LOCALAPPDATA%").Output()
+	if err != nil {
+		return ""
+	}
+	win := strings.TrimSpace(string(out))
+	if win == "" || strings.Contains(win, "%") {
+		return ""
+	}
+	out, err = exec.Command("wslpath", "-u", win).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
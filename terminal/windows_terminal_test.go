@@ -0,0 +1,125 @@
+package terminal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetWindowsTerminalFontSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		size     int // Current size read from the settings
+		want     string
+	}{
+		{
+			name: "replaces size, keeps comments",
+			settings: `{
+    // Comment with "font": {"size": 1}
+    "profiles": {
+        "defaults": {
+            /* per-profile defaults */
+            "font": { "face": "Cascadia Mono", "size": 11.5, },
+        },
+        "list": [{ "name": "Ubuntu", "font": { "size": 20 } }],
+    },
+}`,
+			size: 11,
+			want: `"font": { "face": "Cascadia Mono", "size": 14, },`,
+		},
+		{
+			name:     "legacy fontSize",
+			settings: `{"profiles": {"defaults": {"fontSize": 10}}}`,
+			size:     10,
+			want:     `{"profiles": {"defaults": {"fontSize": 14}}}`,
+		},
+		{
+			name:     "adds size to font",
+			settings: `{"profiles": {"defaults": {"font": {"face": "Consolas"}}}}`,
+			size:     windowsTerminalDefaultSize,
+			want:     `{"profiles": {"defaults": {"font": { "size": 14, "face": "Consolas"}}}}`,
+		},
+		{
+			name:     "adds font to empty defaults",
+			settings: "{\"profiles\": {\"defaults\": {\n  // nothing yet\n}}}",
+			size:     windowsTerminalDefaultSize,
+			want:     "{\"profiles\": {\"defaults\": { \"font\": { \"size\": 14 } \n  // nothing yet\n}}}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := windowsTerminalFontSize([]byte(tt.settings))
+			if err != nil || size != tt.size {
+				t.Errorf("windowsTerminalFontSize() = %d, %v; want %d", size, err, tt.size)
+			}
+			got, err := setWindowsTerminalFontSize([]byte(tt.settings), 14)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(got), tt.want) {
+				t.Errorf("settings = %s\nwant it to contain %s", got, tt.want)
+			}
+			if size, _ := windowsTerminalFontSize(got); size != 14 {
+				t.Errorf("size after set = %d, want 14", size)
+			}
+		})
+	}
+
+	if _, err := setWindowsTerminalFontSize([]byte(`{"profiles": []}`), 14); err == nil {
+		t.Error("settings without profiles.defaults: want error")
+	}
+}
+
+func TestWindowsTerminalControllerReset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(path, []byte(`{"profiles": {"defaults": {"font": {"size": 11}}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w := &WindowsTerminalController{settings: path}
+	for _, step := range []func() error{w.Increase, w.Increase, w.Decrease} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(path)
+	if size, _ := windowsTerminalFontSize(data); size != 12 {
+		t.Errorf("size = %d, want 12", size)
+	}
+	if err := w.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if size, _ := windowsTerminalFontSize(data); size != 11 {
+		t.Errorf("size after reset = %d, want 11", size)
+	}
+}
+
+func TestWindowsTerminalControllerAppearance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	settings := "{\n  // keep me\n  \"profiles\": {\"defaults\": {\"colorScheme\": \"Campbell\"}}\n}"
+	if err := os.WriteFile(path, []byte(settings), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w := &WindowsTerminalController{settings: path}
+	if err := w.SetTheme("One Half Light"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetOpacity(0.85); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetFontFamily("Cascadia Code"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetOpacity(1.5); err == nil {
+		t.Error("SetOpacity(1.5): want error")
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{`// keep me`, `"colorScheme": "One Half Light"`, `"opacity": 85`, `"face": "Cascadia Code"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("settings = %s\nwant it to contain %s", data, want)
+		}
+	}
+}
@@ -2,14 +2,23 @@
 package tmux
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 type Session struct {
+	Host         string    `json:"host,omitempty"` // Remote host spec; empty for local tmux
 	Name         string    `json:"name"`
 	Created      time.Time `json:"created"`
 	Windows      int       `json:"windows"`
@@ -28,6 +37,7 @@ type Window struct {
 }
 
 type Pane struct {
+	Host    string `json:"host,omitempty"` // Remote host spec; empty for local tmux
 	Session string `json:"session"`
 	Window  int    `json:"window"`
 	Index   int    `json:"index"`
@@ -37,8 +47,9 @@ type PaneInfo struct {
 	Index   int    `json:"index"`
 	Active  bool   `json:"active"`
 	Command string `json:"command"`
-	Path    string `json:"path"`  // pane_current_path
-	Title   string `json:"title"` // pane_title (can be set with nerd fonts)
+	Path    string `json:"path"`          // pane_current_path
+	Title   string `json:"title"`         // pane_title (can be set with nerd fonts)
+	PID     int    `json:"pid,omitempty"` // pane_pid: the process the pane was started with
 }
 
 func (p Pane) Target() string {
@@ -50,6 +61,15 @@ func (p Pane) Target() string {
 	return fmt.Sprintf("%s:%d.%d", p.Session, p.Window, p.Index)
 }
 
+// Key identifies the pane across hosts, for use as a cache or map key.
+// Local panes use their plain target.
+func (p Pane) Key() string {
+	if p.Host == "" {
+		return p.Target()
+	}
+	return p.Host + "|" + p.Target()
+}
+
 // URLTarget returns a URL-safe version of Target() for use in URLs.
 // Session names with / are encoded to %2F.
 func (p Pane) URLTarget() string {
@@ -59,12 +79,74 @@ func (p Pane) URLTarget() string {
 
 type Client struct {
 	tmuxPath string
+	host     string          // ssh destination (user@host); empty runs tmux locally
+	ctx      context.Context // Parent of the spans of commands run; nil for none
 }
 
 func NewClient() *Client {
 	return &Client{tmuxPath: "tmux"}
 }
 
+// NewRemoteClient returns a client that runs tmux on host over ssh.
+// host is any ssh destination, e.g. "user@devbox" or an ~/.ssh/config alias.
+func NewRemoteClient(host string) *Client {
+	return &Client{tmuxPath: "tmux", host: host}
+}
+
+// Host returns the remote host spec, or "" for the local tmux server.
+func (c *Client) Host() string {
+	return c.host
+}
+
+// WithContext returns a client whose commands are traced as spans of
+// ctx, e.g. the request they're run for.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// command builds a command that runs name with args locally, or on the
+// remote host via ssh. Remote connections are multiplexed over a shared
+// ControlMaster socket so each tmux call doesn't pay for a new handshake.
+func (c *Client) command(name string, args ...string) *command {
+	cmd := &command{ctx: c.ctx, span: spanName(name, args), host: c.host}
+	if c.host == "" {
+		cmd.Cmd = exec.Command(name, args...)
+		return cmd
+	}
+
+	remote := make([]string, 0, len(args)+1)
+	remote = append(remote, ShellQuote(name))
+	for _, a := range args {
+		remote = append(remote, ShellQuote(a))
+	}
+	cmd.Cmd = exec.Command("ssh",
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=5",
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath="+filepath.Join(os.TempDir(), "houston-ssh-%C"),
+		"-o", "ControlPersist=5m",
+		c.host, "--", strings.Join(remote, " "))
+	return cmd
+}
+
+// tmuxCommand builds a tmux command for this client's host.
+func (c *Client) tmuxCommand(args ...string) *command {
+	return c.command(c.tmuxPath, args...)
+}
+
+// ShellQuote quotes s for a POSIX shell when it has anything besides plain
+// path characters (ssh passes the remote command through the login shell).
+func ShellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func parseSessionLine(line string) (Session, error) {
 	parts := strings.Split(line, "|")
 	if len(parts) != 5 {
@@ -86,7 +168,7 @@ func parseSessionLine(line string) (Session, error) {
 }
 
 func (c *Client) ListSessions() ([]Session, error) {
-	cmd := exec.Command(c.tmuxPath, "list-sessions", "-F",
+	cmd := c.tmuxCommand("list-sessions", "-F",
 		"#{session_name}|#{session_created}|#{session_windows}|#{session_attached}|#{session_activity}")
 
 	out, err := cmd.Output()
@@ -106,6 +188,7 @@ func (c *Client) ListSessions() ([]Session, error) {
 		if err != nil {
 			continue
 		}
+		s.Host = c.host
 		sessions = append(sessions, s)
 	}
 
@@ -113,7 +196,7 @@ func (c *Client) ListSessions() ([]Session, error) {
 }
 
 func (c *Client) ListWindows(session string) ([]Window, error) {
-	cmd := exec.Command(c.tmuxPath, "list-windows", "-t", session, "-F",
+	cmd := c.tmuxCommand("list-windows", "-t", session, "-F",
 		"#{window_index}|#{window_name}|#{window_active}|#{window_panes}|#{window_activity}|#{pane_current_path}")
 
 	out, err := cmd.Output()
@@ -159,9 +242,9 @@ func (c *Client) ListWindows(session string) ([]Window, error) {
 
 	// Get worktrees and populate branch names
 	if firstPath != "" {
-		worktrees, _ := GetWorktrees(firstPath)
+		worktrees, _ := c.GetWorktrees(firstPath)
 		for i := range windows {
-			windows[i].Branch = GetBranchForPath(windows[i].Path, worktrees)
+			windows[i].Branch = c.GetBranchForPath(windows[i].Path, worktrees)
 		}
 	}
 
@@ -170,8 +253,8 @@ func (c *Client) ListWindows(session string) ([]Window, error) {
 
 func (c *Client) ListPanes(session string, window int) ([]PaneInfo, error) {
 	target := fmt.Sprintf("%s:%d", session, window)
-	cmd := exec.Command(c.tmuxPath, "list-panes", "-t", target, "-F",
-		"#{pane_index}|#{pane_active}|#{pane_current_command}|#{pane_current_path}|#{pane_title}")
+	cmd := c.tmuxCommand("list-panes", "-t", target, "-F",
+		"#{pane_index}|#{pane_active}|#{pane_current_command}|#{pane_current_path}|#{pane_pid}|#{pane_title}")
 
 	out, err := cmd.Output()
 	if err != nil {
@@ -193,9 +276,14 @@ func (c *Client) ListPanes(session string, window int) ([]PaneInfo, error) {
 		if len(parts) >= 4 {
 			path = parts[3]
 		}
-		title := ""
+		pid := 0
 		if len(parts) >= 5 {
-			title = parts[4]
+			pid, _ = strconv.Atoi(parts[4])
+		}
+		title := ""
+		if len(parts) >= 6 {
+			// Titles are free text and may contain the separator
+			title = strings.Join(parts[5:], "|")
 		}
 		panes = append(panes, PaneInfo{
 			Index:   idx,
@@ -203,12 +291,114 @@ func (c *Client) ListPanes(session string, window int) ([]PaneInfo, error) {
 			Command: parts[2],
 			Path:    path,
 			Title:   title,
+			PID:     pid,
 		})
 	}
 
 	return panes, nil
 }
 
+// SessionTree is a session with its windows and their panes.
+type SessionTree struct {
+	Session
+	Windows []WindowTree
+}
+
+// WindowTree is a window with its panes.
+type WindowTree struct {
+	Window
+	Panes []PaneInfo
+}
+
+// treeFormat lists every pane with its window and session. Fields are
+// tab-separated so names containing "|" survive; the title goes last since
+// it's free text.
+var treeFormat = strings.Join([]string{
+	"#{session_name}", "#{session_created}", "#{session_windows}", "#{session_attached}", "#{session_activity}",
+	"#{window_index}", "#{window_name}", "#{window_active}", "#{window_panes}", "#{window_activity}",
+	"#{pane_index}", "#{pane_active}", "#{pane_current_command}", "#{pane_current_path}", "#{pane_pid}", "#{pane_title}",
+}, "\t")
+
+// ListTree lists all sessions with their windows and panes in a single
+// tmux call, where ListSessions, ListWindows and ListPanes need one per
+// session and window. Window branches aren't looked up: that runs git.
+func (c *Client) ListTree() ([]SessionTree, error) {
+	cmd := c.tmuxCommand("list-panes", "-a", "-F", treeFormat)
+
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(err.Error(), "no server running") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sessions := parseTree(string(out))
+	for i := range sessions {
+		sessions[i].Host = c.host
+	}
+	return sessions, nil
+}
+
+// parseTree groups list-panes -a lines in treeFormat by session and window,
+// in the order tmux lists them.
+func parseTree(out string) []SessionTree {
+	unix := func(s string) time.Time {
+		ts, _ := strconv.ParseInt(s, 10, 64)
+		return time.Unix(ts, 0)
+	}
+
+	var sessions []SessionTree
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		parts := strings.SplitN(line, "\t", 16)
+		if len(parts) != 16 {
+			continue
+		}
+
+		if n := len(sessions); n == 0 || sessions[n-1].Name != parts[0] {
+			windows, _ := strconv.Atoi(parts[2])
+			sessions = append(sessions, SessionTree{Session: Session{
+				Name:         parts[0],
+				Created:      unix(parts[1]),
+				Windows:      windows,
+				Attached:     parts[3] == "1",
+				LastActivity: unix(parts[4]),
+			}})
+		}
+		sess := &sessions[len(sessions)-1]
+
+		idx, _ := strconv.Atoi(parts[5])
+		if n := len(sess.Windows); n == 0 || sess.Windows[n-1].Index != idx {
+			panes, _ := strconv.Atoi(parts[8])
+			sess.Windows = append(sess.Windows, WindowTree{Window: Window{
+				Index:        idx,
+				Name:         parts[6],
+				Active:       parts[7] == "1",
+				Panes:        panes,
+				LastActivity: unix(parts[9]),
+			}})
+		}
+		win := &sess.Windows[len(sess.Windows)-1]
+
+		paneIdx, _ := strconv.Atoi(parts[10])
+		pid, _ := strconv.Atoi(parts[14])
+		pane := PaneInfo{
+			Index:   paneIdx,
+			Active:  parts[11] == "1",
+			Command: parts[12],
+			Path:    parts[13],
+			Title:   parts[15],
+			PID:     pid,
+		}
+		// A window's path is its active pane's, as in list-windows
+		if pane.Active || win.Path == "" {
+			win.Path = pane.Path
+		}
+		win.Panes = append(win.Panes, pane)
+	}
+	return sessions
+}
+
 // CaptureResult holds the captured pane output and detected mode
 type CaptureResult struct {
 	Output     string `json:"output"`
@@ -224,8 +414,11 @@ func (c *Client) CapturePane(p Pane, lines int) (string, error) {
 	return result.Output, nil
 }
 
+// CapturePaneWithMode captures the last lines of a pane with ANSI escape
+// sequences preserved (-e), so colors survive through to the xterm.js renderer.
+// Callers that need plain text strip them afterwards.
 func (c *Client) CapturePaneWithMode(p Pane, lines int) (CaptureResult, error) {
-	cmd := exec.Command(c.tmuxPath, "capture-pane",
+	cmd := c.tmuxCommand("capture-pane",
 		"-t", p.Target(),
 		"-p",
 		"-e", // Include ANSI escape sequences (colors)
@@ -252,32 +445,223 @@ func (c *Client) CapturePaneWithMode(p Pane, lines int) (CaptureResult, error) {
 
 
 
+// PasteThreshold is the length above which SendKeys pastes text rather
+// than typing it.
+const PasteThreshold = 512
+
+// InputMode is how SendText gets text into a pane.
+type InputMode string
+
+const (
+	InputAuto  InputMode = ""      // Paste multi-line or long text, type the rest
+	InputType  InputMode = "type"  // Type it, as send-keys -l
+	InputPaste InputMode = "paste" // Paste it, bracketed if the application asked
+)
+
+// ValidInputMode reports whether mode is one SendText takes.
+func ValidInputMode(mode InputMode) bool {
+	return mode == InputAuto || mode == InputType || mode == InputPaste
+}
+
+// pastes reports whether mode sends text as a paste. Newlines typed into a
+// shell or an agent's prompt act as Enter, and send-keys slows down on long
+// text, so multi-line and long text is pasted.
+func pastes(mode InputMode, text string) bool {
+	if mode == InputAuto {
+		return strings.ContainsAny(text, "\r\n") || len(text) > PasteThreshold
+	}
+	return mode == InputPaste
+}
+
+// SendKeys types or pastes keys into p as InputAuto picks, pressing Enter
+// after when enter is set.
 func (c *Client) SendKeys(p Pane, keys string, enter bool) error {
-	// Use -l for literal text to avoid interpreting special characters
-	args := []string{"send-keys", "-t", p.Target(), "-l", keys}
-	cmd := exec.Command(c.tmuxPath, args...)
-	if err := cmd.Run(); err != nil {
-		return err
+	return c.SendText(p, keys, enter, InputAuto)
+}
+
+// SendText gets text into p as mode says, pressing Enter after when enter
+// is set.
+func (c *Client) SendText(p Pane, text string, enter bool, mode InputMode) error {
+	if pastes(mode, text) {
+		if err := c.PasteText(p, text); err != nil {
+			return err
+		}
+	} else {
+		// Use -l for literal text to avoid interpreting special characters
+		args := []string{"send-keys", "-t", p.Target(), "-l", text}
+		cmd := c.tmuxCommand(args...)
+		if err := cmd.Run(); err != nil {
+			return err
+		}
 	}
 
 	// Send Enter separately (not literal)
 	if enter {
-		cmd = exec.Command(c.tmuxPath, "send-keys", "-t", p.Target(), "Enter")
-		return cmd.Run()
+		return c.tmuxCommand("send-keys", "-t", p.Target(), "Enter").Run()
+	}
+	return nil
+}
+
+// pasteBuffers numbers the buffers PasteText loads text into.
+var pasteBuffers atomic.Uint64
+
+// PasteText pastes text into p, as a bracketed paste if the application
+// asked for one, so its newlines are part of the text rather than Enter.
+// The text goes through a buffer of its own, deleted after the paste, and
+// leaves the user's paste buffers alone.
+func (c *Client) PasteText(p Pane, text string) error {
+	buffer := fmt.Sprintf("houston-%d-%d", os.Getpid(), pasteBuffers.Add(1))
+	cmd := c.tmuxCommand("load-buffer", "-b", buffer, "-")
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("load-buffer failed: %s", strings.TrimSpace(string(out)))
+	}
+	if out, err := c.tmuxCommand("paste-buffer", "-p", "-d", "-b", buffer, "-t", p.Target()).CombinedOutput(); err != nil {
+		_ = c.tmuxCommand("delete-buffer", "-b", buffer).Run()
+		return fmt.Errorf("paste-buffer failed: %s", strings.TrimSpace(string(out)))
 	}
 	return nil
 }
 
 func (c *Client) SendSpecialKey(p Pane, key string) error {
-	cmd := exec.Command(c.tmuxPath, "send-keys", "-t", p.Target(), key)
+	cmd := c.tmuxCommand("send-keys", "-t", p.Target(), key)
 	return cmd.Run()
 }
 
+// ModeState is where a pane stands in tmux's modes. A pane in copy-mode
+// shows its history from ScrollPosition lines above the bottom, while its
+// program's output goes on below.
+type ModeState struct {
+	InMode         bool   `json:"in_mode"`
+	Mode           string `json:"mode,omitempty"`            // copy-mode, view-mode, ...
+	ScrollPosition int    `json:"scroll_position,omitempty"` // Lines scrolled up, in copy-mode
+	HistorySize    int    `json:"history_size"`              // Lines of scrollback above the screen
+
+	height int // Of the pane, for CaptureScrolled
+}
+
+// Scrolled reports whether the pane shows earlier output than its bottom.
+func (s ModeState) Scrolled() bool {
+	return s.Mode == "copy-mode" && s.ScrollPosition > 0
+}
+
+// modeFormat prints a ModeState for parseModeState.
+const modeFormat = "#{pane_in_mode}\t#{pane_mode}\t#{scroll_position}\t#{history_size}\t#{pane_height}"
+
+func parseModeState(line string) (ModeState, error) {
+	parts := strings.Split(strings.TrimRight(line, "\n"), "\t")
+	if len(parts) != 5 {
+		return ModeState{}, fmt.Errorf("unexpected mode line: %q", line)
+	}
+	state := ModeState{InMode: parts[0] == "1", Mode: parts[1]}
+	state.ScrollPosition, _ = strconv.Atoi(parts[2])
+	state.HistorySize, _ = strconv.Atoi(parts[3])
+	state.height, _ = strconv.Atoi(parts[4])
+	if !state.InMode {
+		state.Mode = ""
+	}
+	return state, nil
+}
+
+// PaneModeState returns the tmux mode p is in and how far it's scrolled.
+func (c *Client) PaneModeState(p Pane) (ModeState, error) {
+	out, err := c.tmuxCommand("display-message", "-p", "-t", p.Target(), modeFormat).Output()
+	if err != nil {
+		return ModeState{}, fmt.Errorf("display-message failed: %w", err)
+	}
+	return parseModeState(string(out))
+}
+
+// CaptureScrolled captures what p shows in copy-mode, scrolled up as state
+// says, with lines of history above it: CapturePane's view, moved up. Panes
+// can't be captured from copy-mode's view, only from the live screen.
+func (c *Client) CaptureScrolled(p Pane, lines int, state ModeState) (string, error) {
+	return c.CaptureRange(p, -(lines + state.ScrollPosition), state.height-1-state.ScrollPosition)
+}
+
+// PaneMode returns the tmux mode p is in (copy-mode, view-mode, ...), or
+// "" when it's showing its program. Keys sent to a pane in a mode drive
+// the mode rather than reach the program.
+func (c *Client) PaneMode(p Pane) (string, error) {
+	state, err := c.PaneModeState(p)
+	return state.Mode, err
+}
+
+// EnterCopyMode puts p in copy-mode, where its history can be scrolled.
+// A pane already in copy-mode stays where it is.
+func (c *Client) EnterCopyMode(p Pane) error {
+	if out, err := c.tmuxCommand("copy-mode", "-t", p.Target()).CombinedOutput(); err != nil {
+		return fmt.Errorf("copy-mode failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// CopyModeCommand runs a copy-mode command (scroll-up, page-down,
+// history-top, ...) in p, repeat times. p must be in copy-mode.
+func (c *Client) CopyModeCommand(p Pane, command string, repeat int) error {
+	args := []string{"send-keys", "-t", p.Target(), "-X"}
+	if repeat > 1 {
+		args = append(args, "-N", strconv.Itoa(repeat))
+	}
+	args = append(args, command)
+	if out, err := c.tmuxCommand(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("send-keys -X %s failed: %s", command, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ExitMode takes p out of copy-mode or any other mode it's in.
+func (c *Client) ExitMode(p Pane) error {
+	if out, err := c.tmuxCommand("copy-mode", "-q", "-t", p.Target()).CombinedOutput(); err != nil {
+		return fmt.Errorf("copy-mode -q failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ErrNoBuffer is returned by ShowBuffer when the tmux server has no paste
+// buffers.
+var ErrNoBuffer = errors.New("no paste buffer")
+
+// ShowBuffer returns the contents of the most recent paste buffer.
+func (c *Client) ShowBuffer() (string, error) {
+	cmd := c.tmuxCommand("show-buffer")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "no buffers") {
+			return "", ErrNoBuffer
+		}
+		return "", fmt.Errorf("show-buffer failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// SetBuffer stores text as a new paste buffer, which becomes the most
+// recent. It's passed on stdin, so its size isn't bound by argument limits.
+func (c *Client) SetBuffer(text string) error {
+	cmd := c.tmuxCommand("load-buffer", "-")
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("load-buffer failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// PasteBuffer pastes the most recent paste buffer into p, as a bracketed
+// paste if the application asked for one.
+func (c *Client) PasteBuffer(p Pane) error {
+	if out, err := c.tmuxCommand("paste-buffer", "-p", "-t", p.Target()).CombinedOutput(); err != nil {
+		return fmt.Errorf("paste-buffer failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 // GetPaneLocation finds the window and pane index for a given pane ID
 // Returns window index, pane index, and error
 func (c *Client) GetPaneLocation(session string, paneID int) (int, int, error) {
 	// List all panes in session with their IDs
-	cmd := exec.Command(c.tmuxPath, "list-panes", "-s", "-t", session, "-F",
+	cmd := c.tmuxCommand("list-panes", "-s", "-t", session, "-F",
 		"#{pane_id}|#{window_index}|#{pane_index}")
 
 	out, err := cmd.Output()
@@ -306,21 +690,21 @@ func (c *Client) GetPaneLocation(session string, paneID int) (int, int, error) {
 
 // KillPane closes a pane
 func (c *Client) KillPane(p Pane) error {
-	cmd := exec.Command(c.tmuxPath, "kill-pane", "-t", p.Target())
+	cmd := c.tmuxCommand("kill-pane", "-t", p.Target())
 	return cmd.Run()
 }
 
 // RespawnPane kills the current process and respawns the pane
 func (c *Client) RespawnPane(p Pane) error {
 	// -k flag kills the current process first
-	cmd := exec.Command(c.tmuxPath, "respawn-pane", "-k", "-t", p.Target())
+	cmd := c.tmuxCommand("respawn-pane", "-k", "-t", p.Target())
 	return cmd.Run()
 }
 
 // KillWindow closes a window
 func (c *Client) KillWindow(session string, window int) error {
 	target := fmt.Sprintf("%s:%d", session, window)
-	cmd := exec.Command(c.tmuxPath, "kill-window", "-t", target)
+	cmd := c.tmuxCommand("kill-window", "-t", target)
 	return cmd.Run()
 }
 
@@ -332,19 +716,19 @@ func (c *Client) ResizePane(p Pane, direction string, adjustment int) error {
 		adjustment = 5
 	}
 	flag := "-" + direction
-	cmd := exec.Command(c.tmuxPath, "resize-pane", "-t", p.Target(), flag, strconv.Itoa(adjustment))
+	cmd := c.tmuxCommand("resize-pane", "-t", p.Target(), flag, strconv.Itoa(adjustment))
 	return cmd.Run()
 }
 
 // ZoomPane toggles zoom on a pane (maximizes/restores).
 func (c *Client) ZoomPane(p Pane) error {
-	cmd := exec.Command(c.tmuxPath, "resize-pane", "-t", p.Target(), "-Z")
+	cmd := c.tmuxCommand("resize-pane", "-t", p.Target(), "-Z")
 	return cmd.Run()
 }
 
 // GetPaneSize returns the width and height of a pane.
 func (c *Client) GetPaneSize(p Pane) (width, height int, err error) {
-	cmd := exec.Command(c.tmuxPath, "display-message", "-t", p.Target(), "-p", "#{pane_width}x#{pane_height}")
+	cmd := c.tmuxCommand("display-message", "-t", p.Target(), "-p", "#{pane_width}x#{pane_height}")
 	out, err := cmd.Output()
 	if err != nil {
 		return 0, 0, err
@@ -358,6 +742,35 @@ func (c *Client) GetPaneSize(p Pane) (width, height int, err error) {
 	return width, height, nil
 }
 
+// GetHistorySize returns the number of scrollback lines held above the
+// visible area of a pane.
+func (c *Client) GetHistorySize(p Pane) (int, error) {
+	cmd := c.tmuxCommand("display-message", "-t", p.Target(), "-p", "#{history_size}")
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
+
+// CaptureRange captures lines start through end (inclusive) of a pane, using
+// tmux line numbering: 0 is the first visible line and negative numbers reach
+// into the scrollback.
+func (c *Client) CaptureRange(p Pane, start, end int) (string, error) {
+	cmd := c.tmuxCommand("capture-pane",
+		"-t", p.Target(),
+		"-p",
+		"-e",
+		"-S", strconv.Itoa(start),
+		"-E", strconv.Itoa(end))
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("capture-pane failed: %w", err)
+	}
+	return strings.ReplaceAll(string(out), "␛", "\x1b"), nil
+}
+
 // Worktree represents a git worktree with its path and branch
 type Worktree struct {
 	Path   string
@@ -367,12 +780,12 @@ type Worktree struct {
 // GetWorktrees returns all git worktrees for a repository.
 // The path should be any directory within the git repo.
 // Returns a map of absolute path -> branch name.
-func GetWorktrees(path string) (map[string]string, error) {
+func (c *Client) GetWorktrees(path string) (map[string]string, error) {
 	if path == "" {
 		return nil, nil
 	}
 
-	cmd := exec.Command("git", "-C", path, "worktree", "list", "--porcelain")
+	cmd := c.command("git", "-C", path, "worktree", "list", "--porcelain")
 	out, err := cmd.Output()
 	if err != nil {
 		// Not a git repo or no worktrees
@@ -398,7 +811,7 @@ func GetWorktrees(path string) (map[string]string, error) {
 
 // GetBranchForPath returns the git branch for a specific path.
 // First tries worktree matching, then falls back to git branch command.
-func GetBranchForPath(path string, worktrees map[string]string) string {
+func (c *Client) GetBranchForPath(path string, worktrees map[string]string) string {
 	if path == "" {
 		return ""
 	}
@@ -416,7 +829,7 @@ func GetBranchForPath(path string, worktrees map[string]string) string {
 	}
 
 	// Fallback: run git branch --show-current
-	cmd := exec.Command("git", "-C", path, "branch", "--show-current")
+	cmd := c.command("git", "-C", path, "branch", "--show-current")
 	out, err := cmd.Output()
 	if err != nil {
 		return ""
@@ -429,6 +842,340 @@ func GetBranchForPath(path string, worktrees map[string]string) string {
 // This works even when resize-pane is capped by the window dimensions.
 func (c *Client) ResizeWindow(session string, window int, cols, rows int) error {
 	target := fmt.Sprintf("%s:%d", session, window)
-	cmd := exec.Command(c.tmuxPath, "resize-window", "-t", target, "-x", strconv.Itoa(cols), "-y", strconv.Itoa(rows))
+	cmd := c.tmuxCommand("resize-window", "-t", target, "-x", strconv.Itoa(cols), "-y", strconv.Itoa(rows))
 	return cmd.Run()
 }
+
+// NewWindow creates a detached window in session, starting in dir and
+// running command (the default shell if empty). Returns the new window index.
+func (c *Client) NewWindow(session, name, dir, command string) (int, error) {
+	args := []string{"new-window", "-d", "-P", "-F", "#{window_index}", "-t", session + ":"}
+	if name != "" {
+		args = append(args, "-n", name)
+	}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	if command != "" {
+		args = append(args, command)
+	}
+
+	out, err := c.tmuxCommand(args...).Output()
+	if err != nil {
+		return 0, fmt.Errorf("new-window failed: %w", err)
+	}
+	idx, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected new-window output: %q", string(out))
+	}
+	return idx, nil
+}
+
+// NewSession creates a detached session named name, starting in dir and
+// running command (the default shell if empty).
+func (c *Client) NewSession(name, dir, command string) error {
+	args := []string{"new-session", "-d", "-s", name}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	if command != "" {
+		args = append(args, command)
+	}
+
+	if out, err := c.tmuxCommand(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("new-session failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// HasSession reports whether a session with exactly this name exists.
+func (c *Client) HasSession(name string) bool {
+	return c.tmuxCommand("has-session", "-t", "="+name).Run() == nil
+}
+
+// RepoRoot returns the top-level directory of the git repository containing path.
+func (c *Client) RepoRoot(path string) (string, error) {
+	out, err := c.command("git", "-C", path, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not in a git repository", path)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Commit is a git commit listed by Commits.
+type Commit struct {
+	Hash    string    `json:"hash"` // Abbreviated
+	At      time.Time `json:"at"`   // Author date
+	Subject string    `json:"subject"`
+}
+
+// Commits returns the commits on any branch of the repository containing
+// path that were authored in [since, until), newest first. When git has a
+// user.email configured, only that user's commits are listed.
+func (c *Client) Commits(path string, since, until time.Time) ([]Commit, error) {
+	args := []string{"-C", path, "log", "--all", "--no-merges", "--format=%h%x1f%aI%x1f%s",
+		"--since=" + since.Format(time.RFC3339), "--until=" + until.Format(time.RFC3339)}
+	if email, err := c.command("git", "-C", path, "config", "user.email").Output(); err == nil && strings.TrimSpace(string(email)) != "" {
+		args = append(args, "--author="+strings.TrimSpace(string(email)))
+	}
+	out, err := c.command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed in %s: %w", path, err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, fields[1])
+		// --since and --until go by commit date: a commit written last week
+		// and rebased today isn't today's
+		if err != nil || at.Before(since) || !at.Before(until) {
+			continue
+		}
+		commits = append(commits, Commit{Hash: fields[0], At: at, Subject: fields[2]})
+	}
+	return commits, nil
+}
+
+// emptyTree is git's empty tree object, diffed against in a repository
+// without commits.
+const emptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Diff returns the uncommitted changes to tracked files in the repository
+// containing path, as git diff --stat and the patch (with renames). With
+// staged, only the changes staged for the next commit.
+func (c *Client) Diff(path string, staged bool) (stat, patch string, err error) {
+	base := "HEAD"
+	if c.command("git", "-C", path, "rev-parse", "--verify", "--quiet", "HEAD").Run() != nil {
+		base = emptyTree
+	}
+	args := []string{"-C", path, "-c", "core.quotepath=off", "diff", "--no-color", "--no-ext-diff", "-M", "--patch-with-stat"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	out, err := c.command("git", append(args, base)...).Output()
+	if err != nil {
+		return "", "", fmt.Errorf("git diff failed in %s: %w", path, err)
+	}
+	stat, patch, _ = strings.Cut(string(out), "\ndiff --git ")
+	if patch != "" {
+		patch = "diff --git " + patch
+	}
+	return stat, patch, nil
+}
+
+// Untracked returns the files git doesn't track yet (ignored files left
+// out) in the repository containing path, relative to its top level like
+// the paths of Diff.
+func (c *Client) Untracked(path string) ([]string, error) {
+	out, err := c.command("git", "-C", path, "ls-files", "--others", "--exclude-standard", "--full-name", "-z", ":/").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed in %s: %w", path, err)
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// HashUntracked returns the blob id git add would store for each of files,
+// as Untracked lists them, so a caller can tell whether their contents
+// changed. An untracked nested repository (listed as "dir/") has no blob
+// and gets "".
+func (c *Client) HashUntracked(path string, files []string) ([]string, error) {
+	hashes := make([]string, len(files))
+	var args []string
+	var idx []int
+	for i, f := range files {
+		if !strings.HasSuffix(f, "/") {
+			args = append(args, f)
+			idx = append(idx, i)
+		}
+	}
+	if len(args) == 0 {
+		return hashes, nil
+	}
+	root, err := c.RepoRoot(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.command("git", append([]string{"-C", root, "hash-object", "--"}, args...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git hash-object failed in %s: %w", root, err)
+	}
+	lines := strings.Fields(string(out))
+	if len(lines) != len(args) {
+		return nil, fmt.Errorf("git hash-object returned %d ids for %d files", len(lines), len(args))
+	}
+	for i, h := range lines {
+		hashes[idx[i]] = h
+	}
+	return hashes, nil
+}
+
+// CommitAll stages every change in the repository containing path (git add
+// -A) and commits it with message. It returns the new commit's abbreviated
+// hash and git's output.
+func (c *Client) CommitAll(path, message string) (hash, output string, err error) {
+	if out, err := c.command("git", "-C", path, "add", "-A").CombinedOutput(); err != nil {
+		return "", strings.TrimSpace(string(out)), fmt.Errorf("git add failed: %w", err)
+	}
+	out, err := c.command("git", "-C", path, "commit", "-m", message).CombinedOutput()
+	output = strings.TrimSpace(string(out))
+	if err != nil {
+		return "", output, fmt.Errorf("git commit failed: %w", err)
+	}
+	head, err := c.command("git", "-C", path, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "", output, fmt.Errorf("git rev-parse failed: %w", err)
+	}
+	return strings.TrimSpace(string(head)), output, nil
+}
+
+// Upstream returns the current branch of the repository containing path,
+// its upstream (e.g. origin/main) and the commits on the branch that the
+// upstream doesn't have, newest first.
+func (c *Client) Upstream(path string) (branch, upstream string, ahead []Commit, err error) {
+	out, err := c.command("git", "-C", path, "rev-parse", "--abbrev-ref", "HEAD", "@{upstream}").Output()
+	lines := strings.Fields(string(out))
+	if err != nil || len(lines) != 2 {
+		return "", "", nil, fmt.Errorf("%s has no upstream branch", path)
+	}
+	branch, upstream = lines[0], lines[1]
+
+	out, err = c.command("git", "-C", path, "log", "--format=%h%x1f%aI%x1f%s", "@{upstream}..HEAD").Output()
+	if err != nil {
+		return "", "", nil, fmt.Errorf("git log failed in %s: %w", path, err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		at, _ := time.Parse(time.RFC3339, fields[1])
+		ahead = append(ahead, Commit{Hash: fields[0], At: at, Subject: fields[2]})
+	}
+	return branch, upstream, ahead, nil
+}
+
+// Push pushes the current branch of the repository containing path to its
+// upstream and returns git's output. Git and ssh may not prompt for
+// credentials: with none available the push fails instead of hanging.
+func (c *Client) Push(path string) (string, error) {
+	cmd := c.command("git", "-C", path, "push")
+	if c.host == "" {
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return strings.TrimSpace(string(out)), fmt.Errorf("git push failed: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// createFileScript writes stdin to $1$2, or, when that exists, to
+// $1-1$2, $1-2$2, ..., and prints the path written. noclobber makes a file
+// created meanwhile fail the write instead of being overwritten.
+const createFileScript = `set -C; f="$1$2"; n=0
+while [ -e "$f" ] || [ -L "$f" ]; do n=$((n+1)); f="$1-$n$2"; done
+cat > "$f" && printf %s "$f"`
+
+// CreateFile writes r to a new file at path, never replacing an existing
+// one: if path exists, a number is added before its extension
+// (data-1.csv). It returns the path written, which is on the client's
+// host.
+func (c *Client) CreateFile(path string, r io.Reader) (string, error) {
+	ext := filepath.Ext(path)
+	cmd := c.command("sh", "-c", createFileScript, "sh", strings.TrimSuffix(path, ext), ext)
+	cmd.Stdin = r
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("create %s: %s", path, cmp.Or(strings.TrimSpace(stderr.String()), err.Error()))
+	}
+	return string(out), nil
+}
+
+// AddWorktree creates a git worktree at path for repo. If the branch doesn't
+// exist yet it is created from base (HEAD if empty). A path, branch or base
+// starting with "-" is refused rather than read as an option.
+func (c *Client) AddWorktree(repo, path, branch, base string) error {
+	for _, v := range []string{path, branch, base} {
+		if strings.HasPrefix(v, "-") {
+			return fmt.Errorf("invalid worktree argument %q", v)
+		}
+	}
+	args := []string{"-C", repo, "worktree", "add"}
+	if c.command("git", "-C", repo, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil {
+		args = append(args, "--", path, branch)
+	} else {
+		args = append(args, "-b", branch, "--", path)
+		if base != "" {
+			args = append(args, base)
+		}
+	}
+
+	if out, err := c.command("git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree add failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// AttachedClient is a terminal attached to the tmux server.
+type AttachedClient struct {
+	TTY      string    `json:"tty"`
+	Session  string    `json:"session"`
+	Activity time.Time `json:"activity"`
+}
+
+func parseClientLine(line string) (AttachedClient, bool) {
+	parts := strings.Split(line, "|")
+	if len(parts) != 4 || parts[3] == "1" {
+		return AttachedClient{}, false // Skip control-mode clients (raw pane sockets)
+	}
+	activity, _ := strconv.ParseInt(parts[2], 10, 64)
+	return AttachedClient{TTY: parts[0], Session: parts[1], Activity: time.Unix(activity, 0)}, true
+}
+
+// ListClients returns the terminals attached to the tmux server, most
+// recently active first. Control-mode clients are left out.
+func (c *Client) ListClients() ([]AttachedClient, error) {
+	cmd := c.tmuxCommand("list-clients", "-F",
+		"#{client_tty}|#{client_session}|#{client_activity}|#{client_control_mode}")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var clients []AttachedClient
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if cl, ok := parseClientLine(line); ok {
+			clients = append(clients, cl)
+		}
+	}
+	sort.SliceStable(clients, func(i, j int) bool { return clients[i].Activity.After(clients[j].Activity) })
+	return clients, nil
+}
+
+// FocusPane switches an attached client to the pane's session and selects
+// its window and pane, as if the user had navigated there in tmux.
+func (c *Client) FocusPane(tty string, p Pane) error {
+	window := fmt.Sprintf("%s:%d", p.Session, p.Window)
+	if err := c.tmuxCommand("select-window", "-t", window).Run(); err != nil {
+		return fmt.Errorf("select-window %s: %w", window, err)
+	}
+	if err := c.tmuxCommand("select-pane", "-t", fmt.Sprintf("%s.%d", window, p.Index)).Run(); err != nil {
+		return fmt.Errorf("select-pane %s.%d: %w", window, p.Index, err)
+	}
+	if err := c.tmuxCommand("switch-client", "-c", tty, "-t", p.Session).Run(); err != nil {
+		return fmt.Errorf("switch-client %s: %w", tty, err)
+	}
+	return nil
+}
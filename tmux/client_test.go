@@ -2,7 +2,18 @@
 package tmux
 
 import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
 	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func TestParseSessionLine(t *testing.T) {
@@ -24,6 +35,38 @@ func TestParseSessionLine(t *testing.T) {
 	}
 }
 
+func TestParseTree(t *testing.T) {
+	out := strings.Join([]string{
+		"main\t1735689600\t2\t1\t1735690000\t1\tclaude\t1\t2\t1735690000\t0\t0\tzsh\t/src/a\t100\tshell",
+		"main\t1735689600\t2\t1\t1735690000\t1\tclaude\t1\t2\t1735690000\t1\t1\tclaude\t/src/b\t101\t✳ fix\ttabs | pipes",
+		"main\t1735689600\t2\t1\t1735690000\t2\ta|b\t0\t1\t1735689900\t0\t1\tvim\t/src/c\t102\t",
+		"work\t1735689700\t1\t0\t1735689800\t0\tzsh\t1\t1\t1735689800\t0\t1\tzsh\t/src/d\t103\thost",
+		"garbage",
+	}, "\n") + "\n"
+
+	sessions := parseTree(out)
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+	main := sessions[0]
+	if main.Name != "main" || !main.Attached || main.Windows[0].Name != "claude" || len(main.Windows) != 2 {
+		t.Errorf("main = %+v", main)
+	}
+	win := main.Windows[0]
+	if len(win.Panes) != 2 || win.Path != "/src/b" || !win.Active {
+		t.Errorf("window 1 = %+v", win)
+	}
+	if p := win.Panes[1]; p.Index != 1 || !p.Active || p.Command != "claude" || p.PID != 101 || p.Title != "✳ fix\ttabs | pipes" {
+		t.Errorf("pane 1.1 = %+v", p)
+	}
+	if win := main.Windows[1]; win.Name != "a|b" || win.Index != 2 || win.Path != "/src/c" || win.Panes[0].Title != "" {
+		t.Errorf("window 2 = %+v", win)
+	}
+	if work := sessions[1]; work.Name != "work" || work.Attached || len(work.Windows) != 1 || work.Windows[0].Panes[0].Path != "/src/d" {
+		t.Errorf("work = %+v", work)
+	}
+}
+
 func TestCapturePaneOutput(t *testing.T) {
 	// This tests the output structure, actual capture requires tmux
 	output := `$ echo hello
@@ -34,3 +77,353 @@ $ _`
 		t.Error("expected non-empty output")
 	}
 }
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"list-panes", "list-panes"},
+		{"work:1.0", "work:1.0"},
+		{"", "''"},
+		{"#{window_index}", "'#{window_index}'"},
+		{"a b", "'a b'"},
+		{"it's", `'it'\''s'`},
+		{"$(rm -rf ~)", "'$(rm -rf ~)'"},
+	}
+
+	for _, tt := range tests {
+		if got := ShellQuote(tt.in); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRemoteCommand(t *testing.T) {
+	c := NewRemoteClient("me@devbox")
+	cmd := c.tmuxCommand("send-keys", "-t", "work:1.0", "-l", "echo hi")
+
+	args := cmd.Args
+	if args[0] != "ssh" {
+		t.Fatalf("expected ssh command, got %v", args)
+	}
+	if args[len(args)-3] != "me@devbox" {
+		t.Errorf("expected host before remote command, got %v", args)
+	}
+	if got, want := args[len(args)-1], "tmux send-keys -t work:1.0 -l 'echo hi'"; got != want {
+		t.Errorf("remote command = %q, want %q", got, want)
+	}
+
+	if local := NewClient().tmuxCommand("list-sessions"); local.Args[0] != "tmux" {
+		t.Errorf("local client should run tmux directly, got %v", local.Args)
+	}
+}
+
+func TestPaneKey(t *testing.T) {
+	local := Pane{Session: "work", Window: 1, Index: 0}
+	remote := Pane{Host: "devbox", Session: "work", Window: 1, Index: 0}
+
+	if local.Key() == remote.Key() {
+		t.Errorf("local and remote panes share key %q", local.Key())
+	}
+	if remote.Target() != local.Target() {
+		t.Errorf("Target() should not include host: %q", remote.Target())
+	}
+}
+
+func TestParseClientLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		wantTTY string
+		wantOK  bool
+	}{
+		{"/dev/pts/3|main|1735690000|0", "/dev/pts/3", true},
+		{"/dev/pts/4|main|1735690000|1", "", false}, // Control-mode client
+		{"garbage", "", false},
+	}
+	for _, tt := range tests {
+		cl, ok := parseClientLine(tt.line)
+		if ok != tt.wantOK || cl.TTY != tt.wantTTY {
+			t.Errorf("parseClientLine(%q) = %q, %v, want %q, %v", tt.line, cl.TTY, ok, tt.wantTTY, tt.wantOK)
+		}
+	}
+}
+
+func TestCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git(nil, "init", "-q")
+	git(nil, "config", "user.email", "me@example.com")
+	git(nil, "config", "user.name", "Me")
+	at := func(date string) []string {
+		return []string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}
+	}
+	git(at("2026-03-01T09:00:00Z"), "commit", "-q", "--allow-empty", "-m", "Before the day")
+	git(at("2026-03-02T10:00:00Z"), "commit", "-q", "--allow-empty", "-m", "Add a parser, with tests")
+	git(append(at("2026-03-02T11:00:00Z"), "GIT_AUTHOR_EMAIL=them@example.com"), "commit", "-q", "--allow-empty", "-m", "Someone else's")
+	git(at("2026-03-02T12:00:00Z"), "commit", "-q", "--allow-empty", "-m", "Fix the parser")
+
+	since := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	commits, err := NewClient().Commits(dir, since, since.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "Fix the parser" || commits[1].Subject != "Add a parser, with tests" {
+		t.Fatalf("Commits() = %+v, want my two commits of the day, newest first", commits)
+	}
+	if commits[1].Hash == "" || !commits[1].At.Equal(since.Add(10*time.Hour)) {
+		t.Errorf("commit = %+v", commits[1])
+	}
+}
+
+func TestDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(dir+"/"+name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("staged.txt", "one\n")
+	git("add", "staged.txt")
+
+	c := NewClient()
+	stat, patch, err := c.Diff(dir, false) // No commits yet
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stat, "staged.txt | 1 +") || !strings.HasPrefix(patch, "diff --git a/staged.txt b/staged.txt\n") {
+		t.Errorf("Diff() = %q, %q", stat, patch)
+	}
+
+	git("-c", "user.email=me@example.com", "-c", "user.name=Me", "commit", "-q", "-m", "first")
+	write("staged.txt", "two\n")
+	write("new.txt", "new\n")
+	if err := os.Mkdir(dir+"/sub", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, patch, _ := c.Diff(dir, true); patch != "" {
+		t.Errorf("Diff(staged) = %q, want nothing staged", patch)
+	}
+	if _, patch, _ := c.Diff(dir+"/sub", false); !strings.Contains(patch, "+two") {
+		t.Errorf("Diff() = %q, want the change to staged.txt", patch)
+	}
+	if files, err := c.Untracked(dir + "/sub"); err != nil || len(files) != 1 || files[0] != "new.txt" {
+		t.Errorf("Untracked() = %v, %v, want [new.txt]", files, err)
+	}
+}
+
+func TestCommitAllAndPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	for k, v := range map[string]string{"GIT_AUTHOR_NAME": "Me", "GIT_AUTHOR_EMAIL": "me@example.com", "GIT_COMMITTER_NAME": "Me", "GIT_COMMITTER_EMAIL": "me@example.com"} {
+		t.Setenv(k, v)
+	}
+	remote, dir := t.TempDir(), t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	git("init", "-q", "--bare", remote)
+	git("clone", "-q", remote, dir)
+
+	c := NewClient()
+	if _, _, _, err := c.Upstream(dir); err == nil {
+		t.Error("Upstream() before the first push: want error")
+	}
+	if err := os.WriteFile(dir+"/a.txt", []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hash, _, err := c.CommitAll(dir, "add a")
+	if err != nil || hash == "" {
+		t.Fatalf("CommitAll() = %q, %v", hash, err)
+	}
+	if _, out, err := c.CommitAll(dir, "nothing"); err == nil {
+		t.Errorf("CommitAll() with nothing to commit succeeded: %s", out)
+	}
+	git("-C", dir, "push", "-q", "-u", "origin", "HEAD")
+
+	if err := os.WriteFile(dir+"/b.txt", []byte("b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CommitAll(dir, "add b"); err != nil {
+		t.Fatal(err)
+	}
+	branch, upstream, ahead, err := c.Upstream(dir)
+	if err != nil || upstream != "origin/"+branch || len(ahead) != 1 || ahead[0].Subject != "add b" {
+		t.Fatalf("Upstream() = %q, %q, %+v, %v", branch, upstream, ahead, err)
+	}
+	if out, err := c.Push(dir); err != nil {
+		t.Fatalf("Push() = %v: %s", err, out)
+	}
+	if _, _, ahead, _ := c.Upstream(dir); len(ahead) != 0 {
+		t.Errorf("Upstream() after Push() = %+v, want nothing ahead", ahead)
+	}
+}
+
+func TestAddWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo, dir := t.TempDir(), t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main", repo},
+		{"-C", repo, "-c", "user.name=Me", "-c", "user.email=me@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"-C", repo, "branch", "existing"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	c := NewClient()
+	for _, branch := range []string{"existing", "feature"} {
+		if err := c.AddWorktree(repo, dir+"/"+branch, branch, ""); err != nil {
+			t.Errorf("AddWorktree(%s) = %v", branch, err)
+		}
+		if got := c.GetBranchForPath(dir+"/"+branch, nil); got != branch {
+			t.Errorf("branch of the %s worktree = %q", branch, got)
+		}
+	}
+	for _, tt := range []struct{ path, branch, base string }{
+		{dir + "/opt", "--detach", ""},
+		{"--lock", "opt", ""},
+		{dir + "/opt", "opt", "--orphan"},
+	} {
+		if err := c.AddWorktree(repo, tt.path, tt.branch, tt.base); err == nil {
+			t.Errorf("AddWorktree(%q, %q, %q) succeeded, want an error", tt.path, tt.branch, tt.base)
+		}
+	}
+	if _, err := os.Stat(dir + "/opt"); !os.IsNotExist(err) {
+		t.Errorf("a worktree was added for an option: %v", err)
+	}
+}
+
+func TestCreateFile(t *testing.T) {
+	dir := t.TempDir()
+	c := NewClient()
+	for i, want := range []string{"data.csv", "data-1.csv", "data-2.csv"} {
+		got, err := c.CreateFile(dir+"/data.csv", strings.NewReader("a,b\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != dir+"/"+want {
+			t.Errorf("CreateFile() #%d = %q, want %q", i, got, dir+"/"+want)
+		}
+	}
+	if data, err := os.ReadFile(dir + "/data.csv"); err != nil || string(data) != "a,b\n" {
+		t.Errorf("data.csv = %q, %v", data, err)
+	}
+	if got, err := c.CreateFile(dir+"/Makefile", strings.NewReader("")); err != nil || got != dir+"/Makefile" {
+		t.Errorf("CreateFile(Makefile) = %q, %v", got, err)
+	}
+	if _, err := c.CreateFile(dir+"/missing/x.txt", strings.NewReader("")); err == nil {
+		t.Error("CreateFile() in a missing directory succeeded")
+	}
+}
+
+func TestSpanName(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"tmux", []string{"capture-pane", "-p", "-t", "work:1.0"}, "tmux capture-pane"},
+		{"tmux", []string{"-C", "attach-session"}, "tmux attach-session"},
+		{"git", []string{"-C", "/src/app", "worktree", "list"}, "git worktree"},
+		{"sh", []string{"-c", createFileScript}, "sh"},
+	}
+	for _, tt := range tests {
+		if got := spanName(tt.name, tt.args); got != tt.want {
+			t.Errorf("spanName(%s %q) = %q, want %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestCommandSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	c := NewClient().WithContext(ctx)
+	if _, err := c.RepoRoot(t.TempDir()); err == nil {
+		t.Fatal("RepoRoot() outside a repository succeeded")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans, want the command's and the request's", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "git rev-parse" || span.Parent().SpanID() != parent.SpanContext().SpanID() || span.Status().Code != codes.Error {
+		t.Errorf("span %q, parent %v, status %v", span.Name(), span.Parent().SpanID(), span.Status())
+	}
+}
+
+func TestPastes(t *testing.T) {
+	long := strings.Repeat("x", PasteThreshold+1)
+	tests := []struct {
+		mode InputMode
+		text string
+		want bool
+	}{
+		{InputAuto, "run the tests", false},
+		{InputAuto, "fix this:\nit fails", true},
+		{InputAuto, "line\r", true},
+		{InputAuto, long, true},
+		{InputAuto, long[1:], false},
+		{InputType, "a\nb", false},
+		{InputPaste, "y", true},
+	}
+	for _, tt := range tests {
+		if got := pastes(tt.mode, tt.text); got != tt.want {
+			t.Errorf("pastes(%q, %.20q) = %v, want %v", tt.mode, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseModeState(t *testing.T) {
+	tests := []struct {
+		line string
+		want ModeState
+	}{
+		{"0\t\t\t277\t40\n", ModeState{HistorySize: 277, height: 40}},
+		{"1\tcopy-mode\t5\t277\t40\n", ModeState{InMode: true, Mode: "copy-mode", ScrollPosition: 5, HistorySize: 277, height: 40}},
+		{"1\tview-mode\t0\t12\t40\n", ModeState{InMode: true, Mode: "view-mode", HistorySize: 12, height: 40}},
+	}
+	for _, tt := range tests {
+		got, err := parseModeState(tt.line)
+		if err != nil || got != tt.want {
+			t.Errorf("parseModeState(%q) = %+v, %v; want %+v", tt.line, got, err, tt.want)
+		}
+	}
+	if _, err := parseModeState("1\tcopy-mode\t5\t277\n"); err == nil {
+		t.Error("parseModeState accepted a short line")
+	}
+}
@@ -0,0 +1,75 @@
+package tmux
+
+import (
+	"context"
+	"os/exec"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/noamsto/houston/internal/tracing"
+)
+
+var tracer = otel.Tracer("github.com/noamsto/houston/tmux")
+
+// command is a tmux or git command of a Client. Running it to completion
+// (Run, Output, CombinedOutput) is traced as a span of the client's
+// context, named after the program and its subcommand; the arguments,
+// which may hold text sent to a pane, are left out.
+type command struct {
+	*exec.Cmd
+	ctx  context.Context
+	span string
+	host string
+}
+
+func (c *command) Run() (err error) {
+	defer c.trace()(&err)
+	return c.Cmd.Run()
+}
+
+func (c *command) Output() (out []byte, err error) {
+	defer c.trace()(&err)
+	return c.Cmd.Output()
+}
+
+func (c *command) CombinedOutput() (out []byte, err error) {
+	defer c.trace()(&err)
+	return c.Cmd.CombinedOutput()
+}
+
+// trace starts the command's span and returns the function ending it
+// with the command's error.
+func (c *command) trace() func(*error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracer.Start(ctx, c.span, trace.WithSpanKind(trace.SpanKindClient))
+	if c.host != "" {
+		span.SetAttributes(attribute.String("houston.host", c.host))
+	}
+	return func(err *error) {
+		tracing.Fail(span, *err)
+		span.End()
+	}
+}
+
+// spanName names a command's span: "tmux capture-pane", "git worktree";
+// other programs by their name only.
+func spanName(name string, args []string) string {
+	if name != "tmux" && name != "git" {
+		return name
+	}
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "-C" && name == "git":
+			i++ // git -C <dir>
+		case len(arg) > 0 && arg[0] == '-':
+		default:
+			return name + " " + arg
+		}
+	}
+	return name
+}
@@ -0,0 +1,184 @@
+package tmux
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ControlStream is a tmux control-mode client (tmux -C) attached to a pane's
+// session. It delivers the pane's raw output bytes as the program writes them
+// and forwards input byte-for-byte, so a browser terminal sees cursor movement
+// and alternate-screen switches instead of capture-pane snapshots.
+//
+// Control clients attach with ignore-size so they never shrink the window for
+// the user's own terminal.
+type ControlStream struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	paneID string
+	output chan []byte
+	done   chan struct{}
+	exited chan struct{} // Closed once readLoop has read stdout to the end
+
+	mu     sync.Mutex // guards stdin
+	closed bool
+}
+
+// PaneID returns the tmux pane id (e.g. "%3") for a pane target.
+func (c *Client) PaneID(p Pane) (string, error) {
+	cmd := c.tmuxCommand("display-message", "-t", p.Target(), "-p", "#{pane_id}")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	id := strings.TrimSpace(string(out))
+	if !strings.HasPrefix(id, "%") {
+		return "", fmt.Errorf("unexpected pane id: %q", id)
+	}
+	return id, nil
+}
+
+// GetCursor returns the cursor position of a pane and whether it is showing
+// the alternate screen.
+func (c *Client) GetCursor(p Pane) (x, y int, alternate bool, err error) {
+	cmd := c.tmuxCommand("display-message", "-t", p.Target(), "-p", "#{cursor_x} #{cursor_y} #{alternate_on}")
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, 0, false, err
+	}
+	var alt int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(out)), "%d %d %d", &x, &y, &alt); err != nil {
+		return 0, 0, false, fmt.Errorf("unexpected cursor format: %s", string(out))
+	}
+	return x, y, alt == 1, nil
+}
+
+// AttachControl starts a control-mode client for the pane's session and
+// streams output for that pane only. Close must be called to detach.
+func (c *Client) AttachControl(p Pane) (*ControlStream, error) {
+	paneID, err := c.PaneID(p)
+	if err != nil {
+		return nil, fmt.Errorf("resolve pane id: %w", err)
+	}
+
+	cmd := c.tmuxCommand("-C", "attach-session", "-f", "ignore-size", "-t", p.Session)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start control client: %w", err)
+	}
+
+	cs := &ControlStream{
+		cmd:    cmd.Cmd,
+		stdin:  stdin,
+		paneID: paneID,
+		output: make(chan []byte, 64),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	go cs.readLoop(stdout)
+	return cs, nil
+}
+
+// Output returns the pane's raw output. The channel is closed when the
+// control client exits.
+func (cs *ControlStream) Output() <-chan []byte {
+	return cs.output
+}
+
+// SendBytes writes input to the pane exactly as given.
+func (cs *ControlStream) SendBytes(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("send-keys -t ")
+	sb.WriteString(cs.paneID)
+	sb.WriteString(" -H")
+	for _, c := range b {
+		sb.WriteByte(' ')
+		sb.WriteString(hex.EncodeToString([]byte{c}))
+	}
+	sb.WriteByte('\n')
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.closed {
+		return io.ErrClosedPipe
+	}
+	_, err := io.WriteString(cs.stdin, sb.String())
+	return err
+}
+
+// Close detaches the control client.
+func (cs *ControlStream) Close() error {
+	cs.mu.Lock()
+	if cs.closed {
+		cs.mu.Unlock()
+		return nil
+	}
+	cs.closed = true
+	close(cs.done)
+	// Closing stdin makes tmux detach the control client
+	_ = cs.stdin.Close()
+	cs.mu.Unlock()
+	// Wait closes stdout, so readLoop must be done with it first
+	<-cs.exited
+	return cs.cmd.Wait()
+}
+
+func (cs *ControlStream) readLoop(stdout io.Reader) {
+	defer close(cs.exited)
+	// Read to the end once done, so tmux isn't stuck writing as it exits
+	defer func() { _, _ = io.Copy(io.Discard, stdout) }()
+	defer close(cs.output)
+
+	prefix := "%output " + cs.paneID + " "
+	r := bufio.NewReader(stdout)
+	for {
+		line, err := r.ReadString('\n')
+		if strings.HasPrefix(line, prefix) {
+			data := decodeControlOutput(strings.TrimSuffix(line[len(prefix):], "\n"))
+			select {
+			case cs.output <- data:
+			case <-cs.done:
+				return
+			}
+		} else if strings.HasPrefix(line, "%exit") {
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// decodeControlOutput reverses control-mode escaping, where bytes below
+// space and backslash itself are written as three-digit octal (\ooo).
+func decodeControlOutput(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && isOctal(s[i+1]) && isOctal(s[i+2]) && isOctal(s[i+3]) {
+			out = append(out, (s[i+1]-'0')<<6|(s[i+2]-'0')<<3|(s[i+3]-'0'))
+			i += 3
+			continue
+		}
+		out = append(out, s[i])
+	}
+	return out
+}
+
+func isOctal(b byte) bool {
+	return b >= '0' && b <= '7'
+}
@@ -0,0 +1,67 @@
+package tmux
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestDecodeControlOutput(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []byte
+	}{
+		{"plain", []byte("plain")},
+		{`hi\015\012`, []byte("hi\r\n")},
+		{`\033[?2004h`, []byte("\x1b[?2004h")},
+		{`a\134b`, []byte(`a\b`)},
+		{`tab\011x`, []byte("tab\tx")},
+		{`short\01`, []byte(`short\01`)},
+		{`bad\089`, []byte(`bad\089`)},
+		{"héllo", []byte("héllo")},
+	}
+	for _, tt := range tests {
+		if got := decodeControlOutput(tt.in); !bytes.Equal(got, tt.want) {
+			t.Errorf("decodeControlOutput(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestControlStreamClose(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	// A tmux server of the test's own, away from the user's sessions
+	t.Setenv("TMUX_TMPDIR", t.TempDir())
+	t.Setenv("TMUX", "")
+	t.Cleanup(func() { _ = exec.Command("tmux", "kill-server").Run() })
+	if out, err := exec.Command("tmux", "new-session", "-d", "-s", "work", "-x", "80", "-y", "24", "yes").CombinedOutput(); err != nil {
+		t.Fatalf("tmux new-session: %v: %s", err, out)
+	}
+
+	// The pane writes constantly, so output is in flight as Close detaches
+	for range 3 {
+		cs, err := NewClient().AttachControl(Pane{Session: "work"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-cs.Output():
+		case <-time.After(5 * time.Second):
+			t.Fatal("no output from the pane")
+		}
+		closed := make(chan error, 1)
+		go func() { closed <- cs.Close() }()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close() didn't return")
+		}
+		for range cs.Output() {
+		}
+		if err := cs.Close(); err != nil {
+			t.Errorf("second Close() = %v", err)
+		}
+	}
+}
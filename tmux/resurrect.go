@@ -0,0 +1,122 @@
+// tmux/resurrect.go
+package tmux
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SavedPane is a pane recorded in a tmux-resurrect save file.
+type SavedPane struct {
+	Pane    Pane
+	Dir     string
+	Command string // pane_current_command at save time (e.g. "claude")
+	Full    string // Full command line at save time (e.g. "claude --continue")
+}
+
+// StartCommand returns the command to relaunch the pane with.
+func (p SavedPane) StartCommand() string {
+	if p.Full != "" {
+		return p.Full
+	}
+	return p.Command
+}
+
+// ResurrectState is the contents of a tmux-resurrect save file.
+type ResurrectState struct {
+	Path    string
+	SavedAt time.Time
+	Panes   map[string]SavedPane // keyed by "session:window.pane"
+}
+
+// Lookup returns the saved pane at session:window.pane, if any.
+func (s *ResurrectState) Lookup(session string, window, pane int) (SavedPane, bool) {
+	p, ok := s.Panes[fmt.Sprintf("%s:%d.%d", session, window, pane)]
+	return p, ok
+}
+
+// FindResurrectFile returns the latest tmux-resurrect save file in the
+// plugin's default locations, or "" if there is none.
+func FindResurrectFile() string {
+	home, _ := os.UserHomeDir()
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	for _, dir := range []string{
+		filepath.Join(home, ".tmux", "resurrect"),
+		filepath.Join(dataHome, "tmux", "resurrect"),
+	} {
+		path := filepath.Join(dir, "last")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// LoadResurrect parses a tmux-resurrect save file. Both the current format
+// (with pane_title) and the older one without it are accepted.
+func LoadResurrect(path string) (*ResurrectState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ResurrectState{
+		Path:    path,
+		SavedAt: info.ModTime(),
+		Panes:   make(map[string]SavedPane),
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		p, ok := parseResurrectPane(scanner.Text())
+		if ok {
+			state.Panes[fmt.Sprintf("%s:%d.%d", p.Pane.Session, p.Pane.Window, p.Pane.Index)] = p
+		}
+	}
+	return state, scanner.Err()
+}
+
+// parseResurrectPane parses a "pane" line:
+//
+//	pane  session  window  active  :flags  index  [title]  :dir  active  command  :full_command
+func parseResurrectPane(line string) (SavedPane, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 10 || fields[0] != "pane" {
+		return SavedPane{}, false
+	}
+	if len(fields) == 10 {
+		// Older format without pane_title
+		fields = append(fields[:6], append([]string{""}, fields[6:]...)...)
+	}
+
+	window, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return SavedPane{}, false
+	}
+	index, err := strconv.Atoi(fields[5])
+	if err != nil {
+		return SavedPane{}, false
+	}
+
+	return SavedPane{
+		Pane:    Pane{Session: fields[1], Window: window, Index: index},
+		Dir:     strings.ReplaceAll(strings.TrimPrefix(fields[7], ":"), `\ `, " "),
+		Command: fields[9],
+		Full:    strings.TrimPrefix(fields[10], ":"),
+	}, true
+}
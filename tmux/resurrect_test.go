@@ -0,0 +1,47 @@
+// tmux/resurrect_test.go
+package tmux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadResurrect(t *testing.T) {
+	content := "pane\twork\t1\t1\t:*\t0\tclaude\t:/src/my\\ app\t1\tclaude\t:claude --continue\n" +
+		"pane\twork\t2\t0\t:-\t1\t:/src/app\t1\tzsh\t:\n" + // older format without title
+		"window\twork\t1\t:claude\t1\t:*\tlayout\t:\n" +
+		"state\twork\twork\n"
+
+	path := filepath.Join(t.TempDir(), "tmux_resurrect_20260101T000000.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := LoadResurrect(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Panes) != 2 {
+		t.Fatalf("expected 2 panes, got %d", len(state.Panes))
+	}
+
+	p, ok := state.Lookup("work", 1, 0)
+	if !ok {
+		t.Fatal("pane work:1.0 not found")
+	}
+	if p.Command != "claude" || p.StartCommand() != "claude --continue" {
+		t.Errorf("work:1.0 command = %q / %q, want claude / claude --continue", p.Command, p.StartCommand())
+	}
+	if p.Dir != "/src/my app" {
+		t.Errorf("work:1.0 dir = %q, want /src/my app", p.Dir)
+	}
+
+	p, ok = state.Lookup("work", 2, 1)
+	if !ok {
+		t.Fatal("pane work:2.1 (old format) not found")
+	}
+	if p.Command != "zsh" || p.StartCommand() != "zsh" || p.Dir != "/src/app" {
+		t.Errorf("work:2.1 = %+v", p)
+	}
+}
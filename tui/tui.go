@@ -0,0 +1,344 @@
+// Package tui renders houston's session dashboard in the terminal, for
+// keeping it in a tmux window next to the agents it watches.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/noamsto/houston/server"
+	"github.com/noamsto/houston/tmux"
+)
+
+// Config connects the TUI to a houston server.
+type Config struct {
+	Fetch    func() (*server.SessionsData, error) // Current sessions, as /api/sessions
+	Focus    func(tmux.Pane) error                // Switch the terminal to a pane
+	Interval time.Duration                        // How often to refetch
+}
+
+// Run shows the dashboard until the user quits.
+func Run(cfg Config) error {
+	_, err := tea.NewProgram(newModel(cfg), tea.WithAltScreen()).Run()
+	return err
+}
+
+var (
+	titleStyle     = lipgloss.NewStyle().Bold(true)
+	groupStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("8"))
+	attentionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	workingStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	mutedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	selectedStyle  = lipgloss.NewStyle().Reverse(true)
+)
+
+// row is one line of the list: a group or session header, or a window.
+type row struct {
+	text   string
+	style  lipgloss.Style
+	window *server.WindowWithStatus // nil for headers
+}
+
+type (
+	dataMsg struct {
+		data   *server.SessionsData
+		err    error
+		manual bool // From the refresh key; the tick loop is already running
+	}
+	focusMsg struct {
+		pane tmux.Pane
+		err  error
+	}
+	tickMsg struct{}
+)
+
+type model struct {
+	cfg         Config
+	rows        []row
+	cursor      int // Index into rows; always a window row when there is one
+	err         error
+	status      string
+	showPreview bool
+	width       int
+	height      int
+}
+
+func newModel(cfg Config) model {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 2 * time.Second
+	}
+	return model{cfg: cfg, showPreview: true, cursor: -1}
+}
+
+func (m model) Init() tea.Cmd {
+	return m.fetch
+}
+
+func (m model) fetch() tea.Msg {
+	data, err := m.cfg.Fetch()
+	return dataMsg{data: data, err: err}
+}
+
+func (m model) tick() tea.Cmd {
+	return tea.Tick(m.cfg.Interval, func(time.Time) tea.Msg { return tickMsg{} })
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tickMsg:
+		return m, m.fetch
+	case dataMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.setRows(buildRows(msg.data))
+		}
+		if msg.manual {
+			return m, nil
+		}
+		return m, m.tick()
+	case focusMsg:
+		if msg.err != nil {
+			m.status = "focus failed: " + msg.err.Error()
+		} else {
+			m.status = "focused " + msg.pane.Target()
+		}
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c", "esc":
+		return m, tea.Quit
+	case "j", "down":
+		m.move(1)
+	case "k", "up":
+		m.move(-1)
+	case "g", "home":
+		m.cursor = -1
+		m.move(1)
+	case "G", "end":
+		m.cursor = len(m.rows)
+		m.move(-1)
+	case "tab":
+		m.nextAttention()
+	case "p":
+		m.showPreview = !m.showPreview
+	case "r":
+		m.status = ""
+		return m, func() tea.Msg {
+			msg := m.fetch().(dataMsg)
+			msg.manual = true
+			return msg
+		}
+	case "enter":
+		if win := m.selected(); win != nil {
+			pane := win.Pane
+			focus := m.cfg.Focus
+			return m, func() tea.Msg { return focusMsg{pane: pane, err: focus(pane)} }
+		}
+	}
+	return m, nil
+}
+
+// setRows replaces the list, keeping the cursor on the same pane.
+func (m *model) setRows(rows []row) {
+	var prev string
+	if win := m.selected(); win != nil {
+		prev = win.Pane.Key()
+	}
+	m.rows = rows
+	m.cursor = -1
+	for i, r := range rows {
+		if r.window != nil && r.window.Pane.Key() == prev {
+			m.cursor = i
+			return
+		}
+	}
+	m.move(1)
+}
+
+// move steps the cursor by dir over window rows, staying put at the ends.
+func (m *model) move(dir int) {
+	for i := m.cursor + dir; i >= 0 && i < len(m.rows); i += dir {
+		if m.rows[i].window != nil {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// nextAttention moves to the next window needing attention, wrapping.
+func (m *model) nextAttention() {
+	for n := 1; n <= len(m.rows); n++ {
+		i := (m.cursor + n) % len(m.rows)
+		if w := m.rows[i].window; w != nil && w.NeedsAttention {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+func (m model) selected() *server.WindowWithStatus {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[m.cursor].window
+}
+
+// buildRows lays out the sessions in the dashboard's groups.
+func buildRows(data *server.SessionsData) []row {
+	var rows []row
+	for _, group := range []struct {
+		name     string
+		sessions []server.SessionWithWindows
+	}{
+		{"NEEDS ATTENTION", data.NeedsAttention},
+		{"ACTIVE", data.Active},
+		{"IDLE", data.Idle},
+	} {
+		if len(group.sessions) == 0 {
+			continue
+		}
+		rows = append(rows, row{text: fmt.Sprintf("%s (%d)", group.name, len(group.sessions)), style: groupStyle})
+		for _, sess := range group.sessions {
+			rows = append(rows, row{text: sessionLine(sess), style: titleStyle})
+			for i := range sess.Windows {
+				win := &sess.Windows[i]
+				rows = append(rows, row{text: windowLine(win), style: windowStyle(win), window: win})
+			}
+		}
+	}
+	return rows
+}
+
+func sessionLine(sess server.SessionWithWindows) string {
+	line := "  " + sess.Session.Name
+	if sess.Session.Host != "" {
+		line += " @" + sess.Session.Host
+	}
+	if p := sess.Project; p != nil && p.Name != sess.Session.Name {
+		line += "  " + p.Name
+	}
+	return line
+}
+
+func windowLine(win *server.WindowWithStatus) string {
+	parts := []string{fmt.Sprintf("    %d:%s", win.Window.Index, win.Window.Name), win.ParseResult.Type.String()}
+	if len(win.MCPDown) > 0 {
+		parts = append(parts, "mcp down: "+strings.Join(win.MCPDown, ", "))
+	}
+	if win.NeedsAttention && win.WaitingMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", win.WaitingMinutes))
+	}
+	if win.Branch != "" {
+		parts = append(parts, win.Branch)
+	}
+	if win.ParseResult.Question != "" {
+		parts = append(parts, win.ParseResult.Question)
+	} else if win.ParseResult.Activity != "" {
+		parts = append(parts, win.ParseResult.Activity)
+	}
+	return strings.Join(parts, "  ")
+}
+
+func windowStyle(win *server.WindowWithStatus) lipgloss.Style {
+	switch {
+	case win.NeedsAttention:
+		return attentionStyle
+	case win.State.State == server.PaneWorking:
+		return workingStyle
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+const helpLine = "j/k move · tab next attention · enter jump · p preview · r refresh · q quit"
+
+func (m model) View() string {
+	var b strings.Builder
+	attention := 0
+	for _, r := range m.rows {
+		if r.window != nil && r.window.NeedsAttention {
+			attention++
+		}
+	}
+	b.WriteString(titleStyle.Render("houston"))
+	if attention > 0 {
+		b.WriteString(attentionStyle.Render(fmt.Sprintf("  %d need attention", attention)))
+	}
+	b.WriteString("\n")
+	if m.err != nil {
+		b.WriteString(attentionStyle.Render("error: "+m.err.Error()) + "\n")
+	}
+
+	// Leave room for the title, help line and preview
+	var preview []string
+	if win := m.selected(); m.showPreview && win != nil {
+		preview = win.Preview
+	}
+	listHeight := len(m.rows)
+	if m.height > 0 {
+		avail := m.height - 3
+		if len(preview) > 0 {
+			maxPreview := avail / 3
+			if len(preview) > maxPreview {
+				preview = preview[len(preview)-maxPreview:]
+			}
+			avail -= len(preview) + 1
+		}
+		listHeight = max(min(listHeight, avail), 1)
+	}
+
+	// Scroll so the cursor stays visible
+	start := 0
+	if m.cursor >= listHeight {
+		start = m.cursor - listHeight + 1
+	}
+	for i := start; i < len(m.rows) && i < start+listHeight; i++ {
+		r := m.rows[i]
+		text := m.truncate(r.text)
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render(text))
+		} else {
+			b.WriteString(r.style.Render(text))
+		}
+		b.WriteString("\n")
+	}
+	if len(m.rows) == 0 && m.err == nil {
+		b.WriteString(mutedStyle.Render("no tmux sessions") + "\n")
+	}
+
+	if len(preview) > 0 {
+		b.WriteString(mutedStyle.Render(strings.Repeat("─", max(m.width, 20))) + "\n")
+		for _, line := range preview {
+			b.WriteString(m.truncate(line) + "\n")
+		}
+	}
+
+	footer := helpLine
+	if m.status != "" {
+		footer = m.status
+	}
+	b.WriteString(mutedStyle.Render(m.truncate(footer)))
+	return b.String()
+}
+
+// truncate cuts text to the terminal width.
+func (m model) truncate(text string) string {
+	if m.width <= 0 {
+		return text
+	}
+	if r := []rune(text); len(r) > m.width {
+		return string(r[:m.width-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,120 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/noamsto/houston/parser"
+	"github.com/noamsto/houston/server"
+	"github.com/noamsto/houston/tmux"
+)
+
+func testData() *server.SessionsData {
+	win := func(session string, index int, typ parser.ResultType, attention bool) server.WindowWithStatus {
+		return server.WindowWithStatus{
+			Window:         tmux.Window{Index: index, Name: "claude"},
+			Pane:           tmux.Pane{Session: session, Window: index},
+			ParseResult:    parser.Result{Type: typ},
+			NeedsAttention: attention,
+			Preview:        []string{"line one", "line two"},
+		}
+	}
+	return &server.SessionsData{
+		NeedsAttention: []server.SessionWithWindows{{
+			Session: tmux.Session{Name: "api"},
+			Windows: []server.WindowWithStatus{win("api", 1, parser.TypeQuestion, true), win("api", 2, parser.TypeIdle, false)},
+		}},
+		Active: []server.SessionWithWindows{{
+			Session: tmux.Session{Name: "web"},
+			Windows: []server.WindowWithStatus{win("web", 1, parser.TypeWorking, false)},
+		}},
+		Idle: []server.SessionWithWindows{{
+			Session: tmux.Session{Name: "docs"},
+			Windows: []server.WindowWithStatus{win("docs", 3, parser.TypeQuestion, true)},
+		}},
+	}
+}
+
+func update(t *testing.T, m model, msg tea.Msg) model {
+	t.Helper()
+	next, _ := m.Update(msg)
+	return next.(model)
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestNavigation(t *testing.T) {
+	m := update(t, newModel(Config{}), dataMsg{data: testData()})
+	if win := m.selected(); win == nil || win.Pane.Target() != "api:1.0" {
+		t.Fatalf("initial selection = %+v, want api:1.0", win)
+	}
+
+	m = update(t, m, key("j"))
+	m = update(t, m, key("j")) // Skips the "web" header
+	if got := m.selected().Pane.Target(); got != "web:1.0" {
+		t.Errorf("after j j: %s, want web:1.0", got)
+	}
+
+	m = update(t, m, key("tab"))
+	if got := m.selected().Pane.Target(); got != "docs:3.0" {
+		t.Errorf("after tab: %s, want docs:3.0", got)
+	}
+	m = update(t, m, key("tab")) // Wraps
+	if got := m.selected().Pane.Target(); got != "api:1.0" {
+		t.Errorf("after second tab: %s, want api:1.0", got)
+	}
+
+	// A refresh keeps the selection on the same pane
+	m = update(t, m, key("G"))
+	m = update(t, m, dataMsg{data: testData()})
+	if got := m.selected().Pane.Target(); got != "docs:3.0" {
+		t.Errorf("after refresh: %s, want docs:3.0", got)
+	}
+}
+
+func TestFocus(t *testing.T) {
+	var focused tmux.Pane
+	m := update(t, newModel(Config{Focus: func(p tmux.Pane) error {
+		focused = p
+		return errors.New("no tmux client attached")
+	}}), dataMsg{data: testData()})
+
+	_, cmd := m.Update(key("enter"))
+	if cmd == nil {
+		t.Fatal("enter returned no command")
+	}
+	m = update(t, m, cmd())
+	if focused.Target() != "api:1.0" {
+		t.Errorf("focused %s, want api:1.0", focused.Target())
+	}
+	if !strings.Contains(m.View(), "focus failed: no tmux client attached") {
+		t.Errorf("View() doesn't show the focus error:\n%s", m.View())
+	}
+}
+
+func TestView(t *testing.T) {
+	m := update(t, newModel(Config{}), dataMsg{data: testData()})
+	m = update(t, m, tea.WindowSizeMsg{Width: 80, Height: 24})
+	view := m.View()
+	for _, want := range []string{"2 need attention", "NEEDS ATTENTION (1)", "ACTIVE (1)", "IDLE (1)", "1:claude  question", "line two"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() is missing %q:\n%s", want, view)
+		}
+	}
+
+	m = update(t, m, key("p"))
+	if strings.Contains(m.View(), "line two") {
+		t.Error("preview still shown after p")
+	}
+}
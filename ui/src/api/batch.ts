@@ -0,0 +1,12 @@
+import type { BatchResult } from './types'
+
+// Fetch several API paths in one round trip via POST /api/batch. Results
+// come in the order of paths, each with the status its GET would have had.
+export async function batch(paths: string[]): Promise<BatchResult[]> {
+  const res = await fetch('api/batch', {
+    method: 'POST',
+    body: JSON.stringify({ paths }),
+  })
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
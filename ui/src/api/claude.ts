@@ -0,0 +1,20 @@
+import type { ClaudeSession, ResumeResult } from './types'
+
+// List the Claude Code conversations recorded for a project directory,
+// most recent first.
+export async function listClaudeSessions(cwd: string): Promise<ClaudeSession[]> {
+  const res = await fetch(`api/claude/sessions?cwd=${encodeURIComponent(cwd)}`)
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
+
+// Type `claude --resume <id>` into a pane at a shell. cwd defaults to the
+// pane's directory.
+export async function resumeClaudeSession(target: string, sessionId: string, cwd?: string): Promise<ResumeResult> {
+  const res = await fetch(`api/pane/${target}/resume`, {
+    method: 'POST',
+    body: JSON.stringify({ session_id: sessionId, cwd }),
+  })
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
@@ -0,0 +1,18 @@
+import type { ModeState, ScrollRequest } from './types'
+
+// Enter (true) or leave (false) tmux copy-mode, freezing a pane's view to
+// read earlier output.
+export async function setCopyMode(target: string, enter: boolean, host?: string): Promise<ModeState> {
+  const query = host ? `?host=${encodeURIComponent(host)}` : ''
+  const res = await fetch(`api/pane/${target}/copy-mode${query}`, { method: enter ? 'POST' : 'DELETE' })
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
+
+// Scroll a pane's history; scrolling to the bottom leaves copy-mode.
+export async function scrollPane(target: string, req: ScrollRequest, host?: string): Promise<ModeState> {
+  const query = host ? `?host=${encodeURIComponent(host)}` : ''
+  const res = await fetch(`api/pane/${target}/scroll${query}`, { method: 'POST', body: JSON.stringify(req) })
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
@@ -0,0 +1,10 @@
+// Switch the tmux client on the houston machine (or the pane's host) to a
+// pane via POST /api/pane/:target/focus. raise=false leaves the terminal
+// window where it is, for when the browser sits next to it.
+export function focusOnDesk(target: string, opts: { host?: string; raise?: boolean } = {}) {
+  const params = new URLSearchParams()
+  if (opts.host) params.set('host', opts.host)
+  if (opts.raise === false) params.set('raise', 'false')
+  const query = params.toString()
+  void fetch(`api/pane/${target}/focus${query ? `?${query}` : ''}`, { method: 'POST' })
+}
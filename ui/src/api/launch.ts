@@ -0,0 +1,9 @@
+import type { LaunchRequest, LaunchResult } from './types'
+
+// Start an agent (or a custom command) in a new window, or with reuse in
+// a window of the session sitting at a shell, via POST /api/launch.
+export async function launchAgent(req: LaunchRequest): Promise<LaunchResult> {
+  const res = await fetch('api/launch', { method: 'POST', body: JSON.stringify(req) })
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
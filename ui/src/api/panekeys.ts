@@ -0,0 +1,12 @@
+import type { PaneKeysResult } from './types'
+
+export type PaneKeyAction = 'interrupt' | 'escape' | 'clear'
+
+// Interrupt, press Escape in, or clear a pane with the keys its agent
+// expects (Escape stops Claude, C-c stops a shell).
+export async function sendPaneKeys(target: string, action: PaneKeyAction, host?: string): Promise<PaneKeysResult> {
+  const query = host ? `?host=${encodeURIComponent(host)}` : ''
+  const res = await fetch(`api/pane/${target}/${action}${query}`, { method: 'POST' })
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
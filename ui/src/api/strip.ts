@@ -0,0 +1,15 @@
+import type { AgentStripItem } from './types'
+
+// Fetch the agent strip via GET /api/strip, with the viewed pane marked
+// active. Cheaper than refetching the whole pane payload for its strip_items.
+export async function fetchStrip(active?: { target: string; host?: string }): Promise<AgentStripItem[]> {
+  const params = new URLSearchParams()
+  if (active) {
+    params.set('active', active.target)
+    if (active.host) params.set('host', active.host)
+  }
+  const query = params.toString()
+  const res = await fetch(`api/strip${query ? `?${query}` : ''}`)
+  if (!res.ok) throw new Error(await res.text())
+  return res.json()
+}
@@ -5,14 +5,15 @@ export type ResultType = 'idle' | 'working' | 'done' | 'question' | 'choice' | '
 export type Mode = 'unknown' | 'insert' | 'normal'
 
 // Mirror of agents.AgentType
-export type AgentType = 'claude-code' | 'amp' | 'generic'
+export type AgentType = 'claude-code' | 'amp' | 'opencode' | 'generic'
 
 // Mirror of parser.Result
 export interface ParseResult {
   type: ResultType
   mode: Mode
   question?: string
-  choices?: string[]
+  choices?: string[]     // verified by the agent: safe to render as buttons
+  raw_choices?: string[] // read from output text: display only, never buttons
   error_snippet?: string
   activity?: string
   suggestion?: string
@@ -20,6 +21,7 @@ export interface ParseResult {
 
 // Mirror of tmux.Session
 export interface Session {
+  host?: string          // remote tmux host; absent for local
   name: string
   created: string        // ISO 8601
   windows: number
@@ -40,6 +42,7 @@ export interface Window {
 
 // Mirror of tmux.Pane
 export interface Pane {
+  host?: string          // remote tmux host; absent for local
   session: string
   window: number
   index: number
@@ -64,6 +67,64 @@ export interface WindowWithStatus {
   branch: string
   process: string
   agent_type: AgentType
+  timers: EffectiveTimers
+  agent_manual?: boolean // agent type pinned via PUT /api/pane/:target/agent
+  relaunch?: RelaunchInfo // restored by tmux-resurrect, agent not running
+  queued?: number // prompts waiting in /api/pane/:target/queue
+  attention_since?: string // ISO 8601, while needs_attention
+  waiting_minutes?: number
+  reminder?: number // reminder intervals passed; a higher value re-notifies
+  mcp_down?: string[] // required MCP servers that disconnected (-mcp-required)
+  opencode?: OpenCodeLink // OpenCode TUI running here; its session is left out of the OpenCode list
+  subagents?: Subagent[] // Claude Code Task subagents, working first
+  claude_status?: ClaudeStatus // model, context usage and cost from Claude's status bar
+  todos?: Todo[] // the agent's task list
+  tags?: string[] // labels set via /api/pane/:target/tags
+  pr?: PullRequest // open pull request of branch (-github-prs)
+  state: PaneState // settled state; sections are built from it
+}
+
+// Mirror of server.PaneState
+export interface PaneState {
+  state: 'idle' | 'working' | 'cooling' | 'attention' // cooling: stopped working within the active TTL
+  since: string // ISO 8601
+  from?: string // state before the last transition
+}
+
+// Mirror of claude.Subagent
+export interface Subagent {
+  id: string
+  name: string // subagent type, or the start of its prompt
+  description?: string
+  tool?: string // while working
+  activity: string
+  status: 'working' | 'done' | 'stopped'
+  last_activity: string // ISO 8601
+}
+
+// Mirror of server.OpenCodeLink
+export interface OpenCodeLink {
+  server: string
+  session_id: string // detail: /api/opencode/session/:server/:session_id
+  title?: string
+  status: 'idle' | 'busy' | 'error'
+}
+
+// Mirror of server.EffectiveTimers
+export interface EffectiveTimers {
+  activity_window_seconds: number // recent output that keeps a non-agent process working
+  active_ttl_seconds: number // how long the session stays Active after work stops
+  pinned?: boolean // ttl:never: stays Active once it has worked
+  preview_lines: number // output lines in the preview
+  attention_preview_lines: number // output lines in the preview while it needs attention
+  rule?: string // -session-timers pattern that set them
+}
+
+// Mirror of server.RelaunchInfo
+export interface RelaunchInfo {
+  pane: Pane
+  agent: AgentType
+  command: string
 }
 
 // Mirror of views.SessionWithWindows
@@ -72,6 +133,26 @@ export interface SessionWithWindows {
   windows: WindowWithStatus[]
   attention_count: number
   has_working: boolean
+  pinned?: boolean // pinned with /api/sessions/{name}/pin: first in its section, never idle
+  project?: ProjectInfo // project of the directory most windows are in (local sessions)
+}
+
+// Mirror of github.PR
+export interface PullRequest {
+  number: number
+  title: string
+  url: string
+  draft?: boolean
+  ci?: 'passing' | 'failing' | 'pending' // empty without checks
+  review?: 'approved' | 'changes_requested' | 'review_required'
+}
+
+// Mirror of project.Info
+export interface ProjectInfo {
+  root: string
+  name: string // repository name (origin remote) or root directory
+  summary?: string // first README paragraph, plain text
+  language?: string
 }
 
 // Mirror of views.SessionsData
@@ -79,10 +160,22 @@ export interface SessionsData {
   needs_attention: SessionWithWindows[]
   active: SessionWithWindows[]
   idle: SessionWithWindows[]
+  hidden: Session[] // sessions hidden with /api/sessions/{name}/hide
+}
+
+// Mirror of server.SessionsPatch (SSE "catchup" event after Last-Event-ID resume)
+export interface SessionsPatch {
+  base: string
+  changed: Record<string, SessionWithWindows>
+  needs_attention: string[]
+  active: string[]
+  idle: string[]
+  hidden: Session[]
 }
 
 // Mirror of views.AgentStripItem
 export interface AgentStripItem {
+  host?: string          // remote tmux host; absent for local
   session: string
   window: number
   pane: number
@@ -103,6 +196,53 @@ export interface PaneData {
   pane_height: number
   suggestion: string
   strip_items: AgentStripItem[]
+  agent_type: AgentType
+  agent_manual?: boolean
+  mcp?: MCPHealth // Claude panes only
+  tmux_mode: ModeState
+}
+
+// Mirror of tmux.ModeState (/api/pane/:target/copy-mode and /scroll)
+export interface ModeState {
+  in_mode: boolean
+  mode?: string // copy-mode, view-mode, ...
+  scroll_position?: number // Lines scrolled up, in copy-mode
+  history_size: number
+}
+
+// Mirror of server.ScrollRequest
+export interface ScrollRequest {
+  direction: 'up' | 'down' | 'top' | 'bottom'
+  lines?: number
+  pages?: number
+}
+
+// Mirror of claude.MCPServer
+export interface MCPServer {
+  name: string
+  status: 'connected' | 'failed' | 'pending' | 'needs-auth' | 'disabled'
+  error?: string
+}
+
+// Mirror of server.MCPHealth
+export interface MCPHealth {
+  servers: MCPServer[]
+  failed?: number // failures Claude reported without naming the servers
+  down?: string[] // required servers that failed
+}
+
+// Mirror of server.View
+export interface View {
+  name: string
+  filter: string
+  group_by: '' | 'session' | 'branch' | 'agent' | 'state' | 'none'
+  sort: '' | 'urgency' | 'activity' | 'name'
+}
+
+// Mirror of server.ViewData
+export interface ViewData {
+  view: View
+  groups: { name: string; windows: WindowWithStatus[] }[]
 }
 
 // WebSocket message types
@@ -115,16 +255,41 @@ export interface WSMessage {
 
 export interface WSOutput {
   data: string
+  id: string // pass back as ?resume= when reconnecting
+}
+
+// Mirror of server.WSPatch: split the base output on "\n", drop the first
+// drop lines, splice delete lines at start with lines, join with "\n"
+export interface WSPatch {
+  base: string // id of the output it applies to
+  id: string // id of the result, like WSOutput.id
+  drop?: number
+  start: number
+  delete?: number
+  lines: string[]
 }
 
 export interface WSMeta {
   agent: AgentType
   mode: string
   status: ResultType
-  choices?: string[]
+  choices?: string[]     // verified by the agent: safe to render as buttons
+  raw_choices?: string[] // read from output text: display only, never buttons
   suggestion?: string
   status_line?: string
   activity?: string
+  claude_status?: ClaudeStatus // parsed from status_line
+  tmux_mode?: ModeState // set while the pane is in copy-mode or another tmux mode
+}
+
+// Mirror of claude.ClaudeStatus
+export interface ClaudeStatus {
+  model?: string
+  context_tokens?: number
+  context_limit?: number
+  context_percent?: number
+  messages?: number
+  cost?: string // e.g. "$1.24"
 }
 
 export interface WSInput {
@@ -135,3 +300,447 @@ export interface WSResize {
   cols: number
   rows: number
 }
+
+// Mirror of server.BroadcastRequest
+export interface BroadcastRequest {
+  targets?: string[]
+  host?: string // remote host for targets
+  tags?: string[] // windows carrying all of these tags
+  filter?: string
+  agents_only?: boolean // skip windows without a detected agent
+  input: string
+  special?: boolean
+  noenter?: boolean
+}
+
+// Mirror of server.BroadcastResult
+export interface BroadcastResult {
+  pane: Pane
+  ok: boolean
+  skipped?: boolean // no agent in the window (agents_only)
+  error?: string
+}
+
+// Mirror of server.CreateWorktreeRequest
+export interface CreateWorktreeRequest {
+  repo: string
+  branch: string
+  base?: string
+  path?: string
+  session?: string
+  command?: string
+  host?: string
+}
+
+// Mirror of server.CreatedPane
+export interface CreatedPane {
+  pane: Pane
+  worktree?: string
+  branch?: string
+}
+
+// Mirror of history.ResponseStats
+export interface ResponseStats {
+  days: { date: string; count: number; median_seconds: number; max_seconds: number; total_seconds: number }[]
+  longest: { window: string; session: string; kind: string; started: string; answered: string; via: 'houston' | 'terminal' }[]
+  pending: { window: string; session: string; kind: string; started: string; waiting_seconds: number }[]
+}
+
+// Mirror of server.Timeline
+export interface Timeline {
+  session: string
+  host?: string
+  since: string
+  until: string
+  step_seconds: number
+  samples: { at: string; state: 'working' | 'attention' | 'idle' }[]
+  minutes: Record<string, number>
+}
+
+// Mirror of server.DailyReport
+export interface DailyReport {
+  since: string
+  until: string
+  sessions: {
+    session: string
+    host?: string
+    minutes: Record<string, number>
+    answered: number
+    claude?: {
+      conversations: number
+      messages: number
+      tool_calls: Record<string, number>
+      input_tokens: number
+      output_tokens: number
+      cache_creation_input_tokens: number
+      cache_read_input_tokens: number
+    }
+    commits: { hash: string; at: string; subject: string }[]
+  }[]
+  markdown: string
+}
+
+// Mirror of gitdiff.File
+export interface DiffFile {
+  path: string
+  old_path?: string
+  status: 'added' | 'deleted' | 'modified' | 'renamed' | 'copied'
+  binary?: boolean
+  additions: number
+  deletions: number
+  hunks: {
+    header: string
+    old_start: number
+    old_lines: number
+    new_start: number
+    new_lines: number
+    lines: { kind: 'context' | 'add' | 'del'; text: string; old?: number; new?: number; no_newline?: boolean }[]
+  }[]
+}
+
+// Mirror of server.PaneDiff
+export interface PaneDiff {
+  pane: Pane
+  dir: string
+  repo: string
+  staged: boolean
+  stat: string
+  files: DiffFile[]
+  untracked: string[]
+  truncated?: boolean
+}
+
+// Mirror of server.GitPreview
+export interface GitPreview {
+  action: 'commit' | 'push'
+  token: string
+  expires: string
+  repo: string
+  message?: string
+  stat?: string
+  untracked?: string[]
+  branch?: string
+  upstream?: string
+  commits?: { hash: string; at: string; subject: string }[]
+}
+
+// Mirror of server.GitResult
+export interface GitResult {
+  action: 'commit' | 'push'
+  repo: string
+  commit?: string
+  output: string
+}
+
+// Mirror of server.Recording
+export interface Recording {
+  id: string
+  pane: Pane
+  width: number
+  height: number
+  started: string
+  ended?: string
+  duration_seconds: number // up to the last output
+  size: number // bytes
+  active: boolean // still being written
+}
+
+// Mirror of server.PaneRecording
+export interface PaneRecording {
+  pane: Pane
+  enabled: boolean
+  recordings: Recording[] // newest first
+}
+
+// Mirror of server.BatchResult
+export interface BatchResult {
+  path: string
+  status: number
+  body?: unknown
+  text?: string
+}
+
+// Mirror of server.Prefs
+export interface Prefs {
+  capture_lines?: number
+  colors?: boolean | null
+  preview_lines?: number
+  collapsed_sections?: string[]
+  updated: string
+}
+
+// Mirror of server.Upload
+export interface Upload {
+  id: string
+  name: string
+  type: string
+  size: number
+  path: string // what the agent was sent
+  created: string
+  expires: string
+}
+
+// Mirror of server.UploadedFile
+export interface UploadedFile {
+  name: string // as sent
+  path: string // where it was written, on the pane's host
+  size: number
+}
+
+// Mirror of server.Clipboard
+export interface Clipboard {
+  text: string
+}
+
+// Mirror of server.Meta
+export interface Meta {
+  version: string
+  api_versions: number[]
+  auth: string
+  features: Record<string, boolean>
+  font_controller?: string
+  hosts: string[]
+  routes: string[]
+  update?: UpdateStatus
+}
+
+// Mirror of server.TerminalInfo
+export interface TerminalInfo {
+  name: string
+  supports: ('font' | 'theme' | 'opacity' | 'font-family')[]
+  themes?: string[]
+}
+
+// Mirror of update.Status
+export interface UpdateStatus {
+  current: string
+  latest?: string
+  available: boolean
+  url?: string
+  channel: 'stable' | 'prerelease'
+  checked_at?: string
+  error?: string
+}
+
+// Mirror of server.HistoryPage
+export interface HistoryPage {
+  output: string
+  before: number
+  lines: number
+  next_before: number
+  has_more: boolean
+}
+
+// Mirror of server.QueuedPrompt
+export interface QueuedPrompt {
+  id: string
+  text: string
+  created: string
+}
+
+// Mirror of server.PromptQueue
+export interface PromptQueue {
+  pane: { session: string; window: number; index: number; host?: string }
+  prompts: QueuedPrompt[]
+}
+
+// Mirror of server.FocusResult
+export interface FocusResult {
+  client: string
+  raised: boolean
+  raise_error?: string
+}
+
+// Mirror of server.PaneChoices, the choices event of /api/pane/{target}/events
+export interface PaneChoices {
+  choices: string[]
+  raw_choices?: string[]
+}
+
+// Mirror of server.ChooseResult
+export interface ChooseResult {
+  choice: string
+  keys: string[] // tmux keys sent
+}
+
+// Mirror of server.MacroStep; exactly one field is set
+export interface MacroStep {
+  text?: string
+  key?: string // tmux key name: Enter, Escape, C-c, ...
+  delay_ms?: number
+}
+
+// Mirror of server.Macro
+export interface Macro {
+  name: string
+  description?: string
+  steps: MacroStep[]
+}
+
+// Mirror of server.SendResult (POST /api/pane/:target/send; 409, with
+// nothing sent, when error is set)
+export interface SendResult {
+  exited_mode?: string // tmux mode (copy-mode, ...) the pane was taken out of
+  checked: boolean // the input was looked for on the pane
+  confirmed: boolean // false when checked: sent but not seen; don't resend
+  error?: string
+}
+
+// Mirror of server.PaneKeysResult (/api/pane/:target/interrupt|escape|clear)
+export interface PaneKeysResult {
+  agent: AgentType
+  steps: MacroStep[]
+}
+
+// Mirror of server.Policy
+export interface Policy {
+  id: string
+  name?: string
+  tool: string
+  command?: string
+  path?: string
+  enabled: boolean
+}
+
+// Mirror of server.Approval
+export interface Approval {
+  at: string
+  pane: Pane
+  path: string
+  tool: string
+  detail: string
+  question: string
+  policy: string
+  policy_name?: string
+}
+
+// Mirror of server.AuditEntry
+export interface AuditEntry {
+  at: string
+  action: string
+  target?: string
+  detail?: string
+  status?: number
+  client: string
+  identity?: string
+}
+
+// Mirror of server.Schedule
+export interface Schedule {
+  id: string
+  name?: string
+  cron: string // e.g. "0 9 * * mon-fri", local time
+  target: string // pane ("work:1.0") or session name
+  host?: string
+  text: string
+  queue?: boolean
+  enabled: boolean
+  next: string // ISO 8601; zero time when disabled
+  last_run?: string
+  last_error?: string
+}
+
+// Mirror of claude.SessionInfo, from GET /api/claude/sessions?cwd=
+export interface ClaudeSession {
+  id: string
+  title: string
+  summary?: string
+  first_prompt?: string
+  cwd?: string
+  git_branch?: string
+  last_activity: string // ISO 8601
+  messages: number
+}
+
+// Mirror of server.ResumeResult
+export interface ResumeResult {
+  pane: Pane
+  command: string
+}
+
+// Mirror of server.LaunchRequest (POST /api/launch)
+export interface LaunchRequest {
+  agent?: 'claude' | 'amp' | 'opencode' // default claude, unless command is set
+  command?: string // custom command instead of an agent
+  dir?: string
+  prompt?: string
+  session?: string // created if missing; default: dir's base name
+  name?: string // window name
+  reuse?: boolean // type into a window sitting at a shell
+  host?: string
+}
+
+// Mirror of server.LaunchResult
+export interface LaunchResult {
+  pane: Pane
+  command: string
+  reused?: boolean
+  queued?: boolean // prompt is sent once the agent is ready
+  created?: boolean // the session was created
+}
+
+// Mirror of server.AutoCompactSetting (/api/pane/:target/auto-compact)
+export interface AutoCompactSetting {
+  enabled: boolean // false: pane opted out
+  threshold: number // context percent; 0 when -auto-compact is off
+  command?: string
+}
+
+// Mirror of server.Compaction
+export interface Compaction {
+  at: string
+  pane: Pane
+  path: string
+  context_percent: number
+  command: string
+}
+
+// Mirror of agents.Todo
+export interface Todo {
+  content: string
+  status: 'pending' | 'in_progress' | 'completed' | 'cancelled'
+  active_form?: string // shown while in progress
+}
+
+// Mirror of server.PaneTodos (GET /api/pane/:target/todos)
+export interface PaneTodos {
+  pane: Pane
+  todos: Todo[]
+  completed: number
+  total: number // cancelled items don't count
+}
+
+// Mirror of server.SearchMatch
+export interface SearchMatch {
+  source: string // 'pane', or the agent type of a transcript
+  pane: Pane
+  target: string // URL-safe pane target
+  window: string // window name
+  line?: number // pane matches: lines above the bottom of the pane
+  role?: 'user' | 'assistant' | 'tool' // transcript matches
+  timestamp?: string // ISO 8601, transcript matches
+  text: string
+}
+
+// Mirror of server.SearchResults (GET /api/search?q=)
+export interface SearchResults {
+  query: string
+  matches: SearchMatch[]
+  truncated?: boolean
+}
+
+// Mirror of server.Snippet (/api/snippets)
+export interface Snippet {
+  name: string
+  description?: string
+  text: string // {{placeholders}} are filled in when sent
+  variables: string[] // placeholders in text, in order of appearance
+}
+
+// Mirror of server.SendTemplateRequest (POST /api/pane/:target/send-template)
+export interface SendTemplateRequest {
+  name: string
+  vars?: Record<string, string> // custom placeholders; also override branch, cwd, session, window
+  clipboard?: string // for {{clipboard}}
+  noenter?: boolean
+}
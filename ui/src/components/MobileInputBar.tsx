@@ -3,6 +3,7 @@ import { useCallback, useRef, useState } from 'react'
 interface Props {
   target: string
   choices?: string[]
+  rawChoices?: string[] // unverified options from output text: shown, never clickable
 }
 
 // Web Speech API types (not in TS lib by default)
@@ -41,7 +42,7 @@ const SpeechRecognitionCtor = (window as unknown as Record<string, unknown>).Spe
 
 async function sendText(target: string, text: string) {
   const body = new URLSearchParams({ input: text })
-  await fetch(`/api/pane/${target}/send`, {
+  await fetch(`api/pane/${target}/send`, {
     method: 'POST',
     body,
     headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
@@ -50,13 +51,23 @@ async function sendText(target: string, text: string) {
 
 async function sendSpecial(target: string, key: string) {
   const body = new URLSearchParams({ input: key, special: 'true' })
-  await fetch(`/api/pane/${target}/send`, {
+  await fetch(`api/pane/${target}/send`, {
     method: 'POST',
     body,
     headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
   })
 }
 
+// Select an option of the agent's selector; the server sends the keys
+// that agent takes and refuses if the menu changed since
+async function choose(target: string, index: number, choice: string) {
+  await fetch(`api/pane/${target}/choose`, {
+    method: 'POST',
+    body: JSON.stringify({ index, choice }),
+    headers: { 'Content-Type': 'application/json' },
+  })
+}
+
 type QuickAction = { label: string; action: 'text' | 'special'; value: string }
 
 // Primary row: always visible
@@ -96,7 +107,7 @@ const pillStyle: React.CSSProperties = {
   whiteSpace: 'nowrap',
 }
 
-export function MobileInputBar({ target, choices }: Props) {
+export function MobileInputBar({ target, choices, rawChoices }: Props) {
   const [text, setText] = useState('')
   const [listening, setListening] = useState(false)
   const [expanded, setExpanded] = useState(false)
@@ -115,8 +126,8 @@ export function MobileInputBar({ target, choices }: Props) {
     await sendText(target, line)
   }
 
-  const handleChoice = async (choice: string) => {
-    await sendText(target, choice)
+  const handleChoice = async (index: number, choice: string) => {
+    await choose(target, index, choice)
   }
 
   const handleQuickAction = useCallback(async (action: 'text' | 'special', value: string) => {
@@ -179,10 +190,10 @@ export function MobileInputBar({ target, choices }: Props) {
             animation: 'slide-up 0.18s ease-out',
           }}
         >
-          {choices.map((c) => (
+          {choices.map((c, i) => (
             <button
               key={c}
-              onClick={() => handleChoice(c)}
+              onClick={() => handleChoice(i, c)}
               style={{
                 background: 'var(--bg-surface)',
                 border: '1px solid var(--accent-attention)',
@@ -199,6 +210,25 @@ export function MobileInputBar({ target, choices }: Props) {
         </div>
       )}
 
+      {/* Options read from output text: plain text so bait can't become a button */}
+      {(!choices || choices.length === 0) && rawChoices && rawChoices.length > 0 && (
+        <div
+          style={{
+            padding: '6px 8px 0',
+            fontSize: 11,
+            color: 'var(--text-muted)',
+            fontFamily: 'var(--font-mono)',
+          }}
+        >
+          <div>Options in output (unverified, type to answer):</div>
+          {rawChoices.map((c, i) => (
+            <div key={i} style={{ whiteSpace: 'nowrap', overflow: 'hidden', textOverflow: 'ellipsis' }}>
+              · {c}
+            </div>
+          ))}
+        </div>
+      )}
+
       {/* Quick action pills — wrapping grid with expand toggle */}
       <div style={{ display: 'flex', alignItems: 'flex-start', padding: '6px 8px 0' }}>
         <div style={{ display: 'flex', flexWrap: 'wrap', gap: 6, flex: 1 }}>
@@ -1,4 +1,6 @@
-import type { AgentType, ResultType, WSMeta } from '../api/types'
+import { scrollPane, setCopyMode } from '../api/copymode'
+import { focusOnDesk } from '../api/focus'
+import type { AgentType, ResultType, ScrollRequest, WSMeta } from '../api/types'
 
 interface Props {
   target: string
@@ -11,9 +13,13 @@ interface Props {
 const AGENT_ICONS: Record<AgentType, string> = {
   'claude-code': '✦',
   'amp': '⚡',
+  'opencode': '◇',
   'generic': '◆',
 }
 
+// Context usage at which Claude panes warn, ahead of auto-compact
+const CONTEXT_WARN_PERCENT = 80
+
 function statusColor(status: ResultType | undefined): string {
   switch (status) {
     case 'done':     return 'var(--accent-done)'
@@ -31,7 +37,14 @@ export function PaneHeader({ target, meta, onClose, wideMode, onToggleWide }: Pr
   const modeBadge = meta?.mode === 'normal' ? 'NOR' : meta?.mode === 'insert' ? 'INS' : null
   // Show activity text if available, otherwise show the window portion of target
   const label = meta?.activity || (target.split(':')[1] ?? target)
+  const contextPercent = meta?.claude_status?.context_percent
+  const contextHigh = contextPercent !== undefined && contextPercent >= CONTEXT_WARN_PERCENT
   const isMobile = !!onToggleWide // mobile passes onToggleWide, desktop doesn't
+  // Scrolled back in tmux copy-mode: the stream shows earlier output
+  const scrolledBack = meta?.tmux_mode?.mode === 'copy-mode'
+  const scroll = (direction: ScrollRequest['direction']) => {
+    scrollPane(target, { direction }).catch(() => {})
+  }
 
   const headerBtn: React.CSSProperties = isMobile
     ? {
@@ -102,6 +115,84 @@ export function PaneHeader({ target, meta, onClose, wideMode, onToggleWide }: Pr
         </span>
       )}
 
+      {contextPercent !== undefined && (
+        <span
+          title={contextHigh ? 'Context nearly full: Claude will compact soon' : 'Context used'}
+          style={{
+            fontSize: isMobile ? 11 : 9,
+            fontFamily: 'var(--font-mono)',
+            color: contextHigh ? 'var(--accent-attention)' : 'var(--text-muted)',
+            flexShrink: 0,
+          }}
+        >
+          {Math.round(contextPercent)}%
+        </span>
+      )}
+
+      {scrolledBack && (
+        <span
+          title="Scrolled back: showing earlier output"
+          style={{
+            fontSize: isMobile ? 11 : 9,
+            fontFamily: 'var(--font-mono)',
+            color: 'var(--accent-attention)',
+            flexShrink: 0,
+          }}
+        >
+          ↑{meta?.tmux_mode?.scroll_position ?? 0}
+        </span>
+      )}
+
+      <button
+        onClick={(e) => {
+          e.stopPropagation()
+          scroll('up')
+        }}
+        title="Scroll back a page"
+        style={{ ...headerBtn, color: 'var(--text-muted)' }}
+      >
+        ▲
+      </button>
+
+      {scrolledBack && (
+        <>
+          <button
+            onClick={(e) => {
+              e.stopPropagation()
+              scroll('down')
+            }}
+            title="Scroll forward a page"
+            style={{ ...headerBtn, color: 'var(--text-muted)' }}
+          >
+            ▼
+          </button>
+          <button
+            onClick={(e) => {
+              e.stopPropagation()
+              setCopyMode(target, false).catch(() => {})
+            }}
+            title="Back to the live output"
+            style={{ ...headerBtn, color: 'var(--accent-attention)' }}
+          >
+            LIVE
+          </button>
+        </>
+      )}
+
+      {isMobile && (
+        <button
+          onClick={(e) => {
+            e.stopPropagation()
+            // Raise the desk terminal too, so the monitor follows the phone
+            focusOnDesk(target)
+          }}
+          title="Show this pane on the desk terminal"
+          style={{ ...headerBtn, color: 'var(--text-muted)' }}
+        >
+          DESK
+        </button>
+      )}
+
       {onToggleWide && (
         <button
           onClick={(e) => {
@@ -1,9 +1,11 @@
 import { useMemo, useState } from 'react'
-import type { AgentType, SessionsData, SessionWithWindows, WindowWithStatus } from '../api/types'
+import { focusOnDesk } from '../api/focus'
+import type { AgentType, SessionsData, SessionWithWindows, Subagent, WindowWithStatus } from '../api/types'
 
 const AGENT_ICONS: Record<AgentType, string> = {
   'claude-code': '✦',
   'amp': '⚡',
+  'opencode': '◇',
   'generic': '',
 }
 
@@ -21,19 +23,27 @@ function WindowRow({ w, sessionName, onSelect, onSplit }: WindowRowProps) {
 
   const dotColor =
     w.needs_attention ? 'var(--accent-attention)' :
-    type === 'working' ? 'var(--accent-working)' :
+    w.state?.state === 'working' || w.opencode?.status === 'busy' ? 'var(--accent-working)' :
     type === 'done'    ? 'var(--accent-done)' :
                          'var(--accent-idle)'
 
   const statusLabel =
+    w.relaunch          ? 'Needs relaunch' :
     type === 'error'    ? 'Error' :
     type === 'question' ? 'Waiting for input' :
     type === 'choice'   ? 'Waiting for choice' :
+    w.mcp_down?.length  ? `MCP down: ${w.mcp_down.join(', ')}` :
+    w.opencode?.title   ? w.opencode.title :
     activity || null
+  const waiting = w.needs_attention && w.waiting_minutes ? ` · ${w.waiting_minutes}m` : ''
+  const todos = (w.todos ?? []).filter((t) => t.status !== 'cancelled')
+  const todosDone = todos.filter((t) => t.status === 'completed').length
+  const currentTodo = todos.find((t) => t.status === 'in_progress')
 
   return (
     <div
       className="tree-row"
+      title="Click to open · Ctrl-click to split · Alt-click to jump the tmux client here"
       style={{
         padding: '3px 8px 3px 24px',
         cursor: 'pointer',
@@ -42,7 +52,9 @@ function WindowRow({ w, sessionName, onSelect, onSplit }: WindowRowProps) {
         fontSize: 12,
       }}
       onClick={(e) => {
-        if (e.ctrlKey || e.metaKey) {
+        if (e.altKey) {
+          focusOnDesk(target, { host: w.pane.host, raise: false })
+        } else if (e.ctrlKey || e.metaKey) {
           onSplit(target)
         } else {
           onSelect(target)
@@ -51,14 +63,37 @@ function WindowRow({ w, sessionName, onSelect, onSplit }: WindowRowProps) {
     >
       <div style={{ display: 'flex', alignItems: 'center', gap: 6 }}>
         <span style={{ width: 6, height: 6, borderRadius: '50%', background: dotColor, flexShrink: 0 }} />
-        {agentIcon && <span style={{ flexShrink: 0, fontSize: 10, color: dotColor }}>{agentIcon}</span>}
+        {agentIcon && (
+          <span
+            style={{ flexShrink: 0, fontSize: 10, color: dotColor }}
+            title={w.agent_manual ? `${w.agent_type} (manually set)` : w.agent_type}
+          >
+            {agentIcon}{w.agent_manual && '*'}
+          </span>
+        )}
         <span style={{ overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }}>
           {w.branch && w.branch !== 'main' && w.branch !== 'master' ? w.branch : w.window.name}
         </span>
+        {todos.length > 0 && (
+          <span
+            style={{ flexShrink: 0, marginLeft: 'auto', fontSize: 10, color: 'var(--text-muted)' }}
+            title={currentTodo ? currentTodo.active_form || currentTodo.content : `${todosDone} of ${todos.length} tasks done`}
+          >
+            {todosDone}/{todos.length}
+          </span>
+        )}
+        {!!w.queued && (
+          <span
+            style={{ flexShrink: 0, marginLeft: todos.length > 0 ? 0 : 'auto', fontSize: 10, color: 'var(--text-muted)' }}
+            title={`${w.queued} queued prompt${w.queued === 1 ? '' : 's'}`}
+          >
+            +{w.queued}
+          </span>
+        )}
       </div>
       {statusLabel && (
         <div style={{ color: dotColor, fontSize: 10, paddingLeft: 12, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }}>
-          {statusLabel}
+          {statusLabel}{waiting}
         </div>
       )}
       {w.branch && w.branch !== 'main' && w.branch !== 'master' && w.branch !== w.window.name && (
@@ -71,6 +106,28 @@ function WindowRow({ w, sessionName, onSelect, onSplit }: WindowRowProps) {
           {w.branch}
         </div>
       )}
+      {w.subagents?.map((a) => <SubagentRow key={a.id} a={a} />)}
+    </div>
+  )
+}
+
+// SubagentRow is a Task subagent listed under the window that started it.
+function SubagentRow({ a }: { a: Subagent }) {
+  const color =
+    a.status === 'working' ? 'var(--accent-working)' :
+    a.status === 'done'    ? 'var(--accent-done)' :
+                             'var(--accent-idle)'
+
+  return (
+    <div
+      title={[a.description, a.activity].filter(Boolean).join('\n')}
+      style={{ display: 'flex', alignItems: 'center', gap: 6, paddingLeft: 12, fontSize: 10, color: 'var(--text-muted)' }}
+    >
+      <span style={{ color, flexShrink: 0 }}>↳</span>
+      <span style={{ overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }}>
+        {a.name}
+        {a.status === 'working' && <span style={{ color }}> · {a.activity}</span>}
+      </span>
     </div>
   )
 }
@@ -107,13 +164,24 @@ function SessionRow({ s, onSelect, onSplit }: SessionRowProps) {
           fontWeight: 500,
         }}
         onClick={() => setExpanded((x) => !x)}
+        title={projectTitle(s)}
       >
         <span style={{ fontSize: 10, color: 'var(--text-muted)', width: 10 }}>
           {expanded ? '▾' : '▸'}
         </span>
         <span style={{ flex: 1, overflow: 'hidden', textOverflow: 'ellipsis', whiteSpace: 'nowrap' }}>
           {s.session.name}
+          {s.project && s.project.name !== s.session.name && (
+            <span style={{ marginLeft: 6, fontSize: 11, fontWeight: 400, color: 'var(--text-muted)' }}>
+              {s.project.name}
+            </span>
+          )}
         </span>
+        {s.project?.language && (
+          <span style={{ fontSize: 10, color: 'var(--text-muted)', flexShrink: 0 }}>
+            {s.project.language}
+          </span>
+        )}
         {s.attention_count > 0 && (
           <span style={{
             background: 'var(--accent-attention)',
@@ -142,6 +210,14 @@ function SessionRow({ s, onSelect, onSplit }: SessionRowProps) {
   )
 }
 
+// projectTitle is the session row tooltip: the project and its README summary.
+function projectTitle(s: SessionWithWindows): string | undefined {
+  if (!s.project) return undefined
+  const { name, language, summary, root } = s.project
+  const head = language ? `${name} (${language})` : name
+  return [head, summary, root].filter(Boolean).join('\n')
+}
+
 interface GroupProps {
   label: string
   items: SessionWithWindows[]
@@ -185,6 +261,7 @@ export function SessionTree({ sessions, onSelect, onSplit }: Props) {
       const q = filter.toLowerCase()
       return (
         s.session.name.toLowerCase().includes(q) ||
+        (s.project?.name.toLowerCase().includes(q) ?? false) ||
         s.windows.some(
           (w) =>
             w.window.name.toLowerCase().includes(q) ||
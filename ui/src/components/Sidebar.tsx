@@ -1,5 +1,5 @@
 import { useEffect, useState } from 'react'
-import type { SessionsData } from '../api/types'
+import type { Meta, SessionsData, UpdateStatus } from '../api/types'
 import { SessionTree } from './SessionTree'
 
 interface Props {
@@ -30,8 +30,23 @@ function useTheme() {
   return { theme, toggle }
 }
 
+/** Latest release check from /api/meta, when the server runs with -update-check. */
+function useUpdateStatus() {
+  const [status, setStatus] = useState<UpdateStatus | null>(null)
+
+  useEffect(() => {
+    fetch('api/meta')
+      .then((r) => r.json() as Promise<Meta>)
+      .then((meta) => setStatus(meta.update ?? null))
+      .catch(() => {})
+  }, [])
+
+  return status
+}
+
 export function Sidebar({ sessions, connected, open, onClose, onSelectWindow, onSplitWindow, isDesktop }: Props) {
   const { theme, toggle } = useTheme()
+  const update = useUpdateStatus()
 
   if (!open) return null
 
@@ -90,6 +105,17 @@ export function Sidebar({ sessions, connected, open, onClose, onSelectWindow, on
           <h2 style={{ fontSize: 13, color: 'var(--text-secondary)', fontFamily: 'var(--font-mono)', fontWeight: 600 }}>
             houston
           </h2>
+          {update?.available && (
+            <a
+              href={update.url}
+              target="_blank"
+              rel="noreferrer"
+              title={`houston ${update.latest} is available (running ${update.current}). Run \`houston update\` to install.`}
+              style={{ fontSize: 11, color: 'var(--accent-done)', fontFamily: 'var(--font-mono)', textDecoration: 'none' }}
+            >
+              ↑ {update.latest}
+            </a>
+          )}
         </div>
 
         <div style={{ display: 'flex', alignItems: 'center', gap: 6 }}>
@@ -342,6 +342,7 @@ export function TerminalPane({ pane, isFocused, onFocus, onClose }: Props) {
         <MobileInputBar
           target={pane.target}
           choices={meta?.choices}
+          rawChoices={meta?.raw_choices}
         />
       )}
     </div>
@@ -2,8 +2,8 @@ import { useEffect, useRef } from 'react'
 import type { SessionsData } from '../api/types'
 
 /** Collect all window keys that currently need attention. */
-function attentionKeys(sessions: SessionsData): Map<string, { session: string; window: string; activity: string }> {
-  const map = new Map<string, { session: string; window: string; activity: string }>()
+function attentionKeys(sessions: SessionsData): Map<string, { session: string; window: string; activity: string; reminder: number; waiting: number }> {
+  const map = new Map<string, { session: string; window: string; activity: string; reminder: number; waiting: number }>()
   for (const s of sessions.needs_attention) {
     for (const w of s.windows) {
       if (!w.needs_attention) continue
@@ -12,17 +12,18 @@ function attentionKeys(sessions: SessionsData): Map<string, { session: string; w
         w.parse_result.type === 'error' ? 'Error' :
         w.parse_result.type === 'question' ? 'Waiting for input' :
         w.parse_result.type === 'choice' ? 'Waiting for choice' :
+        w.mcp_down?.length ? `MCP server disconnected: ${w.mcp_down.join(', ')}` :
         w.parse_result.activity || 'Needs attention'
       const label = w.branch && w.branch !== 'main' && w.branch !== 'master'
         ? w.branch : w.window.name
-      map.set(key, { session: s.session.name, window: label, activity })
+      map.set(key, { session: s.session.name, window: label, activity, reminder: w.reminder ?? 0, waiting: w.waiting_minutes ?? 0 })
     }
   }
   return map
 }
 
 export function useAttentionNotifications(sessions: SessionsData | null) {
-  const prevKeysRef = useRef<Set<string>>(new Set())
+  const prevKeysRef = useRef<Map<string, number>>(new Map()) // key -> reminder level notified
   const permissionRef = useRef<NotificationPermission>(
     typeof Notification !== 'undefined' ? Notification.permission : 'denied',
   )
@@ -44,14 +45,17 @@ export function useAttentionNotifications(sessions: SessionsData | null) {
     const prev = prevKeysRef.current
 
     for (const [key, info] of current) {
-      if (prev.has(key)) continue
-      // New attention window — notify
+      const notified = prev.get(key)
+      if (notified !== undefined && info.reminder <= notified) continue
+      // New attention window, or still unanswered at the next reminder — notify
+      const reminder = notified !== undefined
       new Notification(`${info.session} — ${info.window}`, {
-        body: info.activity,
+        body: reminder ? `Waiting ${info.waiting} min: ${info.activity}` : info.activity,
         tag: key, // dedup same window
-      })
+        renotify: reminder,
+      } as NotificationOptions)
     }
 
-    prevKeysRef.current = new Set(current.keys())
+    prevKeysRef.current = new Map([...current].map(([key, info]) => [key, info.reminder]))
   }, [sessions])
 }
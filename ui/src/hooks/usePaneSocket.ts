@@ -1,16 +1,25 @@
 import { useCallback, useEffect, useRef, useState } from 'react'
-import type { WSMeta, WSOutput } from '../api/types'
+import type { WSMeta, WSOutput, WSPatch } from '../api/types'
 
 interface PaneSocketCallbacks {
   onOutput: (data: string) => void
   onMeta: (meta: WSMeta) => void
 }
 
+// Apply a patch to the output it was computed from (see server.WSPatch)
+function applyPatch(base: string, patch: WSPatch): string {
+  const lines = base.split('\n').slice(patch.drop ?? 0)
+  lines.splice(patch.start, patch.delete ?? 0, ...patch.lines)
+  return lines.join('\n')
+}
+
 export function usePaneSocket(target: string | null, callbacks: PaneSocketCallbacks) {
   const wsRef = useRef<WebSocket | null>(null)
   const callbacksRef = useRef(callbacks)
   const [connected, setConnected] = useState(false)
   const retriesRef = useRef(0)
+  const outputIdRef = useRef('')
+  const outputRef = useRef('')
 
   // Keep callbacks ref up-to-date without triggering reconnect
   useEffect(() => {
@@ -34,12 +43,17 @@ export function usePaneSocket(target: string | null, callbacks: PaneSocketCallba
 
     let cancelled = false
     let reconnectTimer: ReturnType<typeof setTimeout>
+    outputIdRef.current = ''
+    outputRef.current = ''
 
     function connect() {
       if (cancelled) return
 
-      const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
-      const wsUrl = `${protocol}//${window.location.host}/api/pane/${target}/ws`
+      // Skip resending output the terminal already shows after a reconnect
+      const resume = outputIdRef.current ? `&resume=${encodeURIComponent(outputIdRef.current)}` : ''
+      // Relative to the page's <base href>, which carries the server's -base-path
+      const wsUrl = new URL(`api/pane/${target}/ws?patch=1${resume}`, document.baseURI)
+      wsUrl.protocol = wsUrl.protocol === 'https:' ? 'wss:' : 'ws:'
 
       const ws = new WebSocket(wsUrl)
       wsRef.current = ws
@@ -65,9 +79,24 @@ export function usePaneSocket(target: string | null, callbacks: PaneSocketCallba
           switch (msg.type) {
             case 'output': {
               const output = msg.data as WSOutput
+              outputIdRef.current = output.id
+              outputRef.current = output.data
               callbacksRef.current.onOutput(output.data)
               break
             }
+            case 'patch': {
+              const patch = msg.data as WSPatch
+              if (patch.base !== outputIdRef.current) {
+                // Out of step: reconnect for the full output
+                outputIdRef.current = ''
+                ws.close()
+                break
+              }
+              outputIdRef.current = patch.id
+              outputRef.current = applyPatch(outputRef.current, patch)
+              callbacksRef.current.onOutput(outputRef.current)
+              break
+            }
             case 'meta': {
               const meta = msg.data as WSMeta
               callbacksRef.current.onMeta(meta)
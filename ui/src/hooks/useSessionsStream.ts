@@ -1,33 +1,81 @@
 import { useEffect, useRef, useState } from 'react'
-import type { SessionsData } from '../api/types'
+import type { SessionsData, SessionsPatch, SessionWithWindows } from '../api/types'
+
+function sessionKey(s: SessionWithWindows): string {
+  return s.session.host ? `${s.session.host}|${s.session.name}` : s.session.name
+}
+
+/** Apply a catch-up patch to the sessions the client had at patch.base. */
+function applyPatch(prev: SessionsData, patch: SessionsPatch): SessionsData {
+  const known = new Map<string, SessionWithWindows>()
+  for (const s of [...prev.needs_attention, ...prev.active, ...prev.idle]) {
+    known.set(sessionKey(s), s)
+  }
+  const pick = (keys: string[]) =>
+    keys.map((k) => patch.changed[k] ?? known.get(k)).filter((s): s is SessionWithWindows => !!s)
+  return {
+    needs_attention: pick(patch.needs_attention),
+    active: pick(patch.active),
+    idle: pick(patch.idle),
+    hidden: patch.hidden ?? [],
+  }
+}
 
 export function useSessionsStream() {
   const [sessions, setSessions] = useState<SessionsData | null>(null)
   const [connected, setConnected] = useState(false)
   const eventSourceRef = useRef<EventSource | null>(null)
+  const lastEventIdRef = useRef('')
 
   useEffect(() => {
-    const es = new EventSource('/api/sessions?stream=1')
-    eventSourceRef.current = es
+    function connect() {
+      // EventSource resends Last-Event-ID on its own reconnects; a new
+      // EventSource (after the browser gave up) passes it explicitly.
+      const resume = lastEventIdRef.current ? `&last_event_id=${encodeURIComponent(lastEventIdRef.current)}` : ''
+      const es = new EventSource(`api/sessions?stream=1${resume}`)
+      eventSourceRef.current = es
 
-    es.onopen = () => setConnected(true)
+      es.onopen = () => setConnected(true)
 
-    es.onmessage = (event) => {
-      try {
-        const data: SessionsData = JSON.parse(event.data)
-        setSessions(data)
-      } catch (e) {
-        console.error('Failed to parse sessions SSE:', e)
+      es.onmessage = (event) => {
+        try {
+          const data: SessionsData = JSON.parse(event.data)
+          lastEventIdRef.current = event.lastEventId
+          setSessions(data)
+        } catch (e) {
+          console.error('Failed to parse sessions SSE:', e)
+        }
+      }
+
+      es.addEventListener('catchup', (event) => {
+        try {
+          const patch: SessionsPatch = JSON.parse((event as MessageEvent).data)
+          lastEventIdRef.current = (event as MessageEvent).lastEventId
+          setSessions((prev) => (prev ? applyPatch(prev, patch) : prev))
+        } catch (e) {
+          console.error('Failed to parse sessions catch-up:', e)
+        }
+      })
+
+      es.onerror = () => {
+        setConnected(false)
+        // EventSource auto-reconnects unless the browser closed it for good
       }
     }
 
-    es.onerror = () => {
-      setConnected(false)
-      // EventSource auto-reconnects
+    connect()
+
+    // Phones often kill the connection while locked; resume on wake
+    const onVisibility = () => {
+      if (document.visibilityState === 'visible' && eventSourceRef.current?.readyState === EventSource.CLOSED) {
+        connect()
+      }
     }
+    document.addEventListener('visibilitychange', onVisibility)
 
     return () => {
-      es.close()
+      document.removeEventListener('visibilitychange', onVisibility)
+      eventSourceRef.current?.close()
       eventSourceRef.current = null
     }
   }, [])
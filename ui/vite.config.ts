@@ -2,6 +2,9 @@ import { defineConfig } from 'vite'
 import react from '@vitejs/plugin-react'
 
 export default defineConfig({
+  // Relative asset URLs resolve against the <base href> the server
+  // injects, so the build works under any -base-path
+  base: './',
   plugins: [react()],
   server: {
     proxy: {
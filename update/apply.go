@@ -0,0 +1,110 @@
+package update
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumsAsset is the release file listing sha256 sums of every binary.
+const ChecksumsAsset = "checksums.txt"
+
+// Apply downloads the release binary for the given platform, verifies it
+// against the release checksums and atomically replaces exe with it.
+// Releases without checksums are refused.
+func Apply(ctx context.Context, client *http.Client, rel Release, goos, goarch, exe string) error {
+	name := AssetName(goos, goarch)
+	asset, ok := rel.Asset(name)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for %s/%s", rel.Tag, goos, goarch)
+	}
+	sums, ok := rel.Asset(ChecksumsAsset)
+	if !ok {
+		return fmt.Errorf("release %s has no %s; refusing unverified update", rel.Tag, ChecksumsAsset)
+	}
+
+	body, err := download(ctx, client, sums.URL)
+	if err != nil {
+		return fmt.Errorf("download checksums: %w", err)
+	}
+	want, err := findChecksum(body, name)
+	_ = body.Close()
+	if err != nil {
+		return err
+	}
+
+	body, err = download(ctx, client, asset.URL)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+	defer func() { _ = body.Close() }()
+
+	// Write next to the target so the final rename stays on one filesystem
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".houston-update-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, h), body); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("download %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+func download(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// findChecksum reads sha256sum output ("<hex>  <name>", optionally with a
+// '*' binary marker) and returns the sum for name.
+func findChecksum(r io.Reader, name string) (string, error) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s not listed in %s", name, ChecksumsAsset)
+}
@@ -0,0 +1,304 @@
+// Package update checks GitHub releases for newer houston versions and
+// replaces the running binary with a verified download.
+//
+// Releases are expected to carry one raw binary per platform named
+// houston_<goos>_<goarch> and a checksums.txt in sha256sum format.
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRepo    = "noamsto/houston"
+	DefaultBaseURL = "https://api.github.com"
+
+	// DefaultInterval is how often the background check polls GitHub.
+	DefaultInterval = 24 * time.Hour
+)
+
+// Channel selects which releases are considered.
+type Channel string
+
+const (
+	ChannelStable     Channel = "stable"
+	ChannelPrerelease Channel = "prerelease"
+)
+
+// ParseChannel validates a --channel value.
+func ParseChannel(s string) (Channel, error) {
+	switch Channel(s) {
+	case "", ChannelStable:
+		return ChannelStable, nil
+	case ChannelPrerelease:
+		return ChannelPrerelease, nil
+	}
+	return "", fmt.Errorf("unknown channel %q (want stable or prerelease)", s)
+}
+
+// Release is the subset of the GitHub release object houston uses.
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	Prerelease bool    `json:"prerelease"`
+	Draft      bool    `json:"draft"`
+	URL        string  `json:"html_url"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is a downloadable release file.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Asset returns the release file with the given name.
+func (r Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// AssetName is the release binary name for a platform.
+func AssetName(goos, goarch string) string {
+	return "houston_" + goos + "_" + goarch
+}
+
+// Status is the result of the most recent version check.
+type Status struct {
+	Current   string    `json:"current"`
+	Latest    string    `json:"latest,omitempty"`
+	Available bool      `json:"available"`
+	URL       string    `json:"url,omitempty"` // Release page
+	Channel   Channel   `json:"channel"`
+	CheckedAt time.Time `json:"checked_at,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Checker looks up the latest release for a channel.
+type Checker struct {
+	Current string
+	Channel Channel
+	Repo    string
+	BaseURL string
+	HTTP    *http.Client
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewChecker creates a checker against GitHub for the default repository.
+func NewChecker(current string, channel Channel) *Checker {
+	return &Checker{
+		Current: current,
+		Channel: channel,
+		Repo:    DefaultRepo,
+		BaseURL: DefaultBaseURL,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+		status:  Status{Current: current, Channel: channel},
+	}
+}
+
+// Latest returns the newest published release on the checker's channel.
+// GitHub lists releases newest first.
+func (c *Checker) Latest(ctx context.Context) (Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=30", strings.TrimSuffix(c.BaseURL, "/"), c.Repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("fetch releases: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("fetch releases: %s", resp.Status)
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return Release{}, fmt.Errorf("decode releases: %w", err)
+	}
+	for _, r := range releases {
+		if r.Draft || (r.Prerelease && c.Channel != ChannelPrerelease) {
+			continue
+		}
+		return r, nil
+	}
+	return Release{}, fmt.Errorf("no %s release found", c.Channel)
+}
+
+// Check queries the latest release and records the result.
+func (c *Checker) Check(ctx context.Context) Status {
+	st := Status{Current: c.Current, Channel: c.Channel, CheckedAt: time.Now()}
+	rel, err := c.Latest(ctx)
+	if err != nil {
+		st.Error = err.Error()
+	} else {
+		st.Latest = rel.Tag
+		st.URL = rel.URL
+		st.Available = Newer(rel.Tag, c.Current)
+	}
+
+	c.mu.Lock()
+	c.status = st
+	c.mu.Unlock()
+	return st
+}
+
+// Status returns the result of the last check.
+func (c *Checker) Status() Status {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.status
+}
+
+// Run checks immediately and then every interval until ctx is done.
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		st := c.Check(ctx)
+		if st.Error != "" {
+			slog.Debug("update check failed", "error", st.Error)
+		} else if st.Available {
+			slog.Info("update available", "current", st.Current, "latest", st.Latest)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// BuildVersion returns the version set with -ldflags, falling back to the
+// module version or VCS revision embedded by the Go toolchain.
+func BuildVersion(ldflags string) string {
+	if ldflags != "" {
+		return ldflags
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			return setting.Value[:12]
+		}
+	}
+	return "dev"
+}
+
+// Newer reports whether latest is a higher semantic version than current.
+// Unparseable versions (dev builds, commit hashes) never compare as older, so
+// development builds do not nag.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	return compareVersions(l, c) > 0
+}
+
+type version struct {
+	core [3]int
+	pre  []string
+}
+
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i] // Build metadata does not affect precedence
+	}
+	var v version
+	core, pre, hasPre := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return version{}, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.core[i] = n
+	}
+	if hasPre {
+		v.pre = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+func compareVersions(a, b version) int {
+	for i := range a.core {
+		if a.core[i] != b.core[i] {
+			if a.core[i] > b.core[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	// A release outranks any of its pre-releases
+	switch {
+	case len(a.pre) == 0 && len(b.pre) == 0:
+		return 0
+	case len(a.pre) == 0:
+		return 1
+	case len(b.pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(a.pre) && i < len(b.pre); i++ {
+		if c := comparePreField(a.pre[i], b.pre[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a.pre) > len(b.pre):
+		return 1
+	case len(a.pre) < len(b.pre):
+		return -1
+	}
+	return 0
+}
+
+func comparePreField(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		switch {
+		case an > bn:
+			return 1
+		case an < bn:
+			return -1
+		}
+		return 0
+	case aErr == nil:
+		return -1 // Numeric identifiers sort before alphanumeric ones
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
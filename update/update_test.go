@@ -0,0 +1,149 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.10.0", false},
+		{"1.2.0", "v1.1.0", true},
+		{"v2.0.0", "v2.0.0-rc.1", true},
+		{"v2.0.0-rc.2", "v2.0.0-rc.1", true},
+		{"v2.0.0-rc.10", "v2.0.0-rc.9", true},
+		{"v2.0.0-rc.1", "v2.0.0", false},
+		{"v2.0.0-beta", "v2.0.0-alpha", true},
+		{"v2.0.0-rc.1.1", "v2.0.0-rc.1", true},
+		{"v1.0.1+build.5", "v1.0.0", true},
+		{"v1.2.0", "dev", false},
+		{"v1.2.0", "0123456789ab", false},
+		{"latest", "v1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestParseChannel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Channel
+		wantErr bool
+	}{
+		{"", ChannelStable, false},
+		{"stable", ChannelStable, false},
+		{"prerelease", ChannelPrerelease, false},
+		{"nightly", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseChannel(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseChannel(%q) = %q, %v, want %q, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckerChannels(t *testing.T) {
+	releases := []Release{
+		{Tag: "v1.3.0-rc.1", Prerelease: true},
+		{Tag: "v1.4.0", Draft: true},
+		{Tag: "v1.2.0", URL: "https://example.com/v1.2.0"},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/noamsto/houston/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(releases)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		channel Channel
+		want    string
+	}{
+		{ChannelStable, "v1.2.0"},
+		{ChannelPrerelease, "v1.3.0-rc.1"},
+	}
+	for _, tt := range tests {
+		c := NewChecker("v1.1.0", tt.channel)
+		c.BaseURL = srv.URL
+		st := c.Check(context.Background())
+		if st.Error != "" || st.Latest != tt.want || !st.Available {
+			t.Errorf("Check(%s) = %+v, want latest %s available", tt.channel, st, tt.want)
+		}
+		if got := c.Status(); got.Latest != tt.want {
+			t.Errorf("Status(%s).Latest = %q, want %q", tt.channel, got.Latest, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(binary)
+	name := AssetName("linux", "amd64")
+
+	checksums := hex.EncodeToString(sum[:]) + "  " + name + "\n"
+	mux := http.NewServeMux()
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc("/sums", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(checksums)) })
+	mux.HandleFunc("/badsums", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0000  " + name + "\n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	release := func(sumsPath string) Release {
+		rel := Release{Tag: "v1.2.0", Assets: []Asset{{Name: name, URL: srv.URL + "/bin"}}}
+		if sumsPath != "" {
+			rel.Assets = append(rel.Assets, Asset{Name: ChecksumsAsset, URL: srv.URL + sumsPath})
+		}
+		return rel
+	}
+
+	tests := []struct {
+		name     string
+		rel      Release
+		goarch   string
+		wantErr  bool
+		wantBody string
+	}{
+		{"verified", release("/sums"), "amd64", false, string(binary)},
+		{"checksum mismatch", release("/badsums"), "amd64", true, "old"},
+		{"no checksums", release(""), "amd64", true, "old"},
+		{"no binary for platform", release("/sums"), "arm64", true, "old"},
+	}
+	for _, tt := range tests {
+		exe := filepath.Join(t.TempDir(), "houston")
+		if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		err := Apply(context.Background(), srv.Client(), tt.rel, "linux", tt.goarch, exe)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Apply() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		got, _ := os.ReadFile(exe)
+		if string(got) != tt.wantBody {
+			t.Errorf("%s: binary = %q, want %q", tt.name, got, tt.wantBody)
+		}
+		entries, _ := os.ReadDir(filepath.Dir(exe))
+		if len(entries) != 1 {
+			t.Errorf("%s: left %d files behind, want only the binary", tt.name, len(entries))
+		}
+	}
+}